@@ -0,0 +1,196 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package benchmarks
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goforj/wire/internal/wire"
+)
+
+var (
+	baselineFile = flag.String("bench_baseline", "", "go test -bench output to compare the benchmarks against; TestBaseline fails on regressions")
+	threshold    = flag.Float64("bench_threshold", 0.2, "fraction by which a benchmark may be slower than its baseline")
+)
+
+// modules are the synthetic modules each benchmark runs on.
+var modules = []Module{
+	{Packages: 5, Providers: 10},
+	{Packages: 20, Providers: 20},
+}
+
+// suite lists the benchmarks by the names go test reports them under, so
+// that TestBaseline can run them and match them with a baseline.
+var suite = []struct {
+	name string
+	fn   func(*testing.B, Module)
+}{
+	{"BenchmarkLoad", benchLoad},
+	{"BenchmarkGenerate", benchGenerate},
+	{"BenchmarkGenerateCached", benchGenerateCached},
+	{"BenchmarkChange", benchChange},
+}
+
+func BenchmarkLoad(b *testing.B)           { runModules(b, benchLoad) }
+func BenchmarkGenerate(b *testing.B)       { runModules(b, benchGenerate) }
+func BenchmarkGenerateCached(b *testing.B) { runModules(b, benchGenerateCached) }
+func BenchmarkChange(b *testing.B)         { runModules(b, benchChange) }
+
+// runModules runs fn as a sub-benchmark for each of modules.
+func runModules(b *testing.B, fn func(*testing.B, Module)) {
+	for _, m := range modules {
+		m := m
+		b.Run(m.String(), func(b *testing.B) { fn(b, m) })
+	}
+}
+
+// benchLoad measures type-checking the module and parsing its injectors.
+func benchLoad(b *testing.B, m Module) {
+	dir, env := setup(b, m)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, errs := wire.Load(ctx, dir, env, "", []string{"./..."}); len(errs) > 0 {
+			b.Fatalf("Load: %v", errs)
+		}
+	}
+}
+
+// benchGenerate measures generating every package with an empty cache.
+func benchGenerate(b *testing.B, m Module) {
+	dir, env := setup(b, m)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		if err := wire.ClearCache(); err != nil {
+			b.Fatalf("ClearCache: %v", err)
+		}
+		b.StartTimer()
+		generate(b, dir, env, m)
+	}
+}
+
+// benchGenerateCached measures a run whose output is all cached, which is
+// dominated by checking that the cache is still valid.
+func benchGenerateCached(b *testing.B, m Module) {
+	dir, env := setup(b, m)
+	generate(b, dir, env, m)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, res := range generate(b, dir, env, m) {
+			if !res.Cached {
+				b.Fatalf("%s was not served from the cache", res.PkgPath)
+			}
+		}
+	}
+}
+
+// benchChange measures the path wire watch takes after a file is saved:
+// one package changes, and the module is generated again with a warm cache.
+func benchChange(b *testing.B, m Module) {
+	dir, env := setup(b, m)
+	generate(b, dir, env, m)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		if err := EditModule(dir, m, m.Packages-1, i+1); err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+		generate(b, dir, env, m)
+	}
+}
+
+// setup writes m to a temporary directory and gives the benchmark a cache
+// of its own. It returns the module directory and the environment to run
+// Wire with.
+func setup(b *testing.B, m Module) (string, []string) {
+	b.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		b.Fatal(err)
+	}
+	dir := b.TempDir()
+	if err := WriteModule(dir, filepath.Dir(wd), m); err != nil {
+		b.Fatal(err)
+	}
+	b.Setenv("TMPDIR", b.TempDir())
+	return dir, append(os.Environ(), "GOWORK=off")
+}
+
+// generate runs Generate on every package of the module m in dir and fails
+// the benchmark on errors.
+func generate(b *testing.B, dir string, env []string, m Module) []wire.GenerateResult {
+	b.Helper()
+	results, errs := wire.Generate(context.Background(), dir, env, []string{"./..."}, &wire.GenerateOptions{})
+	if len(errs) > 0 {
+		b.Fatalf("Generate: %v", errs)
+	}
+	if len(results) != m.Packages {
+		b.Fatalf("Generate returned %d results; want %d", len(results), m.Packages)
+	}
+	for _, res := range results {
+		if len(res.Errs) > 0 {
+			b.Fatalf("Generate %s: %v", res.PkgPath, res.Errs)
+		}
+	}
+	return results
+}
+
+// TestBaseline runs the benchmarks that -bench_baseline has results for and
+// fails for each one that is slower by more than -bench_threshold.
+func TestBaseline(t *testing.T) {
+	if *baselineFile == "" {
+		t.Skip("no -bench_baseline given")
+	}
+	f, err := os.Open(*baselineFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	baseline, err := ParseResults(f)
+	f.Close()
+	if err != nil {
+		t.Fatalf("reading %s: %v", *baselineFile, err)
+	}
+	current := make(map[string]float64)
+	for _, bench := range suite {
+		for _, m := range modules {
+			name := bench.name + "/" + m.String()
+			if _, ok := baseline[name]; !ok {
+				t.Logf("%s: no baseline", name)
+				continue
+			}
+			fn := bench.fn
+			res := testing.Benchmark(func(b *testing.B) { fn(b, m) })
+			if res.N == 0 {
+				t.Errorf("%s failed", name)
+				continue
+			}
+			current[name] = float64(res.NsPerOp())
+			t.Logf("%s: %d ns/op, baseline %.0f ns/op", name, res.NsPerOp(), baseline[name])
+		}
+	}
+	for _, r := range Compare(baseline, current, *threshold) {
+		t.Errorf("regression: %v", r)
+	}
+}
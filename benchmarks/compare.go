@@ -0,0 +1,106 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package benchmarks
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseResults reads the output of go test -bench and returns the time per
+// operation of each benchmark in nanoseconds, keyed by its name without the
+// GOMAXPROCS suffix. A benchmark reported more than once, as with -count,
+// keeps its fastest time, which is the least affected by noise.
+func ParseResults(r io.Reader) (map[string]float64, error) {
+	results := make(map[string]float64)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		name := trimProcs(fields[0])
+		for i := 2; i+1 < len(fields); i += 2 {
+			if fields[i+1] != "ns/op" {
+				continue
+			}
+			ns, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse %s: %v", fields[0], err)
+			}
+			if prev, ok := results[name]; !ok || ns < prev {
+				results[name] = ns
+			}
+			break
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// trimProcs removes the -N GOMAXPROCS suffix from a benchmark name.
+func trimProcs(name string) string {
+	i := strings.LastIndex(name, "-")
+	if i < 0 {
+		return name
+	}
+	if _, err := strconv.Atoi(name[i+1:]); err != nil {
+		return name
+	}
+	return name[:i]
+}
+
+// A Regression is a benchmark that got slower than its baseline.
+type Regression struct {
+	Name string
+	// Baseline and Current are times per operation in nanoseconds.
+	Baseline, Current float64
+}
+
+// Ratio returns how many times slower the benchmark got.
+func (r Regression) Ratio() float64 {
+	return r.Current / r.Baseline
+}
+
+// String describes the regression as a percentage.
+func (r Regression) String() string {
+	return fmt.Sprintf("%s: %.0f ns/op, was %.0f ns/op (%+.1f%%)", r.Name, r.Current, r.Baseline, (r.Ratio()-1)*100)
+}
+
+// Compare returns the benchmarks of current that are slower than in
+// baseline by more than threshold, a fraction such as 0.2 for 20%, sorted by
+// name. Benchmarks missing from either are ignored.
+func Compare(baseline, current map[string]float64, threshold float64) []Regression {
+	var regressions []Regression
+	for name, ns := range current {
+		base, ok := baseline[name]
+		if !ok || base <= 0 {
+			continue
+		}
+		if ns > base*(1+threshold) {
+			regressions = append(regressions, Regression{Name: name, Baseline: base, Current: ns})
+		}
+	}
+	sort.Slice(regressions, func(i, j int) bool {
+		return regressions[i].Name < regressions[j].Name
+	})
+	return regressions
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package benchmarks
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseResults(t *testing.T) {
+	out := strings.Join([]string{
+		"goos: linux",
+		"pkg: github.com/goforj/wire/benchmarks",
+		"BenchmarkLoad/5x10-8          \t      10\t 120000000 ns/op\t 5000 B/op\t 40 allocs/op",
+		"BenchmarkLoad/5x10-8          \t      10\t 100000000 ns/op\t 5000 B/op\t 40 allocs/op",
+		"BenchmarkChange/20x20         \t       3\t 350000000 ns/op",
+		"BenchmarkBroken-8 --- FAIL",
+		"PASS",
+	}, "\n")
+	got, err := ParseResults(strings.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{
+		"BenchmarkLoad/5x10":    100000000,
+		"BenchmarkChange/20x20": 350000000,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseResults = %v; want %v", got, want)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	baseline := map[string]float64{"A": 100, "B": 100, "C": 100}
+	current := map[string]float64{"A": 119, "B": 150, "C": 50, "D": 1000}
+	got := Compare(baseline, current, 0.2)
+	want := []Regression{{Name: "B", Baseline: 100, Current: 150}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Compare = %v; want %v", got, want)
+	}
+	if s := got[0].String(); s != "B: 150 ns/op, was 100 ns/op (+50.0%)" {
+		t.Errorf("String = %q", s)
+	}
+}
+
+func TestWriteModule(t *testing.T) {
+	dir := t.TempDir()
+	m := Module{Packages: 3, Providers: 2}
+	if err := WriteModule(dir, "/wire", m); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(filepath.Join(dir, "p002", "providers.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"p001.Set,", "func NewT0(dep *p001.T1) *T0 {", "func NewT1(dep *T0) *T1 {"} {
+		if !strings.Contains(string(before), want) {
+			t.Errorf("providers.go lacks %q:\n%s", want, before)
+		}
+	}
+	if err := EditModule(dir, m, 2, 1); err != nil {
+		t.Fatal(err)
+	}
+	after, err := os.ReadFile(filepath.Join(dir, "p002", "providers.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) == string(before) {
+		t.Error("EditModule did not change providers.go")
+	}
+	if err := EditModule(dir, m, 3, 1); err == nil {
+		t.Error("EditModule of a missing package succeeded")
+	}
+}
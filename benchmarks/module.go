@@ -0,0 +1,157 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package benchmarks measures Wire on synthetic modules, so that changes to
+// the loader and the cache can be checked for performance regressions.
+//
+// Run the benchmarks with
+//
+//	go test -run='^$' -bench=. ./benchmarks > old.txt
+//
+// and, after a change, compare against the saved results with
+//
+//	go test -run=TestBaseline -bench_baseline=old.txt ./benchmarks
+//
+// which fails if a benchmark got slower by more than -bench_threshold.
+package benchmarks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A Module describes a synthetic module: a chain of packages, each with a
+// chain of providers that starts from the last provider of the previous
+// package. Each package exports a provider set that includes the previous
+// package's set, and declares an injector for its last type, so every
+// package is generated and later packages solve larger graphs.
+type Module struct {
+	// Packages is the number of packages.
+	Packages int
+	// Providers is the number of providers in each package.
+	Providers int
+}
+
+// String returns a name for m suitable for a sub-benchmark.
+func (m Module) String() string {
+	return fmt.Sprintf("%dx%d", m.Packages, m.Providers)
+}
+
+// modulePath is the module path of synthetic modules.
+const modulePath = "example.com/bench"
+
+// WriteModule writes m as a Go module in dir. The module requires Wire
+// from the repository at wireRoot with a replace directive, so it builds
+// without network access.
+func WriteModule(dir, wireRoot string, m Module) error {
+	if m.Packages < 1 || m.Providers < 1 {
+		return fmt.Errorf("module %v must have at least one package and one provider", m)
+	}
+	goMod := strings.Join([]string{
+		"module " + modulePath,
+		"",
+		"go 1.19",
+		"",
+		"require github.com/goforj/wire v0.0.0",
+		"",
+		"replace github.com/goforj/wire => " + wireRoot,
+		"",
+	}, "\n")
+	if err := writeFile(filepath.Join(dir, "go.mod"), goMod); err != nil {
+		return err
+	}
+	for i := 0; i < m.Packages; i++ {
+		pkgDir := filepath.Join(dir, packageName(i))
+		if err := writeFile(filepath.Join(pkgDir, "providers.go"), providersFile(m, i, 0)); err != nil {
+			return err
+		}
+		if err := writeFile(filepath.Join(pkgDir, "wire.go"), injectorFile(m, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EditModule rewrites the providers of package pkg of the module m in dir
+// as an edit in an editor would, changing the body of a provider so that
+// the package must be generated again. Each rev gives different content.
+func EditModule(dir string, m Module, pkg, rev int) error {
+	if pkg < 0 || pkg >= m.Packages {
+		return fmt.Errorf("module %v has no package %d", m, pkg)
+	}
+	return writeFile(filepath.Join(dir, packageName(pkg), "providers.go"), providersFile(m, pkg, rev))
+}
+
+// packageName returns the name, and directory, of package i.
+func packageName(i int) string {
+	return fmt.Sprintf("p%03d", i)
+}
+
+// providersFile returns the source of the providers of package i. rev
+// changes the value the first provider stores.
+func providersFile(m Module, i, rev int) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "package %s\n\n", packageName(i))
+	sb.WriteString("import (\n")
+	if i > 0 {
+		fmt.Fprintf(&sb, "\t%q\n", modulePath+"/"+packageName(i-1))
+	}
+	sb.WriteString("\t\"github.com/goforj/wire\"\n)\n\n")
+	sb.WriteString("var Set = wire.NewSet(\n")
+	if i > 0 {
+		fmt.Fprintf(&sb, "\t%s.Set,\n", packageName(i-1))
+	}
+	for j := 0; j < m.Providers; j++ {
+		fmt.Fprintf(&sb, "\tNewT%d,\n", j)
+	}
+	sb.WriteString(")\n")
+	for j := 0; j < m.Providers; j++ {
+		fmt.Fprintf(&sb, "\ntype T%d struct {\n\tN   int\n\tDep interface{}\n}\n\n", j)
+		switch {
+		case j > 0:
+			fmt.Fprintf(&sb, "func NewT%d(dep *T%d) *T%d {\n\treturn &T%d{N: %d, Dep: dep}\n}\n", j, j-1, j, j, j)
+		case i > 0:
+			fmt.Fprintf(&sb, "func NewT0(dep *%s.T%d) *T0 {\n\treturn &T0{N: %d, Dep: dep}\n}\n", packageName(i-1), m.Providers-1, rev)
+		default:
+			fmt.Fprintf(&sb, "func NewT0() *T0 {\n\treturn &T0{N: %d}\n}\n", rev)
+		}
+	}
+	return sb.String()
+}
+
+// injectorFile returns the source of the wireinject file of package i.
+func injectorFile(m Module, i int) string {
+	return strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package " + packageName(i),
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		fmt.Sprintf("func Init() *T%d {", m.Providers-1),
+		"\tpanic(wire.Build(Set))",
+		"}",
+		"",
+	}, "\n")
+}
+
+// writeFile writes content to path, creating its directory.
+func writeFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0o644)
+}
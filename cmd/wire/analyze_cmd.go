@@ -0,0 +1,169 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/goforj/wire/internal/wire"
+	"github.com/google/subcommands"
+)
+
+type analyzeCmd struct {
+	packageFlags
+	outputFlags
+	top     int
+	profile profileFlags
+}
+
+// Name returns the subcommand name.
+func (*analyzeCmd) Name() string { return "analyze" }
+
+// Synopsis returns a short summary of the subcommand.
+func (*analyzeCmd) Synopsis() string {
+	return "report the critical construction path of each injector"
+}
+
+// Usage returns the help text for the subcommand.
+func (*analyzeCmd) Usage() string {
+	return `analyze [-tags tag,list] [-match regexp] [-top N] [-output_file_prefix prefix] [-output_file_name template] [packages]
+
+  Given one or more packages, analyze reports for each injector how long its
+  result takes to construct and the critical path: the most expensive chain
+  of providers the result depends on. Costs come from //wire:cost directives
+  in the providers' doc comments:
+
+	//wire:cost 50ms
+	func NewDB(cfg Config) (*sql.DB, error)
+
+  Providers without a directive, values, and fields cost nothing. The total
+  is the sum of all step costs, since generated code runs them one after
+  another; the critical path is what remains if independent providers were
+  run concurrently, so it shows where to optimize startup time. For an
+  injector that returns no value, built for the side effects of its
+  providers, the critical path is the most expensive chain of all its steps.
+
+  With -top N, analyze instead lists the N injectors with the most distinct
+  providers, the deepest chains of dependencies, and the most lines of
+  generated code, to find object graphs worth splitting. Pass the
+  -output_file_prefix and -output_file_name given to gen so that the
+  generated code is found.
+
+  If no packages are listed, it defaults to ".".
+`
+}
+
+// SetFlags registers flags for the subcommand.
+func (cmd *analyzeCmd) SetFlags(f *flag.FlagSet) {
+	cmd.packageFlags.addFlags(f)
+	cmd.outputFlags.addFlags(f)
+	f.IntVar(&cmd.top, "top", 0, "list the N largest injectors by providers, chain depth, and generated lines instead of their costs")
+	cmd.profile.addFlags(f)
+}
+
+// Execute runs the subcommand.
+func (cmd *analyzeCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
+	stop, err := cmd.profile.start(cmd.Name())
+	if err != nil {
+		log.Println(err)
+		return subcommands.ExitFailure
+	}
+	defer stop()
+	totalStart := time.Now()
+	ctx = cmd.profile.withTiming(ctx)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		log.Println("failed to get working directory: ", err)
+		return subcommands.ExitFailure
+	}
+	env := os.Environ()
+	pkgs, err := matchedPackages(ctx, wd, env, cmd.tags, cmd.match, cmd.skip, f)
+	if err != nil {
+		log.Println(err)
+		return subcommands.ExitFailure
+	}
+	loadStart := time.Now()
+	info, errs := wire.Load(ctx, wd, env, cmd.tags, pkgs)
+	logTiming(cmd.profile.timings, "wire.Load", loadStart)
+	if info != nil && cmd.top > 0 {
+		sizes := wire.InjectorSizes(info.Fset, sortedInjectors(info.Injectors), cmd.prefixFileName, cmd.fileName)
+		printTopSizes(os.Stdout, sizes, cmd.top)
+	} else if info != nil {
+		costs, costErrs := wire.InjectorCosts(info.Fset, sortedInjectors(info.Injectors))
+		errs = append(errs, costErrs...)
+		for i, ic := range costs {
+			if i > 0 {
+				fmt.Println()
+			}
+			printInjectorCost(info, ic)
+		}
+	}
+	if len(errs) > 0 {
+		logErrors(errs)
+		log.Println("error analyzing packages")
+		return subcommands.ExitFailure
+	}
+	logTiming(cmd.profile.timings, "total", totalStart)
+	return subcommands.ExitSuccess
+}
+
+// printInjectorCost prints an injector's costs and the steps on its critical
+// path, from the first one constructed to the injector's result, or to the
+// last step of the chain if the injector returns no value.
+func printInjectorCost(info *wire.Info, ic *wire.InjectorCost) {
+	fmt.Printf("%v\n", ic.Injector)
+	fmt.Printf("\tTotal: %v\n", ic.Total)
+	fmt.Printf("\tCritical path: %v\n", ic.Critical)
+	for _, i := range ic.CriticalPath {
+		step := ic.Injector.Steps[i]
+		fmt.Printf("\t\t%v\t%s <- %s\n", ic.Steps[i], wire.TypeString(step.Out), describeStep(info.Fset, step))
+	}
+}
+
+// printTopSizes prints the n largest of sizes by each measure to w, largest
+// first. Ties keep the order of sizes.
+func printTopSizes(w io.Writer, sizes []*wire.InjectorSize, n int) {
+	measures := []struct {
+		title string
+		unit  string
+		value func(*wire.InjectorSize) int
+	}{
+		{"Most providers", "providers", func(is *wire.InjectorSize) int { return is.Providers }},
+		{"Deepest chains", "steps", func(is *wire.InjectorSize) int { return is.Depth }},
+		{"Largest generated code", "lines", func(is *wire.InjectorSize) int { return is.Lines }},
+	}
+	for i, m := range measures {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		sorted := append([]*wire.InjectorSize(nil), sizes...)
+		sort.SliceStable(sorted, func(i, j int) bool { return m.value(sorted[i]) > m.value(sorted[j]) })
+		if len(sorted) > n {
+			sorted = sorted[:n]
+		}
+		fmt.Fprintf(w, "%s:\n", m.title)
+		for _, is := range sorted {
+			fmt.Fprintf(w, "\t%d %s\t%v\n", m.value(is), m.unit, is.Injector)
+		}
+	}
+}
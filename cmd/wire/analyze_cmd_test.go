@@ -0,0 +1,58 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/goforj/wire/internal/wire"
+)
+
+func TestPrintTopSizes(t *testing.T) {
+	size := func(name string, providers, depth, lines int) *wire.InjectorSize {
+		return &wire.InjectorSize{
+			Injector:  &wire.Injector{ImportPath: "example.com/app", FuncName: name},
+			Providers: providers,
+			Depth:     depth,
+			Lines:     lines,
+		}
+	}
+	sizes := []*wire.InjectorSize{
+		size("InitA", 3, 5, 10),
+		size("InitB", 8, 2, 10),
+		size("InitC", 1, 1, 40),
+	}
+	var buf bytes.Buffer
+	printTopSizes(&buf, sizes, 2)
+	want := strings.Join([]string{
+		"Most providers:",
+		"\t8 providers\t\"example.com/app\".InitB",
+		"\t3 providers\t\"example.com/app\".InitA",
+		"",
+		"Deepest chains:",
+		"\t5 steps\t\"example.com/app\".InitA",
+		"\t2 steps\t\"example.com/app\".InitB",
+		"",
+		"Largest generated code:",
+		"\t40 lines\t\"example.com/app\".InitC",
+		"\t10 lines\t\"example.com/app\".InitA",
+		"",
+	}, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("printTopSizes printed:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goforj/wire/internal/wire"
+	"github.com/google/subcommands"
+)
+
+type bindGenCmd struct {
+	headerFile string
+	tags       string
+	set        string
+	out        string
+	inputs     stringList
+	name       string
+	outputFile string
+	force      bool
+	stdout     bool
+}
+
+// Name returns the subcommand name.
+func (*bindGenCmd) Name() string { return "bind-gen" }
+
+// Synopsis returns a short summary of the subcommand.
+func (*bindGenCmd) Synopsis() string {
+	return "write an injector stub for a provider set from another package"
+}
+
+// Usage returns the help text for the subcommand.
+func (*bindGenCmd) Usage() string {
+	return `bind-gen -set import/path.Set -out type [-in type] [-name name] [-output_file file]
+
+  bind-gen writes a wireinject file to the package in the current directory
+  declaring one injector that builds -out from the provider set -set. Run
+  wire gen afterwards to generate the injector's implementation.
+
+  Types are written as an optional '*' followed by a type name, qualified by
+  import path unless the type is in the set's package or predeclared, e.g.
+  "*example.com/platform/db.Conn". The injector's arguments are given with
+  -in, which may be repeated or comma-separated; by default they are the
+  types the set needs to build -out but does not provide. Its cleanup and
+  error results follow from the providers it calls.
+
+  The injector is named Initialize<Type> unless -name is given, and the file
+  defaults to wire_<name>.go. Existing files are only replaced with -force.
+`
+}
+
+// SetFlags registers flags for the subcommand.
+func (cmd *bindGenCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&cmd.headerFile, "header_file", "", "path to file to insert as a header in the stub")
+	addTagsFlag(f, &cmd.tags)
+	f.StringVar(&cmd.set, "set", "", "provider set to build from, as import/path.VarName")
+	f.StringVar(&cmd.out, "out", "", "type the injector returns")
+	f.Var(&cmd.inputs, "in", "type of an injector argument; may be repeated or comma-separated")
+	f.StringVar(&cmd.name, "name", "", "name of the injector function")
+	f.StringVar(&cmd.outputFile, "output_file", "", "name of the file to write")
+	f.BoolVar(&cmd.force, "force", false, "replace the output file if it exists")
+	f.BoolVar(&cmd.stdout, "stdout", false, "print the stub instead of writing it")
+}
+
+// Execute runs the subcommand.
+func (cmd *bindGenCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
+	if cmd.set == "" || cmd.out == "" {
+		log.Println("bind-gen requires -set and -out")
+		return subcommands.ExitUsageError
+	}
+	if f.NArg() > 0 {
+		log.Println("bind-gen does not take package arguments; run it in the consumer package's directory")
+		return subcommands.ExitUsageError
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		log.Println("failed to get working directory: ", err)
+		return subcommands.ExitFailure
+	}
+	opts := &wire.BindGenOptions{
+		Set:    cmd.set,
+		Out:    cmd.out,
+		Inputs: cmd.inputs,
+		Name:   cmd.name,
+	}
+	if opts.Name == "" {
+		typeName := cmd.out[strings.LastIndexAny(cmd.out, "*./")+1:]
+		opts.Name = "Initialize" + strings.ToUpper(typeName[:1]) + typeName[1:]
+	}
+	if cmd.headerFile != "" {
+		opts.Header, err = ioutil.ReadFile(cmd.headerFile)
+		if err != nil {
+			log.Printf("failed to read header file %q: %v\n", cmd.headerFile, err)
+			return subcommands.ExitFailure
+		}
+	}
+	src, errs := wire.BindGen(ctx, wd, os.Environ(), cmd.tags, opts)
+	if len(errs) > 0 {
+		logErrors(errs)
+		log.Println("bind-gen failed")
+		return subcommands.ExitFailure
+	}
+	if cmd.stdout {
+		os.Stdout.Write(src)
+		return subcommands.ExitSuccess
+	}
+	name := cmd.outputFile
+	if name == "" {
+		name = "wire_" + strings.ToLower(opts.Name) + ".go"
+	}
+	path := filepath.Join(wd, name)
+	if _, err := os.Stat(path); err == nil && !cmd.force {
+		log.Printf("%s already exists; use -force to replace it\n", path)
+		return subcommands.ExitFailure
+	}
+	if err := ioutil.WriteFile(path, src, 0666); err != nil {
+		log.Printf("failed to write %s: %v\n", path, err)
+		return subcommands.ExitFailure
+	}
+	infof("wrote %s; run wire gen to generate %s\n", path, opts.Name)
+	return subcommands.ExitSuccess
+}
@@ -19,13 +19,19 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"strings"
 
 	"github.com/goforj/wire/internal/wire"
 	"github.com/google/subcommands"
 )
 
 type cacheCmd struct {
-	clear bool
+	clear      bool
+	invalidate bool
+	warm       bool
+	examples   bool
+	generate   generateFlags
 }
 
 // Name returns the subcommand name.
@@ -38,15 +44,30 @@ func (*cacheCmd) Synopsis() string {
 
 // Usage returns the help text for the subcommand.
 func (*cacheCmd) Usage() string {
-	return `cache [-clear]
+	return `cache [-clear] [-invalidate import paths] [-warm [packages]]
 
   By default, prints the cache directory. With -clear, removes all cache files.
+
+  With -invalidate, removes only the cached output of the packages whose
+  import paths match the arguments, which may use "..." wildcards as in
+  example.com/app/..., so that the next run generates them again. Run it
+  after rewriting those packages with a tool whose changes the cache may not
+  notice.
+
+  With -warm, runs generation for the given packages, defaulting to ".", to
+  fill the cache without writing any files. Run it from the directory and
+  with the flags later runs of wire gen will use, such as when building a CI
+  image or devcontainer, so that those runs are cache hits.
 `
 }
 
 // SetFlags registers flags for the subcommand.
 func (cmd *cacheCmd) SetFlags(f *flag.FlagSet) {
 	f.BoolVar(&cmd.clear, "clear", false, "remove all cached data")
+	f.BoolVar(&cmd.invalidate, "invalidate", false, "remove the cached data of the packages matching the import path arguments")
+	f.BoolVar(&cmd.warm, "warm", false, "fill the cache by generating the given packages without writing files")
+	f.BoolVar(&cmd.examples, "examples", false, "with -warm, also cache wire_example_test.go output, as wire gen -examples does")
+	cmd.generate.addFlags(f)
 }
 
 // Execute runs the subcommand.
@@ -56,9 +77,71 @@ func (cmd *cacheCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...inter
 			log.Printf("failed to clear cache: %v\n", err)
 			return subcommands.ExitFailure
 		}
-		log.Printf("cleared cache at %s\n", wire.CacheDir())
+		infof("cleared cache at %s\n", wire.CacheDir())
+		return subcommands.ExitSuccess
+	}
+	if cmd.invalidate {
+		if f.NArg() == 0 {
+			log.Println("-invalidate requires import paths")
+			return subcommands.ExitUsageError
+		}
+		if err := wire.InvalidatePattern(ctx, f.Args()); err != nil {
+			log.Printf("failed to invalidate cache: %v\n", err)
+			return subcommands.ExitFailure
+		}
+		infof("invalidated %s in cache at %s\n", strings.Join(f.Args(), " "), wire.CacheDir())
 		return subcommands.ExitSuccess
 	}
+	if cmd.warm {
+		return cmd.warmCache(ctx, f)
+	}
+	if f.NArg() > 0 {
+		log.Println("packages may only be given with -invalidate or -warm")
+		return subcommands.ExitUsageError
+	}
 	fmt.Println(wire.CacheDir())
 	return subcommands.ExitSuccess
 }
+
+// warmCache generates the packages named by f's arguments, discarding the
+// output, so that the results are cached.
+func (cmd *cacheCmd) warmCache(ctx context.Context, f *flag.FlagSet) subcommands.ExitStatus {
+	wd, err := os.Getwd()
+	if err != nil {
+		log.Println("failed to get working directory: ", err)
+		return subcommands.ExitFailure
+	}
+	opts, err := cmd.generate.options()
+	if err != nil {
+		log.Println(err)
+		return subcommands.ExitFailure
+	}
+	opts.Examples = cmd.examples
+	env := os.Environ()
+	pkgs, err := matchedPackages(ctx, wd, env, cmd.generate.tags, cmd.generate.match, cmd.generate.skip, f)
+	if err != nil {
+		log.Println(err)
+		return subcommands.ExitFailure
+	}
+	outs, errs := wire.Generate(ctx, wd, env, pkgs, opts)
+	if len(errs) > 0 {
+		logErrors(errs.Errors())
+		log.Println("warming cache failed")
+		return subcommands.ExitFailure
+	}
+	success := true
+	for _, out := range outs {
+		if len(out.Errs) > 0 {
+			logErrors(out.Errs.Errors())
+			log.Printf("%s: generate failed\n", out.PkgPath)
+			success = false
+		}
+	}
+	if !success {
+		// Failed packages are not cached, so later runs will load again.
+		log.Println("warming cache failed")
+		return subcommands.ExitFailure
+	}
+	infof("warmed cache at %s for %d packages\n", wire.CacheDir(), len(outs))
+	return subcommands.ExitSuccess
+}
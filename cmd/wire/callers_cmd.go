@@ -0,0 +1,127 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/goforj/wire/internal/wire"
+	"github.com/google/subcommands"
+)
+
+type callersCmd struct {
+	packageFlags
+	scope   string
+	profile profileFlags
+}
+
+// Name returns the subcommand name.
+func (*callersCmd) Name() string { return "callers" }
+
+// Synopsis returns a short summary of the subcommand.
+func (*callersCmd) Synopsis() string {
+	return "list the call sites of each injector across the module"
+}
+
+// Usage returns the help text for the subcommand.
+func (*callersCmd) Usage() string {
+	return `callers [-tags tag,list] [-match regexp] [-scope patterns] [packages]
+
+  Given one or more packages, callers finds every call to their injectors in
+  the packages of the enclosing module, including tests, and prints the
+  position of each call under the injector it calls. Run it before changing
+  an injector's signature or inputs to see which code is affected.
+
+  -scope replaces the packages searched for calls with the given space
+  separated patterns, relative to the current directory.
+
+  If no packages are listed, it defaults to ".".
+`
+}
+
+// SetFlags registers flags for the subcommand.
+func (cmd *callersCmd) SetFlags(f *flag.FlagSet) {
+	cmd.packageFlags.addFlags(f)
+	f.StringVar(&cmd.scope, "scope", "", "space separated patterns of the packages to search for calls instead of the whole module")
+	cmd.profile.addFlags(f)
+}
+
+// Execute runs the subcommand.
+func (cmd *callersCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
+	stop, err := cmd.profile.start(cmd.Name())
+	if err != nil {
+		log.Println(err)
+		return subcommands.ExitFailure
+	}
+	defer stop()
+	totalStart := time.Now()
+	ctx = cmd.profile.withTiming(ctx)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		log.Println("failed to get working directory: ", err)
+		return subcommands.ExitFailure
+	}
+	env := os.Environ()
+	pkgs, err := matchedPackages(ctx, wd, env, cmd.tags, cmd.match, cmd.skip, f)
+	if err != nil {
+		log.Println(err)
+		return subcommands.ExitFailure
+	}
+	scopeDir, scope := wd, strings.Fields(cmd.scope)
+	if len(scope) == 0 {
+		if scopeDir, err = moduleRoot(wd, env); err != nil {
+			log.Printf("failed to find the module root: %v\n", err)
+			return subcommands.ExitFailure
+		}
+		scope = []string{"./..."}
+	}
+	loadStart := time.Now()
+	info, errs := wire.Load(ctx, wd, env, cmd.tags, pkgs)
+	logTiming(cmd.profile.timings, "wire.Load", loadStart)
+	if len(errs) > 0 {
+		logErrors(errs)
+		log.Println("error loading packages")
+		return subcommands.ExitFailure
+	}
+	callersStart := time.Now()
+	calls, errs := wire.InjectorCallers(ctx, scopeDir, env, cmd.tags, sortedInjectors(info.Injectors), scope)
+	logTiming(cmd.profile.timings, "wire.InjectorCallers", callersStart)
+	if len(errs) > 0 {
+		logErrors(errs)
+		log.Println("error finding callers")
+		return subcommands.ExitFailure
+	}
+	for i, ic := range calls {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%v\n", ic.Injector)
+		if len(ic.Calls) == 0 {
+			fmt.Println("\tno callers")
+		}
+		for _, pos := range ic.Calls {
+			fmt.Printf("\t%v\n", pos)
+		}
+	}
+	logTiming(cmd.profile.timings, "total", totalStart)
+	return subcommands.ExitSuccess
+}
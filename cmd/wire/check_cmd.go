@@ -17,6 +17,7 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"time"
@@ -26,10 +27,23 @@ import (
 )
 
 type checkCmd struct {
-	tags    string
-	profile profileFlags
+	packageFlags
+	outputFlags
+	lint             bool
+	warningsAsErrors bool
+	allowErrors      bool
+	syntax           bool
+	layers           string
+	profile          profileFlags
 }
 
+// Exit statuses of the check subcommand, in increasing severity.
+const (
+	checkOK       subcommands.ExitStatus = 0
+	checkWarnings subcommands.ExitStatus = 1
+	checkErrors   subcommands.ExitStatus = 2
+)
+
 // Name returns the subcommand name.
 func (*checkCmd) Name() string { return "check" }
 
@@ -40,10 +54,54 @@ func (*checkCmd) Synopsis() string {
 
 // Usage returns the help text for the subcommand.
 func (*checkCmd) Usage() string {
-	return `check [-tags tag,list] [packages]
+	return `check [-tags tag,list] [-match regexp] [-lint] [-warnings_as_errors] [-allow_errors] [-syntax] [-layers file] [packages]
 
   Given one or more packages, check prints any type-checking or Wire errors
-  found with top-level variable provider sets or injector functions.
+  found with top-level variable provider sets or injector functions. It also
+  reports generated wire_gen.go files left in packages that no longer
+  declare any injectors; remove those with wire clean.
+
+  check also prints warnings for problems that do not prevent generating
+  code: injectors that use providers whose doc comment has a "Deprecated:"
+  paragraph, uses of provider sets redirected with wire.Redirect, provider
+  sets passed by a bare name that a package imported by the same file also
+  declares a provider set under, such as ProviderSet where repo.ProviderSet
+  was likely meant, and, with -lint, parameters of providers in the given
+  packages that the injectors call to no effect: parameters the provider
+  never uses, and parameters every injector passes the zero value, such as
+  wire.Value(Config{}).
+
+  Normally a package that fails to type-check is only reported with its
+  errors. With -allow_errors, check reports them and still analyzes the
+  package, printing the Wire errors of the provider sets and injectors that
+  contain no errors themselves. This is meant for editors, which check code
+  while it is being edited.
+
+  With -layers, check also reads a JSON file declaring layers of packages
+  and the layers each may depend on, and reports every provider that an
+  injector calls with a value from a layer its own layer may not depend on:
+
+    {
+      "layers": [
+        {"name": "handlers", "packages": ["example.com/app/handlers/..."]},
+        {"name": "services", "packages": ["example.com/app/services/..."]}
+      ],
+      "allow": {"handlers": ["services"]}
+    }
+
+  Packages in no layer are not checked, and a layer may always depend on
+  itself.
+
+  With -syntax, check only parses the packages' files, without
+  type-checking, and reports injectors that break the rules visible in the
+  syntax: files without the wireinject build tag, bodies that are more than
+  a single wire.Build call and a return, and result lists that are not
+  empty, a lone error, or a value optionally followed by a cleanup function
+  and an error. It is fast enough to run before every commit; run check
+  without -syntax for the rest.
+
+  The exit status is 2 if there are errors. Warnings do not fail the check
+  unless -warnings_as_errors is set, in which case the exit status is 1.
 
   If no packages are listed, it defaults to ".".
 `
@@ -51,34 +109,95 @@ func (*checkCmd) Usage() string {
 
 // SetFlags registers flags for the subcommand.
 func (cmd *checkCmd) SetFlags(f *flag.FlagSet) {
-	f.StringVar(&cmd.tags, "tags", "", "append build tags to the default wirebuild")
+	cmd.packageFlags.addFlags(f)
+	cmd.outputFlags.addFlags(f)
+	f.BoolVar(&cmd.lint, "lint", false, "warn about provider parameters that have no effect")
+	f.BoolVar(&cmd.warningsAsErrors, "warnings_as_errors", false, "fail with exit status 1 if there are warnings")
+	f.BoolVar(&cmd.allowErrors, "allow_errors", false, "analyze packages that have type errors on a best-effort basis")
+	f.BoolVar(&cmd.syntax, "syntax", false, "only check the structure of injectors, without type-checking")
+	f.StringVar(&cmd.layers, "layers", "", "JSON file declaring package layers and their allowed dependencies to check the injectors against")
 	cmd.profile.addFlags(f)
 }
 
 // Execute runs the subcommand.
 func (cmd *checkCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
-	stop, err := cmd.profile.start()
+	stop, err := cmd.profile.start(cmd.Name())
 	if err != nil {
 		log.Println(err)
-		return subcommands.ExitFailure
+		return checkErrors
 	}
 	defer stop()
 	totalStart := time.Now()
-	ctx = withTiming(ctx, cmd.profile.timings)
+	ctx = cmd.profile.withTiming(ctx)
 
 	wd, err := os.Getwd()
 	if err != nil {
 		log.Println("failed to get working directory: ", err)
-		return subcommands.ExitFailure
+		return checkErrors
+	}
+	env := os.Environ()
+	pkgs, err := matchedPackages(ctx, wd, env, cmd.tags, cmd.match, cmd.skip, f)
+	if err != nil {
+		log.Println(err)
+		return checkErrors
+	}
+	if cmd.syntax {
+		syntaxStart := time.Now()
+		errs := wire.CheckSyntax(ctx, wd, env, cmd.tags, pkgs)
+		logTiming(cmd.profile.timings, "wire.CheckSyntax", syntaxStart)
+		if len(errs) > 0 {
+			logErrors(errs)
+			log.Println("error checking injectors")
+			return checkErrors
+		}
+		logTiming(cmd.profile.timings, "total", totalStart)
+		return checkOK
 	}
 	loadStart := time.Now()
-	_, errs := wire.Load(ctx, wd, os.Environ(), cmd.tags, packages(f))
+	info, errs := wire.LoadWithOptions(ctx, wd, env, cmd.tags, pkgs, &wire.LoadOptions{AllowErrors: cmd.allowErrors, Lightweight: true})
 	logTiming(cmd.profile.timings, "wire.Load", loadStart)
+	var warnings []error
+	if info != nil {
+		for _, w := range info.Warnings {
+			if w.Code == wire.CodeUnusedParam && !cmd.lint {
+				continue
+			}
+			warnings = append(warnings, w)
+		}
+	}
+	if cmd.warningsAsErrors {
+		logErrors(warnings)
+	} else {
+		for _, w := range warnings {
+			infof("warning: %s\n", relativePaths(w.Error()))
+		}
+	}
+	if cmd.layers != "" && info != nil {
+		cfg, err := wire.ReadLayerConfig(cmd.layers)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			errs = append(errs, wire.CheckLayers(info.Fset, info.Injectors, cfg)...)
+		}
+	}
+	orphanStart := time.Now()
+	orphaned, err := wire.OrphanedFiles(ctx, wd, env, cmd.tags, pkgs, cmd.prefixFileName, cmd.fileName)
+	logTiming(cmd.profile.timings, "wire.OrphanedFiles", orphanStart)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, path := range orphaned {
+		errs = append(errs, fmt.Errorf("%s: generated file is orphaned: its package no longer declares any injectors (remove it with wire clean)", path))
+	}
 	if len(errs) > 0 {
 		logErrors(errs)
 		log.Println("error loading packages")
-		return subcommands.ExitFailure
+		return checkErrors
+	}
+	if cmd.warningsAsErrors && len(warnings) > 0 {
+		log.Println("warnings treated as errors")
+		return checkWarnings
 	}
 	logTiming(cmd.profile.timings, "total", totalStart)
-	return subcommands.ExitSuccess
+	return checkOK
 }
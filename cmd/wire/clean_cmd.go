@@ -0,0 +1,99 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/goforj/wire/internal/wire"
+	"github.com/google/subcommands"
+)
+
+type cleanCmd struct {
+	packageFlags
+	outputFlags
+	dryRun bool
+}
+
+// Name returns the subcommand name.
+func (*cleanCmd) Name() string { return "clean" }
+
+// Synopsis returns a short summary of the subcommand.
+func (*cleanCmd) Synopsis() string {
+	return "remove generated wire_gen.go files"
+}
+
+// Usage returns the help text for the subcommand.
+func (*cleanCmd) Usage() string {
+	return `clean [-dry_run] [-output_file_prefix prefix] [-output_file_name template] [-match regexp] [packages]
+
+  Given one or more packages, clean removes the wire_gen.go file (and any
+  generated examples and test injectors) from each. Only files marked as
+  generated by Wire are removed. Packages are found even if their injectors
+  no longer exist, which makes clean useful for orphaned output left behind
+  by branch switches or renames.
+
+  If no packages are listed, it defaults to ".". With -dry_run, clean only
+  prints the files it would remove.
+`
+}
+
+// SetFlags registers flags for the subcommand.
+func (cmd *cleanCmd) SetFlags(f *flag.FlagSet) {
+	cmd.packageFlags.addFlags(f)
+	cmd.outputFlags.addFlags(f)
+	f.BoolVar(&cmd.dryRun, "dry_run", false, "print the files that would be removed without removing them")
+}
+
+// Execute runs the subcommand.
+func (cmd *cleanCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
+	wd, err := os.Getwd()
+	if err != nil {
+		log.Println("failed to get working directory: ", err)
+		return subcommands.ExitFailure
+	}
+	env := os.Environ()
+	pkgs, err := matchedPackages(ctx, wd, env, cmd.tags, cmd.match, cmd.skip, f)
+	if err != nil {
+		log.Println(err)
+		return subcommands.ExitFailure
+	}
+	files, err := wire.GeneratedFiles(ctx, wd, env, cmd.tags, pkgs, cmd.prefixFileName, cmd.fileName)
+	if err != nil {
+		log.Println(err)
+		return subcommands.ExitFailure
+	}
+	success := true
+	for _, path := range files {
+		if cmd.dryRun {
+			fmt.Println(path)
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			log.Printf("failed to remove %s: %v\n", path, err)
+			success = false
+			continue
+		}
+		infof("removed %s\n", path)
+	}
+	if !success {
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
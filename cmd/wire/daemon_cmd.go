@@ -0,0 +1,445 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/goforj/wire/internal/wire"
+	"github.com/google/subcommands"
+)
+
+// daemonSocketEnv names the environment variable holding the path of the
+// daemon's socket, for both wire daemon and the commands run with -daemon.
+const daemonSocketEnv = "WIRE_DAEMON_SOCKET"
+
+// daemonSocket returns the path of the daemon's socket: the value of
+// WIRE_DAEMON_SOCKET, or daemon.sock in a directory that only the user may
+// access, $XDG_RUNTIME_DIR/wire or wire in the user cache directory, which
+// it creates if needed. The daemon runs requests as its user, with the
+// directory and environment they name, and clients send it their
+// environment, so neither may reach another user's socket.
+func daemonSocket() (string, error) {
+	if path := os.Getenv(daemonSocketEnv); path != "" {
+		return path, nil
+	}
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir != "" {
+		dir = filepath.Join(dir, "wire")
+	} else {
+		cache, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(cache, "wire")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() || !ownedByUser(info) || info.Mode().Perm()&0077 != 0 {
+		return "", fmt.Errorf("%s must be a directory that only you can access", dir)
+	}
+	return filepath.Join(dir, "daemon.sock"), nil
+}
+
+// checkDaemonSocket returns an error if a file at path is not a socket
+// owned by the user. A missing file is fine.
+func checkDaemonSocket(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 || !ownedByUser(info) {
+		return fmt.Errorf("%s is not a socket owned by you", path)
+	}
+	return nil
+}
+
+// daemonMethods maps the commands that -daemon routes through the daemon to
+// their JSON-RPC methods.
+var daemonMethods = map[string]string{
+	"gen":   "Wire.Generate",
+	"check": "Wire.Check",
+	"show":  "Wire.Show",
+}
+
+// daemonCommands creates the commands the daemon runs, by name. Each
+// request gets a new command, so that no flag values leak between them.
+var daemonCommands = map[string]func() subcommands.Command{
+	"gen":   func() subcommands.Command { return &genCmd{} },
+	"check": func() subcommands.Command { return &checkCmd{} },
+	"show":  func() subcommands.Command { return &showCmd{} },
+}
+
+type daemonCmd struct {
+	socket string
+}
+
+// Name returns the subcommand name.
+func (*daemonCmd) Name() string { return "daemon" }
+
+// Synopsis returns a short summary of the subcommand.
+func (*daemonCmd) Synopsis() string {
+	return "serve gen, check, and show over a JSON-RPC socket"
+}
+
+// Usage returns the help text for the subcommand.
+func (*daemonCmd) Usage() string {
+	return `daemon [-socket path]
+
+  daemon keeps a wire process running and serves JSON-RPC 1.0 requests on a
+  unix socket, so that repeated runs from editors and CI skip process
+  startup and reuse the warm cache. It also keeps the packages it loads,
+  type-checked, and reuses them for later requests of the same packages in
+  the same directory and environment until their files change.
+
+  It listens on -socket, or on the path in WIRE_DAEMON_SOCKET, or on
+  daemon.sock in $XDG_RUNTIME_DIR/wire or in wire in the user cache
+  directory. Requests run as the daemon's user, so the socket is only
+  accessible to that user, and neither the daemon nor its clients use a
+  socket that another user owns.
+
+  The methods Wire.Generate, Wire.Check, and Wire.Show take
+  {"Dir": wd, "Env": [...], "Args": [...]} and run gen, check, or show with
+  those arguments in that directory and environment, one request at a time.
+  They reply {"Stdout": ..., "Stderr": ..., "ExitCode": n}.
+  Wire.Invalidate takes {"Patterns": [...]} and removes the cached output of
+  the matching packages, as wire cache -invalidate does, along with the
+  packages the daemon keeps loaded that include them.
+
+  Every command accepts -daemon (also before the command name), which runs
+  gen, check, and show through the daemon when one is listening, and locally
+  otherwise.
+`
+}
+
+// SetFlags registers flags for the subcommand.
+func (cmd *daemonCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&cmd.socket, "socket", "", "path of the unix socket to listen on (default $"+daemonSocketEnv+" or a socket in $XDG_RUNTIME_DIR/wire or the user cache directory)")
+}
+
+// Execute runs the subcommand.
+func (cmd *daemonCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
+	if f.NArg() > 0 {
+		log.Println("daemon does not accept arguments")
+		return subcommands.ExitUsageError
+	}
+	path := cmd.socket
+	if path == "" {
+		var err error
+		if path, err = daemonSocket(); err != nil {
+			log.Printf("failed to find the daemon socket: %v\n", err)
+			return subcommands.ExitFailure
+		}
+	}
+	if err := checkDaemonSocket(path); err != nil {
+		log.Printf("refusing to listen: %v\n", err)
+		return subcommands.ExitFailure
+	}
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		log.Printf("a daemon is already listening on %s\n", path)
+		return subcommands.ExitFailure
+	}
+	// Nothing listens, so a file left at path is stale.
+	os.Remove(path)
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		log.Printf("failed to listen on %s: %v\n", path, err)
+		return subcommands.ExitFailure
+	}
+	defer os.Remove(path)
+	if err := os.Chmod(path, 0600); err != nil {
+		log.Printf("failed to restrict access to %s: %v\n", path, err)
+		return subcommands.ExitFailure
+	}
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+	stopped := make(chan struct{})
+	go func() {
+		<-stop
+		close(stopped)
+		ln.Close()
+	}()
+
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("Wire", newDaemonService(ctx)); err != nil {
+		log.Println(err)
+		return subcommands.ExitFailure
+	}
+	infof("listening on %s\n", path)
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			select {
+			case <-stopped:
+				return subcommands.ExitSuccess
+			default:
+			}
+			log.Printf("failed to accept a connection: %v\n", err)
+			return subcommands.ExitFailure
+		}
+		go srv.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
+
+// DaemonArgs are the arguments of the daemon's Generate, Check, and Show
+// methods.
+type DaemonArgs struct {
+	// Dir is the working directory to run the command in.
+	Dir string
+	// Env is the environment to run the command with.
+	Env []string
+	// Args are the command's flags and arguments, after its name.
+	Args []string
+}
+
+// InvalidateArgs are the arguments of the daemon's Invalidate method.
+type InvalidateArgs struct {
+	// Patterns are the import paths to invalidate, which may use "..."
+	// wildcards.
+	Patterns []string
+}
+
+// DaemonReply is the reply of every method of the daemon.
+type DaemonReply struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// DaemonService implements the daemon's JSON-RPC methods. Commands change
+// process-wide state, such as the working directory, the environment, and
+// the standard output, so requests run one at a time.
+type DaemonService struct {
+	ctx context.Context
+	mu  sync.Mutex
+	// warm keeps the packages that requests load for later requests.
+	warm *wire.WarmLoads
+}
+
+// newDaemonService returns a DaemonService running its requests with ctx.
+func newDaemonService(ctx context.Context) *DaemonService {
+	return &DaemonService{ctx: ctx, warm: wire.NewWarmLoads()}
+}
+
+// Generate runs wire gen.
+func (s *DaemonService) Generate(args DaemonArgs, reply *DaemonReply) error {
+	return s.run("gen", args, reply)
+}
+
+// Check runs wire check.
+func (s *DaemonService) Check(args DaemonArgs, reply *DaemonReply) error {
+	return s.run("check", args, reply)
+}
+
+// Show runs wire show.
+func (s *DaemonService) Show(args DaemonArgs, reply *DaemonReply) error {
+	return s.run("show", args, reply)
+}
+
+// Invalidate removes the cached output of the packages matching
+// args.Patterns, and drops the loaded packages that include them.
+func (s *DaemonService) Invalidate(args InvalidateArgs, reply *DaemonReply) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(args.Patterns) == 0 {
+		return errors.New("invalidate requires import paths")
+	}
+	s.warm.Invalidate(args.Patterns)
+	if err := wire.InvalidatePattern(s.ctx, args.Patterns); err != nil {
+		reply.Stderr = fmt.Sprintf("wire: failed to invalidate cache: %v\n", err)
+		reply.ExitCode = int(subcommands.ExitFailure)
+	}
+	return nil
+}
+
+// run runs the command name in the directory and environment of args,
+// capturing its output in reply.
+func (s *DaemonService) run(name string, args DaemonArgs, reply *DaemonReply) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	start := time.Now()
+	restore, err := enterRequest(args.Dir, args.Env)
+	if err != nil {
+		return err
+	}
+	defer restore()
+	out, err := os.CreateTemp("", "wire-daemon-stdout")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	var stderr bytes.Buffer
+	stdout := os.Stdout
+	os.Stdout = out
+	log.SetOutput(&stderr)
+	// Flags such as -log_level set package variables; start each request
+	// from their defaults, and restore the daemon's own afterwards.
+	level, wasOffline, paths := currentLevel, offline, errorPaths
+	currentLevel, offline, errorPaths, useDaemon = levelNormal, false, defaultErrorPaths, false
+	f := flag.NewFlagSet(name, flag.ContinueOnError)
+	f.SetOutput(&stderr)
+	cmd := leveledCmd{daemonCommands[name]()}
+	cmd.SetFlags(f)
+	status := subcommands.ExitUsageError
+	if err := f.Parse(args.Args); err == nil {
+		// Not cmd.Execute, which would send a request with -daemon back
+		// to this daemon.
+		applyOffline()
+		status = cmd.Command.Execute(wire.WithWarmLoads(withDebug(s.ctx), s.warm), f)
+	}
+	os.Stdout = stdout
+	log.SetOutput(os.Stderr)
+	currentLevel, offline, errorPaths, useDaemon = level, wasOffline, paths, false
+	if _, err := out.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(out)
+	if err != nil {
+		return err
+	}
+	reply.Stdout, reply.Stderr, reply.ExitCode = string(data), stderr.String(), int(status)
+	verbosef("%s %s in %s: exit %d (%s)\n", name, strings.Join(args.Args, " "), args.Dir, status, formatDuration(time.Since(start)))
+	return nil
+}
+
+// enterRequest changes to dir and replaces the environment with env, and
+// returns a function undoing both.
+func enterRequest(dir string, env []string) (func(), error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chdir(dir); err != nil {
+		return nil, err
+	}
+	saved := os.Environ()
+	setEnv(env)
+	return func() {
+		setEnv(saved)
+		os.Chdir(wd)
+	}, nil
+}
+
+// setEnv replaces the environment with env.
+func setEnv(env []string) {
+	os.Clearenv()
+	for _, kv := range env {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			os.Setenv(k, v)
+		}
+	}
+}
+
+// useDaemon is set by the -daemon flag of every command.
+var useDaemon bool
+
+// addDaemonFlags registers the -daemon flag. It defaults to its current
+// value, so that a command keeps -daemon given before its name.
+func addDaemonFlags(f *flag.FlagSet) {
+	f.BoolVar(&useDaemon, "daemon", useDaemon, "run gen, check, and show through wire daemon if one is listening")
+}
+
+// runInDaemon runs the command name through the daemon, if -daemon is set,
+// the daemon serves the command, and one is listening. It reports whether
+// it did, and the command's exit status if so.
+func runInDaemon(name string) (subcommands.ExitStatus, bool) {
+	method, ok := daemonMethods[name]
+	if !useDaemon || !ok {
+		return 0, false
+	}
+	path, err := daemonSocket()
+	if err != nil {
+		verbosef("no daemon socket (%v), running %s locally\n", err, name)
+		return 0, false
+	}
+	if err := checkDaemonSocket(path); err != nil {
+		log.Printf("not using the daemon: %v; running %s locally\n", err, name)
+		return 0, false
+	}
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		verbosef("no daemon listening on %s, running %s locally\n", path, name)
+		return 0, false
+	}
+	client := jsonrpc.NewClient(conn)
+	defer client.Close()
+	wd, err := os.Getwd()
+	if err != nil {
+		return 0, false
+	}
+	args := DaemonArgs{Dir: wd, Env: os.Environ(), Args: daemonArgs(name, os.Args[1:])}
+	var reply DaemonReply
+	if err := client.Call(method, args, &reply); err != nil {
+		log.Printf("daemon on %s failed, running %s locally: %v\n", path, name, err)
+		return 0, false
+	}
+	os.Stdout.WriteString(reply.Stdout)
+	os.Stderr.WriteString(reply.Stderr)
+	return subcommands.ExitStatus(reply.ExitCode), true
+}
+
+// daemonArgs returns the arguments to send to the daemon for the command
+// name, given the process arguments: those after the command name, without
+// -daemon, preceded by the flags given before the command name. If the
+// command is not named, as when wire runs gen by default, every argument is
+// the command's.
+func daemonArgs(name string, osArgs []string) []string {
+	args := []string{"-log_level=" + currentLevel.String(), "-error_paths=" + errorPaths}
+	if offline {
+		args = append(args, "-offline")
+	}
+	rest := osArgs
+	for i, arg := range osArgs {
+		if arg == name {
+			rest = osArgs[i+1:]
+			break
+		}
+	}
+	for _, arg := range rest {
+		switch arg {
+		case "-daemon", "--daemon", "-daemon=true", "--daemon=true":
+			continue
+		}
+		args = append(args, arg)
+	}
+	return args
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDaemonArgs(t *testing.T) {
+	level, wasOffline, paths := currentLevel, offline, errorPaths
+	t.Cleanup(func() { currentLevel, offline, errorPaths = level, wasOffline, paths })
+
+	tests := []struct {
+		name       string
+		level      logLevel
+		offline    bool
+		errorPaths string
+		osArgs     []string
+		want       []string
+	}{
+		{"gen", levelNormal, false, "module", []string{"gen", "-daemon", "./..."}, []string{"-log_level=normal", "-error_paths=module", "./..."}},
+		{"gen", levelNormal, false, "module", []string{"gen", "--daemon=true", "-tags=foo", "-daemon=true", "--daemon", "./a"}, []string{"-log_level=normal", "-error_paths=module", "-tags=foo", "./a"}},
+		// Flags before the command name are sent as the values they set.
+		{"check", levelDebug, true, "module", []string{"-daemon", "-log_level=debug", "-offline", "check", "./a"}, []string{"-log_level=debug", "-error_paths=module", "-offline", "./a"}},
+		{"show", levelQuiet, false, "module", []string{"-log_level", "quiet", "show", "-daemon"}, []string{"-log_level=quiet", "-error_paths=module"}},
+		{"gen", levelNormal, false, "absolute", []string{"-daemon", "-error_paths=absolute", "gen", "./a"}, []string{"-log_level=normal", "-error_paths=absolute", "./a"}},
+		// wire runs gen when no command is named.
+		{"gen", levelNormal, false, "module", []string{"-daemon", "./..."}, []string{"-log_level=normal", "-error_paths=module", "./..."}},
+		{"gen", levelVerbose, false, "wd", []string{"-log_level", "verbose", "-error_paths", "wd", "-daemon", "./a", "./b"}, []string{"-log_level=verbose", "-error_paths=wd", "-log_level", "verbose", "-error_paths", "wd", "./a", "./b"}},
+	}
+	for _, test := range tests {
+		currentLevel, offline, errorPaths = test.level, test.offline, test.errorPaths
+		if got := daemonArgs(test.name, test.osArgs); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("daemonArgs(%q, %q) = %q; want %q", test.name, test.osArgs, got, test.want)
+		}
+	}
+}
+
+func TestAddDaemonFlagsKeepsValue(t *testing.T) {
+	saved := useDaemon
+	t.Cleanup(func() { useDaemon = saved })
+
+	// -daemon before the command name sets useDaemon before the command
+	// registers its flags.
+	useDaemon = true
+	f := flag.NewFlagSet("gen", flag.ContinueOnError)
+	addDaemonFlags(f)
+	if err := f.Parse([]string{"./..."}); err != nil {
+		t.Fatal(err)
+	}
+	if !useDaemon {
+		t.Error("registering the command's flags cleared -daemon")
+	}
+}
+
+func TestDaemonRoundTrip(t *testing.T) {
+	repoRoot, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.19\n\nrequire github.com/goforj/wire v0.0.0\nreplace github.com/goforj/wire => " + repoRoot + "\n",
+		"app/app.go": strings.Join([]string{
+			"package app",
+			"",
+			"type Foo struct{}",
+			"",
+			"func NewFoo() *Foo { return &Foo{} }",
+			"",
+		}, "\n"),
+		"app/wire.go": strings.Join([]string{
+			"//go:build wireinject",
+			"// +build wireinject",
+			"",
+			"package app",
+			"",
+			"import \"github.com/goforj/wire\"",
+			"",
+			"func Init() *Foo {",
+			"\twire.Build(NewFoo)",
+			"\treturn nil",
+			"}",
+			"",
+		}, "\n"),
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("Wire", newDaemonService(context.Background())); err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("unix", filepath.Join(t.TempDir(), "daemon.sock"))
+	if err != nil {
+		t.Skipf("unix sockets are not available: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go srv.ServeCodec(jsonrpc.NewServerCodec(conn))
+		}
+	}()
+	conn, err := net.Dial("unix", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := jsonrpc.NewClient(conn)
+	defer client.Close()
+
+	env := append(os.Environ(), "GOWORK=off", "TMPDIR="+t.TempDir())
+	call := func(method string, args interface{}) DaemonReply {
+		t.Helper()
+		var reply DaemonReply
+		if err := client.Call(method, args, &reply); err != nil {
+			t.Fatalf("%s failed: %v", method, err)
+		}
+		if reply.ExitCode != 0 {
+			t.Fatalf("%s exited with %d:\n%s", method, reply.ExitCode, reply.Stderr)
+		}
+		return reply
+	}
+	// check reports whether a check of the app package reused the
+	// packages the daemon loaded before.
+	check := func() bool {
+		t.Helper()
+		reply := call("Wire.Check", DaemonArgs{Dir: dir, Env: env, Args: []string{"-log_level=debug", "./app"}})
+		return strings.Contains(reply.Stderr, "reusing the packages loaded for ./app")
+	}
+
+	call("Wire.Generate", DaemonArgs{Dir: dir, Env: env, Args: []string{"./app"}})
+	if _, err := os.Stat(filepath.Join(dir, "app", "wire_gen.go")); err != nil {
+		t.Fatalf("Generate did not write wire_gen.go: %v", err)
+	}
+	check()
+	if !check() {
+		t.Error("second check did not reuse the loaded packages")
+	}
+	call("Wire.Invalidate", InvalidateArgs{Patterns: []string{"example.com/app/..."}})
+	if check() {
+		t.Error("check after Invalidate reused the loaded packages")
+	}
+	var reply DaemonReply
+	if err := client.Call("Wire.Invalidate", InvalidateArgs{}, &reply); err == nil {
+		t.Error("Invalidate without patterns succeeded")
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !unix
+
+package main
+
+import "os"
+
+// ownedByUser reports whether the file described by info belongs to the
+// user running wire. Without unix file ownership, the permissions of the
+// socket's directory are all there is to check.
+func ownedByUser(info os.FileInfo) bool {
+	return true
+}
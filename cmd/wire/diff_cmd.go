@@ -15,12 +15,16 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/goforj/wire/internal/wire"
@@ -29,9 +33,9 @@ import (
 )
 
 type diffCmd struct {
-	headerFile string
-	tags       string
+	generate   generateFlags
 	profile    profileFlags
+	writePatch string
 }
 
 // Name returns the subcommand name.
@@ -44,13 +48,24 @@ func (*diffCmd) Synopsis() string {
 
 // Usage returns the help text for the subcommand.
 func (*diffCmd) Usage() string {
-	return `diff [packages]
+	return `diff [-match regexp] [-output_file_prefix prefix] [-output_file_name template] [-write_patch file] [packages]
 
   Given one or more packages, diff generates the content for their wire_gen.go
-  files and outputs the diff against the existing files.
+  files and outputs the diff against the existing files. diff accepts the
+  same generation flags as gen, so pass the same -output_file_prefix,
+  -output_file_name, and -header_file to compare against what gen writes.
 
   If no packages are listed, it defaults to ".".
 
+  Like gen, diff serves the output of unchanged packages from the cache,
+  so that it does not analyze them again; with -log_level=verbose they are
+  reported as "no diff (cached)".
+
+  With -write_patch, diff also writes the differences of all packages to a
+  single patch file, with paths relative to the root of the git repository
+  (or of the module outside of one), that git apply can apply. The file is
+  empty if there are no differences.
+
   Similar to the diff command, it returns 0 if no diff, 1 if different, 2
   plus an error if trouble.
 `
@@ -58,9 +73,9 @@ func (*diffCmd) Usage() string {
 
 // SetFlags registers flags for the subcommand.
 func (cmd *diffCmd) SetFlags(f *flag.FlagSet) {
-	f.StringVar(&cmd.headerFile, "header_file", "", "path to file to insert as a header in wire_gen.go")
-	f.StringVar(&cmd.tags, "tags", "", "append build tags to the default wirebuild")
+	cmd.generate.addFlags(f)
 	cmd.profile.addFlags(f)
+	f.StringVar(&cmd.writePatch, "write_patch", "", "also write all differences as a patch file that git apply accepts")
 }
 
 // Execute runs the subcommand.
@@ -69,73 +84,84 @@ func (cmd *diffCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interf
 		errReturn  = subcommands.ExitStatus(2)
 		diffReturn = subcommands.ExitStatus(1)
 	)
-	stop, err := cmd.profile.start()
+	stop, err := cmd.profile.start(cmd.Name())
 	if err != nil {
 		log.Println(err)
 		return errReturn
 	}
 	defer stop()
 	totalStart := time.Now()
-	ctx = withTiming(ctx, cmd.profile.timings)
+	ctx = cmd.profile.withTiming(ctx)
 
 	wd, err := os.Getwd()
 	if err != nil {
 		log.Println("failed to get working directory: ", err)
 		return errReturn
 	}
-	opts, err := newGenerateOptions(cmd.headerFile)
+	opts, err := cmd.generate.options()
 	if err != nil {
 		log.Println(err)
 		return subcommands.ExitFailure
 	}
 
-	opts.Tags = cmd.tags
-
-	genStart := time.Now()
-	outs, errs := wire.Generate(ctx, wd, os.Environ(), packages(f), opts)
-	logTiming(cmd.profile.timings, "wire.Generate", genStart)
-	if len(errs) > 0 {
-		logErrors(errs)
-		log.Println("generate failed")
+	env := os.Environ()
+	pkgs, err := matchedPackages(ctx, wd, env, cmd.generate.tags, cmd.generate.match, cmd.generate.skip, f)
+	if err != nil {
+		log.Println(err)
 		return errReturn
 	}
-	if len(outs) == 0 {
-		logTiming(cmd.profile.timings, "total", totalStart)
-		return subcommands.ExitSuccess
+
+	genStart := time.Now()
+	diffs, errs := wire.Diff(ctx, wd, env, pkgs, opts)
+	logTiming(cmd.profile.timings, "wire.Diff", genStart)
+	success := len(errs) == 0
+	if !success {
+		logErrors(errs.Errors())
+		if len(diffs) == 0 {
+			log.Println("generate failed")
+			return errReturn
+		}
 	}
-	success := true
 	hadDiff := false
 	diffStart := time.Now()
-	for _, out := range outs {
-		if len(out.Errs) > 0 {
-			logErrors(out.Errs)
-			log.Printf("%s: generate failed\n", out.PkgPath)
-			success = false
+	var patch bytes.Buffer
+	patchRoot := ""
+	if cmd.writePatch != "" {
+		if patchRoot, err = repoRoot(wd, env); err != nil {
+			log.Printf("failed to find the repository root for -write_patch: %v\n", err)
+			return errReturn
 		}
-		if len(out.Content) == 0 {
-			// No Wire output. Maybe errors, maybe no Wire directives.
+	}
+	for _, d := range diffs {
+		switch {
+		case d.Unified != "":
+			// Print the actual diff to stdout, not stderr.
+			fmt.Printf("%s: diff from %s:\n%s\n", d.PkgPath, d.Path, d.Unified)
+			hadDiff = true
+		case d.Cached:
+			// The package is unchanged since gen last wrote it.
+			verbosef("%s: no diff (cached)\n", d.PkgPath)
 			continue
+		default:
+			verbosef("%s: %s is up to date\n", d.PkgPath, d.Path)
 		}
-		// Assumes the current file is empty if we can't read it.
-		cur, _ := ioutil.ReadFile(out.OutputPath)
-		if diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
-			A: difflib.SplitLines(string(cur)),
-			B: difflib.SplitLines(string(out.Content)),
-		}); err == nil {
-			if diff != "" {
-				// Print the actual diff to stdout, not stderr.
-				fmt.Printf("%s: diff from %s:\n%s\n", out.PkgPath, out.OutputPath, diff)
-				hadDiff = true
+		if cmd.writePatch != "" {
+			if err := writeFilePatch(&patch, patchRoot, d.Path, d.Current, d.Generated); err != nil {
+				log.Printf("%s: failed to diff %s: %v\n", d.PkgPath, d.Path, err)
+				success = false
 			}
-		} else {
-			log.Printf("%s: failed to diff %s: %v\n", out.PkgPath, out.OutputPath, err)
-			success = false
 		}
 	}
 	if !success {
 		log.Println("at least one generate failure")
 		return errReturn
 	}
+	if cmd.writePatch != "" {
+		if err := ioutil.WriteFile(cmd.writePatch, patch.Bytes(), 0666); err != nil {
+			log.Printf("failed to write patch: %v\n", err)
+			return errReturn
+		}
+	}
 	logTiming(cmd.profile.timings, "diffs", diffStart)
 	logTiming(cmd.profile.timings, "total", totalStart)
 	if hadDiff {
@@ -143,3 +169,98 @@ func (cmd *diffCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interf
 	}
 	return subcommands.ExitSuccess
 }
+
+// writeFilePatch appends to buf a patch that git apply accepts, turning the
+// file at path, with content cur, into want. The file is named relative to
+// root, and is created by the patch if it does not exist.
+func writeFilePatch(buf *bytes.Buffer, root, path string, cur, want []byte) error {
+	// git reports its root with symbolic links resolved.
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(path)); err == nil {
+		path = filepath.Join(dir, filepath.Base(path))
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return err
+	}
+	rel = filepath.ToSlash(rel)
+	from := "a/" + rel
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		from = "/dev/null"
+	}
+	a, b := patchLines(cur), patchLines(want)
+	groups := difflib.NewMatcher(a, b).GetGroupedOpCodes(3)
+	if len(groups) == 0 {
+		return nil
+	}
+	fmt.Fprintf(buf, "diff --git a/%s b/%s\n", rel, rel)
+	if from == "/dev/null" {
+		buf.WriteString("new file mode 100644\n")
+	}
+	fmt.Fprintf(buf, "--- %s\n+++ b/%s\n", from, rel)
+	for _, group := range groups {
+		first, last := group[0], group[len(group)-1]
+		fmt.Fprintf(buf, "@@ -%s +%s @@\n", hunkRange(first.I1, last.I2), hunkRange(first.J1, last.J2))
+		for _, c := range group {
+			if c.Tag == 'e' {
+				writePatchLines(buf, ' ', a[c.I1:c.I2])
+				continue
+			}
+			if c.Tag == 'r' || c.Tag == 'd' {
+				writePatchLines(buf, '-', a[c.I1:c.I2])
+			}
+			if c.Tag == 'r' || c.Tag == 'i' {
+				writePatchLines(buf, '+', b[c.J1:c.J2])
+			}
+		}
+	}
+	return nil
+}
+
+// patchLines splits content into lines that keep their newlines. Unlike
+// difflib.SplitLines, it adds no empty line after a final newline, and
+// leaves a last line without a newline as it is.
+func patchLines(content []byte) []string {
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// hunkRange formats the lines [start, end) of a file for a hunk header.
+// An empty range is given by the line before it, so that a hunk adding
+// the lines of a new or empty file starts at 0,0.
+func hunkRange(start, end int) string {
+	switch n := end - start; n {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, n)
+	}
+}
+
+// writePatchLines writes lines to buf, each prefixed with op, marking a
+// last line that has no newline as git does.
+func writePatchLines(buf *bytes.Buffer, op byte, lines []string) {
+	for _, line := range lines {
+		buf.WriteByte(op)
+		buf.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			buf.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// repoRoot returns the root of the git repository holding wd, or the root
+// of its module if it is not in one.
+func repoRoot(wd string, env []string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = wd
+	cmd.Env = env
+	if out, err := cmd.Output(); err == nil {
+		return strings.TrimSpace(string(out)), nil
+	}
+	return moduleRoot(wd, env)
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteFilePatch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	generated := "package app\n\nfunc Init() *Foo {\n\tfoo := NewFoo()\n\treturn foo\n}\n"
+	tests := []struct {
+		name string
+		// cur is the content of the existing file, or nil if there is none.
+		cur  *string
+		want string
+	}{
+		{
+			name: "StaleWithNewline",
+			cur:  strPtr("package app\n\nfunc Init() *Foo {\n\treturn nil\n}\n"),
+			want: generated,
+		},
+		{
+			name: "StaleWithoutNewline",
+			cur:  strPtr("package app\n\nfunc Init() *Foo {\n\treturn nil\n}"),
+			want: generated,
+		},
+		{
+			name: "GeneratedWithoutNewline",
+			cur:  strPtr(generated),
+			want: strings.TrimSuffix(generated, "\n"),
+		},
+		{
+			name: "Empty",
+			cur:  strPtr(""),
+			want: generated,
+		},
+		{
+			name: "Missing",
+			want: generated,
+		},
+		{
+			name: "ChangesFarApart",
+			cur:  strPtr("a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"),
+			want: "A\nb\nc\nd\ne\nf\ng\nh\ni\nJ\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root, err := filepath.EvalSymlinks(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(root, "app", "wire_gen.go")
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			var cur []byte
+			if test.cur != nil {
+				cur = []byte(*test.cur)
+				if err := os.WriteFile(path, cur, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var patch bytes.Buffer
+			if err := writeFilePatch(&patch, root, path, cur, []byte(test.want)); err != nil {
+				t.Fatal(err)
+			}
+			patchFile := filepath.Join(t.TempDir(), "wire.patch")
+			if err := os.WriteFile(patchFile, patch.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+			for _, args := range [][]string{{"apply", "--check", patchFile}, {"apply", patchFile}} {
+				cmd := exec.Command("git", args...)
+				cmd.Dir = root
+				if out, err := cmd.CombinedOutput(); err != nil {
+					t.Fatalf("git %s failed: %v\n%s\npatch:\n%s", strings.Join(args, " "), err, out, patch.String())
+				}
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("applying the patch wrote %q; want %q\npatch:\n%s", got, test.want, patch.String())
+			}
+		})
+	}
+}
+
+func TestWriteFilePatchUnchanged(t *testing.T) {
+	root := t.TempDir()
+	content := []byte("package app\n")
+	var patch bytes.Buffer
+	if err := writeFilePatch(&patch, root, filepath.Join(root, "wire_gen.go"), content, content); err != nil {
+		t.Fatal(err)
+	}
+	if patch.Len() != 0 {
+		t.Errorf("writeFilePatch for an unchanged file wrote:\n%s\nwant nothing", patch.String())
+	}
+}
+
+func strPtr(s string) *string { return &s }
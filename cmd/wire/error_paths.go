@@ -0,0 +1,120 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// defaultErrorPaths is the default of the -error_paths flag.
+const defaultErrorPaths = "module"
+
+// errorPaths is set by the -error_paths flag of every command.
+var errorPaths = defaultErrorPaths
+
+// addErrorPathsFlags registers the -error_paths flag. It defaults to its
+// current value, so that a command keeps -error_paths given before its name.
+func addErrorPathsFlags(f *flag.FlagSet) {
+	f.StringVar(&errorPaths, "error_paths", errorPaths, "print file names in errors and warnings relative to the module root (module), the working directory (wd), or another directory, or as they are (absolute)")
+}
+
+// errorPathBases caches the directory -error_paths names for each working
+// directory, since finding the module root runs the go command. Watch
+// pipelines log concurrently, and the daemon changes directories between
+// requests.
+var errorPathBases struct {
+	mu    sync.Mutex
+	bases map[[2]string]string
+}
+
+// errorPathBase returns the directory that file names in diagnostics are
+// printed relative to, or "" to print them as they are.
+func errorPathBase() string {
+	mode := errorPaths
+	if mode == "" || mode == "absolute" {
+		return ""
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	errorPathBases.mu.Lock()
+	defer errorPathBases.mu.Unlock()
+	key := [2]string{mode, wd}
+	if base, ok := errorPathBases.bases[key]; ok {
+		return base
+	}
+	var base string
+	switch mode {
+	case "module":
+		if base, err = moduleRoot(wd, os.Environ()); err != nil {
+			debugf("error paths: failed to resolve module root, printing absolute paths: %v", err)
+			base = ""
+		}
+	case "wd":
+		base = wd
+	default:
+		base = mode
+		if !filepath.IsAbs(base) {
+			base = filepath.Join(wd, base)
+		}
+	}
+	if errorPathBases.bases == nil {
+		errorPathBases.bases = make(map[[2]string]string)
+	}
+	errorPathBases.bases[key] = base
+	return base
+}
+
+// relativePaths returns msg with the file names under the -error_paths
+// directory made relative to it, so that logs from CI, which builds in
+// temporary directories, stay short and point into the checkout. Positions
+// nested in messages, such as those of conflicting providers, are
+// shortened too.
+func relativePaths(msg string) string {
+	base := errorPathBase()
+	if base == "" {
+		return msg
+	}
+	return trimPathPrefix(msg, filepath.Clean(base)+string(filepath.Separator))
+}
+
+// trimPathPrefix removes prefix from the paths in msg that begin with it.
+// A path begins at the start of msg or after white space, an opening
+// parenthesis or bracket, or a quote, so that prefix is left alone where it
+// is only part of a longer path, such as /tmp/app/ in
+// /home/x/tmp/app/wire.go.
+func trimPathPrefix(msg, prefix string) string {
+	var b strings.Builder
+	last := 0
+	for start := 0; ; {
+		i := strings.Index(msg[start:], prefix)
+		if i < 0 {
+			break
+		}
+		i += start
+		if i == 0 || strings.ContainsRune(" \t\n([\"'`", rune(msg[i-1])) {
+			b.WriteString(msg[last:i])
+			last = i + len(prefix)
+		}
+		start = i + len(prefix)
+	}
+	b.WriteString(msg[last:])
+	return b.String()
+}
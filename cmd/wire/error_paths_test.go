@@ -0,0 +1,63 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestTrimPathPrefix(t *testing.T) {
+	tests := []struct {
+		msg, want string
+	}{
+		{"/tmp/app/wire.go:3:1: no provider", "wire.go:3:1: no provider"},
+		{"inject Init: /tmp/app/a.go:1:1 (see /tmp/app/b.go:2:1)", "inject Init: a.go:1:1 (see b.go:2:1)"},
+		{"open \"/tmp/app/go.mod\": denied", "open \"go.mod\": denied"},
+		{"/home/x/tmp/app/wire.go:3:1: no provider", "/home/x/tmp/app/wire.go:3:1: no provider"},
+		{"/tmp/app/x/tmp/app/wire.go", "x/tmp/app/wire.go"},
+		{"no paths here", "no paths here"},
+	}
+	for _, test := range tests {
+		if got := trimPathPrefix(test.msg, "/tmp/app/"); got != test.want {
+			t.Errorf("trimPathPrefix(%q) = %q; want %q", test.msg, got, test.want)
+		}
+	}
+}
+
+func TestAddErrorPathsFlagsKeepsValue(t *testing.T) {
+	saved := errorPaths
+	t.Cleanup(func() { errorPaths = saved })
+
+	// -error_paths before the command name is parsed into errorPaths by the
+	// top-level flag set before the command registers its flags.
+	errorPaths = defaultErrorPaths
+	top := flag.NewFlagSet("wire", flag.ContinueOnError)
+	addErrorPathsFlags(top)
+	if err := top.Parse([]string{"-error_paths=absolute", "check", "./..."}); err != nil {
+		t.Fatal(err)
+	}
+	f := flag.NewFlagSet("check", flag.ContinueOnError)
+	leveledCmd{&checkCmd{}}.SetFlags(f)
+	if err := f.Parse(top.Args()[1:]); err != nil {
+		t.Fatal(err)
+	}
+	if errorPaths != "absolute" {
+		t.Errorf("errorPaths = %q after registering the command's flags; want %q", errorPaths, "absolute")
+	}
+	if base := errorPathBase(); base != "" {
+		t.Errorf("errorPathBase() = %q; want \"\" for absolute paths", base)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/goforj/wire/internal/wire"
+	"github.com/google/subcommands"
+)
+
+type fmtCmd struct {
+	packageFlags
+	list bool
+}
+
+// Name returns the subcommand name.
+func (*fmtCmd) Name() string { return "fmt" }
+
+// Synopsis returns a short summary of the subcommand.
+func (*fmtCmd) Synopsis() string {
+	return "order wire.Build and wire.NewSet arguments canonically"
+}
+
+// Usage returns the help text for the subcommand.
+func (*fmtCmd) Usage() string {
+	return `fmt [-l] [-match regexp] [packages]
+
+  Given one or more packages, fmt rewrites the arguments of every
+  wire.Build, wire.TestBuild, wire.MustBuild and wire.NewSet call in place
+  so that provider sets come first, then providers, then bindings, values
+  and requirements, each sorted alphabetically. Comments stay with the argument they precede
+  or follow on the same line.
+
+  If no packages are listed, it defaults to ".". With -l, fmt only prints
+  the files whose arguments are out of order, and exits with status 1 if
+  there are any.
+`
+}
+
+// SetFlags registers flags for the subcommand.
+func (cmd *fmtCmd) SetFlags(f *flag.FlagSet) {
+	cmd.packageFlags.addFlags(f)
+	f.BoolVar(&cmd.list, "l", false, "list files that would be rewritten without rewriting them")
+}
+
+// Execute runs the subcommand.
+func (cmd *fmtCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
+	wd, err := os.Getwd()
+	if err != nil {
+		log.Println("failed to get working directory: ", err)
+		return subcommands.ExitFailure
+	}
+	env := os.Environ()
+	pkgs, err := matchedPackages(ctx, wd, env, cmd.tags, cmd.match, cmd.skip, f)
+	if err != nil {
+		log.Println(err)
+		return subcommands.ExitFailure
+	}
+	results, errs := wire.Format(ctx, wd, env, cmd.tags, pkgs)
+	if len(errs) > 0 {
+		logErrors(errs)
+		log.Println("fmt failed")
+		return subcommands.ExitFailure
+	}
+	success := true
+	for _, res := range results {
+		if cmd.list {
+			fmt.Println(res.Path)
+			success = false
+			continue
+		}
+		if err := ioutil.WriteFile(res.Path, res.Content, 0666); err != nil {
+			log.Printf("failed to write %s: %v\n", res.Path, err)
+			success = false
+			continue
+		}
+		infof("formatted %s\n", res.Path)
+	}
+	if !success {
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
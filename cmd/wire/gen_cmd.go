@@ -19,6 +19,7 @@ import (
 	"flag"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/goforj/wire/internal/wire"
@@ -26,10 +27,12 @@ import (
 )
 
 type genCmd struct {
-	headerFile     string
-	prefixFileName string
-	tags           string
-	profile        profileFlags
+	generate     generateFlags
+	examples     bool
+	partial      bool
+	injectors    stringList
+	jsonManifest string
+	profile      profileFlags
 }
 
 // Name returns the subcommand name.
@@ -42,55 +45,99 @@ func (*genCmd) Synopsis() string {
 
 // Usage returns the help text for the subcommand.
 func (*genCmd) Usage() string {
-	return `gen [packages]
+	return `gen [-match regexp] [-injector name] [-partial] [-examples] [-json_manifest file] [packages]
 
   Given one or more packages, gen creates the wire_gen.go file for each.
+  Injectors declared in wireinject test files are written to
+  wire_gen_test.go, or wire_gen_external_test.go for an external test
+  package.
 
-  If no packages are listed, it defaults to ".".
+  With -output_file_name, the generated file is named by a template
+  executed with the package name as .Package, such as
+  "{{.Package}}_wire.gen.go"; test outputs replace its .go with _test.go
+  and _external_test.go.
+
+  If no packages are listed, it defaults to ".". With -match, only packages
+  whose import path matches the regular expression are generated.
+
+  With -injector, only the named injectors are regenerated; the code of the
+  other injectors in each wire_gen.go is kept as is, and packages without a
+  named injector are left alone. -injector may be repeated.
+
+  With -partial, an injector that fails to generate does not stop the rest
+  of its package from being written: the failing injector keeps its code
+  from the existing wire_gen.go, if any, and its errors are still reported.
+
+  With -examples, gen also writes a wire_example_test.go file holding a Go doc
+  example for each injector.
+
+  With -json_manifest, packages are loaded from the files listed in a JSON
+  file written by a build system such as Bazel, mapping import paths to
+  file paths relative to the JSON file, instead of with the go command. The
+  packages are then given by import path, and default to all listed ones.
 `
 }
 
 // SetFlags registers flags for the subcommand.
 func (cmd *genCmd) SetFlags(f *flag.FlagSet) {
-	f.StringVar(&cmd.headerFile, "header_file", "", "path to file to insert as a header in wire_gen.go")
-	f.StringVar(&cmd.prefixFileName, "output_file_prefix", "", "string to prepend to output file names.")
-	f.StringVar(&cmd.tags, "tags", "", "append build tags to the default wirebuild")
+	cmd.generate.addFlags(f)
+	f.Var(&cmd.injectors, "injector", "only regenerate the injector with this name, keeping the others in wire_gen.go (may be repeated)")
+	f.BoolVar(&cmd.partial, "partial", false, "write a package's other injectors when some of them fail to generate")
+	f.BoolVar(&cmd.examples, "examples", false, "also write a wire_example_test.go file with a Go doc example for each injector")
+	f.StringVar(&cmd.jsonManifest, "json_manifest", "", "load packages from this JSON file mapping import paths to files instead of with the go command")
 	cmd.profile.addFlags(f)
 }
 
 // Execute runs the subcommand.
 func (cmd *genCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
-	stop, err := cmd.profile.start()
+	stop, err := cmd.profile.start(cmd.Name())
 	if err != nil {
 		log.Println(err)
 		return subcommands.ExitFailure
 	}
 	defer stop()
 	totalStart := time.Now()
-	ctx = withTiming(ctx, cmd.profile.timings)
+	ctx = cmd.profile.withTiming(ctx)
 
 	wd, err := os.Getwd()
 	if err != nil {
 		log.Println("failed to get working directory: ", err)
 		return subcommands.ExitFailure
 	}
-	opts, err := newGenerateOptions(cmd.headerFile)
+	opts, err := cmd.generate.options()
 	if err != nil {
 		log.Println(err)
 		return subcommands.ExitFailure
 	}
 
-	opts.PrefixOutputFile = cmd.prefixFileName
-	opts.Tags = cmd.tags
+	opts.Examples = cmd.examples
+	opts.Injectors = cmd.injectors
+	opts.Partial = cmd.partial
+	opts.JSONManifest = cmd.jsonManifest
+
+	env := os.Environ()
+	var pkgs []string
+	if cmd.jsonManifest != "" {
+		// The manifest lists the packages; there is no go command to
+		// expand patterns.
+		pkgs = f.Args()
+	} else if pkgs, err = matchedPackages(ctx, wd, env, cmd.generate.tags, cmd.generate.match, cmd.generate.skip, f); err != nil {
+		log.Println(err)
+		return subcommands.ExitFailure
+	}
 
 	genStart := time.Now()
-	outs, errs := wire.Generate(ctx, wd, os.Environ(), packages(f), opts)
+	outs, errs := wire.Generate(ctx, wd, env, pkgs, opts)
 	logTiming(cmd.profile.timings, "wire.Generate", genStart)
 	if len(errs) > 0 {
-		logErrors(errs)
+		logErrors(errs.Errors())
 		log.Println("generate failed")
 		return subcommands.ExitFailure
 	}
+	if len(cmd.injectors) > 0 && !anyOutput(outs) {
+		log.Printf("no injectors named %s found\n", strings.Join(cmd.injectors, ", "))
+		return subcommands.ExitFailure
+	}
 	if len(outs) == 0 {
 		logTiming(cmd.profile.timings, "total", totalStart)
 		return subcommands.ExitSuccess
@@ -98,17 +145,33 @@ func (cmd *genCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interfa
 	success := true
 	writeStart := time.Now()
 	for _, out := range outs {
+		for _, w := range out.Warnings {
+			infof("warning: %s\n", relativePaths(w.Error()))
+		}
 		if len(out.Errs) > 0 {
-			logErrors(out.Errs)
+			logErrors(out.Errs.Errors())
 			log.Printf("%s: generate failed\n", out.PkgPath)
 			success = false
 		}
+		if out.Skipped {
+			verbosef("%s: skipped: no injectors\n", out.PkgPath)
+			continue
+		}
 		if len(out.Content) == 0 {
-			// No Wire output. Maybe errors, maybe no Wire directives.
+			// The errors are logged above.
 			continue
 		}
-		if err := out.Commit(); err == nil {
-			log.Printf("%s: wrote %s (%s)\n", out.PkgPath, out.OutputPath, formatDuration(time.Since(totalStart)))
+		if out.Cached {
+			verbosef("%s: output served from the cache\n", out.PkgPath)
+		}
+		commitStart := time.Now()
+		err := out.Commit()
+		cmd.profile.span("generate.package."+out.PkgPath+".write", commitStart)
+		if err == nil {
+			infof("%s: wrote %s (%s)\n", out.PkgPath, out.OutputPath, formatDuration(time.Since(totalStart)))
+			if len(out.ExampleContent) > 0 {
+				infof("%s: wrote %s\n", out.PkgPath, out.ExamplePath)
+			}
 		} else {
 			log.Printf("%s: failed to write %s: %v\n", out.PkgPath, out.OutputPath, err)
 			success = false
@@ -122,3 +185,13 @@ func (cmd *genCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interfa
 	logTiming(cmd.profile.timings, "total", totalStart)
 	return subcommands.ExitSuccess
 }
+
+// anyOutput reports whether any result has generated output or errors.
+func anyOutput(outs []wire.GenerateResult) bool {
+	for _, out := range outs {
+		if len(out.Content) > 0 || len(out.Errs) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/goforj/wire/internal/wire"
+	"github.com/google/subcommands"
+)
+
+type genSetCmd struct {
+	headerFile string
+	tags       string
+	from       string
+	outputFile string
+	force      bool
+	stdout     bool
+}
+
+// Name returns the subcommand name.
+func (*genSetCmd) Name() string { return "genset" }
+
+// Synopsis returns a short summary of the subcommand.
+func (*genSetCmd) Synopsis() string {
+	return "write provider sets listed in a JSON or YAML manifest"
+}
+
+// Usage returns the help text for the subcommand.
+func (*genSetCmd) Usage() string {
+	return `genset -from manifest [-output_file file]
+
+  genset writes a Go file to the package in the current directory declaring
+  the provider sets listed in a manifest, for programs that register their
+  providers in service metadata files rather than in hand-edited Go. The
+  manifest is YAML if its name ends in .yaml or .yml and JSON otherwise:
+
+    package: providers
+    sets:
+      - name: ServiceSet
+        doc: ServiceSet provides the services of the API server.
+        providers:
+          - example.com/app/users.NewService
+          - example.com/app/db.ProviderSet
+      - name: AppSet
+        providers: [ServiceSet, example.com/app/server.New]
+
+  Each provider is a provider function or provider set qualified by its
+  import path, or the bare name of another set in the manifest. Every one
+  is looked up, so a manifest naming a missing or invalid provider fails.
+  package is only needed if the package has no Go files yet.
+
+  The file defaults to wire_sets.go. A file genset did not write is only
+  replaced with -force.
+`
+}
+
+// SetFlags registers flags for the subcommand.
+func (cmd *genSetCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&cmd.headerFile, "header_file", "", "path to file to insert as a header in the generated file")
+	addTagsFlag(f, &cmd.tags)
+	f.StringVar(&cmd.from, "from", "", "JSON or YAML manifest listing the provider sets")
+	f.StringVar(&cmd.outputFile, "output_file", "wire_sets.go", "name of the file to write")
+	f.BoolVar(&cmd.force, "force", false, "replace the output file even if genset did not write it")
+	f.BoolVar(&cmd.stdout, "stdout", false, "print the file instead of writing it")
+}
+
+// Execute runs the subcommand.
+func (cmd *genSetCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
+	if cmd.from == "" {
+		log.Println("genset requires -from")
+		return subcommands.ExitUsageError
+	}
+	if f.NArg() > 0 {
+		log.Println("genset does not take package arguments; run it in the package's directory")
+		return subcommands.ExitUsageError
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		log.Println("failed to get working directory: ", err)
+		return subcommands.ExitFailure
+	}
+	manifest, err := wire.ReadSetManifest(cmd.from)
+	if err != nil {
+		log.Println(err)
+		return subcommands.ExitFailure
+	}
+	var header []byte
+	if cmd.headerFile != "" {
+		header, err = ioutil.ReadFile(cmd.headerFile)
+		if err != nil {
+			log.Printf("failed to read header file %q: %v\n", cmd.headerFile, err)
+			return subcommands.ExitFailure
+		}
+	}
+	src, errs := wire.GenSet(ctx, wd, os.Environ(), cmd.tags, manifest, header)
+	if len(errs) > 0 {
+		logErrors(errs)
+		log.Println("genset failed")
+		return subcommands.ExitFailure
+	}
+	if cmd.stdout {
+		os.Stdout.Write(src)
+		return subcommands.ExitSuccess
+	}
+	path := filepath.Join(wd, cmd.outputFile)
+	if old, err := ioutil.ReadFile(path); err == nil && !cmd.force && !wire.IsGenSetOutput(old) {
+		log.Printf("%s was not written by genset; use -force to replace it\n", path)
+		return subcommands.ExitFailure
+	}
+	if err := ioutil.WriteFile(path, src, 0666); err != nil {
+		log.Printf("failed to write %s: %v\n", path, err)
+		return subcommands.ExitFailure
+	}
+	infof("wrote %s\n", path)
+	return subcommands.ExitSuccess
+}
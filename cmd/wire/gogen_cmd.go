@@ -0,0 +1,146 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/goforj/wire/internal/wire"
+	"github.com/google/subcommands"
+)
+
+type goGenCmd struct {
+	generate generateFlags
+	list     bool
+}
+
+// Name returns the subcommand name.
+func (*goGenCmd) Name() string { return "gogen" }
+
+// Synopsis returns a short summary of the subcommand.
+func (*goGenCmd) Synopsis() string {
+	return "write the //go:generate directive that runs wire gen into wireinject files"
+}
+
+// Usage returns the help text for the subcommand.
+func (*goGenCmd) Usage() string {
+	return `gogen [-l] [gen flags] [packages]
+
+  Given one or more packages, gogen writes a //go:generate directive that
+  runs wire gen with the given gen flags, such as -tags or
+  -output_file_prefix, into the first wireinject file of each package, so
+  that go generate and direct wire invocations produce the same output.
+  An existing directive that runs wire is updated in place, and any others
+  in the package's wireinject files are removed. -header_file is recorded
+  relative to each package's directory, and -package_header as given, since
+  it is already resolved from there. -match and -skip only select the
+  packages to update.
+
+  Once a package has its own directive, the generated wire_gen.go no longer
+  includes one.
+
+  If no packages are listed, it defaults to ".". With -l, gogen only prints
+  the files whose directives are out of date, and exits with status 1 if
+  there are any.
+`
+}
+
+// SetFlags registers flags for the subcommand.
+func (cmd *goGenCmd) SetFlags(f *flag.FlagSet) {
+	cmd.generate.addFlags(f)
+	f.BoolVar(&cmd.list, "l", false, "list files that would be rewritten without rewriting them")
+}
+
+// goGenSkippedFlags are the flags of gogen that are not passed on to the
+// wire gen run by the directive.
+var goGenSkippedFlags = map[string]bool{
+	"l":               true,
+	"match":           true,
+	"skip":            true,
+	"include_skipped": true,
+	"log_level":       true,
+}
+
+// Execute runs the subcommand.
+func (cmd *goGenCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
+	wd, err := os.Getwd()
+	if err != nil {
+		log.Println("failed to get working directory: ", err)
+		return subcommands.ExitFailure
+	}
+	headerFile := ""
+	if cmd.generate.headerFile != "" {
+		if headerFile, err = filepath.Abs(cmd.generate.headerFile); err != nil {
+			log.Println(err)
+			return subcommands.ExitFailure
+		}
+	}
+	env := os.Environ()
+	pkgs, err := matchedPackages(ctx, wd, env, cmd.generate.tags, cmd.generate.match, cmd.generate.skip, f)
+	if err != nil {
+		log.Println(err)
+		return subcommands.ExitFailure
+	}
+	directive := func(dir string) string {
+		genArgs := []string{"gen"}
+		f.Visit(func(fl *flag.Flag) {
+			if goGenSkippedFlags[fl.Name] {
+				return
+			}
+			value := fl.Value.String()
+			if fl.Name == "header_file" {
+				if rel, err := filepath.Rel(dir, headerFile); err == nil {
+					value = filepath.ToSlash(rel)
+				}
+			}
+			if b, ok := fl.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() && value == "true" {
+				genArgs = append(genArgs, "-"+fl.Name)
+				return
+			}
+			genArgs = append(genArgs, fmt.Sprintf("-%s=%s", fl.Name, value))
+		})
+		return wire.GoGenerateDirective(genArgs)
+	}
+	results, errs := wire.GoGenerate(ctx, wd, env, cmd.generate.tags, pkgs, directive)
+	if len(errs) > 0 {
+		logErrors(errs)
+		log.Println("gogen failed")
+		return subcommands.ExitFailure
+	}
+	success := true
+	for _, res := range results {
+		if cmd.list {
+			fmt.Println(res.Path)
+			success = false
+			continue
+		}
+		if err := ioutil.WriteFile(res.Path, res.Content, 0666); err != nil {
+			log.Printf("failed to write %s: %v\n", res.Path, err)
+			success = false
+			continue
+		}
+		infof("updated %s\n", res.Path)
+	}
+	if !success {
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
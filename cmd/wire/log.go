@@ -0,0 +1,128 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/goforj/wire/internal/wire"
+	"github.com/google/subcommands"
+)
+
+// logLevel selects how much the commands log. Errors are always logged.
+type logLevel int
+
+const (
+	// levelQuiet logs only errors.
+	levelQuiet logLevel = iota
+	// levelNormal also logs progress, such as the files written, and
+	// warnings.
+	levelNormal
+	// levelVerbose also logs what happened to each package.
+	levelVerbose
+	// levelDebug also logs Wire's decisions, such as which packages it
+	// loads and the inputs of cache keys, and every watch event.
+	levelDebug
+)
+
+var logLevelNames = []string{"quiet", "normal", "verbose", "debug"}
+
+// currentLevel is set by the -log_level flag of every command.
+var currentLevel = levelNormal
+
+// String implements flag.Value.
+func (l *logLevel) String() string {
+	if l == nil || int(*l) >= len(logLevelNames) {
+		return ""
+	}
+	return logLevelNames[*l]
+}
+
+// Set implements flag.Value.
+func (l *logLevel) Set(s string) error {
+	for i, name := range logLevelNames {
+		if s == name {
+			*l = logLevel(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown log level %q (want quiet, normal, verbose, or debug)", s)
+}
+
+// addLogFlags registers the -log_level flag on f.
+func addLogFlags(f *flag.FlagSet) {
+	f.Var(&currentLevel, "log_level", "how much to log: quiet, normal, verbose, or debug")
+}
+
+// infof logs progress at the normal level.
+func infof(format string, args ...interface{}) {
+	if currentLevel >= levelNormal {
+		log.Printf(format, args...)
+	}
+}
+
+// verbosef logs details at the verbose level.
+func verbosef(format string, args ...interface{}) {
+	if currentLevel >= levelVerbose {
+		log.Printf(format, args...)
+	}
+}
+
+// debugf logs at the debug level.
+func debugf(format string, args ...interface{}) {
+	if currentLevel >= levelDebug {
+		log.Printf("debug: "+format, args...)
+	}
+}
+
+// withDebug passes Wire's debug messages to the log at the debug level.
+func withDebug(ctx context.Context) context.Context {
+	if currentLevel < levelDebug {
+		return ctx
+	}
+	return wire.WithDebug(ctx, func(msg string) {
+		log.Print("debug: " + msg)
+	})
+}
+
+// leveledCmd adds the -log_level, -offline, -error_paths, and -daemon flags
+// to a subcommand, enables Wire's debug messages for it at the debug level, and
+// runs it through the daemon if asked to.
+type leveledCmd struct {
+	subcommands.Command
+}
+
+// SetFlags registers the subcommand's flags, -log_level, -offline,
+// -error_paths, and -daemon.
+func (cmd leveledCmd) SetFlags(f *flag.FlagSet) {
+	cmd.Command.SetFlags(f)
+	addLogFlags(f)
+	addOfflineFlags(f)
+	addErrorPathsFlags(f)
+	addDaemonFlags(f)
+}
+
+// Execute runs the subcommand once its flags, including -log_level,
+// -offline, and -daemon, are parsed.
+func (cmd leveledCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
+	applyOffline()
+	if status, ok := runInDaemon(cmd.Name()); ok {
+		return status
+	}
+	return cmd.Command.Execute(withDebug(ctx), f, args...)
+}
@@ -24,6 +24,8 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"os/exec"
+	"regexp"
 	"runtime"
 	"runtime/pprof"
 	"runtime/trace"
@@ -39,13 +41,28 @@ func main() {
 	subcommands.Register(subcommands.CommandsCommand(), "")
 	subcommands.Register(subcommands.FlagsCommand(), "")
 	subcommands.Register(subcommands.HelpCommand(), "")
-	subcommands.Register(&checkCmd{}, "")
-	subcommands.Register(&cacheCmd{}, "")
-	subcommands.Register(&diffCmd{}, "")
-	subcommands.Register(&genCmd{}, "")
-	subcommands.Register(&watchCmd{}, "")
-	subcommands.Register(&showCmd{}, "")
+	subcommands.Register(leveledCmd{&analyzeCmd{}}, "")
+	subcommands.Register(leveledCmd{&bindGenCmd{}}, "")
+	subcommands.Register(leveledCmd{&callersCmd{}}, "")
+	subcommands.Register(leveledCmd{&checkCmd{}}, "")
+	subcommands.Register(leveledCmd{&cacheCmd{}}, "")
+	subcommands.Register(leveledCmd{&cleanCmd{}}, "")
+	subcommands.Register(leveledCmd{&daemonCmd{}}, "")
+	subcommands.Register(leveledCmd{&diffCmd{}}, "")
+	subcommands.Register(leveledCmd{&fmtCmd{}}, "")
+	subcommands.Register(leveledCmd{&genCmd{}}, "")
+	subcommands.Register(leveledCmd{&genSetCmd{}}, "")
+	subcommands.Register(leveledCmd{&goGenCmd{}}, "")
+	subcommands.Register(leveledCmd{&watchCmd{}}, "")
+	subcommands.Register(leveledCmd{&replayCmd{}}, "")
+	subcommands.Register(leveledCmd{&showCmd{}}, "")
+	subcommands.Register(leveledCmd{&updateCmd{}}, "")
+	addLogFlags(flag.CommandLine)
+	addOfflineFlags(flag.CommandLine)
+	addErrorPathsFlags(flag.CommandLine)
+	addDaemonFlags(flag.CommandLine)
 	flag.Parse()
+	applyOffline()
 
 	// Initialize the default logger to log to stderr.
 	log.SetFlags(0)
@@ -60,17 +77,28 @@ func main() {
 		"commands": true, // builtin
 		"help":     true, // builtin
 		"flags":    true, // builtin
+		"analyze":  true,
+		"bind-gen": true,
+		"callers":  true,
 		"check":    true,
 		"cache":    true,
+		"clean":    true,
+		"daemon":   true,
 		"diff":     true,
+		"fmt":      true,
 		"gen":      true,
+		"genset":   true,
+		"gogen":    true,
+		"replay":   true,
 		"serve":    true,
 		"show":     true,
+		"update":   true,
 		"watch":    true,
 	}
 	// Default to running the "gen" command.
 	if args := flag.Args(); len(args) == 0 || !allCmds[args[0]] {
-		genCmd := &genCmd{}
+		// leveledCmd runs it through the daemon with -daemon.
+		genCmd := leveledCmd{&genCmd{}}
 		os.Exit(int(genCmd.Execute(context.Background(), flag.CommandLine)))
 	}
 	os.Exit(int(subcommands.Execute(context.Background())))
@@ -88,11 +116,124 @@ func packages(f *flag.FlagSet) []string {
 	return pkgs
 }
 
+// matchedPackages returns the packages selected by command-line args. If
+// match is non-empty or a pattern has a wildcard, the patterns are expanded,
+// narrowed to the packages whose import path matches the regular
+// expression, and filtered as skip says.
+func matchedPackages(ctx context.Context, wd string, env []string, tags string, match string, skip skipFlags, f *flag.FlagSet) ([]string, error) {
+	return filterPackages(ctx, wd, env, tags, match, skip, packages(f))
+}
+
+// filterPackages expands and filters pkgs like matchedPackages.
+func filterPackages(ctx context.Context, wd string, env []string, tags string, match string, skip skipFlags, pkgs []string) ([]string, error) {
+	filter := &wire.PackageFilter{
+		Skip:           skip.skip,
+		IncludeSkipped: skip.includeSkipped,
+	}
+	if match != "" {
+		re, err := regexp.Compile(match)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -match expression: %v", err)
+		}
+		filter.Match = re
+	} else if !hasWildcard(pkgs) {
+		return pkgs, nil
+	}
+	return wire.FilterPackages(ctx, wd, env, tags, pkgs, filter)
+}
+
+// hasWildcard reports whether one of the package patterns has a "..."
+// wildcard.
+func hasWildcard(patterns []string) bool {
+	for _, p := range patterns {
+		if strings.Contains(p, "...") {
+			return true
+		}
+	}
+	return false
+}
+
+// skipFlags holds the flags that leave packages out of wildcard patterns.
+type skipFlags struct {
+	skip           stringList
+	includeSkipped bool
+}
+
+// addFlags registers the skip flags on the provided FlagSet.
+func (sf *skipFlags) addFlags(f *flag.FlagSet) {
+	f.Var(&sf.skip, "skip", "comma-separated import path patterns of packages to leave out of ... patterns")
+	f.BoolVar(&sf.includeSkipped, "include_skipped", false, "keep the vendor, testdata, and generated-only packages that ... patterns leave out by default")
+}
+
+// workspacePackages returns patterns covering every module of the go.work
+// workspace that wd belongs to, narrowed by match and skip like
+// matchedPackages.
+func workspacePackages(ctx context.Context, wd string, env []string, tags string, match string, skip skipFlags) ([]string, error) {
+	gowork, err := goEnv(ctx, wd, env, "GOWORK")
+	if err != nil {
+		return nil, err
+	}
+	if gowork == "" || gowork == "off" {
+		return nil, fmt.Errorf("-all-modules requires a go.work workspace; none found for %s", wd)
+	}
+	cmd := exec.CommandContext(ctx, "go", "list", "-m", "-f", "{{.Path}}")
+	cmd.Dir = wd
+	cmd.Env = env
+	out, err := cmd.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			err = fmt.Errorf("%s", strings.TrimSpace(string(ee.Stderr)))
+		}
+		return nil, fmt.Errorf("failed to list workspace modules: %v", err)
+	}
+	var pkgs []string
+	for _, mod := range strings.Fields(string(out)) {
+		pkgs = append(pkgs, mod+"/...")
+	}
+	return filterPackages(ctx, wd, env, tags, match, skip, pkgs)
+}
+
+// goEnv returns the value of the go environment variable name as seen
+// from wd.
+func goEnv(ctx context.Context, wd string, env []string, name string) (string, error) {
+	cmd := exec.CommandContext(ctx, "go", "env", name)
+	cmd.Dir = wd
+	cmd.Env = env
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("go env %s: %v", name, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// stringList is a flag.Value that collects repeated or comma-separated
+// string flags.
+type stringList []string
+
+// String implements flag.Value.
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set implements flag.Value.
+func (l *stringList) Set(v string) error {
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			*l = append(*l, s)
+		}
+	}
+	return nil
+}
+
 type profileFlags struct {
 	cpuProfile   string
 	memProfile   string
 	traceProfile string
 	timings      bool
+
+	// spans records timings as OpenTelemetry spans if WIRE_OTEL_ENDPOINT
+	// is set.
+	spans *spanRecorder
 }
 
 // addFlags registers profiling flags on the provided FlagSet.
@@ -103,8 +244,11 @@ func (pf *profileFlags) addFlags(f *flag.FlagSet) {
 	f.BoolVar(&pf.timings, "timings", false, "log timing information for major steps")
 }
 
-// start enables configured profiles and returns a stop function.
-func (pf *profileFlags) start() (func(), error) {
+// start enables configured profiles and tracing for the command name and
+// returns a stop function.
+func (pf *profileFlags) start(name string) (func(), error) {
+	startTime := time.Now()
+	pf.spans = newSpanRecorder()
 	var cpuFile *os.File
 	var traceFile *os.File
 
@@ -141,6 +285,7 @@ func (pf *profileFlags) start() (func(), error) {
 	}
 
 	stop := func() {
+		pf.spans.export("wire "+name, startTime)
 		if traceFile != nil {
 			trace.Stop()
 			traceFile.Close()
@@ -172,26 +317,119 @@ func logTiming(enabled bool, label string, start time.Time) {
 	}
 }
 
-// withTiming attaches a timing logger to the context when enabled.
-func withTiming(ctx context.Context, enabled bool) context.Context {
-	if !enabled {
+// withTiming attaches a timing logger to the context when -timings is set,
+// recording spans as well when tracing is enabled. With -timings, other
+// measurements such as memory released are logged too.
+func (pf *profileFlags) withTiming(ctx context.Context) context.Context {
+	if !pf.timings && pf.spans == nil {
 		return ctx
 	}
-	return wire.WithTiming(ctx, func(label string, dur time.Duration) {
-		log.Printf("timing: %s=%s", label, dur)
+	ctx = wire.WithTiming(ctx, func(label string, dur time.Duration) {
+		pf.spans.record(label, dur)
+		if pf.timings {
+			log.Printf("timing: %s=%s", label, dur)
+		}
 	})
+	if pf.timings {
+		ctx = wire.WithMetrics(ctx, func(name string, value int64) {
+			log.Printf("metric: %s=%d", name, value)
+		})
+	}
+	return ctx
+}
+
+// span records a span for a step of the command itself, such as writing
+// output, when tracing is enabled.
+func (pf *profileFlags) span(label string, start time.Time) {
+	pf.spans.record(label, time.Since(start))
+}
+
+// packageFlags holds the flags that choose the packages a command works on,
+// shared by every command that takes package patterns.
+type packageFlags struct {
+	tags  string
+	match string
+	skip  skipFlags
 }
 
-// newGenerateOptions returns an initialized wire.GenerateOptions, possibly
-// with the Header option set.
-// newGenerateOptions builds GenerateOptions, loading the header if set.
-func newGenerateOptions(headerFile string) (*wire.GenerateOptions, error) {
-	opts := new(wire.GenerateOptions)
-	if headerFile != "" {
+// addFlags registers the package selection flags on the provided FlagSet.
+func (pf *packageFlags) addFlags(f *flag.FlagSet) {
+	addTagsFlag(f, &pf.tags)
+	f.StringVar(&pf.match, "match", "", "only process packages whose import path matches this regular expression")
+	pf.skip.addFlags(f)
+}
+
+// addTagsFlag registers the -tags flag, for the commands that load packages
+// without taking the rest of packageFlags.
+func addTagsFlag(f *flag.FlagSet, tags *string) {
+	f.StringVar(tags, "tags", "", "append build tags to the default wirebuild")
+}
+
+// outputFlags holds the flags that name the generated files, shared by the
+// commands that write them and those that look for them, so that both find
+// the same files.
+type outputFlags struct {
+	prefixFileName string
+	fileName       string
+}
+
+// addFlags registers the output file name flags on the provided FlagSet.
+func (of *outputFlags) addFlags(f *flag.FlagSet) {
+	f.StringVar(&of.prefixFileName, "output_file_prefix", "", "string to prepend to output file names")
+	f.StringVar(&of.fileName, "output_file_name", "", "template for the output file name, such as {{.Package}}_wire.gen.go (default wire_gen.go)")
+}
+
+// generateFlags holds the flags shared by the commands that run Generate, so
+// that gen, diff, and watch agree on what they generate and where.
+type generateFlags struct {
+	packageFlags
+	outputFlags
+	headerFile    string
+	packageHeader string
+	autoContext   bool
+	stableOrder   bool
+	sourceMap     bool
+	autoNames     string
+	debugDir      string
+	debugSnapshot string
+	hermetic      bool
+}
+
+// addFlags registers generation flags on the provided FlagSet.
+func (gf *generateFlags) addFlags(f *flag.FlagSet) {
+	gf.packageFlags.addFlags(f)
+	gf.outputFlags.addFlags(f)
+	f.StringVar(&gf.headerFile, "header_file", "", "path to file to insert as a header in wire_gen.go")
+	f.StringVar(&gf.packageHeader, "package_header", "", "header file to look for in each package's directory and its parents up to the module root, or a template of its path; overrides -header_file where found")
+	f.BoolVar(&gf.autoContext, "auto_context", false, "pass an injector's context.Context argument to providers even if its sets provide a context")
+	f.BoolVar(&gf.stableOrder, "stable_order", false, "order an injector's independent calls by the name of the type they build")
+	f.BoolVar(&gf.sourceMap, "source_map", false, "precede each generated call with a //wire:source comment giving the position of its provider")
+	f.StringVar(&gf.autoNames, "auto_names", "", "regular expression for the names of the functions wire.AutoSet uses as providers (default ^New)")
+	f.BoolVar(&gf.hermetic, "hermetic", false, "fail if generation depends on GOFLAGS, GOPATH, or packages outside the module, its dependencies, and GOROOT")
+	f.StringVar(&gf.debugDir, "debug_dir", "", "directory to save generated source that fails to format (default: a temporary directory)")
+	f.StringVar(&gf.debugSnapshot, "debug_snapshot", "", "if generation fails, save the loaded packages to this directory for wire replay")
+}
+
+// options builds GenerateOptions from the flags, loading the header if set.
+func (gf *generateFlags) options() (*wire.GenerateOptions, error) {
+	opts := &wire.GenerateOptions{
+		PackageHeader:    gf.packageHeader,
+		PrefixOutputFile: gf.prefixFileName,
+		OutputFileName:   gf.fileName,
+		Tags:             gf.tags,
+		AutoContext:      gf.autoContext,
+		StableOrder:      gf.stableOrder,
+		SourceMap:        gf.sourceMap,
+		AutoNames:        gf.autoNames,
+		DebugDir:         gf.debugDir,
+		DebugSnapshot:    gf.debugSnapshot,
+		Hermetic:         gf.hermetic,
+	}
+	if gf.headerFile != "" {
 		var err error
-		opts.Header, err = ioutil.ReadFile(headerFile)
+		opts.Header, err = ioutil.ReadFile(gf.headerFile)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read header file %q: %v", headerFile, err)
+			return nil, fmt.Errorf("failed to read header file %q: %v", gf.headerFile, err)
 		}
 	}
 	return opts, nil
@@ -200,6 +438,6 @@ func newGenerateOptions(headerFile string) (*wire.GenerateOptions, error) {
 // logErrors logs each error with consistent formatting.
 func logErrors(errs []error) {
 	for _, err := range errs {
-		log.Println(strings.Replace(err.Error(), "\n", "\n\t", -1))
+		log.Println(strings.Replace(relativePaths(err.Error()), "\n", "\n\t", -1))
 	}
 }
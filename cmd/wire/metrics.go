@@ -0,0 +1,128 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// durationBuckets are the upper bounds, in seconds, of the regeneration
+// duration histogram.
+var durationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// watchMetrics collects regeneration statistics for the watch loop and
+// exposes them in the Prometheus text format.
+type watchMetrics struct {
+	mu                sync.Mutex
+	runs              uint64
+	failures          uint64
+	packageCacheHits  uint64
+	manifestCacheHits uint64
+	durationCounts    []uint64
+	durationSum       float64
+	lastSuccess       time.Time
+}
+
+// newWatchMetrics returns an empty metrics collector.
+func newWatchMetrics() *watchMetrics {
+	return &watchMetrics{durationCounts: make([]uint64, len(durationBuckets))}
+}
+
+// observeRun records one regeneration pass.
+func (m *watchMetrics) observeRun(d time.Duration, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs++
+	if !ok {
+		m.failures++
+	} else {
+		m.lastSuccess = time.Now()
+	}
+	secs := d.Seconds()
+	m.durationSum += secs
+	for i, le := range durationBuckets {
+		if secs <= le {
+			m.durationCounts[i]++
+		}
+	}
+}
+
+// observeTiming records cache hits reported through wire.WithTiming.
+func (m *watchMetrics) observeTiming(label string, _ time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case label == "generate.manifest_hit":
+		m.manifestCacheHits++
+	case strings.HasPrefix(label, "generate.package.") && strings.HasSuffix(label, ".cache_hit"):
+		m.packageCacheHits++
+	}
+}
+
+// ServeHTTP writes the collected metrics.
+func (m *watchMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# HELP wire_regenerations_total Regeneration passes run by wire watch.")
+	fmt.Fprintln(w, "# TYPE wire_regenerations_total counter")
+	fmt.Fprintf(w, "wire_regenerations_total %d\n", m.runs)
+	fmt.Fprintln(w, "# HELP wire_regeneration_failures_total Regeneration passes that reported errors.")
+	fmt.Fprintln(w, "# TYPE wire_regeneration_failures_total counter")
+	fmt.Fprintf(w, "wire_regeneration_failures_total %d\n", m.failures)
+	fmt.Fprintln(w, "# HELP wire_cache_hits_total Generation results served from the wire cache.")
+	fmt.Fprintln(w, "# TYPE wire_cache_hits_total counter")
+	fmt.Fprintf(w, "wire_cache_hits_total{kind=\"manifest\"} %d\n", m.manifestCacheHits)
+	fmt.Fprintf(w, "wire_cache_hits_total{kind=\"package\"} %d\n", m.packageCacheHits)
+	fmt.Fprintln(w, "# HELP wire_regeneration_duration_seconds Wall time of regeneration passes.")
+	fmt.Fprintln(w, "# TYPE wire_regeneration_duration_seconds histogram")
+	for i, le := range durationBuckets {
+		fmt.Fprintf(w, "wire_regeneration_duration_seconds_bucket{le=\"%g\"} %d\n", le, m.durationCounts[i])
+	}
+	fmt.Fprintf(w, "wire_regeneration_duration_seconds_bucket{le=\"+Inf\"} %d\n", m.runs)
+	fmt.Fprintf(w, "wire_regeneration_duration_seconds_sum %g\n", m.durationSum)
+	fmt.Fprintf(w, "wire_regeneration_duration_seconds_count %d\n", m.runs)
+	if !m.lastSuccess.IsZero() {
+		fmt.Fprintln(w, "# HELP wire_last_success_timestamp_seconds Unix time of the last successful regeneration.")
+		fmt.Fprintln(w, "# TYPE wire_last_success_timestamp_seconds gauge")
+		fmt.Fprintf(w, "wire_last_success_timestamp_seconds %d\n", m.lastSuccess.Unix())
+	}
+}
+
+// serveMetrics starts an HTTP server exposing m at /metrics on addr. The
+// listener is opened synchronously so that address errors are reported
+// before the watch loop starts.
+func serveMetrics(addr string, m *watchMetrics) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on metrics address %s: %v", addr, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+	infof("watch: serving metrics on http://%s/metrics", ln.Addr())
+	go func() {
+		if err := http.Serve(ln, mux); err != nil {
+			log.Printf("watch: metrics server stopped: %v", err)
+		}
+	}()
+	return nil
+}
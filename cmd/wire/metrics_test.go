@@ -0,0 +1,207 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goforj/wire/internal/wire"
+)
+
+// scrapedMetrics is a page of metrics in the Prometheus text format.
+type scrapedMetrics struct {
+	help, typ map[string]string
+	// samples maps each sample, with its labels, to its value.
+	samples map[string]string
+}
+
+// scrapeMetrics serves a request for /metrics with m and parses the page,
+// checking that every sample follows the HELP and TYPE lines of its metric.
+func scrapeMetrics(t *testing.T, m *watchMetrics) scrapedMetrics {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if got, want := rec.Header().Get("Content-Type"), "text/plain; version=0.0.4"; got != want {
+		t.Errorf("metrics served with content type %q; want %q", got, want)
+	}
+	page := scrapedMetrics{help: map[string]string{}, typ: map[string]string{}, samples: map[string]string{}}
+	for _, line := range strings.Split(strings.TrimSuffix(rec.Body.String(), "\n"), "\n") {
+		if rest := strings.TrimPrefix(line, "# HELP "); rest != line {
+			name, text, _ := strings.Cut(rest, " ")
+			page.help[name] = text
+			continue
+		}
+		if rest := strings.TrimPrefix(line, "# TYPE "); rest != line {
+			name, typ, _ := strings.Cut(rest, " ")
+			if page.help[name] == "" {
+				t.Errorf("TYPE line for %s comes before its HELP line", name)
+			}
+			page.typ[name] = typ
+			continue
+		}
+		sample, value, ok := strings.Cut(line, " ")
+		if !ok {
+			t.Errorf("malformed metrics line %q", line)
+			continue
+		}
+		name, _, _ := strings.Cut(sample, "{")
+		metric := name
+		if page.typ[metric] == "" {
+			// Histogram samples belong to the metric without their
+			// suffix.
+			for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+				metric = strings.TrimSuffix(metric, suffix)
+				if page.typ[metric] != "" {
+					break
+				}
+			}
+		}
+		if page.typ[metric] == "" {
+			t.Errorf("sample %s has no TYPE line", sample)
+		}
+		page.samples[sample] = value
+	}
+	return page
+}
+
+func TestWatchMetrics(t *testing.T) {
+	repoRoot, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Keep the cache of this test to itself, so that the first run misses.
+	t.Setenv("TMPDIR", t.TempDir())
+	dir := t.TempDir()
+	wireGo := func(provider string) string {
+		return strings.Join([]string{
+			"//go:build wireinject",
+			"// +build wireinject",
+			"",
+			"package app",
+			"",
+			"import \"github.com/goforj/wire\"",
+			"",
+			"func Init() *Foo {",
+			"\twire.Build(" + provider + ")",
+			"\treturn nil",
+			"}",
+			"",
+		}, "\n")
+	}
+	files := map[string]string{
+		"go.mod":      "module example.com/app\n\ngo 1.19\n\nrequire github.com/goforj/wire v0.0.0\nreplace github.com/goforj/wire => " + repoRoot + "\n",
+		"app/app.go":  "package app\n\ntype Foo struct{}\n\nfunc NewFoo() *Foo { return &Foo{} }\n",
+		"app/wire.go": wireGo("NewFoo"),
+	}
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range files {
+		write(name, content)
+	}
+
+	m := newWatchMetrics()
+	env := append(os.Environ(), "GOWORK=off")
+	// cycle runs one regeneration as the watch loop does, reporting its
+	// timings and outcome to m.
+	cycle := func() {
+		t.Helper()
+		start := time.Now()
+		ctx := wire.WithTiming(context.Background(), m.observeTiming)
+		outs, errs := wire.Generate(ctx, dir, env, []string{"./app"}, nil)
+		ok := len(errs) == 0
+		for _, out := range outs {
+			if len(out.Errs) > 0 {
+				ok = false
+				continue
+			}
+			if err := out.Commit(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		m.observeRun(time.Since(start), ok)
+	}
+
+	page := scrapeMetrics(t, m)
+	if _, ok := page.samples["wire_last_success_timestamp_seconds"]; ok {
+		t.Error("metrics report a last success before any regeneration")
+	}
+
+	// The first run generates the package, the second is served from the
+	// manifest, and the third fails.
+	cycle()
+	cycle()
+	write("app/wire.go", wireGo("NewMissing"))
+	cycle()
+
+	page = scrapeMetrics(t, m)
+	wantTypes := map[string]string{
+		"wire_regenerations_total":            "counter",
+		"wire_regeneration_failures_total":    "counter",
+		"wire_cache_hits_total":               "counter",
+		"wire_regeneration_duration_seconds":  "histogram",
+		"wire_last_success_timestamp_seconds": "gauge",
+	}
+	for name, typ := range wantTypes {
+		if page.typ[name] != typ {
+			t.Errorf("metric %s has type %q; want %q", name, page.typ[name], typ)
+		}
+		if page.help[name] == "" {
+			t.Errorf("metric %s has no HELP text", name)
+		}
+	}
+	if len(page.typ) != len(wantTypes) {
+		t.Errorf("metrics page declares %d metrics; want %d", len(page.typ), len(wantTypes))
+	}
+	wantSamples := map[string]string{
+		"wire_regenerations_total":                               "3",
+		"wire_regeneration_failures_total":                       "1",
+		"wire_cache_hits_total{kind=\"manifest\"}":               "1",
+		"wire_cache_hits_total{kind=\"package\"}":                "0",
+		"wire_regeneration_duration_seconds_bucket{le=\"+Inf\"}": "3",
+		"wire_regeneration_duration_seconds_count":               "3",
+		"wire_regeneration_duration_seconds_bucket{le=\"30\"}":   "3",
+	}
+	for sample, want := range wantSamples {
+		if got := page.samples[sample]; got != want {
+			t.Errorf("sample %s = %q; want %q", sample, got, want)
+		}
+	}
+	if got := page.samples["wire_last_success_timestamp_seconds"]; got == "" || got == "0" {
+		t.Errorf("wire_last_success_timestamp_seconds = %q; want the time of the second run", got)
+	}
+	if got := page.samples["wire_regeneration_duration_seconds_sum"]; got == "" || got == "0" {
+		t.Errorf("wire_regeneration_duration_seconds_sum = %q; want the total time of the runs", got)
+	}
+}
+
+func TestServeMetricsBadAddress(t *testing.T) {
+	if err := serveMetrics("not an address", newWatchMetrics()); err == nil {
+		t.Error("serveMetrics on a malformed address succeeded")
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"os"
+)
+
+// offline is set by the -offline flag of every command.
+var offline bool
+
+// addOfflineFlags registers the -offline flag. It defaults to its current
+// value, so that a command keeps -offline given before its name.
+func addOfflineFlags(f *flag.FlagSet) {
+	f.BoolVar(&offline, "offline", offline, "never download modules, so that a module missing from the module cache fails at once instead of waiting on the network")
+}
+
+// applyOffline disables module downloads if -offline is set. Every command
+// loads packages with os.Environ(), so setting GOPROXY=off here covers the
+// go commands they all run, including checksum database lookups, which go
+// through the proxy.
+func applyOffline() {
+	if offline {
+		os.Setenv("GOPROXY", "off")
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// otelEndpointEnv names the environment variable holding the OTLP/HTTP
+// endpoint that generation spans are exported to, such as
+// "http://localhost:4318".
+const otelEndpointEnv = "WIRE_OTEL_ENDPOINT"
+
+// spanRecorder turns the timings reported through wire.WithTiming into
+// OpenTelemetry spans and exports them with OTLP over HTTP, using the JSON
+// encoding so that no SDK is needed. A nil *spanRecorder records nothing.
+type spanRecorder struct {
+	endpoint string
+	client   *http.Client
+
+	mu    sync.Mutex
+	spans []recordedSpan
+}
+
+// recordedSpan is a timed step of a run, named by its timing label.
+type recordedSpan struct {
+	label      string
+	start, end time.Time
+}
+
+// newSpanRecorder returns a recorder exporting to the endpoint named by
+// WIRE_OTEL_ENDPOINT, or nil if it is not set.
+func newSpanRecorder() *spanRecorder {
+	endpoint := strings.TrimSpace(os.Getenv(otelEndpointEnv))
+	if endpoint == "" {
+		return nil
+	}
+	if !strings.HasSuffix(endpoint, "/v1/traces") {
+		endpoint = strings.TrimSuffix(endpoint, "/") + "/v1/traces"
+	}
+	return &spanRecorder{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// fork returns an empty recorder exporting to the same endpoint as r.
+func (r *spanRecorder) fork() *spanRecorder {
+	if r == nil {
+		return nil
+	}
+	return &spanRecorder{endpoint: r.endpoint, client: r.client}
+}
+
+// record adds a span for a step labeled label that just took dur.
+func (r *spanRecorder) record(label string, dur time.Duration) {
+	if r == nil {
+		return
+	}
+	end := time.Now()
+	r.mu.Lock()
+	r.spans = append(r.spans, recordedSpan{label: label, start: end.Add(-dur), end: end})
+	r.mu.Unlock()
+}
+
+// export sends the spans recorded so far, if any, as one trace under a root
+// span named name that started at start, and forgets them. Export failures
+// are logged but do not affect the run.
+func (r *spanRecorder) export(name string, start time.Time) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	spans := r.spans
+	r.spans = nil
+	r.mu.Unlock()
+	if len(spans) == 0 {
+		return
+	}
+	body, err := json.Marshal(otlpRequest(name, start, time.Now(), spans))
+	if err != nil {
+		log.Printf("otel: failed to encode spans: %v", err)
+		return
+	}
+	resp, err := r.client.Post(r.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("otel: failed to export spans: %v", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		log.Printf("otel: failed to export spans: %s returned %s", r.endpoint, resp.Status)
+	}
+}
+
+// The OTLP/JSON encoding of a trace export request. See
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding.
+type (
+	otlpExport struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string          `json:"traceId"`
+		SpanID            string          `json:"spanId"`
+		ParentSpanID      string          `json:"parentSpanId,omitempty"`
+		Name              string          `json:"name"`
+		Kind              int             `json:"kind"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	}
+	otlpAttribute struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue string `json:"stringValue"`
+	}
+)
+
+// otlpSpanKindInternal is SPAN_KIND_INTERNAL.
+const otlpSpanKindInternal = 1
+
+// otlpRequest builds the export request for a trace rooted at a span named
+// name. Each recorded span is parented to the span of the step that encloses
+// it, found from its label: "generate.package.P.format" belongs to
+// "generate.package.P.total", and "load.packages.base.load" to
+// "load.packages". Steps without an enclosing step belong to the root.
+func otlpRequest(name string, start, end time.Time, spans []recordedSpan) *otlpExport {
+	traceID := randomHex(16)
+	root := otlpSpan{
+		TraceID:           traceID,
+		SpanID:            randomHex(8),
+		Name:              name,
+		Kind:              otlpSpanKindInternal,
+		StartTimeUnixNano: unixNano(start),
+		EndTimeUnixNano:   unixNano(end),
+		Attributes:        []otlpAttribute{stringAttribute("wire.args", strings.Join(os.Args[1:], " "))},
+	}
+	ids := make([]string, len(spans))
+	byLabel := make(map[string]int, len(spans))
+	for i, s := range spans {
+		ids[i] = randomHex(8)
+		byLabel[s.label] = i
+	}
+	out := []otlpSpan{root}
+	for i, s := range spans {
+		parent := root.SpanID
+		for p := s.label; ; {
+			dot := strings.LastIndexByte(p, '.')
+			if dot < 0 {
+				break
+			}
+			p = p[:dot]
+			if j, ok := parentSpan(spans, byLabel, i, p); ok {
+				parent = ids[j]
+				break
+			}
+		}
+		span := otlpSpan{
+			TraceID:           traceID,
+			SpanID:            ids[i],
+			ParentSpanID:      parent,
+			Name:              s.label,
+			Kind:              otlpSpanKindInternal,
+			StartTimeUnixNano: unixNano(s.start),
+			EndTimeUnixNano:   unixNano(s.end),
+		}
+		// Name per-package steps by the step alone, so that spans of
+		// different packages can be aggregated, and record the package
+		// as an attribute.
+		for _, prefix := range []string{"generate.package.", "load.package."} {
+			if rest := strings.TrimPrefix(s.label, prefix); rest != s.label {
+				if dot := strings.LastIndexByte(rest, '.'); dot > 0 {
+					span.Name = prefix + rest[dot+1:]
+					span.Attributes = []otlpAttribute{stringAttribute("wire.package", rest[:dot])}
+				}
+			}
+		}
+		out = append(out, span)
+	}
+	return &otlpExport{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: []otlpAttribute{stringAttribute("service.name", "wire")}},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "github.com/goforj/wire"}, Spans: out}},
+	}}}
+}
+
+// parentSpan returns the index of the span labeled prefix or prefix+".total"
+// that encloses spans[i] in time, if any.
+func parentSpan(spans []recordedSpan, byLabel map[string]int, i int, prefix string) (int, bool) {
+	for _, label := range []string{prefix + ".total", prefix} {
+		j, ok := byLabel[label]
+		if !ok || j == i {
+			continue
+		}
+		if !spans[j].start.After(spans[i].start) && !spans[j].end.Before(spans[i].end) {
+			return j, true
+		}
+	}
+	return 0, false
+}
+
+func stringAttribute(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{StringValue: value}}
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// randomHex returns n random bytes, hex encoded.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("reading random bytes: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,245 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// decodedExport is the OTLP/JSON export request as a collector decodes it,
+// declared apart from the types that encode it so that the field names are
+// checked too.
+type decodedExport struct {
+	ResourceSpans []struct {
+		Resource struct {
+			Attributes []decodedAttribute `json:"attributes"`
+		} `json:"resource"`
+		ScopeSpans []struct {
+			Scope struct {
+				Name string `json:"name"`
+			} `json:"scope"`
+			Spans []decodedSpan `json:"spans"`
+		} `json:"scopeSpans"`
+	} `json:"resourceSpans"`
+}
+
+type decodedSpan struct {
+	TraceID           string             `json:"traceId"`
+	SpanID            string             `json:"spanId"`
+	ParentSpanID      *string            `json:"parentSpanId"`
+	Name              string             `json:"name"`
+	Kind              int                `json:"kind"`
+	StartTimeUnixNano string             `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string             `json:"endTimeUnixNano"`
+	Attributes        []decodedAttribute `json:"attributes"`
+}
+
+type decodedAttribute struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+// decodeExport decodes an export request and checks the parts that do not
+// depend on the spans, returning its spans.
+func decodeExport(t *testing.T, body []byte) []decodedSpan {
+	t.Helper()
+	var req decodedExport
+	if err := json.Unmarshal(body, &req); err != nil {
+		t.Fatalf("failed to decode export request: %v\n%s", err, body)
+	}
+	if len(req.ResourceSpans) != 1 || len(req.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("export request has %d resource spans; want 1 with 1 scope:\n%s", len(req.ResourceSpans), body)
+	}
+	rs := req.ResourceSpans[0]
+	if got := attributes(rs.Resource.Attributes); got["service.name"] != "wire" || len(got) != 1 {
+		t.Errorf("resource attributes = %v; want service.name=wire", got)
+	}
+	if got, want := rs.ScopeSpans[0].Scope.Name, "github.com/goforj/wire"; got != want {
+		t.Errorf("scope name = %q; want %q", got, want)
+	}
+	return rs.ScopeSpans[0].Spans
+}
+
+func attributes(attrs []decodedAttribute) map[string]string {
+	m := make(map[string]string, len(attrs))
+	for _, a := range attrs {
+		m[a.Key] = a.Value.StringValue
+	}
+	return m
+}
+
+func TestOTLPRequest(t *testing.T) {
+	t0 := time.Unix(1700000000, 123456789)
+	at := func(ms int) time.Time { return t0.Add(time.Duration(ms) * time.Millisecond) }
+	spans := []recordedSpan{
+		{label: "load.packages", start: at(0), end: at(50)},
+		{label: "load.packages.base.load", start: at(10), end: at(40)},
+		// Labeled like a step of load.packages, but outside it in time.
+		{label: "load.packages.late", start: at(45), end: at(55)},
+		{label: "generate.package.example.com/app.total", start: at(60), end: at(100)},
+		{label: "generate.package.example.com/app.format", start: at(70), end: at(80)},
+		{label: "cache.read", start: at(101), end: at(102)},
+	}
+	body, err := json.Marshal(otlpRequest("wire gen", t0, at(110), spans))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := decodeExport(t, body)
+	if len(got) != len(spans)+1 {
+		t.Fatalf("export request has %d spans; want the root and %d recorded", len(got), len(spans))
+	}
+
+	root := got[0]
+	traceID := regexp.MustCompile(`^[0-9a-f]{32}$`)
+	spanID := regexp.MustCompile(`^[0-9a-f]{16}$`)
+	if !traceID.MatchString(root.TraceID) || root.TraceID == "00000000000000000000000000000000" {
+		t.Errorf("trace ID = %q; want 16 random bytes in hex", root.TraceID)
+	}
+	if root.ParentSpanID != nil {
+		t.Errorf("root span has parent %q; want none", *root.ParentSpanID)
+	}
+	if root.Name != "wire gen" {
+		t.Errorf("root span name = %q; want %q", root.Name, "wire gen")
+	}
+	if _, ok := attributes(root.Attributes)["wire.args"]; !ok {
+		t.Errorf("root span attributes = %v; want wire.args", attributes(root.Attributes))
+	}
+	ids := map[string]int{}
+	for i, s := range got {
+		if s.TraceID != root.TraceID {
+			t.Errorf("span %d has trace ID %q; want %q", i, s.TraceID, root.TraceID)
+		}
+		if !spanID.MatchString(s.SpanID) {
+			t.Errorf("span %d has span ID %q; want 8 bytes in hex", i, s.SpanID)
+		}
+		if j, ok := ids[s.SpanID]; ok {
+			t.Errorf("spans %d and %d share the span ID %q", j, i, s.SpanID)
+		}
+		ids[s.SpanID] = i
+		if s.Kind != otlpSpanKindInternal {
+			t.Errorf("span %d has kind %d; want %d", i, s.Kind, otlpSpanKindInternal)
+		}
+	}
+	wantTimes := append([]recordedSpan{{start: t0, end: at(110)}}, spans...)
+	for i, w := range wantTimes {
+		start, end := strconv.FormatInt(w.start.UnixNano(), 10), strconv.FormatInt(w.end.UnixNano(), 10)
+		if got[i].StartTimeUnixNano != start || got[i].EndTimeUnixNano != end {
+			t.Errorf("span %d runs from %s to %s; want %s to %s", i, got[i].StartTimeUnixNano, got[i].EndTimeUnixNano, start, end)
+		}
+	}
+
+	tests := []struct {
+		name string
+		// parent is the index in got of the span's parent.
+		parent int
+		attrs  map[string]string
+	}{
+		{name: "load.packages", parent: 0},
+		{name: "load.packages.base.load", parent: 1},
+		{name: "load.packages.late", parent: 0},
+		{name: "generate.package.total", parent: 0, attrs: map[string]string{"wire.package": "example.com/app"}},
+		{name: "generate.package.format", parent: 4, attrs: map[string]string{"wire.package": "example.com/app"}},
+		{name: "cache.read", parent: 0},
+	}
+	for i, test := range tests {
+		s := got[i+1]
+		if s.Name != test.name {
+			t.Errorf("span for %s is named %q; want %q", spans[i].label, s.Name, test.name)
+		}
+		if s.ParentSpanID == nil || *s.ParentSpanID != got[test.parent].SpanID {
+			t.Errorf("span for %s has parent %v; want span %d (%s)", spans[i].label, s.ParentSpanID, test.parent, got[test.parent].Name)
+		}
+		attrs := attributes(s.Attributes)
+		if len(attrs) != len(test.attrs) {
+			t.Errorf("span for %s has attributes %v; want %v", spans[i].label, attrs, test.attrs)
+			continue
+		}
+		for k, v := range test.attrs {
+			if attrs[k] != v {
+				t.Errorf("span for %s has attributes %v; want %v", spans[i].label, attrs, test.attrs)
+			}
+		}
+	}
+}
+
+func TestSpanRecorderExport(t *testing.T) {
+	var bodies [][]byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("export sent to %s with content type %q; want /v1/traces with application/json", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		bodies = append(bodies, body)
+	}))
+	defer srv.Close()
+
+	t.Setenv(otelEndpointEnv, "")
+	if r := newSpanRecorder(); r != nil {
+		t.Errorf("newSpanRecorder without %s = %+v; want nil", otelEndpointEnv, r)
+	}
+	// A nil recorder records and exports nothing.
+	var none *spanRecorder
+	none.record("load.packages", time.Millisecond)
+	none.export("wire gen", time.Now())
+	if none.fork() != nil {
+		t.Error("fork of a nil recorder is not nil")
+	}
+
+	t.Setenv(otelEndpointEnv, srv.URL+"/")
+	r := newSpanRecorder()
+	if r == nil || r.endpoint != srv.URL+"/v1/traces" {
+		t.Fatalf("newSpanRecorder with %s=%s/ = %+v; want endpoint %s/v1/traces", otelEndpointEnv, srv.URL, r, srv.URL)
+	}
+	start := time.Now()
+	r.export("wire gen", start)
+	if len(bodies) != 0 {
+		t.Errorf("export without spans sent %d requests; want none", len(bodies))
+	}
+
+	// Each fork exports its own trace.
+	a, b := r.fork(), r.fork()
+	a.record("load.packages", time.Millisecond)
+	b.record("cache.read", time.Millisecond)
+	b.record("cache.write", time.Millisecond)
+	a.export("wire watch", start)
+	b.export("wire watch", start)
+	if len(bodies) != 2 {
+		t.Fatalf("exporting two forks sent %d requests; want 2", len(bodies))
+	}
+	first, second := decodeExport(t, bodies[0]), decodeExport(t, bodies[1])
+	if len(first) != 2 || len(second) != 3 {
+		t.Errorf("forks exported %d and %d spans; want 2 and 3", len(first), len(second))
+	}
+	if first[0].TraceID == second[0].TraceID {
+		t.Error("forks exported spans of the same trace; want a trace each")
+	}
+	// Exporting forgets the spans.
+	a.export("wire watch", start)
+	if len(bodies) != 2 {
+		t.Errorf("exporting again sent %d requests; want 2", len(bodies))
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/goforj/wire/internal/wire"
+	"github.com/google/subcommands"
+)
+
+type replayCmd struct {
+	write bool
+}
+
+// Name returns the subcommand name.
+func (*replayCmd) Name() string { return "replay" }
+
+// Synopsis returns a short summary of the subcommand.
+func (*replayCmd) Synopsis() string {
+	return "reproduce a failed generation from a -debug_snapshot directory"
+}
+
+// Usage returns the help text for the subcommand.
+func (*replayCmd) Usage() string {
+	return `replay [-write] dir
+
+  replay repeats a generation run saved by gen -debug_snapshot dir, with the
+  same packages and options, and prints its errors. It is meant for
+  debugging Wire: the snapshot holds copies of every package the run loaded
+  outside GOROOT, so a failure can be reproduced without the repository it
+  happened in. The errors of the original run are listed in the snapshot's
+  snapshot.json.
+
+  With -write, the generated files are written to the snapshot's copy of
+  the packages.
+`
+}
+
+// SetFlags registers flags for the subcommand.
+func (cmd *replayCmd) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&cmd.write, "write", false, "write generated files into the snapshot")
+}
+
+// Execute runs the subcommand.
+func (cmd *replayCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
+	if f.NArg() != 1 {
+		log.Println("replay takes exactly one snapshot directory")
+		return subcommands.ExitUsageError
+	}
+	outs, errs := wire.Replay(ctx, f.Arg(0), os.Environ())
+	if len(errs) > 0 {
+		logErrors(errs.Errors())
+		log.Println("replay failed")
+		return subcommands.ExitFailure
+	}
+	success := true
+	for _, out := range outs {
+		if len(out.Errs) > 0 {
+			logErrors(out.Errs.Errors())
+			log.Printf("%s: generate failed\n", out.PkgPath)
+			success = false
+		}
+		if !cmd.write || len(out.Content) == 0 {
+			continue
+		}
+		if err := out.Commit(); err != nil {
+			log.Printf("%s: failed to write %s: %v\n", out.PkgPath, out.OutputPath, err)
+			success = false
+			continue
+		}
+		infof("%s: wrote %s\n", out.PkgPath, out.OutputPath)
+	}
+	if !success {
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
@@ -34,8 +34,12 @@ import (
 )
 
 type showCmd struct {
-	tags    string
-	profile profileFlags
+	packageFlags
+	outputFlags
+	allModules bool
+	order      bool
+	short      bool
+	profile    profileFlags
 }
 
 // Name returns the subcommand name.
@@ -48,53 +52,83 @@ func (*showCmd) Synopsis() string {
 
 // Usage returns the help text for the subcommand.
 func (*showCmd) Usage() string {
-	return `show [packages]
+	return `show [-match regexp] [-all-modules] [-order] [-short] [-output_file_prefix prefix] [-output_file_name template] [packages]
 
   Given one or more packages, show finds all the provider sets declared as
   top-level variables and prints what other provider sets they import and what
-  outputs they can produce, given possible inputs. It also lists any injector
-  functions defined in the package.
+  outputs they can produce, given possible inputs. Each interface output
+  names the concrete type bound to it and the wire.Bind call that binds it,
+  or the wire.InterfaceValue call that supplies it. It also lists any injector
+  functions defined in the package, along with the chain of providers used to
+  build each injector's result. When an injector's generated code is up to
+  date, each step of the chain also gives the line of the generated file
+  that constructs it.
 
-  If no packages are listed, it defaults to ".".
+  With -order, show instead lists each injector's steps in the order the
+  generated code constructs them, so that every value comes after the values
+  it depends on. Runtime frameworks such as shutdown managers can mirror this
+  order (reversed for shutdown) without re-deriving it.
+
+  With -short, show prints one line per provider set, as
+  "path".Set: N providers, M outputs, K imports, and one per injector, as
+  "path".Injector: inputs -> output, for grepping and for summarizing wiring
+  changes in scripts.
+
+  If no packages are listed, it defaults to ".". With -all-modules, show
+  instead loads every package of every module in the current go.work
+  workspace, so that sets and injectors spread across sibling modules are
+  described in one invocation.
 `
 }
 
 // SetFlags registers flags for the subcommand.
 func (cmd *showCmd) SetFlags(f *flag.FlagSet) {
-	f.StringVar(&cmd.tags, "tags", "", "append build tags to the default wirebuild")
+	cmd.packageFlags.addFlags(f)
+	cmd.outputFlags.addFlags(f)
+	f.BoolVar(&cmd.allModules, "all-modules", false, "load all modules of the go.work workspace instead of the listed packages")
+	f.BoolVar(&cmd.order, "order", false, "list injector steps in construction order instead of as a chain")
+	f.BoolVar(&cmd.short, "short", false, "print one line per provider set and per injector")
 	cmd.profile.addFlags(f)
 }
 
 // Execute runs the subcommand.
 func (cmd *showCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
-	stop, err := cmd.profile.start()
+	stop, err := cmd.profile.start(cmd.Name())
 	if err != nil {
 		log.Println(err)
 		return subcommands.ExitFailure
 	}
 	defer stop()
 	totalStart := time.Now()
-	ctx = withTiming(ctx, cmd.profile.timings)
+	ctx = cmd.profile.withTiming(ctx)
 
 	wd, err := os.Getwd()
 	if err != nil {
 		log.Println("failed to get working directory: ", err)
 		return subcommands.ExitFailure
 	}
+	env := os.Environ()
+	var pkgs []string
+	if cmd.allModules {
+		if f.NArg() > 0 {
+			log.Println("-all-modules does not accept package arguments")
+			return subcommands.ExitFailure
+		}
+		pkgs, err = workspacePackages(ctx, wd, env, cmd.tags, cmd.match, cmd.skip)
+	} else {
+		pkgs, err = matchedPackages(ctx, wd, env, cmd.tags, cmd.match, cmd.skip, f)
+	}
+	if err != nil {
+		log.Println(err)
+		return subcommands.ExitFailure
+	}
 	loadStart := time.Now()
-	info, errs := wire.Load(ctx, wd, os.Environ(), cmd.tags, packages(f))
+	info, errs := wire.Load(ctx, wd, env, cmd.tags, pkgs)
 	logTiming(cmd.profile.timings, "wire.Load", loadStart)
-	if info != nil {
-		keys := make([]wire.ProviderSetID, 0, len(info.Sets))
-		for k := range info.Sets {
-			keys = append(keys, k)
-		}
-		sort.Slice(keys, func(i, j int) bool {
-			if keys[i].ImportPath == keys[j].ImportPath {
-				return keys[i].VarName < keys[j].VarName
-			}
-			return keys[i].ImportPath < keys[j].ImportPath
-		})
+	if info != nil && cmd.short {
+		printShortSummary(info)
+	} else if info != nil {
+		keys := sortedSetIDs(info)
 		for i, k := range keys {
 			if i > 0 {
 				fmt.Println()
@@ -107,14 +141,18 @@ func (cmd *showCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interf
 			for i := range outGroups {
 				fmt.Printf("\tOutputs given %s:\n", outGroups[i].name)
 				out := make(map[string]token.Pos, outGroups[i].outputs.Len())
+				bound := make(map[string]string)
 				outGroups[i].outputs.Iterate(func(t types.Type, v interface{}) {
+					if line := bindingLine(info.Fset, info.Sets[k], t); line != "" {
+						bound[wire.TypeString(t)] = line
+					}
 					switch v := v.(type) {
 					case *wire.Provider:
-						out[types.TypeString(t, nil)] = v.Pos
+						out[wire.TypeString(t)] = v.Pos
 					case *wire.Value:
-						out[types.TypeString(t, nil)] = v.Pos
+						out[wire.TypeString(t)] = v.Pos
 					case *wire.Field:
-						out[types.TypeString(t, nil)] = v.Pos
+						out[wire.TypeString(t)] = v.Pos
 					default:
 						panic("unreachable")
 					}
@@ -122,20 +160,22 @@ func (cmd *showCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interf
 				for _, t := range sortSet(out) {
 					fmt.Printf("\t\t%s\n", t)
 					fmt.Printf("\t\t\tat %v\n", info.Fset.Position(out[t]))
+					if line := bound[t]; line != "" {
+						fmt.Printf("\t\t\t%s\n", line)
+					}
 				}
 			}
 		}
 		if len(info.Injectors) > 0 {
-			injectors := append([]*wire.Injector(nil), info.Injectors...)
-			sort.Slice(injectors, func(i, j int) bool {
-				if injectors[i].ImportPath == injectors[j].ImportPath {
-					return injectors[i].FuncName < injectors[j].FuncName
-				}
-				return injectors[i].ImportPath < injectors[j].ImportPath
-			})
 			fmt.Println("\nInjectors:")
-			for _, in := range injectors {
+			for _, in := range sortedInjectors(info.Injectors) {
 				fmt.Printf("\t%v\n", in)
+				generated := wire.GeneratedPositions(info.Fset, in, cmd.prefixFileName, cmd.fileName)
+				if cmd.order {
+					printInjectorOrder(info.Fset, in, generated)
+				} else {
+					printInjectorChain(info.Fset, in, generated)
+				}
 			}
 		}
 	}
@@ -148,6 +188,192 @@ func (cmd *showCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interf
 	return subcommands.ExitSuccess
 }
 
+// sortedSetIDs returns the IDs of the provider sets of info, sorted by
+// import path and then by name.
+func sortedSetIDs(info *wire.Info) []wire.ProviderSetID {
+	keys := make([]wire.ProviderSetID, 0, len(info.Sets))
+	for k := range info.Sets {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].ImportPath == keys[j].ImportPath {
+			return keys[i].VarName < keys[j].VarName
+		}
+		return keys[i].ImportPath < keys[j].ImportPath
+	})
+	return keys
+}
+
+// printShortSummary prints a line for each provider set of info, with the
+// number of providers, values, and fields that produce its outputs, the
+// number of outputs, and the number of sets it imports, and a line for each
+// injector, with its inputs and output.
+func printShortSummary(info *wire.Info) {
+	for _, k := range sortedSetIDs(info) {
+		set := info.Sets[k]
+		_, imports := gather(info, k)
+		sources := make(map[interface{}]bool)
+		outputs := set.Outputs()
+		for _, t := range outputs {
+			switch pt := set.For(t); {
+			case pt.IsProvider():
+				sources[pt.Provider()] = true
+			case pt.IsValue():
+				sources[pt.Value()] = true
+			case pt.IsField():
+				sources[pt.Field()] = true
+			}
+		}
+		fmt.Printf("%s: %s, %s, %s\n", k, count(len(sources), "provider"), count(len(outputs), "output"), count(len(imports), "import"))
+	}
+	for _, in := range sortedInjectors(info.Injectors) {
+		inputs := "no inputs"
+		if in.Params.Len() > 0 {
+			names := make([]string, in.Params.Len())
+			for i := range names {
+				names[i] = wire.TypeString(in.Params.At(i).Type())
+			}
+			inputs = strings.Join(names, ", ")
+		}
+		output := "nothing"
+		if in.Out != nil {
+			output = wire.TypeString(in.Out)
+		}
+		fmt.Printf("%v: %s -> %s\n", in, inputs, output)
+	}
+}
+
+// count returns n followed by noun, in the plural unless n is 1.
+func count(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// bindingLine describes how set satisfies the interface type t: the
+// concrete type bound to it and the wire.Bind call doing so, or the
+// wire.InterfaceValue call supplying it. It returns "" for other types.
+func bindingLine(fset *token.FileSet, set *wire.ProviderSet, t types.Type) string {
+	if set == nil || !types.IsInterface(t) {
+		return ""
+	}
+	binding, value := set.InterfaceSource(t)
+	switch {
+	case binding != nil:
+		return fmt.Sprintf("bound to %s by wire.Bind at %v", wire.TypeString(binding.Provided), fset.Position(binding.Pos))
+	case value != nil:
+		return fmt.Sprintf("supplied by wire.InterfaceValue at %v", fset.Position(value.Pos))
+	}
+	return ""
+}
+
+// sortedInjectors returns a copy of injectors sorted by package and name.
+func sortedInjectors(injectors []*wire.Injector) []*wire.Injector {
+	sorted := append([]*wire.Injector(nil), injectors...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].ImportPath == sorted[j].ImportPath {
+			return sorted[i].FuncName < sorted[j].FuncName
+		}
+		return sorted[i].ImportPath < sorted[j].ImportPath
+	})
+	return sorted
+}
+
+// printInjectorChain prints the providers used to build an injector's result,
+// starting from the result and descending through each provider's inputs.
+// generated holds the positions in the generated code of the injector's
+// steps, if known.
+func printInjectorChain(fset *token.FileSet, in *wire.Injector, generated []token.Position) {
+	printed := make(map[int]bool)
+	var visit func(t types.Type, idx int, depth int)
+	visit = func(t types.Type, idx int, depth int) {
+		indent := strings.Repeat("\t", depth)
+		name := wire.TypeString(t)
+		if idx < in.Params.Len() {
+			fmt.Printf("%s%s <- injector argument %s\n", indent, name, in.Params.At(idx).Name())
+			return
+		}
+		step := in.Steps[idx-in.Params.Len()]
+		if !types.Identical(t, step.Out) {
+			name += " (bound to " + wire.TypeString(step.Out) + ")"
+		}
+		if printed[idx] {
+			fmt.Printf("%s%s (see above)\n", indent, name)
+			return
+		}
+		printed[idx] = true
+		desc := describeStep(fset, step)
+		if generated != nil {
+			desc += ", built at " + generated[idx-in.Params.Len()].String()
+		}
+		fmt.Printf("%s%s <- %s\n", indent, name, desc)
+		for i, arg := range step.Args {
+			visit(stepInputType(step, i), arg, depth+1)
+		}
+	}
+	if idx := in.OutIndex(); idx >= 0 {
+		visit(in.Out, idx, 2)
+		return
+	}
+	if in.Out != nil {
+		return
+	}
+	// An injector without a result builds its steps for their side effects,
+	// so the chains start at each step that no other step consumes.
+	consumed := make(map[int]bool)
+	for _, step := range in.Steps {
+		for _, arg := range step.Args {
+			consumed[arg] = true
+		}
+	}
+	for i, step := range in.Steps {
+		if idx := in.Params.Len() + i; !consumed[idx] {
+			visit(step.Out, idx, 2)
+		}
+	}
+}
+
+// printInjectorOrder prints the steps of an injector in construction order.
+// generated holds the positions in the generated code of the injector's
+// steps, if known.
+func printInjectorOrder(fset *token.FileSet, in *wire.Injector, generated []token.Position) {
+	for i, step := range in.Steps {
+		desc := describeStep(fset, step)
+		if generated != nil {
+			desc += ", built at " + generated[i].String()
+		}
+		fmt.Printf("\t\t%d. %s <- %s\n", i+1, wire.TypeString(step.Out), desc)
+	}
+}
+
+// describeStep renders the source of an injector step for display.
+func describeStep(fset *token.FileSet, step wire.InjectorStep) string {
+	switch {
+	case step.Provider != nil:
+		kind := "provider"
+		if step.Provider.IsStruct {
+			kind = "struct provider"
+		}
+		return fmt.Sprintf("%s %s.%s at %v", kind, step.Provider.Pkg.Path(), step.Provider.Name, fset.Position(step.Provider.Pos))
+	case step.Value != nil:
+		return fmt.Sprintf("wire.Value at %v", fset.Position(step.Value.Pos))
+	case step.Field != nil:
+		return fmt.Sprintf("field %s of %s at %v", step.Field.Name, wire.TypeString(step.Field.Parent), fset.Position(step.Field.Pos))
+	default:
+		panic("unreachable")
+	}
+}
+
+// stepInputType returns the type requested by the i'th input of step, which
+// may be an interface bound to the type of the step that satisfies it.
+func stepInputType(step wire.InjectorStep, i int) types.Type {
+	if step.Provider != nil {
+		return step.Provider.Args[i].Type
+	}
+	return step.Field.Parent
+}
+
 type outGroup struct {
 	name    string
 	inputs  *typeutil.Map // values are not important
@@ -315,7 +541,7 @@ func gather(info *wire.Info, key wire.ProviderSetID) (_ []outGroup, imports map[
 		}
 		instr := make([]string, 0, groups[i].inputs.Len())
 		groups[i].inputs.Iterate(func(k types.Type, _ interface{}) {
-			instr = append(instr, types.TypeString(k, nil))
+			instr = append(instr, wire.TypeString(k))
 		})
 		sort.Strings(instr)
 		groups[i].name = strings.Join(instr, ", ")
@@ -0,0 +1,395 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/google/subcommands"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	releasesURL        = "https://api.github.com/repos/goforj/wire/releases"
+	checksumsAssetName = "checksums.txt"
+	signatureAssetName = checksumsAssetName + ".sig"
+
+	// maxMetadataSize bounds the size of the release description, the
+	// checksums, and their signature, and maxBinarySize that of the binary,
+	// so that a misbehaving server cannot exhaust memory.
+	maxMetadataSize = 1 << 20
+	maxBinarySize   = 256 << 20
+)
+
+// releaseSigningKey is the base64 Ed25519 public key whose signature over
+// checksums.txt update requires. Release builds pin it with
+// -ldflags "-X main.releaseSigningKey=...", so that a binary only trusts
+// the key it was released with; builds without it cannot update
+// themselves.
+var releaseSigningKey string
+
+// updateCmd implements the wire update subcommand.
+type updateCmd struct {
+	check          bool
+	version        string
+	allowDowngrade bool
+	timeout        time.Duration
+}
+
+// Name returns the subcommand name.
+func (*updateCmd) Name() string { return "update" }
+
+// Synopsis returns a short summary of the subcommand.
+func (*updateCmd) Synopsis() string {
+	return "replace the wire binary with the latest release"
+}
+
+// Usage returns the help text for the subcommand.
+func (*updateCmd) Usage() string {
+	return `update [-check] [-version tag] [-allow_downgrade]
+
+  update downloads the wire binary for the current platform from the
+  goforj/wire GitHub releases, verifies the Ed25519 signature of the
+  release's checksums.txt asset, in checksums.txt.sig, against the key the
+  running binary was released with, verifies the binary's SHA-256 checksum
+  against checksums.txt, and replaces the running executable. A tampered
+  release, whose checksums were not signed with that key, is refused.
+
+  Binaries built with go install carry no release key and cannot update
+  themselves; run go install again instead.
+
+  Release binaries are expected to be named wire_GOOS_GOARCH (with a .exe
+  suffix on Windows). With -check, update only reports whether a newer
+  release is available.
+
+  Versions are compared as semantic versions. update refuses to replace the
+  running binary with an older release, such as one named by -version,
+  unless -allow_downgrade is set.
+`
+}
+
+// SetFlags registers flags for the subcommand.
+func (cmd *updateCmd) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&cmd.check, "check", false, "only report whether an update is available")
+	f.StringVar(&cmd.version, "version", "", "release tag to install instead of the latest release")
+	f.BoolVar(&cmd.allowDowngrade, "allow_downgrade", false, "install the release even if it is older than the running binary")
+	f.DurationVar(&cmd.timeout, "timeout", 2*time.Minute, "timeout for release downloads")
+}
+
+// Execute runs the subcommand.
+func (cmd *updateCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
+	ctx, cancel := context.WithTimeout(ctx, cmd.timeout)
+	defer cancel()
+
+	rel, err := fetchRelease(ctx, cmd.version)
+	if err != nil {
+		log.Printf("update: %v", err)
+		return subcommands.ExitFailure
+	}
+	current := currentVersion()
+	order, err := compareRelease(rel.TagName, current)
+	if err != nil {
+		log.Printf("update: %v", err)
+		return subcommands.ExitFailure
+	}
+	switch {
+	case order == 0:
+		infof("update: already at %s", current)
+		return subcommands.ExitSuccess
+	case order < 0 && cmd.check:
+		infof("update: %s is older than the running %s", rel.TagName, current)
+		return subcommands.ExitSuccess
+	case order < 0 && !cmd.allowDowngrade:
+		log.Printf("update: %s is older than the running %s; pass -allow_downgrade to install it anyway", rel.TagName, current)
+		return subcommands.ExitFailure
+	case cmd.check:
+		fmt.Printf("%s -> %s\n", current, rel.TagName)
+		return subcommands.ExitSuccess
+	}
+
+	if releaseSigningKey == "" {
+		log.Println("update: this wire binary was not built with a release signing key, so it cannot verify a release; install with go install instead")
+		return subcommands.ExitFailure
+	}
+	name := binaryAssetName(runtime.GOOS, runtime.GOARCH)
+	bin, ok := rel.asset(name)
+	if !ok {
+		log.Printf("update: release %s has no binary %s", rel.TagName, name)
+		return subcommands.ExitFailure
+	}
+	sums, ok := rel.asset(checksumsAssetName)
+	if !ok {
+		log.Printf("update: release %s has no %s; refusing to install an unverified binary", rel.TagName, checksumsAssetName)
+		return subcommands.ExitFailure
+	}
+	sig, ok := rel.asset(signatureAssetName)
+	if !ok {
+		log.Printf("update: release %s has no %s; refusing to install an unverified binary", rel.TagName, signatureAssetName)
+		return subcommands.ExitFailure
+	}
+	sumsData, err := download(ctx, sums.URL, maxMetadataSize)
+	if err != nil {
+		log.Printf("update: %v", err)
+		return subcommands.ExitFailure
+	}
+	sigData, err := download(ctx, sig.URL, maxMetadataSize)
+	if err != nil {
+		log.Printf("update: %v", err)
+		return subcommands.ExitFailure
+	}
+	if err := verifySignature(sumsData, sigData, releaseSigningKey); err != nil {
+		log.Printf("update: %s: %v", checksumsAssetName, err)
+		return subcommands.ExitFailure
+	}
+	want, err := lookupChecksum(sumsData, name)
+	if err != nil {
+		log.Printf("update: %v", err)
+		return subcommands.ExitFailure
+	}
+	data, err := download(ctx, bin.URL, maxBinarySize)
+	if err != nil {
+		log.Printf("update: %v", err)
+		return subcommands.ExitFailure
+	}
+	if err := verifyChecksum(data, want); err != nil {
+		log.Printf("update: %s: %v", name, err)
+		return subcommands.ExitFailure
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		log.Printf("update: failed to locate executable: %v", err)
+		return subcommands.ExitFailure
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		log.Printf("update: failed to resolve executable: %v", err)
+		return subcommands.ExitFailure
+	}
+	if err := replaceExecutable(exe, data); err != nil {
+		log.Printf("update: %v", err)
+		return subcommands.ExitFailure
+	}
+	infof("update: installed %s to %s", rel.TagName, exe)
+	return subcommands.ExitSuccess
+}
+
+// release is the subset of the GitHub release API response used by update.
+type release struct {
+	TagName string         `json:"tag_name"`
+	Assets  []releaseAsset `json:"assets"`
+}
+
+// releaseAsset is a downloadable file attached to a release.
+type releaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// asset returns the asset with the given name.
+func (r *release) asset(name string) (releaseAsset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return releaseAsset{}, false
+}
+
+// fetchRelease looks up the release for tag, or the latest release if tag is
+// empty.
+func fetchRelease(ctx context.Context, tag string) (*release, error) {
+	url := releasesURL + "/latest"
+	if tag != "" {
+		url = releasesURL + "/tags/" + tag
+	}
+	data, err := download(ctx, url, maxMetadataSize)
+	if err != nil {
+		return nil, err
+	}
+	rel := new(release)
+	if err := json.Unmarshal(data, rel); err != nil {
+		return nil, fmt.Errorf("failed to decode release from %s: %v", url, err)
+	}
+	if rel.TagName == "" {
+		return nil, fmt.Errorf("no release found at %s", url)
+	}
+	return rel, nil
+}
+
+// download fetches url and returns the response body, which must not be
+// longer than limit bytes.
+func download(ctx context.Context, url string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %v", url, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("GET %s: response is larger than %d bytes", url, limit)
+	}
+	return data, nil
+}
+
+// binaryAssetName returns the release asset name for a platform.
+func binaryAssetName(goos, goarch string) string {
+	name := "wire_" + goos + "_" + goarch
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// lookupChecksum finds the hex SHA-256 for name in a sha256sum-style file.
+func lookupChecksum(sums []byte, name string) (string, error) {
+	sc := bufio.NewScanner(bytes.NewReader(sums))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != name {
+			continue
+		}
+		if sum, err := hex.DecodeString(fields[0]); err != nil || len(sum) != sha256.Size {
+			return "", fmt.Errorf("%s lists %s with an invalid SHA-256 checksum %q", checksumsAssetName, name, fields[0])
+		}
+		return strings.ToLower(fields[0]), nil
+	}
+	return "", fmt.Errorf("%s does not list %s", checksumsAssetName, name)
+}
+
+// verifySignature reports an error unless sig, a base64 Ed25519 signature,
+// signs data with key, a base64 Ed25519 public key.
+func verifySignature(data, sig []byte, key string) error {
+	pub, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid release signing key %q", key)
+	}
+	// base64 wraps long lines, so white space anywhere is dropped.
+	raw, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(sig)), ""))
+	if err != nil || len(raw) != ed25519.SignatureSize {
+		return fmt.Errorf("%s is not a base64 Ed25519 signature", signatureAssetName)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(pub), data, raw) {
+		return fmt.Errorf("signature does not match the release signing key")
+	}
+	return nil
+}
+
+// verifyChecksum reports an error if data does not hash to want.
+func verifyChecksum(data []byte, want string) error {
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("checksum mismatch: got %s, want %s", got, want)
+	}
+	return nil
+}
+
+// replaceExecutable atomically swaps the executable at path for data. The
+// old binary is moved aside first so that the swap also works on platforms
+// that do not allow overwriting a running executable.
+func replaceExecutable(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".new-")
+	if err != nil {
+		return fmt.Errorf("failed to stage update: %v", err)
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to stage update: %v", firstErr(writeErr, closeErr))
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()|0111); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	old := path + ".old"
+	os.Remove(old)
+	if err := os.Rename(path, old); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to move %s aside: %v", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Rename(old, path)
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to install %s: %v", path, err)
+	}
+	// Removing the old binary may fail on Windows while it is running; it is
+	// cleaned up by the next update.
+	os.Remove(old)
+	return nil
+}
+
+// compareRelease compares the release tag to the running version, current,
+// as semantic versions, returning -1, 0, or +1 as the release is older than,
+// the same as, or newer than it. A current version that is not a semantic
+// version, such as "(devel)", is older than any release.
+func compareRelease(tag, current string) (int, error) {
+	if !semver.IsValid(tag) {
+		return 0, fmt.Errorf("release tag %q is not a semantic version", tag)
+	}
+	if !semver.IsValid(current) {
+		return 1, nil
+	}
+	return semver.Compare(tag, current), nil
+}
+
+// currentVersion returns the module version the running binary was built
+// from, or "(devel)" for local builds.
+func currentVersion() string {
+	if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Version != "" {
+		return bi.Main.Version
+	}
+	return "(devel)"
+}
+
+// firstErr returns the first non-nil error.
+func firstErr(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
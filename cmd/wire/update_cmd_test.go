@@ -0,0 +1,180 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLookupChecksum(t *testing.T) {
+	linux := strings.Repeat("AB", sha256.Size)
+	windows := strings.Repeat("01", sha256.Size)
+	sums := []byte("" +
+		linux + "  wire_linux_amd64\n" +
+		"malformed line\n" +
+		windows + " *wire_windows_amd64.exe\n" +
+		"abcd  wire_linux_arm64\n")
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "wire_linux_amd64", want: strings.ToLower(linux)},
+		{name: "wire_windows_amd64.exe", want: windows},
+		{name: "wire_darwin_arm64", wantErr: true},
+		{name: "line", wantErr: true},
+		{name: "wire_linux_arm64", wantErr: true},
+	}
+	for _, test := range tests {
+		got, err := lookupChecksum(sums, test.name)
+		if got != test.want || (err != nil) != test.wantErr {
+			t.Errorf("lookupChecksum(%q) = %q, %v; want %q, error %t", test.name, got, err, test.want, test.wantErr)
+		}
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	data := []byte("wire binary")
+	sum := sha256.Sum256(data)
+	if err := verifyChecksum(data, hex.EncodeToString(sum[:])); err != nil {
+		t.Errorf("verifyChecksum of the right sum: %v", err)
+	}
+	if err := verifyChecksum(append(data, '!'), hex.EncodeToString(sum[:])); err == nil {
+		t.Error("verifyChecksum of changed data succeeded")
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := base64.StdEncoding.EncodeToString(pub)
+	sums := []byte(strings.Repeat("ab", sha256.Size) + "  wire_linux_amd64\n")
+	sig := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, sums)) + "\n")
+	tests := []struct {
+		name      string
+		data, sig []byte
+		key       string
+		wantErr   bool
+	}{
+		{name: "Valid", data: sums, sig: sig, key: key},
+		{name: "WrappedSignature", data: sums, sig: append(append(append([]byte{}, sig[:76]...), '\n'), sig[76:]...), key: key},
+		{name: "TamperedChecksums", data: append([]byte("00"), sums[2:]...), sig: sig, key: key, wantErr: true},
+		{name: "OtherKey", data: sums, sig: sig, key: base64.StdEncoding.EncodeToString(otherPub), wantErr: true},
+		{name: "MalformedSignature", data: sums, sig: []byte("not base64!"), key: key, wantErr: true},
+		{name: "ShortSignature", data: sums, sig: []byte(base64.StdEncoding.EncodeToString([]byte("short"))), key: key, wantErr: true},
+		{name: "NoKey", data: sums, sig: sig, wantErr: true},
+		{name: "MalformedKey", data: sums, sig: sig, key: "AAAA", wantErr: true},
+	}
+	for _, test := range tests {
+		if err := verifySignature(test.data, test.sig, test.key); (err != nil) != test.wantErr {
+			t.Errorf("%s: verifySignature returned %v; want error %t", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func TestDownloadLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("0123456789"))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	if data, err := download(ctx, srv.URL, 10); err != nil || string(data) != "0123456789" {
+		t.Errorf("download with a limit of the body's size = %q, %v; want the body", data, err)
+	}
+	if data, err := download(ctx, srv.URL, 9); err == nil {
+		t.Errorf("download with a limit below the body's size = %q; want an error", data)
+	}
+}
+
+func TestReplaceExecutable(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "wire")
+	if err := os.WriteFile(path, []byte("old"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := replaceExecutable(path, []byte("new")); err != nil {
+		t.Fatalf("replaceExecutable: %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil || string(got) != "new" {
+		t.Errorf("executable after replaceExecutable = %q, %v; want %q", got, err, "new")
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0751 {
+			t.Errorf("executable mode after replaceExecutable = %v; want %v", perm, os.FileMode(0751))
+		}
+	}
+	// Neither the staged nor the old binary is left behind.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("directory after replaceExecutable holds %v; want only wire", names)
+	}
+
+	if err := replaceExecutable(filepath.Join(dir, "missing"), []byte("new")); err == nil {
+		t.Error("replaceExecutable of a missing executable succeeded")
+	}
+}
+
+func TestCompareRelease(t *testing.T) {
+	tests := []struct {
+		tag, current string
+		want         int
+		wantErr      bool
+	}{
+		{tag: "v1.2.0", current: "v1.2.0", want: 0},
+		{tag: "v1.10.0", current: "v1.9.0", want: 1},
+		{tag: "v1.9.0", current: "v1.10.0", want: -1},
+		{tag: "v1.2.0", current: "v1.2.0-rc.1", want: 1},
+		{tag: "v1.2.0-rc.1", current: "v1.2.0", want: -1},
+		{tag: "v1.2.0", current: "v0.0.0-20260101000000-abcdefabcdef", want: 1},
+		{tag: "v1.2.0", current: "(devel)", want: 1},
+		{tag: "nightly", current: "v1.2.0", wantErr: true},
+	}
+	for _, test := range tests {
+		got, err := compareRelease(test.tag, test.current)
+		if got != test.want || (err != nil) != test.wantErr {
+			t.Errorf("compareRelease(%q, %q) = %d, %v; want %d, error %t", test.tag, test.current, got, err, test.want, test.wantErr)
+		}
+	}
+}
@@ -24,21 +24,22 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/fsnotify/fsnotify"
 	"github.com/goforj/wire/internal/wire"
 	"github.com/google/subcommands"
 )
 
 // watchCmd implements the wire watch subcommand.
 type watchCmd struct {
-	headerFile     string
-	prefixFileName string
-	tags           string
-	profile        profileFlags
-	pollInterval   time.Duration
-	rescanInterval time.Duration
+	generate        generateFlags
+	profile         profileFlags
+	pollInterval    time.Duration
+	maxPollInterval time.Duration
+	rescanInterval  time.Duration
+	metricsAddr     string
+	removeOrphans   bool
 }
 
 // Name returns the subcommand name.
@@ -51,32 +52,73 @@ func (*watchCmd) Synopsis() string {
 
 // Usage returns the help text for the subcommand.
 func (*watchCmd) Usage() string {
-	return `watch [packages]
-
-  Given one or more packages, watch re-runs wire gen when Go files change.
-  If no packages are listed, it defaults to ".".
+	return `watch [-match regexp] [-metrics_addr addr] [-remove_orphans] [packages]
+
+  Given one or more packages, watch re-runs wire gen when Go files, or
+  go.mod, go.sum, or go.work files, change. If no packages are listed, it
+  defaults to ".".
+
+  In a go.work workspace, each module holding matched packages is watched
+  independently, and a change regenerates only the packages of its module.
+
+  Without filesystem notifications, watch polls instead: it stats every
+  watched file each -poll_interval, doubling the interval up to
+  -max_poll_interval while nothing changes, and rescans for new or removed
+  files each -rescan_interval, walking the tree only when a directory's
+  modification time has changed. -timings reports how long each poll and
+  rescan takes.
+
+  A package named on the command line whose directory is deleted, or left
+  with nothing but generated files, is reported once and skipped until it
+  has Go files again; the rest are still generated. With -remove_orphans,
+  each run first removes generated files left in packages that no longer
+  declare any injectors, as wire clean would.
+
+  With -metrics_addr, watch serves Prometheus metrics (regeneration counts,
+  durations, failures, and cache hits) at /metrics on that address.
 `
 }
 
 // SetFlags registers flags for the subcommand.
 func (cmd *watchCmd) SetFlags(f *flag.FlagSet) {
-	f.StringVar(&cmd.headerFile, "header_file", "", "path to file to insert as a header in wire_gen.go")
-	f.StringVar(&cmd.prefixFileName, "output_file_prefix", "", "string to prepend to output file names.")
-	f.StringVar(&cmd.tags, "tags", "", "append build tags to the default wirebuild")
+	cmd.generate.addFlags(f)
 	f.DurationVar(&cmd.pollInterval, "poll_interval", 250*time.Millisecond, "interval between file stat checks")
+	f.DurationVar(&cmd.maxPollInterval, "max_poll_interval", 4*time.Second, "longest interval file stat checks back off to while no files change")
 	f.DurationVar(&cmd.rescanInterval, "rescan_interval", 2*time.Second, "interval to rescan for new or removed Go files")
+	f.StringVar(&cmd.metricsAddr, "metrics_addr", "", "serve Prometheus metrics at /metrics on this address (e.g. :9090)")
+	f.BoolVar(&cmd.removeOrphans, "remove_orphans", false, "remove generated files left in packages that no longer declare any injectors")
 	cmd.profile.addFlags(f)
 }
 
 // Execute runs the subcommand.
 func (cmd *watchCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
-	stop, err := cmd.profile.start()
+	stop, err := cmd.profile.start(cmd.Name())
 	if err != nil {
 		log.Println(err)
 		return subcommands.ExitFailure
 	}
 	defer stop()
-	ctx = withTiming(ctx, cmd.profile.timings)
+
+	var metrics *watchMetrics
+	if cmd.metricsAddr != "" {
+		metrics = newWatchMetrics()
+		if err := serveMetrics(cmd.metricsAddr, metrics); err != nil {
+			log.Println(err)
+			return subcommands.ExitFailure
+		}
+	}
+	timings := cmd.profile.timings
+	logf := func(label string, dur time.Duration) {
+		if metrics != nil {
+			metrics.observeTiming(label, dur)
+		}
+		if timings {
+			log.Printf("timing: %s=%s", label, dur)
+		}
+	}
+	if metrics != nil || timings {
+		ctx = wire.WithTiming(ctx, logf)
+	}
 
 	if cmd.pollInterval <= 0 {
 		log.Println("poll_interval must be greater than zero")
@@ -92,192 +134,232 @@ func (cmd *watchCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...inter
 		log.Println("failed to get working directory:", err)
 		return subcommands.ExitFailure
 	}
-	opts, err := newGenerateOptions(cmd.headerFile)
+	opts, err := cmd.generate.options()
 	if err != nil {
 		log.Println(err)
 		return subcommands.ExitFailure
 	}
-	opts.PrefixOutputFile = cmd.prefixFileName
-	opts.Tags = cmd.tags
 
 	env := os.Environ()
-	runGenerate := func() {
+	var removed removedPackages
+	// generate runs one generation of the packages in module, or of all
+	// packages if module is empty, committing and logging each package's
+	// result as it completes. The initial run also prints a summary, since
+	// in a large repository it can take a while.
+	generate := func(initial bool, module string) bool {
 		totalStart := time.Now()
+		// Each run is exported as a trace of its own, since pipelines
+		// may run concurrently.
+		spans := cmd.profile.spans.fork()
+		defer spans.export("wire watch", totalStart)
+		ctx := ctx
+		if spans != nil {
+			ctx = wire.WithTiming(ctx, func(label string, dur time.Duration) {
+				logf(label, dur)
+				spans.record(label, dur)
+			})
+		}
+		patterns := packages(f)
+		if cmd.removeOrphans {
+			cmd.removeOrphanedOutput(ctx, wd, env, patterns)
+		}
+		if patterns = removed.filter(wd, patterns); len(patterns) == 0 {
+			return true
+		}
+		// Re-expand patterns on every run so new packages are picked up.
+		pkgs, err := filterPackages(ctx, wd, env, cmd.generate.tags, cmd.generate.match, cmd.generate.skip, patterns)
+		if err != nil {
+			log.Println(err)
+			return false
+		}
+		if module != "" {
+			byModule, err := wire.PackagesByModule(ctx, wd, env, cmd.generate.tags, pkgs)
+			if err != nil {
+				log.Println(err)
+				return false
+			}
+			if pkgs = byModule[module]; len(pkgs) == 0 {
+				return true
+			}
+		}
+		var summary watchSummary
+		runOpts := *opts
+		runOpts.OnResult = func(out wire.GenerateResult) {
+			commitStart := time.Now()
+			summary.add(out, commitWatchResult(out, totalStart))
+			spans.record("generate.package."+out.PkgPath+".write", time.Since(commitStart))
+		}
 		genStart := time.Now()
-		outs, errs := wire.Generate(ctx, wd, env, packages(f), opts)
+		_, errs := wire.Generate(ctx, wd, env, pkgs, &runOpts)
 		logTiming(cmd.profile.timings, "wire.Generate", genStart)
 		if len(errs) > 0 {
-			logErrors(errs)
+			logErrors(errs.Errors())
 			log.Println("generate failed")
-			return
+			return false
 		}
-		if len(outs) == 0 {
-			logTiming(cmd.profile.timings, "total", totalStart)
-			return
+		if initial {
+			infof("watch: initial generation: %s (%s)\n", summary, formatDuration(time.Since(totalStart)))
 		}
-		success := true
-		writeStart := time.Now()
-		for _, out := range outs {
-			if len(out.Errs) > 0 {
-				logErrors(out.Errs)
-				log.Printf("%s: generate failed\n", out.PkgPath)
-				success = false
-			}
-			if len(out.Content) == 0 {
-				continue
-			}
-			if err := out.Commit(); err == nil {
-				log.Printf("%s: wrote %s (%s)\n", out.PkgPath, out.OutputPath, formatDuration(time.Since(totalStart)))
-			} else {
-				log.Printf("%s: failed to write %s: %v\n", out.PkgPath, out.OutputPath, err)
-				success = false
-			}
-		}
-		if !success {
+		if summary.failed > 0 {
 			log.Println("at least one generate failure")
-			return
+			return false
 		}
-		logTiming(cmd.profile.timings, "writes", writeStart)
 		logTiming(cmd.profile.timings, "total", totalStart)
+		return true
+	}
+	runGenerate := func(module string) func() {
+		return func() {
+			start := time.Now()
+			ok := generate(false, module)
+			if metrics != nil {
+				metrics.observeRun(time.Since(start), ok)
+			}
+		}
 	}
 
-	root, err := moduleRoot(wd, env)
-	if err != nil {
-		log.Printf("watch: failed to resolve module root, using %s: %v", wd, err)
-		root = wd
+	initialStart := time.Now()
+	ok := generate(true, "")
+	if metrics != nil {
+		metrics.observeRun(time.Since(initialStart), ok)
 	}
 
-	runGenerate()
-	if err := watchWithFSNotify(root, runGenerate); err == nil {
+	// In a go.work workspace, each module holding matched packages gets a
+	// pipeline of its own, so that a change in one module regenerates only
+	// that module's packages. The pipelines share the wire cache.
+	pkgs, err := matchedPackages(ctx, wd, env, cmd.generate.tags, cmd.generate.match, cmd.generate.skip, f)
+	if err != nil {
+		log.Printf("watch: failed to list packages: %v", err)
+	}
+	roots, byModule, err := wire.WatchRoots(ctx, wd, env, cmd.generate.tags, pkgs)
+	if err != nil {
+		log.Printf("watch: failed to find the modules to watch, watching %s: %v", roots[0], err)
+	}
+	if byModule == nil {
+		cmd.watchRoot(ctx, roots[0], runGenerate(""))
 		return subcommands.ExitSuccess
-	} else {
-		log.Printf("watch: fsnotify unavailable, falling back to polling: %v", err)
 	}
+	infof("watch: watching %d workspace modules independently", len(roots))
+	var wg sync.WaitGroup
+	for _, dir := range roots {
+		wg.Add(1)
+		go func(dir string) {
+			defer wg.Done()
+			cmd.watchRoot(ctx, dir, runGenerate(dir))
+		}(dir)
+	}
+	wg.Wait()
+	return subcommands.ExitSuccess
+}
 
-	state, err := scanGoFiles(root)
+// watchRoot calls onChange whenever the files of the module at root change,
+// as wire.Watch reports them.
+func (cmd *watchCmd) watchRoot(ctx context.Context, root string, onChange func()) {
+	opts := &wire.WatchOptions{
+		PollInterval:    cmd.pollInterval,
+		MaxPollInterval: cmd.maxPollInterval,
+		RescanInterval:  cmd.rescanInterval,
+		OnPoll: func(err error) {
+			infof("watch: fsnotify unavailable for %s, falling back to polling: %v", root, err)
+		},
+	}
+	wire.Watch(ctx, root, opts, func(changed []string) {
+		infof("watch: changes detected (%s), re-running", formatChangedFiles(changed, root))
+		onChange()
+	})
+}
+
+// removeOrphanedOutput removes the generated files that wire.OrphanedFiles
+// finds in the packages matching patterns.
+func (cmd *watchCmd) removeOrphanedOutput(ctx context.Context, wd string, env []string, patterns []string) {
+	orphaned, err := wire.OrphanedFiles(ctx, wd, env, cmd.generate.tags, patterns, cmd.generate.prefixFileName, cmd.generate.fileName)
 	if err != nil {
-		log.Printf("initial scan failed: %v", err)
+		log.Printf("watch: failed to find orphaned output: %v", err)
+		return
 	}
-	state, _ = scanGoFiles(root)
-
-	pollTicker := time.NewTicker(cmd.pollInterval)
-	rescanTicker := time.NewTicker(cmd.rescanInterval)
-	defer pollTicker.Stop()
-	defer rescanTicker.Stop()
-
-	for {
-		select {
-		case <-pollTicker.C:
-			if changed := updateFileState(state); len(changed) > 0 {
-				log.Printf("watch: changes detected (%s), re-running", formatChangedFiles(changed, root))
-				runGenerate()
-				state, _ = scanGoFiles(root)
-			}
-		case <-rescanTicker.C:
-			newState, err := scanGoFiles(root)
-			if err != nil {
-				log.Printf("rescan failed: %v", err)
-				continue
-			}
-			if changed := diffFileState(state, newState); len(changed) > 0 {
-				log.Printf("watch: file set changed (%s), re-running", formatChangedFiles(changed, root))
-				state = newState
-				runGenerate()
-				state, _ = scanGoFiles(root)
-			} else {
-				state = newState
-			}
+	for _, path := range orphaned {
+		if err := os.Remove(path); err != nil {
+			log.Printf("failed to remove %s: %v\n", path, err)
+			continue
 		}
+		infof("removed orphaned %s\n", path)
 	}
 }
 
-// fileState stores file metadata for polling-based change detection.
-type fileState struct {
-	modTime time.Time
-	size    int64
+// removedPackages tracks the package patterns given to watch whose
+// directories have been deleted or left without Go files, so that each
+// removal is logged once and later runs skip the package instead of failing
+// on it. Pipelines of workspace modules share it.
+type removedPackages struct {
+	mu   sync.Mutex
+	gone map[string]bool
 }
 
-// scanGoFiles recursively collects Go file metadata under root.
-func scanGoFiles(root string) (map[string]fileState, error) {
-	state := make(map[string]fileState)
-	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			if d != nil && d.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-		if d.IsDir() {
-			if shouldSkipDir(d.Name()) {
-				return filepath.SkipDir
+// filter returns the patterns whose packages have not been removed.
+func (r *removedPackages) filter(wd string, patterns []string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var kept []string
+	for _, p := range patterns {
+		if !wire.PackageRemoved(wd, p) {
+			if r.gone[p] {
+				delete(r.gone, p)
+				infof("watch: %s has Go files again, generating it\n", p)
 			}
-			return nil
-		}
-		if !strings.HasSuffix(d.Name(), ".go") {
-			return nil
-		}
-		if strings.HasSuffix(d.Name(), "wire_gen.go") {
-			return nil
-		}
-		info, infoErr := d.Info()
-		if infoErr != nil {
-			return nil
-		}
-		state[path] = fileState{
-			modTime: info.ModTime(),
-			size:    info.Size(),
-		}
-		return nil
-	})
-	return state, err
-}
-
-// updateFileState returns the paths that changed since the last poll.
-func updateFileState(state map[string]fileState) []string {
-	var changed []string
-	for path, old := range state {
-		info, err := os.Stat(path)
-		if err != nil {
-			delete(state, path)
-			changed = append(changed, path)
+			kept = append(kept, p)
 			continue
 		}
-		next := fileState{modTime: info.ModTime(), size: info.Size()}
-		if next.modTime != old.modTime || next.size != old.size {
-			state[path] = next
-			changed = append(changed, path)
+		if !r.gone[p] {
+			if r.gone == nil {
+				r.gone = make(map[string]bool)
+			}
+			r.gone[p] = true
+			infof("watch: %s was removed, skipping it\n", p)
 		}
 	}
-	return changed
+	return kept
 }
 
-// diffFileState returns the paths that changed between two snapshots.
-func diffFileState(prev, next map[string]fileState) []string {
-	var changed []string
-	for path, old := range prev {
-		cur, ok := next[path]
-		if !ok {
-			changed = append(changed, path)
-			continue
-		}
-		if old.modTime != cur.modTime || old.size != cur.size {
-			changed = append(changed, path)
-		}
+// commitWatchResult logs the result of generating a package and writes its
+// output, reporting whether both succeeded. start is the start of the run.
+func commitWatchResult(out wire.GenerateResult, start time.Time) bool {
+	if len(out.Errs) > 0 {
+		logErrors(out.Errs.Errors())
+		log.Printf("%s: generate failed\n", out.PkgPath)
+		return false
 	}
-	for path := range next {
-		if _, ok := prev[path]; !ok {
-			changed = append(changed, path)
-		}
+	if out.Skipped {
+		verbosef("%s: skipped: no injectors\n", out.PkgPath)
+		return true
+	}
+	if out.Cached {
+		verbosef("%s: output served from the cache\n", out.PkgPath)
+	}
+	if err := out.Commit(); err != nil {
+		log.Printf("%s: failed to write %s: %v\n", out.PkgPath, out.OutputPath, err)
+		return false
 	}
-	return changed
+	infof("%s: wrote %s (%s)\n", out.PkgPath, out.OutputPath, formatDuration(time.Since(start)))
+	return true
 }
 
-// shouldSkipDir reports whether a directory should be ignored for watching.
-func shouldSkipDir(name string) bool {
-	if name == "vendor" {
-		return true
+// watchSummary counts the outcomes of the packages in a generation run.
+type watchSummary struct {
+	generated, cached, failed int
+}
+
+func (s *watchSummary) add(out wire.GenerateResult, ok bool) {
+	switch {
+	case !ok:
+		s.failed++
+	case out.Cached:
+		s.cached++
+	case len(out.Content) > 0:
+		s.generated++
 	}
-	return strings.HasPrefix(name, ".")
+}
+
+func (s watchSummary) String() string {
+	return fmt.Sprintf("%d generated, %d cached, %d failed", s.generated, s.cached, s.failed)
 }
 
 // formatChangedFiles formats a list of changed paths relative to root.
@@ -332,102 +414,3 @@ func moduleRoot(wd string, env []string) (string, error) {
 func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%.2fms", float64(d)/float64(time.Millisecond))
 }
-
-// watchWithFSNotify runs the watcher using native filesystem notifications.
-func watchWithFSNotify(root string, onChange func()) error {
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		return err
-	}
-	defer watcher.Close()
-
-	if err := addWatchDirs(watcher, root); err != nil {
-		return err
-	}
-
-	changed := make(map[string]struct{})
-	debounce := 200 * time.Millisecond
-	timer := time.NewTimer(debounce)
-	if !timer.Stop() {
-		<-timer.C
-	}
-
-	for {
-		select {
-		case event, ok := <-watcher.Events:
-			if !ok {
-				return fmt.Errorf("watcher closed")
-			}
-			if event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Remove|fsnotify.Rename) == 0 {
-				continue
-			}
-			if event.Op&fsnotify.Create != 0 {
-				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
-					if !shouldSkipDir(filepath.Base(event.Name)) {
-						_ = addWatchDirs(watcher, event.Name)
-					}
-					continue
-				}
-			}
-			if !isWatchedGoFile(event.Name) {
-				continue
-			}
-			changed[event.Name] = struct{}{}
-			if !timer.Stop() {
-				select {
-				case <-timer.C:
-				default:
-				}
-			}
-			timer.Reset(debounce)
-		case <-timer.C:
-			if len(changed) == 0 {
-				continue
-			}
-			paths := make([]string, 0, len(changed))
-			for path := range changed {
-				paths = append(paths, path)
-			}
-			for key := range changed {
-				delete(changed, key)
-			}
-			log.Printf("watch: changes detected (%s), re-running", formatChangedFiles(paths, root))
-			onChange()
-		case err, ok := <-watcher.Errors:
-			if !ok {
-				return fmt.Errorf("watcher closed")
-			}
-			return err
-		}
-	}
-}
-
-// addWatchDirs registers watchers for all directories under root.
-func addWatchDirs(watcher *fsnotify.Watcher, root string) error {
-	return filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			if d != nil && d.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-		if !d.IsDir() {
-			return nil
-		}
-		if shouldSkipDir(d.Name()) {
-			return filepath.SkipDir
-		}
-		if err := watcher.Add(path); err != nil {
-			return err
-		}
-		return nil
-	})
-}
-
-// isWatchedGoFile reports whether a path should trigger a regeneration.
-func isWatchedGoFile(path string) bool {
-	if !strings.HasSuffix(path, ".go") {
-		return false
-	}
-	return !strings.HasSuffix(path, "wire_gen.go")
-}
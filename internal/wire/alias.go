@@ -0,0 +1,70 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import "go/types"
+
+// TypeString formats t for diagnostics and tool output. Aliases, including
+// those in the element types of pointers, slices, arrays, maps and channels,
+// are resolved to the types they denote, so that a type reads the same
+// however a provider or injector spells it.
+func TypeString(t types.Type) string {
+	return types.TypeString(resolveAliases(t), nil)
+}
+
+// resolveAliases returns t with its aliases resolved. Only the composite
+// types whose element types are commonly spelled with an alias are rebuilt.
+func resolveAliases(t types.Type) types.Type {
+	switch t := unalias(t).(type) {
+	case *types.Pointer:
+		if elem := resolveAliases(t.Elem()); elem != t.Elem() {
+			return types.NewPointer(elem)
+		}
+		return t
+	case *types.Slice:
+		if elem := resolveAliases(t.Elem()); elem != t.Elem() {
+			return types.NewSlice(elem)
+		}
+		return t
+	case *types.Array:
+		if elem := resolveAliases(t.Elem()); elem != t.Elem() {
+			return types.NewArray(elem, t.Len())
+		}
+		return t
+	case *types.Map:
+		key, elem := resolveAliases(t.Key()), resolveAliases(t.Elem())
+		if key != t.Key() || elem != t.Elem() {
+			return types.NewMap(key, elem)
+		}
+		return t
+	case *types.Chan:
+		if elem := resolveAliases(t.Elem()); elem != t.Elem() {
+			return types.NewChan(t.Dir(), elem)
+		}
+		return t
+	default:
+		return t
+	}
+}
+
+// describeNeeded formats t, a type that has no provider. If t is spelled
+// with an alias, the alias is mentioned too, since that is the name the user
+// wrote.
+func describeNeeded(t types.Type) string {
+	if resolved := resolveAliases(t); resolved != t {
+		return types.TypeString(resolved, nil) + " (aliased as " + types.TypeString(t, nil) + ")"
+	}
+	return types.TypeString(t, nil)
+}
@@ -0,0 +1,109 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// This file supports LoadOptions.AllowErrors, which analyzes packages that
+// fail to type-check.
+
+type allowErrorsKey struct{}
+
+// withAllowErrors makes the loads run with ctx keep packages that have
+// errors, returning the errors along with the packages.
+func withAllowErrors(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allowErrorsKey{}, true)
+}
+
+// allowErrors reports whether loads run with ctx keep packages that have
+// errors.
+func allowErrors(ctx context.Context) bool {
+	allow, _ := ctx.Value(allowErrorsKey{}).(bool)
+	return allow
+}
+
+// errorLines records the lines of each file of a package that have errors.
+type errorLines map[string]map[int]bool
+
+// newErrorLines returns the lines of pkg's errors.
+func newErrorLines(pkg *packages.Package) *errorLines {
+	lines := make(errorLines)
+	for _, e := range pkg.Errors {
+		file, line, ok := splitErrorPos(e.Pos)
+		if !ok {
+			continue
+		}
+		if lines[file] == nil {
+			lines[file] = make(map[int]bool)
+		}
+		lines[file][line] = true
+	}
+	return &lines
+}
+
+// in reports whether any error lies within the lines spanned by n. A nil
+// *errorLines has no errors.
+func (el *errorLines) in(fset *token.FileSet, n ast.Node) bool {
+	if el == nil {
+		return false
+	}
+	start, end := fset.Position(n.Pos()), fset.Position(n.End())
+	for line := range (*el)[start.Filename] {
+		if line >= start.Line && line <= end.Line {
+			return true
+		}
+	}
+	return false
+}
+
+// splitErrorPos parses the "file:line" or "file:line:col" position of a
+// package error.
+func splitErrorPos(pos string) (file string, line int, ok bool) {
+	file, n, ok := cutPosNumber(pos)
+	if !ok {
+		return "", 0, false
+	}
+	if f, line, ok := cutPosNumber(file); ok {
+		return f, line, true
+	}
+	return file, n, true
+}
+
+// cutPosNumber splits the trailing ":n" off pos.
+func cutPosNumber(pos string) (string, int, bool) {
+	i := strings.LastIndexByte(pos, ':')
+	if i < 0 {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(pos[i+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return pos[:i], n, true
+}
+
+// causedByErrors reports whether err is a Wire error caused by a type that
+// failed to type-check, which the package's own errors already explain.
+func causedByErrors(err error) bool {
+	return strings.Contains(err.Error(), "invalid type")
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadAllowErrors(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"type DB struct{}",
+		"type Cache struct{}",
+		"type App struct{}",
+		"",
+		"func NewDB() *DB { return undefinedDB() }",
+		"",
+		"func NewCache(Missing) *Cache { return nil }",
+		"",
+		"func NewApp(*DB) *App { return nil }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func InitApp() *App { panic(wire.Build(NewApp, NewDB)) }",
+		"",
+		"func InitMissing() *App { panic(wire.Build(NewDB)) }",
+		"",
+		"func InitBroken() *App { panic(wire.Build(NewApp, NewDB, Bogus)) }",
+		"",
+		"func InitCache() *Cache { panic(wire.Build(NewCache)) }",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	if info, errs := Load(ctx, root, env, "", []string{"./app"}); len(info.Injectors) > 0 || len(errs) != 3 {
+		t.Errorf("Load returned %d injectors, %d errors; want only the 3 type errors: %v", len(info.Injectors), len(errs), errs)
+	}
+
+	info, errs := LoadWithOptions(ctx, root, env, "", []string{"./app"}, &LoadOptions{AllowErrors: true})
+	if info == nil {
+		t.Fatalf("LoadWithOptions returned no info: %v", errs)
+	}
+	var got []string
+	for _, err := range errs {
+		msg := err.Error()
+		got = append(got, msg[strings.Index(msg, "wire.go")+len("wire.go"):])
+	}
+	want := []string{
+		":9:1: inject InitMissing: no provider found for *example.com/app/app.App, output of injector",
+	}
+	// The type errors come first, in no particular order.
+	if len(got) != 3+len(want) || !reflect.DeepEqual(got[3:], want) {
+		t.Errorf("LoadWithOptions errors = %q; want 3 type errors followed by %q", errs, want)
+	}
+	if len(info.Injectors) != 1 || info.Injectors[0].FuncName != "InitApp" {
+		t.Errorf("LoadWithOptions injectors = %v; want InitApp", info.Injectors)
+	}
+}
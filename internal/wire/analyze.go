@@ -45,6 +45,9 @@ type call struct {
 	// out is the type this step produces.
 	out types.Type
 
+	// src is the provider, value, or field that this step is derived from.
+	src ProvidedType
+
 	// pkg and name identify one of the following:
 	// 1) the provider to call for kind == funcProviderCall;
 	// 2) the type to construct for kind == structProvider;
@@ -77,8 +80,16 @@ type call struct {
 
 	// hasCleanup is true if the provider call returns a cleanup function.
 	hasCleanup bool
+	// cleanupErr is true if that cleanup function returns an error.
+	cleanupErr bool
 	// hasErr is true if the provider call returns an error.
 	hasErr bool
+	// method is the method to call on args[0] if the provider is a method
+	// expression.
+	method string
+	// shared is true if the call goes through the package's accessor for a
+	// provider passed to wire.Shared.
+	shared bool
 
 	// The following are only set for kind == valueExpr:
 
@@ -110,11 +121,29 @@ func solve(fset *token.FileSet, out types.Type, given *types.Tuple, set *Provide
 	var used []*providerSetSrc
 	var calls []call
 	type frame struct {
-		t    types.Type
-		from types.Type
-		up   *frame
+		t     types.Type
+		from  types.Type
+		up    *frame
+		req   *Requirement
+		after *AfterHook
+	}
+	// Required types are visited before the output so that the output is
+	// still built by the last call of those. The parameters of wire.After
+	// hooks are visited after it, since hooks commonly take the output.
+	reqs := set.requirements()
+	var stk []frame
+	for i := len(set.Afters) - 1; i >= 0; i-- {
+		hook := set.Afters[i]
+		for j := len(hook.Params) - 1; j >= 0; j-- {
+			stk = append(stk, frame{t: hook.Params[j], after: hook})
+		}
+	}
+	if out != nil {
+		stk = append(stk, frame{t: out})
+	}
+	for i := len(reqs) - 1; i >= 0; i-- {
+		stk = append(stk, frame{t: reqs[i].Type, req: reqs[i]})
 	}
-	stk := []frame{{t: out}}
 dfs:
 	for len(stk) > 0 {
 		curr := stk[len(stk)-1]
@@ -125,17 +154,27 @@ dfs:
 
 		pv := set.For(curr.t)
 		if pv.IsNil() {
+			if curr.req != nil {
+				ec.add(notePosition(fset.Position(curr.req.Pos), withCode(CodeNoProvider, fmt.Errorf("no provider found for %s, required by wire.Require", describeNeeded(curr.t)))))
+				index.Set(curr.t, errAbort)
+				continue
+			}
+			if curr.after != nil {
+				ec.add(notePosition(fset.Position(curr.after.Pos), withCode(CodeNoProvider, fmt.Errorf("no provider found for %s, parameter of wire.After function", describeNeeded(curr.t)))))
+				index.Set(curr.t, errAbort)
+				continue
+			}
 			if curr.from == nil {
-				ec.add(fmt.Errorf("no provider found for %s, output of injector", types.TypeString(curr.t, nil)))
+				ec.add(withCode(CodeNoProvider, fmt.Errorf("no provider found for %s, output of injector", describeNeeded(curr.t))))
 				index.Set(curr.t, errAbort)
 				continue
 			}
 			sb := new(strings.Builder)
-			fmt.Fprintf(sb, "no provider found for %s", types.TypeString(curr.t, nil))
+			fmt.Fprintf(sb, "no provider found for %s", describeNeeded(curr.t))
 			for f := curr.up; f != nil; f = f.up {
-				fmt.Fprintf(sb, "\nneeded by %s in %s", types.TypeString(f.t, nil), set.srcMap.At(f.t).(*providerSetSrc).description(fset, f.t))
+				fmt.Fprintf(sb, "\nneeded by %s in %s", TypeString(f.t), set.srcMap.At(f.t).(*providerSetSrc).description(fset, f.t))
 			}
-			ec.add(errors.New(sb.String()))
+			ec.add(withCode(CodeNoProvider, errors.New(sb.String())))
 			index.Set(curr.t, errAbort)
 			continue
 		}
@@ -204,8 +243,12 @@ dfs:
 				fieldNames: fieldNames,
 				ins:        ins,
 				out:        curr.t,
+				src:        pv,
 				hasCleanup: p.HasCleanup,
+				cleanupErr: p.CleanupErr,
 				hasErr:     p.HasErr,
+				method:     p.Method,
+				shared:     p.Shared,
 			})
 		case pv.IsValue():
 			v := pv.Value()
@@ -213,6 +256,7 @@ dfs:
 			calls = append(calls, call{
 				kind:          valueExpr,
 				out:           curr.t,
+				src:           pv,
 				valueExpr:     v.expr,
 				valueTypeInfo: v.info,
 			})
@@ -240,6 +284,7 @@ dfs:
 				pkg:        f.Pkg,
 				name:       f.Name,
 				out:        curr.t,
+				src:        pv,
 				args:       args,
 				ptrToField: ptrToField,
 			})
@@ -250,14 +295,286 @@ dfs:
 	if len(ec.errors) > 0 {
 		return nil, ec.errors
 	}
-	if errs := verifyArgsUsed(set, used); len(errs) > 0 {
+	if len(calls) > 0 && out != nil {
+		// The output must be built after the required types.
+		outIndex := index.At(out).(int)
+		for _, r := range reqs {
+			if index.At(r.Type).(int) <= outIndex {
+				continue
+			}
+			if outIndex < given.Len() {
+				return nil, []error{fmt.Errorf("cannot build the types required by wire.Require: the output %s is an injector argument", TypeString(out))}
+			}
+			return nil, []error{fmt.Errorf("cannot build the types required by wire.Require before the output %s, which they depend on", TypeString(out))}
+		}
+	}
+	if errs := verifySharedArgs(given.Len(), calls); len(errs) > 0 {
+		return nil, errs
+	}
+	used = append(used, set.shadowed...)
+	if errs := verifyArgsUsed(fset, set, used); len(errs) > 0 {
 		return nil, errs
 	}
+	if copies := set.copies(); len(copies) > 0 {
+		var errs []error
+		if calls, errs = copyCalls(fset, given.Len(), calls, index, copies, set, out, reqs); len(errs) > 0 {
+			return nil, errs
+		}
+	}
+	if set.stableOrder {
+		calls = stableOrder(given.Len(), calls, set, out)
+	}
 	return calls, nil
 }
 
-// verifyArgsUsed ensures that all of the arguments in set were used during solve.
-func verifyArgsUsed(set *ProviderSet, used []*providerSetSrc) []error {
+// verifySharedArgs checks that the arguments of the calls to providers
+// passed to wire.Shared are the same in every injector of the package, since
+// only the first call's arguments are used. Each must itself be shared, a
+// value, or a field of one, so that the shared value never holds something
+// that a single injector built and may clean up.
+func verifySharedArgs(numGiven int, calls []call) []error {
+	ec := new(errorCollector)
+	// reason returns why the argument a cannot be passed to a shared
+	// provider, or "" if it can.
+	var reason func(a int) string
+	reason = func(a int) string {
+		if a < numGiven {
+			return "it is an argument of the injector"
+		}
+		c := &calls[a-numGiven]
+		switch c.kind {
+		case valueExpr:
+			return ""
+		case selectorExpr:
+			return reason(c.args[0])
+		case structProvider:
+			return "it is built by a struct provider"
+		}
+		switch {
+		case c.shared:
+			return ""
+		case c.hasCleanup:
+			return fmt.Sprintf("its provider %s returns a cleanup function", c.name)
+		default:
+			return fmt.Sprintf("its provider %s is not passed to wire.Shared", c.name)
+		}
+	}
+	for _, c := range calls {
+		if !c.shared {
+			continue
+		}
+		for i, a := range c.args {
+			if r := reason(a); r != "" {
+				ec.add(fmt.Errorf("shared provider %s cannot depend on %s: %s", c.name, TypeString(c.ins[i]), r))
+			}
+		}
+	}
+	return ec.errors
+}
+
+// stableOrder reorders calls, the result of solve, so that of the calls
+// whose arguments are built, the one building the type with the smallest
+// name comes first. Calls building identical types keep their order. The
+// call building out stays between the calls it needs, along with those of
+// wire.Require, and the calls built only for wire.After hooks.
+func stableOrder(numGiven int, calls []call, set *ProviderSet, out types.Type) []call {
+	if len(calls) < 2 {
+		return calls
+	}
+	names := make([]string, len(calls))
+	for i := range calls {
+		names[i] = TypeString(calls[i].out)
+	}
+	placed := make([]bool, len(calls))
+	var order []int
+	// sortRange appends the calls in [lo, hi) to order. The calls before
+	// lo are already placed, and none of them depends on a later call.
+	sortRange := func(lo, hi int) {
+		ready := func(i int) bool {
+			for _, a := range calls[i].args {
+				if a >= numGiven && !placed[a-numGiven] {
+					return false
+				}
+			}
+			for j := lo; j < i; j++ {
+				if !placed[j] && types.Identical(calls[j].out, calls[i].out) {
+					return false
+				}
+			}
+			return true
+		}
+		for n := lo; n < hi; n++ {
+			next := -1
+			for i := lo; i < hi; i++ {
+				if !placed[i] && ready(i) && (next < 0 || names[i] < names[next]) {
+					next = i
+				}
+			}
+			placed[next] = true
+			order = append(order, next)
+		}
+	}
+	if outCall := argIndex(numGiven, calls, set, out) - numGiven; outCall < 0 {
+		sortRange(0, len(calls))
+	} else {
+		sortRange(0, outCall)
+		placed[outCall] = true
+		order = append(order, outCall)
+		sortRange(outCall+1, len(calls))
+	}
+	remap := make([]int, len(calls))
+	for i, old := range order {
+		remap[old] = numGiven + i
+	}
+	result := make([]call, len(calls))
+	for i, old := range order {
+		c := calls[old]
+		c.args = make([]int, len(calls[old].args))
+		for k, a := range calls[old].args {
+			if a >= numGiven {
+				a = remap[a-numGiven]
+			}
+			c.args[k] = a
+		}
+		result[i] = c
+	}
+	return result
+}
+
+// copyCalls rewrites calls so that each call consuming a type marked with
+// wire.Copy gets a call of its own to the type's provider. The original call
+// goes to the first consumer, unless the injector's output, a wire.Require,
+// or a wire.After hook uses it, in which case it is kept for those. index
+// maps the types of the injector to their positions, as in solve.
+func copyCalls(fset *token.FileSet, numGiven int, calls []call, index *typeutil.Map, copies []*CopiedType, set *ProviderSet, out types.Type, reqs []*Requirement) ([]call, []error) {
+	ec := new(errorCollector)
+	copied := make([]bool, len(calls))
+	for _, c := range copies {
+		v := index.At(c.Type)
+		if v == nil {
+			// The injector does not need the type.
+			continue
+		}
+		i := v.(int)
+		var reason string
+		switch {
+		case i < numGiven:
+			reason = "it is an argument of the injector"
+		case calls[i-numGiven].kind == valueExpr:
+			reason = "it is provided by wire.Value"
+		case calls[i-numGiven].kind == selectorExpr:
+			reason = "it is provided by wire.FieldsOf"
+		case calls[i-numGiven].shared:
+			reason = "its provider is passed to wire.Shared"
+		}
+		if reason != "" {
+			ec.add(notePosition(fset.Position(c.Pos), fmt.Errorf("cannot copy %s: %s", TypeString(c.Type), reason)))
+			continue
+		}
+		copied[i-numGiven] = true
+	}
+	if len(ec.errors) > 0 {
+		return nil, ec.errors
+	}
+	// kept marks the originals that must not be handed to a consumer.
+	kept := make([]bool, len(calls))
+	keep := func(t types.Type) {
+		if t == nil {
+			return
+		}
+		if i, ok := index.At(t).(int); ok && i >= numGiven {
+			kept[i-numGiven] = true
+		}
+	}
+	keep(out)
+	for _, r := range reqs {
+		keep(r.Type)
+	}
+	for _, hook := range set.Afters {
+		for _, p := range hook.Params {
+			keep(p)
+		}
+	}
+
+	var result []call
+	// remap maps the positions of the original calls to their positions in
+	// result.
+	remap := make([]int, len(calls))
+	var resolve func(args []int) []int
+	resolve = func(args []int) []int {
+		resolved := make([]int, len(args))
+		for k, a := range args {
+			if a < numGiven {
+				resolved[k] = a
+				continue
+			}
+			i := a - numGiven
+			if !copied[i] || !kept[i] {
+				// The first consumer takes the original.
+				kept[i] = copied[i]
+				resolved[k] = remap[i]
+				continue
+			}
+			clone := calls[i]
+			clone.args = resolve(calls[i].args)
+			resolved[k] = numGiven + len(result)
+			result = append(result, clone)
+		}
+		return resolved
+	}
+	for i, c := range calls {
+		c.args = resolve(c.args)
+		remap[i] = numGiven + len(result)
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// Inputs returns the minimal set of types that must be supplied from outside
+// set, such as injector arguments, to build out and the types set requires
+// with wire.Require. They are listed in the order they are first reached
+// from out. If set does not provide out, the result is just out.
+//
+// Inputs is meant for tools that write injector signatures: an injector
+// whose arguments are the returned types can be built from set.
+func (set *ProviderSet) Inputs(out types.Type) []types.Type {
+	var inputs []types.Type
+	var visited typeutil.Map
+	var visit func(t types.Type)
+	visit = func(t types.Type) {
+		if visited.At(t) != nil {
+			return
+		}
+		visited.Set(t, true)
+		pt := set.For(t)
+		switch {
+		case pt.IsNil():
+			inputs = append(inputs, t)
+		case pt.IsProvider():
+			for _, arg := range pt.Provider().Args {
+				visit(arg.Type)
+			}
+		case pt.IsField():
+			visit(pt.Field().Parent)
+		}
+	}
+	if out != nil {
+		visit(out)
+	}
+	for _, r := range set.requirements() {
+		visit(r.Type)
+	}
+	for _, hook := range set.Afters {
+		for _, t := range hook.Params {
+			visit(t)
+		}
+	}
+	return inputs
+}
+
+// verifyArgsUsed ensures that all of the arguments in set were used during
+// solve. Each error is positioned at the unused argument.
+func verifyArgsUsed(fset *token.FileSet, set *ProviderSet, used []*providerSetSrc) []error {
 	var errs []error
 	for _, imp := range set.Imports {
 		found := false
@@ -268,10 +585,11 @@ func verifyArgsUsed(set *ProviderSet, used []*providerSetSrc) []error {
 			}
 		}
 		if !found {
+			pos := fset.Position(set.argPosition(imp))
 			if imp.VarName == "" {
-				errs = append(errs, errors.New("unused provider set"))
+				errs = append(errs, notePosition(pos, withCode(CodeUnused, errors.New("unused provider set"))))
 			} else {
-				errs = append(errs, fmt.Errorf("unused provider set %q", imp.VarName))
+				errs = append(errs, notePosition(pos, withCode(CodeUnused, fmt.Errorf("unused provider set %q", imp.VarName))))
 			}
 		}
 	}
@@ -284,7 +602,7 @@ func verifyArgsUsed(set *ProviderSet, used []*providerSetSrc) []error {
 			}
 		}
 		if !found {
-			errs = append(errs, fmt.Errorf("unused provider %q", p.Pkg.Name()+"."+p.Name))
+			errs = append(errs, notePosition(fset.Position(set.argPosition(p)), withCode(CodeUnused, fmt.Errorf("unused provider %q", p.Pkg.Name()+"."+p.Name))))
 		}
 	}
 	for _, v := range set.Values {
@@ -296,7 +614,7 @@ func verifyArgsUsed(set *ProviderSet, used []*providerSetSrc) []error {
 			}
 		}
 		if !found {
-			errs = append(errs, fmt.Errorf("unused value of type %s", types.TypeString(v.Out, nil)))
+			errs = append(errs, notePosition(fset.Position(set.argPosition(v)), withCode(CodeUnused, fmt.Errorf("unused value of type %s", TypeString(v.Out)))))
 		}
 	}
 	for _, b := range set.Bindings {
@@ -308,7 +626,7 @@ func verifyArgsUsed(set *ProviderSet, used []*providerSetSrc) []error {
 			}
 		}
 		if !found {
-			errs = append(errs, fmt.Errorf("unused interface binding to type %s", types.TypeString(b.Iface, nil)))
+			errs = append(errs, notePosition(fset.Position(set.argPosition(b)), withCode(CodeUnused, fmt.Errorf("unused interface binding to type %s", TypeString(b.Iface)))))
 		}
 	}
 	for _, f := range set.Fields {
@@ -320,7 +638,7 @@ func verifyArgsUsed(set *ProviderSet, used []*providerSetSrc) []error {
 			}
 		}
 		if !found {
-			errs = append(errs, fmt.Errorf("unused field %q.%s", f.Parent, f.Name))
+			errs = append(errs, notePosition(fset.Position(set.argPosition(f)), withCode(CodeUnused, fmt.Errorf("unused field %q.%s", f.Parent, f.Name))))
 		}
 	}
 	return errs
@@ -329,7 +647,11 @@ func verifyArgsUsed(set *ProviderSet, used []*providerSetSrc) []error {
 // buildProviderMap creates the providerMap and srcMap fields for a given
 // provider set. The given provider set's providerMap and srcMap fields are
 // ignored.
-func buildProviderMap(fset *token.FileSet, hasher typeutil.Hasher, set *ProviderSet) (*typeutil.Map, *typeutil.Map, []error) {
+//
+// If autoContext is set and set is an injector's set with a context.Context
+// argument, context.Context outputs from the rest of the set are shadowed by
+// the argument and recorded in set.shadowed instead of conflicting with it.
+func buildProviderMap(fset *token.FileSet, hasher typeutil.Hasher, set *ProviderSet, autoContext bool) (*typeutil.Map, *typeutil.Map, []error) {
 	providerMap := new(typeutil.Map)
 	providerMap.SetHasher(hasher)
 	srcMap := new(typeutil.Map) // to *providerSetSrc
@@ -351,26 +673,52 @@ func buildProviderMap(fset *token.FileSet, hasher typeutil.Hasher, set *Provider
 			srcMap.Set(typ, src)
 		}
 	}
+	set.shadowed = nil
+	shadow := func(typ types.Type, src *providerSetSrc) bool {
+		if !autoContext || !isContextType(typ) {
+			return false
+		}
+		if prev, ok := srcMap.At(typ).(*providerSetSrc); !ok || prev.InjectorArg == nil {
+			return false
+		}
+		set.shadowed = append(set.shadowed, src)
+		return true
+	}
 	// Process imports, verifying that there are no conflicts between sets.
+	// Conflicts are collected rather than returned early so that a single
+	// run reports every conflict in the set.
+	// pending collects the bindings to resolve against the injector
+	// arguments, with the source to credit when they are used.
+	type pendingSrc struct {
+		pendingBinding
+		src *providerSetSrc
+	}
+	var pending []pendingSrc
 	for _, imp := range set.Imports {
 		src := &providerSetSrc{Import: imp}
-		imp.providerMap.Iterate(func(k types.Type, v interface{}) {
+		for _, pb := range imp.pending {
+			pending = append(pending, pendingSrc{pb, src})
+		}
+		for _, k := range sortedKeys(imp.providerMap) {
+			if shadow(k, src) {
+				continue
+			}
 			if prevSrc := srcMap.At(k); prevSrc != nil {
 				ec.add(bindingConflictError(fset, k, set, src, prevSrc.(*providerSetSrc)))
-				return
+				continue
 			}
-			providerMap.Set(k, v)
+			providerMap.Set(k, imp.providerMap.At(k))
 			srcMap.Set(k, src)
-		})
-	}
-	if len(ec.errors) > 0 {
-		return nil, nil, ec.errors
+		}
 	}
 
 	// Process non-binding providers in new set.
 	for _, p := range set.Providers {
 		src := &providerSetSrc{Provider: p}
 		for _, typ := range p.Out {
+			if shadow(typ, src) {
+				continue
+			}
 			if prevSrc := srcMap.At(typ); prevSrc != nil {
 				ec.add(bindingConflictError(fset, typ, set, src, prevSrc.(*providerSetSrc)))
 				continue
@@ -381,6 +729,9 @@ func buildProviderMap(fset *token.FileSet, hasher typeutil.Hasher, set *Provider
 	}
 	for _, v := range set.Values {
 		src := &providerSetSrc{Value: v}
+		if shadow(v.Out, src) {
+			continue
+		}
 		if prevSrc := srcMap.At(v.Out); prevSrc != nil {
 			ec.add(bindingConflictError(fset, v.Out, set, src, prevSrc.(*providerSetSrc)))
 			continue
@@ -391,6 +742,9 @@ func buildProviderMap(fset *token.FileSet, hasher typeutil.Hasher, set *Provider
 	for _, f := range set.Fields {
 		src := &providerSetSrc{Field: f}
 		for _, typ := range f.Out {
+			if shadow(typ, src) {
+				continue
+			}
 			if prevSrc := srcMap.At(typ); prevSrc != nil {
 				ec.add(bindingConflictError(fset, typ, set, src, prevSrc.(*providerSetSrc)))
 				continue
@@ -399,14 +753,14 @@ func buildProviderMap(fset *token.FileSet, hasher typeutil.Hasher, set *Provider
 			srcMap.Set(typ, src)
 		}
 	}
-	if len(ec.errors) > 0 {
-		return nil, nil, ec.errors
-	}
 
 	// Process bindings in set. Must happen after the other providers to
 	// ensure the concrete type is being provided.
 	for _, b := range set.Bindings {
 		src := &providerSetSrc{Binding: b}
+		if shadow(b.Iface, src) {
+			continue
+		}
 		if prevSrc := srcMap.At(b.Iface); prevSrc != nil {
 			ec.add(bindingConflictError(fset, b.Iface, set, src, prevSrc.(*providerSetSrc)))
 			continue
@@ -417,12 +771,39 @@ func buildProviderMap(fset *token.FileSet, hasher typeutil.Hasher, set *Provider
 			if setName == "" {
 				setName = "provider set"
 			}
-			ec.add(notePosition(fset.Position(b.Pos), fmt.Errorf("wire.Bind of concrete type %q to interface %q, but %s does not include a provider for %q", b.Provided, b.Iface, setName, b.Provided)))
+			// The concrete type may be an argument of the injectors
+			// that use the set.
+			pending = append(pending, pendingSrc{pendingBinding{binding: b, setName: setName}, src})
 			continue
 		}
 		providerMap.Set(b.Iface, concrete)
 		srcMap.Set(b.Iface, src)
 	}
+	set.pending = nil
+	for _, pb := range pending {
+		b := pb.binding
+		if set.InjectorArgs == nil {
+			set.pending = append(set.pending, pb.pendingBinding)
+			continue
+		}
+		// Only injector arguments resolve a binding from another set:
+		// a set must otherwise include a provider for the concrete type
+		// it binds.
+		concrete := providerMap.At(b.Provided)
+		if prevSrc, ok := srcMap.At(b.Provided).(*providerSetSrc); concrete == nil || !ok || prevSrc.InjectorArg == nil {
+			ec.add(notePosition(fset.Position(b.Pos), withCode(CodeNoProvider, fmt.Errorf("wire.Bind of concrete type %q to interface %q, but %s does not include a provider for %q", b.Provided, b.Iface, pb.setName, b.Provided))))
+			continue
+		}
+		if shadow(b.Iface, pb.src) {
+			continue
+		}
+		if prevSrc := srcMap.At(b.Iface); prevSrc != nil {
+			ec.add(bindingConflictError(fset, b.Iface, set, pb.src, prevSrc.(*providerSetSrc)))
+			continue
+		}
+		providerMap.Set(b.Iface, concrete)
+		srcMap.Set(b.Iface, pb.src)
+	}
 	if len(ec.errors) > 0 {
 		return nil, nil, ec.errors
 	}
@@ -439,8 +820,7 @@ func verifyAcyclic(providerMap *typeutil.Map, hasher typeutil.Hasher) []error {
 	visited.SetHasher(hasher)
 	ec := new(errorCollector)
 	// Sort output types so that errors about cycles are consistent.
-	outputs := providerMap.Keys()
-	sort.Slice(outputs, func(i, j int) bool { return types.TypeString(outputs[i], nil) < types.TypeString(outputs[j], nil) })
+	outputs := sortedKeys(providerMap)
 	for _, root := range outputs {
 		// Depth-first search using a stack of trails through the provider map.
 		stk := [][]types.Type{{root}}
@@ -477,19 +857,19 @@ func verifyAcyclic(providerMap *typeutil.Map, hasher typeutil.Hasher) []error {
 					for i, b := range curr {
 						if types.Identical(a, b) {
 							sb := new(strings.Builder)
-							fmt.Fprintf(sb, "cycle for %s:\n", types.TypeString(a, nil))
+							fmt.Fprintf(sb, "cycle for %s:\n", TypeString(a))
 							for j := i; j < len(curr); j++ {
 								t := providerMap.At(curr[j]).(*ProvidedType)
 								if t.IsProvider() {
 									p := t.Provider()
-									fmt.Fprintf(sb, "%s (%s.%s) ->\n", types.TypeString(curr[j], nil), p.Pkg.Path(), p.Name)
+									fmt.Fprintf(sb, "%s (%s.%s) ->\n", TypeString(curr[j]), p.Pkg.Path(), p.Name)
 								} else {
 									p := t.Field()
-									fmt.Fprintf(sb, "%s (%s.%s) ->\n", types.TypeString(curr[j], nil), p.Parent, p.Name)
+									fmt.Fprintf(sb, "%s (%s.%s) ->\n", TypeString(curr[j]), p.Parent, p.Name)
 								}
 							}
-							fmt.Fprintf(sb, "%s", types.TypeString(a, nil))
-							ec.add(errors.New(sb.String()))
+							fmt.Fprintf(sb, "%s", TypeString(a))
+							ec.add(withCode(CodeCycle, errors.New(sb.String())))
 							hasCycle = true
 							break
 						}
@@ -507,6 +887,14 @@ func verifyAcyclic(providerMap *typeutil.Map, hasher typeutil.Hasher) []error {
 	return ec.errors
 }
 
+// sortedKeys returns the keys of m ordered by their type strings, so that
+// iteration and the errors it produces are deterministic.
+func sortedKeys(m *typeutil.Map) []types.Type {
+	keys := m.Keys()
+	sort.Slice(keys, func(i, j int) bool { return TypeString(keys[i]) < TypeString(keys[j]) })
+	return keys
+}
+
 // bindingConflictError creates a new error describing multiple bindings
 // for the same output type.
 func bindingConflictError(fset *token.FileSet, typ types.Type, set *ProviderSet, cur, prev *providerSetSrc) error {
@@ -514,8 +902,34 @@ func bindingConflictError(fset *token.FileSet, typ types.Type, set *ProviderSet,
 	if set.VarName != "" {
 		fmt.Fprintf(sb, "%s has ", set.VarName)
 	}
-	fmt.Fprintf(sb, "multiple bindings for %s\n", types.TypeString(typ, nil))
+	fmt.Fprintf(sb, "multiple bindings for %s\n", TypeString(typ))
 	fmt.Fprintf(sb, "current:\n<- %s\n", strings.Join(cur.trace(fset, typ), "\n<- "))
 	fmt.Fprintf(sb, "previous:\n<- %s", strings.Join(prev.trace(fset, typ), "\n<- "))
-	return notePosition(fset.Position(set.Pos), errors.New(sb.String()))
+	if hint := conflictHint(set, typ, cur, prev); hint != "" {
+		fmt.Fprintf(sb, "\n%s", hint)
+	}
+	return notePosition(fset.Position(set.argPosition(cur.member())), withCode(CodeConflict, errors.New(sb.String())))
+}
+
+// conflictHint suggests how to resolve a conflict over typ between cur and
+// prev, the members of set that provide it, when at least one of them is an
+// imported provider set. It returns "" if there is no simple fix.
+func conflictHint(set *ProviderSet, typ types.Type, cur, prev *providerSetSrc) string {
+	if cur.Import == nil && prev.Import == nil {
+		return ""
+	}
+	curName, prevName := cur.memberName(), prev.memberName()
+	if curName == "" || prevName == "" || curName == prevName {
+		return ""
+	}
+	where := "wire.Build"
+	if set.VarName != "" {
+		where = set.PkgPath + "." + set.VarName
+	}
+	imported := cur
+	if imported.Import == nil {
+		imported = prev
+	}
+	return fmt.Sprintf("to keep one binding, remove %s or %s from %s, or use wire.Exclude to drop %s from %s",
+		curName, prevName, where, TypeString(typ), imported.memberName())
 }
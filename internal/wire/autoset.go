@@ -0,0 +1,149 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"regexp"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// defaultAutoNames matches the names of the functions wire.AutoSet treats as
+// providers when GenerateOptions.AutoNames is empty.
+var defaultAutoNames = regexp.MustCompile(`^New`)
+
+// autoNamesRegexp returns the compiled GenerateOptions.AutoNames, or nil if
+// it is empty or invalid. Generate reports an invalid expression before
+// generating anything.
+func autoNamesRegexp(expr string) *regexp.Regexp {
+	if expr == "" {
+		return nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil
+	}
+	return re
+}
+
+// processAutoSet creates a provider set from a wire.AutoSet call. The set
+// holds the exported functions whose names match the discovery expression,
+// declared in the packages that match the call's patterns among the calling
+// package and the packages it imports, directly or indirectly. Restricting
+// the patterns to those packages keeps the functions in the files the cache
+// key covers.
+func (oc *objectCache) processAutoSet(info *types.Info, pkgPath string, call *ast.CallExpr, varName string) (*ProviderSet, []error) {
+	// Assumes that call.Fun is wire.AutoSet.
+
+	pos := oc.fset.Position(call.Pos())
+	if len(call.Args) == 0 {
+		return nil, []error{notePosition(pos, errors.New("call to AutoSet takes at least one package pattern"))}
+	}
+	var deps []string
+	for path := range collectAllPackages([]*packages.Package{oc.packages[pkgPath]}) {
+		if !isWireImport(path) {
+			deps = append(deps, path)
+		}
+	}
+	sort.Strings(deps)
+	names := oc.autoNames
+	if names == nil {
+		names = defaultAutoNames
+	}
+	pset := &ProviderSet{
+		Pos:     call.Pos(),
+		PkgPath: pkgPath,
+		VarName: varName,
+	}
+	ec := new(errorCollector)
+	for _, arg := range call.Args {
+		tv := info.Types[arg]
+		if tv.Value == nil || tv.Value.Kind() != constant.String {
+			ec.add(notePosition(oc.fset.Position(arg.Pos()), errors.New("argument to AutoSet must be a constant import path pattern")))
+			continue
+		}
+		pattern := constant.StringVal(tv.Value)
+		match := importPathPattern(pattern)
+		matched := false
+		for _, dep := range deps {
+			if !match.MatchString(dep) {
+				continue
+			}
+			matched = true
+			providers, errs := oc.autoProviders(dep, names)
+			if len(errs) > 0 {
+				ec.add(errs...)
+				continue
+			}
+			for _, p := range providers {
+				pset.Providers = append(pset.Providers, p)
+				pset.noteArgPos(p, arg.Pos())
+			}
+		}
+		if !matched {
+			ec.add(notePosition(oc.fset.Position(arg.Pos()),
+				fmt.Errorf("AutoSet pattern %q matches no package imported by %s", pattern, pkgPath)))
+		}
+	}
+	if len(ec.errors) > 0 {
+		return nil, ec.errors
+	}
+	if len(pset.Providers) == 0 {
+		return nil, []error{notePosition(pos, fmt.Errorf("AutoSet found no exported functions matching %s", names))}
+	}
+	if errs := oc.rebuildProviderMap(pset); len(errs) > 0 {
+		return nil, errs
+	}
+	return pset, nil
+}
+
+// autoProviders returns the providers for the exported top-level functions
+// of the package at pkgPath whose names match names, in declaration order.
+// Functions in wireinject files, generic functions, and functions whose
+// signatures are not valid for providers are skipped: they match the naming
+// convention without being constructors.
+func (oc *objectCache) autoProviders(pkgPath string, names *regexp.Regexp) ([]*Provider, []error) {
+	pkg, errs := oc.ensurePackage(pkgPath)
+	if pkg == nil {
+		return nil, errs
+	}
+	var providers []*Provider
+	for _, f := range pkg.Syntax {
+		if isWireinjectFile(f) {
+			continue
+		}
+		for _, decl := range f.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Recv != nil || !fd.Name.IsExported() || !names.MatchString(fd.Name.Name) {
+				continue
+			}
+			fn, ok := pkg.TypesInfo.Defs[fd.Name].(*types.Func)
+			if !ok || fn.Type().(*types.Signature).TypeParams().Len() > 0 {
+				continue
+			}
+			item, errs := oc.get(fn)
+			if p, ok := item.(*Provider); ok && len(errs) == 0 {
+				providers = append(providers, p)
+			}
+		}
+	}
+	return providers, nil
+}
@@ -0,0 +1,323 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// BindGenOptions describes the injector stub written by BindGen.
+type BindGenOptions struct {
+	// Set names the provider set to build from, as "import/path.VarName".
+	Set string
+	// Out is the type the injector returns, such as "*example.com/db.Conn".
+	// Types written without an import path are looked up in the set's
+	// package, then among the predeclared types.
+	Out string
+	// Inputs are the types of the injector's arguments, written like Out.
+	// If empty, they are inferred as the types the set needs to build Out
+	// but does not provide itself.
+	Inputs []string
+	// Name is the name of the injector function.
+	Name string
+	// Header will be inserted at the start of the file.
+	Header []byte
+}
+
+// BindGen returns the source of a wireinject file for the package in wd that
+// declares a single injector building opts.Out from the provider set
+// opts.Set. It lets a package consume a provider set exported by a library
+// without hand-writing the injector; running Wire on the package then
+// generates the injector's implementation as usual.
+//
+// The package in wd only needs a name, so it may refer to the injector
+// before it exists.
+func BindGen(ctx context.Context, wd string, env []string, tags string, opts *BindGenOptions) ([]byte, []error) {
+	i := strings.LastIndex(opts.Set, ".")
+	if i <= 0 || strings.Contains(opts.Set[i:], "/") {
+		return nil, []error{fmt.Errorf("provider set %q must be of the form import/path.VarName", opts.Set)}
+	}
+	setPath, setVar := opts.Set[:i], opts.Set[i+1:]
+	if !token.IsIdentifier(opts.Name) {
+		return nil, []error{fmt.Errorf("injector name %q is not a valid identifier", opts.Name)}
+	}
+	consumer, err := bindGenPackage(ctx, wd, env, tags)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	pkgs, loader, errs := load(ctx, wd, env, tags, []string{setPath})
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if len(pkgs) == 0 {
+		return nil, []error{fmt.Errorf("package %s not found", setPath)}
+	}
+	oc := newObjectCache(pkgs, loader)
+	pkg, errs := oc.ensurePackage(pkgs[0].PkgPath)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	obj := pkg.Types.Scope().Lookup(setVar)
+	if obj == nil || !isProviderSetType(obj.Type()) {
+		return nil, []error{fmt.Errorf("%s is not a provider set", opts.Set)}
+	}
+	if !obj.Exported() && pkg.PkgPath != consumer.PkgPath {
+		return nil, []error{fmt.Errorf("provider set %s is not exported", opts.Set)}
+	}
+	item, errs := oc.get(obj)
+	if len(errs) > 0 {
+		return nil, notePositionAll(oc.fset.Position(obj.Pos()), errs)
+	}
+	set := item.(*ProviderSet)
+
+	out, err := lookupTypeString(pkg.Types, opts.Out)
+	if err != nil {
+		return nil, []error{err}
+	}
+	if set.For(out).IsNil() {
+		return nil, []error{fmt.Errorf("%s does not provide %s", opts.Set, TypeString(out))}
+	}
+	var inputs []types.Type
+	if len(opts.Inputs) > 0 {
+		for _, s := range opts.Inputs {
+			t, err := lookupTypeString(pkg.Types, s)
+			if err != nil {
+				return nil, []error{err}
+			}
+			inputs = append(inputs, t)
+		}
+	} else {
+		inputs = set.Inputs(out)
+	}
+
+	g := &bindGen{
+		pkgPath: consumer.PkgPath,
+		imports: map[string]string{"github.com/goforj/wire": "wire"},
+		names:   map[string]string{"github.com/goforj/wire": "wire"},
+	}
+	setRef := setVar
+	if pkg.PkgPath != consumer.PkgPath {
+		setRef = g.qualify(pkg.Types) + "." + setVar
+	}
+	// Qualify every type before naming the parameters so that the names do
+	// not shadow the imports they need.
+	outType := types.TypeString(out, g.qualify)
+	paramTypes := make([]string, len(inputs))
+	for i, t := range inputs {
+		paramTypes[i] = types.TypeString(t, g.qualify)
+	}
+	vars := make([]*types.Var, len(inputs))
+	params := make([]string, len(inputs))
+	used := make(map[string]bool)
+	collides := func(n string) bool { return used[n] || g.nameTaken(n) }
+	for i, t := range inputs {
+		n := typeVariableName(t, "arg", unexport, collides)
+		used[n] = true
+		vars[i] = types.NewVar(token.NoPos, nil, n, t)
+		params[i] = n + " " + paramTypes[i]
+	}
+	calls, errs := solve(oc.fset, out, types.NewTuple(vars...), set)
+	if len(errs) > 0 {
+		return nil, mapErrors(errs, func(e error) error {
+			if w, ok := e.(*wireErr); ok {
+				return notePosition(w.position, fmt.Errorf("inject %s: %w", opts.Name, w.error))
+			}
+			return fmt.Errorf("inject %s: %w", opts.Name, e)
+		})
+	}
+	results := []string{outType}
+	hasCleanup, cleanupErr, hasErr := false, false, false
+	for _, c := range calls {
+		hasCleanup = hasCleanup || c.hasCleanup
+		cleanupErr = cleanupErr || c.cleanupErr
+		hasErr = hasErr || c.hasErr
+	}
+	switch {
+	case cleanupErr:
+		results = append(results, "func() error")
+	case hasCleanup:
+		results = append(results, "func()")
+	}
+	if hasErr {
+		results = append(results, "error")
+	}
+
+	var buf bytes.Buffer
+	buf.Write(opts.Header)
+	buf.WriteString("// Injector stub generated by wire bind-gen. Run wire to generate its\n// implementation.\n\n")
+	buf.WriteString("//go:build wireinject\n// +build wireinject\n\n")
+	buf.WriteString("package " + consumer.Name + "\n\n")
+	g.writeImports(&buf)
+	result := strings.Join(results, ", ")
+	if len(results) > 1 {
+		result = "(" + result + ")"
+	}
+	fmt.Fprintf(&buf, "func %s(%s) %s {\n", opts.Name, strings.Join(params, ", "), result)
+	fmt.Fprintf(&buf, "\tpanic(wire.Build(%s))\n}\n", setRef)
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, []error{fmt.Errorf("generated stub is not valid Go source: %v", err)}
+	}
+	return src, nil
+}
+
+// bindGenPackage returns the name and path of the package in wd. Only names
+// are loaded, so the package may have errors.
+func bindGenPackage(ctx context.Context, wd string, env []string, tags string) (*packages.Package, error) {
+	cfg := &packages.Config{
+		Context:    ctx,
+		Mode:       packages.NeedName,
+		Dir:        wd,
+		Env:        env,
+		BuildFlags: []string{"-tags=wireinject"},
+	}
+	if len(tags) > 0 {
+		cfg.BuildFlags[0] += " " + tags
+	}
+	pkgs, err := packages.Load(cfg, ".")
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 || pkgs[0].Name == "" {
+		return nil, errors.New("no Go package found in " + wd)
+	}
+	return pkgs[0], nil
+}
+
+// lookupTypeString resolves a type written as an optional run of '*'
+// followed by a possibly qualified type name, such as
+// "*example.com/db.Conn". Qualified names must be in pkg or one of its
+// transitive imports; bare names are looked up in pkg and then among the
+// predeclared types.
+func lookupTypeString(pkg *types.Package, s string) (types.Type, error) {
+	name := strings.TrimLeft(s, "*")
+	stars := len(s) - len(name)
+	scope := pkg.Scope()
+	if i := strings.LastIndex(name, "."); i >= 0 && !strings.Contains(name[i:], "/") {
+		path := name[:i]
+		name = name[i+1:]
+		scope = nil
+		for _, p := range allTypesImports(pkg) {
+			if p.Path() == path {
+				scope = p.Scope()
+				break
+			}
+		}
+		if scope == nil {
+			return nil, fmt.Errorf("type %s: package %s is not imported by %s", s, path, pkg.Path())
+		}
+	}
+	obj, _ := scope.Lookup(name).(*types.TypeName)
+	if obj == nil && scope == pkg.Scope() {
+		obj, _ = types.Universe.Lookup(name).(*types.TypeName)
+	}
+	if obj == nil {
+		return nil, fmt.Errorf("type %s not found", s)
+	}
+	t := obj.Type()
+	for i := 0; i < stars; i++ {
+		t = types.NewPointer(t)
+	}
+	return t, nil
+}
+
+// allTypesImports returns pkg and its transitive imports.
+func allTypesImports(pkg *types.Package) []*types.Package {
+	seen := make(map[*types.Package]bool)
+	var all []*types.Package
+	stack := []*types.Package{pkg}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		all = append(all, p)
+		stack = append(stack, p.Imports()...)
+	}
+	return all
+}
+
+// bindGen tracks the imports of an injector stub or a file of provider
+// sets.
+type bindGen struct {
+	pkgPath string
+	// imports maps import paths to the names they are imported as.
+	imports map[string]string
+	// names maps import paths to their package names.
+	names map[string]string
+	// declared are the names the file declares, which imports must not
+	// shadow.
+	declared map[string]bool
+}
+
+// qualify is a types.Qualifier that imports pkg under a unique name.
+func (g *bindGen) qualify(pkg *types.Package) string {
+	if pkg.Path() == g.pkgPath {
+		return ""
+	}
+	if name, ok := g.imports[pkg.Path()]; ok {
+		return name
+	}
+	name := disambiguate(pkg.Name(), g.nameTaken)
+	g.imports[pkg.Path()] = name
+	g.names[pkg.Path()] = pkg.Name()
+	return name
+}
+
+// writeImports writes the import declaration of the file.
+func (g *bindGen) writeImports(buf *bytes.Buffer) {
+	buf.WriteString("import (\n")
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if name := g.imports[path]; name != g.names[path] {
+			fmt.Fprintf(buf, "\t%s %s\n", name, strconv.Quote(path))
+		} else {
+			fmt.Fprintf(buf, "\t%s\n", strconv.Quote(path))
+		}
+	}
+	buf.WriteString(")\n\n")
+}
+
+// nameTaken reports whether name is used for an import or declared by the
+// file.
+func (g *bindGen) nameTaken(name string) bool {
+	if g.declared[name] {
+		return true
+	}
+	for _, n := range g.imports {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
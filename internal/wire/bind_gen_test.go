@@ -0,0 +1,95 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBindGen(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "platform", "platform.go"), strings.Join([]string{
+		"package platform",
+		"",
+		"import (",
+		"\t\"net/http\"",
+		"",
+		"\t\"github.com/goforj/wire\"",
+		")",
+		"",
+		"type Config struct{}",
+		"type Logger struct{}",
+		"type DB struct{}",
+		"",
+		"func NewLogger() *Logger { return &Logger{} }",
+		"",
+		"func NewDB(Config, *Logger, *http.Client) (*DB, func(), error) { return &DB{}, func() {}, nil }",
+		"",
+		"var Set = wire.NewSet(NewLogger, NewDB)",
+		"",
+	}, "\n"))
+	// The consumer refers to an injector that does not exist yet.
+	writeFile(t, filepath.Join(root, "app", "main.go"), strings.Join([]string{
+		"package main",
+		"",
+		"import \"example.com/app/platform\"",
+		"",
+		"func main() { InitializeDB(platform.Config{}, nil) }",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	src, errs := BindGen(ctx, filepath.Join(root, "app"), env, "", &BindGenOptions{
+		Set:  "example.com/app/platform.Set",
+		Out:  "*example.com/app/platform.DB",
+		Name: "InitializeDB",
+	})
+	if len(errs) > 0 {
+		t.Fatalf("BindGen failed: %v", errs)
+	}
+	want := "func InitializeDB(config platform.Config, client *http.Client) (*platform.DB, func(), error) {\n\tpanic(wire.Build(platform.Set))\n}\n"
+	if !strings.Contains(string(src), want) {
+		t.Errorf("BindGen output:\n%s\nwant it to contain:\n%s", src, want)
+	}
+	if !strings.Contains(string(src), "//go:build wireinject\n") || !strings.Contains(string(src), "\npackage main\n") {
+		t.Errorf("BindGen output is not a wireinject file for package main:\n%s", src)
+	}
+
+	// The stub generates like a hand-written injector.
+	writeFile(t, filepath.Join(root, "app", "wire.go"), string(src))
+	gens, genErrs := Generate(ctx, filepath.Join(root, "app"), env, []string{"."}, &GenerateOptions{})
+	if len(genErrs) > 0 {
+		t.Fatalf("Generate failed: %v", genErrs)
+	}
+	if len(gens) != 1 || len(gens[0].Errs) > 0 || len(gens[0].Content) == 0 {
+		t.Fatalf("Generate results = %+v", gens)
+	}
+
+	_, errs = BindGen(ctx, filepath.Join(root, "app"), env, "", &BindGenOptions{
+		Set:    "example.com/app/platform.Set",
+		Out:    "*DB",
+		Inputs: []string{"Config"},
+		Name:   "InitializeDB",
+	})
+	if len(errs) == 0 || !strings.Contains(errs[0].Error(), "no provider found for *net/http.Client") {
+		t.Errorf("BindGen with missing input errors = %v; want missing provider for *net/http.Client", errs)
+	}
+}
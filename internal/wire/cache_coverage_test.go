@@ -16,13 +16,17 @@ package wire
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"sync"
 	"testing"
+	"time"
 
 	"golang.org/x/tools/go/packages"
 )
@@ -30,12 +34,14 @@ import (
 type cacheHookState struct {
 	osCreateTemp        func(string, string) (*os.File, error)
 	osMkdirAll          func(string, os.FileMode) error
+	osReadDir           func(string) ([]os.DirEntry, error)
 	osReadFile          func(string) ([]byte, error)
 	osRemove            func(string) error
 	osRemoveAll         func(string) error
 	osRename            func(string, string) error
 	osStat              func(string) (os.FileInfo, error)
 	osTempDir           func() string
+	timeNow             func() time.Time
 	jsonMarshal         func(any) ([]byte, error)
 	jsonUnmarshal       func([]byte, any) error
 	extraCachePathsFunc func(string) []string
@@ -61,12 +67,14 @@ func saveCacheHooks() cacheHookState {
 	return cacheHookState{
 		osCreateTemp:        osCreateTemp,
 		osMkdirAll:          osMkdirAll,
+		osReadDir:           osReadDir,
 		osReadFile:          osReadFile,
 		osRemove:            osRemove,
 		osRemoveAll:         osRemoveAll,
 		osRename:            osRename,
 		osStat:              osStat,
 		osTempDir:           osTempDir,
+		timeNow:             timeNow,
 		jsonMarshal:         jsonMarshal,
 		jsonUnmarshal:       jsonUnmarshal,
 		extraCachePathsFunc: extraCachePathsFunc,
@@ -82,12 +90,14 @@ func saveCacheHooks() cacheHookState {
 func restoreCacheHooks(state cacheHookState) {
 	osCreateTemp = state.osCreateTemp
 	osMkdirAll = state.osMkdirAll
+	osReadDir = state.osReadDir
 	osReadFile = state.osReadFile
 	osRemove = state.osRemove
 	osRemoveAll = state.osRemoveAll
 	osRename = state.osRename
 	osStat = state.osStat
 	osTempDir = state.osTempDir
+	timeNow = state.timeNow
 	jsonMarshal = state.jsonMarshal
 	jsonUnmarshal = state.jsonUnmarshal
 	extraCachePathsFunc = state.extraCachePathsFunc
@@ -179,6 +189,40 @@ func TestCacheStoreReadError(t *testing.T) {
 	}
 }
 
+func TestCacheStoreDiscardsCorruptBlobs(t *testing.T) {
+	lockCacheHooks(t)
+	state := saveCacheHooks()
+	t.Cleanup(func() { restoreCacheHooks(state) })
+
+	tempDir := t.TempDir()
+	osTempDir = func() string { return tempDir }
+
+	writeCache("empty", nil)
+	if got, ok := readCache("empty"); !ok || len(got) != 0 {
+		t.Fatalf("readCache(empty) = %q, %v; want empty hit", got, ok)
+	}
+	blob := encodeCacheBlob([]byte("package app\n"))
+	for name, data := range map[string][]byte{
+		"truncated": blob[:len(blob)-3],
+		"flipped":   append(append([]byte(nil), blob[:len(blob)-1]...), 'x'),
+		"legacy":    []byte("package app\n"),
+	} {
+		path := cachePath(name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got, ok := readCache(name); ok {
+			t.Errorf("readCache(%s) = %q; want miss", name, got)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("corrupt blob %s was not removed: %v", name, err)
+		}
+	}
+}
+
 func TestCacheStoreWriteErrors(t *testing.T) {
 	lockCacheHooks(t)
 	state := saveCacheHooks()
@@ -518,14 +562,14 @@ func TestManifestReadWriteErrors(t *testing.T) {
 	tempDir := t.TempDir()
 	osTempDir = func() string { return tempDir }
 
-	if _, ok := readManifest("missing"); ok {
+	if _, ok := readManifest("missing", manifestRoot{}); ok {
 		t.Fatal("expected manifest miss")
 	}
 
 	osReadFile = func(string) ([]byte, error) {
 		return []byte("{bad json"), nil
 	}
-	if _, ok := readManifest("bad-json"); ok {
+	if _, ok := readManifest("bad-json", manifestRoot{}); ok {
 		t.Fatal("expected manifest miss on invalid json")
 	}
 
@@ -1065,3 +1109,269 @@ func TestManifestValidHookBranches(t *testing.T) {
 		t.Fatal("expected root files mismatch")
 	}
 }
+
+func TestRecordManifestUsePrunes(t *testing.T) {
+	lockCacheHooks(t)
+	state := saveCacheHooks()
+	t.Cleanup(func() { restoreCacheHooks(state) })
+
+	tempDir := t.TempDir()
+	osTempDir = func() string { return tempDir }
+	if err := os.MkdirAll(cacheDir(), 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	timeNow = func() time.Time { return now }
+	writeManifestStub := func(key string) {
+		t.Helper()
+		if err := os.WriteFile(cacheManifestPath(key), []byte("{}"), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+
+	// A manifest unused for longer than manifestMaxAge is pruned.
+	writeManifestStub("old")
+	recordManifestUse("old", "/work", []string{"./..."})
+	now = now.Add(manifestMaxAge + time.Minute)
+	for i := 0; i < maxManifests+2; i++ {
+		key := fmt.Sprintf("k%03d", i)
+		writeManifestStub(key)
+		now = now.Add(time.Second)
+		recordManifestUse(key, "/work", []string{fmt.Sprintf("./p%d", i)})
+	}
+	// Using a manifest again keeps it among the most recent.
+	now = now.Add(time.Second)
+	recordManifestUse("k002", "/work", []string{"./p2"})
+
+	idx := readManifestIndex()
+	if len(idx.Entries) != maxManifests {
+		t.Fatalf("index has %d entries; want %d", len(idx.Entries), maxManifests)
+	}
+	if idx.Entries[0].Key != "k002" {
+		t.Errorf("most recently used manifest = %s; want k002", idx.Entries[0].Key)
+	}
+	for _, key := range []string{"old", "k000", "k001"} {
+		if _, err := os.Stat(cacheManifestPath(key)); !os.IsNotExist(err) {
+			t.Errorf("manifest %s was not pruned (err=%v)", key, err)
+		}
+	}
+	for _, key := range []string{"k002", "k003", fmt.Sprintf("k%03d", maxManifests+1)} {
+		if _, err := os.Stat(cacheManifestPath(key)); err != nil {
+			t.Errorf("manifest %s was pruned: %v", key, err)
+		}
+	}
+
+	// Stray manifests are only removed once they are past the grace period.
+	writeManifestStub("stray")
+	recordManifestUse("k002", "/work", []string{"./p2"})
+	if _, err := os.Stat(cacheManifestPath("stray")); err != nil {
+		t.Errorf("recent stray manifest was removed: %v", err)
+	}
+	old := time.Now().Add(-2 * unindexedManifestGrace)
+	if err := os.Chtimes(cacheManifestPath("stray"), old, old); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+	timeNow = time.Now
+	recordManifestUse("k002", "/work", []string{"./p2"})
+	if _, err := os.Stat(cacheManifestPath("stray")); !os.IsNotExist(err) {
+		t.Errorf("old stray manifest was not removed (err=%v)", err)
+	}
+}
+
+func TestTouchManifestDoesNotPrune(t *testing.T) {
+	lockCacheHooks(t)
+	state := saveCacheHooks()
+	t.Cleanup(func() { restoreCacheHooks(state) })
+
+	tempDir := t.TempDir()
+	osTempDir = func() string { return tempDir }
+	if err := os.MkdirAll(cacheDir(), 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+	old := now.Add(-2 * unindexedManifestGrace)
+	for _, key := range []string{"used", "stray"} {
+		if err := os.WriteFile(cacheManifestPath(key), []byte("{}"), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		if err := os.Chtimes(cacheManifestPath(key), old, old); err != nil {
+			t.Fatalf("Chtimes failed: %v", err)
+		}
+	}
+	recordManifestUse("used", "/work", []string{"./..."})
+	if _, err := os.Stat(cacheManifestPath("stray")); !os.IsNotExist(err) {
+		t.Fatalf("old stray manifest was not removed on write (err=%v)", err)
+	}
+
+	// A hit only bumps the entry, and does not scan the cache directory.
+	if err := os.WriteFile(cacheManifestPath("stray"), []byte("{}"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := os.Chtimes(cacheManifestPath("stray"), old, old); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+	osReadDir = func(string) ([]os.DirEntry, error) {
+		t.Error("touchManifest read the cache directory")
+		return nil, nil
+	}
+	now = now.Add(manifestMaxAge + time.Minute)
+	touchManifest("used", "/work", []string{"./..."})
+	if _, err := os.Stat(cacheManifestPath("stray")); err != nil {
+		t.Errorf("touchManifest removed a stray manifest: %v", err)
+	}
+	idx := readManifestIndex()
+	if len(idx.Entries) != 1 || idx.Entries[0].LastUsed != now.UnixNano() {
+		t.Errorf("index after touchManifest = %+v; want used, last used now", idx.Entries)
+	}
+}
+
+func TestRecordManifestUseConcurrent(t *testing.T) {
+	lockCacheHooks(t)
+	state := saveCacheHooks()
+	t.Cleanup(func() { restoreCacheHooks(state) })
+
+	tempDir := t.TempDir()
+	osTempDir = func() string { return tempDir }
+	if err := os.MkdirAll(cacheDir(), 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	const runs = 16
+	var wg sync.WaitGroup
+	for i := 0; i < runs; i++ {
+		key := fmt.Sprintf("k%02d", i)
+		if err := os.WriteFile(cacheManifestPath(key), []byte("{}"), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		touch := i%2 == 1
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if touch {
+				touchManifest(key, "/work", []string{"./..."})
+			} else {
+				recordManifestUse(key, "/work", []string{"./..."})
+			}
+		}()
+	}
+	wg.Wait()
+	if got := len(readManifestIndex().Entries); got != runs {
+		t.Errorf("index has %d entries after %d concurrent runs; want %d", got, runs, runs)
+	}
+	for i := 0; i < runs; i++ {
+		if _, err := os.Stat(cacheManifestPath(fmt.Sprintf("k%02d", i))); err != nil {
+			t.Errorf("manifest k%02d was removed: %v", i, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir(), manifestIndexLockName)); !os.IsNotExist(err) {
+		t.Errorf("index lock left behind (err=%v)", err)
+	}
+}
+
+func TestClearPackageAndInvalidatePattern(t *testing.T) {
+	lockCacheHooks(t)
+	state := saveCacheHooks()
+	t.Cleanup(func() { restoreCacheHooks(state) })
+
+	tempDir := t.TempDir()
+	osTempDir = func() string { return tempDir }
+	ctx := context.Background()
+
+	if err := ClearPackage(ctx, "example.com/app"); err != nil {
+		t.Fatalf("ClearPackage without a cache directory: %v", err)
+	}
+	pkgPaths := []string{"example.com/app", "example.com/app/db", "example.com/application", "example.com/other"}
+	for i, pkgPath := range pkgPaths {
+		hash := fmt.Sprintf("content%d", i)
+		writeCache(hash, []byte(pkgPath))
+		writeCache(examplesCacheKey(hash), []byte(pkgPath))
+		writeCacheMeta(fmt.Sprintf("meta%d", i), &cacheMeta{Version: cacheVersion, PkgPath: pkgPath, ContentHash: hash})
+	}
+	writeManifestFile("withdb", &cacheManifest{Version: cacheVersion, Packages: []manifestPackage{{PkgPath: "example.com/app/db"}}})
+	writeManifestFile("withother", &cacheManifest{Version: cacheVersion, Packages: []manifestPackage{{PkgPath: "example.com/other"}}})
+	cached := func() []string {
+		var got []string
+		for i, pkgPath := range pkgPaths {
+			_, ok := readCache(fmt.Sprintf("content%d", i))
+			if _, metaOK := readCacheMeta(fmt.Sprintf("meta%d", i)); ok && metaOK {
+				got = append(got, pkgPath)
+			}
+		}
+		return got
+	}
+	manifestExists := func(key string) bool {
+		_, err := os.Stat(cacheManifestPath(key))
+		return err == nil
+	}
+
+	if err := ClearPackage(ctx, "example.com/other"); err != nil {
+		t.Fatalf("ClearPackage: %v", err)
+	}
+	if got, want := cached(), pkgPaths[:3]; !reflect.DeepEqual(got, want) {
+		t.Errorf("after ClearPackage, cached packages = %v; want %v", got, want)
+	}
+	if _, ok := readCache(examplesCacheKey("content3")); ok {
+		t.Error("ClearPackage kept the cached examples")
+	}
+	if manifestExists("withother") || !manifestExists("withdb") {
+		t.Errorf("after ClearPackage, manifests withother, withdb exist = %t, %t; want false, true", manifestExists("withother"), manifestExists("withdb"))
+	}
+
+	if err := InvalidatePattern(ctx, []string{"example.com/app/..."}); err != nil {
+		t.Fatalf("InvalidatePattern: %v", err)
+	}
+	if got, want := cached(), []string{"example.com/application"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after InvalidatePattern, cached packages = %v; want %v", got, want)
+	}
+	if manifestExists("withdb") {
+		t.Error("InvalidatePattern kept the manifest listing example.com/app/db")
+	}
+}
+
+func TestImportPathPattern(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"example.com/app", "example.com/app", true},
+		{"example.com/app", "example.com/app/db", false},
+		{"example.com/app/...", "example.com/app", true},
+		{"example.com/app/...", "example.com/app/db/sql", true},
+		{"example.com/app/...", "example.com/application", false},
+		{"example.com/.../db", "example.com/app/db", true},
+		{"example.com/app.v2", "example.com/appxv2", false},
+	}
+	for _, test := range tests {
+		if got := importPathPattern(test.pattern).MatchString(test.path); got != test.want {
+			t.Errorf("importPathPattern(%q) matches %q = %t; want %t", test.pattern, test.path, got, test.want)
+		}
+	}
+}
+
+func TestCacheVersionIncludesToolIdentity(t *testing.T) {
+	lockCacheHooks(t)
+	state := saveCacheHooks()
+	t.Cleanup(func() { restoreCacheHooks(state) })
+
+	if id := toolIdentity(); id != toolIdentity() || cacheVersion != cacheSchema+"+"+id {
+		t.Fatalf("cacheVersion = %q; want %q followed by a stable tool identity", cacheVersion, cacheSchema)
+	}
+	tempDir := t.TempDir()
+	osTempDir = func() string { return tempDir }
+	file := writeTempFile(t, tempDir, "a.go", "package a\n")
+	pkg := &packages.Package{PkgPath: "example.com/a", GoFiles: []string{file}}
+	key, err := cacheKeyForPackage(pkg, &GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := cacheVersion
+	t.Cleanup(func() { cacheVersion = saved })
+	cacheVersion = cacheSchema + "+other-build"
+	other, err := cacheKeyForPackage(pkg, &GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key == other {
+		t.Error("cache keys of different builds of Wire are equal")
+	}
+}
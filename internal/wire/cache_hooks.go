@@ -17,17 +17,20 @@ package wire
 import (
 	"encoding/json"
 	"os"
+	"time"
 )
 
 var (
 	osCreateTemp = os.CreateTemp
 	osMkdirAll   = os.MkdirAll
+	osReadDir    = os.ReadDir
 	osReadFile   = os.ReadFile
 	osRemove     = os.Remove
 	osRemoveAll  = os.RemoveAll
 	osRename     = os.Rename
 	osStat       = os.Stat
 	osTempDir    = os.TempDir
+	timeNow      = time.Now
 
 	jsonMarshal   = json.Marshal
 	jsonUnmarshal = json.Unmarshal
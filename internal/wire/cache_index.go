@@ -0,0 +1,235 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// manifestIndexName is the name of the file in the cache directory that
+	// lists the manifests in use.
+	manifestIndexName = "manifests.index.json"
+	// maxManifests is the number of most recently used manifests kept.
+	maxManifests = 64
+	// manifestMaxAge is how long a manifest is kept after its last use.
+	manifestMaxAge = 14 * 24 * time.Hour
+	// unindexedManifestGrace is how long a manifest missing from the index
+	// is kept, so that one written by a concurrent run before it updates
+	// the index is not removed.
+	unindexedManifestGrace = time.Hour
+	// manifestIndexLockName is the name of the file whose existence locks
+	// the manifest index.
+	manifestIndexLockName = "manifests.index.lock"
+	// manifestIndexLockWait is how long a run waits for the lock before it
+	// gives up updating the index.
+	manifestIndexLockWait = 2 * time.Second
+	// manifestIndexLockStale is the age after which a lock is taken to be
+	// left behind by a run that died while holding it.
+	manifestIndexLockStale = 30 * time.Second
+)
+
+// manifestIndex lists the manifests in the cache directory and when each
+// was last used. Each distinct combination of working directory, patterns,
+// environment, and options has its own manifest; the index lets runs prune
+// the ones that are no longer used instead of leaving them to accumulate.
+type manifestIndex struct {
+	Version string               `json:"version"`
+	Entries []manifestIndexEntry `json:"entries"`
+}
+
+// manifestIndexEntry describes a single manifest in the index.
+type manifestIndexEntry struct {
+	Key      string   `json:"key"`
+	WD       string   `json:"wd"`
+	Patterns []string `json:"patterns"`
+	// LastUsed is when the manifest was last written or read, in Unix
+	// nanoseconds.
+	LastUsed int64 `json:"last_used"`
+}
+
+// manifestIndexPath returns the on-disk path of the manifest index.
+func manifestIndexPath() string {
+	return filepath.Join(cacheDir(), manifestIndexName)
+}
+
+// readManifestIndex loads the manifest index. A missing, unreadable, or
+// outdated index yields an empty one.
+func readManifestIndex() *manifestIndex {
+	idx := &manifestIndex{Version: cacheVersion}
+	data, err := osReadFile(manifestIndexPath())
+	if err != nil {
+		return idx
+	}
+	var stored manifestIndex
+	if err := jsonUnmarshal(data, &stored); err != nil || stored.Version != cacheVersion {
+		return idx
+	}
+	return &stored
+}
+
+// writeManifestIndex writes the manifest index to disk. Callers hold the
+// lock of the index, so that concurrent runs do not lose each other's
+// entries.
+func writeManifestIndex(idx *manifestIndex) {
+	dir := cacheDir()
+	if err := osMkdirAll(dir, 0755); err != nil {
+		return
+	}
+	data, err := jsonMarshal(idx)
+	if err != nil {
+		return
+	}
+	tmp, err := osCreateTemp(dir, manifestIndexName+"-")
+	if err != nil {
+		return
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil {
+		osRemove(tmp.Name())
+		return
+	}
+	if err := osRename(tmp.Name(), manifestIndexPath()); err != nil {
+		osRemove(tmp.Name())
+	}
+}
+
+// lockManifestIndex takes the lock of the manifest index, waiting up to
+// manifestIndexLockWait for other runs to release it, and returns the
+// function that releases it. It reports false if the lock could not be
+// taken, in which case the index is left alone.
+func lockManifestIndex() (unlock func(), ok bool) {
+	dir := cacheDir()
+	if err := osMkdirAll(dir, 0755); err != nil {
+		return nil, false
+	}
+	path := filepath.Join(dir, manifestIndexLockName)
+	deadline := time.Now().Add(manifestIndexLockWait)
+	for {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			f.Close()
+			return func() { osRemove(path) }, true
+		}
+		if !os.IsExist(err) {
+			return nil, false
+		}
+		if info, err := osStat(path); err == nil && time.Since(info.ModTime()) > manifestIndexLockStale {
+			osRemove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// updateManifestIndex applies update to the manifest index under its lock.
+func updateManifestIndex(update func(idx *manifestIndex, now time.Time)) {
+	unlock, ok := lockManifestIndex()
+	if !ok {
+		return
+	}
+	defer unlock()
+	idx := readManifestIndex()
+	update(idx, timeNow())
+	writeManifestIndex(idx)
+}
+
+// touchManifest marks the manifest stored under key as used now. It runs on
+// every manifest hit, so it leaves pruning to the runs that write
+// manifests.
+func touchManifest(key, wd string, patterns []string) {
+	updateManifestIndex(func(idx *manifestIndex, now time.Time) {
+		idx.markUsed(key, wd, patterns, now)
+	})
+}
+
+// recordManifestUse marks the manifest stored under key, just written, as
+// used now and prunes the manifests that have not been used recently: those
+// beyond the maxManifests most recently used, those unused for
+// manifestMaxAge, and stray manifests missing from the index, such as ones
+// written by older versions of Wire.
+func recordManifestUse(key, wd string, patterns []string) {
+	updateManifestIndex(func(idx *manifestIndex, now time.Time) {
+		idx.markUsed(key, wd, patterns, now)
+		idx.prune(now)
+	})
+}
+
+// markUsed sets the last use of the manifest stored under key to now,
+// adding an entry for it if there is none.
+func (idx *manifestIndex) markUsed(key, wd string, patterns []string, now time.Time) {
+	for i := range idx.Entries {
+		if idx.Entries[i].Key == key {
+			idx.Entries[i].LastUsed = now.UnixNano()
+			return
+		}
+	}
+	idx.Entries = append(idx.Entries, manifestIndexEntry{
+		Key:      key,
+		WD:       filepath.Clean(wd),
+		Patterns: sortedStrings(patterns),
+		LastUsed: now.UnixNano(),
+	})
+}
+
+// prune removes the manifests that have not been used recently from the
+// index and the cache directory, along with old manifests missing from the
+// index.
+func (idx *manifestIndex) prune(now time.Time) {
+	sort.SliceStable(idx.Entries, func(i, j int) bool {
+		return idx.Entries[i].LastUsed > idx.Entries[j].LastUsed
+	})
+	kept := idx.Entries[:0]
+	indexed := make(map[string]bool)
+	for i, e := range idx.Entries {
+		if i >= maxManifests || now.Sub(time.Unix(0, e.LastUsed)) > manifestMaxAge {
+			osRemove(cacheManifestPath(e.Key))
+			continue
+		}
+		kept = append(kept, e)
+		indexed[e.Key] = true
+	}
+	idx.Entries = kept
+	removeUnindexedManifests(indexed, now)
+}
+
+// removeUnindexedManifests removes the manifests in the cache directory
+// that are not in indexed and are older than unindexedManifestGrace.
+func removeUnindexedManifests(indexed map[string]bool, now time.Time) {
+	entries, err := osReadDir(cacheDir())
+	if err != nil {
+		return
+	}
+	const suffix = ".manifest.json"
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, suffix) || indexed[strings.TrimSuffix(name, suffix)] {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || now.Sub(info.ModTime()) < unindexedManifestGrace {
+			continue
+		}
+		osRemove(filepath.Join(cacheDir(), name))
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ClearPackage removes the cached output of the package at pkgPath, for
+// every set of options it was generated with, so that the next run
+// generates it again. Build tools call it after rewriting a package's
+// sources in ways that keep file contents and times the cache checks, such
+// as restoring files from another checkout. The manifests that list the
+// package are removed too; the other packages they list stay cached.
+func ClearPackage(ctx context.Context, pkgPath string) error {
+	return clearPackages(ctx, func(path string) bool { return path == pkgPath })
+}
+
+// InvalidatePattern is like ClearPackage for each package whose import path
+// matches one of patterns. A pattern is an import path, or an import path
+// containing "..." wildcards as for the go command, such as
+// "example.com/app/...".
+func InvalidatePattern(ctx context.Context, patterns []string) error {
+	res := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		res[i] = importPathPattern(p)
+	}
+	return clearPackages(ctx, func(path string) bool {
+		for _, re := range res {
+			if re.MatchString(path) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// importPathPattern returns a regular expression that matches the import
+// paths matched by pattern. As for the go command, "..." matches any
+// string, and a trailing "/..." also matches the path before it.
+func importPathPattern(pattern string) *regexp.Regexp {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\.\.\.`, `.*`)
+	if strings.HasSuffix(expr, `/.*`) {
+		expr = strings.TrimSuffix(expr, `/.*`) + `(/.*)?`
+	}
+	return regexp.MustCompile(`^` + expr + `$`)
+}
+
+// clearPackages removes the cache entries of the packages whose import
+// paths match: their metadata and output, and the manifests that list them.
+func clearPackages(ctx context.Context, match func(pkgPath string) bool) error {
+	entries, err := osReadDir(cacheDir())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	const manifestSuffix = ".manifest.json"
+	removed := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") || name == manifestIndexName {
+			continue
+		}
+		data, err := osReadFile(filepath.Join(cacheDir(), name))
+		if err != nil {
+			continue
+		}
+		if strings.HasSuffix(name, manifestSuffix) {
+			var manifest cacheManifest
+			if jsonUnmarshal(data, &manifest) != nil {
+				continue
+			}
+			for _, pkg := range manifest.Packages {
+				if match(pkg.PkgPath) {
+					if err := removeCacheFile(cacheManifestPath(strings.TrimSuffix(name, manifestSuffix))); err != nil {
+						return err
+					}
+					break
+				}
+			}
+			continue
+		}
+		var meta cacheMeta
+		if jsonUnmarshal(data, &meta) != nil || meta.PkgPath == "" || !match(meta.PkgPath) {
+			continue
+		}
+		for _, path := range []string{
+			cachePath(meta.ContentHash),
+			cachePath(examplesCacheKey(meta.ContentHash)),
+			cacheMetaPath(strings.TrimSuffix(name, ".json")),
+		} {
+			if err := removeCacheFile(path); err != nil {
+				return err
+			}
+		}
+		debugf(ctx, "cleared the cached output of %s", meta.PkgPath)
+		removed++
+	}
+	debugf(ctx, "cleared %d cache entries", removed)
+	return nil
+}
+
+// removeCacheFile removes the cache file at path, if it exists.
+func removeCacheFile(path string) error {
+	if err := osRemove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -17,20 +17,88 @@ package wire
 import (
 	"crypto/sha256"
 	"fmt"
+	"hash"
+	"os"
 	"path/filepath"
+	"runtime"
+	"runtime/debug"
 	"sort"
+	"sync"
+	"sync/atomic"
 
 	"golang.org/x/tools/go/packages"
 )
 
-// cacheVersion is the schema/version identifier for cache entries.
-const cacheVersion = "wire-cache-v3"
+// cacheSchema is the schema identifier for cache entries. Bump it when the
+// layout of the entries changes.
+const cacheSchema = "wire-cache-v9"
+
+// cacheVersion identifies cache entries written by this build of Wire: the
+// schema and the tool's identity, so that switching between versions of
+// Wire, or between forks, never serves output another generator wrote,
+// even if nobody bumped cacheSchema.
+var cacheVersion = cacheSchema + "+" + toolIdentity()
+
+// wireModulePath is the path of the module Wire is built from.
+const wireModulePath = "github.com/goforj/wire"
+
+// toolIdentity identifies the running build of Wire by the Go version it was
+// built with and, in order of preference, the released version of its
+// module, its VCS revision if the checkout was clean, or the size and
+// modification time of its executable, which change on every rebuild.
+func toolIdentity() string {
+	id := runtime.Version()
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return id + "+" + executableIdentity()
+	}
+	mod := &bi.Main
+	for _, dep := range bi.Deps {
+		if dep.Path == wireModulePath {
+			mod = dep
+		}
+	}
+	if mod.Path == wireModulePath && mod.Replace == nil && mod.Version != "" && mod.Version != "(devel)" {
+		return id + "+" + mod.Version + "+" + mod.Sum
+	}
+	if mod == &bi.Main {
+		var revision, modified string
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				revision = s.Value
+			case "vcs.modified":
+				modified = s.Value
+			}
+		}
+		if revision != "" && modified == "false" {
+			return id + "+" + revision
+		}
+	}
+	return id + "+" + executableIdentity()
+}
+
+// executableIdentity returns the path, size, and modification time of the
+// running executable, or "" if they are unknown.
+func executableIdentity() string {
+	path, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d:%d", path, info.Size(), info.ModTime().UnixNano())
+}
 
 // cacheFile captures file metadata used to validate cached content.
 type cacheFile struct {
 	Path    string `json:"path"`
 	Size    int64  `json:"size"`
 	ModTime int64  `json:"mod_time"`
+	// Hash is the content hash of the file, recorded only in manifests.
+	Hash string `json:"hash,omitempty"`
 }
 
 // cacheMeta tracks inputs and outputs for a single package cache entry.
@@ -51,6 +119,10 @@ func cacheKeyForPackage(pkg *packages.Package, opts *GenerateOptions) (string, e
 	if len(files) == 0 {
 		return "", nil
 	}
+	if gomod := packageGoMod(pkg); gomod != "" {
+		// The go directive decides whether cleanups may use errors.Join.
+		files = append(files, gomod)
+	}
 	sort.Strings(files)
 	metaKey := cacheMetaKey(pkg, opts)
 	if meta, ok := readCacheMeta(metaKey); ok {
@@ -86,6 +158,19 @@ func cacheKeyForPackage(pkg *packages.Package, opts *GenerateOptions) (string, e
 	return contentHash, nil
 }
 
+// packageGoMod returns the go.mod file of the module holding pkg, or the
+// empty string if there is none.
+func packageGoMod(pkg *packages.Package) string {
+	if len(pkg.GoFiles) == 0 {
+		return ""
+	}
+	dir := moduleDir(filepath.Dir(pkg.GoFiles[0]))
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "go.mod")
+}
+
 // packageFiles returns the transitive Go files for a package graph.
 func packageFiles(root *packages.Package) []string {
 	seen := make(map[string]struct{})
@@ -101,11 +186,7 @@ func packageFiles(root *packages.Package) []string {
 			continue
 		}
 		seen[p.PkgPath] = struct{}{}
-		if len(p.CompiledGoFiles) > 0 {
-			files = append(files, p.CompiledGoFiles...)
-		} else if len(p.GoFiles) > 0 {
-			files = append(files, p.GoFiles...)
-		}
+		files = append(files, packageSourceFiles(p)...)
 		for _, imp := range p.Imports {
 			stack = append(stack, imp)
 		}
@@ -125,9 +206,32 @@ func cacheMetaKey(pkg *packages.Package, opts *GenerateOptions) string {
 	h.Write([]byte(opts.PrefixOutputFile))
 	h.Write([]byte{0})
 	h.Write([]byte(headerHash(opts.Header)))
+	writeOptionFlags(h, opts)
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
+// writeOptionFlags hashes the boolean generation options, and the expression
+// of AutoNames, that change the generated output. Nothing is written for
+// defaults so that keys for existing configurations stay stable.
+func writeOptionFlags(h hash.Hash, opts *GenerateOptions) {
+	if opts.AutoContext {
+		h.Write([]byte{0})
+		h.Write([]byte("auto_context"))
+	}
+	if opts.StableOrder {
+		h.Write([]byte{0})
+		h.Write([]byte("stable_order"))
+	}
+	if opts.SourceMap {
+		h.Write([]byte{0})
+		h.Write([]byte("source_map"))
+	}
+	if opts.AutoNames != "" {
+		h.Write([]byte{0})
+		h.Write([]byte("auto_names=" + opts.AutoNames))
+	}
+}
+
 // cacheMetaPath returns the on-disk path for a cache metadata key.
 func cacheMetaPath(key string) string {
 	return filepath.Join(cacheDir(), key+".json")
@@ -250,16 +354,10 @@ func contentHashForPaths(pkgPath string, opts *GenerateOptions, files []string)
 	h.Write([]byte(opts.PrefixOutputFile))
 	h.Write([]byte{0})
 	h.Write([]byte(headerHash(opts.Header)))
+	writeOptionFlags(h, opts)
 	h.Write([]byte{0})
-	for _, name := range files {
-		h.Write([]byte(name))
-		h.Write([]byte{0})
-		data, err := osReadFile(name)
-		if err != nil {
-			return "", err
-		}
-		h.Write(data)
-		h.Write([]byte{0})
+	if err := writeFileDigests(h, files); err != nil {
+		return "", err
 	}
 	return fmt.Sprintf("%x", h.Sum(nil)), nil
 }
@@ -269,6 +367,18 @@ func rootPackageFiles(pkg *packages.Package) []string {
 	if pkg == nil {
 		return nil
 	}
+	return packageSourceFiles(pkg)
+}
+
+// packageSourceFiles returns the files whose content determines the types
+// of pkg. For cgo packages, CompiledGoFiles holds the output of cgo in the
+// build cache rather than the package's own files, so the Go and C sources
+// that cgo processes are used instead.
+func packageSourceFiles(pkg *packages.Package) []string {
+	if usesCgo(pkg) {
+		files := append([]string(nil), pkg.GoFiles...)
+		return append(files, pkg.OtherFiles...)
+	}
 	if len(pkg.CompiledGoFiles) > 0 {
 		return append([]string(nil), pkg.CompiledGoFiles...)
 	}
@@ -278,21 +388,116 @@ func rootPackageFiles(pkg *packages.Package) []string {
 	return nil
 }
 
-// hashFiles returns a combined content hash for the provided paths.
+// usesCgo reports whether pkg is processed by cgo.
+func usesCgo(pkg *packages.Package) bool {
+	return len(cgoGeneratedFiles(pkg)) > 0
+}
+
+// cgoGeneratedFiles returns the set of files pkg compiles that cgo
+// generated from its Go files, or nil if pkg does not use cgo. Packages
+// whose Go files are unknown are assumed not to.
+func cgoGeneratedFiles(pkg *packages.Package) map[string]bool {
+	if len(pkg.GoFiles) == 0 {
+		return nil
+	}
+	sources := make(map[string]bool, len(pkg.GoFiles))
+	for _, name := range pkg.GoFiles {
+		sources[filepath.Clean(name)] = true
+	}
+	var generated map[string]bool
+	for _, name := range pkg.CompiledGoFiles {
+		if name = filepath.Clean(name); !sources[name] {
+			if generated == nil {
+				generated = make(map[string]bool)
+			}
+			generated[name] = true
+		}
+	}
+	return generated
+}
+
+// hashFiles returns a combined content hash for the provided paths. Only
+// base names are hashed, so the hash of a package's files does not depend on
+// where the package is checked out.
 func hashFiles(files []string) (string, error) {
 	if len(files) == 0 {
 		return "", nil
 	}
+	digests, err := fileDigests(files)
+	if err != nil {
+		return "", err
+	}
 	h := sha256.New()
-	for _, name := range files {
+	for i, name := range files {
+		h.Write([]byte(filepath.Base(name)))
+		h.Write([]byte{0})
+		h.Write(digests[i][:])
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// hashWorkers bounds the number of files read and hashed concurrently.
+var hashWorkers = runtime.GOMAXPROCS(0)
+
+// writeFileDigests writes each file name followed by the digest of its
+// contents to h, in the order of files.
+func writeFileDigests(h hash.Hash, files []string) error {
+	digests, err := fileDigests(files)
+	if err != nil {
+		return err
+	}
+	for i, name := range files {
 		h.Write([]byte(name))
 		h.Write([]byte{0})
-		data, err := osReadFile(name)
+		h.Write(digests[i][:])
+	}
+	return nil
+}
+
+// fileDigests returns the SHA-256 digest of each file's contents. Files are
+// hashed by up to hashWorkers goroutines, but the result and the error
+// reported, that of the first unreadable file, do not depend on scheduling.
+func fileDigests(files []string) ([][sha256.Size]byte, error) {
+	digests := make([][sha256.Size]byte, len(files))
+	errs := make([]error, len(files))
+	hashOne := func(i int) {
+		data, err := osReadFile(files[i])
 		if err != nil {
-			return "", err
+			errs[i] = err
+			return
 		}
-		h.Write(data)
-		h.Write([]byte{0})
+		digests[i] = sha256.Sum256(data)
 	}
-	return fmt.Sprintf("%x", h.Sum(nil)), nil
+	workers := hashWorkers
+	if workers > len(files) {
+		workers = len(files)
+	}
+	if workers <= 1 {
+		for i := range files {
+			hashOne(i)
+		}
+	} else {
+		var next int64
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					i := int(atomic.AddInt64(&next, 1) - 1)
+					if i >= len(files) {
+						return
+					}
+					hashOne(i)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return digests, nil
 }
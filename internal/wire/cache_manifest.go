@@ -23,17 +23,24 @@ import (
 	"golang.org/x/tools/go/packages"
 )
 
-// cacheManifest stores per-run cache metadata for generated packages.
+// cacheManifest stores per-run cache metadata for generated packages. On
+// disk, paths inside the manifest root are stored relative to it, so the
+// manifest is shared by every checkout of the same module or workspace.
+// OptionsHash identifies the module, environment, and options of the run
+// apart from its working directory and patterns; manifests that share it can
+// share package entries.
 type cacheManifest struct {
-	Version    string            `json:"version"`
-	WD         string            `json:"wd"`
-	Tags       string            `json:"tags"`
-	Prefix     string            `json:"prefix"`
-	HeaderHash string            `json:"header_hash"`
-	EnvHash    string            `json:"env_hash"`
-	Patterns   []string          `json:"patterns"`
-	Packages   []manifestPackage `json:"packages"`
-	ExtraFiles []cacheFile       `json:"extra_files"`
+	Version     string            `json:"version"`
+	Root        string            `json:"root"`
+	WD          string            `json:"wd"`
+	Tags        string            `json:"tags"`
+	Prefix      string            `json:"prefix"`
+	HeaderHash  string            `json:"header_hash"`
+	EnvHash     string            `json:"env_hash"`
+	OptionsHash string            `json:"options_hash"`
+	Patterns    []string          `json:"patterns"`
+	Packages    []manifestPackage `json:"packages"`
+	ExtraFiles  []cacheFile       `json:"extra_files"`
 }
 
 // manifestPackage captures cached output for a single package.
@@ -50,26 +57,48 @@ var extraCachePathsFunc = extraCachePaths
 
 // readManifestResults loads cached generation results if still valid.
 func readManifestResults(wd string, env []string, patterns []string, opts *GenerateOptions) ([]GenerateResult, bool) {
+	root := findManifestRoot(wd)
 	key := manifestKey(wd, env, patterns, opts)
-	manifest, ok := readManifest(key)
+	manifest, ok := readManifest(key, root)
 	if !ok {
 		return nil, false
 	}
-	if !manifestValid(manifest) {
+	valid, refreshed := manifestCurrent(manifest)
+	if !valid {
 		return nil, false
 	}
+	for _, pkg := range manifest.Packages {
+		// Test injectors are generated from test files, which the
+		// manifest does not track.
+		if hasTestInjectorFiles(filepath.Dir(pkg.OutputPath)) {
+			return nil, false
+		}
+	}
 	results := make([]GenerateResult, 0, len(manifest.Packages))
 	for _, pkg := range manifest.Packages {
 		content, ok := readCache(pkg.ContentHash)
 		if !ok {
 			return nil, false
 		}
-		results = append(results, GenerateResult{
+		res := GenerateResult{
 			PkgPath:    pkg.PkgPath,
 			OutputPath: pkg.OutputPath,
 			Content:    content,
-		})
+			Cached:     true,
+			Skipped:    len(content) == 0,
+		}
+		if opts.Examples {
+			res.ExamplePath = filepath.Join(filepath.Dir(pkg.OutputPath), opts.PrefixOutputFile+examplesFileName)
+		}
+		if !readExamplesCache(pkg.ContentHash, opts, &res) {
+			return nil, false
+		}
+		results = append(results, res)
 	}
+	if refreshed {
+		writeManifestFile(key, manifest.relative(root))
+	}
+	touchManifest(key, wd, patterns)
 	return results, true
 }
 
@@ -78,16 +107,19 @@ func writeManifest(wd string, env []string, patterns []string, opts *GenerateOpt
 	if len(pkgs) == 0 {
 		return
 	}
+	root := findManifestRoot(wd)
 	key := manifestKey(wd, env, patterns, opts)
 	manifest := &cacheManifest{
 		Version:    cacheVersion,
+		Root:       root.ID,
 		WD:         wd,
 		Tags:       opts.Tags,
 		Prefix:     opts.PrefixOutputFile,
 		HeaderHash: headerHash(opts.Header),
-		EnvHash:    envHash(env),
+		EnvHash:    envHash(root.relEnv(env)),
 		Patterns:   sortedStrings(patterns),
 	}
+	manifest.OptionsHash = manifestOptionsHash(root, env, opts)
 	manifest.ExtraFiles = extraCacheFiles(wd)
 	for _, pkg := range pkgs {
 		if pkg == nil {
@@ -106,7 +138,11 @@ func writeManifest(wd string, env []string, patterns []string, opts *GenerateOpt
 		if err != nil {
 			continue
 		}
-		outputPath := filepath.Join(outDir, opts.PrefixOutputFile+"wire_gen.go")
+		name, err := outputFileName(pkg, opts)
+		if err != nil {
+			continue
+		}
+		outputPath := filepath.Join(outDir, name)
 		metaFiles, err := buildCacheFilesFunc(files)
 		if err != nil {
 			continue
@@ -130,23 +166,142 @@ func writeManifest(wd string, env []string, patterns []string, opts *GenerateOpt
 			RootHash:    rootHash,
 		})
 	}
-	writeManifestFile(key, manifest)
+	addContentHashes(root, manifest)
+	mergeSiblingManifests(key, root, manifest)
+	writeManifestFile(key, manifest.relative(root))
+	recordManifestUse(key, wd, patterns)
+}
+
+// mergeSiblingManifests copies the package entries of fresh, just written
+// under key, into the other indexed manifests with the same options hash
+// that list the same packages. A run for ./app thus keeps the manifest of
+// ./... current for the packages it regenerated, instead of leaving it to
+// fail validation and regenerate everything on the next full run. Entries
+// are only replaced, never added, since a package that fresh lists may not
+// match the other manifest's patterns. A manifest whose module files have
+// changed is left alone: its other packages may need regenerating too.
+func mergeSiblingManifests(key string, root manifestRoot, fresh *cacheManifest) {
+	if fresh.OptionsHash == "" || len(fresh.Packages) == 0 {
+		return
+	}
+	byPath := make(map[string]manifestPackage, len(fresh.Packages))
+	for _, pkg := range fresh.Packages {
+		byPath[pkg.PkgPath] = pkg
+	}
+	for _, e := range readManifestIndex().Entries {
+		if e.Key == key {
+			continue
+		}
+		other, ok := readManifest(e.Key, root)
+		if !ok || other.Version != cacheVersion || other.OptionsHash != fresh.OptionsHash {
+			continue
+		}
+		var refreshed bool
+		if !filesUnchanged(other.ExtraFiles, &refreshed) {
+			continue
+		}
+		merged := false
+		for i := range other.Packages {
+			if pkg, ok := byPath[other.Packages[i].PkgPath]; ok {
+				other.Packages[i] = pkg
+				merged = true
+			}
+		}
+		if merged || refreshed {
+			writeManifestFile(e.Key, other.relative(root))
+		}
+	}
+}
+
+// relative returns a copy of the manifest with paths inside root made
+// relative to it, for writing to disk.
+func (m *cacheManifest) relative(root manifestRoot) *cacheManifest {
+	out := *m
+	out.WD = root.rel(m.WD)
+	out.ExtraFiles = root.relFiles(m.ExtraFiles)
+	out.Packages = make([]manifestPackage, len(m.Packages))
+	for i, pkg := range m.Packages {
+		pkg.OutputPath = root.rel(pkg.OutputPath)
+		pkg.Files = root.relFiles(pkg.Files)
+		pkg.RootFiles = root.relFiles(pkg.RootFiles)
+		out.Packages[i] = pkg
+	}
+	return &out
+}
+
+// resolve converts relative paths read from disk back to absolute paths
+// under root, in place.
+func (m *cacheManifest) resolve(root manifestRoot) {
+	m.WD = root.abs(m.WD)
+	root.absFiles(m.ExtraFiles)
+	for i := range m.Packages {
+		pkg := &m.Packages[i]
+		pkg.OutputPath = root.abs(pkg.OutputPath)
+		root.absFiles(pkg.Files)
+		root.absFiles(pkg.RootFiles)
+	}
+}
+
+// addContentHashes records the content hash of every manifest file inside
+// root. A fresh checkout gives files new modification times, and the hashes
+// let manifestValid tell that their contents are unchanged. Files outside
+// the root, such as those in the module cache, are immutable and keep their
+// times. If any file cannot be read, no hashes are recorded.
+func addContentHashes(root manifestRoot, m *cacheManifest) {
+	if root.Dir == "" {
+		return
+	}
+	lists := [][]cacheFile{m.ExtraFiles}
+	for _, pkg := range m.Packages {
+		lists = append(lists, pkg.Files, pkg.RootFiles)
+	}
+	index := make(map[string]int)
+	var paths []string
+	for _, files := range lists {
+		for _, f := range files {
+			if _, ok := index[f.Path]; ok || root.rel(f.Path) == f.Path {
+				continue
+			}
+			index[f.Path] = len(paths)
+			paths = append(paths, f.Path)
+		}
+	}
+	digests, err := fileDigests(paths)
+	if err != nil {
+		return
+	}
+	for _, files := range lists {
+		for i := range files {
+			if j, ok := index[files[i].Path]; ok {
+				files[i].Hash = fmt.Sprintf("%x", digests[j])
+			}
+		}
+	}
 }
 
 // manifestKey builds the cache key for a given run configuration.
 func manifestKey(wd string, env []string, patterns []string, opts *GenerateOptions) string {
+	root := findManifestRoot(wd)
 	h := sha256.New()
 	h.Write([]byte(cacheVersion))
 	h.Write([]byte{0})
-	h.Write([]byte(filepath.Clean(wd)))
+	h.Write([]byte(root.ID))
+	h.Write([]byte{0})
+	h.Write([]byte(filepath.Clean(root.rel(filepath.Clean(wd)))))
 	h.Write([]byte{0})
-	h.Write([]byte(envHash(env)))
+	h.Write([]byte(envHash(root.relEnv(env))))
 	h.Write([]byte{0})
 	h.Write([]byte(opts.Tags))
 	h.Write([]byte{0})
 	h.Write([]byte(opts.PrefixOutputFile))
 	h.Write([]byte{0})
 	h.Write([]byte(headerHash(opts.Header)))
+	writeOptionFlags(h, opts)
+	if opts.OutputFileName != "" {
+		// The manifest records output paths, which the template names.
+		h.Write([]byte{0})
+		h.Write([]byte(opts.OutputFileName))
+	}
 	h.Write([]byte{0})
 	for _, p := range sortedStrings(patterns) {
 		h.Write([]byte(p))
@@ -155,6 +310,27 @@ func manifestKey(wd string, env []string, patterns []string, opts *GenerateOptio
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
+// manifestOptionsHash hashes what manifestKey does other than the working
+// directory and the patterns.
+func manifestOptionsHash(root manifestRoot, env []string, opts *GenerateOptions) string {
+	h := sha256.New()
+	h.Write([]byte(cacheVersion))
+	h.Write([]byte{0})
+	h.Write([]byte(root.ID))
+	h.Write([]byte{0})
+	h.Write([]byte(envHash(root.relEnv(env))))
+	h.Write([]byte{0})
+	h.Write([]byte(opts.Tags))
+	h.Write([]byte{0})
+	h.Write([]byte(opts.PrefixOutputFile))
+	h.Write([]byte{0})
+	h.Write([]byte(headerHash(opts.Header)))
+	writeOptionFlags(h, opts)
+	h.Write([]byte{0})
+	h.Write([]byte(opts.OutputFileName))
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
 // manifestKeyFromManifest rebuilds the cache key from stored metadata.
 func manifestKeyFromManifest(manifest *cacheManifest) string {
 	if manifest == nil {
@@ -163,6 +339,8 @@ func manifestKeyFromManifest(manifest *cacheManifest) string {
 	h := sha256.New()
 	h.Write([]byte(cacheVersion))
 	h.Write([]byte{0})
+	h.Write([]byte(manifest.Root))
+	h.Write([]byte{0})
 	h.Write([]byte(filepath.Clean(manifest.WD)))
 	h.Write([]byte{0})
 	h.Write([]byte(manifest.EnvHash))
@@ -180,8 +358,9 @@ func manifestKeyFromManifest(manifest *cacheManifest) string {
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
-// readManifest loads the cached manifest by key.
-func readManifest(key string) (*cacheManifest, bool) {
+// readManifest loads the cached manifest by key, resolving relative paths
+// against root.
+func readManifest(key string, root manifestRoot) (*cacheManifest, bool) {
 	data, err := osReadFile(cacheManifestPath(key))
 	if err != nil {
 		return nil, false
@@ -190,6 +369,7 @@ func readManifest(key string) (*cacheManifest, bool) {
 	if err := jsonUnmarshal(data, &manifest); err != nil {
 		return nil, false
 	}
+	manifest.resolve(root)
 	return &manifest, true
 }
 
@@ -226,57 +406,33 @@ func cacheManifestPath(key string) string {
 
 // manifestValid reports whether the manifest still matches current inputs.
 func manifestValid(manifest *cacheManifest) bool {
+	valid, _ := manifestCurrent(manifest)
+	return valid
+}
+
+// manifestCurrent reports whether the manifest still matches current inputs,
+// and whether any recorded modification times were refreshed because only
+// they had changed.
+func manifestCurrent(manifest *cacheManifest) (valid, refreshed bool) {
 	if manifest == nil || manifest.Version != cacheVersion {
-		return false
+		return false, false
 	}
 	if manifest.EnvHash == "" || len(manifest.Packages) == 0 {
-		return false
+		return false, false
 	}
-	if len(manifest.ExtraFiles) > 0 {
-		current, err := buildCacheFilesFromMetaFunc(manifest.ExtraFiles)
-		if err != nil {
-			return false
-		}
-		if len(current) != len(manifest.ExtraFiles) {
-			return false
-		}
-		for i := range manifest.ExtraFiles {
-			if manifest.ExtraFiles[i] != current[i] {
-				return false
-			}
-		}
+	if len(manifest.ExtraFiles) > 0 && !filesUnchanged(manifest.ExtraFiles, &refreshed) {
+		return false, false
 	}
 	for i := range manifest.Packages {
 		pkg := manifest.Packages[i]
 		if pkg.ContentHash == "" {
-			return false
+			return false, false
 		}
 		if len(pkg.RootFiles) == 0 || pkg.RootHash == "" {
-			return false
+			return false, false
 		}
-		current, err := buildCacheFilesFromMetaFunc(pkg.Files)
-		if err != nil {
-			return false
-		}
-		if len(current) != len(pkg.Files) {
-			return false
-		}
-		for j := range pkg.Files {
-			if pkg.Files[j] != current[j] {
-				return false
-			}
-		}
-		rootCurrent, err := buildCacheFilesFromMetaFunc(pkg.RootFiles)
-		if err != nil {
-			return false
-		}
-		if len(rootCurrent) != len(pkg.RootFiles) {
-			return false
-		}
-		for j := range pkg.RootFiles {
-			if pkg.RootFiles[j] != rootCurrent[j] {
-				return false
-			}
+		if !filesUnchanged(pkg.Files, &refreshed) || !filesUnchanged(pkg.RootFiles, &refreshed) {
+			return false, false
 		}
 		rootPaths := make([]string, 0, len(pkg.RootFiles))
 		for _, file := range pkg.RootFiles {
@@ -285,8 +441,39 @@ func manifestValid(manifest *cacheManifest) bool {
 		sort.Strings(rootPaths)
 		rootHash, err := hashFiles(rootPaths)
 		if err != nil || rootHash != pkg.RootHash {
+			return false, false
+		}
+	}
+	return true, refreshed
+}
+
+// filesUnchanged reports whether files still match their recorded metadata.
+// A file whose modification time alone differs still matches if its content
+// hash was recorded and is unchanged; its entry is then updated with the new
+// time and *refreshed is set.
+func filesUnchanged(files []cacheFile, refreshed *bool) bool {
+	current, err := buildCacheFilesFromMetaFunc(files)
+	if err != nil {
+		return false
+	}
+	if len(current) != len(files) {
+		return false
+	}
+	for i := range files {
+		cur := current[i]
+		cur.Hash = files[i].Hash
+		if files[i] == cur {
+			continue
+		}
+		if cur.Hash == "" || files[i].Path != cur.Path || files[i].Size != cur.Size {
+			return false
+		}
+		digests, err := fileDigests([]string{cur.Path})
+		if err != nil || fmt.Sprintf("%x", digests[0]) != cur.Hash {
 			return false
 		}
+		files[i].ModTime = cur.ModTime
+		*refreshed = true
 	}
 	return true
 }
@@ -0,0 +1,149 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// manifestRoot is the directory that paths stored in a manifest are relative
+// to, so that a manifest written in one checkout of a repository can be
+// reused by another checkout of the same commit in a different directory.
+type manifestRoot struct {
+	// Dir is the absolute root directory, or empty if wd is not inside a
+	// module or workspace.
+	Dir string
+	// ID identifies the root independently of where it is checked out.
+	ID string
+}
+
+// findManifestRoot returns the manifest root for wd: the nearest directory
+// with a go.work file, since that is the workspace the go command uses, or
+// else the nearest directory with a go.mod file.
+func findManifestRoot(wd string) manifestRoot {
+	wd = filepath.Clean(wd)
+	if dir, ok := findUp(wd, "go.work"); ok {
+		data, err := osReadFile(filepath.Join(dir, "go.work"))
+		if err == nil {
+			return manifestRoot{Dir: dir, ID: fmt.Sprintf("work %x", sha256.Sum256(data))}
+		}
+	}
+	if dir, ok := findUp(wd, "go.mod"); ok {
+		data, err := osReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			if path := modulePath(data); path != "" {
+				return manifestRoot{Dir: dir, ID: "module " + path}
+			}
+		}
+	}
+	return manifestRoot{}
+}
+
+// findUp returns the nearest directory at or above dir containing name.
+func findUp(dir, name string) (string, bool) {
+	for {
+		if _, err := osStat(filepath.Join(dir, name)); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// modulePath returns the module path declared by the contents of a go.mod
+// file, or the empty string if there is none.
+func modulePath(data []byte) string {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if !strings.HasPrefix(line, "module") {
+			continue
+		}
+		path := strings.TrimSpace(strings.TrimPrefix(line, "module"))
+		if i := strings.Index(path, "//"); i >= 0 {
+			path = strings.TrimSpace(path[:i])
+		}
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			path = unquoted
+		}
+		return path
+	}
+	return ""
+}
+
+// rel returns path relative to the root in slash-separated form. Paths
+// outside the root, such as those in the module cache, are returned
+// unchanged.
+func (r manifestRoot) rel(path string) string {
+	if r.Dir == "" || path == "" {
+		return path
+	}
+	rel, err := filepath.Rel(r.Dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// abs reverses rel, resolving a relative path against the root.
+func (r manifestRoot) abs(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(r.Dir, filepath.FromSlash(path))
+}
+
+// relEnv returns env with occurrences of the root directory replaced by a
+// placeholder, so that variables such as GOFLAGS=-modfile=<root>/go.dev.mod
+// hash the same in every checkout.
+func (r manifestRoot) relEnv(env []string) []string {
+	if r.Dir == "" || len(env) == 0 {
+		return env
+	}
+	out := make([]string, len(env))
+	for i, kv := range env {
+		out[i] = strings.ReplaceAll(kv, r.Dir, "$WIRE_ROOT")
+	}
+	return out
+}
+
+// relFiles returns a copy of files with paths made relative to the root.
+func (r manifestRoot) relFiles(files []cacheFile) []cacheFile {
+	if files == nil {
+		return nil
+	}
+	out := make([]cacheFile, len(files))
+	for i, f := range files {
+		f.Path = r.rel(f.Path)
+		out[i] = f
+	}
+	return out
+}
+
+// absFiles resolves the paths of files against the root in place.
+func (r manifestRoot) absFiles(files []cacheFile) {
+	for i := range files {
+		files[i].Path = r.abs(files[i].Path)
+	}
+}
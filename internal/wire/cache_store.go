@@ -15,11 +15,18 @@
 package wire
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"io/fs"
 	"path/filepath"
 )
 
+// cacheBlobMagic starts every cache blob. It is followed by the hex SHA-256
+// of the content and a newline, then the content itself.
+const cacheBlobMagic = "wire-cache-v1 "
+
 // cacheDir returns the base directory for Wire cache files.
 func cacheDir() string {
 	return filepath.Join(osTempDir(), "wire-cache")
@@ -40,13 +47,74 @@ func cachePath(key string) string {
 	return filepath.Join(cacheDir(), key+".bin")
 }
 
-// readCache reads a cached content blob by key.
+// readCache reads a cached content blob by key. A blob whose checksum does
+// not match its content, such as one truncated by a crashed process, is
+// removed and reported as a miss, so that the content is regenerated.
 func readCache(key string) ([]byte, bool) {
-	data, err := osReadFile(cachePath(key))
+	path := cachePath(key)
+	data, err := osReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	content, ok := decodeCacheBlob(data)
+	if !ok {
+		osRemove(path)
+		return nil, false
+	}
+	return content, true
+}
+
+// cacheHit is the output of a package read from the cache.
+type cacheHit struct {
+	content        []byte
+	exampleContent []byte
+}
+
+// readCacheHit returns the output cached under key for a package generated
+// with opts, or nil if the cache misses.
+func readCacheHit(key string, opts *GenerateOptions) *cacheHit {
+	content, ok := readCache(key)
+	if !ok {
+		return nil
+	}
+	var res GenerateResult
+	if !readExamplesCache(key, opts, &res) {
+		return nil
+	}
+	return &cacheHit{content: content, exampleContent: res.ExampleContent}
+}
+
+// encodeCacheBlob prefixes content with its checksum.
+func encodeCacheBlob(content []byte) []byte {
+	sum := sha256.Sum256(content)
+	blob := make([]byte, 0, len(cacheBlobMagic)+hex.EncodedLen(len(sum))+1+len(content))
+	blob = append(blob, cacheBlobMagic...)
+	blob = append(blob, hex.EncodeToString(sum[:])...)
+	blob = append(blob, '\n')
+	return append(blob, content...)
+}
+
+// decodeCacheBlob returns the content of blob, reporting false if it is not
+// a blob written by encodeCacheBlob or its content does not match the
+// checksum.
+func decodeCacheBlob(blob []byte) ([]byte, bool) {
+	if !bytes.HasPrefix(blob, []byte(cacheBlobMagic)) {
+		return nil, false
+	}
+	rest := blob[len(cacheBlobMagic):]
+	end := hex.EncodedLen(sha256.Size)
+	if len(rest) <= end || rest[end] != '\n' {
+		return nil, false
+	}
+	want, err := hex.DecodeString(string(rest[:end]))
 	if err != nil {
 		return nil, false
 	}
-	return data, true
+	content := rest[end+1:]
+	if sum := sha256.Sum256(content); !bytes.Equal(sum[:], want) {
+		return nil, false
+	}
+	return content, true
 }
 
 // writeCache persists a content blob for the provided cache key.
@@ -60,7 +128,7 @@ func writeCache(key string, content []byte) {
 	if err != nil {
 		return
 	}
-	_, writeErr := tmp.Write(content)
+	_, writeErr := tmp.Write(encodeCacheBlob(content))
 	closeErr := tmp.Close()
 	if writeErr != nil || closeErr != nil {
 		osRemove(tmp.Name())
@@ -74,3 +142,26 @@ func writeCache(key string, content []byte) {
 		osRemove(tmp.Name())
 	}
 }
+
+// examplesCacheKey returns the cache key for the examples generated
+// alongside the content cached under key.
+func examplesCacheKey(key string) string {
+	return key + "-examples"
+}
+
+// readExamplesCache fills in res's examples from the cache entry stored
+// alongside key when opts requests examples. It reports false if the
+// examples are needed but not cached.
+func readExamplesCache(key string, opts *GenerateOptions, res *GenerateResult) bool {
+	if !opts.Examples {
+		return true
+	}
+	content, ok := readCache(examplesCacheKey(key))
+	if !ok {
+		return false
+	}
+	if len(content) > 0 {
+		res.ExampleContent = content
+	}
+	return true
+}
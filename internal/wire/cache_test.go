@@ -16,7 +16,9 @@ package wire
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -84,9 +86,9 @@ func TestCacheInvalidation(t *testing.T) {
 		t.Fatalf("first Generate returned unexpected result: %+v", first)
 	}
 
-	pkgs, _, errs := load(ctx, root, env, opts.Tags, []string{"./app"})
-	if len(errs) > 0 || len(pkgs) != 1 {
-		t.Fatalf("load failed: %v", errs)
+	pkgs, _, loadErrs := load(ctx, root, env, opts.Tags, []string{"./app"})
+	if len(loadErrs) > 0 || len(pkgs) != 1 {
+		t.Fatalf("load failed: %v", loadErrs)
 	}
 	key, err := cacheKeyForPackage(pkgs[0], opts)
 	if err != nil {
@@ -112,9 +114,9 @@ func TestCacheInvalidation(t *testing.T) {
 	if len(second) != 1 || len(second[0].Content) == 0 {
 		t.Fatalf("second Generate returned unexpected result: %+v", second)
 	}
-	pkgs, _, errs = load(ctx, root, env, opts.Tags, []string{"./app"})
-	if len(errs) > 0 || len(pkgs) != 1 {
-		t.Fatalf("reload failed: %v", errs)
+	pkgs, _, loadErrs = load(ctx, root, env, opts.Tags, []string{"./app"})
+	if len(loadErrs) > 0 || len(pkgs) != 1 {
+		t.Fatalf("reload failed: %v", loadErrs)
 	}
 	key2, err := cacheKeyForPackage(pkgs[0], opts)
 	if err != nil {
@@ -126,6 +128,117 @@ func TestCacheInvalidation(t *testing.T) {
 	if cached, ok := readCache(key2); !ok || len(cached) == 0 {
 		t.Fatal("expected cache entry after second Generate")
 	}
+	if second[0].Cached {
+		t.Error("second Generate result is Cached; want generated")
+	}
+
+	var reported []GenerateResult
+	third, errs := Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{
+		OnResult: func(res GenerateResult) { reported = append(reported, res) },
+	})
+	if len(errs) > 0 {
+		t.Fatalf("third Generate errors: %v", errs)
+	}
+	if len(third) != 1 || !third[0].Cached {
+		t.Fatalf("third Generate returned %+v; want one cached result", third)
+	}
+	if len(reported) != 1 || reported[0].PkgPath != third[0].PkgPath || !reported[0].Cached {
+		t.Errorf("OnResult reported %+v; want the cached result for %s", reported, third[0].PkgPath)
+	}
+}
+
+func TestCgoPackages(t *testing.T) {
+	if out, err := exec.Command("go", "env", "CGO_ENABLED").Output(); err != nil || strings.TrimSpace(string(out)) != "1" {
+		t.Skip("cgo is not enabled")
+	}
+	if _, err := exec.LookPath("gcc"); err != nil {
+		t.Skip("no C compiler available")
+	}
+	root := t.TempDir()
+
+	prevTmp := os.Getenv("TMPDIR")
+	if err := os.Setenv("TMPDIR", t.TempDir()); err != nil {
+		t.Fatalf("Setenv TMPDIR failed: %v", err)
+	}
+	t.Cleanup(func() {
+		os.Setenv("TMPDIR", prevTmp)
+	})
+
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "dep", "dep.go"), strings.Join([]string{
+		"package dep",
+		"",
+		"// static int answer(void) { return 42; }",
+		"import \"C\"",
+		"",
+		"type Answer int",
+		"",
+		"func NewAnswer() Answer { return Answer(C.answer()) }",
+		"",
+	}, "\n"))
+	providerPath := filepath.Join(root, "app", "app.go")
+	provider := func(results, ret string) string {
+		return strings.Join([]string{
+			"package app",
+			"",
+			"// static int twice(int x) { return 2 * x; }",
+			"import \"C\"",
+			"",
+			"import \"example.com/app/dep\"",
+			"",
+			"type Doubled int",
+			"",
+			"func NewDoubled(a dep.Answer) " + results + " { return " + ret + " }",
+			"",
+		}, "\n")
+	}
+	writeFile(t, providerPath, provider("Doubled", "Doubled(C.twice(C.int(a)))"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package app",
+		"",
+		"import (",
+		"\t\"example.com/app/dep\"",
+		"\t\"github.com/goforj/wire\"",
+		")",
+		"",
+		"func Init() Doubled {",
+		"\tpanic(wire.Build(dep.NewAnswer, NewDoubled))",
+		"}",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	first, errs := Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("first Generate errors: %v", errs)
+	}
+	if len(first) != 1 || len(first[0].Errs) > 0 || len(first[0].Content) == 0 {
+		t.Fatalf("first Generate returned unexpected result: %+v", first)
+	}
+	if got := string(first[0].Content); !strings.Contains(got, "doubled := NewDoubled(answer)") || strings.Contains(got, "unsafe") {
+		t.Errorf("first Generate output:\n%s\nwant a call to NewDoubled and no import added by cgo", got)
+	}
+
+	// The provider now returns an error, which the injector does not. The
+	// change must not be hidden by the cache, even though the files cgo
+	// generates live outside the package.
+	writeFile(t, providerPath, provider("(Doubled, error)", "Doubled(C.twice(C.int(a))), nil"))
+	second, errs := Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("second Generate errors: %v", errs)
+	}
+	if len(second) != 1 {
+		t.Fatalf("second Generate returned %d results; want 1", len(second))
+	}
+	if len(second[0].Errs) == 0 {
+		t.Fatalf("second Generate succeeded (cached: %t); want an error for the provider's new signature", second[0].Cached)
+	}
+	if got := second[0].Errs.Errors()[0].Error(); !strings.Contains(got, "returns error but injection not allowed to fail") {
+		t.Errorf("second Generate error = %q; want provider returns error", got)
+	}
 }
 
 func TestManifestInvalidation(t *testing.T) {
@@ -187,7 +300,7 @@ func TestManifestInvalidation(t *testing.T) {
 	}
 
 	key := manifestKey(root, env, []string{"./app"}, opts)
-	manifest, ok := readManifest(key)
+	manifest, ok := readManifest(key, findManifestRoot(root))
 	if !ok {
 		t.Fatal("expected manifest after Generate")
 	}
@@ -209,6 +322,76 @@ func TestManifestInvalidation(t *testing.T) {
 	}
 }
 
+func TestManifestMergedFromPartialRun(t *testing.T) {
+	root := t.TempDir()
+
+	prevTmp := os.Getenv("TMPDIR")
+	if err := os.Setenv("TMPDIR", t.TempDir()); err != nil {
+		t.Fatalf("Setenv TMPDIR failed: %v", err)
+	}
+	t.Cleanup(func() {
+		os.Setenv("TMPDIR", prevTmp)
+	})
+
+	writeAppModule(t, root)
+	wireGo := func(pkg, message string) string {
+		return strings.Join([]string{
+			"//go:build wireinject",
+			"",
+			"package " + pkg,
+			"",
+			"import \"github.com/goforj/wire\"",
+			"",
+			"func provideMessage() string { return \"" + message + "\" }",
+			"",
+			"func Init() string {",
+			"\twire.Build(provideMessage)",
+			"\treturn \"\"",
+			"}",
+			"",
+		}, "\n")
+	}
+	writeFile(t, filepath.Join(root, "app", "wire.go"), wireGo("app", "hello"))
+	writeFile(t, filepath.Join(root, "other", "wire.go"), wireGo("other", "hello"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	opts := &GenerateOptions{}
+	manifestRoot := findManifestRoot(root)
+	if _, errs := Generate(ctx, root, env, []string{"./..."}, opts); len(errs) > 0 {
+		t.Fatalf("Generate ./... errors: %v", errs)
+	}
+	fullKey := manifestKey(root, env, []string{"./..."}, opts)
+	if full, ok := readManifest(fullKey, manifestRoot); !ok || !manifestValid(full) {
+		t.Fatal("expected a valid manifest for ./... after Generate")
+	}
+
+	writeFile(t, filepath.Join(root, "app", "wire.go"), wireGo("app", "goodbye"))
+	if _, errs := Generate(ctx, root, env, []string{"./app"}, opts); len(errs) > 0 {
+		t.Fatalf("Generate ./app errors: %v", errs)
+	}
+	full, ok := readManifest(fullKey, manifestRoot)
+	if !ok || !manifestValid(full) {
+		t.Fatal("expected the manifest for ./... to stay valid after Generate ./app")
+	}
+	if len(full.Packages) != 2 {
+		t.Fatalf("manifest for ./... lists %d packages; want 2", len(full.Packages))
+	}
+	results, ok := readManifestResults(root, env, []string{"./..."}, opts)
+	if !ok {
+		t.Fatal("expected ./... to be served from its manifest")
+	}
+	for _, res := range results {
+		want := "hello"
+		if res.PkgPath == "example.com/app/app" {
+			want = "goodbye"
+		}
+		if !strings.Contains(string(res.Content), want) {
+			t.Errorf("cached output for %s lacks %q:\n%s", res.PkgPath, want, res.Content)
+		}
+	}
+}
+
 func TestManifestInvalidationGoMod(t *testing.T) {
 	repoRoot := mustRepoRoot(t)
 	root := t.TempDir()
@@ -268,7 +451,7 @@ func TestManifestInvalidationGoMod(t *testing.T) {
 	}
 
 	key := manifestKey(root, env, []string{"./app"}, opts)
-	manifest, ok := readManifest(key)
+	manifest, ok := readManifest(key, findManifestRoot(root))
 	if !ok {
 		t.Fatal("expected manifest after Generate")
 	}
@@ -350,7 +533,7 @@ func TestManifestInvalidationSameTimestamp(t *testing.T) {
 	}
 
 	key := manifestKey(root, env, []string{"./app"}, opts)
-	manifest, ok := readManifest(key)
+	manifest, ok := readManifest(key, findManifestRoot(root))
 	if !ok {
 		t.Fatal("expected manifest after Generate")
 	}
@@ -383,3 +566,157 @@ func TestManifestInvalidationSameTimestamp(t *testing.T) {
 		t.Fatal("expected manifest to be invalid after same-timestamp content update")
 	}
 }
+
+func TestHashFilesConcurrency(t *testing.T) {
+	dir := t.TempDir()
+	files := writeHashFiles(t, dir, 64, 128)
+	prev := hashWorkers
+	t.Cleanup(func() { hashWorkers = prev })
+
+	hashWorkers = 1
+	want, err := hashFiles(files)
+	if err != nil {
+		t.Fatalf("hashFiles error: %v", err)
+	}
+	hashWorkers = 8
+	got, err := hashFiles(files)
+	if err != nil {
+		t.Fatalf("hashFiles error: %v", err)
+	}
+	if got != want {
+		t.Errorf("hashFiles with 8 workers = %s; want %s as with 1 worker", got, want)
+	}
+
+	// The error is that of the first missing file, however the reads are
+	// scheduled.
+	missing := append([]string(nil), files...)
+	missing[10] = filepath.Join(dir, "missing1.go")
+	missing[50] = filepath.Join(dir, "missing2.go")
+	for i := 0; i < 10; i++ {
+		_, err := hashFiles(missing)
+		if err == nil || !strings.Contains(err.Error(), "missing1.go") {
+			t.Fatalf("hashFiles error = %v; want error for missing1.go", err)
+		}
+	}
+}
+
+func BenchmarkHashFiles(b *testing.B) {
+	files := writeHashFiles(b, b.TempDir(), 2000, 16<<10)
+	prev := hashWorkers
+	b.Cleanup(func() { hashWorkers = prev })
+	for _, workers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			hashWorkers = workers
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := hashFiles(files); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// writeHashFiles writes n files of the given size to dir and returns their
+// paths.
+func writeHashFiles(tb testing.TB, dir string, n, size int) []string {
+	tb.Helper()
+	files := make([]string, n)
+	data := make([]byte, size)
+	for i := range files {
+		for j := range data {
+			data[j] = byte(i + j)
+		}
+		files[i] = filepath.Join(dir, fmt.Sprintf("f%05d.go", i))
+		if err := os.WriteFile(files[i], data, 0o644); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return files
+}
+
+func TestManifestSharedAcrossCheckouts(t *testing.T) {
+	prevTmp := os.Getenv("TMPDIR")
+	if err := os.Setenv("TMPDIR", t.TempDir()); err != nil {
+		t.Fatalf("Setenv TMPDIR failed: %v", err)
+	}
+	t.Cleanup(func() {
+		os.Setenv("TMPDIR", prevTmp)
+	})
+
+	checkout := func(root string) {
+		writeAppModule(t, root)
+		writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+			"//go:build wireinject",
+			"// +build wireinject",
+			"",
+			"package app",
+			"",
+			"import (",
+			"\t\"example.com/app/dep\"",
+			"\t\"github.com/goforj/wire\"",
+			")",
+			"",
+			"func Init() string {",
+			"\twire.Build(dep.ProvideMessage)",
+			"\treturn \"\"",
+			"}",
+			"",
+		}, "\n"))
+		writeFile(t, filepath.Join(root, "dep", "dep.go"), strings.Join([]string{
+			"package dep",
+			"",
+			"func ProvideMessage() string {",
+			"\treturn \"hello\"",
+			"}",
+			"",
+		}, "\n"))
+	}
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	first := filepath.Join(t.TempDir(), "first")
+	second := filepath.Join(t.TempDir(), "second")
+	checkout(first)
+
+	results, errs := Generate(ctx, first, env, []string{"./app"}, &GenerateOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("Generate in first checkout: %v", errs)
+	}
+	if len(results) != 1 || results[0].Cached {
+		t.Fatalf("Generate in first checkout returned %+v; want one generated result", results)
+	}
+	want := results[0].Content
+
+	// A second checkout of the same sources has new paths and times.
+	checkout(second)
+	results, errs = Generate(ctx, second, env, []string{"./app"}, &GenerateOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("Generate in second checkout: %v", errs)
+	}
+	if len(results) != 1 || !results[0].Cached {
+		t.Fatalf("Generate in second checkout returned %+v; want one cached result", results)
+	}
+	if got, want := results[0].OutputPath, filepath.Join(second, "app", "wire_gen.go"); got != want {
+		t.Errorf("OutputPath = %q; want %q", got, want)
+	}
+	if string(results[0].Content) != string(want) {
+		t.Errorf("cached content differs from the first checkout:\n%s", results[0].Content)
+	}
+
+	writeFile(t, filepath.Join(second, "dep", "dep.go"), strings.Join([]string{
+		"package dep",
+		"",
+		"func ProvideMessage() string {",
+		"\treturn \"goodbye!\"",
+		"}",
+		"",
+	}, "\n"))
+	results, errs = Generate(ctx, second, env, []string{"./app"}, &GenerateOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("Generate after edit: %v", errs)
+	}
+	if len(results) != 1 || results[0].Cached {
+		t.Fatalf("Generate after edit returned %+v; want one generated result", results)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+)
+
+// InjectorCalls lists the places where an injector is called.
+type InjectorCalls struct {
+	Injector *Injector
+	// Calls holds the positions of the calls to the injector, sorted by
+	// file and offset.
+	Calls []token.Position
+}
+
+// InjectorCallers finds the calls to each of injectors in the packages that
+// match scope, such as the "./..." of the enclosing module, loaded in wd
+// with tags and their tests. The packages are loaded without the wireinject
+// tag, as they are built, so the calls are those that reach the generated
+// injectors. Results are in the order of injectors.
+func InjectorCallers(ctx context.Context, wd string, env []string, tags string, injectors []*Injector, scope []string) ([]*InjectorCalls, []error) {
+	calls := make([]*InjectorCalls, len(injectors))
+	byName := make(map[string]*InjectorCalls, len(injectors))
+	for i, in := range injectors {
+		calls[i] = &InjectorCalls{Injector: in}
+		byName[in.ImportPath+"."+in.FuncName] = calls[i]
+	}
+	if len(injectors) == 0 {
+		return calls, nil
+	}
+	cfg := &packages.Config{
+		Context: ctx,
+		Mode:    packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps,
+		Dir:     wd,
+		Env:     env,
+		Tests:   true,
+		Overlay: loadOverlay(ctx),
+	}
+	if len(tags) > 0 {
+		cfg.BuildFlags = []string{"-tags=" + tags}
+	}
+	escaped := make([]string, len(scope))
+	for i := range scope {
+		escaped[i] = "pattern=" + scope[i]
+	}
+	debugf(ctx, "loading %s to find the callers of %d injectors", strings.Join(scope, " "), len(injectors))
+	loadStart := time.Now()
+	pkgs, err := packages.Load(cfg, escaped...)
+	logTiming(ctx, "callers.load", loadStart)
+	if err != nil {
+		return nil, []error{err}
+	}
+	if errs := collectLoadErrors(pkgs); len(errs) > 0 {
+		return nil, errs
+	}
+	// A package's files are loaded again in its test variant.
+	seen := make(map[token.Position]bool)
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Syntax {
+			ast.Inspect(f, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				var id *ast.Ident
+				switch fun := astutil.Unparen(call.Fun).(type) {
+				case *ast.Ident:
+					id = fun
+				case *ast.SelectorExpr:
+					id = fun.Sel
+				default:
+					return true
+				}
+				fn, ok := pkg.TypesInfo.Uses[id].(*types.Func)
+				if !ok || fn.Pkg() == nil {
+					return true
+				}
+				ic := byName[fn.Pkg().Path()+"."+fn.Name()]
+				if ic == nil || fn.Type().(*types.Signature).Recv() != nil {
+					return true
+				}
+				pos := pkg.Fset.Position(call.Pos())
+				if !seen[pos] {
+					seen[pos] = true
+					ic.Calls = append(ic.Calls, pos)
+				}
+				return true
+			})
+		}
+	}
+	for _, ic := range calls {
+		sort.Slice(ic.Calls, func(i, j int) bool { return positionLess(ic.Calls[i], ic.Calls[j]) })
+	}
+	return calls, nil
+}
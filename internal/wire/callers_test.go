@@ -0,0 +1,111 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInjectorCallers(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "db.go"), strings.Join([]string{
+		"package app",
+		"",
+		"type DB struct{}",
+		"",
+		"func NewDB() *DB { return &DB{} }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func InitDB() *DB {",
+		"\tpanic(wire.Build(NewDB))",
+		"}",
+		"",
+		"func InitUnused() *DB {",
+		"\tpanic(wire.Build(NewDB))",
+		"}",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire_gen.go"), strings.Join([]string{
+		generatedMarker,
+		"",
+		"//go:build !wireinject",
+		"",
+		"package app",
+		"",
+		"func InitDB() *DB { return NewDB() }",
+		"",
+		"func InitUnused() *DB { return NewDB() }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "cmd", "main.go"), strings.Join([]string{
+		"package main",
+		"",
+		"import \"example.com/app/app\"",
+		"",
+		"func main() {",
+		"\t_ = app.InitDB()",
+		"\t_ = (app.InitDB)()",
+		"}",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "db_test.go"), strings.Join([]string{
+		"package app",
+		"",
+		"import \"testing\"",
+		"",
+		"func TestDB(t *testing.T) {",
+		"\t_ = InitDB()",
+		"}",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	info, errs := Load(ctx, root, env, "", []string{"./app"})
+	if len(errs) > 0 || len(info.Injectors) != 2 {
+		t.Fatalf("Load = %v, %v; want two injectors", info, errs)
+	}
+	calls, errs := InjectorCallers(ctx, root, env, "", info.Injectors, []string{"./..."})
+	if len(errs) > 0 || len(calls) != 2 {
+		t.Fatalf("InjectorCallers = %v, %v; want calls of two injectors", calls, errs)
+	}
+	got := make(map[string][]string)
+	for _, ic := range calls {
+		for _, pos := range ic.Calls {
+			rel, _ := filepath.Rel(root, pos.Filename)
+			got[ic.Injector.FuncName] = append(got[ic.Injector.FuncName], fmt.Sprintf("%s:%d", filepath.ToSlash(rel), pos.Line))
+		}
+	}
+	want := map[string][]string{
+		"InitDB": {"app/db_test.go:6", "cmd/main.go:6", "cmd/main.go:7"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("InjectorCallers calls = %v; want %v", got, want)
+	}
+}
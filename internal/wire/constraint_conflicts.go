@@ -0,0 +1,233 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// An injectorConflict is an injector declared both in a wireinject file of
+// a package and in one that the build constraints exclude, such as initApp
+// in wire_dev.go for "wireinject && dev" and in wire_prod.go for
+// "wireinject && !dev". Each configuration generates its own version of the
+// injector.
+type injectorConflict struct {
+	name string
+	// pos is the position of the included declaration.
+	pos token.Position
+	// expr is the constraint of the included file, and otherExpr that of
+	// the excluded file declaring the same injector.
+	expr, otherExpr constraint.Expr
+	file, otherFile string
+}
+
+// injectorConflicts returns the injectors of pkg that excluded wireinject
+// files declare again. Test files are not considered.
+func injectorConflicts(pkg *packages.Package) []injectorConflict {
+	fset := token.NewFileSet()
+	type decl struct {
+		pos  token.Position
+		expr constraint.Expr
+		file string
+	}
+	included := make(map[string]decl)
+	for _, path := range pkg.GoFiles {
+		f := parseWireinjectFile(fset, path)
+		if f == nil {
+			continue
+		}
+		expr := buildConstraint(f.Comments, f.Package)
+		for _, fn := range syntaxInjectors(f) {
+			included[fn.Name.Name] = decl{pos: fset.Position(fn.Pos()), expr: expr, file: filepath.Base(path)}
+		}
+	}
+	if len(included) == 0 {
+		return nil
+	}
+	var conflicts []injectorConflict
+	for _, path := range pkg.IgnoredFiles {
+		f := parseWireinjectFile(fset, path)
+		if f == nil {
+			continue
+		}
+		otherExpr := buildConstraint(f.Comments, f.Package)
+		for _, fn := range syntaxInjectors(f) {
+			d, ok := included[fn.Name.Name]
+			if !ok {
+				continue
+			}
+			conflicts = append(conflicts, injectorConflict{
+				name:      fn.Name.Name,
+				pos:       d.pos,
+				expr:      d.expr,
+				file:      d.file,
+				otherExpr: otherExpr,
+				otherFile: filepath.Base(path),
+			})
+		}
+	}
+	return conflicts
+}
+
+// parseWireinjectFile parses the Go file at path if it is a wireinject file
+// other than a test, or returns nil.
+func parseWireinjectFile(fset *token.FileSet, path string) *ast.File {
+	if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+		return nil
+	}
+	f, err := parser.ParseFile(fset, path, nil, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil || !isWireinjectFile(f) {
+		return nil
+	}
+	return f
+}
+
+// syntaxInjectors returns the functions of f that are injectors by their
+// syntax.
+func syntaxInjectors(f *ast.File) []*ast.FuncDecl {
+	names, dot := wireImportNames(f)
+	if len(names) == 0 && !dot {
+		return nil
+	}
+	var fns []*ast.FuncDecl
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil || fn.Recv != nil {
+			continue
+		}
+		if call, _, err := findInjectorBuildSyntax(fn, names, dot); call != nil || err != nil {
+			fns = append(fns, fn)
+		}
+	}
+	return fns
+}
+
+// conflictConstraint returns the constraint the generated file of pkg needs
+// besides !wireinject when injectors conflict across configurations: the
+// conjunction of the constraints of its wireinject files, with wireinject
+// taken as set. It returns nil if that is always true.
+func conflictConstraint(pkg *packages.Package) constraint.Expr {
+	fset := token.NewFileSet()
+	var out constraint.Expr
+	for _, path := range pkg.GoFiles {
+		f := parseWireinjectFile(fset, path)
+		if f == nil {
+			continue
+		}
+		expr, value := assumeTag(buildConstraint(f.Comments, f.Package), "wireinject")
+		if expr == nil {
+			if !value {
+				// The file cannot be included; it is not loaded.
+				return nil
+			}
+			continue
+		}
+		if out == nil {
+			out = expr
+		} else if out.String() != expr.String() {
+			out = &constraint.AndExpr{X: out, Y: expr}
+		}
+	}
+	return out
+}
+
+// assumeTag simplifies x with tag set. If the result does not depend on any
+// other tag, it returns nil and the result's value.
+func assumeTag(x constraint.Expr, tag string) (constraint.Expr, bool) {
+	switch x := x.(type) {
+	case nil:
+		return nil, true
+	case *constraint.TagExpr:
+		if x.Tag == tag {
+			return nil, true
+		}
+		return x, false
+	case *constraint.NotExpr:
+		y, value := assumeTag(x.X, tag)
+		if y == nil {
+			return nil, !value
+		}
+		return &constraint.NotExpr{X: y}, false
+	case *constraint.AndExpr:
+		a, av := assumeTag(x.X, tag)
+		b, bv := assumeTag(x.Y, tag)
+		switch {
+		case a == nil && !av, b == nil && !bv:
+			return nil, false
+		case a == nil:
+			return b, bv
+		case b == nil:
+			return a, av
+		}
+		return &constraint.AndExpr{X: a, Y: b}, false
+	case *constraint.OrExpr:
+		a, av := assumeTag(x.X, tag)
+		b, bv := assumeTag(x.Y, tag)
+		switch {
+		case a == nil && av, b == nil && bv:
+			return nil, true
+		case a == nil:
+			return b, bv
+		case b == nil:
+			return a, av
+		}
+		return &constraint.OrExpr{X: a, Y: b}, false
+	}
+	return x, false
+}
+
+// conflictWarnings describes conflicts as warnings for the package pkgPath.
+// extra is the constraint the generated file carries because of them.
+func conflictWarnings(pkgPath string, conflicts []injectorConflict, extra constraint.Expr) ErrorList {
+	var warnings ErrorList
+	for _, c := range conflicts {
+		msg := fmt.Sprintf("inject %s: also declared in %s with the constraint %q, which this configuration excludes in favor of %s with %q",
+			c.name, c.otherFile, constraintString(c.otherExpr), c.file, constraintString(c.expr))
+		if extra != nil {
+			msg += fmt.Sprintf("; the generated file requires %q so that it builds only with this configuration", extra.String())
+		}
+		msg += "; generate each configuration to its own file with -output_file_prefix so that neither overwrites the other"
+		warnings = append(warnings, newWarning(pkgPath, CodeConstraintConflict, notePosition(c.pos, fmt.Errorf("%s", msg))))
+	}
+	return warnings
+}
+
+// constraintString returns expr as in a //go:build line, or "" if it is nil.
+func constraintString(expr constraint.Expr) string {
+	if expr == nil {
+		return ""
+	}
+	return expr.String()
+}
+
+// plusBuildLines returns the // +build lines for expr, or nil if it is nil.
+func plusBuildLines(expr constraint.Expr) []string {
+	if expr == nil {
+		return nil
+	}
+	lines, err := constraint.PlusBuildLines(expr)
+	if err != nil {
+		return nil
+	}
+	return lines
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInjectorConstraintConflicts(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "db.go"), strings.Join([]string{
+		"package app",
+		"",
+		"type DB struct{ Name string }",
+		"",
+		"func NewDevDB() *DB { return &DB{Name: \"dev\"} }",
+		"",
+		"func NewProdDB() *DB { return &DB{Name: \"prod\"} }",
+		"",
+	}, "\n"))
+	wireGo := func(constraint, provider string) string {
+		return strings.Join([]string{
+			"//go:build " + constraint,
+			"",
+			"package app",
+			"",
+			"import \"github.com/goforj/wire\"",
+			"",
+			"func InitDB() *DB {",
+			"\tpanic(wire.Build(" + provider + "))",
+			"}",
+			"",
+		}, "\n")
+	}
+	writeFile(t, filepath.Join(root, "app", "wire_dev.go"), wireGo("wireinject && dev", "NewDevDB"))
+	writeFile(t, filepath.Join(root, "app", "wire_prod.go"), wireGo("wireinject && !dev", "NewProdDB"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	tests := []struct {
+		tags       string
+		provider   string
+		constraint string
+		other      string
+	}{
+		{tags: "", provider: "NewProdDB", constraint: "//go:build !wireinject && !dev", other: "wire_dev.go"},
+		{tags: "dev", provider: "NewDevDB", constraint: "//go:build !wireinject && dev", other: "wire_prod.go"},
+	}
+	for _, test := range tests {
+		gens, errs := Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{Tags: test.tags, PrefixOutputFile: test.tags})
+		if len(errs) > 0 {
+			t.Fatalf("Generate with tags %q failed: %v", test.tags, errs)
+		}
+		if len(gens) != 1 || len(gens[0].Errs) > 0 {
+			t.Fatalf("Generate with tags %q returned %+v; want one result without errors", test.tags, gens)
+		}
+		content := string(gens[0].Content)
+		if !strings.Contains(content, test.provider+"()") {
+			t.Errorf("Generate with tags %q did not call %s:\n%s", test.tags, test.provider, content)
+		}
+		if !strings.Contains(content, test.constraint+"\n") {
+			t.Errorf("Generate with tags %q: output lacks %q:\n%s", test.tags, test.constraint, content)
+		}
+		if w := gens[0].Warnings; len(w) != 1 || w[0].Code != CodeConstraintConflict || !strings.Contains(w[0].Error(), test.other) {
+			t.Errorf("Generate with tags %q warnings = %v; want one %s warning naming %s", test.tags, w, CodeConstraintConflict, test.other)
+		}
+	}
+
+	info, loadErrs := Load(ctx, root, env, "", []string{"./app"})
+	if len(loadErrs) > 0 {
+		t.Fatalf("Load failed: %v", loadErrs)
+	}
+	if w := info.Warnings; len(w) != 1 || w[0].Code != CodeConstraintConflict {
+		t.Errorf("Load warnings = %v; want one %s warning", w, CodeConstraintConflict)
+	}
+}
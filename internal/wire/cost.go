@@ -0,0 +1,207 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+	"time"
+)
+
+// costDirective declares the approximate time a provider takes to run, for
+// the critical path reported by wire analyze. It is placed in the
+// provider's doc comment:
+//
+//	//wire:cost 50ms
+//	func NewDB(cfg Config) (*sql.DB, error)
+const costDirective = "//wire:cost"
+
+// An InjectorCost describes the construction cost of an injector's result,
+// as declared by the //wire:cost directives of its providers.
+type InjectorCost struct {
+	Injector *Injector
+
+	// Steps holds the declared cost of each of Injector.Steps. Steps
+	// without a declared cost, including values and fields, cost zero.
+	Steps []time.Duration
+
+	// Total is the sum of the costs of all steps, which is how long the
+	// generated code takes since it runs the steps one after another.
+	Total time.Duration
+
+	// CriticalPath lists the indices into Injector.Steps of the most
+	// expensive chain of dependencies leading to the injector's result, in
+	// construction order. Critical is the sum of their costs: the least
+	// time in which the result could be built if independent steps ran
+	// concurrently. For an injector that returns no value, the chain may
+	// end at any of its steps, since each is built for its side effects.
+	CriticalPath []int
+	Critical     time.Duration
+}
+
+// InjectorCosts computes the cost of constructing each injector's result
+// from the //wire:cost directives of its providers. The providers' source
+// files are read from disk, since the directives are in comments that
+// loading does not keep.
+func InjectorCosts(fset *token.FileSet, injectors []*Injector) ([]*InjectorCost, []error) {
+	cr := &costReader{
+		docs:  newProviderDocs(fset),
+		costs: make(map[*Provider]time.Duration),
+	}
+	ec := new(errorCollector)
+	costs := make([]*InjectorCost, 0, len(injectors))
+	for _, in := range injectors {
+		ic := &InjectorCost{
+			Injector: in,
+			Steps:    make([]time.Duration, len(in.Steps)),
+		}
+		for i, step := range in.Steps {
+			if step.Provider == nil {
+				continue
+			}
+			cost, err := cr.providerCost(step.Provider)
+			if err != nil {
+				ec.add(err)
+				continue
+			}
+			ic.Steps[i] = cost
+			ic.Total += cost
+		}
+		ic.CriticalPath, ic.Critical = criticalPath(in, ic.Steps)
+		costs = append(costs, ic)
+	}
+	return costs, ec.errors
+}
+
+// criticalPath returns the most expensive chain of steps that the
+// injector's result depends on, along with its cost. For an injector that
+// returns no value, it returns the most expensive chain of all its steps.
+func criticalPath(in *Injector, costs []time.Duration) ([]int, time.Duration) {
+	nparams := in.Params.Len()
+	out := in.OutIndex() - nparams
+	if out < 0 && (in.Out != nil || len(in.Steps) == 0) {
+		return nil, 0
+	}
+	// Steps only consume earlier steps, so one pass in construction order
+	// finds the most expensive chain ending at each step.
+	dist := make([]time.Duration, len(in.Steps))
+	prev := make([]int, len(in.Steps))
+	for i, step := range in.Steps {
+		prev[i] = -1
+		for _, arg := range step.Args {
+			if j := arg - nparams; j >= 0 && (prev[i] < 0 || dist[j] > dist[prev[i]]) {
+				prev[i] = j
+			}
+		}
+		dist[i] = costs[i]
+		if prev[i] >= 0 {
+			dist[i] += dist[prev[i]]
+		}
+		if in.Out == nil && (out < 0 || dist[i] >= dist[out]) {
+			out = i
+		}
+	}
+	var path []int
+	for i := out; i >= 0; i = prev[i] {
+		path = append(path, i)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, dist[out]
+}
+
+// An InjectorSize describes how large an injector's object graph and
+// generated code are, to find injectors worth splitting.
+type InjectorSize struct {
+	Injector *Injector
+
+	// Providers is the number of distinct providers the injector calls.
+	Providers int
+	// Depth is the number of steps in the longest chain of dependencies
+	// that the injector's result depends on, itself included, or of all
+	// its steps if it returns no value.
+	Depth int
+	// Lines is the number of lines of the injector's function in its
+	// generated file, or 0 if the file does not declare it.
+	Lines int
+}
+
+// InjectorSizes measures each of injectors. fset must be the file set they
+// were loaded with, and prefix and nameTemplate are the output file prefix
+// and name template their generated files were written with.
+func InjectorSizes(fset *token.FileSet, injectors []*Injector, prefix, nameTemplate string) []*InjectorSize {
+	sizes := make([]*InjectorSize, 0, len(injectors))
+	for _, in := range injectors {
+		is := &InjectorSize{Injector: in}
+		providers := make(map[*Provider]bool)
+		ones := make([]time.Duration, len(in.Steps))
+		for i, step := range in.Steps {
+			if step.Provider != nil {
+				providers[step.Provider] = true
+			}
+			ones[i] = 1
+		}
+		is.Providers = len(providers)
+		path, _ := criticalPath(in, ones)
+		is.Depth = len(path)
+		if genFset, _, fn := generatedInjector(fset, in, prefix, nameTemplate); fn != nil {
+			is.Lines = genFset.Position(fn.End()).Line - genFset.Position(fn.Pos()).Line + 1
+		}
+		sizes = append(sizes, is)
+	}
+	return sizes
+}
+
+// costReader reads the //wire:cost directives of providers.
+type costReader struct {
+	docs  *providerDocs
+	costs map[*Provider]time.Duration
+}
+
+func (cr *costReader) providerCost(p *Provider) (time.Duration, error) {
+	if cost, ok := cr.costs[p]; ok {
+		return cost, nil
+	}
+	doc, err := cr.docs.doc(p)
+	var cost time.Duration
+	if err == nil && doc != nil {
+		cost, err = parseCostDirective(cr.docs.fset, doc)
+	}
+	cr.costs[p] = cost
+	return cost, err
+}
+
+// parseCostDirective returns the cost declared by a //wire:cost directive
+// in doc, or zero if there is none.
+func parseCostDirective(fset *token.FileSet, doc *ast.CommentGroup) (time.Duration, error) {
+	for _, c := range doc.List {
+		rest := strings.TrimPrefix(c.Text, costDirective)
+		if rest == c.Text || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+			continue
+		}
+		cost, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err == nil && cost < 0 {
+			err = fmt.Errorf("negative cost %s", strings.TrimSpace(rest))
+		}
+		if err != nil {
+			return 0, notePosition(fset.Position(c.Pos()), fmt.Errorf("invalid %s directive: %v", costDirective, err))
+		}
+		return cost, nil
+	}
+	return 0, nil
+}
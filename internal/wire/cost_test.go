@@ -0,0 +1,255 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInjectorSizes(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"type Foo int",
+		"type Bar struct{ Foo Foo }",
+		"type Baz struct{ Foo Foo }",
+		"type App struct {",
+		"\tBar *Bar",
+		"\tBaz *Baz",
+		"}",
+		"",
+		"func NewFoo() Foo { return 1 }",
+		"func NewBar(f Foo) *Bar { return &Bar{Foo: f} }",
+		"func NewBaz(f Foo) *Baz { return &Baz{Foo: f} }",
+		"func NewApp(bar *Bar, baz *Baz) *App { return &App{Bar: bar, Baz: baz} }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func InitApp() *App {",
+		"\tpanic(wire.Build(NewFoo, NewBar, NewBaz, NewApp))",
+		"}",
+		"",
+		"func InitFoo() Foo {",
+		"\tpanic(wire.Build(NewFoo))",
+		"}",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	info, errs := Load(ctx, root, env, "", []string{"./app"})
+	if len(errs) > 0 {
+		t.Fatalf("Load failed: %v", errs)
+	}
+	if len(info.Injectors) != 2 {
+		t.Fatalf("Load found %d injectors; want 2", len(info.Injectors))
+	}
+	byName := make(map[string]*Injector)
+	for _, in := range info.Injectors {
+		byName[in.FuncName] = in
+	}
+	injectors := []*Injector{byName["InitApp"], byName["InitFoo"]}
+
+	// Without generated code, only the graph is measured.
+	for i, is := range InjectorSizes(info.Fset, injectors, "", "") {
+		if is.Injector != injectors[i] || is.Lines != 0 {
+			t.Errorf("InjectorSizes before generating [%d] = %+v; want %s with 0 lines", i, is, injectors[i].FuncName)
+		}
+	}
+
+	gens, genErrs := Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{})
+	if len(genErrs) > 0 || len(gens) != 1 || len(gens[0].Errs) > 0 {
+		t.Fatalf("Generate failed: %v %v", genErrs, gens)
+	}
+	if err := gens[0].Commit(); err != nil {
+		t.Fatal(err)
+	}
+	// NewFoo is called once although both NewBar and NewBaz need it, and
+	// the longest chain is NewFoo, NewBar, NewApp.
+	want := []struct{ providers, depth, lines int }{
+		{4, 3, 7},
+		{1, 1, 4},
+	}
+	sizes := InjectorSizes(info.Fset, injectors, "", "")
+	if len(sizes) != len(want) {
+		t.Fatalf("InjectorSizes returned %d sizes; want %d", len(sizes), len(want))
+	}
+	for i, is := range sizes {
+		if is.Providers != want[i].providers || is.Depth != want[i].depth || is.Lines != want[i].lines {
+			t.Errorf("InjectorSizes of %s = %d providers, depth %d, and %d lines; want %d, %d, and %d", injectors[i].FuncName, is.Providers, is.Depth, is.Lines, want[i].providers, want[i].depth, want[i].lines)
+		}
+	}
+	if sizes := InjectorSizes(info.Fset, injectors, "missing_", ""); sizes[0].Lines != 0 {
+		t.Errorf("InjectorSizes with another output prefix has %d lines; want 0", sizes[0].Lines)
+	}
+}
+
+func TestInjectorCosts(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	providers := func(cacheCost string) string {
+		return strings.Join([]string{
+			"package app",
+			"",
+			"type Config struct{}",
+			"type DB struct{}",
+			"type Cache struct{}",
+			"",
+			"// Server serves.",
+			"//",
+			"//wire:cost 10ms",
+			"type Server struct {",
+			"\tDB    *DB",
+			"\tCache *Cache",
+			"}",
+			"",
+			"//wire:cost 5ms",
+			"func NewConfig() Config { return Config{} }",
+			"",
+			"// NewDB connects to the database.",
+			"//",
+			"//wire:cost 120ms",
+			"func NewDB(Config) *DB { return nil }",
+			"",
+			"//wire:cost " + cacheCost,
+			"func NewCache(Config) *Cache { return nil }",
+			"",
+		}, "\n")
+	}
+	writeFile(t, filepath.Join(root, "app", "app.go"), providers("40ms"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"// +build wireinject",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func InitServer() *Server {",
+		"\twire.Build(NewConfig, NewDB, NewCache, wire.Struct(new(Server), \"*\"))",
+		"\treturn nil",
+		"}",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	info, errs := Load(ctx, root, env, "", []string{"./app"})
+	if len(errs) > 0 {
+		t.Fatalf("Load returned errors: %v", errs)
+	}
+	costs, errs := InjectorCosts(info.Fset, info.Injectors)
+	if len(errs) > 0 {
+		t.Fatalf("InjectorCosts returned errors: %v", errs)
+	}
+	if len(costs) != 1 {
+		t.Fatalf("InjectorCosts returned %d costs; want 1", len(costs))
+	}
+	ic := costs[0]
+	if ic.Total != 175*time.Millisecond || ic.Critical != 135*time.Millisecond {
+		t.Errorf("Total, Critical = %v, %v; want 175ms, 135ms", ic.Total, ic.Critical)
+	}
+	var path []string
+	for _, i := range ic.CriticalPath {
+		path = append(path, TypeString(ic.Injector.Steps[i].Out))
+	}
+	want := []string{"example.com/app/app.Config", "*example.com/app/app.DB", "*example.com/app/app.Server"}
+	if !reflect.DeepEqual(path, want) {
+		t.Errorf("CriticalPath = %v; want %v", path, want)
+	}
+
+	writeFile(t, filepath.Join(root, "app", "app.go"), providers("soon"))
+	info, errs = Load(ctx, root, env, "", []string{"./app"})
+	if len(errs) > 0 {
+		t.Fatalf("Load returned errors: %v", errs)
+	}
+	_, errs = InjectorCosts(info.Fset, info.Injectors)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `app.go:23:1: invalid //wire:cost directive`) {
+		t.Errorf("InjectorCosts errors = %v; want an invalid directive at app.go:23:1", errs)
+	}
+}
+
+func TestInjectorCostsSideEffects(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"type DB struct{}",
+		"type Handlers struct{}",
+		"type Metrics struct{}",
+		"",
+		"//wire:cost 100ms",
+		"func NewDB() *DB { return nil }",
+		"",
+		"//wire:cost 20ms",
+		"func NewHandlers(*DB) Handlers { return Handlers{} }",
+		"",
+		"//wire:cost 50ms",
+		"func NewMetrics() Metrics { return Metrics{} }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func Register() {",
+		"\twire.Build(NewDB, NewHandlers, NewMetrics, wire.Require(new(Handlers)), wire.Require(new(Metrics)))",
+		"}",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	info, errs := Load(context.Background(), root, env, "", []string{"./app"})
+	if len(errs) > 0 {
+		t.Fatalf("Load returned errors: %v", errs)
+	}
+	costs, errs := InjectorCosts(info.Fset, info.Injectors)
+	if len(errs) > 0 {
+		t.Fatalf("InjectorCosts returned errors: %v", errs)
+	}
+	ic := costs[0]
+	if ic.Total != 170*time.Millisecond || ic.Critical != 120*time.Millisecond {
+		t.Errorf("Total, Critical = %v, %v; want 170ms, 120ms", ic.Total, ic.Critical)
+	}
+	var path []string
+	for _, i := range ic.CriticalPath {
+		path = append(path, TypeString(ic.Injector.Steps[i].Out))
+	}
+	want := []string{"*example.com/app/app.DB", "example.com/app/app.Handlers"}
+	if !reflect.DeepEqual(path, want) {
+		t.Errorf("CriticalPath = %v; want %v", path, want)
+	}
+	if sizes := InjectorSizes(info.Fset, info.Injectors, "", ""); sizes[0].Depth != 2 {
+		t.Errorf("InjectorSizes depth = %d; want 2", sizes[0].Depth)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"fmt"
+)
+
+type debugLogger func(string)
+
+type debugKey struct{}
+
+// WithDebug enables debug output for wire operations using the provided
+// callback, which receives one message per decision Wire makes, such as
+// which packages it loads and how it computes cache keys.
+func WithDebug(ctx context.Context, logf func(string)) context.Context {
+	if logf == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, debugKey{}, debugLogger(logf))
+}
+
+func debugLog(ctx context.Context) debugLogger {
+	if ctx == nil {
+		return nil
+	}
+	if v := ctx.Value(debugKey{}); v != nil {
+		if d, ok := v.(debugLogger); ok {
+			return d
+		}
+	}
+	return nil
+}
+
+// debugf formats a debug message, only if debug output is enabled.
+func debugf(ctx context.Context, format string, args ...interface{}) {
+	if d := debugLog(ctx); d != nil {
+		d(fmt.Sprintf(format, args...))
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithDebugNil(t *testing.T) {
+	ctx := context.Background()
+	if got := WithDebug(ctx, nil); got != ctx {
+		t.Fatal("expected WithDebug to return original context on nil logger")
+	}
+	if debugLog(nil) != nil {
+		t.Fatal("expected nil debug logger for nil context")
+	}
+	// Without a logger, debugf is a no-op.
+	debugf(ctx, "ignored %d", 1)
+}
+
+func TestWithDebugAndLog(t *testing.T) {
+	var got []string
+	ctx := WithDebug(context.Background(), func(msg string) {
+		got = append(got, msg)
+	})
+	debugf(ctx, "%s: cache %s", "example.com/app", "hit")
+	if len(got) != 1 || got[0] != "example.com/app: cache hit" {
+		t.Fatalf("debug messages = %q; want [\"example.com/app: cache hit\"]", got)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// A FileDiff compares a generated file on disk with what Generate produces
+// for it.
+type FileDiff struct {
+	// PkgPath is the import path of the package the file belongs to.
+	PkgPath string
+	// Path is the path of the generated file.
+	Path string
+	// Current is the content of the file on disk, or nil if it does not
+	// exist.
+	Current []byte
+	// Generated is the content Generate produced for the file.
+	Generated []byte
+	// Unified is a unified diff from Current to Generated without context
+	// lines, as printed by wire diff, or empty if they are equal.
+	Unified string
+	// Cached reports whether Generated was read from the cache.
+	Cached bool
+}
+
+// Changed reports whether the file on disk differs from the generated
+// content.
+func (d FileDiff) Changed() bool {
+	return !bytes.Equal(d.Current, d.Generated)
+}
+
+// Diff generates the packages matching patterns, as Generate does, and
+// compares each generated file with the one on disk. It returns a FileDiff
+// for every package that has injectors, in the order of Generate's results,
+// whether or not its file is up to date. The errors of packages that failed
+// to generate are returned along with the diffs of the others; with
+// opts.Partial, a package can have both.
+func Diff(ctx context.Context, wd string, env []string, patterns []string, opts *GenerateOptions) ([]FileDiff, ErrorList) {
+	outs, errs := Generate(ctx, wd, env, patterns, opts)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	diffStart := time.Now()
+	defer logTiming(ctx, "diff", diffStart)
+	var diffs []FileDiff
+	for _, out := range outs {
+		errs = append(errs, out.Errs...)
+		if out.Skipped || len(out.Content) == 0 {
+			// No injectors, or errors reported above.
+			continue
+		}
+		d, err := diffFile(out)
+		if err != nil {
+			errs.add(out.PkgPath, err)
+			continue
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs, errs
+}
+
+// diffFile compares the output of out with its file on disk. A missing file
+// is treated as empty.
+func diffFile(out GenerateResult) (FileDiff, error) {
+	cur, _ := os.ReadFile(out.OutputPath)
+	d := FileDiff{
+		PkgPath:   out.PkgPath,
+		Path:      out.OutputPath,
+		Current:   cur,
+		Generated: out.Content,
+		Cached:    out.Cached,
+	}
+	if !d.Changed() {
+		return d, nil
+	}
+	unified, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A: difflib.SplitLines(string(cur)),
+		B: difflib.SplitLines(string(out.Content)),
+	})
+	if err != nil {
+		return FileDiff{}, fmt.Errorf("failed to diff %s: %v", out.OutputPath, err)
+	}
+	d.Unified = unified
+	return d, nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), "package app\n\nfunc NewMessage() string { return \"ok\" }\n")
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"// +build wireinject",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func Init() string {",
+		"\twire.Build(NewMessage)",
+		"\treturn \"\"",
+		"}",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	diffs, errs := Diff(ctx, root, env, []string{"./app"}, &GenerateOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("Diff returned errors: %v", errs)
+	}
+	if len(diffs) != 1 {
+		t.Fatalf("Diff returned %d diffs; want 1", len(diffs))
+	}
+	d := diffs[0]
+	if d.PkgPath != "example.com/app/app" || d.Path != filepath.Join(root, "app", "wire_gen.go") {
+		t.Errorf("Diff = %s, %s; want example.com/app/app and its wire_gen.go", d.PkgPath, d.Path)
+	}
+	if !d.Changed() || d.Current != nil || !strings.Contains(d.Unified, "+func Init() string {") {
+		t.Errorf("Diff of a missing file = %+v; want a diff that adds Init", d)
+	}
+
+	if err := os.WriteFile(d.Path, d.Generated, 0666); err != nil {
+		t.Fatal(err)
+	}
+	diffs, errs = Diff(ctx, root, env, []string{"./app"}, &GenerateOptions{})
+	if len(errs) > 0 || len(diffs) != 1 {
+		t.Fatalf("Diff = %v, %v; want one diff", diffs, errs)
+	}
+	if diffs[0].Changed() || diffs[0].Unified != "" {
+		t.Errorf("Diff of an up to date file = %+v; want no changes", diffs[0])
+	}
+}
@@ -15,7 +15,13 @@
 package wire
 
 import (
+	"errors"
+	"fmt"
 	"go/token"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
 )
 
 // errorCollector manages a list of errors. The zero value is an empty list.
@@ -44,10 +50,279 @@ func mapErrors(errs []error, f func(error) error) []error {
 	return newErrs
 }
 
-// A wireErr is an error with an optional position.
+// A wireErr is an error with an optional position and suggested fixes.
 type wireErr struct {
 	error    error
 	position token.Position
+	fixes    []SuggestedFix
+}
+
+// A SuggestedFix is a set of source edits that would resolve an error.
+type SuggestedFix struct {
+	// Message describes the fix.
+	Message string
+	// Edits are the changes to apply, in source order.
+	Edits []TextEdit
+}
+
+// A TextEdit replaces the source between Pos and End with NewText. Pos and
+// End are equal for insertions.
+type TextEdit struct {
+	Pos     token.Position
+	End     token.Position
+	NewText string
+}
+
+// String describes the edit, e.g. `foo.go:3:1: insert "return nil"`.
+func (e TextEdit) String() string {
+	if e.End == e.Pos {
+		return fmt.Sprintf("%v: insert %q", e.Pos, e.NewText)
+	}
+	return fmt.Sprintf("%v-%d:%d: replace with %q", e.Pos, e.End.Line, e.End.Column, e.NewText)
+}
+
+// SuggestedFixes returns the fixes attached to an error returned by Generate
+// or Load, or nil if it has none.
+func SuggestedFixes(err error) []SuggestedFix {
+	var w *wireErr
+	if errors.As(err, &w) {
+		return w.fixes
+	}
+	return nil
+}
+
+// An ErrorCode classifies an Error so that tools can handle kinds of errors
+// without matching on messages.
+type ErrorCode string
+
+// Error codes. Errors that fit none of these have an empty code.
+const (
+	// CodeLoad means a package failed to load or type-check.
+	CodeLoad ErrorCode = "load"
+	// CodeNoProvider means no provider was found for a needed type.
+	CodeNoProvider ErrorCode = "no_provider"
+	// CodeConflict means more than one provider was found for a type.
+	CodeConflict ErrorCode = "conflict"
+	// CodeCycle means providers depend on each other in a cycle.
+	CodeCycle ErrorCode = "cycle"
+	// CodeUnused means a provider, value, binding, or set passed to
+	// wire.Build is not used.
+	CodeUnused ErrorCode = "unused"
+	// CodeSignature means a provider's or injector's signature is invalid,
+	// or the injector cannot return what its providers need it to.
+	CodeSignature ErrorCode = "signature"
+	// CodeFormat means the generated code could not be formatted, which
+	// indicates a bug in Wire.
+	CodeFormat ErrorCode = "format"
+	// CodeDeprecated means an injector uses a provider whose doc comment
+	// marks it as deprecated, or code uses a provider set redirected with
+	// wire.Redirect.
+	CodeDeprecated ErrorCode = "deprecated"
+	// CodeUnusedParam means a provider parameter has no effect; see
+	// UnusedParam.
+	CodeUnusedParam ErrorCode = "unused_param"
+	// CodeExcludedFile means a package's wireinject files are all excluded
+	// by their build constraints, usually because -tags was not given.
+	CodeExcludedFile ErrorCode = "excluded_file"
+	// CodeGeneratedLeak means a wireinject file uses a name declared only
+	// in Wire's generated output, or another file uses a name declared only
+	// in a wireinject file, so the package fails to build under one side of
+	// the wireinject build tag.
+	CodeGeneratedLeak ErrorCode = "generated_leak"
+	// CodeConstraintConflict means an injector is declared again in a
+	// wireinject file that the build constraints exclude, such as one
+	// file per configuration selected with -tags.
+	CodeConstraintConflict ErrorCode = "constraint_conflict"
+	// CodeShadowedSet means a provider set is passed by a bare name that
+	// a package imported by the same file also uses for a provider set.
+	CodeShadowedSet ErrorCode = "shadowed_set"
+	// CodeModuleDownload means a package failed to load because the go
+	// command could not download a module it needs, such as a private
+	// module or any module without network access.
+	CodeModuleDownload ErrorCode = "module_download"
+	// CodeLayerViolation means an injector calls a provider with a value
+	// from a layer that the LayerConfig passed to CheckLayers does not
+	// allow the provider's layer to depend on.
+	CodeLayerViolation ErrorCode = "layer_violation"
+)
+
+// A Severity says whether an Error prevents generating code.
+type Severity int
+
+const (
+	// SeverityError means code cannot be generated.
+	SeverityError Severity = iota
+	// SeverityWarning means code can be generated, but the problem is
+	// likely a mistake or a maintenance hazard.
+	SeverityWarning
+)
+
+// String returns "error" or "warning".
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// An Error is a single error reported by Generate.
+type Error struct {
+	// Pkg is the import path of the package being processed, if known.
+	Pkg string
+	// Pos is the position the error refers to. It is not valid if the
+	// error has no position.
+	Pos token.Position
+	// Code classifies the error.
+	Code ErrorCode
+	// Msg is the error message without the position.
+	Msg string
+	// Fixes are the suggested fixes for the error, if any.
+	Fixes []SuggestedFix
+	// Severity is SeverityError for the errors returned by Generate and
+	// Load, and SeverityWarning for Info.Warnings.
+	Severity Severity
+
+	err error
+}
+
+// Error returns the error formatted as on the command line.
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// An ErrorList is a list of errors.
+type ErrorList []*Error
+
+// Errors returns the list as plain errors.
+func (l ErrorList) Errors() []error {
+	if len(l) == 0 {
+		return nil
+	}
+	errs := make([]error, len(l))
+	for i, e := range l {
+		errs[i] = e
+	}
+	return errs
+}
+
+// add appends errs to the list, attributing them to the package pkg unless
+// they name a package themselves.
+func (l *ErrorList) add(pkg string, errs ...error) {
+	for _, err := range errs {
+		if err != nil {
+			*l = append(*l, newError(pkg, err))
+		}
+	}
+}
+
+// newErrorList converts errs into an ErrorList.
+func newErrorList(pkg string, errs []error) ErrorList {
+	var l ErrorList
+	l.add(pkg, errs...)
+	return l
+}
+
+// newError describes err as an Error.
+func newError(pkg string, err error) *Error {
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+	e := &Error{Pkg: pkg, Msg: err.Error(), err: err}
+	var pe *pkgError
+	if errors.As(err, &pe) {
+		e.Pkg = pe.pkg
+	}
+	var w *wireErr
+	var le packages.Error
+	switch {
+	case errors.As(err, &w):
+		e.Pos = w.position
+		e.Msg = w.error.Error()
+		e.Fixes = w.fixes
+	case errors.As(err, &le):
+		e.Pos = parsePosition(le.Pos)
+		e.Msg = le.Msg
+		e.Code = CodeLoad
+	}
+	var ce *codedErr
+	if errors.As(err, &ce) {
+		e.Code = ce.code
+	}
+	return e
+}
+
+// newWarning describes err as an Error with SeverityWarning.
+func newWarning(pkg string, code ErrorCode, err error) *Error {
+	e := newError(pkg, withCode(code, err))
+	e.Severity = SeverityWarning
+	return e
+}
+
+// positionLess orders positions by file and then by offset.
+func positionLess(p, q token.Position) bool {
+	if p.Filename != q.Filename {
+		return p.Filename < q.Filename
+	}
+	return p.Offset < q.Offset
+}
+
+// parsePosition parses a position of the form "file:line:col" or
+// "file:line", as reported by go/packages. It returns the zero Position if
+// pos has neither form.
+func parsePosition(pos string) token.Position {
+	var nums []int
+	file := pos
+	for len(nums) < 2 {
+		i := strings.LastIndex(file, ":")
+		if i < 0 {
+			break
+		}
+		n, err := strconv.Atoi(file[i+1:])
+		if err != nil {
+			break
+		}
+		nums = append(nums, n)
+		file = file[:i]
+	}
+	switch len(nums) {
+	case 1:
+		return token.Position{Filename: file, Line: nums[0]}
+	case 2:
+		return token.Position{Filename: file, Line: nums[1], Column: nums[0]}
+	}
+	return token.Position{}
+}
+
+// codedErr attaches an ErrorCode to an error.
+type codedErr struct {
+	code ErrorCode
+	error
+}
+
+// Unwrap returns the underlying error.
+func (e *codedErr) Unwrap() error {
+	return e.error
+}
+
+// withCode returns err classified with code.
+func withCode(code ErrorCode, err error) error {
+	return &codedErr{code: code, error: err}
+}
+
+// pkgError attributes a load error to the package it was reported for.
+type pkgError struct {
+	pkg string
+	error
+}
+
+// Unwrap returns the underlying error.
+func (e *pkgError) Unwrap() error {
+	return e.error
 }
 
 // notePosition wraps an error with position information if it doesn't already
@@ -75,10 +350,30 @@ func notePositionAll(p token.Position, errs []error) []error {
 	})
 }
 
-// Error returns the error message prefixed by the position if valid.
+// Unwrap returns the underlying error.
+func (w *wireErr) Unwrap() error {
+	return w.error
+}
+
+// Error returns the error message prefixed by the position if valid,
+// followed by any suggested fixes.
 func (w *wireErr) Error() string {
-	if !w.position.IsValid() {
-		return w.error.Error()
+	msg := w.error.Error()
+	if w.position.IsValid() {
+		msg = w.position.String() + ": " + msg
+	}
+	if len(w.fixes) == 0 {
+		return msg
+	}
+	sb := new(strings.Builder)
+	sb.WriteString(msg)
+	for _, fix := range w.fixes {
+		sb.WriteString("\n\tsuggested fix: ")
+		sb.WriteString(fix.Message)
+		for _, edit := range fix.Edits {
+			sb.WriteString("\n\t\t")
+			sb.WriteString(edit.String())
+		}
 	}
-	return w.position.String() + ": " + w.error.Error()
+	return sb.String()
 }
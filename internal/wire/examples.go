@@ -0,0 +1,152 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bytes"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// examplesFileName is the name of the generated examples file, before the
+// output file prefix is applied.
+const examplesFileName = "wire_example_test.go"
+
+// generateExamples returns the unformatted source of a test file holding a
+// Go doc example for each injector in pkg, or nil if pkg has no injectors.
+// The examples are compiled by go test but never run, since their inputs
+// are placeholders. The file has the build constraint of the output
+// generated with tags.
+func generateExamples(pkg *packages.Package, header []byte, tags string) []byte {
+	g := newGen(pkg)
+	for _, f := range pkg.Syntax {
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			if buildCall, _ := findInjectorBuild(pkg.TypesInfo, fn); buildCall == nil {
+				continue
+			}
+			sig := pkg.TypesInfo.ObjectOf(fn.Name).Type().(*types.Signature)
+			g.injectorExample(fn.Name.Name, sig)
+		}
+	}
+	if g.buf.Len() == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	buf.Write(header)
+	buf.WriteString(generatedMarker + "\n\n")
+	for _, line := range plusBuildLines(generatedConstraint(tags, nil)) {
+		buf.WriteString(line + "\n")
+	}
+	buf.WriteString("\n")
+	buf.WriteString("package ")
+	buf.WriteString(pkg.Name)
+	buf.WriteString("\n\n")
+	g.writeImports(&buf)
+	buf.Write(g.buf.Bytes())
+	return buf.Bytes()
+}
+
+// injectorExample emits an example function that declares a placeholder for
+// each of the injector's arguments and calls it.
+func (g *gen) injectorExample(name string, sig *types.Signature) {
+	out, err := injectorOutput(sig)
+	if err != nil {
+		return
+	}
+	// Qualify every type first so that the names picked below do not
+	// shadow the imports they need.
+	params := sig.Params()
+	paramTypes := make([]string, params.Len())
+	for i := range paramTypes {
+		paramTypes[i] = types.TypeString(params.At(i).Type(), g.qualifyPkg)
+	}
+	used := make(map[string]bool)
+	collides := func(n string) bool { return used[n] || g.nameInFileScope(n) }
+	args := make([]string, params.Len())
+	for i := range args {
+		n := params.At(i).Name()
+		if n == "" || n == "_" {
+			n = typeVariableName(params.At(i).Type(), "arg", unexport, collides)
+		} else {
+			n = disambiguate(n, collides)
+		}
+		used[n] = true
+		args[i] = n
+	}
+	var result string
+	var results []string
+	if out.out != nil {
+		result = typeVariableName(out.out, "v", unexport, collides)
+		used[result] = true
+		results = append(results, result)
+	}
+	var cleanupVar, errVar string
+	if out.cleanup {
+		cleanupVar = disambiguate("cleanup", collides)
+		used[cleanupVar] = true
+		results = append(results, cleanupVar)
+	}
+	if out.err {
+		errVar = disambiguate("err", collides)
+		used[errVar] = true
+		results = append(results, errVar)
+	}
+
+	g.p("func %s() {\n", exampleFuncName(name))
+	if len(args) > 0 {
+		g.p("\t// Replace these placeholders with real values.\n")
+		g.p("\tvar (\n")
+		for i, arg := range args {
+			g.p("\t\t%s %s\n", arg, paramTypes[i])
+		}
+		g.p("\t)\n")
+	}
+	call := strings.Join(args, ", ")
+	if sig.Variadic() {
+		call += "..."
+	}
+	if len(results) == 0 {
+		g.p("\t%s(%s)\n", name, call)
+	} else {
+		g.p("\t%s := %s(%s)\n", strings.Join(results, ", "), name, call)
+	}
+	if out.err {
+		g.p("\tif %s != nil {\n\t\treturn\n\t}\n", errVar)
+	}
+	if out.cleanup {
+		g.p("\tdefer %s()\n", cleanupVar)
+	}
+	if result != "" {
+		g.p("\t_ = %s\n", result)
+	}
+	g.p("}\n\n")
+}
+
+// exampleFuncName returns the name of the example for the injector name.
+// Exported injectors get an example attached to them in godoc; unexported
+// ones are documented as package examples.
+func exampleFuncName(name string) string {
+	if ast.IsExported(name) {
+		return "Example" + name
+	}
+	return "Example_" + name
+}
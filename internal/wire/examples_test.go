@@ -0,0 +1,86 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateExamples(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"type Config struct{}",
+		"",
+		"type Svc struct{}",
+		"",
+		"func NewSvc(cfg Config) (*Svc, func(), error) { return &Svc{}, func() {}, nil }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"// +build wireinject",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func Init(cfg Config) (*Svc, func(), error) {",
+		"\twire.Build(NewSvc)",
+		"\treturn nil, nil, nil",
+		"}",
+		"",
+		"func initConfig() Config {",
+		"\twire.Build(wire.Value(Config{}))",
+		"\treturn Config{}",
+		"}",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	opts := &GenerateOptions{Examples: true}
+
+	for i := 0; i < 2; i++ {
+		gens, errs := Generate(ctx, root, env, []string{"./app"}, opts)
+		if len(errs) > 0 {
+			t.Fatalf("Generate returned errors: %v", errs)
+		}
+		if len(gens) != 1 || len(gens[0].Errs) > 0 {
+			t.Fatalf("Generate failed: %+v", gens)
+		}
+		if want := filepath.Join(root, "app", "wire_example_test.go"); gens[0].ExamplePath != want {
+			t.Errorf("ExamplePath = %q; want %q", gens[0].ExamplePath, want)
+		}
+		got := string(gens[0].ExampleContent)
+		for _, want := range []string{
+			"func ExampleInit() {",
+			"svc, cleanup, err := Init(cfg)",
+			"defer cleanup()",
+			"func Example_initConfig() {",
+			"config := initConfig()",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("run %d: examples missing %q:\n%s", i, want, got)
+			}
+		}
+	}
+}
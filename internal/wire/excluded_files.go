@@ -0,0 +1,187 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// maxSuggestedTags bounds the number of unset tags considered when looking
+// for a set of tags that would include an excluded wireinject file.
+const maxSuggestedTags = 8
+
+// excludedInjectorWarnings returns a warning for each wireinject file of pkg
+// that the build constraints exclude under tags and env, unless another
+// wireinject file of pkg is included. Forgetting -tags otherwise leaves the
+// package silently without injectors. Each warning names the tags that would
+// include the file, if any do.
+func excludedInjectorWarnings(pkg *packages.Package, env []string, tags string) ErrorList {
+	fset := token.NewFileSet()
+	for _, path := range pkg.GoFiles {
+		f, err := parser.ParseFile(fset, path, nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err == nil && isWireinjectFile(f) {
+			return nil
+		}
+	}
+	satisfied := tagMatcher(env, tags)
+	var warnings ErrorList
+	for _, path := range pkg.IgnoredFiles {
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil || !isWireinjectFile(f) {
+			continue
+		}
+		expr := buildConstraint(f.Comments, f.Package)
+		if expr == nil {
+			continue
+		}
+		msg := fmt.Sprintf("no injectors found: %s has the wireinject build tag but is excluded by its constraint %q", filepath.Base(path), expr.String())
+		if extra, ok := suggestTags(expr, satisfied); ok {
+			msg += fmt.Sprintf("; generate with -tags=%q or add \"%s %s\" to the file", strings.Join(extra, ","), tagsDirective, strings.Join(extra, " "))
+		} else {
+			msg += " for the target platform"
+		}
+		warnings = append(warnings, newWarning(pkg.PkgPath, CodeExcludedFile, notePosition(fset.Position(f.Package), fmt.Errorf("%s", msg))))
+	}
+	return warnings
+}
+
+// buildConstraint returns the build constraint in the comments of a file
+// before its package clause at pkgPos, or nil if it has none.
+func buildConstraint(comments []*ast.CommentGroup, pkgPos token.Pos) constraint.Expr {
+	var plusBuild []constraint.Expr
+	for _, cg := range comments {
+		if cg.Pos() > pkgPos {
+			break
+		}
+		for _, c := range cg.List {
+			if !constraint.IsGoBuild(c.Text) && !constraint.IsPlusBuild(c.Text) {
+				continue
+			}
+			expr, err := constraint.Parse(c.Text)
+			if err != nil {
+				continue
+			}
+			if constraint.IsGoBuild(c.Text) {
+				return expr
+			}
+			plusBuild = append(plusBuild, expr)
+		}
+	}
+	if len(plusBuild) == 0 {
+		return nil
+	}
+	expr := plusBuild[0]
+	for _, x := range plusBuild[1:] {
+		expr = &constraint.AndExpr{X: expr, Y: x}
+	}
+	return expr
+}
+
+// tagMatcher reports whether a build tag is set when loading with tags
+// under env, along with wireinject.
+func tagMatcher(env []string, tags string) func(string) bool {
+	set := map[string]bool{"wireinject": true}
+	for _, tag := range strings.FieldsFunc(tags, func(r rune) bool { return r == ',' || r == ' ' }) {
+		set[tag] = true
+	}
+	goos, goarch := lookupEnv(env, "GOOS"), lookupEnv(env, "GOARCH")
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	if goarch == "" {
+		goarch = runtime.GOARCH
+	}
+	set[goos], set[goarch] = true, true
+	if cgo := lookupEnv(env, "CGO_ENABLED"); cgo == "1" || (cgo == "" && build.Default.CgoEnabled) {
+		set["cgo"] = true
+	}
+	for _, tag := range build.Default.ReleaseTags {
+		set[tag] = true
+	}
+	return func(tag string) bool { return set[tag] }
+}
+
+// suggestTags returns a smallest set of unset tags that satisfies expr.
+func suggestTags(expr constraint.Expr, satisfied func(string) bool) ([]string, bool) {
+	seen := make(map[string]bool)
+	var unset []string
+	walkTags(expr, func(tag string) {
+		if !satisfied(tag) && !seen[tag] && !strings.HasPrefix(tag, "go1.") {
+			seen[tag] = true
+			unset = append(unset, tag)
+		}
+	})
+	if len(unset) > maxSuggestedTags {
+		return nil, false
+	}
+	sort.Strings(unset)
+	var best []string
+	for mask := 1; mask < 1<<len(unset); mask++ {
+		var extra []string
+		for i, tag := range unset {
+			if mask&(1<<i) != 0 {
+				extra = append(extra, tag)
+			}
+		}
+		if best != nil && len(extra) >= len(best) {
+			continue
+		}
+		ok := expr.Eval(func(tag string) bool {
+			if satisfied(tag) {
+				return true
+			}
+			for _, e := range extra {
+				if e == tag {
+					return true
+				}
+			}
+			return false
+		})
+		if ok {
+			best = extra
+		}
+	}
+	return best, best != nil
+}
+
+// walkTags calls fn for each tag in expr.
+func walkTags(expr constraint.Expr, fn func(string)) {
+	switch x := expr.(type) {
+	case *constraint.TagExpr:
+		fn(x.Tag)
+	case *constraint.NotExpr:
+		walkTags(x.X, fn)
+	case *constraint.AndExpr:
+		walkTags(x.X, fn)
+		walkTags(x.Y, fn)
+	case *constraint.OrExpr:
+		walkTags(x.X, fn)
+		walkTags(x.Y, fn)
+	}
+}
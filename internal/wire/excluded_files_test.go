@@ -0,0 +1,83 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExcludedInjectorFileWarning(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "db.go"), strings.Join([]string{
+		"package app",
+		"",
+		"type DB struct{}",
+		"",
+		"func NewDB() *DB { return &DB{} }",
+		"",
+	}, "\n"))
+	wireGo := func(header ...string) string {
+		return strings.Join(append(header,
+			"",
+			"package app",
+			"",
+			"import \"github.com/goforj/wire\"",
+			"",
+			"func InitDB() *DB {",
+			"\tpanic(wire.Build(NewDB))",
+			"}",
+			"",
+		), "\n")
+	}
+	writeFile(t, filepath.Join(root, "app", "wire.go"), wireGo("//go:build wireinject && (integration || e2e) && !nightly"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	const hint = `generate with -tags="e2e"`
+	gens, errs := Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("Generate failed: %v", errs)
+	}
+	if len(gens) != 1 || len(gens[0].Errs) > 0 || len(gens[0].Content) > 0 {
+		t.Fatalf("Generate returned %+v; want one result with no output or errors", gens)
+	}
+	if w := gens[0].Warnings; len(w) != 1 || w[0].Code != CodeExcludedFile || !strings.Contains(w[0].Error(), hint) {
+		t.Errorf("Generate warnings = %v; want one %s warning containing %q", w, CodeExcludedFile, hint)
+	}
+	info, loadErrs := Load(ctx, root, env, "", []string{"./app"})
+	if len(loadErrs) > 0 {
+		t.Fatalf("Load failed: %v", loadErrs)
+	}
+	if w := info.Warnings; len(w) != 1 || w[0].Code != CodeExcludedFile {
+		t.Errorf("Load warnings = %v; want one %s warning", w, CodeExcludedFile)
+	}
+
+	gens, errs = Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{Tags: "e2e"})
+	if len(errs) > 0 || len(gens) != 1 || len(gens[0].Warnings) > 0 || len(gens[0].Content) == 0 {
+		t.Errorf("Generate with -tags=e2e returned %+v, %v; want output and no warnings", gens, errs)
+	}
+
+	// The directive suggested by the warning includes the file on its own.
+	writeFile(t, filepath.Join(root, "app", "wire.go"), wireGo("//go:build wireinject && (integration || e2e) && !nightly", "//wire:tags e2e"))
+	gens, errs = Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{})
+	if len(errs) > 0 || len(gens) != 1 || len(gens[0].Errs) > 0 || !strings.Contains(string(gens[0].Content), "func InitDB() *DB {") {
+		t.Errorf("Generate with //wire:tags returned %+v, %v; want InitDB", gens, errs)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"errors"
+	"go/ast"
+	"go/types"
+)
+
+// fakeConventionPrefix is the name prefix of the functions TestBuild uses as
+// fakes for interfaces: NewFakeX provides the interface X.
+const fakeConventionPrefix = "NewFake"
+
+// A fakeSet is the result of a call to wire.Fakes.
+type fakeSet struct {
+	providers []*Provider
+}
+
+// processFakes creates a fakeSet from a call to wire.Fakes.
+func (oc *objectCache) processFakes(info *types.Info, pkgPath string, call *ast.CallExpr) (*fakeSet, []error) {
+	// Assumes that call.Fun is wire.Fakes.
+
+	fs := new(fakeSet)
+	ec := new(errorCollector)
+	for _, arg := range call.Args {
+		item, errs := oc.processExpr(info, pkgPath, arg, "")
+		if len(errs) > 0 {
+			ec.add(errs...)
+			continue
+		}
+		switch item := item.(type) {
+		case *Provider:
+			fs.providers = append(fs.providers, item)
+		case *fakeSet:
+			fs.providers = append(fs.providers, item.providers...)
+		default:
+			ec.add(notePosition(oc.fset.Position(arg.Pos()), errors.New("arguments to wire.Fakes must be provider functions or fake sets")))
+		}
+	}
+	if len(ec.errors) > 0 {
+		return nil, ec.errors
+	}
+	return fs, nil
+}
+
+// addFakes adds to set, which comes from a call to wire.TestBuild, the fakes
+// for the types needed to build out that set does not otherwise provide. The
+// fakes passed to wire.TestBuild are preferred over those found by naming
+// convention in the injector's package. Sets from wire.Build are left as is.
+func (oc *objectCache) addFakes(set *ProviderSet, out types.Type) []error {
+	if !set.testBuild {
+		return nil
+	}
+	scope := oc.packages[set.PkgPath].Types.Scope()
+	for {
+		var added []*Provider
+		for _, t := range set.Inputs(out) {
+			fake, errs := oc.fakeFor(set, scope, t)
+			if len(errs) > 0 {
+				return errs
+			}
+			if fake != nil {
+				added = append(added, fake)
+			}
+		}
+		if len(added) == 0 {
+			return nil
+		}
+		// The fakes may need types that are missing too, so repeat until
+		// no more are found.
+		set.Providers = append(set.Providers, added...)
+		if errs := oc.rebuildProviderMap(set); len(errs) > 0 {
+			return errs
+		}
+	}
+}
+
+// fakeFor returns the fake provider for t, or nil if there is none.
+func (oc *objectCache) fakeFor(set *ProviderSet, scope *types.Scope, t types.Type) (*Provider, []error) {
+	for _, p := range set.fakes {
+		if types.Identical(p.Out[0], t) {
+			return p, nil
+		}
+	}
+	named, ok := t.(*types.Named)
+	if !ok || !types.IsInterface(named) {
+		return nil, nil
+	}
+	obj := scope.Lookup(fakeConventionPrefix + named.Obj().Name())
+	if obj == nil {
+		return nil, nil
+	}
+	if _, ok := obj.(*types.TypeName); ok {
+		return nil, nil
+	}
+	item, errs := oc.get(obj)
+	if len(errs) > 0 {
+		return nil, notePositionAll(oc.fset.Position(obj.Pos()), errs)
+	}
+	if p, ok := item.(*Provider); ok && types.Identical(p.Out[0], t) {
+		return p, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,227 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build/constraint"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// This file recognizes common injector mistakes and turns the generic errors
+// they cause into targeted diagnostics with suggested fixes.
+
+// injectorTypeErrors rewrites type errors in pkgs that are caused by common
+// injector mistakes, returning errs with those errors replaced.
+func injectorTypeErrors(fset *token.FileSet, pkgs []*packages.Package, errs []error) []error {
+	var out []error
+	for _, err := range errs {
+		var pe packages.Error
+		if errors.As(err, &pe) && pe.Kind == packages.TypeError && pe.Msg == "missing return" {
+			if fixed := missingReturnError(fset, pkgs, pe.Pos); fixed != nil {
+				out = append(out, fixed)
+				continue
+			}
+		}
+		out = append(out, err)
+	}
+	return out
+}
+
+// missingReturnError reports an injector whose body ends at pos without the
+// return statement that must follow wire.Build, or nil if pos is not the end
+// of an injector.
+func missingReturnError(fset *token.FileSet, pkgs []*packages.Package, pos string) error {
+	for _, pkg := range pkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		for _, f := range pkg.Syntax {
+			for _, decl := range f.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Body == nil {
+					continue
+				}
+				end := fset.Position(fn.Body.Rbrace)
+				if end.String() != pos {
+					continue
+				}
+				if call, _ := findInjectorBuild(pkg.TypesInfo, fn); call == nil {
+					return nil
+				}
+				werr := &wireErr{
+					error:    fmt.Errorf("inject %s: wire.Build must be followed by a return statement", fn.Name.Name),
+					position: end,
+				}
+				if ret, ok := zeroReturn(pkg, fn); ok {
+					werr.fixes = []SuggestedFix{{
+						Message: "return zero values after wire.Build; the generated injector replaces this body",
+						Edits:   []TextEdit{{Pos: end, End: end, NewText: "\t" + ret + "\n"}},
+					}}
+				}
+				return werr
+			}
+		}
+	}
+	return nil
+}
+
+// zeroReturn returns a return statement yielding zero values for fn's results.
+func zeroReturn(pkg *packages.Package, fn *ast.FuncDecl) (string, bool) {
+	obj, ok := pkg.TypesInfo.Defs[fn.Name].(*types.Func)
+	if !ok {
+		return "", false
+	}
+	results := obj.Type().(*types.Signature).Results()
+	qf := types.RelativeTo(pkg.Types)
+	vals := make([]string, results.Len())
+	for i := range vals {
+		t := results.At(i).Type()
+		if !hasZeroValue(t) {
+			return "", false
+		}
+		vals[i] = zeroValue(t, qf)
+	}
+	return "return " + strings.Join(vals, ", "), true
+}
+
+// hasZeroValue reports whether zeroValue can spell a zero value for t.
+func hasZeroValue(t types.Type) bool {
+	switch u := t.Underlying().(type) {
+	case *types.Array, *types.Struct, *types.Chan, *types.Interface, *types.Map, *types.Pointer, *types.Signature, *types.Slice:
+		return true
+	case *types.Basic:
+		return u.Info()&(types.IsBoolean|types.IsInteger|types.IsFloat|types.IsComplex|types.IsString) != 0
+	default:
+		return false
+	}
+}
+
+// missingWireinjectError reports an injector declared in a file that is not
+// excluded from normal builds by the wireinject build tag, or nil if f has
+// the constraint. Without it, the injector stub and the generated injector
+// are both compiled and collide.
+func missingWireinjectError(fset *token.FileSet, f *ast.File, fn *ast.FuncDecl) error {
+	for _, cg := range f.Comments {
+		if cg.Pos() > f.Package {
+			break
+		}
+		for _, c := range cg.List {
+			if !constraint.IsGoBuild(c.Text) && !constraint.IsPlusBuild(c.Text) {
+				continue
+			}
+			expr, err := constraint.Parse(c.Text)
+			if err != nil {
+				continue
+			}
+			// The file is correctly excluded if it drops out of the build
+			// when wireinject is the only tag that is unset.
+			if !expr.Eval(func(tag string) bool { return tag != "wireinject" }) {
+				return nil
+			}
+		}
+	}
+	start := fset.Position(fset.File(f.Pos()).Pos(0))
+	return &wireErr{
+		error:    fmt.Errorf("inject %s: injector files must be excluded from normal builds with the wireinject build tag", fn.Name.Name),
+		position: fset.Position(fn.Pos()),
+		fixes: []SuggestedFix{{
+			Message: "add a wireinject build constraint to the file",
+			Edits:   []TextEdit{{Pos: start, End: start, NewText: "//go:build wireinject\n\n"}},
+		}},
+	}
+}
+
+// noResultsError reports an injector that returns nothing, or only an
+// error, and has nothing to build for its side effects. If set has a single
+// type that nothing else in the set consumes, it is suggested as the result
+// type.
+func noResultsError(fset *token.FileSet, pkg *packages.Package, fn *ast.FuncDecl, set *ProviderSet) error {
+	what := "has no return values"
+	if fn.Type.Results.NumFields() > 0 {
+		what = "returns only an error"
+	}
+	werr := &wireErr{
+		error:    fmt.Errorf("inject %s: injector %s and builds nothing; return the type it builds, or require the types to build for their side effects with wire.Require", fn.Name.Name, what),
+		position: fset.Position(fn.Pos()),
+	}
+	if set == nil || fn.Type.Results != nil {
+		return werr
+	}
+	root, ok := rootOutput(set)
+	if !ok {
+		return werr
+	}
+	qf := types.RelativeTo(pkg.Types)
+	results := []string{types.TypeString(root.Type(), qf)}
+	if root.IsProvider() {
+		if root.Provider().CleanupErr {
+			results = append(results, "func() error")
+		} else if root.Provider().HasCleanup {
+			results = append(results, "func()")
+		}
+		if root.Provider().HasErr {
+			results = append(results, "error")
+		}
+	}
+	text := " " + results[0]
+	if len(results) > 1 {
+		text = " (" + strings.Join(results, ", ") + ")"
+	}
+	at := fset.Position(fn.Type.Params.Closing + 1)
+	werr.fixes = []SuggestedFix{{
+		Message: fmt.Sprintf("return %s, the only type in the set that no provider consumes", results[0]),
+		Edits:   []TextEdit{{Pos: at, End: at, NewText: text}},
+	}}
+	return werr
+}
+
+// rootOutput returns the single provided type in set that is not an injector
+// argument or an input of any other provider, binding, or field in the set.
+func rootOutput(set *ProviderSet) (ProvidedType, bool) {
+	consumed := make(map[string]bool)
+	outputs := set.Outputs()
+	for _, t := range outputs {
+		pt := set.For(t)
+		switch {
+		case pt.IsProvider():
+			for _, arg := range pt.Provider().Args {
+				consumed[TypeString(arg.Type)] = true
+			}
+		case pt.IsField():
+			consumed[TypeString(pt.Field().Parent)] = true
+		}
+		if !types.Identical(pt.Type(), t) {
+			// t is an interface bound to pt's concrete type.
+			consumed[TypeString(pt.Type())] = true
+		}
+	}
+	var root ProvidedType
+	n := 0
+	for _, t := range outputs {
+		if consumed[TypeString(t)] || set.For(t).IsArg() {
+			continue
+		}
+		root = set.For(t)
+		n++
+	}
+	return root, n == 1
+}
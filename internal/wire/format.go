@@ -0,0 +1,337 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+)
+
+// A FormatResult is the source of a file whose wire.Build or wire.NewSet
+// arguments Format reordered, or whose //go:generate directives GoGenerate
+// updated.
+type FormatResult struct {
+	// Path is the absolute path of the file.
+	Path string
+	// Content is the rewritten source, formatted with gofmt.
+	Content []byte
+}
+
+// Format orders the arguments of every wire.Build, wire.TestBuild,
+// wire.MustBuild and wire.NewSet call in the packages matching patterns
+// canonically: provider sets first, then providers, then bindings, values
+// and requirements, each group sorted alphabetically by its source text. It
+// returns the files whose source changed; writing them is left to the caller.
+//
+// Each argument keeps the comments on the lines directly above it and at the
+// end of its last line. Calls that mix arguments on shared and separate
+// lines, or that contain comments between arguments on a single line, are
+// left alone, as are calls whose last argument is spread with "...".
+func Format(ctx context.Context, wd string, env []string, tags string, patterns []string) ([]FormatResult, []error) {
+	pkgs, loader, errs := load(ctx, wd, env, tags, patterns)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if len(pkgs) == 0 {
+		return nil, nil
+	}
+	fset := newObjectCache(pkgs, loader).fset
+	ec := new(errorCollector)
+	var results []FormatResult
+	for _, pkg := range pkgs {
+		if isWireImport(pkg.PkgPath) {
+			continue
+		}
+		pkgLoader := loader
+		if tagged, tagLoader, _, errs := reloadWithTags(ctx, wd, env, tags, pkg, fset); len(errs) > 0 {
+			ec.add(errs...)
+			continue
+		} else if tagged != nil {
+			pkg, pkgLoader = tagged, tagLoader
+		}
+		oc := newObjectCache([]*packages.Package{pkg}, pkgLoader)
+		if loaded, errs := oc.ensurePackage(pkg.PkgPath); len(errs) > 0 {
+			ec.add(errs...)
+			continue
+		} else if loaded != nil {
+			pkg = loaded
+		}
+		for _, f := range pkg.Syntax {
+			res, err := formatFile(oc.fset, pkg.TypesInfo, f)
+			if err != nil {
+				ec.add(err)
+				continue
+			}
+			if res != nil {
+				results = append(results, *res)
+			}
+		}
+	}
+	if len(ec.errors) > 0 {
+		return nil, ec.errors
+	}
+	return results, nil
+}
+
+// formatFile reorders the wire.Build and wire.NewSet arguments in f. It
+// returns nil if f is already in canonical order.
+func formatFile(fset *token.FileSet, info *types.Info, f *ast.File) (*FormatResult, error) {
+	var calls []*ast.CallExpr
+	ast.Inspect(f, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok && isProviderListCall(info, call) {
+			calls = append(calls, call)
+		}
+		return true
+	})
+	if len(calls) == 0 {
+		return nil, nil
+	}
+	tf := fset.File(f.Pos())
+	src, err := os.ReadFile(tf.Name())
+	if err != nil {
+		return nil, err
+	}
+	if len(src) != tf.Size() {
+		return nil, fmt.Errorf("%s: file changed while formatting", tf.Name())
+	}
+	af := &argFormatter{info: info, tf: tf, file: f, src: src, calls: calls}
+	out := af.render(0, len(src))
+	if out == string(src) {
+		return nil, nil
+	}
+	content, err := format.Source([]byte(out))
+	if err != nil {
+		return nil, fmt.Errorf("%s: formatting reordered source: %v", tf.Name(), err)
+	}
+	if bytes.Equal(content, src) {
+		return nil, nil
+	}
+	return &FormatResult{Path: tf.Name(), Content: content}, nil
+}
+
+// isProviderListCall reports whether call is a call to wire.Build,
+// wire.TestBuild, wire.MustBuild or wire.NewSet.
+func isProviderListCall(info *types.Info, call *ast.CallExpr) bool {
+	obj := qualifiedIdentObject(info, call.Fun)
+	if obj == nil || obj.Pkg() == nil || !isWireImport(obj.Pkg().Path()) {
+		return false
+	}
+	switch obj.Name() {
+	case "Build", "TestBuild", "MustBuild", "NewSet":
+		return true
+	}
+	return false
+}
+
+// Argument groups, in canonical order.
+const (
+	formatGroupSet = iota
+	formatGroupProvider
+	formatGroupBinding
+	formatGroupAfter
+)
+
+// formatGroup returns the group that arg belongs to in canonical order.
+func formatGroup(info *types.Info, arg ast.Expr) int {
+	arg = astutil.Unparen(arg)
+	if tv, ok := info.Types[arg]; ok && isProviderSetType(tv.Type) {
+		return formatGroupSet
+	}
+	if call, ok := arg.(*ast.CallExpr); ok {
+		if obj := qualifiedIdentObject(info, call.Fun); obj != nil && obj.Pkg() != nil && isWireImport(obj.Pkg().Path()) {
+			switch obj.Name() {
+			case "Fakes":
+				return formatGroupSet
+			case "Bind", "Value", "InterfaceValue", "Require":
+				return formatGroupBinding
+			case "After":
+				return formatGroupAfter
+			}
+		}
+	}
+	return formatGroupProvider
+}
+
+// argFormatter rewrites the provider list calls of a single file.
+type argFormatter struct {
+	info  *types.Info
+	tf    *token.File
+	file  *ast.File
+	src   []byte
+	calls []*ast.CallExpr // in source order; enclosing calls come first
+}
+
+func (af *argFormatter) offset(pos token.Pos) int { return af.tf.Offset(pos) }
+func (af *argFormatter) line(pos token.Pos) int   { return af.tf.Line(pos) }
+
+// lineEnd returns the offset just past the newline that ends the line
+// containing off.
+func (af *argFormatter) lineEnd(off int) int {
+	if i := bytes.IndexByte(af.src[off:], '\n'); i >= 0 {
+		return off + i + 1
+	}
+	return len(af.src)
+}
+
+// render returns src[start:end] with every provider list call it contains
+// rewritten.
+func (af *argFormatter) render(start, end int) string {
+	var sb strings.Builder
+	pos := start
+	for _, call := range af.calls {
+		cs, ce := af.offset(call.Pos()), af.offset(call.End())
+		if cs < pos || ce > end {
+			// Outside the range, or nested in a call already rendered.
+			continue
+		}
+		sb.Write(af.src[pos:cs])
+		sb.WriteString(af.rewrite(call))
+		pos = ce
+	}
+	sb.Write(af.src[pos:end])
+	return sb.String()
+}
+
+// rewrite returns the source of call with its arguments in canonical order.
+func (af *argFormatter) rewrite(call *ast.CallExpr) string {
+	head := string(af.src[af.offset(call.Pos()) : af.offset(call.Lparen)+1])
+	lparen, rparen := af.offset(call.Lparen)+1, af.offset(call.Rparen)
+	unchanged := head + af.render(lparen, rparen) + ")"
+	args := call.Args
+	if len(args) < 2 || call.Ellipsis.IsValid() {
+		return unchanged
+	}
+	order := af.canonicalOrder(args)
+	if order == nil {
+		return unchanged
+	}
+
+	if af.line(args[0].Pos()) == af.line(args[len(args)-1].End()) {
+		// All arguments share a line: join them with ", ".
+		if af.hasComments(args[0].Pos(), args[len(args)-1].End()) {
+			return unchanged
+		}
+		var sb strings.Builder
+		sb.WriteString(head)
+		sb.WriteString(af.render(lparen, af.offset(args[0].Pos())))
+		for i, j := range order {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(af.render(af.offset(args[j].Pos()), af.offset(args[j].End())))
+		}
+		sb.WriteString(af.render(af.offset(args[len(args)-1].End()), rparen))
+		sb.WriteString(")")
+		return sb.String()
+	}
+
+	// Otherwise each argument must start on a line of its own, with the
+	// closing parenthesis on a later line. An argument's segment runs from
+	// the line after the previous argument to the end of its own last line,
+	// taking its comments along with it.
+	if af.line(call.Lparen) == af.line(args[0].Pos()) || af.line(call.Rparen) == af.line(args[len(args)-1].End()) {
+		return unchanged
+	}
+	bounds := make([]int, len(args)+1)
+	bounds[0] = af.lineEnd(lparen - 1)
+	for i, arg := range args {
+		if i > 0 && af.line(arg.Pos()) == af.line(args[i-1].End()) {
+			return unchanged
+		}
+		end := af.offset(arg.End())
+		if rest := bytes.TrimLeft(af.src[end:af.lineEnd(end)], " \t"); len(rest) == 0 || rest[0] != ',' {
+			return unchanged
+		}
+		bounds[i+1] = af.lineEnd(end)
+	}
+	segments := make([]string, len(args))
+	blankLines := false
+	for i := range args {
+		seg := af.render(bounds[i], bounds[i+1])
+		trimmed := seg
+		for {
+			j := strings.IndexByte(trimmed, '\n')
+			if j < 0 || strings.TrimSpace(trimmed[:j]) != "" {
+				break
+			}
+			trimmed = trimmed[j+1:]
+		}
+		if i > 0 && trimmed != seg {
+			blankLines = true
+		}
+		segments[i] = trimmed
+	}
+	var sb strings.Builder
+	sb.WriteString(head)
+	sb.WriteString(af.render(lparen, bounds[0]))
+	for i, j := range order {
+		if blankLines && i > 0 && formatGroup(af.info, args[j]) != formatGroup(af.info, args[order[i-1]]) {
+			// Keep groups apart if the call used blank lines to
+			// separate its arguments.
+			sb.WriteString("\n")
+		}
+		sb.WriteString(segments[j])
+	}
+	sb.WriteString(af.render(bounds[len(args)], rparen))
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// canonicalOrder returns the indices of args in canonical order, or nil if
+// they are already in it.
+func (af *argFormatter) canonicalOrder(args []ast.Expr) []int {
+	order := make([]int, len(args))
+	groups := make([]int, len(args))
+	keys := make([]string, len(args))
+	for i, arg := range args {
+		order[i] = i
+		groups[i] = formatGroup(af.info, arg)
+		keys[i] = types.ExprString(arg)
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		i, j := order[a], order[b]
+		if groups[i] != groups[j] {
+			return groups[i] < groups[j]
+		}
+		return keys[i] < keys[j]
+	})
+	for i, j := range order {
+		if i != j {
+			return order
+		}
+	}
+	return nil
+}
+
+// hasComments reports whether any comment lies between start and end.
+func (af *argFormatter) hasComments(start, end token.Pos) bool {
+	for _, cg := range af.file.Comments {
+		if cg.Pos() < end && cg.End() > start {
+			return true
+		}
+	}
+	return false
+}
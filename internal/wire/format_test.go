@@ -0,0 +1,121 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), `package app
+
+import "github.com/goforj/wire"
+
+type (
+	DB    struct{}
+	Cache struct{}
+	Store interface{}
+	Cfg   string
+	App   struct{}
+)
+
+func NewDB() *DB                          { return nil }
+func NewCache() *Cache                    { return nil }
+func NewApp(*DB, *Cache, Store, Cfg) *App { return nil }
+
+var Inner = wire.NewSet(NewDB, NewCache)
+
+var Set = wire.NewSet(
+	// Storage.
+	wire.Bind(new(Store), new(*DB)),
+	NewDB, // primary database
+
+	wire.Value(Cfg("x")),
+	wire.NewSet(NewDB, NewApp),
+	NewCache,
+)
+`)
+	writeFile(t, filepath.Join(root, "app", "wire.go"), `//go:build wireinject
+
+package app
+
+import "github.com/goforj/wire"
+
+func InitApp() *App {
+	panic(wire.Build(wire.Value(Cfg("x")), NewApp, wire.Bind(new(Store), new(*DB)), Inner))
+}
+
+func InitDB() *DB {
+	panic(wire.Build(NewDB /* the only one */, Inner))
+}
+`)
+	writeFile(t, filepath.Join(root, "app", "sorted.go"), `package app
+
+import "github.com/goforj/wire"
+
+var Sorted = wire.NewSet(Inner, NewApp, wire.Value(Cfg("y")))
+`)
+
+	env := append(os.Environ(), "GOWORK=off")
+	results, errs := Format(context.Background(), root, env, "", []string{"./app"})
+	if len(errs) > 0 {
+		t.Fatalf("Format failed: %v", errs)
+	}
+	want := map[string]string{
+		"app.go": `var Inner = wire.NewSet(NewCache, NewDB)
+
+var Set = wire.NewSet(
+	wire.NewSet(NewApp, NewDB),
+
+	NewCache,
+	NewDB, // primary database
+
+	// Storage.
+	wire.Bind(new(Store), new(*DB)),
+	wire.Value(Cfg("x")),
+)
+`,
+		"wire.go": `func InitApp() *App {
+	panic(wire.Build(Inner, NewApp, wire.Bind(new(Store), new(*DB)), wire.Value(Cfg("x"))))
+}
+
+func InitDB() *DB {
+	panic(wire.Build(NewDB /* the only one */, Inner))
+}
+`,
+	}
+	if len(results) != len(want) {
+		t.Fatalf("Format returned %d files; want %d", len(results), len(want))
+	}
+	for _, res := range results {
+		name := filepath.Base(res.Path)
+		if !strings.HasSuffix(string(res.Content), want[name]) {
+			t.Errorf("%s formatted to:\n%s\nwant it to end with:\n%s", name, res.Content, want[name])
+		}
+		writeFile(t, res.Path, string(res.Content))
+	}
+
+	// Formatting is idempotent.
+	results, errs = Format(context.Background(), root, env, "", []string{"./app"})
+	if len(errs) > 0 || len(results) > 0 {
+		t.Errorf("second Format returned %d files, %v; want none", len(results), errs)
+	}
+}
@@ -18,15 +18,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"go/build/constraint"
 	"go/format"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"golang.org/x/tools/go/packages"
 )
 
 // generateForPackage runs Wire code generation for a single package.
-func generateForPackage(ctx context.Context, pkg *packages.Package, loader *lazyLoader, opts *GenerateOptions) GenerateResult {
+func generateForPackage(ctx context.Context, pkg *packages.Package, loader *lazyLoader, opts *GenerateOptions, shared *sharedLoad) GenerateResult {
 	if opts == nil {
 		opts = &GenerateOptions{}
 	}
@@ -38,37 +40,116 @@ func generateForPackage(ctx context.Context, pkg *packages.Package, loader *lazy
 	outDir, err := detectOutputDir(pkg.GoFiles)
 	logTiming(ctx, "generate.package."+pkg.PkgPath+".output_dir", dirStart)
 	if err != nil {
-		res.Errs = append(res.Errs, err)
+		res.Errs.add(pkg.PkgPath, err)
 		return res
 	}
-	res.OutputPath = filepath.Join(outDir, opts.PrefixOutputFile+"wire_gen.go")
-	cacheKey, err := cacheKeyForPackage(pkg, opts)
+	name, err := outputFileName(pkg, opts)
 	if err != nil {
-		res.Errs = append(res.Errs, err)
+		res.Errs.add(pkg.PkgPath, err)
 		return res
 	}
+	res.OutputPath = filepath.Join(outDir, name)
+	tests := isTestVariant(pkg)
+	examples := opts.Examples && !tests
+	if examples {
+		res.ExamplePath = filepath.Join(outDir, opts.PrefixOutputFile+examplesFileName)
+	}
+	// Injectors redeclared under other build constraints leave the output
+	// valid for this configuration only. The constraint derives from the
+	// included files alone, which the cache key covers.
+	var conflictExpr constraint.Expr
+	if !tests {
+		if conflicts := injectorConflicts(pkg); len(conflicts) > 0 {
+			conflictExpr = conflictConstraint(pkg)
+			res.Warnings = conflictWarnings(pkg.PkgPath, conflicts, conflictExpr)
+		}
+	}
+	var cacheKey string
+	var hit *cacheHit
+	lookedUp := false
+	if len(opts.Injectors) == 0 && len(opts.Overlay) == 0 && !tests {
+		if key, h, ok := shared.cacheKey(pkg.PkgPath); ok {
+			cacheKey, hit, lookedUp = key, h, true
+		} else {
+			keyStart := time.Now()
+			cacheKey, err = cacheKeyForPackage(pkg, opts)
+			logTiming(ctx, "generate.package."+pkg.PkgPath+".cache_key", keyStart)
+			if err != nil {
+				res.Errs.add(pkg.PkgPath, err)
+				return res
+			}
+		}
+		if debugLog(ctx) != nil {
+			debugf(ctx, "%s: cache key %s from %d files, tags %q, prefix %q, header hash %q, auto_context %t",
+				pkg.PkgPath, cacheKey, len(packageFiles(pkg)), opts.Tags, opts.PrefixOutputFile, headerHash(opts.Header), opts.AutoContext)
+		}
+	}
 	if cacheKey != "" {
 		cacheHitStart := time.Now()
-		if cached, ok := readCache(cacheKey); ok {
-			res.Content = cached
+		if !lookedUp {
+			hit = readCacheHit(cacheKey, opts)
+		}
+		if hit != nil {
+			debugf(ctx, "%s: cache hit", pkg.PkgPath)
+			res.Content = hit.content
+			res.ExampleContent = hit.exampleContent
+			res.Cached = true
+			res.Skipped = len(hit.content) == 0
 			logTiming(ctx, "generate.package."+pkg.PkgPath+".cache_hit", cacheHitStart)
 			logTiming(ctx, "generate.package."+pkg.PkgPath+".total", pkgStart)
 			return res
 		}
 	}
-	oc := newObjectCache([]*packages.Package{pkg}, loader)
+	if cacheKey != "" {
+		debugf(ctx, "%s: cache miss", pkg.PkgPath)
+	}
+	var oc *objectCache
+	if !tests {
+		oc = shared.objectCache(ctx, pkg.PkgPath)
+	}
+	if oc == nil {
+		oc = newObjectCache([]*packages.Package{pkg}, loader)
+		oc.autoContext = opts.AutoContext
+		oc.stableOrder = opts.StableOrder
+		oc.autoNames = autoNamesRegexp(opts.AutoNames)
+	}
 	if loaded, errs := oc.ensurePackage(pkg.PkgPath); len(errs) > 0 {
-		res.Errs = append(res.Errs, errs...)
+		res.Errs.add(pkg.PkgPath, errs...)
 		return res
 	} else if loaded != nil {
 		pkg = loaded
 	}
 	g := newGen(pkg)
+	g.tests = tests
+	g.constraint = conflictExpr
+	g.partial = opts.Partial
+	if opts.SourceMap {
+		g.sourceDir = filepath.Dir(res.OutputPath)
+	}
+	if len(opts.Injectors) > 0 {
+		g.only = make(map[string]bool, len(opts.Injectors))
+		for _, name := range opts.Injectors {
+			g.only[name] = true
+		}
+	}
+	if g.only != nil || g.partial {
+		if g.prev, err = readPreviousOutput(pkg, res.OutputPath); err != nil {
+			res.Errs.add(pkg.PkgPath, err)
+			return res
+		}
+	}
 	injectorStart := time.Now()
 	injectorFiles, errs := generateInjectors(oc, g, pkg)
 	logTiming(ctx, "generate.package."+pkg.PkgPath+".injectors", injectorStart)
 	if len(errs) > 0 {
-		res.Errs = errs
+		res.Errs.add(pkg.PkgPath, errs...)
+		if !g.partial || g.emitted == 0 {
+			return res
+		}
+	}
+	if g.only != nil && g.regenerated == 0 {
+		// None of the requested injectors are in this package.
+		res.Skipped = true
 		return res
 	}
 	copyStart := time.Now()
@@ -77,27 +158,91 @@ func generateForPackage(ctx context.Context, pkg *packages.Package, loader *lazy
 	frameStart := time.Now()
 	goSrc := g.frame(opts.Tags)
 	logTiming(ctx, "generate.package."+pkg.PkgPath+".frame", frameStart)
-	if len(opts.Header) > 0 {
+	if goSrc == nil {
+		// The package declares no injectors, so it gets no file, not even
+		// one holding just the header.
+		res.Skipped = true
+	} else if len(opts.Header) > 0 {
 		goSrc = append(opts.Header, goSrc...)
 	}
 	formatStart := time.Now()
-	fmtSrc, err := format.Source(goSrc)
+	fmtSrc, err := formatSource(goSrc, res.OutputPath, opts.DebugDir)
 	logTiming(ctx, "generate.package."+pkg.PkgPath+".format", formatStart)
 	if err != nil {
 		// This is likely a bug from a poorly generated source file.
 		// Add an error but also the unformatted source.
-		res.Errs = append(res.Errs, err)
+		res.Errs.add(pkg.PkgPath, err)
 	} else {
 		goSrc = fmtSrc
 	}
 	res.Content = goSrc
+	if examples {
+		examplesStart := time.Now()
+		exampleSrc := generateExamples(pkg, opts.Header, opts.Tags)
+		if exampleSrc != nil {
+			fmtSrc, err := formatSource(exampleSrc, res.ExamplePath, opts.DebugDir)
+			if err != nil {
+				res.Errs.add(pkg.PkgPath, err)
+			} else {
+				exampleSrc = fmtSrc
+			}
+		}
+		logTiming(ctx, "generate.package."+pkg.PkgPath+".examples", examplesStart)
+		res.ExampleContent = exampleSrc
+	}
 	if cacheKey != "" && len(res.Errs) == 0 {
+		cacheWriteStart := time.Now()
 		writeCache(cacheKey, res.Content)
+		if examples {
+			writeCache(examplesCacheKey(cacheKey), res.ExampleContent)
+		}
+		logTiming(ctx, "generate.package."+pkg.PkgPath+".cache_write", cacheWriteStart)
 	}
 	logTiming(ctx, "generate.package."+pkg.PkgPath+".total", pkgStart)
 	return res
 }
 
+// formatSource gofmts the source generated for outputPath. If that fails,
+// the unformatted source is saved to debugDir, or to the temporary directory
+// if debugDir is empty, and the returned error points into the saved file so
+// that the generator bug can be inspected and reported.
+func formatSource(src []byte, outputPath string, debugDir string) ([]byte, error) {
+	out, err := format.Source(src)
+	if err == nil {
+		return out, nil
+	}
+	name := filepath.Base(outputPath)
+	path, dumpErr := saveUnformatted(src, name, debugDir)
+	if dumpErr != nil {
+		return nil, withCode(CodeFormat, fmt.Errorf("generated %s is not valid Go source (could not save it: %v): %v", name, dumpErr, err))
+	}
+	return nil, withCode(CodeFormat, fmt.Errorf("generated %s is not valid Go source: %s:%v", name, path, err))
+}
+
+// saveUnformatted writes src to a new file in dir, or in a wire directory
+// under the temporary directory if dir is empty, and returns its path.
+func saveUnformatted(src []byte, name string, dir string) (string, error) {
+	if dir == "" {
+		dir = filepath.Join(osTempDir(), "wire-debug")
+	}
+	if err := osMkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	f, err := osCreateTemp(dir, strings.TrimSuffix(name, ".go")+"-*.go")
+	if err != nil {
+		return "", err
+	}
+	_, writeErr := f.Write(src)
+	closeErr := f.Close()
+	if writeErr != nil {
+		return "", writeErr
+	}
+	if closeErr != nil {
+		return "", closeErr
+	}
+	return f.Name(), nil
+}
+
 // allGeneratedOK reports whether every package result succeeded.
 func allGeneratedOK(results []GenerateResult) bool {
 	if len(results) == 0 {
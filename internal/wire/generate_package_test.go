@@ -15,9 +15,11 @@
 package wire
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -25,7 +27,7 @@ import (
 )
 
 func TestGenerateForPackageOptionAndDetectErrors(t *testing.T) {
-	res := generateForPackage(context.Background(), &packages.Package{PkgPath: "example.com/empty"}, nil, nil)
+	res := generateForPackage(context.Background(), &packages.Package{PkgPath: "example.com/empty"}, nil, nil, nil)
 	if len(res.Errs) == 0 {
 		t.Fatal("expected error for empty package")
 	}
@@ -34,6 +36,42 @@ func TestGenerateForPackageOptionAndDetectErrors(t *testing.T) {
 	}
 }
 
+func TestOutputFileName(t *testing.T) {
+	tests := []struct {
+		prefix, tmpl, pkgName string
+		want                  string // empty if an error is expected
+	}{
+		{"", "", "app", "wire_gen.go"},
+		{"gen_", "", "app", "gen_wire_gen.go"},
+		{"", "{{.Package}}_wire.gen.go", "app", "app_wire.gen.go"},
+		{"x_", "{{.Package}}_gen.go", "app", "x_app_gen.go"},
+		{"", "{{.Package}", "app", ""},
+		{"", "{{.Missing}}.go", "app", ""},
+		{"", "{{.Package}}/wire_gen.go", "app", ""},
+		{"", "{{.Package}}_gen_test.go", "app", ""},
+		{"", "{{.Package}}.txt", "app", ""},
+		{"", "_{{.Package}}.go", "app", ""},
+	}
+	for _, test := range tests {
+		got, err := outputFileNameFor(test.prefix, test.tmpl, test.pkgName)
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("outputFileNameFor(%q, %q, %q) = %q; want error", test.prefix, test.tmpl, test.pkgName, got)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("outputFileNameFor(%q, %q, %q) = %q, %v; want %q", test.prefix, test.tmpl, test.pkgName, got, err, test.want)
+		}
+	}
+	if got := testOutputFileName("app_wire.gen.go", false); got != "app_wire.gen_test.go" {
+		t.Errorf("testOutputFileName = %q; want app_wire.gen_test.go", got)
+	}
+	if got := testOutputFileName("wire_gen.go", true); got != "wire_gen_external_test.go" {
+		t.Errorf("testOutputFileName external = %q; want wire_gen_external_test.go", got)
+	}
+}
+
 func TestGenerateForPackageCacheKeyError(t *testing.T) {
 	tempDir := t.TempDir()
 	missing := filepath.Join(tempDir, "missing.go")
@@ -41,7 +79,7 @@ func TestGenerateForPackageCacheKeyError(t *testing.T) {
 		PkgPath: "example.com/missing",
 		GoFiles: []string{missing},
 	}
-	res := generateForPackage(context.Background(), pkg, nil, &GenerateOptions{})
+	res := generateForPackage(context.Background(), pkg, nil, &GenerateOptions{}, nil)
 	if len(res.Errs) == 0 {
 		t.Fatal("expected cache key error")
 	}
@@ -66,7 +104,7 @@ func TestGenerateForPackageCacheHit(t *testing.T) {
 		t.Fatalf("cacheKeyForPackage failed: %v", err)
 	}
 	writeCache(key, []byte("cached"))
-	res := generateForPackage(context.Background(), pkg, nil, opts)
+	res := generateForPackage(context.Background(), pkg, nil, opts, nil)
 	if string(res.Content) != "cached" {
 		t.Fatalf("expected cached content, got %q", res.Content)
 	}
@@ -117,10 +155,26 @@ func TestGenerateForPackageFormatError(t *testing.T) {
 	if len(errs) > 0 || len(pkgs) != 1 {
 		t.Fatalf("load errors: %v", errs)
 	}
-	opts := &GenerateOptions{Header: []byte("invalid")}
-	res := generateForPackage(ctx, pkgs[0], loader, opts)
-	if len(res.Errs) == 0 {
-		t.Fatal("expected format.Source error")
+	debugDir := filepath.Join(tempDir, "debug")
+	for _, dir := range []string{"", debugDir} {
+		opts := &GenerateOptions{Header: []byte("invalid"), DebugDir: dir}
+		res := generateForPackage(ctx, pkgs[0], loader, opts, nil)
+		if len(res.Errs) == 0 {
+			t.Fatal("expected format.Source error")
+		}
+		if dir == "" {
+			dir = filepath.Join(tempDir, "wire-debug")
+		}
+		saved, err := filepath.Glob(filepath.Join(dir, "wire_gen-*.go"))
+		if err != nil || len(saved) != 1 {
+			t.Fatalf("saved sources in %s = %v, %v; want one file", dir, saved, err)
+		}
+		if !strings.Contains(res.Errs[0].Error(), saved[0]+":") {
+			t.Errorf("error %q does not point into %s", res.Errs[0], saved[0])
+		}
+		if data, err := os.ReadFile(saved[0]); err != nil || !bytes.Equal(data, res.Content) {
+			t.Errorf("saved source does not match the unformatted output (err=%v)", err)
+		}
 	}
 }
 
@@ -128,10 +182,625 @@ func TestAllGeneratedOK(t *testing.T) {
 	if allGeneratedOK(nil) {
 		t.Fatal("expected empty results to be false")
 	}
-	if allGeneratedOK([]GenerateResult{{Errs: []error{context.DeadlineExceeded}}}) {
+	if allGeneratedOK([]GenerateResult{{Errs: newErrorList("", []error{context.DeadlineExceeded})}}) {
 		t.Fatal("expected errors to be false")
 	}
 	if !allGeneratedOK([]GenerateResult{{}}) {
 		t.Fatal("expected success results to be true")
 	}
 }
+
+func TestGenerateForPackageSharedLoad(t *testing.T) {
+	lockCacheHooks(t)
+	state := saveCacheHooks()
+	t.Cleanup(func() { restoreCacheHooks(state) })
+
+	tempDir := t.TempDir()
+	osTempDir = func() string { return tempDir }
+
+	repoRoot := mustRepoRoot(t)
+	writeTempFile(t, tempDir, "go.mod", strings.Join([]string{
+		"module example.com/app",
+		"",
+		"go 1.19",
+		"",
+		"require github.com/goforj/wire v0.0.0",
+		"replace github.com/goforj/wire => " + repoRoot,
+		"",
+	}, "\n"))
+	platformDir := filepath.Join(tempDir, "platform")
+	if err := os.MkdirAll(platformDir, 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	writeTempFile(t, platformDir, "platform.go", strings.Join([]string{
+		"package platform",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"type Logger struct{}",
+		"",
+		"func NewLogger() *Logger { return &Logger{} }",
+		"",
+		"var Set = wire.NewSet(NewLogger)",
+		"",
+	}, "\n"))
+	for _, name := range []string{"a", "b"} {
+		dir := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("MkdirAll failed: %v", err)
+		}
+		writeTempFile(t, dir, "wire.go", strings.Join([]string{
+			"//go:build wireinject",
+			"// +build wireinject",
+			"",
+			"package " + name,
+			"",
+			"import (",
+			"\t\"example.com/app/platform\"",
+			"\t\"github.com/goforj/wire\"",
+			")",
+			"",
+			"func Init() *platform.Logger {",
+			"\twire.Build(platform.Set)",
+			"\treturn nil",
+			"}",
+			"",
+		}, "\n"))
+	}
+
+	ctx := context.Background()
+	env := append(os.Environ(), "GOWORK=off")
+	pkgs, loader, errs := load(ctx, tempDir, env, "", []string{"./a", "./b"})
+	if len(errs) > 0 || len(pkgs) != 2 {
+		t.Fatalf("load errors: %v", errs)
+	}
+	opts := &GenerateOptions{}
+	shared := newSharedLoad(pkgs, loader, opts)
+	oc := shared.objectCache(ctx, pkgs[0].PkgPath)
+	if oc == nil || shared.objectCache(ctx, pkgs[1].PkgPath) != oc {
+		t.Fatal("packages that miss the cache do not share an object cache")
+	}
+	for _, pkg := range pkgs {
+		if _, hit, ok := shared.cacheKey(pkg.PkgPath); !ok || hit != nil {
+			t.Errorf("%s: cache key recorded = %t, hit = %v; want a recorded miss", pkg.PkgPath, ok, hit)
+		}
+		res := generateForPackage(ctx, pkg, loader, opts, shared)
+		if len(res.Errs) > 0 || len(res.Content) == 0 {
+			t.Fatalf("%s: generate failed: %v", pkg.PkgPath, res.Errs)
+		}
+	}
+	if _, ok := oc.objects[objRef{importPath: "example.com/app/platform", name: "Set"}]; !ok {
+		t.Error("platform.Set was not processed in the shared object cache")
+	}
+
+	// The outputs read while looking for misses are not read again.
+	shared = newSharedLoad(pkgs, loader, opts)
+	shared.prepare()
+	readFile := osReadFile
+	osReadFile = func(name string) ([]byte, error) {
+		if strings.HasPrefix(name, cacheDir()) {
+			t.Errorf("cache read again: %s", name)
+		}
+		return readFile(name)
+	}
+	for _, pkg := range pkgs {
+		res := generateForPackage(ctx, pkg, loader, opts, shared)
+		if !res.Cached || len(res.Content) == 0 {
+			t.Errorf("%s: Cached = %t with %d bytes; want the cached output", pkg.PkgPath, res.Cached, len(res.Content))
+		}
+	}
+	osReadFile = readFile
+
+	var nilShared *sharedLoad
+	if _, _, ok := nilShared.cacheKey(pkgs[0].PkgPath); ok || nilShared.objectCache(ctx, pkgs[0].PkgPath) != nil {
+		t.Error("nil sharedLoad shares state")
+	}
+	if newSharedLoad(pkgs[:1], loader, opts) != nil {
+		t.Error("newSharedLoad of a single package is not nil")
+	}
+}
+
+func TestGenerateForPackageSharedLoadPartial(t *testing.T) {
+	lockCacheHooks(t)
+	state := saveCacheHooks()
+	t.Cleanup(func() { restoreCacheHooks(state) })
+
+	tempDir := t.TempDir()
+	osTempDir = func() string { return tempDir }
+	writeAppModule(t, tempDir)
+	for _, name := range []string{"a", "b", "broken"} {
+		body := "\treturn &Foo{}"
+		if name == "broken" {
+			body = "\treturn undefined"
+		}
+		writeFile(t, filepath.Join(tempDir, name, "foo.go"), strings.Join([]string{
+			"package " + name,
+			"",
+			"type Foo struct{}",
+			"",
+			"func NewFoo() *Foo {",
+			body,
+			"}",
+			"",
+		}, "\n"))
+		writeFile(t, filepath.Join(tempDir, name, "wire.go"), strings.Join([]string{
+			"//go:build wireinject",
+			"// +build wireinject",
+			"",
+			"package " + name,
+			"",
+			"import \"github.com/goforj/wire\"",
+			"",
+			"func Init() *Foo {",
+			"\twire.Build(NewFoo)",
+			"\treturn nil",
+			"}",
+			"",
+		}, "\n"))
+	}
+
+	ctx := context.Background()
+	env := append(os.Environ(), "GOWORK=off")
+	pkgs, loader, errs := load(ctx, tempDir, env, "", []string{"./a", "./b", "./broken"})
+	if len(errs) > 0 || len(pkgs) != 3 {
+		t.Fatalf("load errors: %v", errs)
+	}
+	opts := &GenerateOptions{}
+	shared := newSharedLoad(pkgs, loader, opts)
+	oc := shared.objectCache(ctx, "example.com/app/a")
+	if oc == nil || shared.objectCache(ctx, "example.com/app/b") != oc {
+		t.Fatal("packages that loaded do not share an object cache")
+	}
+	if shared.objectCache(ctx, "example.com/app/broken") != nil {
+		t.Error("package that failed to load shares the object cache")
+	}
+	for _, pkg := range pkgs {
+		res := generateForPackage(ctx, pkg, loader, opts, shared)
+		if pkg.PkgPath == "example.com/app/broken" {
+			if len(res.Errs) == 0 || !strings.Contains(res.Errs[0].Msg, "undefined") {
+				t.Errorf("broken: errors = %v; want its type error", res.Errs)
+			}
+			continue
+		}
+		if len(res.Errs) > 0 || len(res.Content) == 0 {
+			t.Errorf("%s: generate failed: %v", pkg.PkgPath, res.Errs)
+		}
+	}
+}
+
+func TestGenerateSkipped(t *testing.T) {
+	lockCacheHooks(t)
+	state := saveCacheHooks()
+	t.Cleanup(func() { restoreCacheHooks(state) })
+
+	tempDir := t.TempDir()
+	osTempDir = func() string { return tempDir }
+
+	writeAppModule(t, tempDir)
+	writeFile(t, filepath.Join(tempDir, "plain", "plain.go"), "package plain\n\ntype Thing struct{}\n")
+	writeFile(t, filepath.Join(tempDir, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package app",
+		"",
+		"import (",
+		"\t\"example.com/app/plain\"",
+		"\t\"github.com/goforj/wire\"",
+		")",
+		"",
+		"func Init() plain.Thing {",
+		"\tpanic(wire.Build(wire.Value(plain.Thing{})))",
+		"}",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(tempDir, "broken", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package broken",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func Init() int {",
+		"\tpanic(wire.Build())",
+		"}",
+		"",
+	}, "\n"))
+
+	ctx := context.Background()
+	env := append(os.Environ(), "GOWORK=off")
+	skipped := func(opts *GenerateOptions) map[string]bool {
+		t.Helper()
+		gens, errs := Generate(ctx, tempDir, env, []string{"./..."}, opts)
+		if len(errs) > 0 {
+			t.Fatalf("Generate failed: %v", errs)
+		}
+		got := make(map[string]bool)
+		for _, gen := range gens {
+			if gen.Skipped && (len(gen.Content) > 0 || len(gen.Errs) > 0) {
+				t.Errorf("%s is skipped but has content %q and errors %v", gen.PkgPath, gen.Content, gen.Errs)
+			}
+			if !gen.Skipped && len(gen.Content) == 0 && len(gen.Errs) == 0 {
+				t.Errorf("%s has neither content nor errors but is not skipped", gen.PkgPath)
+			}
+			got[gen.PkgPath] = gen.Skipped
+		}
+		return got
+	}
+	header := []byte("// License.\n\n")
+	want := map[string]bool{"example.com/app/plain": true, "example.com/app/app": false, "example.com/app/broken": false}
+	// The second run reads the cache.
+	for run := 0; run < 2; run++ {
+		if got := skipped(&GenerateOptions{Header: header}); !reflect.DeepEqual(got, want) {
+			t.Errorf("run %d: Skipped = %v; want %v", run, got, want)
+		}
+	}
+	// Injectors that are not selected are still checked if there is no
+	// previous output to keep, so broken still fails.
+	want = map[string]bool{"example.com/app/plain": true, "example.com/app/app": true, "example.com/app/broken": false}
+	if got := skipped(&GenerateOptions{Injectors: []string{"Other"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("with other injectors: Skipped = %v; want %v", got, want)
+	}
+}
+
+func TestGenerateAutoContext(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"import (",
+		"\t\"context\"",
+		"",
+		"\t\"github.com/goforj/wire\"",
+		")",
+		"",
+		"type Svc struct{}",
+		"",
+		"func NewSvc(ctx context.Context) *Svc { return &Svc{} }",
+		"",
+		"func DefaultContext() context.Context { return context.Background() }",
+		"",
+		"var Set = wire.NewSet(NewSvc, DefaultContext)",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"// +build wireinject",
+		"",
+		"package app",
+		"",
+		"import (",
+		"\t\"context\"",
+		"",
+		"\t\"github.com/goforj/wire\"",
+		")",
+		"",
+		"func Init(ctx context.Context) *Svc {",
+		"\twire.Build(Set)",
+		"\treturn nil",
+		"}",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+
+	gens, errs := Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("Generate returned errors: %v", errs)
+	}
+	if len(gens) != 1 || len(gens[0].Errs) == 0 {
+		t.Fatal("expected a conflicting context.Context binding without AutoContext")
+	}
+
+	gens, errs = Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{AutoContext: true})
+	if len(errs) > 0 {
+		t.Fatalf("Generate returned errors: %v", errs)
+	}
+	if len(gens) != 1 || len(gens[0].Errs) > 0 {
+		t.Fatalf("Generate with AutoContext failed: %+v", gens)
+	}
+	if got := string(gens[0].Content); !strings.Contains(got, "NewSvc(ctx)") || strings.Contains(got, "DefaultContext()") {
+		t.Fatalf("generated injector does not pass ctx through:\n%s", got)
+	}
+}
+
+func TestGenerateStableOrder(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"type Zeta struct{}",
+		"",
+		"type Alpha struct{}",
+		"",
+		"type Beta struct{}",
+		"",
+		"type App struct{}",
+		"",
+		"func NewZeta() *Zeta { return &Zeta{} }",
+		"",
+		"func NewAlpha(z *Zeta) *Alpha { return &Alpha{} }",
+		"",
+		"func NewBeta() *Beta { return &Beta{} }",
+		"",
+		"func NewApp(z *Zeta, b *Beta, a *Alpha) *App { return &App{} }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func Init() *App {",
+		"\tpanic(wire.Build(NewApp, NewZeta, NewAlpha, NewBeta))",
+		"}",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	for _, test := range []struct {
+		stable bool
+		want   []string
+	}{
+		{false, []string{"NewZeta()", "NewBeta()", "NewAlpha(", "NewApp("}},
+		// Alpha must still follow Zeta, which it depends on.
+		{true, []string{"NewBeta()", "NewZeta()", "NewAlpha(", "NewApp("}},
+	} {
+		gens, errs := Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{StableOrder: test.stable})
+		if len(errs) > 0 || len(gens) != 1 || len(gens[0].Errs) > 0 {
+			t.Fatalf("Generate with StableOrder=%t failed: %v %+v", test.stable, errs, gens)
+		}
+		got := string(gens[0].Content)
+		last := -1
+		for _, call := range test.want {
+			i := strings.Index(got, call)
+			if i <= last {
+				t.Errorf("Generate with StableOrder=%t does not call %s in the order %v:\n%s", test.stable, call, test.want, got)
+				break
+			}
+			last = i
+		}
+	}
+}
+
+func TestGenerateSourceMap(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "db", "db.go"), strings.Join([]string{
+		"package db",
+		"",
+		"type Config struct{ DSN string }",
+		"",
+		"type DB struct{}",
+		"",
+		"func NewDB(dsn string) *DB { return &DB{} }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package app",
+		"",
+		"import (",
+		"\t\"example.com/app/db\"",
+		"\t\"github.com/goforj/wire\"",
+		")",
+		"",
+		"func Init() *db.DB {",
+		"\tpanic(wire.Build(",
+		"\t\twire.Value(db.Config{}),",
+		"\t\twire.FieldsOf(new(db.Config), \"DSN\"),",
+		"\t\tdb.NewDB,",
+		"\t))",
+		"}",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	for _, sourceMap := range []bool{false, true} {
+		gens, errs := Generate(context.Background(), root, env, []string{"./app"}, &GenerateOptions{SourceMap: sourceMap})
+		if len(errs) > 0 {
+			t.Fatalf("Generate failed: %v", errs)
+		}
+		if len(gens) != 1 || len(gens[0].Errs) > 0 {
+			t.Fatalf("Generate returned %+v; want one result without errors", gens)
+		}
+		content := string(gens[0].Content)
+		if !sourceMap {
+			if strings.Contains(content, "//wire:source") {
+				t.Errorf("Generate without SourceMap emitted source comments:\n%s", content)
+			}
+			continue
+		}
+		// Each comment must directly precede the statement it maps.
+		for _, want := range []struct{ comment, next string }{
+			{"//wire:source wire.go:12:14 wire.Value", "= _wireConfigValue"},
+			{"//wire:source ../db/db.go:3:21 wire.FieldsOf example.com/app/db.Config.DSN", ".DSN"},
+			{"//wire:source ../db/db.go:7:6 example.com/app/db.NewDB", "db.NewDB("},
+		} {
+			i := strings.Index(content, "\t"+want.comment+"\n")
+			if i < 0 {
+				t.Errorf("Generate with SourceMap output lacks %q:\n%s", want.comment, content)
+				continue
+			}
+			next := strings.SplitN(content[i:], "\n", 3)[1]
+			if !strings.Contains(next, want.next) {
+				t.Errorf("Generate with SourceMap output has %q before %q; want a line containing %q", want.comment, next, want.next)
+			}
+		}
+	}
+}
+
+func TestGenerateSelectedInjectors(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"type A struct{ N int }",
+		"",
+		"type B struct{ S string }",
+		"",
+		"func NewA(n int) A { return A{n} }",
+		"",
+		"func NewB(s string) B { return B{s} }",
+		"",
+	}, "\n"))
+	injectors := func(a, b string) string {
+		return strings.Join([]string{
+			"//go:build wireinject",
+			"// +build wireinject",
+			"",
+			"package app",
+			"",
+			"import \"github.com/goforj/wire\"",
+			"",
+			"func InitA() A {",
+			"\twire.Build(NewA, wire.Value(" + a + "))",
+			"\treturn A{}",
+			"}",
+			"",
+			"func InitB() B {",
+			"\twire.Build(NewB, wire.Value(" + b + "))",
+			"\treturn B{}",
+			"}",
+			"",
+		}, "\n")
+	}
+	writeFile(t, filepath.Join(root, "app", "wire.go"), injectors(`1`, `"one"`))
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	gens, errs := Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("Generate returned errors: %v", errs)
+	}
+	if len(gens) != 1 || len(gens[0].Errs) > 0 {
+		t.Fatalf("Generate failed: %+v", gens)
+	}
+	if err := gens[0].Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, filepath.Join(root, "app", "wire.go"), injectors(`2`, `"two"`))
+	gens, errs = Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{Injectors: []string{"InitB"}})
+	if len(errs) > 0 {
+		t.Fatalf("Generate returned errors: %v", errs)
+	}
+	if len(gens) != 1 || len(gens[0].Errs) > 0 {
+		t.Fatalf("Generate with Injectors failed: %+v", gens)
+	}
+	got := string(gens[0].Content)
+	if !strings.Contains(got, "_wireIntValue = 1") {
+		t.Errorf("InitA was regenerated; want it kept from the previous output:\n%s", got)
+	}
+	if !strings.Contains(got, `_wireStringValue = "two"`) {
+		t.Errorf("InitB was not regenerated:\n%s", got)
+	}
+
+	gens, errs = Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{Injectors: []string{"InitC"}})
+	if len(errs) > 0 {
+		t.Fatalf("Generate returned errors: %v", errs)
+	}
+	if len(gens) != 1 || len(gens[0].Errs) > 0 || len(gens[0].Content) > 0 {
+		t.Fatalf("Generate for a missing injector produced output: %+v", gens)
+	}
+}
+
+func TestGeneratePartial(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"type A struct{ N int }",
+		"",
+		"type B struct{ S string }",
+		"",
+		"func NewA(n int) A { return A{n} }",
+		"",
+		"func NewB(s string) B { return B{s} }",
+		"",
+	}, "\n"))
+	injectors := func(a, b string) string {
+		return strings.Join([]string{
+			"//go:build wireinject",
+			"// +build wireinject",
+			"",
+			"package app",
+			"",
+			"import \"github.com/goforj/wire\"",
+			"",
+			"func InitA() A {",
+			"\twire.Build(" + a + ")",
+			"\treturn A{}",
+			"}",
+			"",
+			"func InitB() B {",
+			"\twire.Build(" + b + ")",
+			"\treturn B{}",
+			"}",
+			"",
+		}, "\n")
+	}
+	writeFile(t, filepath.Join(root, "app", "wire.go"), injectors(`NewA, wire.Value(1)`, `NewB, wire.Value("one")`))
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	gens, errs := Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("Generate returned errors: %v", errs)
+	}
+	if len(gens) != 1 || len(gens[0].Errs) > 0 {
+		t.Fatalf("Generate failed: %+v", gens)
+	}
+	if err := gens[0].Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	// InitB no longer provides its string.
+	writeFile(t, filepath.Join(root, "app", "wire.go"), injectors(`NewA, wire.Value(2)`, `NewB`))
+	gens, errs = Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("Generate returned errors: %v", errs)
+	}
+	if len(gens) != 1 || len(gens[0].Errs) == 0 || len(gens[0].Content) > 0 {
+		t.Fatalf("Generate without Partial = %+v; want errors and no content", gens)
+	}
+
+	gens, errs = Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{Partial: true})
+	if len(errs) > 0 {
+		t.Fatalf("Generate returned errors: %v", errs)
+	}
+	if len(gens) != 1 || len(gens[0].Errs) != 1 || !strings.Contains(gens[0].Errs[0].Error(), "inject InitB") {
+		t.Fatalf("Generate with Partial errors = %v; want one error for InitB", gens[0].Errs)
+	}
+	got := string(gens[0].Content)
+	if !strings.Contains(got, "_wireIntValue = 2") {
+		t.Errorf("InitA was not regenerated:\n%s", got)
+	}
+	if !strings.Contains(got, `_wireStringValue = "one"`) {
+		t.Errorf("InitB was not kept from the previous output:\n%s", got)
+	}
+
+	// Without previous output, the failing injector is left out.
+	if err := os.Remove(gens[0].OutputPath); err != nil {
+		t.Fatal(err)
+	}
+	gens, errs = Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{Partial: true})
+	if len(errs) > 0 {
+		t.Fatalf("Generate returned errors: %v", errs)
+	}
+	if len(gens) != 1 || len(gens[0].Errs) != 1 {
+		t.Fatalf("Generate with Partial errors = %v; want one error for InitB", gens[0].Errs)
+	}
+	got = string(gens[0].Content)
+	if !strings.Contains(got, "func InitA()") || strings.Contains(got, "func InitB()") {
+		t.Errorf("Generate with Partial = %s; want only InitA", got)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// sharedLoad type-checks the packages of a Generate run that miss the cache
+// in a single load, and processes their injectors with one object cache.
+// A provider set imported by many of those packages is then parsed and
+// solved once rather than once per package.
+//
+// A nil *sharedLoad is valid and shares nothing.
+type sharedLoad struct {
+	loader *lazyLoader
+	opts   *GenerateOptions
+	pkgs   []*packages.Package
+
+	prepared bool
+	// keys maps import paths to their cache keys, for the packages whose
+	// key was computed while looking for cache misses.
+	keys map[string]string
+	// hits maps import paths to the cached output of the packages that
+	// hit the cache, so that it is not read again.
+	hits map[string]*cacheHit
+	// misses lists the import paths of the packages that must be generated.
+	misses map[string]bool
+	// oc is the object cache of the misses that loaded without errors,
+	// once they are loaded, and shared lists their import paths. A miss
+	// that failed to load is loaded again on its own, to report its
+	// errors.
+	oc     *objectCache
+	shared map[string]bool
+	loaded bool
+}
+
+// newSharedLoad returns a sharedLoad for the root packages pkgs, loaded by
+// loader and generated with opts.
+func newSharedLoad(pkgs []*packages.Package, loader *lazyLoader, opts *GenerateOptions) *sharedLoad {
+	if loader == nil || len(pkgs) < 2 {
+		return nil
+	}
+	return &sharedLoad{loader: loader, opts: opts, pkgs: pkgs}
+}
+
+// prepare finds the packages that miss the cache. Packages with their own
+// //wire:tags are loaded separately and are not included.
+func (sl *sharedLoad) prepare() {
+	if sl.prepared {
+		return
+	}
+	sl.prepared = true
+	sl.keys = make(map[string]string)
+	sl.hits = make(map[string]*cacheHit)
+	sl.misses = make(map[string]bool)
+	for _, pkg := range sl.pkgs {
+		if tags, err := packageTags(pkg); err != nil || len(tags) > 0 {
+			continue
+		}
+		if len(sl.opts.Injectors) == 0 && len(sl.opts.Overlay) == 0 {
+			opts, err := withPackageHeader(pkg, sl.opts)
+			if err != nil {
+				continue
+			}
+			key, err := cacheKeyForPackage(pkg, opts)
+			if err != nil {
+				continue
+			}
+			sl.keys[pkg.PkgPath] = key
+			if hit := readCacheHit(key, opts); hit != nil {
+				sl.hits[pkg.PkgPath] = hit
+				continue
+			}
+		}
+		sl.misses[pkg.PkgPath] = true
+	}
+}
+
+// cacheKey returns the cache key of the package at pkgPath and its cached
+// output, or nil if it missed the cache, if they have already been looked
+// up.
+func (sl *sharedLoad) cacheKey(pkgPath string) (string, *cacheHit, bool) {
+	if sl == nil {
+		return "", nil, false
+	}
+	sl.prepare()
+	key, ok := sl.keys[pkgPath]
+	return key, sl.hits[pkgPath], ok
+}
+
+// objectCache returns the object cache shared by the packages that miss the
+// cache, loading them on first use, or nil if the package at pkgPath must be
+// loaded on its own.
+func (sl *sharedLoad) objectCache(ctx context.Context, pkgPath string) *objectCache {
+	if sl == nil {
+		return nil
+	}
+	sl.prepare()
+	if !sl.misses[pkgPath] || len(sl.misses) < 2 {
+		return nil
+	}
+	if !sl.loaded {
+		sl.loaded = true
+		paths := make([]string, 0, len(sl.misses))
+		for _, pkg := range sl.pkgs {
+			if sl.misses[pkg.PkgPath] {
+				paths = append(paths, pkg.PkgPath)
+			}
+		}
+		loadStart := time.Now()
+		loaded, err := sl.loader.loadAll(paths)
+		logTiming(ctx, "generate.shared_load", loadStart)
+		if err != nil {
+			debugf(ctx, "loading %d packages together failed, loading them separately: %v", len(paths), err)
+			return nil
+		}
+		var ok []*packages.Package
+		sl.shared = make(map[string]bool, len(loaded))
+		for _, pkg := range loaded {
+			if !loadedCleanly(pkg) {
+				// Loaded again on its own, so that it reports its own
+				// errors.
+				debugf(ctx, "%s: failed to load, loading it separately", pkg.PkgPath)
+				continue
+			}
+			ok = append(ok, pkg)
+			sl.shared[pkg.PkgPath] = true
+		}
+		if len(ok) == 0 {
+			return nil
+		}
+		debugf(ctx, "loaded %d packages together to share their provider sets", len(ok))
+		sl.oc = newObjectCache(ok, sl.loader)
+		sl.oc.autoContext = sl.opts.AutoContext
+		sl.oc.stableOrder = sl.opts.StableOrder
+		sl.oc.autoNames = autoNamesRegexp(sl.opts.AutoNames)
+	}
+	if !sl.shared[pkgPath] {
+		return nil
+	}
+	return sl.oc
+}
+
+// loadedCleanly reports whether pkg and the packages it imports loaded
+// without errors.
+func loadedCleanly(pkg *packages.Package) bool {
+	clean := true
+	packages.Visit([]*packages.Package{pkg}, func(p *packages.Package) bool {
+		if len(p.Errors) > 0 {
+			clean = false
+		}
+		return clean
+	}, nil)
+	return clean
+}
@@ -0,0 +1,336 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// GeneratedFiles returns the paths of the files Wire has generated, using
+// the given output file prefix and name template (see
+// GenerateOptions.OutputFileName), in the directories of the packages that
+// match patterns. Packages are found even if their injectors are gone, so
+// that orphaned output can be cleaned up. Only files that carry Wire's
+// generated-code marker are returned.
+func GeneratedFiles(ctx context.Context, wd string, env []string, tags string, patterns []string, prefix, nameTemplate string) ([]string, error) {
+	files, _, err := generatedFiles(ctx, wd, env, tags, patterns, prefix, nameTemplate)
+	return files, err
+}
+
+// generatedFiles is like GeneratedFiles, but also reports which of the
+// files are test outputs.
+func generatedFiles(ctx context.Context, wd string, env []string, tags string, patterns []string, prefix, nameTemplate string) ([]string, map[string]bool, error) {
+	// Load without the wireinject tag: generated files are built then, so
+	// directories that only hold generated output still match patterns.
+	cfg := &packages.Config{
+		Context: ctx,
+		Mode:    packages.NeedName | packages.NeedFiles,
+		Dir:     wd,
+		Env:     env,
+	}
+	if len(tags) > 0 {
+		cfg.BuildFlags = []string{"-tags=" + tags}
+	}
+	escaped := make([]string, len(patterns))
+	for i := range patterns {
+		escaped[i] = "pattern=" + patterns[i]
+	}
+	pkgs, err := packages.Load(cfg, escaped...)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Package errors are expected here, since orphaned output usually
+	// does not compile.
+	dirs := make(map[string]string)
+	for _, pkg := range pkgs {
+		for _, files := range [][]string{pkg.GoFiles, pkg.OtherFiles, pkg.IgnoredFiles} {
+			for _, f := range files {
+				dirs[filepath.Dir(f)] = pkg.Name
+			}
+		}
+	}
+	var found []string
+	tests := make(map[string]bool)
+	for dir, pkgName := range dirs {
+		name, err := outputFileNameFor(prefix, nameTemplate, pkgName)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, name := range []string{name, prefix + examplesFileName, testOutputFileName(name, false), testOutputFileName(name, true)} {
+			path := filepath.Join(dir, name)
+			if isGeneratedFile(path) {
+				found = append(found, path)
+			}
+		}
+		tests[filepath.Join(dir, testOutputFileName(name, false))] = true
+		tests[filepath.Join(dir, testOutputFileName(name, true))] = true
+	}
+	sort.Strings(found)
+	return found, tests, nil
+}
+
+// OrphanedFiles returns the files reported by GeneratedFiles whose packages
+// no longer declare any injectors, typically because the injector file was
+// deleted or renamed. Such output lingers and redeclares symbols the package
+// may now define itself.
+func OrphanedFiles(ctx context.Context, wd string, env []string, tags string, patterns []string, prefix, nameTemplate string) ([]string, error) {
+	files, tests, err := generatedFiles(ctx, wd, env, tags, patterns, prefix, nameTemplate)
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, f := range files {
+		if dir := filepath.Dir(f); !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	cfg := &packages.Config{
+		Context:    ctx,
+		Mode:       packages.NeedName | packages.NeedFiles,
+		Dir:        wd,
+		Env:        env,
+		BuildFlags: []string{"-tags=wireinject"},
+	}
+	if len(tags) > 0 {
+		cfg.BuildFlags[0] += " " + tags
+	}
+	pkgs, err := packages.Load(cfg, dirs...)
+	if err != nil {
+		return nil, err
+	}
+	hasInjectors := make(map[string]bool)
+	fset := token.NewFileSet()
+	for _, pkg := range pkgs {
+		for _, f := range pkg.GoFiles {
+			if declaresInjector(fset, f) {
+				hasInjectors[filepath.Dir(f)] = true
+				break
+			}
+		}
+	}
+	// Test outputs come from the test files, which the load does not list.
+	hasTestInjectors := testInjectorDirs(fset, dirs)
+	var orphaned []string
+	for _, f := range files {
+		if tests[f] {
+			if !hasTestInjectors[filepath.Dir(f)] {
+				orphaned = append(orphaned, f)
+			}
+		} else if !hasInjectors[filepath.Dir(f)] {
+			orphaned = append(orphaned, f)
+		}
+	}
+	return orphaned, nil
+}
+
+// declaresInjector reports whether the Go file at path has a function that
+// calls wire.Build. It only inspects syntax, so it works for packages that
+// do not type-check. The wire package may be imported under another name or
+// with a dot import.
+func declaresInjector(fset *token.FileSet, path string) bool {
+	f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return false
+	}
+	names, dot := wireImportNames(f)
+	if len(names) == 0 && !dot {
+		return false
+	}
+	found := false
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok && isBuildCallSyntax(call, names, dot) {
+				found = true
+			}
+			return !found
+		})
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// isGeneratedFile reports whether the file at path was generated by Wire.
+func isGeneratedFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if string(line) == generatedMarker {
+			return true
+		}
+		if bytes.HasPrefix(line, []byte("package ")) {
+			return false
+		}
+	}
+	return false
+}
+
+// GeneratedPositions returns, for each step of in, the position of the
+// statement in the generated file that constructs the step's value. fset
+// must be the file set in was loaded with, and prefix and nameTemplate are
+// the output file prefix and name template the file was generated with. It
+// returns nil if there is no generated file for in, or if the file does not
+// match in, as happens when it is out of date.
+func GeneratedPositions(fset *token.FileSet, in *Injector, prefix, nameTemplate string) []token.Position {
+	genFset, f, fn := generatedInjector(fset, in, prefix, nameTemplate)
+	if fn == nil || fn.Body == nil {
+		return nil
+	}
+	body := fn.Body
+	// Each step is constructed by a short variable declaration, except for
+	// the struct filled in by wire.Populate, whose fields are assigned
+	// instead. The blank assignments that keep the values built for
+	// wire.Require used construct nothing.
+	var stmts []*ast.AssignStmt
+	filling := false
+	for _, stmt := range body.List {
+		assign, ok := stmt.(*ast.AssignStmt)
+		if !ok || isBlankAssign(assign) {
+			continue
+		}
+		if assign.Tok == token.DEFINE {
+			stmts = append(stmts, assign)
+		} else if !filling {
+			filling = true
+			stmts = append(stmts, assign)
+		}
+	}
+	if len(stmts) != len(in.Steps) {
+		return nil
+	}
+	positions := make([]token.Position, len(stmts))
+	for i, stmt := range stmts {
+		if p := in.Steps[i].Provider; p != nil && !p.IsStruct && !callsProvider(f, stmt, p) {
+			return nil
+		}
+		positions[i] = genFset.Position(stmt.Pos())
+	}
+	return positions
+}
+
+// generatedInjector parses the generated file of in, as for
+// GeneratedPositions, and returns its declaration of in's function along
+// with the file and the file set it was parsed into. It returns a nil
+// declaration if there is no generated file or it does not declare the
+// function.
+func generatedInjector(fset *token.FileSet, in *Injector, prefix, nameTemplate string) (*token.FileSet, *ast.File, *ast.FuncDecl) {
+	if !in.Pos.IsValid() {
+		return nil, nil, nil
+	}
+	injectorFile := fset.Position(in.Pos).Filename
+	src, err := parser.ParseFile(token.NewFileSet(), injectorFile, nil, parser.PackageClauseOnly)
+	if err != nil {
+		return nil, nil, nil
+	}
+	name, err := outputFileNameFor(prefix, nameTemplate, src.Name.Name)
+	if err != nil {
+		return nil, nil, nil
+	}
+	path := filepath.Join(filepath.Dir(injectorFile), name)
+	if !isGeneratedFile(path) {
+		return nil, nil, nil
+	}
+	genFset := token.NewFileSet()
+	f, err := parser.ParseFile(genFset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, nil, nil
+	}
+	for _, decl := range f.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == in.FuncName {
+			return genFset, f, fn
+		}
+	}
+	return nil, nil, nil
+}
+
+// isBlankAssign reports whether stmt only assigns to the blank identifier.
+func isBlankAssign(stmt *ast.AssignStmt) bool {
+	if stmt.Tok != token.ASSIGN || len(stmt.Lhs) != 1 {
+		return false
+	}
+	id, ok := stmt.Lhs[0].(*ast.Ident)
+	return ok && id.Name == "_"
+}
+
+// callsProvider reports whether stmt assigns the result of a call to the
+// function provider p, or, if p is shared, to the accessor declared in f
+// that calls p once.
+func callsProvider(f *ast.File, stmt *ast.AssignStmt, p *Provider) bool {
+	if len(stmt.Rhs) != 1 {
+		return false
+	}
+	call, ok := stmt.Rhs[0].(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	if p.Shared {
+		fun, ok := call.Fun.(*ast.Ident)
+		if !ok || !strings.HasPrefix(fun.Name, "_wireShared") {
+			return false
+		}
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == fun.Name && fn.Body != nil {
+				found := false
+				ast.Inspect(fn.Body, func(n ast.Node) bool {
+					if call, ok := n.(*ast.CallExpr); ok && isProviderCall(call, p) {
+						found = true
+					}
+					return !found
+				})
+				return found
+			}
+		}
+		return false
+	}
+	return isProviderCall(call, p)
+}
+
+// isProviderCall reports whether call calls the function provider p.
+func isProviderCall(call *ast.CallExpr, p *Provider) bool {
+	name := p.Name
+	if p.Method != "" {
+		name = p.Method
+	}
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return fun.Name == name
+	case *ast.SelectorExpr:
+		return fun.Sel.Name == name
+	}
+	return false
+}
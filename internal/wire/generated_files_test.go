@@ -0,0 +1,338 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGeneratedFiles(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/app\n\ngo 1.19\n")
+	generated := "// Code generated by Wire. DO NOT EDIT.\n\n//go:build !wireinject\n// +build !wireinject\n\npackage %s\n"
+	writeFile(t, filepath.Join(root, "app", "app.go"), "package app\n")
+	writeFile(t, filepath.Join(root, "app", "wire_gen.go"), strings.Replace(generated, "%s", "app", 1))
+	writeFile(t, filepath.Join(root, "app", "gen_wire_gen.go"), strings.Replace(generated, "%s", "app", 1))
+	writeFile(t, filepath.Join(root, "app", "app_wire.gen.go"), strings.Replace(generated, "%s", "app", 1))
+	// An orphaned package that only holds generated output.
+	writeFile(t, filepath.Join(root, "orphan", "wire_gen.go"), "// Copyright header.\n\n"+strings.Replace(generated, "%s", "orphan", 1))
+	// A hand-written file that happens to share the name.
+	writeFile(t, filepath.Join(root, "manual", "wire_gen.go"), "package manual\n")
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	got, err := GeneratedFiles(ctx, root, env, "", []string{"./..."}, "", "")
+	if err != nil {
+		t.Fatalf("GeneratedFiles failed: %v", err)
+	}
+	want := []string{
+		filepath.Join(root, "app", "wire_gen.go"),
+		filepath.Join(root, "orphan", "wire_gen.go"),
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("GeneratedFiles = %v; want %v", got, want)
+	}
+	got, err = GeneratedFiles(ctx, root, env, "", []string{"./..."}, "gen_", "")
+	if err != nil {
+		t.Fatalf("GeneratedFiles failed: %v", err)
+	}
+	if want := filepath.Join(root, "app", "gen_wire_gen.go"); len(got) != 1 || got[0] != want {
+		t.Errorf("GeneratedFiles with prefix = %v; want [%s]", got, want)
+	}
+	got, err = GeneratedFiles(ctx, root, env, "", []string{"./..."}, "", "{{.Package}}_wire.gen.go")
+	if err != nil {
+		t.Fatalf("GeneratedFiles failed: %v", err)
+	}
+	if want := filepath.Join(root, "app", "app_wire.gen.go"); len(got) != 1 || got[0] != want {
+		t.Errorf("GeneratedFiles with name template = %v; want [%s]", got, want)
+	}
+}
+
+func TestGeneratedPositions(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"type Foo int",
+		"type Bar struct{ Foo Foo }",
+		"",
+		"func NewFoo() Foo { return 1 }",
+		"func NewBar(f Foo) *Bar { return &Bar{Foo: f} }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func InitBar() *Bar {",
+		"\tpanic(wire.Build(NewFoo, NewBar))",
+		"}",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	info, errs := Load(ctx, root, env, "", []string{"./app"})
+	if len(errs) > 0 {
+		t.Fatalf("Load failed: %v", errs)
+	}
+	if len(info.Injectors) != 1 {
+		t.Fatalf("Load found %d injectors; want 1", len(info.Injectors))
+	}
+	in := info.Injectors[0]
+	if got := GeneratedPositions(info.Fset, in, "", ""); got != nil {
+		t.Errorf("GeneratedPositions before generating = %v; want nil", got)
+	}
+
+	gens, genErrs := Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{})
+	if len(genErrs) > 0 || len(gens) != 1 || len(gens[0].Errs) > 0 {
+		t.Fatalf("Generate failed: %v %v", genErrs, gens)
+	}
+	if err := gens[0].Commit(); err != nil {
+		t.Fatal(err)
+	}
+	got := GeneratedPositions(info.Fset, in, "", "")
+	if len(got) != 2 {
+		t.Fatalf("GeneratedPositions = %v; want 2 positions", got)
+	}
+	lines := strings.Split(string(gens[0].Content), "\n")
+	for i, want := range []string{"foo := NewFoo()", "bar := NewBar(foo)"} {
+		if got[i].Filename != gens[0].OutputPath || strings.TrimSpace(lines[got[i].Line-1]) != want {
+			t.Errorf("GeneratedPositions[%d] = %v; want the line of %q in %s", i, got[i], want, gens[0].OutputPath)
+		}
+	}
+
+	// Output that no longer matches the injector is ignored.
+	writeFile(t, gens[0].OutputPath, strings.Replace(string(gens[0].Content), "NewFoo()", "newFoo()", 1))
+	if got := GeneratedPositions(info.Fset, in, "", ""); got != nil {
+		t.Errorf("GeneratedPositions with stale output = %v; want nil", got)
+	}
+}
+
+func TestGeneratedPositionsRequire(t *testing.T) {
+	got := generatedPositionLines(t, strings.Join([]string{
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"type Config struct{}",
+		"type Telemetry struct{}",
+		"type Server struct{}",
+		"",
+		"func NewConfig() *Config { return &Config{} }",
+		"func NewTelemetry(*Config) *Telemetry { return &Telemetry{} }",
+		"func NewServer(*Config) *Server { return &Server{} }",
+		"",
+		"var TelemetrySet = wire.NewSet(NewTelemetry, wire.Require(new(*Telemetry)))",
+		"",
+	}, "\n"), "func InitServer() *Server {\n\tpanic(wire.Build(NewConfig, NewServer, TelemetrySet))\n}\n")
+	// The blank assignment that keeps the telemetry used is not a step.
+	want := []string{"config := NewConfig()", "telemetry := NewTelemetry(config)", "server := NewServer(config)"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("GeneratedPositions point at:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestGeneratedPositionsShared(t *testing.T) {
+	got := generatedPositionLines(t, strings.Join([]string{
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"type Config struct{}",
+		"type Pool struct{}",
+		"type Users struct{}",
+		"",
+		"func NewConfig() *Config { return &Config{} }",
+		"func NewPool(*Config) (*Pool, error) { return &Pool{}, nil }",
+		"func NewUsers(*Pool) *Users { return &Users{} }",
+		"",
+		"var StorageSet = wire.NewSet(wire.Shared(NewConfig), wire.Shared(NewPool))",
+		"",
+	}, "\n"), "func InitUsers() (*Users, error) {\n\tpanic(wire.Build(StorageSet, NewUsers))\n}\n")
+	// Shared providers are built by calling their accessors.
+	want := []string{"config := _wireSharedConfig()", "pool, err := _wireSharedPool(config)", "users := NewUsers(pool)"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("GeneratedPositions point at:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// generatedPositionLines generates the single injector declared by
+// injector in package app, whose other declarations are in app, and
+// returns the generated lines that GeneratedPositions points at for each of
+// its steps.
+func generatedPositionLines(t *testing.T, app, injector string) []string {
+	t.Helper()
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), app)
+	writeFile(t, filepath.Join(root, "app", "wire.go"), "//go:build wireinject\n\npackage app\n\nimport \"github.com/goforj/wire\"\n\n"+injector)
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	gens, genErrs := Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{})
+	if len(genErrs) > 0 || len(gens) != 1 || len(gens[0].Errs) > 0 {
+		t.Fatalf("Generate failed: %v %v", genErrs, gens)
+	}
+	if err := gens[0].Commit(); err != nil {
+		t.Fatal(err)
+	}
+	info, errs := Load(ctx, root, env, "", []string{"./app"})
+	if len(errs) > 0 {
+		t.Fatalf("Load failed: %v", errs)
+	}
+	if len(info.Injectors) != 1 {
+		t.Fatalf("Load found %d injectors; want 1", len(info.Injectors))
+	}
+	in := info.Injectors[0]
+	positions := GeneratedPositions(info.Fset, in, "", "")
+	if len(positions) != len(in.Steps) {
+		t.Fatalf("GeneratedPositions = %v; want a position for each of %d steps in:\n%s", positions, len(in.Steps), gens[0].Content)
+	}
+	lines := strings.Split(string(gens[0].Content), "\n")
+	var got []string
+	for _, p := range positions {
+		got = append(got, strings.TrimSpace(lines[p.Line-1]))
+	}
+	return got
+}
+
+func TestOrphanedFiles(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	generated := "// Code generated by Wire. DO NOT EDIT.\n\n//go:build !wireinject\n// +build !wireinject\n\npackage %s\n"
+	writeFile(t, filepath.Join(root, "app", "app.go"), "package app\n")
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"// +build wireinject",
+		"",
+		"package app",
+		"",
+		"import di \"github.com/goforj/wire\"",
+		"",
+		"func Init() string {",
+		"\tdi.Build(di.Value(\"\"))",
+		"\treturn \"\"",
+		"}",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire_gen.go"), strings.Replace(generated, "%s", "app", 1))
+	writeFile(t, filepath.Join(root, "moved", "moved.go"), "package moved\n")
+	writeFile(t, filepath.Join(root, "moved", "wire_gen.go"), strings.Replace(generated, "%s", "moved", 1))
+	writeFile(t, filepath.Join(root, "orphan", "wire_gen.go"), strings.Replace(generated, "%s", "orphan", 1))
+
+	env := append(os.Environ(), "GOWORK=off")
+	got, err := OrphanedFiles(context.Background(), root, env, "", []string{"./..."}, "", "")
+	if err != nil {
+		t.Fatalf("OrphanedFiles failed: %v", err)
+	}
+	want := []string{
+		filepath.Join(root, "moved", "wire_gen.go"),
+		filepath.Join(root, "orphan", "wire_gen.go"),
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("OrphanedFiles = %v; want %v", got, want)
+	}
+}
+
+func TestWireImportForms(t *testing.T) {
+	repoRoot := mustRepoRoot(t)
+	root := t.TempDir()
+	markers, err := os.ReadFile(filepath.Join(repoRoot, "wire.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A stand-in for the upstream fork, with the same markers.
+	writeFile(t, filepath.Join(root, "google", "go.mod"), "module github.com/google/wire\n\ngo 1.19\n")
+	writeFile(t, filepath.Join(root, "google", "wire.go"), string(markers))
+	writeFile(t, filepath.Join(root, "go.mod"), strings.Join([]string{
+		"module example.com/app",
+		"",
+		"go 1.19",
+		"",
+		"require (",
+		"\tgithub.com/goforj/wire v0.0.0",
+		"\tgithub.com/google/wire v0.0.0",
+		")",
+		"",
+		"replace github.com/goforj/wire => " + repoRoot,
+		"",
+		"replace github.com/google/wire => ./google",
+		"",
+	}, "\n"))
+	tests := []struct {
+		name, importSpec, build string
+	}{
+		{"alias", `di "github.com/goforj/wire"`, "di.Build(di.NewSet(NewMessage))"},
+		{"dot", `. "github.com/goforj/wire"`, "Build(NewSet(NewMessage))"},
+		{"googlealias", `gw "github.com/google/wire"`, "gw.Build(NewMessage)"},
+		{"googledot", `. "github.com/google/wire"`, "Build(NewMessage)"},
+	}
+	var patterns []string
+	for _, test := range tests {
+		dir := filepath.Join(root, test.name)
+		writeFile(t, filepath.Join(dir, "app.go"), "package "+test.name+"\n\nfunc NewMessage() string { return \"ok\" }\n")
+		writeFile(t, filepath.Join(dir, "wire.go"), strings.Join([]string{
+			"//go:build wireinject",
+			"// +build wireinject",
+			"",
+			"package " + test.name,
+			"",
+			"import " + test.importSpec,
+			"",
+			"func Init() string {",
+			"\t" + test.build,
+			"\treturn \"\"",
+			"}",
+			"",
+		}, "\n"))
+		patterns = append(patterns, "./"+test.name)
+		if !declaresInjector(token.NewFileSet(), filepath.Join(dir, "wire.go")) {
+			t.Errorf("%s: declaresInjector = false; want true", test.name)
+		}
+	}
+	blank := filepath.Join(root, "blank.go")
+	writeFile(t, blank, "package blank\n\nimport _ \"github.com/goforj/wire\"\n\nfunc Init() { Build() }\n")
+	if declaresInjector(token.NewFileSet(), blank) {
+		t.Error("declaresInjector = true for a blank import of wire; want false")
+	}
+
+	env := append(os.Environ(), "GOWORK=off")
+	gens, errs := Generate(context.Background(), root, env, patterns, &GenerateOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("Generate returned errors: %v", errs)
+	}
+	if len(gens) != len(tests) {
+		t.Fatalf("Generate returned %d results; want %d", len(gens), len(tests))
+	}
+	for i, gen := range gens {
+		if len(gen.Errs) > 0 {
+			t.Errorf("%s: generate failed: %v", tests[i].name, gen.Errs)
+			continue
+		}
+		if got := string(gen.Content); !strings.Contains(got, "func Init() string {") || !strings.Contains(got, "NewMessage()") {
+			t.Errorf("%s: generated code does not call NewMessage:\n%s", tests[i].name, got)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// generatedLeakWarnings returns a warning for each name that a file of pkg
+// uses but that is only declared on the other side of the wireinject build
+// tag: a wireinject file using a name declared only in Wire's generated
+// output, which is excluded when injectors are loaded, or any other file
+// using a name declared only in a wireinject file and missing from the
+// generated output, which breaks the normal build. Either way the package
+// builds under one set of tags and fails with "undefined" errors under the
+// other. pkg must be loaded with the wireinject tag.
+func generatedLeakWarnings(pkg *packages.Package) ErrorList {
+	fset := token.NewFileSet()
+	included := make(map[string]bool, len(pkg.GoFiles))
+	for _, path := range pkg.GoFiles {
+		included[path] = true
+	}
+	var inject, generated, common []*ast.File
+	for _, path := range append(append([]string(nil), pkg.GoFiles...), pkg.IgnoredFiles...) {
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		switch {
+		case isGeneratedFile(path):
+			generated = append(generated, f)
+		case isWireinjectFile(f):
+			inject = append(inject, f)
+		case included[path]:
+			common = append(common, f)
+		}
+	}
+	if len(inject) == 0 {
+		return nil
+	}
+	injectDecls, generatedDecls, commonDecls := topLevelDecls(fset, inject), topLevelDecls(fset, generated), topLevelDecls(fset, common)
+	var warnings ErrorList
+	report := func(files []*ast.File, other map[string]string, msg string) {
+		for _, f := range files {
+			imports := fileImportNames(f)
+			seen := make(map[string]bool)
+			for _, id := range f.Unresolved {
+				file, ok := other[id.Name]
+				if !ok || seen[id.Name] || imports[id.Name] {
+					continue
+				}
+				if _, ok := commonDecls[id.Name]; ok {
+					continue
+				}
+				seen[id.Name] = true
+				err := notePosition(fset.Position(id.Pos()), fmt.Errorf(msg, id.Name, file))
+				warnings = append(warnings, newWarning(pkg.PkgPath, CodeGeneratedLeak, err))
+			}
+		}
+	}
+	onlyGenerated := make(map[string]string)
+	for name, file := range generatedDecls {
+		if _, ok := injectDecls[name]; !ok {
+			onlyGenerated[name] = file
+		}
+	}
+	onlyInject := make(map[string]string)
+	for name, file := range injectDecls {
+		if _, ok := generatedDecls[name]; !ok {
+			onlyInject[name] = file
+		}
+	}
+	report(inject, onlyGenerated, "%s is declared only in the generated file %s, which is excluded when injectors are built with the wireinject tag")
+	report(common, onlyInject, "%s is declared only in the wireinject file %s and not in the generated output, so builds without the wireinject tag fail; regenerate with wire gen or move the declaration out of the wireinject file")
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return positionLess(warnings[i].Pos, warnings[j].Pos)
+	})
+	return warnings
+}
+
+// topLevelDecls maps the names declared at the top level of files to the
+// base name of the file that declares them.
+func topLevelDecls(fset *token.FileSet, files []*ast.File) map[string]string {
+	decls := make(map[string]string)
+	for _, f := range files {
+		name := filepath.Base(fset.File(f.Pos()).Name())
+		add := func(id *ast.Ident) {
+			if id.Name != "_" && id.Name != "init" {
+				decls[id.Name] = name
+			}
+		}
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					add(decl.Name)
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						add(spec.Name)
+					case *ast.ValueSpec:
+						for _, id := range spec.Names {
+							add(id)
+						}
+					}
+				}
+			}
+		}
+	}
+	return decls
+}
+
+// fileImportNames returns the names that the imports of f bind in its
+// scope. Imports without an explicit name are assumed to bind the last
+// element of their path.
+func fileImportNames(f *ast.File) map[string]bool {
+	names := make(map[string]bool)
+	for _, imp := range f.Imports {
+		if imp.Name != nil {
+			names[imp.Name.Name] = true
+			continue
+		}
+		if path, err := strconv.Unquote(imp.Path.Value); err == nil {
+			names[path[strings.LastIndex(path, "/")+1:]] = true
+		}
+	}
+	return names
+}
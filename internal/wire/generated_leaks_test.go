@@ -0,0 +1,93 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGeneratedLeakWarnings(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "db.go"), strings.Join([]string{
+		"package app",
+		"",
+		"type DB struct{ Name string }",
+		"",
+		"func NewDB() *DB { return &DB{Name: dbName()} }",
+		"",
+	}, "\n"))
+	// wire.go uses newPool, which only the generated file declares, and
+	// declares dbName, which the stale generated file lacks.
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func dbName() string { return \"main\" }",
+		"",
+		"func InitDB() *DB {",
+		"\tpanic(wire.Build(newPool, NewDB))",
+		"}",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire_gen.go"), strings.Join([]string{
+		generatedMarker,
+		"",
+		"//go:build !wireinject",
+		"",
+		"package app",
+		"",
+		"func newPool() int { return 1 }",
+		"",
+		"func InitDB() *DB { return NewDB() }",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	wantLeaks := func(what string, warnings ErrorList) {
+		t.Helper()
+		var got []string
+		for _, w := range warnings {
+			if w.Code == CodeGeneratedLeak {
+				got = append(got, filepath.Base(w.Pos.Filename)+": "+w.Msg)
+			}
+		}
+		if len(got) != 2 || !strings.HasPrefix(got[0], "db.go: dbName is declared only in the wireinject file wire.go") || !strings.HasPrefix(got[1], "wire.go: newPool is declared only in the generated file wire_gen.go") {
+			t.Errorf("%s leak warnings = %q; want dbName in db.go and newPool in wire.go", what, got)
+		}
+	}
+	info, loadErrs := Load(ctx, root, env, "", []string{"./app"})
+	if len(loadErrs) == 0 {
+		t.Error("Load succeeded; want an undefined newPool error")
+	}
+	wantLeaks("Load", info.Warnings)
+
+	gens, errs := Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{})
+	if len(errs) > 0 || len(gens) != 1 {
+		t.Fatalf("Generate returned %+v, %v; want one result", gens, errs)
+	}
+	if len(gens[0].Errs) == 0 {
+		t.Error("Generate succeeded; want an undefined newPool error")
+	}
+	wantLeaks("Generate", gens[0].Warnings)
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/format"
+	"strings"
+)
+
+// genSetMarker is the first line GenSet writes after the header.
+const genSetMarker = "// Code generated by wire genset. DO NOT EDIT."
+
+// IsGenSetOutput reports whether src is a file written by GenSet, which
+// may be replaced by running it again.
+func IsGenSetOutput(src []byte) bool {
+	for _, line := range strings.Split(string(src), "\n") {
+		if line = strings.TrimSpace(line); line == genSetMarker {
+			return true
+		}
+		if strings.HasPrefix(line, "package ") {
+			return false
+		}
+	}
+	return false
+}
+
+// GenSet returns the source of a Go file for the package in wd declaring
+// the provider sets of m. Every provider the manifest names is looked up and
+// checked like a wire.NewSet argument, so a manifest that names a
+// constructor which has been renamed or removed fails here, at the entry
+// that names it, instead of when the package is next compiled.
+//
+// The package in wd only needs a name, which m.Package may give instead if
+// the package has no Go files yet.
+func GenSet(ctx context.Context, wd string, env []string, tags string, m *SetManifest, header []byte) ([]byte, []error) {
+	if err := m.validate(); err != nil {
+		return nil, []error{err}
+	}
+	pkgName, pkgPath := m.Package, ""
+	consumer, err := bindGenPackage(ctx, wd, env, tags)
+	switch {
+	case err == nil:
+		if m.Package != "" && m.Package != consumer.Name {
+			return nil, []error{fmt.Errorf("manifest declares package %s, but %s holds package %s", m.Package, wd, consumer.Name)}
+		}
+		pkgName, pkgPath = consumer.Name, consumer.PkgPath
+	case m.Package == "":
+		return nil, []error{fmt.Errorf("%v; name the package in the manifest", err)}
+	}
+
+	var paths []string
+	seen := make(map[string]bool)
+	for _, set := range m.Sets {
+		for _, ref := range set.Providers {
+			if path, _ := splitProviderRef(ref); path != "" && !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	g := &bindGen{
+		pkgPath:  pkgPath,
+		imports:  map[string]string{"github.com/goforj/wire": "wire"},
+		names:    map[string]string{"github.com/goforj/wire": "wire"},
+		declared: make(map[string]bool),
+	}
+	for _, set := range m.Sets {
+		g.declared[set.Name] = true
+	}
+	var oc *objectCache
+	if len(paths) > 0 {
+		pkgs, loader, errs := load(ctx, wd, env, tags, paths)
+		if len(errs) > 0 {
+			return nil, errs
+		}
+		oc = newObjectCache(pkgs, loader)
+	}
+
+	var ec errorCollector
+	var decls bytes.Buffer
+	for _, set := range m.Sets {
+		args := make([]string, 0, len(set.Providers))
+		for _, ref := range set.Providers {
+			arg, errs := genSetArg(oc, g, ref)
+			if len(errs) > 0 {
+				ec.add(mapErrors(errs, func(e error) error {
+					if w, ok := e.(*wireErr); ok {
+						return notePosition(w.position, fmt.Errorf("set %s: %w", set.Name, w.error))
+					}
+					return fmt.Errorf("set %s: %w", set.Name, e)
+				})...)
+				continue
+			}
+			args = append(args, arg)
+		}
+		if set.Doc != "" {
+			for _, line := range strings.Split(strings.TrimSpace(set.Doc), "\n") {
+				fmt.Fprintf(&decls, "// %s\n", strings.TrimSpace(line))
+			}
+		} else {
+			fmt.Fprintf(&decls, "// %s is declared in the provider set manifest.\n", set.Name)
+		}
+		fmt.Fprintf(&decls, "var %s = wire.NewSet(\n", set.Name)
+		for _, arg := range args {
+			fmt.Fprintf(&decls, "\t%s,\n", arg)
+		}
+		decls.WriteString(")\n\n")
+	}
+	if len(ec.errors) > 0 {
+		return nil, ec.errors
+	}
+
+	var buf bytes.Buffer
+	buf.Write(header)
+	buf.WriteString(genSetMarker + "\n\n")
+	buf.WriteString("package " + pkgName + "\n\n")
+	g.writeImports(&buf)
+	buf.Write(decls.Bytes())
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, []error{fmt.Errorf("generated sets are not valid Go source: %v", err)}
+	}
+	return src, nil
+}
+
+// genSetArg returns the wire.NewSet argument for the manifest reference
+// ref, importing its package into g. Bare names refer to other sets of the
+// manifest and are not looked up.
+func genSetArg(oc *objectCache, g *bindGen, ref string) (string, []error) {
+	path, name := splitProviderRef(ref)
+	if path == "" {
+		return name, nil
+	}
+	pkg, errs := oc.ensurePackage(path)
+	if pkg == nil || pkg.Types == nil {
+		if len(errs) == 0 {
+			errs = []error{fmt.Errorf("package %s not found", path)}
+		}
+		return "", errs
+	}
+	obj := pkg.Types.Scope().Lookup(name)
+	if obj == nil {
+		return "", []error{fmt.Errorf("%s not found", ref)}
+	}
+	if !obj.Exported() && path != g.pkgPath {
+		return "", []error{notePosition(oc.fset.Position(obj.Pos()), fmt.Errorf("%s is not exported", ref))}
+	}
+	if _, errs := oc.get(obj); len(errs) > 0 {
+		return "", notePositionAll(oc.fset.Position(obj.Pos()), errs)
+	}
+	if path == g.pkgPath {
+		return name, nil
+	}
+	return g.qualify(pkg.Types) + "." + name, nil
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadSetManifest(t *testing.T) {
+	want := &SetManifest{
+		Package: "providers",
+		Sets: []ManifestSet{
+			{
+				Name:      "ServiceSet",
+				Doc:       "ServiceSet provides the services: users and orders.",
+				Providers: []string{"example.com/app/users.NewService", "example.com/app/orders.NewService"},
+			},
+			{
+				Name:      "AppSet",
+				Providers: []string{"ServiceSet", "example.com/app/db.ProviderSet"},
+			},
+		},
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"providers.yaml": `# Provider sets of the API server.
+package: providers
+sets:
+  - name: ServiceSet
+    doc: "ServiceSet provides the services: users and orders."
+    providers:
+    - example.com/app/users.NewService   # the user service
+    - 'example.com/app/orders.NewService'
+  -
+    name: AppSet
+    providers: [ServiceSet, example.com/app/db.ProviderSet]
+`,
+		"providers.json": `{
+  "package": "providers",
+  "sets": [
+    {
+      "name": "ServiceSet",
+      "doc": "ServiceSet provides the services: users and orders.",
+      "providers": ["example.com/app/users.NewService", "example.com/app/orders.NewService"]
+    },
+    {"name": "AppSet", "providers": ["ServiceSet", "example.com/app/db.ProviderSet"]}
+  ]
+}
+`,
+	}
+	for name, content := range files {
+		got, err := ReadSetManifest(writeTempFile(t, dir, name, content))
+		if err != nil {
+			t.Errorf("ReadSetManifest(%s): %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ReadSetManifest(%s) = %+v; want %+v", name, got, want)
+		}
+	}
+
+	invalid := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"duplicate.yaml", "sets:\n  - name: A\n    providers: [x.New]\n  - name: A\n    providers: [x.New]\n", `unique identifiers, got "A"`},
+		{"unknown.yaml", "sets:\n  - name: A\n    providers: [Missing]\n", "Missing is not a set in the manifest"},
+		{"self.yaml", "sets:\n  - name: A\n    providers: [A]\n", "set A includes itself"},
+		{"empty.yaml", "sets:\n  - name: A\n", "set A lists no providers"},
+		{"indent.yaml", "sets:\n  - name: A\n     providers: [x.New]\n", "line 3: unexpected indentation"},
+		{"block.yaml", "sets:\n  - name: A\n    doc: |\n      text\n", "unsupported YAML syntax |"},
+	}
+	for _, test := range invalid {
+		_, err := ReadSetManifest(writeTempFile(t, dir, test.name, test.content))
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("ReadSetManifest(%s) = %v; want an error containing %q", test.name, err, test.want)
+		}
+	}
+}
+
+func TestGenSet(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "users", "users.go"), `package users
+
+type Service struct{}
+
+func NewService() *Service { return &Service{} }
+
+const Version = 1
+`)
+	writeFile(t, filepath.Join(root, "orders", "service", "service.go"), `package service
+
+import (
+	"example.com/app/users"
+	"github.com/goforj/wire"
+)
+
+type Orders struct{}
+
+func New(*users.Service) *Orders { return &Orders{} }
+
+var Set = wire.NewSet(New)
+`)
+	writeFile(t, filepath.Join(root, "users", "service", "service.go"), `package service
+
+type Audit struct{}
+
+func NewAudit() *Audit { return &Audit{} }
+`)
+	// The package has no Go files yet, so the manifest names it.
+	providers := filepath.Join(root, "providers")
+	if err := os.MkdirAll(providers, 0755); err != nil {
+		t.Fatal(err)
+	}
+	m := &SetManifest{
+		Package: "providers",
+		Sets: []ManifestSet{
+			{Name: "ServiceSet", Providers: []string{"example.com/app/users.NewService", "example.com/app/users/service.NewAudit"}},
+			{Name: "AppSet", Doc: "AppSet builds the app.", Providers: []string{"ServiceSet", "example.com/app/orders/service.Set"}},
+		},
+	}
+	env := append(os.Environ(), "GOWORK=off")
+	src, errs := GenSet(context.Background(), providers, env, "", m, []byte("// Header.\n\n"))
+	if len(errs) > 0 {
+		t.Fatalf("GenSet errors: %v", errs)
+	}
+	want := `// Header.
+
+// Code generated by wire genset. DO NOT EDIT.
+
+package providers
+
+import (
+	service2 "example.com/app/orders/service"
+	"example.com/app/users"
+	"example.com/app/users/service"
+	"github.com/goforj/wire"
+)
+
+// ServiceSet is declared in the provider set manifest.
+var ServiceSet = wire.NewSet(
+	users.NewService,
+	service.NewAudit,
+)
+
+// AppSet builds the app.
+var AppSet = wire.NewSet(
+	ServiceSet,
+	service2.Set,
+)
+`
+	if string(src) != want {
+		t.Errorf("GenSet =\n%s\nwant:\n%s", src, want)
+	}
+	if !IsGenSetOutput(src) {
+		t.Error("IsGenSetOutput(GenSet output) = false; want true")
+	}
+
+	// The written file is part of the package, which now loads.
+	writeFile(t, filepath.Join(providers, "wire_sets.go"), string(src))
+	m.Package = ""
+	m.Sets[0].Providers = append(m.Sets[0].Providers, "example.com/app/users.Version", "example.com/app/users.NewMissing")
+	_, errs = GenSet(context.Background(), providers, env, "", m, nil)
+	if len(errs) != 2 {
+		t.Fatalf("GenSet with invalid providers = %v; want two errors", errs)
+	}
+	for i, want := range []string{"set ServiceSet: ", "set ServiceSet: example.com/app/users.NewMissing not found"} {
+		if !strings.Contains(errs[i].Error(), want) {
+			t.Errorf("GenSet error %d = %v; want it to contain %q", i, errs[i], want)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// goGeneratePrefix starts a //go:generate directive.
+const goGeneratePrefix = "//go:generate "
+
+// GoGenerateDirective returns the //go:generate directive that runs wire
+// with args, such as []string{"gen", "-tags=integration"}. Arguments that go
+// generate would split are quoted.
+func GoGenerateDirective(args []string) string {
+	var sb strings.Builder
+	sb.WriteString(goGeneratePrefix + "go run -mod=mod github.com/goforj/wire/cmd/wire")
+	for _, arg := range args {
+		sb.WriteByte(' ')
+		if arg == "" || strings.ContainsAny(arg, " \t\"") {
+			arg = strconv.Quote(arg)
+		}
+		sb.WriteString(arg)
+	}
+	return sb.String()
+}
+
+// isWireGoGenerate reports whether the comment text is a //go:generate
+// directive that runs wire, either installed or with go run.
+func isWireGoGenerate(text string) bool {
+	rest := strings.TrimPrefix(text, goGeneratePrefix)
+	if rest == text {
+		return false
+	}
+	fields := strings.Fields(rest)
+	if len(fields) > 0 && fields[0] == "wire" {
+		return true
+	}
+	for _, field := range fields {
+		if strings.HasSuffix(field, "/wire/cmd/wire") || strings.Contains(field, "/wire/cmd/wire@") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasWireGoGenerate reports whether one of files has a //go:generate
+// directive that runs wire, in which case the generated file does not add
+// its own.
+func hasWireGoGenerate(files []*ast.File) bool {
+	for _, f := range files {
+		for _, cg := range f.Comments {
+			for _, c := range cg.List {
+				if isWireGoGenerate(c.Text) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// GoGenerate adds a //go:generate directive that runs wire to one
+// wireinject file of each package matching patterns, and removes the other
+// directives that run wire from the package's wireinject files, so that go
+// generate runs wire once per package. directive returns the directive to
+// use for the package in dir, usually built by GoGenerateDirective. An
+// existing directive is rewritten in place; otherwise the directive is added
+// after the build constraints of the first wireinject file by name.
+//
+// GoGenerate returns the files whose source changed; writing them is left to
+// the caller. Test files are left alone: the package's directive regenerates
+// the injectors they declare.
+func GoGenerate(ctx context.Context, wd string, env []string, tags string, patterns []string, directive func(dir string) string) ([]FormatResult, []error) {
+	pkgs, _, errs := load(ctx, wd, env, tags, patterns)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	ec := new(errorCollector)
+	var results []FormatResult
+	for _, pkg := range pkgs {
+		if isWireImport(pkg.PkgPath) {
+			continue
+		}
+		res, err := goGeneratePackage(pkg, directive(packageDir(pkg)))
+		if err != nil {
+			ec.add(err)
+			continue
+		}
+		results = append(results, res...)
+	}
+	if len(ec.errors) > 0 {
+		return nil, ec.errors
+	}
+	return results, nil
+}
+
+// goGeneratePackage returns the wireinject files of pkg rewritten so that
+// exactly one of them holds directive.
+func goGeneratePackage(pkg *packages.Package, directive string) ([]FormatResult, error) {
+	var paths []string
+	for _, path := range append(append([]string(nil), pkg.GoFiles...), pkg.IgnoredFiles...) {
+		if strings.HasSuffix(path, ".go") && !strings.HasSuffix(path, "_test.go") {
+			paths = append(paths, path)
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool { return filepath.Base(paths[i]) < filepath.Base(paths[j]) })
+	fset := token.NewFileSet()
+	var files []*ast.File
+	srcs := make(map[*ast.File][]byte)
+	target := -1
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		f, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		if !isWireinjectFile(f) {
+			continue
+		}
+		if target < 0 && hasWireGoGenerate([]*ast.File{f}) {
+			target = len(files)
+		}
+		files = append(files, f)
+		srcs[f] = src
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+	if target < 0 {
+		target = 0
+	}
+	var results []FormatResult
+	for i, f := range files {
+		// edits maps 1-based line numbers to their new text; an empty
+		// text deletes the line.
+		edits := make(map[int]string)
+		for _, cg := range f.Comments {
+			for _, c := range cg.List {
+				if pos := fset.Position(c.Pos()); pos.Column == 1 && isWireGoGenerate(c.Text) {
+					edits[pos.Line] = ""
+				}
+			}
+		}
+		if i == target {
+			if line := firstLine(edits); line > 0 {
+				edits[line] = directive
+			} else {
+				line := constraintEnd(fset, f)
+				edits[line] = string(lineAt(srcs[f], line)) + "\n\n" + directive
+			}
+		}
+		if len(edits) == 0 {
+			continue
+		}
+		src := srcs[f]
+		var out bytes.Buffer
+		for n, line := range bytes.SplitAfter(src, []byte("\n")) {
+			text, ok := edits[n+1]
+			switch {
+			case !ok:
+				out.Write(line)
+			case text != "":
+				out.WriteString(text + "\n")
+			}
+		}
+		content, err := format.Source(out.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("%s: formatting rewritten source: %v", fset.File(f.Pos()).Name(), err)
+		}
+		if !bytes.Equal(content, src) {
+			results = append(results, FormatResult{Path: fset.File(f.Pos()).Name(), Content: content})
+		}
+	}
+	return results, nil
+}
+
+// firstLine returns the smallest key of edits, or 0 if it is empty.
+func firstLine(edits map[int]string) int {
+	first := 0
+	for line := range edits {
+		if first == 0 || line < first {
+			first = line
+		}
+	}
+	return first
+}
+
+// constraintEnd returns the line of the last build constraint before the
+// package clause of f.
+func constraintEnd(fset *token.FileSet, f *ast.File) int {
+	end := 0
+	for _, cg := range f.Comments {
+		if cg.Pos() > f.Package {
+			break
+		}
+		for _, c := range cg.List {
+			if strings.HasPrefix(c.Text, "//go:build") || strings.HasPrefix(c.Text, "// +build") {
+				end = fset.Position(c.Pos()).Line
+			}
+		}
+	}
+	return end
+}
+
+// lineAt returns the 1-based line n of src without its newline.
+func lineAt(src []byte, n int) []byte {
+	lines := bytes.Split(src, []byte("\n"))
+	if n < 1 || n > len(lines) {
+		return nil
+	}
+	return lines[n-1]
+}
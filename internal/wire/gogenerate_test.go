@@ -0,0 +1,117 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGoGenerateDirective(t *testing.T) {
+	directive := GoGenerateDirective([]string{"gen", "-tags=a b", "-output_file_prefix=x_"})
+	if want := `//go:generate go run -mod=mod github.com/goforj/wire/cmd/wire gen "-tags=a b" -output_file_prefix=x_`; directive != want {
+		t.Errorf("GoGenerateDirective = %q; want %q", directive, want)
+	}
+	for text, want := range map[string]bool{
+		"//go:generate wire":                    true,
+		"//go:generate wire gen -tags=x":        true,
+		directive:                               true,
+		"//go:generate go run example.com/wire": false,
+		"//go:generate stringer -type=Kind":     false,
+		"// go:generate wire":                   false,
+	} {
+		if got := isWireGoGenerate(text); got != want {
+			t.Errorf("isWireGoGenerate(%q) = %t; want %t", text, got, want)
+		}
+	}
+}
+
+func TestGoGenerate(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), `package app
+
+type App struct{}
+
+func NewApp() *App { return nil }
+`)
+	writeFile(t, filepath.Join(root, "app", "wire.go"), `//go:build wireinject
+
+package app
+
+import "github.com/goforj/wire"
+
+func InitApp() *App {
+	panic(wire.Build(NewApp))
+}
+`)
+	writeFile(t, filepath.Join(root, "app", "wire_more.go"), `//go:build wireinject
+
+//go:generate wire
+//go:generate stringer -type=Kind
+
+package app
+`)
+	writeFile(t, filepath.Join(root, "other", "other.go"), `package other
+`)
+
+	ctx := context.Background()
+	env := append(os.Environ(), "GOWORK=off")
+	directive := func(string) string { return GoGenerateDirective([]string{"gen", "-tags=integration"}) }
+	results, errs := GoGenerate(ctx, root, env, "", []string{"./..."}, directive)
+	if len(errs) > 0 {
+		t.Fatalf("GoGenerate failed: %v", errs)
+	}
+	want := map[string]string{
+		// The existing directive is updated in place.
+		"wire_more.go": `//go:build wireinject
+
+//go:generate go run -mod=mod github.com/goforj/wire/cmd/wire gen -tags=integration
+//go:generate stringer -type=Kind
+
+package app
+`,
+	}
+	if len(results) != len(want) {
+		t.Fatalf("GoGenerate returned %d files; want %d", len(results), len(want))
+	}
+	for _, res := range results {
+		name := filepath.Base(res.Path)
+		if string(res.Content) != want[name] {
+			t.Errorf("%s rewritten to:\n%s\nwant:\n%s", name, res.Content, want[name])
+		}
+		writeFile(t, res.Path, string(res.Content))
+	}
+
+	// Without a directive, one is added to the first wireinject file.
+	writeFile(t, filepath.Join(root, "app", "wire_more.go"), "//go:build wireinject\n\npackage app\n")
+	results, errs = GoGenerate(ctx, root, env, "", []string{"./app"}, directive)
+	if len(errs) > 0 || len(results) != 1 || filepath.Base(results[0].Path) != "wire.go" {
+		t.Fatalf("GoGenerate = %v, %v; want wire.go rewritten", results, errs)
+	}
+	if !strings.HasPrefix(string(results[0].Content), "//go:build wireinject\n\n"+directive("")+"\n\npackage app\n") {
+		t.Errorf("wire.go rewritten to:\n%s", results[0].Content)
+	}
+	writeFile(t, results[0].Path, string(results[0].Content))
+
+	// Updating is idempotent.
+	results, errs = GoGenerate(ctx, root, env, "", []string{"./app"}, directive)
+	if len(errs) > 0 || len(results) > 0 {
+		t.Errorf("second GoGenerate returned %d files, %v; want none", len(results), errs)
+	}
+}
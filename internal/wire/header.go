@@ -0,0 +1,122 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// packageHeaderData is the data PackageHeader templates are executed with.
+type packageHeaderData struct {
+	// Dir is the directory of the package.
+	Dir string
+	// ModuleDir is the directory of the package's go.mod file, or Dir if
+	// there is none.
+	ModuleDir string
+	// Package is the name of the package.
+	Package string
+	// ImportPath is the import path of the package.
+	ImportPath string
+}
+
+// parsePackageHeader parses a PackageHeader template.
+func parsePackageHeader(tmpl string) (*template.Template, error) {
+	t, err := template.New("package_header").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid header file template: %v", err)
+	}
+	return t, nil
+}
+
+// withPackageHeader returns opts with the Header of pkg as opts.PackageHeader
+// resolves it, or opts itself if PackageHeader is empty or finds no file for
+// pkg.
+func withPackageHeader(pkg *packages.Package, opts *GenerateOptions) (*GenerateOptions, error) {
+	if opts.PackageHeader == "" {
+		return opts, nil
+	}
+	path, err := packageHeaderFile(pkg, opts.PackageHeader)
+	if err != nil || path == "" {
+		return opts, err
+	}
+	header, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header file %q: %v", path, err)
+	}
+	pkgOpts := *opts
+	pkgOpts.Header = header
+	return &pkgOpts, nil
+}
+
+// packageHeaderFile returns the path of the header file of pkg, as tmpl
+// names it, or "" if there is none. An absolute path is used as is, and
+// must exist. A relative path is looked up in the package's directory and
+// then in each of its parents up to the module root, and the nearest
+// match wins.
+func packageHeaderFile(pkg *packages.Package, tmpl string) (string, error) {
+	t, err := parsePackageHeader(tmpl)
+	if err != nil {
+		return "", err
+	}
+	dir := packageDir(pkg)
+	if dir == "" {
+		return "", nil
+	}
+	modDir := moduleDir(dir)
+	data := packageHeaderData{Dir: dir, ModuleDir: modDir, Package: pkg.Name, ImportPath: pkg.PkgPath}
+	if data.ModuleDir == "" {
+		data.ModuleDir = dir
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("invalid header file template: %v", err)
+	}
+	name := filepath.FromSlash(buf.String())
+	if filepath.IsAbs(name) {
+		return filepath.Clean(name), nil
+	}
+	for {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if dir == modDir || parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// moduleDir returns the nearest directory at or above dir that holds a
+// go.mod file, or "" if there is none.
+func moduleDir(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGeneratePackageHeader(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "header.txt"), "// Root license.\n\n")
+	writeFile(t, filepath.Join(root, "hack", "boilerplate.txt"), "// Boilerplate.\n\n")
+	writeFile(t, filepath.Join(root, "vendored", "header.txt"), "// Vendored license.\n\n")
+	for _, dir := range []string{"app", "vendored/lib", "plain"} {
+		name := filepath.Base(dir)
+		writeFile(t, filepath.Join(root, filepath.FromSlash(dir), "wire.go"), strings.Join([]string{
+			"//go:build wireinject",
+			"",
+			"package " + name,
+			"",
+			"import \"github.com/goforj/wire\"",
+			"",
+			"type Thing struct{}",
+			"",
+			"func Init() Thing {",
+			"\tpanic(wire.Build(wire.Value(Thing{})))",
+			"}",
+			"",
+		}, "\n"))
+	}
+	// plain has a header.txt of its own that is a directory, which is
+	// skipped.
+	if err := os.MkdirAll(filepath.Join(root, "plain", "header.txt"), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	tests := []struct {
+		packageHeader string
+		header        string
+		want          map[string]string
+	}{
+		{
+			packageHeader: "header.txt",
+			want: map[string]string{
+				"example.com/app/app":          "// Root license.",
+				"example.com/app/vendored/lib": "// Vendored license.",
+				"example.com/app/plain":        "// Root license.",
+			},
+		},
+		{
+			packageHeader: "{{.ModuleDir}}/hack/boilerplate.txt",
+			want: map[string]string{
+				"example.com/app/app":          "// Boilerplate.",
+				"example.com/app/vendored/lib": "// Boilerplate.",
+				"example.com/app/plain":        "// Boilerplate.",
+			},
+		},
+		{
+			packageHeader: "missing.txt",
+			header:        "// Default.\n\n",
+			want: map[string]string{
+				"example.com/app/app":          "// Default.",
+				"example.com/app/vendored/lib": "// Default.",
+				"example.com/app/plain":        "// Default.",
+			},
+		},
+	}
+	for _, test := range tests {
+		opts := &GenerateOptions{PackageHeader: test.packageHeader, Header: []byte(test.header)}
+		gens, errs := Generate(ctx, root, env, []string{"./..."}, opts)
+		if len(errs) > 0 {
+			t.Fatalf("Generate with PackageHeader %q failed: %v", test.packageHeader, errs)
+		}
+		if len(gens) != len(test.want) {
+			t.Fatalf("Generate with PackageHeader %q returned %d results; want %d", test.packageHeader, len(gens), len(test.want))
+		}
+		for _, gen := range gens {
+			if len(gen.Errs) > 0 {
+				t.Fatalf("Generate with PackageHeader %q failed for %s: %v", test.packageHeader, gen.PkgPath, gen.Errs)
+			}
+			want := test.want[gen.PkgPath]
+			if got := strings.SplitN(string(gen.Content), "\n", 2)[0]; got != want {
+				t.Errorf("Generate with PackageHeader %q starts %s with %q; want %q", test.packageHeader, gen.PkgPath, got, want)
+			}
+		}
+	}
+
+	if _, errs := Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{PackageHeader: "{{.Dir"}); len(errs) == 0 {
+		t.Error("Generate with a malformed PackageHeader template succeeded; want an error")
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// checkHermetic reports the ways in which loading pkgs depended on state
+// outside the repository: go command settings that change how packages
+// resolve but are not part of the cache key, and packages loaded from
+// somewhere other than the main modules, the module cache, or GOROOT, such
+// as GOPATH or a replace directive pointing elsewhere on disk.
+func checkHermetic(ctx context.Context, wd string, env []string, pkgs []*packages.Package) []error {
+	goenv, err := goEnvValues(ctx, wd, env, "GOROOT", "GOMODCACHE", "GOFLAGS", "GO111MODULE")
+	if err != nil {
+		return []error{err}
+	}
+	var errs []error
+	if v := goenv["GOFLAGS"]; v != "" {
+		errs = append(errs, fmt.Errorf("hermetic: GOFLAGS is set to %q, which changes how packages are loaded but is not part of the cache key", v))
+	}
+	if v := goenv["GO111MODULE"]; v != "" && v != "on" {
+		errs = append(errs, fmt.Errorf("hermetic: GO111MODULE is set to %q; packages must be resolved in module mode", v))
+	}
+	if v := lookupEnv(env, "GOWORK"); v != "" && v != "off" {
+		errs = append(errs, fmt.Errorf("hermetic: GOWORK is set to %s; use a go.work file found from the module instead", v))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	roots, err := mainModuleDirs(ctx, wd, env)
+	if err != nil {
+		return []error{err}
+	}
+	roots = append(roots, goenv["GOROOT"], goenv["GOMODCACHE"])
+	for i := range roots {
+		roots[i] = realPath(roots[i])
+	}
+	for _, pkg := range collectAllPackages(pkgs) {
+		files := packageSourceFiles(pkg)
+		if len(files) == 0 {
+			continue
+		}
+		dir := filepath.Dir(files[0])
+		if !withinAny(realPath(dir), roots) {
+			errs = append(errs, fmt.Errorf("hermetic: package %s is loaded from %s, outside the main modules, the module cache, and GOROOT", pkg.PkgPath, dir))
+		}
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errs
+}
+
+// goEnvValues returns the values of the named go environment variables as
+// seen from wd, including those set in the go env configuration file.
+func goEnvValues(ctx context.Context, wd string, env []string, names ...string) (map[string]string, error) {
+	cmd := exec.CommandContext(ctx, "go", append([]string{"env", "-json"}, names...)...)
+	cmd.Dir = wd
+	cmd.Env = env
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go env: %v", err)
+	}
+	values := make(map[string]string)
+	if err := json.Unmarshal(out, &values); err != nil {
+		return nil, fmt.Errorf("go env: %v", err)
+	}
+	return values, nil
+}
+
+// mainModuleDirs returns the directories of the main modules as seen from
+// wd: the current module, or every module of the go.work workspace.
+func mainModuleDirs(ctx context.Context, wd string, env []string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "go", "list", "-m", "-f", "{{.Dir}}")
+	cmd.Dir = wd
+	cmd.Env = env
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list -m: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// lookupEnv returns the value of name in env. As with the go command, the
+// last occurrence wins.
+func lookupEnv(env []string, name string) string {
+	value := ""
+	for _, kv := range env {
+		if strings.HasPrefix(kv, name+"=") {
+			value = kv[len(name)+1:]
+		}
+	}
+	return value
+}
+
+// realPath resolves symlinks in path, so that paths reported by different
+// tools compare equal. It returns path unchanged if it cannot be resolved.
+func realPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
+// withinAny reports whether path is one of roots or inside one of them.
+func withinAny(path string, roots []string) bool {
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
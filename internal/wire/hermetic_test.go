@@ -0,0 +1,99 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateHermetic(t *testing.T) {
+	repoRoot := mustRepoRoot(t)
+	root := t.TempDir()
+	writeAppModule(t, filepath.Join(root, "app"))
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"type A struct{}",
+		"",
+		"func NewA() A { return A{} }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"// +build wireinject",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func InitA() A {",
+		"\twire.Build(NewA)",
+		"\treturn A{}",
+		"}",
+		"",
+	}, "\n"))
+
+	var env []string
+	for _, kv := range os.Environ() {
+		switch strings.SplitN(kv, "=", 2)[0] {
+		case "GOFLAGS", "GOWORK", "GO111MODULE":
+		default:
+			env = append(env, kv)
+		}
+	}
+	env = append(env, "GOENV=off")
+	ctx := context.Background()
+	wd := filepath.Join(root, "app")
+	opts := &GenerateOptions{Hermetic: true}
+
+	_, errs := Generate(ctx, wd, append(env, "GOFLAGS=-mod=mod"), []string{"."}, opts)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "GOFLAGS") {
+		t.Errorf("Generate with GOFLAGS set errors = %v; want one about GOFLAGS", errs)
+	}
+	_, errs = Generate(ctx, wd, append(env, "GOFLAGS=-mod=mod"), []string{"."}, &GenerateOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("Generate without Hermetic returned errors: %v", errs)
+	}
+	_, errs = Generate(ctx, wd, env, []string{"."}, opts)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "package github.com/goforj/wire is loaded from") {
+		t.Errorf("Generate with a replaced module errors = %v; want one about github.com/goforj/wire", errs)
+	}
+
+	// In a workspace, the replaced module is a main module.
+	writeFile(t, filepath.Join(root, "go.work"), strings.Join([]string{
+		"go 1.19",
+		"",
+		"use (",
+		"\t./app",
+		"\t" + repoRoot,
+		")",
+		"",
+	}, "\n"))
+	gens, errs := Generate(ctx, wd, env, []string{"."}, opts)
+	if len(errs) > 0 {
+		t.Fatalf("Generate in a workspace returned errors: %v", errs)
+	}
+	if len(gens) != 1 || len(gens[0].Errs) > 0 || len(gens[0].Content) == 0 {
+		t.Fatalf("Generate in a workspace = %+v; want content", gens)
+	}
+	_, errs = Generate(ctx, wd, append(env, "GOWORK="+filepath.Join(root, "go.work")), []string{"."}, opts)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "GOWORK") {
+		t.Errorf("Generate with GOWORK set errors = %v; want one about GOWORK", errs)
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// This file loads packages from a JSON manifest written by a build system
+// such as Bazel or Please, instead of asking the go command. The manifest
+// maps import paths to the files of each package, relative to the
+// manifest's directory:
+//
+//	{
+//		"example.com/app": ["app/app.go", "app/wire.go"],
+//		"example.com/app/db": ["app/db/db.go"]
+//	}
+//
+// Files are filtered by their build constraints with the wireinject tag and
+// the requested tags. Imports of packages that are not in the manifest are
+// type-checked from source, which suits the standard library.
+
+// loadJSONManifest type-checks the packages listed in the manifest at path
+// and returns those whose import paths are in roots, or all of them if roots
+// is empty. The returned loader serves the packages from memory.
+func loadJSONManifest(ctx context.Context, path string, tags string, roots []string) ([]*packages.Package, *lazyLoader, []error) {
+	loadStart := time.Now()
+	defer logTiming(ctx, "load.json_manifest", loadStart)
+	data, err := osReadFile(path)
+	if err != nil {
+		return nil, nil, []error{fmt.Errorf("reading JSON manifest: %v", err)}
+	}
+	var files map[string][]string
+	if err := jsonUnmarshal(data, &files); err != nil {
+		return nil, nil, []error{fmt.Errorf("parsing JSON manifest %s: %v", path, err)}
+	}
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, nil, []error{err}
+	}
+	ml := &manifestLoader{
+		fset:  token.NewFileSet(),
+		dir:   dir,
+		files: files,
+		pkgs:  make(map[string]*packages.Package),
+		build: build.Default,
+	}
+	ml.build.BuildTags = append([]string{"wireinject"}, strings.Fields(tags)...)
+	ml.fallback = importer.ForCompiler(ml.fset, "source", nil)
+	if len(roots) == 0 {
+		for importPath := range files {
+			roots = append(roots, importPath)
+		}
+		sort.Strings(roots)
+	}
+	debugf(ctx, "loading %d packages from JSON manifest %s", len(roots), path)
+	var pkgs []*packages.Package
+	for _, root := range roots {
+		if _, ok := files[root]; !ok {
+			return nil, nil, []error{fmt.Errorf("package %s is not listed in JSON manifest %s", root, path)}
+		}
+		pkg, err := ml.load(root)
+		if err != nil {
+			return nil, nil, []error{err}
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	errs := collectLoadErrors(pkgs)
+	if len(errs) > 0 && !allowErrors(ctx) {
+		return nil, nil, errs
+	}
+	loader := &lazyLoader{
+		ctx:       ctx,
+		wd:        dir,
+		tags:      tags,
+		fset:      ml.fset,
+		baseFiles: collectPackageFiles(pkgs),
+		preloaded: ml.pkgs,
+	}
+	return pkgs, loader, errs
+}
+
+// manifestLoader type-checks the packages of a JSON manifest on demand.
+type manifestLoader struct {
+	fset     *token.FileSet
+	dir      string
+	files    map[string][]string
+	build    build.Context
+	fallback types.Importer
+
+	// pkgs holds the packages loaded so far, and loading the import paths
+	// being loaded, to report import cycles.
+	pkgs    map[string]*packages.Package
+	loading map[string]bool
+}
+
+// load returns the type-checked package at importPath, which must be in the
+// manifest.
+func (ml *manifestLoader) load(importPath string) (*packages.Package, error) {
+	if pkg := ml.pkgs[importPath]; pkg != nil {
+		return pkg, nil
+	}
+	if ml.loading[importPath] {
+		return nil, fmt.Errorf("import cycle through %s in JSON manifest", importPath)
+	}
+	if ml.loading == nil {
+		ml.loading = make(map[string]bool)
+	}
+	ml.loading[importPath] = true
+	defer delete(ml.loading, importPath)
+
+	pkg := &packages.Package{
+		ID:         importPath,
+		PkgPath:    importPath,
+		Fset:       ml.fset,
+		Imports:    make(map[string]*packages.Package),
+		TypesSizes: types.SizesFor("gc", runtime.GOARCH),
+		TypesInfo: &types.Info{
+			Types:      make(map[ast.Expr]types.TypeAndValue),
+			Instances:  make(map[*ast.Ident]types.Instance),
+			Defs:       make(map[*ast.Ident]types.Object),
+			Uses:       make(map[*ast.Ident]types.Object),
+			Implicits:  make(map[ast.Node]types.Object),
+			Selections: make(map[*ast.SelectorExpr]*types.Selection),
+			Scopes:     make(map[ast.Node]*types.Scope),
+		},
+	}
+	for _, name := range ml.files[importPath] {
+		path := name
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(ml.dir, name)
+		}
+		match, err := ml.build.MatchFile(filepath.Dir(path), filepath.Base(path))
+		if err != nil {
+			return nil, err
+		}
+		if !match {
+			pkg.IgnoredFiles = append(pkg.IgnoredFiles, path)
+			continue
+		}
+		f, err := parser.ParseFile(ml.fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		pkg.GoFiles = append(pkg.GoFiles, path)
+		pkg.Syntax = append(pkg.Syntax, f)
+		if pkg.Name == "" {
+			pkg.Name = f.Name.Name
+		}
+	}
+	pkg.CompiledGoFiles = pkg.GoFiles
+	for _, f := range pkg.Syntax {
+		for _, spec := range f.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil || pkg.Imports[path] != nil {
+				continue
+			}
+			if _, ok := ml.files[path]; !ok {
+				continue
+			}
+			imp, err := ml.load(path)
+			if err != nil {
+				return nil, err
+			}
+			pkg.Imports[path] = imp
+		}
+	}
+	conf := &types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			if imp := pkg.Imports[path]; imp != nil {
+				return imp.Types, nil
+			}
+			return ml.fallback.Import(path)
+		}),
+		Sizes: pkg.TypesSizes,
+		Error: func(err error) {
+			if terr, ok := err.(types.Error); ok {
+				pkg.Errors = append(pkg.Errors, packages.Error{
+					Pos:  terr.Fset.Position(terr.Pos).String(),
+					Msg:  terr.Msg,
+					Kind: packages.TypeError,
+				})
+			}
+		},
+	}
+	pkg.Types, _ = conf.Check(importPath, ml.fset, pkg.Syntax, pkg.TypesInfo)
+	pkg.IllTyped = len(pkg.Errors) > 0
+	ml.pkgs[importPath] = pkg
+	return pkg, nil
+}
+
+// importerFunc implements types.Importer with a function.
+type importerFunc func(path string) (*types.Package, error)
+
+// Import returns the package at path.
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateJSONManifest(t *testing.T) {
+	lockCacheHooks(t)
+	state := saveCacheHooks()
+	t.Cleanup(func() { restoreCacheHooks(state) })
+
+	repoRoot := mustRepoRoot(t)
+	root := t.TempDir()
+	cacheDir := t.TempDir()
+	osTempDir = func() string { return cacheDir }
+	writeFile(t, filepath.Join(root, "app", "app.go"), `package app
+
+import "example.com/app/msg"
+
+type Greeter struct{ Msg msg.Message }
+
+func NewGreeter(m msg.Message) *Greeter { return &Greeter{Msg: m} }
+`)
+	writeFile(t, filepath.Join(root, "app", "wire.go"), `//go:build wireinject
+
+package app
+
+import (
+	"example.com/app/msg"
+	"github.com/goforj/wire"
+)
+
+func InitGreeter() *Greeter {
+	panic(wire.Build(msg.Set, NewGreeter))
+}
+`)
+	writeFile(t, filepath.Join(root, "msg", "msg.go"), `package msg
+
+import (
+	"strings"
+
+	"github.com/goforj/wire"
+)
+
+type Message string
+
+func NewMessage() Message { return Message(strings.ToUpper("hi")) }
+
+var Set = wire.NewSet(NewMessage)
+`)
+	manifest, err := json.Marshal(map[string][]string{
+		"example.com/app":        {"app/app.go", "app/wire.go"},
+		"example.com/app/msg":    {"msg/msg.go"},
+		"github.com/goforj/wire": {filepath.Join(repoRoot, "wire.go")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestPath := filepath.Join(root, "wire_manifest.json")
+	writeFile(t, manifestPath, string(manifest))
+
+	// The working directory does not matter: nothing is asked of the go
+	// command.
+	opts := &GenerateOptions{JSONManifest: manifestPath}
+	gens, errs := Generate(context.Background(), t.TempDir(), nil, []string{"example.com/app"}, opts)
+	if len(errs) > 0 {
+		t.Fatalf("Generate failed: %v", errs)
+	}
+	if len(gens) != 1 || len(gens[0].Errs) > 0 {
+		t.Fatalf("Generate = %+v; want one result without errors", gens)
+	}
+	if want := "message := msg.NewMessage()"; !strings.Contains(string(gens[0].Content), want) {
+		t.Errorf("generated content does not contain %q:\n%s", want, gens[0].Content)
+	}
+	if got, want := gens[0].OutputPath, filepath.Join(root, "app", "wire_gen.go"); got != want {
+		t.Errorf("OutputPath = %q; want %q", got, want)
+	}
+
+	opts = &GenerateOptions{JSONManifest: manifestPath}
+	if _, errs := Generate(context.Background(), root, nil, []string{"example.com/missing"}, opts); len(errs) == 0 {
+		t.Error("Generate of a package missing from the manifest succeeded")
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// A LayerConfig declares the layers of a program and the directions in which
+// they may depend on each other, such as handlers on services but not
+// services on handlers. It is read from a JSON file:
+//
+//	{
+//		"layers": [
+//			{"name": "handlers", "packages": ["example.com/app/handlers/..."]},
+//			{"name": "services", "packages": ["example.com/app/services/..."]},
+//			{"name": "repo", "packages": ["example.com/app/repo/..."]}
+//		],
+//		"allow": {
+//			"handlers": ["services"],
+//			"services": ["repo"]
+//		}
+//	}
+type LayerConfig struct {
+	// Layers lists the layers. A package belongs to the first layer with a
+	// pattern matching its import path. Packages in no layer are not
+	// checked.
+	Layers []Layer `json:"layers"`
+	// Allow maps the name of each layer to those of the layers it may
+	// depend on. A layer may always depend on itself.
+	Allow map[string][]string `json:"allow"`
+}
+
+// A Layer is a named group of packages.
+type Layer struct {
+	Name string `json:"name"`
+	// Packages are import path patterns, in which "..." matches any
+	// string, as in example.com/app/services/....
+	Packages []string `json:"packages"`
+}
+
+// ReadLayerConfig reads and validates the LayerConfig in the JSON file at
+// path.
+func ReadLayerConfig(path string) (*LayerConfig, error) {
+	data, err := osReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading layer config: %v", err)
+	}
+	cfg := new(LayerConfig)
+	if err := jsonUnmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing layer config %s: %v", path, err)
+	}
+	names := make(map[string]bool)
+	for _, l := range cfg.Layers {
+		if l.Name == "" || names[l.Name] {
+			return nil, fmt.Errorf("layer config %s: layer names must be unique and not empty, got %q", path, l.Name)
+		}
+		names[l.Name] = true
+	}
+	for from, tos := range cfg.Allow {
+		for _, name := range append([]string{from}, tos...) {
+			if !names[name] {
+				return nil, fmt.Errorf("layer config %s: allow names unknown layer %q", path, name)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// layerOf returns the name of the layer of the package pkgPath, or "" if it
+// is in none.
+func (cfg *LayerConfig) layerOf(pkgPath string) string {
+	for _, l := range cfg.Layers {
+		if matchesAnyPattern(l.Packages, pkgPath) {
+			return l.Name
+		}
+	}
+	return ""
+}
+
+// allows reports whether layer from may depend on layer to.
+func (cfg *LayerConfig) allows(from, to string) bool {
+	if from == to {
+		return true
+	}
+	for _, name := range cfg.Allow[from] {
+		if name == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckLayers returns an error for each provider that the injectors call
+// with a value produced in a layer that cfg does not allow the provider's
+// layer to depend on. The injectors' graphs are where such dependencies
+// become concrete, even when no package imports the other directly. Each
+// pair of providers is reported once, at the dependent provider, sorted by
+// position.
+func CheckLayers(fset *token.FileSet, injectors []*Injector, cfg *LayerConfig) []error {
+	type violation struct {
+		pos token.Position
+		err error
+	}
+	type pair struct{ from, to interface{} }
+	var found []violation
+	seen := make(map[pair]bool)
+	for _, in := range injectors {
+		for _, step := range in.Steps {
+			p := step.Provider
+			if p == nil || p.Pkg == nil {
+				continue
+			}
+			from := cfg.layerOf(p.Pkg.Path())
+			if from == "" {
+				continue
+			}
+			for _, arg := range step.Args {
+				if arg < in.Params.Len() {
+					continue
+				}
+				dep := in.Steps[arg-in.Params.Len()]
+				src, name, pkgPath := stepSource(dep)
+				if src == nil {
+					continue
+				}
+				to := cfg.layerOf(pkgPath)
+				if to == "" || cfg.allows(from, to) || seen[pair{p, src}] {
+					continue
+				}
+				seen[pair{p, src}] = true
+				pos := fset.Position(p.Pos)
+				err := fmt.Errorf("%s.%s in layer %s depends on %s, provided by %s in layer %s, which %s may not depend on (in injector %s)",
+					p.Pkg.Path(), p.Name, from, TypeString(dep.Out), name, to, from, in.FuncName)
+				found = append(found, violation{pos: pos, err: notePosition(pos, withCode(CodeLayerViolation, err))})
+			}
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return positionLess(found[i].pos, found[j].pos) })
+	errs := make([]error, len(found))
+	for i, v := range found {
+		errs[i] = v.err
+	}
+	return errs
+}
+
+// stepSource returns the provider or field behind step, its name, and the
+// package it belongs to, or nil for values, which belong to no layer.
+func stepSource(step InjectorStep) (src interface{}, name, pkgPath string) {
+	switch {
+	case step.Provider != nil && step.Provider.Pkg != nil:
+		return step.Provider, step.Provider.Pkg.Path() + "." + step.Provider.Name, step.Provider.Pkg.Path()
+	case step.Field != nil && step.Field.Pkg != nil:
+		return step.Field, strings.TrimPrefix(TypeString(step.Field.Parent), "*") + "." + step.Field.Name, step.Field.Pkg.Path()
+	}
+	return nil, "", ""
+}
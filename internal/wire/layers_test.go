@@ -0,0 +1,109 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckLayers(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "handlers", "handlers.go"), strings.Join([]string{
+		"package handlers",
+		"",
+		"type Router struct{}",
+		"",
+		"func NewRouter() *Router { return &Router{} }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "services", "services.go"), strings.Join([]string{
+		"package services",
+		"",
+		"import \"example.com/app/handlers\"",
+		"",
+		"type Users struct{}",
+		"",
+		"func NewUsers(r *handlers.Router) *Users { return &Users{} }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package app",
+		"",
+		"import (",
+		"\t\"example.com/app/handlers\"",
+		"\t\"example.com/app/services\"",
+		"\t\"github.com/goforj/wire\"",
+		")",
+		"",
+		"func InitUsers() *services.Users {",
+		"\tpanic(wire.Build(handlers.NewRouter, services.NewUsers))",
+		"}",
+		"",
+		"func InitUsersAgain() *services.Users {",
+		"\tpanic(wire.Build(handlers.NewRouter, services.NewUsers))",
+		"}",
+		"",
+	}, "\n"))
+	cfgPath := filepath.Join(root, "layers.json")
+	writeFile(t, cfgPath, strings.Join([]string{
+		"{",
+		"  \"layers\": [",
+		"    {\"name\": \"handlers\", \"packages\": [\"example.com/app/handlers/...\"]},",
+		"    {\"name\": \"services\", \"packages\": [\"example.com/app/services/...\"]}",
+		"  ],",
+		"  \"allow\": {\"handlers\": [\"services\"]}",
+		"}",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	info, errs := Load(context.Background(), root, env, "", []string{"./app"})
+	if len(errs) > 0 {
+		t.Fatalf("Load errors: %v", errs)
+	}
+	cfg, err := ReadLayerConfig(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs = CheckLayers(info.Fset, info.Injectors, cfg)
+	if len(errs) != 1 {
+		t.Fatalf("CheckLayers = %v; want one violation reported once for both injectors", errs)
+	}
+	e := newError("", errs[0])
+	if e.Code != CodeLayerViolation || !strings.Contains(e.Msg, "example.com/app/services.NewUsers in layer services depends on") ||
+		!strings.Contains(e.Msg, "provided by example.com/app/handlers.NewRouter in layer handlers") {
+		t.Errorf("CheckLayers error = %+v; want a layer_violation of NewUsers on NewRouter", e)
+	}
+	if filepath.Base(e.Pos.Filename) != "services.go" || e.Pos.Line != 7 {
+		t.Errorf("CheckLayers error position = %v; want services.go:7", e.Pos)
+	}
+
+	cfg.Allow["services"] = []string{"handlers"}
+	if errs := CheckLayers(info.Fset, info.Injectors, cfg); len(errs) > 0 {
+		t.Errorf("CheckLayers with services allowed to depend on handlers = %v; want no errors", errs)
+	}
+
+	writeFile(t, cfgPath, `{"layers": [{"name": "a", "packages": ["x/..."]}], "allow": {"a": ["b"]}}`)
+	if _, err := ReadLayerConfig(cfgPath); err == nil || !strings.Contains(err.Error(), `unknown layer "b"`) {
+		t.Errorf("ReadLayerConfig with an unknown layer = %v; want an error naming it", err)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"go/ast"
+	"go/types"
+	"runtime"
+)
+
+// trimInfo replaces the type information that the values and hooks of info
+// refer to with the part that covers their own expressions. The syntax and
+// type information of every loaded package are otherwise reachable from
+// info for as long as it is. If ctx carries WithMetrics, the heap is
+// measured before and after, which costs two garbage collections.
+func trimInfo(ctx context.Context, info *Info) {
+	var before uint64
+	measure := metrics(ctx) != nil
+	if measure {
+		before = heapAlloc()
+	}
+	t := &infoTrimmer{sets: make(map[*ProviderSet]bool)}
+	for _, set := range info.Sets {
+		t.trimSet(set)
+	}
+	for _, in := range info.Injectors {
+		for _, step := range in.Steps {
+			t.trimValue(step.Value)
+		}
+	}
+	if measure {
+		if after := heapAlloc(); after < before {
+			logMetric(ctx, "load.lightweight.freed_bytes", int64(before-after))
+		} else {
+			logMetric(ctx, "load.lightweight.freed_bytes", 0)
+		}
+	}
+}
+
+// heapAlloc returns the bytes of live heap objects after a collection.
+func heapAlloc() uint64 {
+	runtime.GC()
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return ms.HeapAlloc
+}
+
+// infoTrimmer trims the provider sets reachable from an Info, visiting each
+// once.
+type infoTrimmer struct {
+	sets map[*ProviderSet]bool
+}
+
+func (t *infoTrimmer) trimSet(set *ProviderSet) {
+	if set == nil || t.sets[set] {
+		return
+	}
+	t.sets[set] = true
+	for _, v := range set.Values {
+		t.trimValue(v)
+	}
+	for _, hook := range set.Afters {
+		if hook.Lit != nil && hook.Info != nil {
+			hook.Info = pruneTypesInfo(hook.Info, hook.Lit)
+		}
+	}
+	for _, imp := range set.Imports {
+		t.trimSet(imp)
+	}
+}
+
+func (t *infoTrimmer) trimValue(v *Value) {
+	if v == nil || v.expr == nil || v.info == nil {
+		return
+	}
+	v.info = pruneTypesInfo(v.info, v.expr)
+}
+
+// pruneTypesInfo returns the entries of info for the nodes in the tree
+// rooted at root.
+func pruneTypesInfo(info *types.Info, root ast.Node) *types.Info {
+	pruned := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Scopes:     make(map[ast.Node]*types.Scope),
+	}
+	if info.Instances != nil {
+		pruned.Instances = make(map[*ast.Ident]types.Instance)
+	}
+	ast.Inspect(root, func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+		if e, ok := n.(ast.Expr); ok {
+			if tv, ok := info.Types[e]; ok {
+				pruned.Types[e] = tv
+			}
+		}
+		if id, ok := n.(*ast.Ident); ok {
+			if obj, ok := info.Defs[id]; ok {
+				pruned.Defs[id] = obj
+			}
+			if obj, ok := info.Uses[id]; ok {
+				pruned.Uses[id] = obj
+			}
+			if inst, ok := info.Instances[id]; ok && pruned.Instances != nil {
+				pruned.Instances[id] = inst
+			}
+		}
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if s, ok := info.Selections[sel]; ok {
+				pruned.Selections[sel] = s
+			}
+		}
+		if obj, ok := info.Implicits[n]; ok {
+			pruned.Implicits[n] = obj
+		}
+		if scope, ok := info.Scopes[n]; ok {
+			pruned.Scopes[n] = scope
+		}
+		return true
+	})
+	return pruned
+}
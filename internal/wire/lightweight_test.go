@@ -0,0 +1,90 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadLightweight(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"type Config struct{ Name string }",
+		"",
+		"type App struct{ Config Config }",
+		"",
+		"func NewApp(c Config) *App { return &App{Config: c} }",
+		"",
+		"func unrelated() int {",
+		"\tx := 1",
+		"\treturn x + 1",
+		"}",
+		"",
+		"var Set = wire.NewSet(NewApp, wire.Value(Config{Name: \"app\"}))",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func InitApp() *App {",
+		"\tpanic(wire.Build(Set))",
+		"}",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	var freed []int64
+	ctx := WithMetrics(context.Background(), func(name string, value int64) {
+		if name == "load.lightweight.freed_bytes" {
+			freed = append(freed, value)
+		}
+	})
+	info, errs := LoadWithOptions(ctx, root, env, "", []string{"./app"}, &LoadOptions{Lightweight: true})
+	if len(errs) > 0 {
+		t.Fatalf("Load failed: %v", errs)
+	}
+	set := info.Sets[ProviderSetID{ImportPath: "example.com/app/app", VarName: "Set"}]
+	if set == nil || len(set.Values) != 1 {
+		t.Fatalf("Load did not find Set with one value: %+v", set)
+	}
+	v := set.Values[0]
+	for id, obj := range v.info.Defs {
+		if obj != nil {
+			t.Errorf("trimmed type info still defines %s", id.Name)
+		}
+	}
+	if _, ok := v.info.Types[v.expr]; !ok {
+		t.Error("trimmed type info lost the type of the value expression")
+	}
+	if len(info.Injectors) != 1 || len(info.Injectors[0].Steps) != 2 {
+		t.Fatalf("Load found injectors %v; want InitApp with two steps", info.Injectors)
+	}
+	if len(freed) != 1 {
+		t.Errorf("freed_bytes reported %d times; want once", len(freed))
+	}
+}
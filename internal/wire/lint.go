@@ -0,0 +1,276 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// UnusedParamReason describes why a provider parameter is reported as
+// unused.
+type UnusedParamReason int
+
+const (
+	// ParamNeverRead means the provider's body never refers to the
+	// parameter.
+	ParamNeverRead UnusedParamReason = iota + 1
+	// ParamAlwaysZero means every injector that calls the provider passes
+	// the zero value for the parameter, such as wire.Value(Config{}).
+	ParamAlwaysZero
+)
+
+// An UnusedParam is a parameter of a provider used by an injector that has
+// no effect on the value the provider returns. Such parameters usually mean
+// the provider's signature can be trimmed.
+type UnusedParam struct {
+	// Provider is the provider the parameter belongs to.
+	Provider *Provider
+	// Index is the index of the parameter in Provider.Args.
+	Index int
+	// Name is the name of the parameter, or the field name for struct
+	// providers.
+	Name string
+	// Pos is the position of the parameter.
+	Pos token.Position
+	// Reason describes why the parameter is reported.
+	Reason UnusedParamReason
+}
+
+// String returns the parameter formatted as a lint message.
+func (u UnusedParam) String() string {
+	return fmt.Sprintf("%v: %s", u.Pos, u.message())
+}
+
+// message describes the unused parameter without its position.
+func (u UnusedParam) message() string {
+	what := "parameter " + u.Name
+	if u.Provider.IsStruct {
+		what = "field " + u.Name
+	}
+	msg := "is never used"
+	if u.Reason == ParamAlwaysZero {
+		msg = "is always the zero value"
+	}
+	return fmt.Sprintf("provider %s.%s: %s %s", u.Provider.Pkg.Name(), u.Provider.Name, what, msg)
+}
+
+// paramLinter finds the unused parameters of the providers that the
+// injectors of the initial packages call. Only providers declared in the
+// initial packages are reported, since the others are rarely the user's to
+// change.
+type paramLinter struct {
+	fset    *token.FileSet
+	initial map[string]bool
+	// neverRead holds the parameters reported as never used, by position.
+	neverRead map[string]UnusedParam
+	// zero tracks, by position, whether every call seen so far passed the
+	// zero value for a parameter.
+	zero map[string]*zeroParam
+}
+
+type zeroParam struct {
+	param UnusedParam
+	all   bool
+}
+
+func newParamLinter(fset *token.FileSet, initial map[string]bool) *paramLinter {
+	return &paramLinter{
+		fset:      fset,
+		initial:   initial,
+		neverRead: make(map[string]UnusedParam),
+		zero:      make(map[string]*zeroParam),
+	}
+}
+
+// addInjector records the calls made by in. oc must be the object cache
+// in was loaded with.
+func (l *paramLinter) addInjector(oc *objectCache, in *Injector) {
+	nparams := in.Params.Len()
+	for _, step := range in.Steps {
+		p := step.Provider
+		if p == nil || p.Pkg == nil || !l.initial[p.Pkg.Path()] {
+			continue
+		}
+		names, positions := l.providerParams(oc, p)
+		if names == nil {
+			continue
+		}
+		for i, arg := range step.Args {
+			param := UnusedParam{Provider: p, Index: i, Name: names[i], Pos: positions[i]}
+			key := param.Pos.String()
+			if !p.IsStruct && param.Name != "" && param.Name != "_" {
+				if _, done := l.neverRead[key]; !done && !l.paramRead(oc, p, i) {
+					param.Reason = ParamNeverRead
+					l.neverRead[key] = param
+				}
+			}
+			isZero := arg >= nparams && isZeroValue(in.Steps[arg-nparams].Value)
+			if z := l.zero[key]; z != nil {
+				z.all = z.all && isZero
+			} else {
+				param.Reason = ParamAlwaysZero
+				l.zero[key] = &zeroParam{param: param, all: isZero}
+			}
+		}
+	}
+}
+
+// results returns the unused parameters found, sorted by position. A
+// parameter that is never read is not also reported as always zero.
+func (l *paramLinter) results() []UnusedParam {
+	var params []UnusedParam
+	for _, p := range l.neverRead {
+		params = append(params, p)
+	}
+	for key, z := range l.zero {
+		if _, dup := l.neverRead[key]; z.all && !dup {
+			params = append(params, z.param)
+		}
+	}
+	sort.Slice(params, func(i, j int) bool {
+		return positionLess(params[i].Pos, params[j].Pos)
+	})
+	return params
+}
+
+// providerParams returns the names and positions of p's parameters, or nil
+// if p's declaration cannot be found.
+func (l *paramLinter) providerParams(oc *objectCache, p *Provider) ([]string, []token.Position) {
+	names := make([]string, len(p.Args))
+	pos := make([]token.Position, len(p.Args))
+	if p.IsStruct {
+		for i, arg := range p.Args {
+			names[i] = arg.FieldName
+			pos[i] = l.fset.Position(p.Pos)
+			if st, ok := p.Out[0].Underlying().(*types.Struct); ok {
+				for j := 0; j < st.NumFields(); j++ {
+					if f := st.Field(j); f.Name() == arg.FieldName {
+						pos[i] = l.fset.Position(f.Pos())
+					}
+				}
+			}
+		}
+		return names, pos
+	}
+	decl := l.providerDecl(oc, p)
+	if decl == nil {
+		return nil, nil
+	}
+	i := 0
+	for _, field := range decl.Type.Params.List {
+		if len(field.Names) == 0 {
+			pos[i] = l.fset.Position(field.Pos())
+			i++
+			continue
+		}
+		for _, name := range field.Names {
+			names[i] = name.Name
+			pos[i] = l.fset.Position(name.Pos())
+			i++
+		}
+	}
+	return names, pos
+}
+
+// providerDecl returns the declaration of the function provider p, or nil
+// if p is not declared by a function declaration with a body.
+func (l *paramLinter) providerDecl(oc *objectCache, p *Provider) *ast.FuncDecl {
+	pkg := oc.packages[p.Pkg.Path()]
+	if pkg == nil {
+		return nil
+	}
+	for _, f := range pkg.Syntax {
+		tf := l.fset.File(f.Pos())
+		if tf == nil || int(p.Pos) < tf.Base() || int(p.Pos) > tf.Base()+tf.Size() {
+			continue
+		}
+		path, _ := astutil.PathEnclosingInterval(f, p.Pos, p.Pos)
+		for _, node := range path {
+			if decl, ok := node.(*ast.FuncDecl); ok && decl.Body != nil && decl.Recv == nil {
+				return decl
+			}
+		}
+	}
+	return nil
+}
+
+// paramRead reports whether the body of the function provider p refers to
+// its i'th parameter.
+func (l *paramLinter) paramRead(oc *objectCache, p *Provider, i int) bool {
+	decl := l.providerDecl(oc, p)
+	info := oc.packages[p.Pkg.Path()].TypesInfo
+	var param types.Object
+	n := 0
+	for _, field := range decl.Type.Params.List {
+		for _, name := range field.Names {
+			if n == i {
+				param = info.Defs[name]
+			}
+			n++
+		}
+		if len(field.Names) == 0 {
+			n++
+		}
+	}
+	if param == nil {
+		return true
+	}
+	read := false
+	ast.Inspect(decl.Body, func(node ast.Node) bool {
+		if id, ok := node.(*ast.Ident); ok && info.Uses[id] == param {
+			read = true
+		}
+		return !read
+	})
+	return read
+}
+
+// isZeroValue reports whether v is a wire.Value of a literal zero value:
+// nil, a zero constant, or an empty composite literal.
+func isZeroValue(v *Value) bool {
+	if v == nil || v.expr == nil || v.info == nil {
+		return false
+	}
+	expr := astutil.Unparen(v.expr)
+	if lit, ok := expr.(*ast.CompositeLit); ok {
+		return len(lit.Elts) == 0
+	}
+	tv, ok := v.info.Types[expr]
+	if !ok {
+		return false
+	}
+	if tv.IsNil() {
+		return true
+	}
+	if tv.Value == nil {
+		return false
+	}
+	switch tv.Value.Kind() {
+	case constant.Bool:
+		return !constant.BoolVal(tv.Value)
+	case constant.String:
+		return constant.StringVal(tv.Value) == ""
+	case constant.Int, constant.Float, constant.Complex:
+		return constant.Sign(tv.Value) == 0
+	}
+	return false
+}
@@ -89,9 +89,9 @@ func TestLoadAndGenerateModule(t *testing.T) {
 		t.Fatalf("Load returned unexpected injectors: %+v", info.Injectors)
 	}
 
-	gens, errs := Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{})
-	if len(errs) > 0 {
-		t.Fatalf("Generate returned errors: %v", errs)
+	gens, genErrs := Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{})
+	if len(genErrs) > 0 {
+		t.Fatalf("Generate returned errors: %v", genErrs)
 	}
 	if len(gens) != 1 {
 		t.Fatalf("Generate returned %d results, want 1", len(gens))
@@ -106,9 +106,9 @@ func TestLoadAndGenerateModule(t *testing.T) {
 		t.Fatal("Generate returned empty output path")
 	}
 
-	noops, errs := Generate(ctx, root, env, []string{"./noop"}, &GenerateOptions{})
-	if len(errs) > 0 {
-		t.Fatalf("Generate noop returned errors: %v", errs)
+	noops, genErrs := Generate(ctx, root, env, []string{"./noop"}, &GenerateOptions{})
+	if len(genErrs) > 0 {
+		t.Fatalf("Generate noop returned errors: %v", genErrs)
 	}
 	if len(noops) != 1 {
 		t.Fatalf("Generate noop returned %d results, want 1", len(noops))
@@ -146,3 +146,67 @@ func writeFile(t *testing.T, path string, content string) {
 		t.Fatalf("WriteFile failed: %v", err)
 	}
 }
+
+// writeAppModule writes the go.mod of module example.com/app to dir,
+// requiring Wire from this checkout.
+func writeAppModule(t *testing.T, dir string) {
+	t.Helper()
+	writeFile(t, filepath.Join(dir, "go.mod"), strings.Join([]string{
+		"module example.com/app",
+		"",
+		"go 1.19",
+		"",
+		"require github.com/goforj/wire v0.0.0",
+		"replace github.com/goforj/wire => " + mustRepoRoot(t),
+		"",
+	}, "\n"))
+}
+
+func TestLoadPatternImportedByAnotherPattern(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "platform", "platform.go"), strings.Join([]string{
+		"package platform",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"type Logger struct{}",
+		"",
+		"func NewLogger() *Logger { return &Logger{} }",
+		"",
+		"var Set = wire.NewSet(NewLogger)",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "svc", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"// +build wireinject",
+		"",
+		"package svc",
+		"",
+		"import (",
+		"\t\"example.com/app/platform\"",
+		"\t\"github.com/goforj/wire\"",
+		")",
+		"",
+		"func Init() *platform.Logger {",
+		"\twire.Build(platform.Set)",
+		"\treturn nil",
+		"}",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	for _, patterns := range [][]string{
+		{"./platform", "./svc"},
+		{"./svc", "./platform"},
+	} {
+		info, errs := Load(ctx, root, env, "", patterns)
+		if len(errs) > 0 {
+			t.Fatalf("Load(%v) returned errors: %v", patterns, errs)
+		}
+		if len(info.Sets) != 1 || len(info.Injectors) != 1 {
+			t.Fatalf("Load(%v) = %d sets, %d injectors; want 1, 1", patterns, len(info.Sets), len(info.Injectors))
+		}
+	}
+}
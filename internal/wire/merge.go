@@ -0,0 +1,186 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// previousOutput is a previously generated file whose injectors are kept
+// when only some injectors are regenerated.
+type previousOutput struct {
+	fset  *token.FileSet
+	file  *ast.File
+	funcs map[string]*ast.FuncDecl
+	// imports maps the identifiers used for imports in the file to their
+	// import paths.
+	imports map[string]string
+	// values maps the names of the file's wire.Value variables to their
+	// declarations.
+	values map[string]*ast.ValueSpec
+}
+
+// readPreviousOutput parses the file previously generated for pkg at path.
+// It returns nil if there is no such file.
+func readPreviousOutput(pkg *packages.Package, path string) (*previousOutput, error) {
+	src, err := osReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	prev := &previousOutput{
+		fset:    fset,
+		file:    file,
+		funcs:   make(map[string]*ast.FuncDecl),
+		imports: make(map[string]string),
+		values:  make(map[string]*ast.ValueSpec),
+	}
+	all := collectAllPackages([]*packages.Package{pkg})
+	for _, imp := range file.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		switch {
+		case imp.Name != nil:
+			prev.imports[imp.Name.Name] = path
+		case all[path] != nil:
+			prev.imports[all[path].Name] = path
+		}
+	}
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil {
+				prev.funcs[decl.Name.Name] = decl
+			}
+		case *ast.GenDecl:
+			if decl.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				spec := spec.(*ast.ValueSpec)
+				if len(spec.Names) == 1 && len(spec.Values) == 1 && strings.HasPrefix(spec.Names[0].Name, "_wire") {
+					prev.values[spec.Names[0].Name] = spec
+				}
+			}
+		}
+	}
+	return prev, nil
+}
+
+// keepPrevious copies the previously generated code for the injector name,
+// along with the wire.Value variables it uses, into the output. It reports
+// false if the injector cannot be kept and must be regenerated instead.
+func (g *gen) keepPrevious(name string) bool {
+	if g.prev == nil {
+		return false
+	}
+	fn := g.prev.funcs[name]
+	if fn == nil {
+		return false
+	}
+	// Find the imports and values the injector refers to before changing
+	// anything, so that an unresolvable import leaves the output as is.
+	var pkgRefs []*ast.Ident
+	var values []*ast.ValueSpec
+	valueRefs := make(map[*ast.ValueSpec][]*ast.Ident)
+	var visit func(ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			if id, ok := n.X.(*ast.Ident); ok && g.prev.imports[id.Name] != "" {
+				pkgRefs = append(pkgRefs, id)
+			}
+		case *ast.Ident:
+			spec := g.prev.values[n.Name]
+			if spec == nil {
+				break
+			}
+			if _, seen := valueRefs[spec]; !seen {
+				valueRefs[spec] = nil
+				if _, kept := g.values[spec.Values[0]]; !kept {
+					values = append(values, spec)
+					ast.Inspect(spec.Values[0], visit)
+				}
+			}
+			valueRefs[spec] = append(valueRefs[spec], n)
+		}
+		return true
+	}
+	ast.Inspect(fn, visit)
+	all := collectAllPackages([]*packages.Package{g.pkg})
+	for _, id := range pkgRefs {
+		if all[g.prev.imports[id.Name]] == nil {
+			return false
+		}
+	}
+	renames := make(map[string]string)
+	for _, id := range pkgRefs {
+		if _, ok := renames[id.Name]; !ok {
+			path := g.prev.imports[id.Name]
+			renames[id.Name] = g.qualifyImport(all[path].Name, path)
+		}
+	}
+	for _, id := range pkgRefs {
+		id.Name = renames[id.Name]
+	}
+	for _, spec := range values {
+		// A regenerated injector may have taken the name in the meantime.
+		name := spec.Names[0].Name
+		if g.nameInFileScope(name) {
+			name = disambiguate(name, g.nameInFileScope)
+		}
+		g.values[spec.Values[0]] = name
+	}
+	for spec, ids := range valueRefs {
+		for _, id := range ids {
+			id.Name = g.values[spec.Values[0]]
+		}
+	}
+
+	if err := printer.Fprint(&g.buf, g.prev.fset, &printer.CommentedNode{Node: fn, Comments: g.prev.file.Comments}); err != nil {
+		panic(err)
+	}
+	g.p("\n\n")
+	if len(values) > 0 {
+		g.p("var (\n")
+		for _, spec := range values {
+			g.p("\t%s = ", g.values[spec.Values[0]])
+			if err := printer.Fprint(&g.buf, g.prev.fset, spec.Values[0]); err != nil {
+				panic(err)
+			}
+			g.p("\n")
+		}
+		g.p(")\n\n")
+	}
+	return true
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// moduleDownloadPatterns are parts of the go command's messages for modules
+// it could not download, from a module proxy or from version control.
+var moduleDownloadPatterns = []string{
+	"module lookup disabled",
+	"dial tcp",
+	"no such host",
+	"i/o timeout",
+	"connection refused",
+	"TLS handshake timeout",
+	"terminal prompts disabled",
+	"could not read Username",
+	"unrecognized import path",
+	"410 Gone",
+	"404 Not Found",
+	"verifying module",
+}
+
+// isModuleDownloadFailure reports whether msg, an error from the go command,
+// says that a module could not be downloaded.
+func isModuleDownloadFailure(msg string) bool {
+	for _, p := range moduleDownloadPatterns {
+		if strings.Contains(msg, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// moduleDownloadHint suggests how to fix the module download failure msg
+// describes.
+func moduleDownloadHint(msg string) string {
+	const notWire = "the go command could not download a module, which is not a Wire error; "
+	switch {
+	case strings.Contains(msg, "module lookup disabled"):
+		return notWire + "downloads are disabled (GOPROXY=off or wire -offline), so run go mod download with network access first, or vendor the dependencies and set GOFLAGS=-mod=vendor"
+	case strings.Contains(msg, "terminal prompts disabled"),
+		strings.Contains(msg, "could not read Username"),
+		strings.Contains(msg, "410 Gone"),
+		strings.Contains(msg, "404 Not Found"),
+		strings.Contains(msg, "verifying module"):
+		return notWire + "if the module is private, set GOPRIVATE to a pattern matching its path, such as go env -w GOPRIVATE=example.com/*, so that it is fetched directly and not checked against the public checksum database"
+	}
+	return notWire + "if the network or module proxy is unreachable, set GOPROXY to a proxy that is, or run go mod download while online and pass -offline to fail fast instead of waiting on the network"
+}
+
+// classifyPackageError returns e with a hint and CodeModuleDownload if it
+// reports a module download failure, or e itself otherwise.
+func classifyPackageError(e packages.Error) error {
+	if !isModuleDownloadFailure(e.Msg) {
+		return e
+	}
+	e.Msg += "\n" + moduleDownloadHint(e.Msg)
+	return withCode(CodeModuleDownload, e)
+}
+
+// classifyLoadError is like classifyPackageError for an error that the build
+// system returned for a whole load, such as a failed go list.
+func classifyLoadError(err error) error {
+	if err == nil || !isModuleDownloadFailure(err.Error()) {
+		return err
+	}
+	return withCode(CodeModuleDownload, fmt.Errorf("%v\n%s", err, moduleDownloadHint(err.Error())))
+}
+
+// A dependencyError is the error of a dependency that could not be
+// downloaded.
+type dependencyError struct {
+	path string
+	err  packages.Error
+}
+
+// dependencyDownloadErrors returns the errors of the dependencies of p that
+// report module download failures, sorted by import path. The go command
+// reports such a failure on the missing package, and its importers only
+// fail to import it.
+func dependencyDownloadErrors(p *packages.Package) []dependencyError {
+	var failed []dependencyError
+	packages.Visit([]*packages.Package{p}, nil, func(dep *packages.Package) {
+		if dep == p {
+			return
+		}
+		for _, e := range dep.Errors {
+			if isModuleDownloadFailure(e.Msg) {
+				failed = append(failed, dependencyError{path: dep.PkgPath, err: e})
+				return
+			}
+		}
+	})
+	sort.Slice(failed, func(i, j int) bool { return failed[i].path < failed[j].path })
+	return failed
+}
+
+// importsFailedDownload reports whether msg is an error importing one of the
+// packages in failed.
+func importsFailedDownload(msg string, failed []dependencyError) bool {
+	for _, dep := range failed {
+		if strings.Contains(msg, "could not import "+dep.path+" ") || strings.HasSuffix(msg, "could not import "+dep.path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateModuleDownloadOffline(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), strings.Join([]string{
+		"module example.com/app",
+		"",
+		"go 1.19",
+		"",
+		"require example.com/missing v1.0.0",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"import _ \"example.com/missing/lib\"",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off", "GOPROXY=off", "GOFLAGS=-mod=mod")
+	_, errs := Generate(context.Background(), root, env, []string{"."}, &GenerateOptions{})
+	if len(errs) == 0 {
+		t.Fatal("Generate succeeded without the module; want an error")
+	}
+	for _, e := range errs {
+		if e.Code != CodeModuleDownload || !strings.Contains(e.Msg, "go mod download") {
+			t.Errorf("Generate error = %+v; want a module_download error suggesting go mod download", e)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// defaultOutputFileName is the name of the generated file when
+// GenerateOptions.OutputFileName is empty.
+const defaultOutputFileName = "wire_gen.go"
+
+// outputNameData is the data OutputFileName templates are executed with.
+type outputNameData struct {
+	// Package is the name of the package the file is generated for.
+	Package string
+}
+
+// parseOutputFileName parses an OutputFileName template.
+func parseOutputFileName(tmpl string) (*template.Template, error) {
+	t, err := template.New("output_file_name").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output file name template: %v", err)
+	}
+	return t, nil
+}
+
+// outputFileNameFor returns the name of the file generated for the package
+// named pkgName: prefix followed by tmpl executed with the package name, or
+// by wire_gen.go if tmpl is empty. The name must be that of a non-test Go
+// file the go command builds.
+func outputFileNameFor(prefix, tmpl, pkgName string) (string, error) {
+	if tmpl == "" {
+		return prefix + defaultOutputFileName, nil
+	}
+	t, err := parseOutputFileName(tmpl)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, outputNameData{Package: pkgName}); err != nil {
+		return "", fmt.Errorf("invalid output file name template: %v", err)
+	}
+	name := prefix + buf.String()
+	switch {
+	case strings.ContainsAny(name, `/\`):
+		return "", fmt.Errorf("output file name %q must not contain a path separator", name)
+	case !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go"):
+		return "", fmt.Errorf("output file name %q must end in .go and not _test.go", name)
+	case strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_"):
+		return "", fmt.Errorf("output file name %q must not start with . or _, which the go command ignores", name)
+	}
+	return name, nil
+}
+
+// testOutputFileName returns the name of the test output generated along
+// with the file named name, for the external test package if external is
+// set.
+func testOutputFileName(name string, external bool) string {
+	if external {
+		return strings.TrimSuffix(name, ".go") + "_external_test.go"
+	}
+	return strings.TrimSuffix(name, ".go") + "_test.go"
+}
@@ -0,0 +1,38 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import "context"
+
+// This file supports the Overlay options, which load unsaved file contents
+// in place of the files on disk.
+
+type overlayKey struct{}
+
+// withOverlay makes the loads run with ctx read the contents in overlay
+// instead of the files at its paths. An empty overlay leaves ctx unchanged.
+func withOverlay(ctx context.Context, overlay map[string][]byte) context.Context {
+	if len(overlay) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, overlayKey{}, overlay)
+}
+
+// loadOverlay returns the overlay of the loads run with ctx, or nil if
+// there is none.
+func loadOverlay(ctx context.Context) map[string][]byte {
+	overlay, _ := ctx.Value(overlayKey{}).(map[string][]byte)
+	return overlay
+}
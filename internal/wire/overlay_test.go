@@ -0,0 +1,81 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOverlay(t *testing.T) {
+	lockCacheHooks(t)
+	state := saveCacheHooks()
+	t.Cleanup(func() { restoreCacheHooks(state) })
+	tempDir := t.TempDir()
+	osTempDir = func() string { return tempDir }
+
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"func NewMessage() string { return \"hello\" }",
+		"",
+	}, "\n"))
+	wireFile := filepath.Join(root, "app", "wire.go")
+	injector := func(name string) string {
+		return strings.Join([]string{
+			"//go:build wireinject",
+			"",
+			"package app",
+			"",
+			"import \"github.com/goforj/wire\"",
+			"",
+			"func " + name + "() string {",
+			"\tpanic(wire.Build(NewMessage))",
+			"}",
+			"",
+		}, "\n")
+	}
+	writeFile(t, wireFile, injector("InitSaved"))
+	overlay := map[string][]byte{wireFile: []byte(injector("InitUnsaved"))}
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	for _, test := range []struct {
+		overlay map[string][]byte
+		want    string
+	}{
+		{nil, "InitSaved"},
+		{overlay, "InitUnsaved"},
+		// The overlay's result must not be served from the cache.
+		{nil, "InitSaved"},
+	} {
+		info, errs := LoadWithOptions(ctx, root, env, "", []string{"./app"}, &LoadOptions{Overlay: test.overlay})
+		if len(errs) > 0 || len(info.Injectors) != 1 || info.Injectors[0].FuncName != test.want {
+			t.Fatalf("LoadWithOptions with %d overlay files = %v, %v; want injector %s", len(test.overlay), info, errs, test.want)
+		}
+		gens, genErrs := Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{Overlay: test.overlay})
+		if len(genErrs) > 0 || len(gens) != 1 || len(gens[0].Errs) > 0 {
+			t.Fatalf("Generate with %d overlay files failed: %v %v", len(test.overlay), genErrs, gens)
+		}
+		if !strings.Contains(string(gens[0].Content), "func "+test.want+"() string") {
+			t.Errorf("Generate with %d overlay files wrote:\n%s\nwant injector %s", len(test.overlay), gens[0].Content, test.want)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// A PackageFilter selects the packages that FilterPackages keeps.
+type PackageFilter struct {
+	// Match, if not nil, keeps only the packages whose import path it
+	// matches.
+	Match *regexp.Regexp
+
+	// Skip lists import path patterns, which may use the go command's
+	// "..." wildcard, of packages to leave out of wildcard patterns.
+	Skip []string
+
+	// IncludeSkipped keeps the packages that wildcard patterns leave out by
+	// default: packages in vendor and testdata directories, unless the
+	// pattern names such a directory itself, and packages whose Go files
+	// are all generated, such as protocol buffer packages.
+	IncludeSkipped bool
+}
+
+// FilterPackages expands the given patterns and returns the import paths of
+// the resulting packages that filter keeps: first those of the patterns that
+// name a package, then those of the wildcard patterns, each in the order the
+// build system reported them. Packages named by a pattern without a
+// wildcard are never skipped. It only loads package names and files, so it
+// is much cheaper than Load or Generate and is intended to narrow their
+// patterns.
+func FilterPackages(ctx context.Context, wd string, env []string, tags string, patterns []string, filter *PackageFilter) ([]string, error) {
+	if filter == nil {
+		filter = &PackageFilter{}
+	}
+	var plain, wild []string
+	for _, p := range patterns {
+		if strings.Contains(p, "...") {
+			wild = append(wild, p)
+		} else {
+			plain = append(plain, p)
+		}
+	}
+	var kept []string
+	seen := make(map[string]bool)
+	keep := func(path string) {
+		if !seen[path] && (filter.Match == nil || filter.Match.MatchString(path)) {
+			seen[path] = true
+			kept = append(kept, path)
+		}
+	}
+	if len(plain) > 0 {
+		pkgs, err := listPackages(ctx, wd, env, tags, plain, packages.NeedName)
+		if err != nil {
+			return nil, err
+		}
+		for _, pkg := range pkgs {
+			keep(pkg.PkgPath)
+		}
+	}
+	skipped := 0
+	if len(wild) > 0 {
+		pkgs, err := listPackages(ctx, wd, env, tags, wild, packages.NeedName|packages.NeedFiles)
+		if err != nil {
+			return nil, err
+		}
+		for _, pkg := range pkgs {
+			if matchesAnyPattern(filter.Skip, pkg.PkgPath) || !filter.IncludeSkipped && (inSkippedDir(pkg.PkgPath, wild) || generatedOnly(pkg)) {
+				skipped++
+				continue
+			}
+			keep(pkg.PkgPath)
+		}
+	}
+	if len(kept) == 0 {
+		msg := "no packages found in " + strings.Join(patterns, " ")
+		if filter.Match != nil {
+			msg = fmt.Sprintf("no packages matching %s found in %s", filter.Match, strings.Join(patterns, " "))
+		}
+		if skipped > 0 {
+			msg += fmt.Sprintf(" (%d skipped)", skipped)
+		}
+		return nil, fmt.Errorf("%s", msg)
+	}
+	return kept, nil
+}
+
+// listPackages runs the build system on patterns, loading only what mode
+// asks for.
+func listPackages(ctx context.Context, wd string, env []string, tags string, patterns []string, mode packages.LoadMode) ([]*packages.Package, error) {
+	cfg := &packages.Config{
+		Context:    ctx,
+		Mode:       mode,
+		Dir:        wd,
+		Env:        env,
+		BuildFlags: []string{"-tags=wireinject"},
+		Overlay:    loadOverlay(ctx),
+	}
+	if len(tags) > 0 {
+		cfg.BuildFlags[0] += " " + tags
+	}
+	escaped := make([]string, len(patterns))
+	for i := range patterns {
+		escaped[i] = "pattern=" + patterns[i]
+	}
+	pkgs, err := packages.Load(cfg, escaped...)
+	return pkgs, classifyLoadError(err)
+}
+
+// matchesAnyPattern reports whether path matches one of the import path
+// patterns. As with the go command, "..." matches any string, and a
+// pattern ending in "/..." also matches the path before it.
+func matchesAnyPattern(patterns []string, path string) bool {
+	for _, p := range patterns {
+		re := regexp.QuoteMeta(p)
+		re = strings.ReplaceAll(re, `\.\.\.`, `.*`)
+		if strings.HasSuffix(re, `/.*`) {
+			re = strings.TrimSuffix(re, `/.*`) + `(/.*)?`
+		}
+		if regexp.MustCompile(`^` + re + `$`).MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// inSkippedDir reports whether path has a vendor or testdata element that
+// none of the wildcard patterns names.
+func inSkippedDir(path string, wild []string) bool {
+	for _, dir := range []string{"vendor", "testdata"} {
+		if !hasPathElement(path, dir) {
+			continue
+		}
+		named := false
+		for _, p := range wild {
+			named = named || hasPathElement(p, dir)
+		}
+		if !named {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPathElement reports whether the slash-separated path has elem as one
+// of its elements.
+func hasPathElement(path, elem string) bool {
+	for _, e := range strings.Split(path, "/") {
+		if e == elem {
+			return true
+		}
+	}
+	return false
+}
+
+// generatedHeader matches the comment that, by Go convention, marks a file
+// as generated.
+var generatedHeader = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// generatedOnly reports whether all of pkg's Go files are generated. A
+// package without Go files, such as one that only holds the output of an
+// injector file that was deleted, is not.
+func generatedOnly(pkg *packages.Package) bool {
+	if len(pkg.GoFiles) == 0 {
+		return false
+	}
+	for _, path := range pkg.GoFiles {
+		if !hasGeneratedHeader(path) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasGeneratedHeader reports whether the Go file at path has a generated
+// code comment before its package clause.
+func hasGeneratedHeader(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if generatedHeader.Match(line) {
+			return true
+		}
+		if bytes.HasPrefix(line, []byte("package ")) {
+			return false
+		}
+	}
+	return false
+}
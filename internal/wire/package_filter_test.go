@@ -0,0 +1,100 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFilterPackages(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/mono\n\ngo 1.19\n")
+	writeFile(t, filepath.Join(root, "app", "app.go"), "package app\n")
+	writeFile(t, filepath.Join(root, "pb", "pb.go"), "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage pb\n")
+	writeFile(t, filepath.Join(root, "mixed", "gen.go"), "// Code generated by stringer. DO NOT EDIT.\n\npackage mixed\n")
+	writeFile(t, filepath.Join(root, "mixed", "mixed.go"), "package mixed\n")
+	writeFile(t, filepath.Join(root, "legacy", "old", "old.go"), "package old\n")
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		patterns []string
+		filter   *PackageFilter
+		want     []string
+	}{
+		{
+			name:     "Default",
+			patterns: []string{"./..."},
+			want:     []string{"example.com/mono/app", "example.com/mono/legacy/old", "example.com/mono/mixed"},
+		},
+		{
+			name:     "Skip",
+			patterns: []string{"./..."},
+			filter:   &PackageFilter{Skip: []string{"example.com/mono/legacy/..."}},
+			want:     []string{"example.com/mono/app", "example.com/mono/mixed"},
+		},
+		{
+			name:     "IncludeSkipped",
+			patterns: []string{"./..."},
+			filter:   &PackageFilter{IncludeSkipped: true},
+			want:     []string{"example.com/mono/app", "example.com/mono/legacy/old", "example.com/mono/mixed", "example.com/mono/pb"},
+		},
+		{
+			name:     "Named",
+			patterns: []string{"./pb", "./legacy/..."},
+			filter:   &PackageFilter{Skip: []string{"example.com/mono/pb"}},
+			want:     []string{"example.com/mono/pb", "example.com/mono/legacy/old"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := FilterPackages(ctx, root, env, "", test.patterns, test.filter)
+			if err != nil {
+				t.Fatalf("FilterPackages failed: %v", err)
+			}
+			if strings.Join(got, " ") != strings.Join(test.want, " ") {
+				t.Errorf("FilterPackages = %v; want %v", got, test.want)
+			}
+		})
+	}
+	_, err := FilterPackages(ctx, root, env, "", []string{"./pb/..."}, nil)
+	if err == nil || err.Error() != "no packages found in ./pb/... (1 skipped)" {
+		t.Errorf("FilterPackages of only skipped packages: err = %v", err)
+	}
+}
+
+func TestInSkippedDir(t *testing.T) {
+	tests := []struct {
+		path string
+		wild []string
+		want bool
+	}{
+		{"example.com/m/a", []string{"./..."}, false},
+		{"example.com/m/vendor/x", []string{"./..."}, true},
+		{"example.com/m/a/testdata/x", []string{"example.com/m/..."}, true},
+		{"example.com/m/a/testdata/x", []string{"./a/testdata/..."}, false},
+		{"example.com/m/testdatax", []string{"./..."}, false},
+	}
+	for _, test := range tests {
+		if got := inSkippedDir(test.path, test.wild); got != test.want {
+			t.Errorf("inSkippedDir(%q, %q) = %t; want %t", test.path, test.wild, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// tagsDirective is the comment that adds build tags for a single package.
+// Placed in a wireinject file before the package clause, as in
+//
+//	//wire:tags integration
+//
+// it makes wire load that package with the given tags in addition to those
+// passed on the command line.
+const tagsDirective = "//wire:tags"
+
+// packageTags returns the build tags requested by the //wire:tags
+// directives in the wireinject files of pkg, in the order they appear. Files
+// excluded by their build constraints are included, since the directive is
+// how such a file asks for the tags it needs.
+func packageTags(pkg *packages.Package) ([]string, error) {
+	return packageDirective(pkg, tagsDirective)
+}
+
+// packageDirective returns the values listed by the directive comments in
+// the wireinject files of pkg, as packageTags does for //wire:tags.
+func packageDirective(pkg *packages.Package, directive string) ([]string, error) {
+	fset := token.NewFileSet()
+	var values []string
+	seen := make(map[string]bool)
+	for i, path := range append(append([]string(nil), pkg.GoFiles...), pkg.IgnoredFiles...) {
+		ignored := i >= len(pkg.GoFiles)
+		if ignored && !strings.HasSuffix(path, ".go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil {
+			if ignored {
+				continue
+			}
+			return nil, err
+		}
+		if !isWireinjectFile(f) {
+			continue
+		}
+		for _, v := range directiveValues(f, directive) {
+			if !seen[v] {
+				seen[v] = true
+				values = append(values, v)
+			}
+		}
+	}
+	return values, nil
+}
+
+// isWireinjectFile reports whether f is constrained to the wireinject build
+// tag.
+func isWireinjectFile(f *ast.File) bool {
+	for _, cg := range f.Comments {
+		if cg.Pos() > f.Package {
+			break
+		}
+		for _, c := range cg.List {
+			if (strings.HasPrefix(c.Text, "//go:build") || strings.HasPrefix(c.Text, "// +build")) && strings.Contains(c.Text, "wireinject") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// directiveValues returns the values listed by the directive comments, such
+// as //wire:tags, in the header of f. Values may be separated by spaces or
+// commas.
+func directiveValues(f *ast.File, directive string) []string {
+	var values []string
+	for _, cg := range f.Comments {
+		if cg.Pos() > f.Package {
+			break
+		}
+		for _, c := range cg.List {
+			rest := strings.TrimPrefix(c.Text, directive)
+			if rest == c.Text || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+				continue
+			}
+			values = append(values, strings.FieldsFunc(rest, func(r rune) bool {
+				return r == ',' || r == ' ' || r == '\t'
+			})...)
+		}
+	}
+	return values
+}
+
+// appendTags adds extra to the space-separated build tags in tags.
+func appendTags(tags string, extra []string) string {
+	return strings.TrimSpace(tags + " " + strings.Join(extra, " "))
+}
+
+// reloadWithTags loads pkg again with the tags its //wire:tags directives
+// request, if any. It returns the new package, its loader and the combined
+// tags, or nil if pkg has no directives. The package is loaded into fset so
+// that its positions can be mixed with those of the original load.
+func reloadWithTags(ctx context.Context, wd string, env []string, tags string, pkg *packages.Package, fset *token.FileSet) (*packages.Package, *lazyLoader, string, []error) {
+	extra, err := packageTags(pkg)
+	if err != nil {
+		return nil, nil, "", []error{err}
+	}
+	if len(extra) == 0 {
+		return nil, nil, "", nil
+	}
+	tags = appendTags(tags, extra)
+	debugf(ctx, "%s: //wire:tags asks for %s, reloading with tags %q", pkg.PkgPath, strings.Join(extra, " "), tags)
+	pkgs, loader, errs := loadInto(ctx, fset, wd, env, tags, []string{pkg.PkgPath})
+	if len(pkgs) == 0 && len(errs) > 0 {
+		return nil, nil, "", errs
+	}
+	for _, p := range pkgs {
+		if p.PkgPath == pkg.PkgPath {
+			return p, loader, tags, nil
+		}
+	}
+	return nil, nil, "", []error{fmt.Errorf("%s: package not found when loading with tags %q", pkg.PkgPath, tags)}
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPackageTagsDirective(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	// The tagged package only has a provider under the integration tag.
+	writeFile(t, filepath.Join(root, "tagged", "db.go"), strings.Join([]string{
+		"//go:build integration",
+		"",
+		"package tagged",
+		"",
+		"type DB struct{}",
+		"",
+		"func NewDB() *DB { return &DB{} }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "tagged", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"//wire:tags integration",
+		"",
+		"package tagged",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func InitDB() *DB {",
+		"\tpanic(wire.Build(NewDB))",
+		"}",
+		"",
+	}, "\n"))
+	// The plain package breaks if the integration tag leaks into its load.
+	writeFile(t, filepath.Join(root, "plain", "db.go"), strings.Join([]string{
+		"//go:build !integration",
+		"",
+		"package plain",
+		"",
+		"type DB struct{}",
+		"",
+		"func NewDB() *DB { return &DB{} }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "plain", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package plain",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func InitDB() *DB {",
+		"\tpanic(wire.Build(NewDB))",
+		"}",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	gens, genErrs := Generate(ctx, root, env, []string{"./..."}, &GenerateOptions{})
+	if len(genErrs) > 0 {
+		t.Fatalf("Generate failed: %v", genErrs)
+	}
+	if len(gens) != 2 {
+		t.Fatalf("Generate returned %d results; want 2", len(gens))
+	}
+	for _, gen := range gens {
+		if len(gen.Errs) > 0 {
+			t.Errorf("Generate %s: %v", gen.PkgPath, gen.Errs)
+			continue
+		}
+		if !strings.Contains(string(gen.Content), "func InitDB() *DB {") {
+			t.Errorf("Generate %s output:\n%s\nwant it to contain InitDB", gen.PkgPath, gen.Content)
+		}
+		tagged := gen.PkgPath == "example.com/app/tagged"
+		if got := strings.Contains(string(gen.Content), "-tags \"integration\""); got != tagged {
+			t.Errorf("Generate %s go:generate line has integration tag = %t; want %t\n%s", gen.PkgPath, got, tagged, gen.Content)
+		}
+		constraint := "//go:build !wireinject\n// +build !wireinject\n"
+		if tagged {
+			constraint = "//go:build !wireinject && integration\n// +build !wireinject,integration\n"
+		}
+		if !strings.Contains(string(gen.Content), constraint) {
+			t.Errorf("Generate %s output:\n%s\nwant build constraint %q", gen.PkgPath, gen.Content, constraint)
+		}
+	}
+
+	info, errs := Load(ctx, root, env, "", []string{"./..."})
+	if len(errs) > 0 {
+		t.Fatalf("Load failed: %v", errs)
+	}
+	if len(info.Injectors) != 2 {
+		t.Errorf("Load found %d injectors; want 2", len(info.Injectors))
+	}
+}
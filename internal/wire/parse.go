@@ -23,6 +23,8 @@ import (
 	"go/types"
 	"os"
 	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -73,6 +75,36 @@ func (p *providerSetSrc) description(fset *token.FileSet, typ types.Type) string
 	panic("providerSetSrc with no fields set")
 }
 
+// memberName names p, a member of a provider set, for suggestions. It
+// returns "" if p is not a provider or a named provider set.
+func (p *providerSetSrc) memberName() string {
+	switch {
+	case p.Provider != nil && p.Provider.Pkg != nil:
+		return fmt.Sprintf("provider %s.%s", p.Provider.Pkg.Path(), p.Provider.Name)
+	case p.Import != nil && p.Import.VarName != "":
+		return fmt.Sprintf("provider set %s.%s", p.Import.PkgPath, p.Import.VarName)
+	}
+	return ""
+}
+
+// member returns the provider, binding, value, set, or field behind p, or
+// nil if p is an injector argument.
+func (p *providerSetSrc) member() interface{} {
+	switch {
+	case p.Provider != nil:
+		return p.Provider
+	case p.Binding != nil:
+		return p.Binding
+	case p.Value != nil:
+		return p.Value
+	case p.Import != nil:
+		return p.Import
+	case p.Field != nil:
+		return p.Field
+	}
+	return nil
+}
+
 // trace returns a slice of strings describing the (possibly recursive) source
 // of p, including line numbers.
 func (p *providerSetSrc) trace(fset *token.FileSet, typ types.Type) []string {
@@ -104,7 +136,10 @@ type ProviderSet struct {
 	Values    []*Value
 	Fields    []*Field
 	Imports   []*ProviderSet
-	// InjectorArgs is only filled in for wire.Build.
+	Requires  []*Requirement
+	Copies    []*CopiedType
+	// Afters and InjectorArgs are only filled in for wire.Build.
+	Afters       []*AfterHook
 	InjectorArgs *InjectorArgs
 
 	// providerMap maps from provided type to a *ProvidedType.
@@ -114,6 +149,102 @@ type ProviderSet struct {
 	// srcMap maps from provided type to a *providerSetSrc capturing the
 	// Provider, Binding, Value, or Import that provided the type.
 	srcMap *typeutil.Map
+
+	// argPos maps the providers, sets, bindings, values, and fields passed
+	// to the call that created the set to the positions of the arguments
+	// that named them, so that errors about them point at the argument
+	// rather than the whole call.
+	argPos map[interface{}]token.Pos
+
+	// pending lists the bindings of set and the sets it imports whose
+	// concrete type no provider in those sets builds. They are resolved
+	// against the injector arguments by the set passed to wire.Build.
+	pending []pendingBinding
+
+	// shadowed records the sources whose context.Context output was
+	// replaced by the injector's context argument under AutoContext. They
+	// count as used.
+	shadowed []*providerSetSrc
+
+	// stableOrder is set on the sets passed to wire.Build when the calls
+	// of their injectors are ordered by GenerateOptions.StableOrder.
+	stableOrder bool
+
+	// testBuild reports whether the set comes from wire.TestBuild, and
+	// fakes lists the fake providers passed to it.
+	testBuild bool
+	fakes     []*Provider
+
+	// mustBuild reports whether the set comes from wire.MustBuild, whose
+	// injector panics when a provider fails instead of returning the error.
+	mustBuild bool
+}
+
+// argPosition returns the position of the argument that added item to set,
+// or the position of the call that created set if it is not known.
+func (set *ProviderSet) argPosition(item interface{}) token.Pos {
+	if pos, ok := set.argPos[item]; ok {
+		return pos
+	}
+	return set.Pos
+}
+
+// noteArgPos records that the argument at pos added item to set.
+func (set *ProviderSet) noteArgPos(item interface{}, pos token.Pos) {
+	if set.argPos == nil {
+		set.argPos = make(map[interface{}]token.Pos)
+	}
+	set.argPos[item] = pos
+}
+
+// A pendingBinding is a binding whose concrete type may be an injector
+// argument.
+type pendingBinding struct {
+	binding *IfaceBinding
+	// setName names the set that declared the binding, for errors.
+	setName string
+}
+
+// requirements returns the types required by set and the sets it imports,
+// in the order they are declared, without duplicates.
+func (set *ProviderSet) requirements() []*Requirement {
+	var reqs []*Requirement
+	var seen typeutil.Map
+	var visit func(set *ProviderSet)
+	visit = func(set *ProviderSet) {
+		for _, imp := range set.Imports {
+			visit(imp)
+		}
+		for _, r := range set.Requires {
+			if seen.At(r.Type) == nil {
+				seen.Set(r.Type, true)
+				reqs = append(reqs, r)
+			}
+		}
+	}
+	visit(set)
+	return reqs
+}
+
+// copies returns the types that set and the sets it imports mark with
+// wire.Copy, without duplicates.
+func (set *ProviderSet) copies() []*CopiedType {
+	var copies []*CopiedType
+	var seen typeutil.Map
+	var visit func(set *ProviderSet)
+	visit = func(set *ProviderSet) {
+		for _, imp := range set.Imports {
+			visit(imp)
+		}
+		for _, c := range set.Copies {
+			if seen.At(c.Type) == nil {
+				seen.Set(c.Type, true)
+				copies = append(copies, c)
+			}
+		}
+	}
+	visit(set)
+	return copies
 }
 
 // Outputs returns a new slice containing the set of possible types the
@@ -131,6 +262,33 @@ func (set *ProviderSet) For(t types.Type) ProvidedType {
 	return *pt.(*ProvidedType)
 }
 
+// InterfaceSource reports how set provides the interface type iface: the
+// wire.Bind declaration binding a concrete type to it, in set or in a set it
+// imports, or the wire.InterfaceValue supplying it. Both are nil if iface
+// is not an output of set or is provided some other way, such as by a
+// provider function returning the interface.
+func (set *ProviderSet) InterfaceSource(iface types.Type) (*IfaceBinding, *Value) {
+	for set != nil && set.srcMap != nil {
+		src, _ := set.srcMap.At(iface).(*providerSetSrc)
+		switch {
+		case src == nil:
+			return nil, nil
+		case src.Import != nil:
+			set = src.Import
+		case src.Binding != nil:
+			return src.Binding, nil
+		case src.Value != nil:
+			if types.IsInterface(src.Value.Out) {
+				return nil, src.Value
+			}
+			return nil, nil
+		default:
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
 // An IfaceBinding declares that a type should be used to satisfy inputs
 // of the given interface type.
 type IfaceBinding struct {
@@ -144,8 +302,47 @@ type IfaceBinding struct {
 	Pos token.Pos
 }
 
+// A Requirement declares that an injector must build a type, even if its
+// output does not depend on it.
+type Requirement struct {
+	// Type is the required type.
+	Type types.Type
+
+	// Pos is the position of the call to wire.Require.
+	Pos token.Pos
+}
+
+// A CopiedType declares that each consumer of a type in an injector gets a
+// value of its own, built by calling the type's provider again.
+type CopiedType struct {
+	// Type is the copied type.
+	Type types.Type
+
+	// Pos is the position of the call to wire.Copy.
+	Pos token.Pos
+}
+
+// An AfterHook is a function literal passed to wire.After, which the
+// injector calls once it has built its output.
+type AfterHook struct {
+	// Params are the types of the hook's parameters, which the injector
+	// builds like its output.
+	Params []types.Type
+	// Err is true if the hook returns an error.
+	Err bool
+
+	// Lit is the function literal and Info the type information of the
+	// package it is declared in, for copying it into the injector.
+	Lit  *ast.FuncLit
+	Info *types.Info
+
+	// Pos is the position of the call to wire.After.
+	Pos token.Pos
+}
+
 // Provider records the signature of a provider. A provider is a
-// single Go object, either a function or a named struct type.
+// single Go object, either a function, a package-level variable of
+// function type, or a named struct type.
 type Provider struct {
 	// Pkg is the package that the Go object resides in.
 	Pkg *types.Package
@@ -153,8 +350,8 @@ type Provider struct {
 	// Name is the name of the Go object.
 	Name string
 
-	// Pos is the source position of the func keyword or type spec
-	// defining this provider.
+	// Pos is the source position of the func keyword, variable, or type
+	// spec defining this provider.
 	Pos token.Pos
 
 	// Args is the list of data dependencies this provider has.
@@ -175,9 +372,22 @@ type Provider struct {
 	// function.  (Always false for structs.)
 	HasCleanup bool
 
+	// CleanupErr reports whether the provider's cleanup function has the
+	// signature func() error instead of func().
+	CleanupErr bool
+
 	// HasErr reports whether the provider function can return an error.
 	// (Always false for structs.)
 	HasErr bool
+
+	// Method is the method name if the provider is a method expression such
+	// as (*Registry).NewClient, in which case Args[0] is the receiver and
+	// Name is the expression without a package qualifier.
+	Method string
+
+	// Shared is true if the provider was passed to wire.Shared, so that it
+	// is called once and its result reused by every injector in a package.
+	Shared bool
 }
 
 // ProviderInput describes an incoming edge in the provider graph.
@@ -238,6 +448,32 @@ type Field struct {
 	Out []types.Type
 }
 
+// LoadOptions holds the options for LoadWithOptions.
+type LoadOptions struct {
+	// AllowErrors makes loading best effort. Packages with syntax or type
+	// errors are still analyzed: their errors are returned along with the
+	// Wire diagnostics for the provider sets and injectors whose
+	// declarations are free of errors. Declarations that contain errors,
+	// and Wire errors caused by types that failed to type-check, are not
+	// reported, since the package errors already explain them. This suits
+	// editors, which check code while it is being edited.
+	AllowErrors bool
+	// Lightweight drops the syntax trees and type information of the
+	// loaded packages once their provider sets and injectors have been
+	// extracted. Only the expressions passed to wire.Value and the
+	// literals passed to wire.After are kept, with the type information
+	// they need. This bounds the memory the returned Info holds on to when
+	// loading many packages, such as ./... in a large repository. The
+	// memory released is reported as the "load.lightweight.freed_bytes"
+	// metric if the context carries WithMetrics.
+	Lightweight bool
+	// Overlay maps absolute file paths to contents that are loaded in
+	// place of the files on disk, or as additional files if they do not
+	// exist, as in golang.org/x/tools/go/packages.Config. Editors use it to
+	// check unsaved buffers.
+	Overlay map[string][]byte
+}
+
 // Load finds all the provider sets in the packages that match the given
 // patterns, as well as the provider sets' transitive dependencies. It
 // may return both errors and Info. The patterns are defined by the
@@ -250,33 +486,80 @@ type Field struct {
 // In case of duplicate environment variables, the last one in the list
 // takes precedence.
 func Load(ctx context.Context, wd string, env []string, tags string, patterns []string) (*Info, []error) {
+	return LoadWithOptions(ctx, wd, env, tags, patterns, nil)
+}
+
+// LoadWithOptions is like Load, but accepts options. opts may be nil.
+func LoadWithOptions(ctx context.Context, wd string, env []string, tags string, patterns []string, opts *LoadOptions) (*Info, []error) {
+	if opts == nil {
+		opts = &LoadOptions{}
+	}
+	if opts.AllowErrors {
+		ctx = withAllowErrors(ctx)
+	}
+	ctx = withOverlay(ctx, opts.Overlay)
 	loadStart := time.Now()
 	pkgs, loader, errs := load(ctx, wd, env, tags, patterns)
 	logTiming(ctx, "load.packages", loadStart)
-	if len(errs) > 0 {
-		return nil, errs
-	}
 	if len(pkgs) == 0 {
+		if len(errs) > 0 {
+			return nil, errs
+		}
 		return new(Info), nil
 	}
-	fset := pkgs[0].Fset
+	// When errors are allowed, the errors of the initial load are reported
+	// again when each package is type-checked below.
+	// The initial load does not request types, so pkgs[0].Fset may be nil;
+	// the object cache falls back to the loader's file set.
+	fset := newObjectCache(pkgs, loader).fset
 	info := &Info{
 		Fset: fset,
 		Sets: make(map[ProviderSetID]*ProviderSet),
 	}
-	oc := newObjectCache(pkgs, loader)
 	ec := new(errorCollector)
+	initial := make(map[string]bool, len(pkgs))
+	for _, pkg := range pkgs {
+		initial[pkg.PkgPath] = true
+	}
+	linter := newParamLinter(fset, initial)
+	docs := newProviderDocs(fset)
 	for _, pkg := range pkgs {
 		if isWireImport(pkg.PkgPath) {
 			// The marker function package confuses analysis.
 			continue
 		}
-		if loaded, errs := oc.ensurePackage(pkg.PkgPath); len(errs) > 0 {
+		// Each package is type-checked by its own lazy load, so a package
+		// that is also imported by another pattern match exists once per
+		// load. Like Generate, use an object cache per package so that
+		// objects from different loads are never mixed.
+		pkgLoader, pkgTags := loader, tags
+		if tagged, tagLoader, taggedTags, errs := reloadWithTags(ctx, wd, env, tags, pkg, fset); len(errs) > 0 {
 			ec.add(errs...)
 			continue
+		} else if tagged != nil {
+			pkg, pkgLoader, pkgTags = tagged, tagLoader, taggedTags
+		}
+		info.Warnings = append(info.Warnings, excludedInjectorWarnings(pkg, env, pkgTags)...)
+		info.Warnings = append(info.Warnings, generatedLeakWarnings(pkg)...)
+		if conflicts := injectorConflicts(pkg); len(conflicts) > 0 {
+			info.Warnings = append(info.Warnings, conflictWarnings(pkg.PkgPath, conflicts, conflictConstraint(pkg))...)
+		}
+		oc := newObjectCache([]*packages.Package{pkg}, pkgLoader)
+		loaded, errs := oc.ensurePackage(pkg.PkgPath)
+		ec.add(errs...)
+		if len(errs) > 0 && loaded == nil {
+			continue
 		} else if loaded != nil {
 			pkg = loaded
 		}
+		// With AllowErrors, skip the declarations that contain the
+		// package's errors, and drop the Wire errors they cause.
+		var bad *errorLines
+		pkgEC := ec
+		if len(errs) > 0 {
+			bad = newErrorLines(pkg)
+			pkgEC = &errorCollector{}
+		}
 		pkgStart := time.Now()
 		scope := pkg.Types.Scope()
 		setStart := time.Now()
@@ -285,9 +568,12 @@ func Load(ctx context.Context, wd string, env []string, tags string, patterns []
 			if !isProviderSetType(obj.Type()) {
 				continue
 			}
+			if spec := oc.varDecl(obj.(*types.Var)); bad != nil && (spec == nil || bad.in(fset, spec)) {
+				continue
+			}
 			item, errs := oc.get(obj)
 			if len(errs) > 0 {
-				ec.add(notePositionAll(fset.Position(obj.Pos()), errs)...)
+				pkgEC.add(notePositionAll(fset.Position(obj.Pos()), errs)...)
 				continue
 			}
 			pset := item.(*ProviderSet)
@@ -296,29 +582,46 @@ func Load(ctx context.Context, wd string, env []string, tags string, patterns []
 			id := ProviderSetID{ImportPath: pset.PkgPath, VarName: name}
 			info.Sets[id] = pset
 		}
+		if _, errs := oc.packageRedirects(pkg.PkgPath); len(errs) > 0 {
+			pkgEC.add(errs...)
+		}
+		if bad == nil {
+			info.Warnings = append(info.Warnings, shadowedSetWarnings(oc, pkg)...)
+		}
 		logTiming(ctx, "load.package."+pkg.PkgPath+".provider_sets", setStart)
 		injectorStart := time.Now()
 		for _, f := range pkg.Syntax {
 			for _, decl := range f.Decls {
 				fn, ok := decl.(*ast.FuncDecl)
-				if !ok {
+				if !ok || bad.in(fset, fn) {
 					continue
 				}
 				buildCall, err := findInjectorBuild(pkg.TypesInfo, fn)
 				if err != nil {
-					ec.add(notePosition(fset.Position(fn.Pos()), fmt.Errorf("inject %s: %v", fn.Name.Name, err)))
+					pkgEC.add(notePosition(fset.Position(fn.Pos()), fmt.Errorf("inject %s: %w", fn.Name.Name, err)))
 					continue
 				}
 				if buildCall == nil {
 					continue
 				}
 				sig := pkg.TypesInfo.ObjectOf(fn.Name).Type().(*types.Signature)
+				if isPopulateCall(pkg.TypesInfo, buildCall) {
+					injector, errs := oc.loadPopulate(pkg, fn, sig, buildCall)
+					if len(errs) > 0 {
+						pkgEC.add(errs...)
+						continue
+					}
+					info.Injectors = append(info.Injectors, injector)
+					info.Warnings = append(info.Warnings, deprecationWarnings(docs, injector)...)
+					linter.addInjector(oc, injector)
+					continue
+				}
 				ins, out, err := injectorFuncSignature(sig)
 				if err != nil {
 					if w, ok := err.(*wireErr); ok {
-						ec.add(notePosition(w.position, fmt.Errorf("inject %s: %v", fn.Name.Name, w.error)))
+						pkgEC.add(notePosition(w.position, fmt.Errorf("inject %s: %w", fn.Name.Name, w.error)))
 					} else {
-						ec.add(notePosition(fset.Position(fn.Pos()), fmt.Errorf("inject %s: %v", fn.Name.Name, err)))
+						pkgEC.add(notePosition(fset.Position(fn.Pos()), fmt.Errorf("inject %s: %w", fn.Name.Name, err)))
 					}
 					continue
 				}
@@ -328,29 +631,70 @@ func Load(ctx context.Context, wd string, env []string, tags string, patterns []
 					Pos:   fn.Pos(),
 				}
 				set, errs := oc.processNewSet(pkg.TypesInfo, pkg.PkgPath, buildCall, injectorArgs, "")
+				if len(errs) == 0 && out.out == nil && !set.buildsForEffect() {
+					pkgEC.add(noResultsError(fset, pkg, fn, set))
+					continue
+				}
+				if len(errs) == 0 {
+					errs = oc.addFakes(set, out.out)
+				}
 				if len(errs) > 0 {
-					ec.add(notePositionAll(fset.Position(fn.Pos()), errs)...)
+					pkgEC.add(notePositionAll(fset.Position(fn.Pos()), errs)...)
 					continue
 				}
-				_, errs = solve(fset, out.out, ins, set)
+				calls, errs := solve(fset, out.out, ins, set)
 				if len(errs) > 0 {
-					ec.add(mapErrors(errs, func(e error) error {
+					pkgEC.add(mapErrors(errs, func(e error) error {
 						if w, ok := e.(*wireErr); ok {
-							return notePosition(w.position, fmt.Errorf("inject %s: %v", fn.Name.Name, w.error))
+							return notePosition(w.position, fmt.Errorf("inject %s: %w", fn.Name.Name, w.error))
 						}
-						return notePosition(fset.Position(fn.Pos()), fmt.Errorf("inject %s: %v", fn.Name.Name, e))
+						return notePosition(fset.Position(fn.Pos()), fmt.Errorf("inject %s: %w", fn.Name.Name, e))
 					})...)
 					continue
 				}
-				info.Injectors = append(info.Injectors, &Injector{
+				injector := &Injector{
 					ImportPath: pkg.PkgPath,
 					FuncName:   fn.Name.Name,
-				})
+					Pos:        fn.Pos(),
+					Params:     ins,
+					Out:        out.out,
+					Steps:      injectorSteps(calls),
+					afterSteps: afterSteps(ins.Len(), calls, set, out.out),
+				}
+				info.Injectors = append(info.Injectors, injector)
+				info.Warnings = append(info.Warnings, deprecationWarnings(docs, injector)...)
+				linter.addInjector(oc, injector)
 			}
 		}
 		logTiming(ctx, "load.package."+pkg.PkgPath+".injectors", injectorStart)
+		for _, w := range oc.warnings {
+			// Uses in other packages are reported when those are loaded.
+			if w.Pkg == pkg.PkgPath {
+				info.Warnings = append(info.Warnings, w)
+			}
+		}
+		if bad != nil {
+			for _, err := range pkgEC.errors {
+				if !causedByErrors(err) {
+					ec.add(err)
+				}
+			}
+		}
 		logTiming(ctx, "load.package."+pkg.PkgPath+".total", pkgStart)
 	}
+	if opts.Lightweight {
+		trimStart := time.Now()
+		trimInfo(ctx, info)
+		logTiming(ctx, "load.lightweight", trimStart)
+	}
+	info.UnusedParams = linter.results()
+	for _, u := range info.UnusedParams {
+		err := notePosition(u.Pos, errors.New(u.message()))
+		info.Warnings = append(info.Warnings, newWarning(u.Provider.Pkg.Path(), CodeUnusedParam, err))
+	}
+	sort.SliceStable(info.Warnings, func(i, j int) bool {
+		return positionLess(info.Warnings[i].Pos, info.Warnings[j].Pos)
+	})
 	return info, ec.errors
 }
 
@@ -364,15 +708,26 @@ func Load(ctx context.Context, wd string, env []string, tags string, patterns []
 // env is nil or empty, it is interpreted as an empty set of variables.
 // In case of duplicate environment variables, the last one in the list
 // takes precedence.
+//
+// With WithWarmLoads, the packages kept from an earlier load of the same
+// patterns are reused if they are current.
 func load(ctx context.Context, wd string, env []string, tags string, patterns []string) ([]*packages.Package, *lazyLoader, []error) {
-	fset := token.NewFileSet()
+	if w := warmLoads(ctx); w != nil {
+		return w.load(ctx, wd, env, tags, patterns)
+	}
+	return loadInto(ctx, token.NewFileSet(), wd, env, tags, patterns)
+}
+
+// loadInto is like load, but records positions in fset.
+func loadInto(ctx context.Context, fset *token.FileSet, wd string, env []string, tags string, patterns []string) ([]*packages.Package, *lazyLoader, []error) {
 	baseCfg := &packages.Config{
 		Context:    ctx,
-		Mode:       packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports | packages.NeedDeps,
+		Mode:       packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports | packages.NeedDeps | packages.NeedEmbedFiles,
 		Dir:        wd,
 		Env:        env,
 		BuildFlags: []string{"-tags=wireinject"},
 		Fset:       fset,
+		Overlay:    loadOverlay(ctx),
 	}
 	if len(tags) > 0 {
 		baseCfg.BuildFlags[0] += " " + tags
@@ -381,16 +736,18 @@ func load(ctx context.Context, wd string, env []string, tags string, patterns []
 	for i := range patterns {
 		escaped[i] = "pattern=" + patterns[i]
 	}
+	debugf(ctx, "loading %s in %s with %s", strings.Join(patterns, " "), wd, baseCfg.BuildFlags[0])
 	baseLoadStart := time.Now()
 	pkgs, err := packages.Load(baseCfg, escaped...)
 	logTiming(ctx, "load.packages.base.load", baseLoadStart)
 	if err != nil {
-		return nil, nil, []error{err}
+		return nil, nil, []error{classifyLoadError(err)}
 	}
 	baseErrsStart := time.Now()
-	errs := collectLoadErrors(pkgs)
+	errs := dropVariantErrors(ctx, pkgs, collectLoadErrors(pkgs))
 	logTiming(ctx, "load.packages.base.collect_errors", baseErrsStart)
-	if len(errs) > 0 {
+	debugf(ctx, "loaded %d packages with %d errors", len(pkgs), len(errs))
+	if len(errs) > 0 && !allowErrors(ctx) {
 		return nil, nil, errs
 	}
 
@@ -403,14 +760,29 @@ func load(ctx context.Context, wd string, env []string, tags string, patterns []
 		fset:      fset,
 		baseFiles: baseFiles,
 	}
-	return pkgs, loader, nil
+	return pkgs, loader, errs
+}
+
+// MatchPackages expands the given patterns and returns the import paths of
+// the resulting packages that match re. It is FilterPackages with the
+// default filter and re as its Match.
+func MatchPackages(ctx context.Context, wd string, env []string, tags string, patterns []string, re *regexp.Regexp) ([]string, error) {
+	return FilterPackages(ctx, wd, env, tags, patterns, &PackageFilter{Match: re})
 }
 
 func collectLoadErrors(pkgs []*packages.Package) []error {
 	var errs []error
 	for _, p := range pkgs {
+		failed := dependencyDownloadErrors(p)
 		for _, e := range p.Errors {
-			errs = append(errs, e)
+			if importsFailedDownload(e.Msg, failed) {
+				// Reported below, with the reason and a hint.
+				continue
+			}
+			errs = append(errs, &pkgError{pkg: p.PkgPath, error: classifyPackageError(e)})
+		}
+		for _, dep := range failed {
+			errs = append(errs, &pkgError{pkg: p.PkgPath, error: classifyPackageError(dep.err)})
 		}
 	}
 	return errs
@@ -426,6 +798,16 @@ type Info struct {
 	// Injectors contains all the injector functions in the initial packages.
 	// The order is undefined.
 	Injectors []*Injector
+
+	// UnusedParams lists the parameters of providers declared in the
+	// initial packages that the injectors call with no effect, sorted by
+	// position.
+	UnusedParams []UnusedParam
+
+	// Warnings lists the problems found in the injectors of the initial
+	// packages that do not prevent generating code, sorted by position:
+	// uses of deprecated providers, and a warning for each of UnusedParams.
+	Warnings ErrorList
 }
 
 // A ProviderSetID identifies a named provider set.
@@ -443,6 +825,22 @@ func (id ProviderSetID) String() string {
 type Injector struct {
 	ImportPath string
 	FuncName   string
+	// Pos is the position of the injector function template.
+	Pos token.Pos
+
+	// Params is the injector function's parameter list.
+	Params *types.Tuple
+	// Out is the type the injector function produces, or nil if it only
+	// builds the types its sets require with wire.Require, or the
+	// parameters of their wire.After hooks, for their side effects.
+	Out types.Type
+	// Steps is the sequence of values the injector constructs, in the order
+	// the generated code creates them.
+	Steps []InjectorStep
+
+	// afterSteps is the number of trailing Steps built only for the
+	// wire.After hooks, after the output.
+	afterSteps int
 }
 
 // String returns the injector name as ""path/to/pkg".Foo".
@@ -450,6 +848,91 @@ func (in *Injector) String() string {
 	return strconv.Quote(in.ImportPath) + "." + in.FuncName
 }
 
+// OutIndex returns the index of the value returned by the injector, using the
+// same numbering as InjectorStep.Args, or -1 if it returns no value.
+func (in *Injector) OutIndex() int {
+	if in.Out == nil {
+		return -1
+	}
+	if n := len(in.Steps) - in.afterSteps; n > 0 {
+		return in.Params.Len() + n - 1
+	}
+	for i := 0; i < in.Params.Len(); i++ {
+		if types.Identical(in.Params.At(i).Type(), in.Out) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ConstructionOrder returns the providers the injector calls, in the order
+// the generated code calls them, so that a provider comes after all the
+// providers it depends on. Frameworks that start or stop components built by
+// the injector can use it to mirror Wire's ordering, reversing it for
+// shutdown. Values and fields are not included, and a provider called for
+// more than one step is listed once.
+func (in *Injector) ConstructionOrder() []*Provider {
+	var order []*Provider
+	seen := make(map[*Provider]bool)
+	for _, step := range in.Steps {
+		if p := step.Provider; p != nil && !seen[p] {
+			seen[p] = true
+			order = append(order, p)
+		}
+	}
+	return order
+}
+
+// An InjectorStep describes a single value constructed by an injector.
+// Exactly one of Provider, Value, or Field will be set.
+type InjectorStep struct {
+	// Out is the type this step produces.
+	Out types.Type
+
+	Provider *Provider
+	Value    *Value
+	Field    *Field
+
+	// Args lists the values this step consumes. Each element is either an
+	// index into the injector's Params (Args[i] < Params.Len()) or refers to
+	// the result of Steps[Args[i]-Params.Len()].
+	Args []int
+}
+
+// afterSteps returns the number of calls that solve added after the one
+// building out, for the parameters of the wire.After hooks in set.
+func afterSteps(numParams int, calls []call, set *ProviderSet, out types.Type) int {
+	if len(set.Afters) == 0 || out == nil {
+		return 0
+	}
+	i := argIndex(numParams, calls, set, out)
+	if i < numParams {
+		return len(calls)
+	}
+	return len(calls) - (i - numParams) - 1
+}
+
+// injectorSteps converts the output of solve into InjectorSteps.
+func injectorSteps(calls []call) []InjectorStep {
+	steps := make([]InjectorStep, len(calls))
+	for i := range calls {
+		c := &calls[i]
+		steps[i] = InjectorStep{
+			Out:  c.out,
+			Args: append([]int(nil), c.args...),
+		}
+		switch {
+		case c.src.IsProvider():
+			steps[i].Provider = c.src.Provider()
+		case c.src.IsValue():
+			steps[i].Value = c.src.Value()
+		case c.src.IsField():
+			steps[i].Field = c.src.Field()
+		}
+	}
+	return steps
+}
+
 // objectCache is a lazily evaluated mapping of objects to Wire structures.
 type objectCache struct {
 	fset     *token.FileSet
@@ -457,6 +940,18 @@ type objectCache struct {
 	objects  map[objRef]objCacheEntry
 	hasher   typeutil.Hasher
 	loader   *lazyLoader
+	// autoContext mirrors GenerateOptions.AutoContext.
+	autoContext bool
+	// stableOrder mirrors GenerateOptions.StableOrder.
+	stableOrder bool
+	// autoNames is the compiled GenerateOptions.AutoNames, or nil for
+	// defaultAutoNames.
+	autoNames *regexp.Regexp
+
+	// redirects memoizes packageRedirects by import path.
+	redirects map[string]packageRedirects
+	// warnings collects the uses of redirected provider sets.
+	warnings ErrorList
 }
 
 type objRef struct {
@@ -526,11 +1021,11 @@ func (oc *objectCache) ensurePackage(pkgPath string) (*packages.Package, []error
 		return nil, []error{fmt.Errorf("package %q is missing type information", pkgPath)}
 	}
 	loaded, errs := oc.loader.load(pkgPath)
-	if len(errs) > 0 {
+	if len(loaded) == 0 {
 		return nil, errs
 	}
 	oc.registerPackages(loaded, true)
-	return oc.packages[pkgPath], nil
+	return oc.packages[pkgPath], errs
 }
 
 // get converts a Go object into a Wire structure. It may return a *Provider, an
@@ -543,7 +1038,9 @@ func (oc *objectCache) get(obj types.Object) (val interface{}, errs []error) {
 	if ent, cached := oc.objects[ref]; cached {
 		return ent.val, append([]error(nil), ent.errs...)
 	}
-	if _, errs := oc.ensurePackage(ref.importPath); len(errs) > 0 {
+	if pkg, errs := oc.ensurePackage(ref.importPath); pkg == nil {
+		// With AllowErrors, a package may load with errors, which are
+		// reported when the package itself is loaded.
 		return nil, errs
 	}
 	defer func() {
@@ -554,6 +1051,11 @@ func (oc *objectCache) get(obj types.Object) (val interface{}, errs []error) {
 	}()
 	switch obj := obj.(type) {
 	case *types.Var:
+		if sig, ok := obj.Type().Underlying().(*types.Signature); ok && obj.Parent() == obj.Pkg().Scope() {
+			// A package-level variable holding a function, such as a
+			// generated or conditionally assigned constructor.
+			return processFuncProvider(oc.fset, obj, sig)
+		}
 		spec := oc.varDecl(obj)
 		if spec == nil || len(spec.Values) == 0 {
 			return nil, []error{fmt.Errorf("%v is not a provider or a provider set", obj)}
@@ -567,7 +1069,7 @@ func (oc *objectCache) get(obj types.Object) (val interface{}, errs []error) {
 		pkgPath := obj.Pkg().Path()
 		return oc.processExpr(oc.packages[pkgPath].TypesInfo, pkgPath, spec.Values[i], obj.Name())
 	case *types.Func:
-		return processFuncProvider(oc.fset, obj)
+		return processFuncProvider(oc.fset, obj, obj.Type().(*types.Signature))
 	default:
 		return nil, []error{fmt.Errorf("%v is not a provider or a provider set", obj)}
 	}
@@ -594,65 +1096,49 @@ func (oc *objectCache) varDecl(obj *types.Var) *ast.ValueSpec {
 }
 
 // processExpr converts an expression into a Wire structure. It may return a
-// *Provider, an *IfaceBinding, a *ProviderSet, a *Value or a []*Field.
+// *Provider, an *IfaceBinding, a *ProviderSet, a *Value, a []*Field or a
+// *fakeSet.
 func (oc *objectCache) processExpr(info *types.Info, pkgPath string, expr ast.Expr, varName string) (interface{}, []error) {
 	exprPos := oc.fset.Position(expr.Pos())
 	expr = astutil.Unparen(expr)
 	if obj := qualifiedIdentObject(info, expr); obj != nil {
+		if err := oc.nonProviderObject(pkgPath, expr, obj); err != nil {
+			return nil, []error{notePosition(exprPos, err)}
+		}
+		if r := oc.redirectOf(obj); r != nil {
+			oc.warnings = append(oc.warnings, redirectWarning(pkgPath, exprPos, r))
+			obj = r.to
+		}
 		item, errs := oc.get(obj)
 		return item, mapErrors(errs, func(err error) error {
 			return notePosition(exprPos, err)
 		})
 	}
 	if call, ok := expr.(*ast.CallExpr); ok {
+		if tv, ok := info.Types[call.Fun]; ok && tv.IsType() {
+			return oc.processConversion(info, pkgPath, call, tv.Type, varName)
+		}
 		fnObj := qualifiedIdentObject(info, call.Fun)
-		if fnObj == nil {
-			return nil, []error{notePosition(exprPos, errors.New("unknown pattern fnObj nil"))}
+		if fnObj == nil || fnObj.Pkg() == nil {
+			return nil, []error{notePosition(exprPos, nonProvider("cannot use the result of calling %s as a provider; %s", types.ExprString(call.Fun), providerArgForms))}
 		}
 		pkg := fnObj.Pkg()
-		if pkg == nil {
-			return nil, []error{notePosition(exprPos, fmt.Errorf("unknown pattern - pkg in fnObj is nil - %s", fnObj))}
-		}
 		if !isWireImport(pkg.Path()) {
-			return nil, []error{notePosition(exprPos, errors.New("unknown pattern"))}
-		}
-		switch fnObj.Name() {
-		case "NewSet":
-			pset, errs := oc.processNewSet(info, pkgPath, call, nil, varName)
-			return pset, notePositionAll(exprPos, errs)
-		case "Bind":
-			b, err := processBind(oc.fset, info, call)
-			if err != nil {
-				return nil, []error{notePosition(exprPos, err)}
-			}
-			return b, nil
-		case "Value":
-			v, err := processValue(oc.fset, info, call)
-			if err != nil {
-				return nil, []error{notePosition(exprPos, err)}
-			}
-			return v, nil
-		case "InterfaceValue":
-			v, err := processInterfaceValue(oc.fset, info, call)
-			if err != nil {
-				return nil, []error{notePosition(exprPos, err)}
-			}
-			return v, nil
-		case "Struct":
-			s, err := processStructProvider(oc.fset, info, call)
-			if err != nil {
-				return nil, []error{notePosition(exprPos, err)}
+			msg := fmt.Sprintf("cannot use the result of calling %s.%s as a provider", pkg.Name(), fnObj.Name())
+			if sig, ok := fnObj.Type().(*types.Signature); ok && sig.Results().Len() == 1 {
+				if _, ok := sig.Results().At(0).Type().Underlying().(*types.Signature); ok {
+					msg += "; to use the function it returns, assign it to a package-level variable and pass the variable"
+				}
 			}
-			return s, nil
-		case "FieldsOf":
-			v, err := processFieldsOf(oc.fset, info, call)
-			if err != nil {
-				return nil, []error{notePosition(exprPos, err)}
+			return nil, []error{notePosition(exprPos, nonProvider("%s; %s", msg, providerArgForms))}
+		}
+		for _, c := range wireCalls {
+			if c.name == fnObj.Name() {
+				item, errs := c.process(oc, info, pkgPath, call, varName)
+				return item, notePositionAll(exprPos, errs)
 			}
-			return v, nil
-		default:
-			return nil, []error{notePosition(exprPos, errors.New("unknown pattern"))}
 		}
+		return nil, []error{notePosition(exprPos, nonProvider("wire.%s cannot be used in a provider set; %s", fnObj.Name(), providerArgForms))}
 	}
 	if tn := structArgType(info, expr); tn != nil {
 		p, errs := processStructLiteralProvider(oc.fset, tn)
@@ -661,36 +1147,291 @@ func (oc *objectCache) processExpr(info *types.Info, pkgPath string, expr ast.Ex
 		}
 		return p, nil
 	}
-	return nil, []error{notePosition(exprPos, errors.New("unknown pattern"))}
+	switch expr := expr.(type) {
+	case *ast.FuncLit:
+		return nil, []error{notePosition(exprPos, nonProvider("function literals cannot be used as providers; declare a named function, or assign the literal to a package-level variable and pass the variable"))}
+	case *ast.SelectorExpr:
+		if sel := info.Selections[expr]; sel != nil {
+			switch sel.Kind() {
+			case types.MethodExpr:
+				item, errs := oc.processMethodExpr(sel)
+				return item, notePositionAll(exprPos, errs)
+			case types.MethodVal:
+				fn := sel.Obj().(*types.Func)
+				return nil, []error{notePosition(exprPos, nonProvider("method value %s cannot be used as a provider; use the method expression %s to have the receiver provided, or declare a function that calls it", types.ExprString(expr), methodExprString(sel.Recv(), fn, pkgPath)))}
+			case types.FieldVal:
+				return nil, []error{notePosition(exprPos, nonProvider("field %s cannot be used as a provider; use wire.FieldsOf to provide struct fields", types.ExprString(expr)))}
+			}
+		}
+		if id, ok := expr.X.(*ast.Ident); ok && !expr.Sel.IsExported() {
+			if pn, ok := info.ObjectOf(id).(*types.PkgName); ok {
+				return nil, []error{notePosition(exprPos, nonProvider("%s is not exported by package %s, so it cannot be used outside of it; export the provider or set, or pass an exported set that includes it", types.ExprString(expr), pn.Imported().Path()))}
+			}
+		}
+	}
+	if tv, ok := info.Types[expr]; ok && tv.IsValue() {
+		if _, isFunc := tv.Type.Underlying().(*types.Signature); !isFunc {
+			return nil, []error{notePosition(exprPos, nonProvider("%s is a value of type %s, not a provider or provider set; to provide it, pass %s",
+				types.ExprString(expr), types.TypeString(tv.Type, nil), valueHint(types.ExprString(expr), tv.Type, pkgPath)))}
+		}
+	}
+	return nil, []error{notePosition(exprPos, nonProvider("unsupported expression %s; %s", types.ExprString(expr), providerArgForms))}
+}
+
+// nonProviderObject returns an error explaining why obj, referred to by
+// expr in the package at pkgPath, cannot be passed to wire.NewSet or
+// wire.Build, or nil if it may be a provider or a provider set.
+func (oc *objectCache) nonProviderObject(pkgPath string, expr ast.Expr, obj types.Object) error {
+	name := types.ExprString(expr)
+	switch obj := obj.(type) {
+	case *types.Const:
+		return nonProvider("%s is a constant, not a provider or provider set; to provide its value, pass %s",
+			name, valueHint(name, obj.Type(), pkgPath))
+	case *types.TypeName:
+		hint := "pass a function that returns it"
+		if _, ok := obj.Type().Underlying().(*types.Struct); ok {
+			hint += fmt.Sprintf(", or wire.Struct(new(%s), \"*\") to fill in its fields", name)
+		}
+		return nonProvider("%s is a type, not a provider or provider set; %s", name, hint)
+	case *types.Var:
+		if obj.Pkg() == nil {
+			return nil
+		}
+		if obj.Parent() != obj.Pkg().Scope() {
+			return nonProvider("%s is a parameter or local variable, not a provider or provider set; injector parameters are provided without being passed to wire.Build, and only package-level variables can hold providers", name)
+		}
+		if _, ok := obj.Type().Underlying().(*types.Signature); ok {
+			return nil
+		}
+		if pkg, _ := oc.ensurePackage(obj.Pkg().Path()); pkg == nil {
+			return nil
+		}
+		if spec := oc.varDecl(obj); spec == nil || len(spec.Values) > 0 {
+			return nil
+		}
+		return nonProvider("%s is a variable of type %s with no initializer, not a provider or provider set; to provide its value, pass %s",
+			name, types.TypeString(obj.Type(), nil), valueHint(name, obj.Type(), pkgPath))
+	}
+	return nil
+}
+
+// valueHint returns the call to pass instead of the expression expr of type
+// t to provide its value in the package at pkgPath.
+func valueHint(expr string, t types.Type, pkgPath string) string {
+	if _, ok := t.Underlying().(*types.Interface); ok {
+		qualifier := func(pkg *types.Package) string {
+			if pkg.Path() == pkgPath {
+				return ""
+			}
+			return pkg.Name()
+		}
+		return fmt.Sprintf("wire.InterfaceValue(new(%s), %s)", types.TypeString(t, qualifier), expr)
+	}
+	return fmt.Sprintf("wire.Value(%s)", expr)
+}
+
+// A nonProviderErr explains why an argument to wire.NewSet or wire.Build is
+// none of the expressions they accept. processNewSet records which argument
+// of which call it is about.
+type nonProviderErr struct {
+	msg string
+	// call is the called function, such as wire.NewSet, and arg is the
+	// 1-based index of the argument. call is empty until they are recorded.
+	call string
+	arg  int
+}
+
+// nonProvider returns a *nonProviderErr with the formatted message.
+func nonProvider(format string, args ...interface{}) error {
+	return &nonProviderErr{msg: fmt.Sprintf(format, args...)}
+}
+
+// Error returns the message, prefixed by the argument it is about if known.
+func (e *nonProviderErr) Error() string {
+	if e.call == "" {
+		return e.msg
+	}
+	return fmt.Sprintf("argument %d to %s: %s", e.arg, e.call, e.msg)
+}
+
+// noteArgIndex records in the non-provider errors of errs that they are
+// about the index-th argument to call. Errors already about an argument of
+// a call nested in the argument keep it. The errors are copied, since errs
+// may be cached for other references to the same object.
+func noteArgIndex(errs []error, call string, index int) []error {
+	return mapErrors(errs, func(err error) error {
+		w, ok := err.(*wireErr)
+		if !ok {
+			return err
+		}
+		np, ok := w.error.(*nonProviderErr)
+		if !ok || np.call != "" {
+			return err
+		}
+		noted := *np
+		noted.call, noted.arg = call, index
+		out := *w
+		out.error = &noted
+		return &out
+	})
+}
+
+// A wireCall is a function of the wire package that may be called in the
+// arguments to wire.NewSet and wire.Build, with the method that processes
+// a call to it.
+type wireCall struct {
+	name    string
+	process func(oc *objectCache, info *types.Info, pkgPath string, call *ast.CallExpr, varName string) (interface{}, []error)
+}
+
+// wireCalls lists the wire functions processExpr accepts calls to, in the
+// order error messages name them, and providerArgForms describes the
+// expressions accepted by wire.NewSet and wire.Build for use in error
+// messages. Both are set by init, since processing wire.NewSet refers back
+// to wireCalls.
+var (
+	wireCalls        []wireCall
+	providerArgForms string
+)
+
+func init() {
+	wireCalls = []wireCall{
+		{"NewSet", func(oc *objectCache, info *types.Info, pkgPath string, call *ast.CallExpr, varName string) (interface{}, []error) {
+			return oc.processNewSet(info, pkgPath, call, nil, varName)
+		}},
+		{"Bind", func(oc *objectCache, info *types.Info, _ string, call *ast.CallExpr, _ string) (interface{}, []error) {
+			return singleError(processBind(oc.fset, info, call))
+		}},
+		{"Value", func(oc *objectCache, info *types.Info, _ string, call *ast.CallExpr, _ string) (interface{}, []error) {
+			return singleError(processValue(oc.fset, info, call))
+		}},
+		{"InterfaceValue", func(oc *objectCache, info *types.Info, _ string, call *ast.CallExpr, _ string) (interface{}, []error) {
+			return singleError(processInterfaceValue(oc.fset, info, call))
+		}},
+		{"Struct", func(oc *objectCache, info *types.Info, _ string, call *ast.CallExpr, _ string) (interface{}, []error) {
+			return singleError(processStructProvider(oc.fset, info, call))
+		}},
+		{"FieldsOf", func(oc *objectCache, info *types.Info, _ string, call *ast.CallExpr, _ string) (interface{}, []error) {
+			return singleError(processFieldsOf(oc.fset, info, call))
+		}},
+		{"Fakes", func(oc *objectCache, info *types.Info, pkgPath string, call *ast.CallExpr, _ string) (interface{}, []error) {
+			return oc.processFakes(info, pkgPath, call)
+		}},
+		{"Shared", func(oc *objectCache, info *types.Info, pkgPath string, call *ast.CallExpr, _ string) (interface{}, []error) {
+			return oc.processShared(info, pkgPath, call)
+		}},
+		{"Exclude", func(oc *objectCache, info *types.Info, pkgPath string, call *ast.CallExpr, varName string) (interface{}, []error) {
+			return oc.processExclude(info, pkgPath, call, varName)
+		}},
+		{"AutoSet", func(oc *objectCache, info *types.Info, pkgPath string, call *ast.CallExpr, varName string) (interface{}, []error) {
+			return oc.processAutoSet(info, pkgPath, call, varName)
+		}},
+		{"Require", func(oc *objectCache, info *types.Info, _ string, call *ast.CallExpr, _ string) (interface{}, []error) {
+			return singleError(processRequire(oc.fset, info, call))
+		}},
+		{"Copy", func(oc *objectCache, info *types.Info, _ string, call *ast.CallExpr, _ string) (interface{}, []error) {
+			return singleError(processCopy(oc.fset, info, call))
+		}},
+		{"After", func(oc *objectCache, info *types.Info, _ string, call *ast.CallExpr, _ string) (interface{}, []error) {
+			return singleError(processAfter(oc.fset, info, call))
+		}},
+	}
+	calls := make([]string, len(wireCalls))
+	for i, c := range wireCalls {
+		calls[i] = "wire." + c.name
+	}
+	providerArgForms = "arguments to wire.NewSet and wire.Build must be provider functions, method expressions, provider sets, " +
+		"package-level variables holding either, conversions of provider functions to function types, " +
+		"or calls to " + strings.Join(calls[:len(calls)-1], ", ") + ", or " + calls[len(calls)-1]
+}
+
+// singleError adapts the results of a function processing a call that
+// reports at most one error.
+func singleError(item interface{}, err error) (interface{}, []error) {
+	if err != nil {
+		return nil, []error{err}
+	}
+	return item, nil
+}
+
+// processConversion handles a conversion used as a provider. Converting a
+// provider function to another function type, such as a named type the
+// function implements, yields the provider itself: the signatures must be
+// identical for the conversion to compile.
+func (oc *objectCache) processConversion(info *types.Info, pkgPath string, call *ast.CallExpr, to types.Type, varName string) (interface{}, []error) {
+	pos := oc.fset.Position(call.Pos())
+	typeString := types.TypeString(to, nil)
+	if _, ok := to.Underlying().(*types.Signature); !ok || len(call.Args) != 1 {
+		return nil, []error{notePosition(pos, fmt.Errorf("conversion to %s cannot be used as a provider; to provide a value of type %s, use wire.Value", typeString, typeString))}
+	}
+	item, errs := oc.processExpr(info, pkgPath, call.Args[0], varName)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if p, ok := item.(*Provider); ok {
+		return p, nil
+	}
+	return nil, []error{notePosition(pos, fmt.Errorf("conversion to %s must convert a provider function", typeString))}
 }
 
 func (oc *objectCache) processNewSet(info *types.Info, pkgPath string, call *ast.CallExpr, args *InjectorArgs, varName string) (*ProviderSet, []error) {
-	// Assumes that call.Fun is wire.NewSet or wire.Build.
+	// Assumes that call.Fun is wire.NewSet, wire.Build, wire.TestBuild, or
+	// wire.MustBuild.
 
 	pset := &ProviderSet{
 		Pos:          call.Pos(),
 		InjectorArgs: args,
 		PkgPath:      pkgPath,
 		VarName:      varName,
+		stableOrder:  args != nil && oc.stableOrder,
+	}
+	callName := "wire.NewSet"
+	if fn := qualifiedIdentObject(info, call.Fun); fn != nil {
+		callName = "wire." + fn.Name()
+		pset.testBuild = fn.Name() == "TestBuild"
+		pset.mustBuild = fn.Name() == "MustBuild"
 	}
 	ec := new(errorCollector)
-	for _, arg := range call.Args {
+	for i, arg := range call.Args {
 		item, errs := oc.processExpr(info, pkgPath, arg, "")
 		if len(errs) > 0 {
-			ec.add(errs...)
+			ec.add(noteArgIndex(errs, callName, i+1)...)
 			continue
 		}
 		switch item := item.(type) {
 		case *Provider:
 			pset.Providers = append(pset.Providers, item)
+			pset.noteArgPos(item, arg.Pos())
 		case *ProviderSet:
 			pset.Imports = append(pset.Imports, item)
+			pset.noteArgPos(item, arg.Pos())
 		case *IfaceBinding:
 			pset.Bindings = append(pset.Bindings, item)
+			pset.noteArgPos(item, arg.Pos())
 		case *Value:
 			pset.Values = append(pset.Values, item)
+			pset.noteArgPos(item, arg.Pos())
 		case []*Field:
 			pset.Fields = append(pset.Fields, item...)
+			for _, f := range item {
+				pset.noteArgPos(f, arg.Pos())
+			}
+		case *Requirement:
+			pset.Requires = append(pset.Requires, item)
+		case *CopiedType:
+			pset.Copies = append(pset.Copies, item)
+		case *AfterHook:
+			if args == nil {
+				ec.add(notePosition(oc.fset.Position(arg.Pos()), errors.New("wire.After may only be passed to wire.Build")))
+				continue
+			}
+			pset.Afters = append(pset.Afters, item)
+		case *fakeSet:
+			if !pset.testBuild {
+				ec.add(notePosition(oc.fset.Position(arg.Pos()), errors.New("fake providers may only be passed to wire.TestBuild")))
+				continue
+			}
+			pset.fakes = append(pset.fakes, item.providers...)
 		default:
 			panic("unknown item type")
 		}
@@ -698,17 +1439,23 @@ func (oc *objectCache) processNewSet(info *types.Info, pkgPath string, call *ast
 	if len(ec.errors) > 0 {
 		return nil, ec.errors
 	}
-	var errs []error
-	pset.providerMap, pset.srcMap, errs = buildProviderMap(oc.fset, oc.hasher, pset)
-	if len(errs) > 0 {
-		return nil, errs
-	}
-	if errs := verifyAcyclic(pset.providerMap, oc.hasher); len(errs) > 0 {
+	if errs := oc.rebuildProviderMap(pset); len(errs) > 0 {
 		return nil, errs
 	}
 	return pset, nil
 }
 
+// rebuildProviderMap builds the provider map of set from its contents and
+// verifies that it has no cycles.
+func (oc *objectCache) rebuildProviderMap(set *ProviderSet) []error {
+	var errs []error
+	set.providerMap, set.srcMap, errs = buildProviderMap(oc.fset, oc.hasher, set, oc.autoContext)
+	if len(errs) > 0 {
+		return errs
+	}
+	return verifyAcyclic(set.providerMap, oc.hasher)
+}
+
 // structArgType attempts to interpret an expression as a simple struct type.
 // It assumes any parentheses have been stripped.
 func structArgType(info *types.Info, expr ast.Expr) *types.TypeName {
@@ -746,13 +1493,57 @@ func qualifiedIdentObject(info *types.Info, expr ast.Expr) types.Object {
 	}
 }
 
-// processFuncProvider creates a provider for a function declaration.
-func processFuncProvider(fset *token.FileSet, fn *types.Func) (*Provider, []error) {
-	sig := fn.Type().(*types.Signature)
+// processMethodExpr creates a provider for a method expression such as
+// (*Registry).NewClient. The receiver is the provider's first argument, so
+// it is provided by the graph like any other input.
+func (oc *objectCache) processMethodExpr(sel *types.Selection) (interface{}, []error) {
+	fn := sel.Obj().(*types.Func)
+	ref := objRef{
+		importPath: fn.Pkg().Path(),
+		name:       types.TypeString(sel.Recv(), nil) + "." + fn.Name(),
+	}
+	if ent, cached := oc.objects[ref]; cached {
+		return ent.val, append([]error(nil), ent.errs...)
+	}
+	provider, errs := processFuncProvider(oc.fset, fn, sel.Type().(*types.Signature))
+	if provider != nil {
+		provider.Name = methodExprString(sel.Recv(), fn, fn.Pkg().Path())
+		provider.Method = fn.Name()
+	}
+	oc.objects[ref] = objCacheEntry{val: provider, errs: append([]error(nil), errs...)}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return provider, nil
+}
+
+// methodExprString returns the method expression selecting fn from recv,
+// such as (*pkg.Registry).NewClient, with types in the package at pkgPath
+// left unqualified. recv is made a pointer if fn requires one.
+func methodExprString(recv types.Type, fn *types.Func, pkgPath string) string {
+	_, isPtr := recv.(*types.Pointer)
+	if _, ptrRecv := fn.Type().(*types.Signature).Recv().Type().(*types.Pointer); ptrRecv && !isPtr {
+		recv, isPtr = types.NewPointer(recv), true
+	}
+	s := types.TypeString(recv, func(pkg *types.Package) string {
+		if pkg.Path() == pkgPath {
+			return ""
+		}
+		return pkg.Name()
+	})
+	if isPtr {
+		s = "(" + s + ")"
+	}
+	return s + "." + fn.Name()
+}
+
+// processFuncProvider creates a provider for a function declaration or a
+// package-level variable of function type. sig is the function's signature.
+func processFuncProvider(fset *token.FileSet, fn types.Object, sig *types.Signature) (*Provider, []error) {
 	fpos := fn.Pos()
 	providerSig, err := funcOutput(sig)
 	if err != nil {
-		return nil, []error{notePosition(fset.Position(fpos), fmt.Errorf("wrong signature for provider %s: %v", fn.Name(), err))}
+		return nil, []error{notePosition(fset.Position(fpos), withCode(CodeSignature, fmt.Errorf("wrong signature for provider %s: %v", fn.Name(), err)))}
 	}
 	params := sig.Params()
 	provider := &Provider{
@@ -763,6 +1554,7 @@ func processFuncProvider(fset *token.FileSet, fn *types.Func) (*Provider, []erro
 		Varargs:    sig.Variadic(),
 		Out:        []types.Type{providerSig.out},
 		HasCleanup: providerSig.cleanup,
+		CleanupErr: providerSig.cleanupErr,
 		HasErr:     providerSig.err,
 	}
 	for i := 0; i < params.Len(); i++ {
@@ -771,7 +1563,7 @@ func processFuncProvider(fset *token.FileSet, fn *types.Func) (*Provider, []erro
 		}
 		for j := 0; j < i; j++ {
 			if types.Identical(provider.Args[i].Type, provider.Args[j].Type) {
-				return nil, []error{notePosition(fset.Position(fpos), fmt.Errorf("provider has multiple parameters of type %s", types.TypeString(provider.Args[j].Type, nil)))}
+				return nil, []error{notePosition(fset.Position(fpos), withCode(CodeSignature, fmt.Errorf("provider has multiple parameters of type %s", TypeString(provider.Args[j].Type))))}
 			}
 		}
 	}
@@ -779,17 +1571,48 @@ func processFuncProvider(fset *token.FileSet, fn *types.Func) (*Provider, []erro
 }
 
 func injectorFuncSignature(sig *types.Signature) (*types.Tuple, outputSignature, error) {
-	out, err := funcOutput(sig)
+	out, err := injectorOutput(sig)
 	if err != nil {
-		return nil, outputSignature{}, err
+		return nil, outputSignature{}, withCode(CodeSignature, err)
 	}
 	return sig.Params(), out, nil
 }
 
+// injectorOutput validates an injector's return signature. Unlike a
+// provider, an injector may return nothing, or only an error, if it builds
+// values just for their side effects; its out is then nil.
+func injectorOutput(sig *types.Signature) (outputSignature, error) {
+	results := sig.Results()
+	switch {
+	case results.Len() == 0:
+		return outputSignature{}, nil
+	case results.Len() == 1 && types.Identical(results.At(0).Type(), errorType):
+		return outputSignature{err: true}, nil
+	}
+	return funcOutput(sig)
+}
+
+// buildsForEffect reports whether set gives an injector without an output
+// anything to build: types required with wire.Require, or the parameters
+// of wire.After hooks.
+func (set *ProviderSet) buildsForEffect() bool {
+	return len(set.requirements()) > 0 || len(set.Afters) > 0
+}
+
 type outputSignature struct {
-	out     types.Type
-	cleanup bool
-	err     bool
+	out        types.Type
+	cleanup    bool
+	cleanupErr bool
+	err        bool
+	// must is set for injectors declared with wire.MustBuild, which panic
+	// with the errors of their providers since they cannot return them.
+	must bool
+}
+
+// canFail reports whether an injector with the signature may call
+// providers that return errors.
+func (sig outputSignature) canFail() bool {
+	return sig.err || sig.must
 }
 
 // funcOutput validates an injector or provider function's return signature.
@@ -807,20 +1630,24 @@ func funcOutput(sig *types.Signature) (outputSignature, error) {
 			return outputSignature{out: out, err: true}, nil
 		case types.Identical(t, cleanupType):
 			return outputSignature{out: out, cleanup: true}, nil
+		case types.Identical(t, errCleanupType):
+			return outputSignature{out: out, cleanup: true, cleanupErr: true}, nil
 		default:
-			return outputSignature{}, fmt.Errorf("second return type is %s; must be error or func()", types.TypeString(t, nil))
+			return outputSignature{}, fmt.Errorf("second return type is %s; must be error, func(), or func() error", types.TypeString(t, nil))
 		}
 	case 3:
-		if t := results.At(1).Type(); !types.Identical(t, cleanupType) {
-			return outputSignature{}, fmt.Errorf("second return type is %s; must be func()", types.TypeString(t, nil))
+		t := results.At(1).Type()
+		if !types.Identical(t, cleanupType) && !types.Identical(t, errCleanupType) {
+			return outputSignature{}, fmt.Errorf("second return type is %s; must be func() or func() error", types.TypeString(t, nil))
 		}
 		if t := results.At(2).Type(); !types.Identical(t, errorType) {
 			return outputSignature{}, fmt.Errorf("third return type is %s; must be error", types.TypeString(t, nil))
 		}
 		return outputSignature{
-			out:     results.At(0).Type(),
-			cleanup: true,
-			err:     true,
+			out:        results.At(0).Type(),
+			cleanup:    true,
+			cleanupErr: types.Identical(t, errCleanupType),
+			err:        true,
 		}, nil
 	default:
 		return outputSignature{}, errors.New("too many return values")
@@ -862,7 +1689,7 @@ func processStructLiteralProvider(fset *token.FileSet, typeName *types.TypeName)
 		}
 		for j := 0; j < i; j++ {
 			if types.Identical(provider.Args[i].Type, provider.Args[j].Type) {
-				return nil, []error{notePosition(fset.Position(pos), fmt.Errorf("provider struct has multiple fields of type %s", types.TypeString(provider.Args[j].Type, nil)))}
+				return nil, []error{notePosition(fset.Position(pos), fmt.Errorf("provider struct has multiple fields of type %s", TypeString(provider.Args[j].Type)))}
 			}
 		}
 	}
@@ -929,7 +1756,7 @@ func processStructProvider(fset *token.FileSet, info *types.Info, call *ast.Call
 		for j := 0; j < i; j++ {
 			if types.Identical(provider.Args[i].Type, provider.Args[j].Type) {
 				f := st.Field(j)
-				return nil, notePosition(fset.Position(f.Pos()), fmt.Errorf("provider struct has multiple fields of type %s", types.TypeString(provider.Args[j].Type, nil)))
+				return nil, notePosition(fset.Position(f.Pos()), fmt.Errorf("provider struct has multiple fields of type %s", TypeString(provider.Args[j].Type)))
 			}
 		}
 	}
@@ -991,7 +1818,7 @@ func processBind(fset *token.FileSet, info *types.Info, call *ast.CallExpr) (*If
 	}
 	if !types.Implements(provided, methodSet) {
 		return nil, notePosition(fset.Position(call.Pos()),
-			fmt.Errorf("%s does not implement %s", types.TypeString(provided, nil), types.TypeString(iface, nil)))
+			fmt.Errorf("%s does not implement %s", TypeString(provided), TypeString(iface)))
 	}
 	return &IfaceBinding{
 		Pos:      call.Pos(),
@@ -1000,6 +1827,256 @@ func processBind(fset *token.FileSet, info *types.Info, call *ast.CallExpr) (*If
 	}, nil
 }
 
+// processShared creates a shared provider from a wire.Shared call. The
+// provider is copied, since the same function may also be used unshared.
+func (oc *objectCache) processShared(info *types.Info, pkgPath string, call *ast.CallExpr) (*Provider, []error) {
+	// Assumes that call.Fun is wire.Shared.
+
+	if len(call.Args) != 1 {
+		return nil, []error{notePosition(oc.fset.Position(call.Pos()),
+			errors.New("call to Shared takes exactly one argument"))}
+	}
+	item, errs := oc.processExpr(info, pkgPath, call.Args[0], "")
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	p, ok := item.(*Provider)
+	if !ok || p.IsStruct {
+		return nil, []error{notePosition(oc.fset.Position(call.Pos()),
+			errors.New("argument to Shared must be a provider function"))}
+	}
+	if p.HasCleanup {
+		return nil, []error{notePosition(oc.fset.Position(call.Pos()),
+			fmt.Errorf("shared provider %s cannot return a cleanup function", p.Name))}
+	}
+	shared := *p
+	shared.Shared = true
+	return &shared, nil
+}
+
+// processExclude creates a provider set from a wire.Exclude call. The set
+// imports a copy of the set passed to Exclude without the bindings of the
+// excluded types.
+func (oc *objectCache) processExclude(info *types.Info, pkgPath string, call *ast.CallExpr, varName string) (*ProviderSet, []error) {
+	// Assumes that call.Fun is wire.Exclude.
+
+	if len(call.Args) < 2 {
+		return nil, []error{notePosition(oc.fset.Position(call.Pos()),
+			errors.New("call to Exclude takes a provider set and at least one type to exclude"))}
+	}
+	item, errs := oc.processExpr(info, pkgPath, call.Args[0], "")
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	set, ok := item.(*ProviderSet)
+	if !ok {
+		return nil, []error{notePosition(oc.fset.Position(call.Pos()),
+			errors.New("first argument to Exclude must be a provider set"))}
+	}
+	setName := "provider set"
+	if set.VarName != "" {
+		setName = set.VarName
+	}
+	excluded := new(typeutil.Map)
+	excluded.SetHasher(oc.hasher)
+	ec := new(errorCollector)
+	for _, arg := range call.Args[1:] {
+		argType := info.TypeOf(arg)
+		ptr, ok := argType.(*types.Pointer)
+		if !ok {
+			ec.add(notePosition(oc.fset.Position(arg.Pos()),
+				fmt.Errorf("argument to Exclude must be a pointer to the excluded type; found %s", types.TypeString(argType, nil))))
+			continue
+		}
+		if set.providerMap.At(ptr.Elem()) == nil {
+			ec.add(notePosition(oc.fset.Position(arg.Pos()),
+				fmt.Errorf("cannot exclude %s: %s does not provide it", types.TypeString(ptr.Elem(), nil), setName)))
+			continue
+		}
+		excluded.Set(ptr.Elem(), true)
+	}
+	if len(ec.errors) > 0 {
+		return nil, ec.errors
+	}
+	trimmed, errs := oc.withoutTypes(set, excluded)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	pset := &ProviderSet{
+		Pos:     call.Pos(),
+		PkgPath: pkgPath,
+		VarName: varName,
+		Imports: []*ProviderSet{trimmed},
+	}
+	if errs := oc.rebuildProviderMap(pset); len(errs) > 0 {
+		return nil, errs
+	}
+	return pset, nil
+}
+
+// withoutTypes returns a copy of set without the providers, bindings,
+// values, and fields that provide a type in excluded, recursing into the
+// sets it imports. Sets that provide none of the types are not copied.
+func (oc *objectCache) withoutTypes(set *ProviderSet, excluded *typeutil.Map) (*ProviderSet, []error) {
+	isExcluded := func(ts ...types.Type) bool {
+		for _, t := range ts {
+			if excluded.At(t) != nil {
+				return true
+			}
+		}
+		return false
+	}
+	provides := false
+	for _, t := range excluded.Keys() {
+		if set.providerMap.At(t) != nil {
+			provides = true
+			break
+		}
+	}
+	if !provides {
+		return set, nil
+	}
+	trimmed := *set
+	trimmed.Providers, trimmed.Bindings, trimmed.Values, trimmed.Fields, trimmed.Imports = nil, nil, nil, nil, nil
+	for _, p := range set.Providers {
+		if !isExcluded(p.Out...) {
+			trimmed.Providers = append(trimmed.Providers, p)
+		}
+	}
+	for _, b := range set.Bindings {
+		if !isExcluded(b.Iface) {
+			trimmed.Bindings = append(trimmed.Bindings, b)
+		}
+	}
+	for _, v := range set.Values {
+		if !isExcluded(v.Out) {
+			trimmed.Values = append(trimmed.Values, v)
+		}
+	}
+	for _, f := range set.Fields {
+		if !isExcluded(f.Out...) {
+			trimmed.Fields = append(trimmed.Fields, f)
+		}
+	}
+	for _, imp := range set.Imports {
+		imp, errs := oc.withoutTypes(imp, excluded)
+		if len(errs) > 0 {
+			return nil, errs
+		}
+		trimmed.Imports = append(trimmed.Imports, imp)
+	}
+	if errs := oc.rebuildProviderMap(&trimmed); len(errs) > 0 {
+		return nil, errs
+	}
+	return &trimmed, nil
+}
+
+// processRequire creates a requirement from a wire.Require call.
+func processRequire(fset *token.FileSet, info *types.Info, call *ast.CallExpr) (*Requirement, error) {
+	// Assumes that call.Fun is wire.Require.
+
+	if len(call.Args) != 1 {
+		return nil, notePosition(fset.Position(call.Pos()),
+			errors.New("call to Require takes exactly one argument"))
+	}
+	argType := info.TypeOf(call.Args[0])
+	ptr, ok := argType.(*types.Pointer)
+	if !ok {
+		return nil, notePosition(fset.Position(call.Pos()),
+			fmt.Errorf("argument to Require must be a pointer to the required type; found %s", types.TypeString(argType, nil)))
+	}
+	return &Requirement{
+		Type: ptr.Elem(),
+		Pos:  call.Pos(),
+	}, nil
+}
+
+// processCopy creates a copied type from a wire.Copy call.
+func processCopy(fset *token.FileSet, info *types.Info, call *ast.CallExpr) (*CopiedType, error) {
+	// Assumes that call.Fun is wire.Copy.
+
+	if len(call.Args) != 1 {
+		return nil, notePosition(fset.Position(call.Pos()),
+			errors.New("call to Copy takes exactly one argument"))
+	}
+	argType := info.TypeOf(call.Args[0])
+	ptr, ok := argType.(*types.Pointer)
+	if !ok {
+		return nil, notePosition(fset.Position(call.Pos()),
+			fmt.Errorf("argument to Copy must be a pointer to the copied type; found %s", types.TypeString(argType, nil)))
+	}
+	return &CopiedType{
+		Type: ptr.Elem(),
+		Pos:  call.Pos(),
+	}, nil
+}
+
+// processAfter creates a hook from a wire.After call.
+func processAfter(fset *token.FileSet, info *types.Info, call *ast.CallExpr) (*AfterHook, error) {
+	// Assumes that call.Fun is wire.After.
+
+	if len(call.Args) != 1 {
+		return nil, notePosition(fset.Position(call.Pos()),
+			errors.New("call to After takes exactly one argument"))
+	}
+	arg := call.Args[0]
+	for {
+		paren, ok := arg.(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		arg = paren.X
+	}
+	lit, ok := arg.(*ast.FuncLit)
+	if !ok {
+		return nil, notePosition(fset.Position(call.Pos()),
+			errors.New("argument to After must be a function literal"))
+	}
+	sig := info.TypeOf(lit).(*types.Signature)
+	if sig.Variadic() {
+		return nil, notePosition(fset.Position(lit.Pos()),
+			errors.New("function passed to After cannot be variadic"))
+	}
+	hook := &AfterHook{
+		Lit:  lit,
+		Info: info,
+		Pos:  call.Pos(),
+	}
+	switch res := sig.Results(); {
+	case res.Len() == 0:
+	case res.Len() == 1 && types.Identical(res.At(0).Type(), errorType):
+		hook.Err = true
+	default:
+		return nil, notePosition(fset.Position(lit.Pos()),
+			errors.New("function passed to After must return nothing or an error"))
+	}
+	for i := 0; i < sig.Params().Len(); i++ {
+		hook.Params = append(hook.Params, sig.Params().At(i).Type())
+	}
+	// The literal is copied out of the injector template, so it can only
+	// use variables it declares and package-level ones.
+	var err error
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok || err != nil {
+			return err == nil
+		}
+		v, ok := info.Uses[id].(*types.Var)
+		if !ok || v.IsField() || v.Pkg() == nil || v.Parent() == v.Pkg().Scope() {
+			return true
+		}
+		if v.Pos() < lit.Pos() || v.Pos() >= lit.End() {
+			err = notePosition(fset.Position(id.Pos()),
+				fmt.Errorf("function passed to After refers to %s, a variable of the injector; take it as a parameter instead", id.Name))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
 // processValue creates a value from a wire.Value call.
 func processValue(fset *token.FileSet, info *types.Info, call *ast.CallExpr) (*Value, error) {
 	// Assumes that call.Fun is wire.Value.
@@ -1007,30 +2084,24 @@ func processValue(fset *token.FileSet, info *types.Info, call *ast.CallExpr) (*V
 	if len(call.Args) != 1 {
 		return nil, notePosition(fset.Position(call.Pos()), errors.New("call to Value takes exactly one argument"))
 	}
-	ok := true
+	// The expression is copied into a package-level variable of the
+	// injector's package, so it is evaluated during initialization. Function
+	// literals are not called then, so only the expression outside of them
+	// matters.
+	var receive *ast.UnaryExpr
 	ast.Inspect(call.Args[0], func(node ast.Node) bool {
 		switch expr := node.(type) {
-		case nil, *ast.ArrayType, *ast.BasicLit, *ast.BinaryExpr, *ast.ChanType, *ast.CompositeLit, *ast.FuncType, *ast.Ident, *ast.IndexExpr, *ast.InterfaceType, *ast.KeyValueExpr, *ast.MapType, *ast.ParenExpr, *ast.SelectorExpr, *ast.SliceExpr, *ast.StarExpr, *ast.StructType, *ast.TypeAssertExpr:
-			// Good!
+		case *ast.FuncLit:
+			return false
 		case *ast.UnaryExpr:
 			if expr.Op == token.ARROW {
-				ok = false
-				return false
+				receive = expr
 			}
-		case *ast.CallExpr:
-			// Only acceptable if it's a type conversion.
-			if _, isFunc := info.TypeOf(expr.Fun).(*types.Signature); isFunc {
-				ok = false
-				return false
-			}
-		default:
-			ok = false
-			return false
 		}
-		return true
+		return receive == nil
 	})
-	if !ok {
-		return nil, notePosition(fset.Position(call.Pos()), errors.New("argument to Value is too complex"))
+	if receive != nil {
+		return nil, notePosition(fset.Position(receive.Pos()), errors.New("argument to Value may not receive from a channel, since it is evaluated when the package is initialized"))
 	}
 	// Result type can't be an interface type; use wire.InterfaceValue for that.
 	argType := info.TypeOf(call.Args[0])
@@ -1064,7 +2135,7 @@ func processInterfaceValue(fset *token.FileSet, info *types.Info, call *ast.Call
 	}
 	provided := info.TypeOf(call.Args[1])
 	if !types.Implements(provided, methodSet) {
-		return nil, notePosition(fset.Position(call.Pos()), fmt.Errorf("%s does not implement %s", types.TypeString(provided, nil), types.TypeString(iface, nil)))
+		return nil, notePosition(fset.Position(call.Pos()), fmt.Errorf("%s does not implement %s", TypeString(provided), TypeString(iface)))
 	}
 	return &Value{
 		Pos:  call.Args[1].Pos(),
@@ -1152,7 +2223,8 @@ func checkField(f ast.Expr, st *types.Struct) (*types.Var, error) {
 	return nil, fmt.Errorf("%s is not a field of %s", b.Value, st.String())
 }
 
-// findInjectorBuild returns the wire.Build call if fn is an injector template.
+// findInjectorBuild returns the wire.Build, wire.TestBuild, wire.MustBuild,
+// or wire.Populate call if fn is an injector template.
 // It returns nil if the function is not an injector template.
 func findInjectorBuild(info *types.Info, fn *ast.FuncDecl) (*ast.CallExpr, error) {
 	if fn.Body == nil {
@@ -1182,7 +2254,10 @@ func findInjectorBuild(info *types.Info, fn *ast.FuncDecl) (*ast.CallExpr, error
 				}
 			}
 			buildObj := qualifiedIdentObject(info, call.Fun)
-			if buildObj == nil || buildObj.Pkg() == nil || !isWireImport(buildObj.Pkg().Path()) || buildObj.Name() != "Build" {
+			if buildObj == nil || buildObj.Pkg() == nil || !isWireImport(buildObj.Pkg().Path()) {
+				continue
+			}
+			if name := buildObj.Name(); name != "Build" && name != "TestBuild" && name != "MustBuild" && name != "Populate" {
 				continue
 			}
 			wireBuildCall = call
@@ -1221,6 +2296,16 @@ func isWireImport(path string) bool {
 	}
 }
 
+// isContextType reports whether t is context.Context.
+func isContextType(t types.Type) bool {
+	n, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := n.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "context" && obj.Name() == "Context"
+}
+
 func isProviderSetType(t types.Type) bool {
 	n, ok := t.(*types.Named)
 	if !ok {
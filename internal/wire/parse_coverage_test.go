@@ -233,13 +233,13 @@ func TestProcessFuncProviderErrors(t *testing.T) {
 	results := types.NewTuple(types.NewVar(token.NoPos, pkg, "", types.Typ[types.String]))
 	sig := types.NewSignatureType(nil, nil, nil, params, results, false)
 	fn := types.NewFunc(token.NoPos, pkg, "Provide", sig)
-	if _, errs := processFuncProvider(fset, fn); len(errs) == 0 {
+	if _, errs := processFuncProvider(fset, fn, fn.Type().(*types.Signature)); len(errs) == 0 {
 		t.Fatal("expected duplicate param error")
 	}
 
 	noResultsSig := types.NewSignatureType(nil, nil, nil, types.NewTuple(), types.NewTuple(), false)
 	fn = types.NewFunc(token.NoPos, pkg, "ProvideNone", noResultsSig)
-	if _, errs := processFuncProvider(fset, fn); len(errs) == 0 {
+	if _, errs := processFuncProvider(fset, fn, fn.Type().(*types.Signature)); len(errs) == 0 {
 		t.Fatal("expected no-results error")
 	}
 }
@@ -395,8 +395,14 @@ func TestInjectorFuncSignature(t *testing.T) {
 	t.Parallel()
 
 	sig := types.NewSignatureType(nil, nil, nil, types.NewTuple(), types.NewTuple(), false)
-	if _, _, err := injectorFuncSignature(sig); err == nil {
-		t.Fatal("expected injector signature error")
+	if _, out, err := injectorFuncSignature(sig); err != nil || out.out != nil || out.err {
+		t.Fatalf("expected injector signature without output, got=%+v err=%v", out, err)
+	}
+
+	errResult := types.NewTuple(types.NewVar(token.NoPos, nil, "", errorType))
+	sig = types.NewSignatureType(nil, nil, nil, types.NewTuple(), errResult, false)
+	if _, out, err := injectorFuncSignature(sig); err != nil || out.out != nil || !out.err {
+		t.Fatalf("expected injector signature returning only an error, got=%+v err=%v", out, err)
 	}
 
 	results := types.NewTuple(types.NewVar(token.NoPos, nil, "", types.Typ[types.Int]))
@@ -15,11 +15,16 @@
 package wire
 
 import (
+	"errors"
+	"fmt"
 	"go/ast"
+	"go/parser"
 	"go/token"
 	"go/types"
+	"strings"
 	"testing"
 
+	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/types/typeutil"
 )
 
@@ -55,6 +60,51 @@ func TestInjectorString(t *testing.T) {
 	}
 }
 
+func TestInjectorConstructionOrder(t *testing.T) {
+	intT := types.Typ[types.Int]
+	newConfig := &Provider{Name: "NewConfig"}
+	newServer := &Provider{Name: "NewServer"}
+	inj := &Injector{
+		Params: types.NewTuple(),
+		Out:    intT,
+		Steps: []InjectorStep{
+			{Out: intT, Provider: newConfig},
+			{Out: intT, Value: &Value{}},
+			{Out: intT, Provider: newServer, Args: []int{0, 1}},
+			{Out: intT, Provider: newConfig},
+		},
+	}
+	got := inj.ConstructionOrder()
+	if len(got) != 2 || got[0] != newConfig || got[1] != newServer {
+		t.Fatalf("ConstructionOrder = %v, want [NewConfig NewServer]", got)
+	}
+}
+
+func TestInjectorOutIndex(t *testing.T) {
+	intT := types.Typ[types.Int]
+	params := types.NewTuple(
+		types.NewVar(token.NoPos, nil, "s", types.Typ[types.String]),
+		types.NewVar(token.NoPos, nil, "n", intT),
+	)
+	inj := &Injector{Params: params, Out: intT}
+	if got := inj.OutIndex(); got != 1 {
+		t.Fatalf("OutIndex for argument = %d, want 1", got)
+	}
+	inj.Steps = []InjectorStep{{Out: intT}, {Out: intT}}
+	if got := inj.OutIndex(); got != 3 {
+		t.Fatalf("OutIndex for step = %d, want 3", got)
+	}
+	inj.Steps = append(inj.Steps, InjectorStep{Out: types.Typ[types.Bool]})
+	inj.afterSteps = 1
+	if got := inj.OutIndex(); got != 3 {
+		t.Fatalf("OutIndex with wire.After step = %d, want 3", got)
+	}
+	inj = &Injector{Params: types.NewTuple(), Out: intT}
+	if got := inj.OutIndex(); got != -1 {
+		t.Fatalf("OutIndex with no source = %d, want -1", got)
+	}
+}
+
 func TestStructArgType(t *testing.T) {
 	pkg := types.NewPackage("example.com/p", "p")
 	obj := types.NewTypeName(token.NoPos, pkg, "S", nil)
@@ -152,8 +202,19 @@ func TestProcessValue(t *testing.T) {
 	info.Types[fnIdent] = types.TypeAndValue{Type: types.NewSignatureType(nil, nil, nil, nil, nil, false)}
 	info.Types[fnCall] = types.TypeAndValue{Type: types.Typ[types.Int]}
 	call = &ast.CallExpr{Fun: &ast.Ident{Name: "Value"}, Args: []ast.Expr{fnCall}}
-	if _, err := processValue(fset, info, call); err == nil {
-		t.Fatal("expected func call error")
+	if _, err := processValue(fset, info, call); err != nil {
+		t.Fatalf("expected func call value, got %v", err)
+	}
+
+	// A receive in a function literal does not happen during initialization.
+	lit := &ast.FuncLit{
+		Type: &ast.FuncType{Params: &ast.FieldList{}},
+		Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ExprStmt{X: &ast.UnaryExpr{Op: token.ARROW, X: &ast.Ident{Name: "ch"}}}}},
+	}
+	info.Types[lit] = types.TypeAndValue{Type: types.NewSignatureType(nil, nil, nil, nil, nil, false)}
+	call = &ast.CallExpr{Fun: &ast.Ident{Name: "Value"}, Args: []ast.Expr{lit}}
+	if _, err := processValue(fset, info, call); err != nil {
+		t.Fatalf("expected func literal value, got %v", err)
 	}
 
 	iface := types.NewInterfaceType(nil, nil)
@@ -432,3 +493,145 @@ func TestIsProviderSetType(t *testing.T) {
 func typeutilMakeMap() *typeutil.Map {
 	return &typeutil.Map{}
 }
+
+func TestSuggestedFixes(t *testing.T) {
+	pos := token.Position{Filename: "wire.go", Line: 3, Column: 1}
+	end := token.Position{Filename: "wire.go", Line: 3, Column: 5}
+	fix := SuggestedFix{Message: "fix it", Edits: []TextEdit{
+		{Pos: pos, End: pos, NewText: "x"},
+		{Pos: pos, End: end, NewText: "y"},
+	}}
+	err := &wireErr{error: errors.New("broken"), position: pos, fixes: []SuggestedFix{fix}}
+	if got := SuggestedFixes(err); len(got) != 1 || got[0].Message != "fix it" {
+		t.Fatalf("SuggestedFixes = %v", got)
+	}
+	if got := SuggestedFixes(errors.New("plain")); got != nil {
+		t.Fatalf("SuggestedFixes(plain) = %v; want nil", got)
+	}
+	want := "wire.go:3:1: broken\n\tsuggested fix: fix it\n\t\twire.go:3:1: insert \"x\"\n\t\twire.go:3:1-3:5: replace with \"y\""
+	if got := err.Error(); got != want {
+		t.Fatalf("Error() = %q; want %q", got, want)
+	}
+}
+
+func TestNewError(t *testing.T) {
+	pos := token.Position{Filename: "wire.go", Line: 7, Column: 2}
+	inner := withCode(CodeNoProvider, errors.New("no provider found for int"))
+	err := fmt.Errorf("inject Init: %w", notePosition(pos, inner))
+	e := newError("example.com/app", err)
+	if e.Pkg != "example.com/app" || e.Pos != pos || e.Code != CodeNoProvider || e.Msg != "no provider found for int" {
+		t.Errorf("newError(wireErr) = %+v", e)
+	}
+	if e.Error() != err.Error() || !errors.Is(e, inner) {
+		t.Errorf("newError(wireErr) does not wrap %v", err)
+	}
+
+	loadErr := &pkgError{pkg: "example.com/dep", error: packages.Error{Pos: "/src/dep/dep.go:4:9", Msg: "undefined: x"}}
+	e = newError("", loadErr)
+	want := token.Position{Filename: "/src/dep/dep.go", Line: 4, Column: 9}
+	if e.Pkg != "example.com/dep" || e.Pos != want || e.Code != CodeLoad || e.Msg != "undefined: x" {
+		t.Errorf("newError(load error) = %+v", e)
+	}
+
+	e = newError("p", errors.New("plain"))
+	if e.Pos.IsValid() || e.Code != "" || e.Msg != "plain" {
+		t.Errorf("newError(plain) = %+v", e)
+	}
+	if l := newErrorList("p", nil); l != nil || l.Errors() != nil {
+		t.Errorf("newErrorList(nil) = %v; want nil", l)
+	}
+}
+
+func TestModuleDownloadErrors(t *testing.T) {
+	tests := []struct {
+		msg      string
+		download bool
+		hint     string
+	}{
+		{"undefined: x", false, ""},
+		{"example.com/private@v1.0.0: module lookup disabled by GOPROXY=off", true, "downloads are disabled"},
+		{"example.com/private@v1.0.0: verifying module: example.com/private@v1.0.0: reading https://sum.golang.org/lookup/example.com/private@v1.0.0: 404 Not Found", true, "GOPRIVATE"},
+		{"git ls-remote -q origin: fatal: could not read Username for 'https://example.com': terminal prompts disabled", true, "GOPRIVATE"},
+		{"Get \"https://proxy.golang.org/example.com/dep/@v/list\": dial tcp: lookup proxy.golang.org: no such host", true, "-offline"},
+	}
+	for _, test := range tests {
+		e := newError("example.com/app", &pkgError{pkg: "example.com/app", error: classifyPackageError(packages.Error{Pos: "/src/app/app.go:3:8", Msg: test.msg})})
+		if !test.download {
+			if e.Code != CodeLoad || e.Msg != test.msg {
+				t.Errorf("load error %q = %+v; want it unchanged", test.msg, e)
+			}
+			continue
+		}
+		if e.Code != CodeModuleDownload || !strings.HasPrefix(e.Msg, test.msg+"\n") || !strings.Contains(e.Msg, "not a Wire error") || !strings.Contains(e.Msg, test.hint) {
+			t.Errorf("load error %q = %+v; want CodeModuleDownload with a hint mentioning %q", test.msg, e, test.hint)
+		}
+		if e.Pos.Line != 3 {
+			t.Errorf("load error %q lost its position: %v", test.msg, e.Pos)
+		}
+	}
+
+	if err := classifyLoadError(errors.New("go list: exit status 1: dial tcp 10.0.0.1:443: i/o timeout")); newError("", err).Code != CodeModuleDownload {
+		t.Errorf("classifyLoadError did not classify a network timeout: %v", err)
+	}
+	if err := classifyLoadError(errors.New("go list: exit status 1: malformed import path")); newError("", err).Code != "" {
+		t.Errorf("classifyLoadError classified an unrelated failure: %v", err)
+	}
+	if classifyLoadError(nil) != nil {
+		t.Error("classifyLoadError(nil) != nil")
+	}
+}
+
+func TestParsePosition(t *testing.T) {
+	tests := []struct {
+		in   string
+		want token.Position
+	}{
+		{"a/b.go:3:4", token.Position{Filename: "a/b.go", Line: 3, Column: 4}},
+		{"C:/a/b.go:3", token.Position{Filename: "C:/a/b.go", Line: 3}},
+		{"-", token.Position{}},
+		{"", token.Position{}},
+	}
+	for _, test := range tests {
+		if got := parsePosition(test.in); got != test.want {
+			t.Errorf("parsePosition(%q) = %+v; want %+v", test.in, got, test.want)
+		}
+	}
+}
+
+func TestCheckFileSyntax(t *testing.T) {
+	const header = "//go:build wireinject\n\npackage app\n\nimport \"github.com/goforj/wire\"\n\n"
+	tests := []struct {
+		name string
+		src  string
+		want string // empty if the file is valid
+	}{
+		{"valid", header + "func Init() (*App, func(), error) {\n\twire.Build(Set)\n\treturn nil, nil, nil\n}\n", ""},
+		{"panic", header + "func Init() *App {\n\tpanic(wire.Build(Set))\n}\n", ""},
+		{"populate", header + "func Init(app *App) {\n\twire.Populate(app, Set)\n}\n", ""},
+		{"not an injector", header + "func helper() int {\n\treturn 1\n}\n", ""},
+		{"dot import", "//go:build wireinject\n\npackage app\n\nimport . \"github.com/google/wire\"\n\nfunc Init() *App {\n\tBuild(Set)\n}\n", "must be followed by a return statement"},
+		{"no results", header + "func Register() {\n\twire.Build(Set)\n}\n", ""},
+		{"missing tag", "package app\n\nimport di \"github.com/goforj/wire\"\n\nfunc Init() *App {\n\tpanic(di.Build(Set))\n}\n", "wireinject build tag"},
+		{"extra statements", header + "func Init() *App {\n\tx := 1\n\t_ = x\n\twire.Build(Set)\n\treturn nil\n}\n", "must consist of only the wire.Build call"},
+		{"bad second result", header + "func Init() (*App, string) {\n\twire.Build(Set)\n\treturn nil, \"\"\n}\n", "second return type is string"},
+		{"bad third result", header + "func Init() (*App, func(), string) {\n\twire.Build(Set)\n\treturn nil, nil, \"\"\n}\n", "third return type is string"},
+		{"missing return", header + "func Init() *App {\n\twire.Build(Set)\n}\n", "must be followed by a return statement"},
+	}
+	for _, test := range tests {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, "wire.go", test.src, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		errs := checkFileSyntax(fset, f)
+		if test.want == "" {
+			if len(errs) > 0 {
+				t.Errorf("%s: checkFileSyntax = %v; want no errors", test.name, errs)
+			}
+			continue
+		}
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), test.want) {
+			t.Errorf("%s: checkFileSyntax = %v; want one error containing %q", test.name, errs, test.want)
+		}
+	}
+}
@@ -14,7 +14,16 @@
 
 package wire
 
-import "testing"
+import (
+	"context"
+	"fmt"
+	"go/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
 
 func TestIsWireImport(t *testing.T) {
 	tests := []struct {
@@ -33,3 +42,394 @@ func TestIsWireImport(t *testing.T) {
 		}
 	}
 }
+
+func TestMatchPackages(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/mono\n\ngo 1.19\n")
+	writeFile(t, filepath.Join(root, "services", "users", "users.go"), "package users\n")
+	writeFile(t, filepath.Join(root, "services", "users", "store", "store.go"), "package store\n")
+	writeFile(t, filepath.Join(root, "lib", "lib.go"), "package lib\n")
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	got, err := MatchPackages(ctx, root, env, "", []string{"./..."}, regexp.MustCompile(`/services/[^/]+$`))
+	if err != nil {
+		t.Fatalf("MatchPackages failed: %v", err)
+	}
+	if len(got) != 1 || got[0] != "example.com/mono/services/users" {
+		t.Fatalf("MatchPackages = %v, want [example.com/mono/services/users]", got)
+	}
+	if _, err := MatchPackages(ctx, root, env, "", []string{"./..."}, regexp.MustCompile(`nothing`)); err == nil {
+		t.Fatal("expected error when no packages match")
+	}
+}
+
+func TestInjectorSteps(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"type Config struct{}",
+		"type Foo struct{ cfg *Config }",
+		"",
+		"func NewConfig() *Config { return &Config{} }",
+		"",
+		"func NewFoo(cfg *Config) *Foo { return &Foo{cfg: cfg} }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func InitFoo(name string) *Foo {",
+		"\twire.Build(NewFoo, NewConfig)",
+		"\treturn nil",
+		"}",
+		"",
+		"func InitName(name string) string {",
+		"\twire.Build()",
+		"\treturn \"\"",
+		"}",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	info, errs := Load(context.Background(), root, env, "", []string{"./app"})
+	if len(errs) > 0 {
+		t.Fatalf("Load failed: %v", errs)
+	}
+	if info.Fset == nil {
+		t.Fatal("Load returned a nil Fset")
+	}
+	if len(info.Injectors) != 2 {
+		t.Fatalf("Load found %d injectors; want 2", len(info.Injectors))
+	}
+	foo, name := info.Injectors[0], info.Injectors[1]
+	var steps []string
+	for _, step := range foo.Steps {
+		if step.Provider == nil {
+			t.Fatalf("InitFoo step %v has no provider", step)
+		}
+		steps = append(steps, step.Provider.Name)
+	}
+	if want := []string{"NewConfig", "NewFoo"}; strings.Join(steps, ",") != strings.Join(want, ",") {
+		t.Errorf("InitFoo steps = %v; want %v", steps, want)
+	}
+	// Arguments number the injector's parameters, then its steps.
+	if got := foo.OutIndex(); got != 2 {
+		t.Errorf("InitFoo OutIndex() = %d; want 2", got)
+	}
+	if got := foo.Steps[1].Args; len(got) != 1 || got[0] != 1 {
+		t.Errorf("NewFoo step args = %v; want [1]", got)
+	}
+	if got := name.OutIndex(); got != 0 {
+		t.Errorf("InitName OutIndex() = %d; want 0, its parameter", got)
+	}
+}
+
+func TestLoadUnusedParams(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"type Config struct{ Debug bool }",
+		"type Logger struct{}",
+		"type DB struct{}",
+		"type App struct{ db *DB }",
+		"",
+		"func NewLogger() *Logger { return &Logger{} }",
+		"",
+		"func NewDB(cfg Config, _ *Logger) *DB {",
+		"	if cfg.Debug {",
+		"		return nil",
+		"	}",
+		"	return &DB{}",
+		"}",
+		"",
+		"func NewApp(db *DB, logger *Logger) *App { return &App{db: db} }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func InitApp() *App {",
+		"	panic(wire.Build(NewApp, NewDB, NewLogger, wire.Value(Config{})))",
+		"}",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	info, errs := Load(context.Background(), root, env, "", []string{"./app"})
+	if len(errs) > 0 {
+		t.Fatalf("Load failed: %v", errs)
+	}
+	var got []string
+	for _, u := range info.UnusedParams {
+		got = append(got, u.String())
+	}
+	// The unnamed *Logger parameter of NewDB is deliberately unused.
+	file := filepath.Join(root, "app", "app.go")
+	want := []string{
+		file + ":10:12: provider app.NewDB: parameter cfg is always the zero value",
+		file + ":17:21: provider app.NewApp: parameter logger is never used",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("UnusedParams:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestProviderSetInputs(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"type Config struct{}",
+		"type Logger struct{}",
+		"type Tracer struct{}",
+		"type DB struct{}",
+		"type App struct{}",
+		"",
+		"func NewDB(Config, *Logger) *DB { return nil }",
+		"",
+		"func NewApp(*DB, *Logger) *App { return nil }",
+		"",
+		"var Set = wire.NewSet(NewApp, NewDB, wire.Require(new(*Tracer)))",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	info, errs := Load(context.Background(), root, env, "", []string{"./app"})
+	if len(errs) > 0 {
+		t.Fatalf("Load failed: %v", errs)
+	}
+	set := info.Sets[ProviderSetID{ImportPath: "example.com/app/app", VarName: "Set"}]
+	if set == nil {
+		t.Fatal("Load did not find app.Set")
+	}
+	inputsOf := func(name string) string {
+		out := types.NewPointer(set.Providers[0].Pkg.Scope().Lookup(name).Type())
+		var got []string
+		for _, t := range set.Inputs(out) {
+			got = append(got, types.TypeString(t, nil))
+		}
+		return strings.Join(got, ", ")
+	}
+	for _, test := range []struct{ out, want string }{
+		{"App", "example.com/app/app.Config, *example.com/app/app.Logger, *example.com/app/app.Tracer"},
+		{"DB", "example.com/app/app.Config, *example.com/app/app.Logger, *example.com/app/app.Tracer"},
+		{"Logger", "*example.com/app/app.Logger, *example.com/app/app.Tracer"},
+	} {
+		if got := inputsOf(test.out); got != test.want {
+			t.Errorf("Inputs(*%s) = %s; want %s", test.out, got, test.want)
+		}
+	}
+}
+
+func TestInterfaceSource(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"type Greeter interface{ Greet() string }",
+		"type Clock interface{ Now() int }",
+		"type Store interface{ Get() string }",
+		"",
+		"type English struct{}",
+		"",
+		"func (English) Greet() string { return \"hello\" }",
+		"",
+		"type fixed struct{}",
+		"",
+		"func (fixed) Now() int { return 0 }",
+		"",
+		"func NewEnglish() English { return English{} }",
+		"",
+		"func NewStore() Store { return nil }",
+		"",
+		"var Base = wire.NewSet(NewEnglish, wire.Bind(new(Greeter), new(English)))",
+		"",
+		"var Set = wire.NewSet(Base, NewStore, wire.InterfaceValue(new(Clock), fixed{}))",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	info, errs := Load(context.Background(), root, env, "", []string{"./app"})
+	if len(errs) > 0 {
+		t.Fatalf("Load failed: %v", errs)
+	}
+	set := info.Sets[ProviderSetID{ImportPath: "example.com/app/app", VarName: "Set"}]
+	if set == nil {
+		t.Fatal("Load did not find app.Set")
+	}
+	scope := set.Providers[0].Pkg.Scope()
+	lookup := func(name string) types.Type { return scope.Lookup(name).Type() }
+
+	binding, value := set.InterfaceSource(lookup("Greeter"))
+	if binding == nil || value != nil {
+		t.Fatalf("InterfaceSource(Greeter) = %v, %v; want the wire.Bind of Base", binding, value)
+	}
+	if got, want := types.TypeString(binding.Provided, nil), "example.com/app/app.English"; got != want {
+		t.Errorf("InterfaceSource(Greeter) binds %s; want %s", got, want)
+	}
+	if got := info.Fset.Position(binding.Pos).Line; got != 21 {
+		t.Errorf("InterfaceSource(Greeter) binding is on line %d; want 21", got)
+	}
+
+	binding, value = set.InterfaceSource(lookup("Clock"))
+	if binding != nil || value == nil {
+		t.Fatalf("InterfaceSource(Clock) = %v, %v; want the wire.InterfaceValue", binding, value)
+	}
+	if got := info.Fset.Position(value.Pos).Line; got != 23 {
+		t.Errorf("InterfaceSource(Clock) value is on line %d; want 23", got)
+	}
+
+	for _, name := range []string{"Store", "English"} {
+		if binding, value := set.InterfaceSource(lookup(name)); binding != nil || value != nil {
+			t.Errorf("InterfaceSource(%s) = %v, %v; want nil, nil", name, binding, value)
+		}
+	}
+}
+
+func TestLoadWarnings(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "db", "db.go"), strings.Join([]string{
+		"package db",
+		"",
+		"type Conn struct{}",
+		"",
+		"// Open opens a connection.",
+		"//",
+		"// Deprecated: use OpenPool, which",
+		"// reuses connections.",
+		"func Open() *Conn { return &Conn{} }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"import \"example.com/app/db\"",
+		"",
+		"type Logger struct{}",
+		"type App struct{ conn *db.Conn }",
+		"",
+		"func NewLogger() *Logger { return &Logger{} }",
+		"",
+		"func NewApp(conn *db.Conn, logger *Logger) *App { return &App{conn: conn} }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package app",
+		"",
+		"import (",
+		"	\"example.com/app/db\"",
+		"	\"github.com/goforj/wire\"",
+		")",
+		"",
+		"func InitApp() *App {",
+		"	panic(wire.Build(NewApp, NewLogger, db.Open))",
+		"}",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	info, errs := Load(context.Background(), root, env, "", []string{"./app"})
+	if len(errs) > 0 {
+		t.Fatalf("Load failed: %v", errs)
+	}
+	var got []string
+	for _, w := range info.Warnings {
+		if w.Severity != SeverityWarning {
+			t.Errorf("%v: Severity = %v; want warning", w, w.Severity)
+		}
+		got = append(got, fmt.Sprintf("%s %s %v", w.Pkg, w.Code, w))
+	}
+	want := []string{
+		"example.com/app/app unused_param " + filepath.Join(root, "app", "app.go") + ":10:28: provider app.NewApp: parameter logger is never used",
+		"example.com/app/app deprecated " + filepath.Join(root, "app", "wire.go") + ":10:1: inject InitApp: provider example.com/app/db.Open is deprecated: use OpenPool, which reuses connections.",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Warnings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestBuildArgumentErrorPositions(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"type Config struct{}",
+		"",
+		"type Logger struct{}",
+		"",
+		"func NewConfig() *Config { return &Config{} }",
+		"",
+		"func NewLogger() *Logger { return &Logger{} }",
+		"",
+		"func OtherConfig() *Config { return &Config{} }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func InitConfig() *Config {",
+		"\twire.Build(",
+		"\t\tNewConfig,",
+		"\t\tNewLogger,",
+		"\t)",
+		"\treturn nil",
+		"}",
+		"",
+		"func InitConflict() *Config {",
+		"\twire.Build(",
+		"\t\tNewConfig,",
+		"\t\tOtherConfig,",
+		"\t)",
+		"\treturn nil",
+		"}",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	gens, errs := Generate(context.Background(), root, env, []string{"./app"}, &GenerateOptions{})
+	if len(errs) > 0 || len(gens) != 1 {
+		t.Fatalf("Generate = %d results, %v; want one result", len(gens), errs)
+	}
+	lines := make(map[string]int)
+	for _, e := range gens[0].Errs {
+		switch {
+		case strings.Contains(e.Msg, `unused provider "app.NewLogger"`):
+			lines["unused"] = e.Pos.Line
+		case strings.Contains(e.Msg, "multiple bindings for *example.com/app/app.Config"):
+			lines["conflict"] = e.Pos.Line
+		}
+	}
+	if lines["unused"] != 10 {
+		t.Errorf("unused provider error on line %d; want line 10, the NewLogger argument (errors: %v)", lines["unused"], gens[0].Errs)
+	}
+	if lines["conflict"] != 18 {
+		t.Errorf("conflict error on line %d; want line 18, the OtherConfig argument (errors: %v)", lines["conflict"], gens[0].Errs)
+	}
+}
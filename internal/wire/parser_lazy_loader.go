@@ -16,10 +16,12 @@ package wire
 
 import (
 	"context"
+	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/token"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"golang.org/x/tools/go/packages"
@@ -32,6 +34,12 @@ type lazyLoader struct {
 	tags      string
 	fset      *token.FileSet
 	baseFiles map[string]map[string]struct{}
+	// preloaded, if not nil, holds every package the loader can load,
+	// already type-checked, in place of the go command.
+	preloaded map[string]*packages.Package
+	// warm, if not nil, is the load kept by WarmLoads that the loader
+	// belongs to, which keeps its type-checked loads too.
+	warm *warmLoad
 }
 
 func collectPackageFiles(pkgs []*packages.Package) map[string]map[string]struct{} {
@@ -70,14 +78,68 @@ func collectAllPackages(pkgs []*packages.Package) map[string]*packages.Package {
 }
 
 func (ll *lazyLoader) load(pkgPath string) ([]*packages.Package, []error) {
-	return ll.loadWithMode(pkgPath, ll.fullMode(), "load.packages.lazy.load")
+	return ll.loadWithMode([]string{pkgPath}, ll.fullMode(), "load.packages.lazy.load")
 }
 
 func (ll *lazyLoader) fullMode() packages.LoadMode {
 	return packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports | packages.NeedDeps | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax
 }
 
-func (ll *lazyLoader) loadWithMode(pkgPath string, mode packages.LoadMode, timingLabel string) ([]*packages.Package, []error) {
+// loadAll type-checks the packages at pkgPaths in a single load, so that
+// they share the objects of the packages they import. It returns every
+// package that was loaded, errors and all, rather than failing if any of
+// them has errors, so that callers can keep the packages that loaded.
+func (ll *lazyLoader) loadAll(pkgPaths []string) ([]*packages.Package, error) {
+	if ll.preloaded != nil {
+		pkgs := make([]*packages.Package, 0, len(pkgPaths))
+		for _, pkgPath := range pkgPaths {
+			if pkg := ll.preloaded[pkgPath]; pkg != nil {
+				pkgs = append(pkgs, pkg)
+			}
+		}
+		return pkgs, nil
+	}
+	return ll.loadPackages(pkgPaths, ll.fullMode(), "load.packages.lazy.load_all")
+}
+
+func (ll *lazyLoader) loadWithMode(pkgPaths []string, mode packages.LoadMode, timingLabel string) ([]*packages.Package, []error) {
+	if ll.preloaded != nil {
+		return ll.loadPreloaded(pkgPaths)
+	}
+	pkgs, err := ll.loadPackages(pkgPaths, mode, timingLabel)
+	if err != nil {
+		return nil, []error{err}
+	}
+	errs := collectLoadErrors(pkgs)
+	if len(errs) > 0 {
+		errs = injectorTypeErrors(ll.fset, pkgs, errs)
+		if !allowErrors(ll.ctx) {
+			return nil, errs
+		}
+	}
+	return pkgs, errs
+}
+
+// loadPackages runs the go command to load the packages at pkgPaths with
+// mode, leaving their errors to the caller. A loader kept by WarmLoads
+// reuses the packages it loaded before.
+func (ll *lazyLoader) loadPackages(pkgPaths []string, mode packages.LoadMode, timingLabel string) ([]*packages.Package, error) {
+	if ll.warm == nil {
+		return ll.goLoad(pkgPaths, mode, timingLabel)
+	}
+	if pkgs, ok := ll.warm.typedLoad(pkgPaths, mode); ok {
+		debugf(ll.ctx, "reusing the syntax and types of %s", strings.Join(pkgPaths, " "))
+		return pkgs, nil
+	}
+	pkgs, err := ll.goLoad(pkgPaths, mode, timingLabel)
+	if err == nil {
+		ll.warm.keepTypedLoad(pkgPaths, mode, pkgs)
+	}
+	return pkgs, err
+}
+
+// goLoad runs the go command to load the packages at pkgPaths with mode.
+func (ll *lazyLoader) goLoad(pkgPaths []string, mode packages.LoadMode, timingLabel string) ([]*packages.Package, error) {
 	cfg := &packages.Config{
 		Context:    ll.ctx,
 		Mode:       mode,
@@ -85,26 +147,55 @@ func (ll *lazyLoader) loadWithMode(pkgPath string, mode packages.LoadMode, timin
 		Env:        ll.env,
 		BuildFlags: []string{"-tags=wireinject"},
 		Fset:       ll.fset,
-		ParseFile:  ll.parseFileFor(pkgPath),
+		ParseFile:  ll.parseFileFor(pkgPaths...),
+		Overlay:    loadOverlay(ll.ctx),
 	}
 	if len(ll.tags) > 0 {
 		cfg.BuildFlags[0] += " " + ll.tags
 	}
+	debugf(ll.ctx, "loading syntax and types of %s", strings.Join(pkgPaths, " "))
+	escaped := make([]string, len(pkgPaths))
+	for i := range pkgPaths {
+		escaped[i] = "pattern=" + pkgPaths[i]
+	}
 	loadStart := time.Now()
-	pkgs, err := packages.Load(cfg, "pattern="+pkgPath)
+	pkgs, err := packages.Load(cfg, escaped...)
 	logTiming(ll.ctx, timingLabel, loadStart)
-	if err != nil {
-		return nil, []error{err}
+	return pkgs, err
+}
+
+// loadPreloaded returns the preloaded packages at pkgPaths.
+func (ll *lazyLoader) loadPreloaded(pkgPaths []string) ([]*packages.Package, []error) {
+	pkgs := make([]*packages.Package, 0, len(pkgPaths))
+	for _, pkgPath := range pkgPaths {
+		pkg := ll.preloaded[pkgPath]
+		if pkg == nil {
+			return nil, []error{fmt.Errorf("package %s was not loaded", pkgPath)}
+		}
+		pkgs = append(pkgs, pkg)
 	}
 	errs := collectLoadErrors(pkgs)
-	if len(errs) > 0 {
+	if len(errs) > 0 && !allowErrors(ll.ctx) {
 		return nil, errs
 	}
-	return pkgs, nil
+	return pkgs, errs
 }
 
-func (ll *lazyLoader) parseFileFor(pkgPath string) func(*token.FileSet, string, []byte) (*ast.File, error) {
-	primary := ll.baseFiles[pkgPath]
+// parseFileFor returns a parser that keeps the function bodies and comments
+// of the files of the packages at pkgPaths only.
+func (ll *lazyLoader) parseFileFor(pkgPaths ...string) func(*token.FileSet, string, []byte) (*ast.File, error) {
+	primary := ll.baseFiles[pkgPaths[0]]
+	if len(pkgPaths) > 1 {
+		primary = make(map[string]struct{})
+		for _, pkgPath := range pkgPaths {
+			for name := range ll.baseFiles[pkgPath] {
+				primary[name] = struct{}{}
+			}
+		}
+		if len(primary) == 0 {
+			primary = nil
+		}
+	}
 	return func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
 		mode := parser.SkipObjectResolution
 		if primary != nil {
@@ -0,0 +1,286 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+)
+
+// isPopulateCall reports whether call, as returned by findInjectorBuild, is
+// a call to wire.Populate.
+func isPopulateCall(info *types.Info, call *ast.CallExpr) bool {
+	obj := qualifiedIdentObject(info, call.Fun)
+	return obj != nil && obj.Name() == "Populate"
+}
+
+// populateTarget returns the index of the parameter of an injector with
+// signature sig that call, a call to wire.Populate, fills in.
+func populateTarget(info *types.Info, sig *types.Signature, call *ast.CallExpr) (int, error) {
+	const targetReq = "first argument to Populate must be a parameter of the function of type pointer to a named struct"
+	if len(call.Args) == 0 {
+		return 0, errors.New(targetReq)
+	}
+	id, ok := astutil.Unparen(call.Args[0]).(*ast.Ident)
+	if !ok {
+		return 0, errors.New(targetReq)
+	}
+	obj := info.ObjectOf(id)
+	params := sig.Params()
+	for i := 0; i < params.Len(); i++ {
+		if params.At(i) != obj {
+			continue
+		}
+		ptr, ok := obj.Type().(*types.Pointer)
+		if !ok {
+			break
+		}
+		if _, ok := ptr.Elem().(*types.Named); !ok {
+			break
+		}
+		if _, ok := ptr.Elem().Underlying().(*types.Struct); !ok {
+			break
+		}
+		return i, nil
+	}
+	return 0, errors.New(targetReq)
+}
+
+// processPopulate returns the provider set for call, a call to
+// wire.Populate, along with the type to solve for. The set holds the
+// providers passed after the target, plus a struct provider that takes the
+// fields to fill in. The struct provider produces a stand-in for the target's
+// type with the same name, so that it neither conflicts with nor is used in
+// place of providers of the real type.
+func (oc *objectCache) processPopulate(info *types.Info, pkgPath string, call *ast.CallExpr, args *InjectorArgs, target int) (*ProviderSet, types.Type, []error) {
+	named := args.Tuple.At(target).Type().(*types.Pointer).Elem().(*types.Named)
+	st := named.Underlying().(*types.Struct)
+	rest := *call
+	rest.Args = call.Args[1:]
+	set, errs := oc.processNewSet(info, pkgPath, &rest, args, "")
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+	if len(set.Afters) > 0 {
+		return nil, nil, []error{notePosition(oc.fset.Position(set.Afters[0].Pos), errors.New("wire.After cannot be used with wire.Populate"))}
+	}
+	obj := named.Obj()
+	out := types.NewNamed(types.NewTypeName(obj.Pos(), obj.Pkg(), obj.Name(), nil), st, nil)
+	provider := &Provider{
+		Pkg:      obj.Pkg(),
+		Name:     obj.Name(),
+		Pos:      obj.Pos(),
+		IsStruct: true,
+		Out:      []types.Type{out},
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		if isPrevented(st.Tag(i)) {
+			continue
+		}
+		f := st.Field(i)
+		for _, arg := range provider.Args {
+			if types.Identical(arg.Type, f.Type()) {
+				return nil, nil, []error{notePosition(oc.fset.Position(f.Pos()), fmt.Errorf("struct populated by wire.Populate has multiple fields of type %s; tag all but one with `wire:\"-\"`", TypeString(f.Type())))}
+			}
+		}
+		provider.Args = append(provider.Args, ProviderInput{
+			Type:      f.Type(),
+			FieldName: f.Name(),
+		})
+	}
+	set.Providers = append(set.Providers, provider)
+	if errs := oc.rebuildProviderMap(set); len(errs) > 0 {
+		return nil, nil, errs
+	}
+	return set, out, nil
+}
+
+// populateSet resolves the target and provider set of fn, a function
+// template that calls wire.Populate.
+func (oc *objectCache) populateSet(pkg *packages.Package, fn *ast.FuncDecl, sig *types.Signature, call *ast.CallExpr) (int, *ProviderSet, types.Type, []error) {
+	fnPos := oc.fset.Position(fn.Pos())
+	target, err := populateTarget(pkg.TypesInfo, sig, call)
+	if err != nil {
+		return 0, nil, nil, []error{notePosition(fnPos, fmt.Errorf("inject %s: %w", fn.Name.Name, err))}
+	}
+	args := &InjectorArgs{
+		Name:  fn.Name.Name,
+		Tuple: sig.Params(),
+		Pos:   fn.Pos(),
+	}
+	set, out, errs := oc.processPopulate(pkg.TypesInfo, pkg.PkgPath, call, args, target)
+	if len(errs) > 0 {
+		return 0, nil, nil, notePositionAll(fnPos, errs)
+	}
+	return target, set, out, nil
+}
+
+// loadPopulate describes fn, a function template that calls wire.Populate,
+// as an Injector whose output is a stand-in for the populated struct type.
+func (oc *objectCache) loadPopulate(pkg *packages.Package, fn *ast.FuncDecl, sig *types.Signature, call *ast.CallExpr) (*Injector, []error) {
+	if _, err := populateOutput(sig); err != nil {
+		return nil, []error{notePosition(oc.fset.Position(fn.Pos()), withCode(CodeSignature, fmt.Errorf("inject %s: %v", fn.Name.Name, err)))}
+	}
+	_, set, out, errs := oc.populateSet(pkg, fn, sig, call)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	calls, errs := solve(oc.fset, out, sig.Params(), set)
+	if len(errs) > 0 {
+		return nil, mapErrors(errs, func(e error) error {
+			if w, ok := e.(*wireErr); ok {
+				return notePosition(w.position, fmt.Errorf("inject %s: %w", fn.Name.Name, w.error))
+			}
+			return notePosition(oc.fset.Position(fn.Pos()), fmt.Errorf("inject %s: %w", fn.Name.Name, e))
+		})
+	}
+	return &Injector{
+		ImportPath: pkg.PkgPath,
+		FuncName:   fn.Name.Name,
+		Pos:        fn.Pos(),
+		Params:     sig.Params(),
+		Out:        out,
+		Steps:      injectorSteps(calls),
+	}, nil
+}
+
+// generatePopulate generates fn, a function template that calls
+// wire.Populate.
+func (g *gen) generatePopulate(oc *objectCache, pkg *packages.Package, fn *ast.FuncDecl, sig *types.Signature, call *ast.CallExpr) []error {
+	target, set, out, errs := oc.populateSet(pkg, fn, sig, call)
+	if len(errs) > 0 {
+		return errs
+	}
+	return g.populate(fn.Pos(), fn.Name.Name, sig, target, set, out, fn.Doc)
+}
+
+// populateOutput validates the results of a function template that calls
+// wire.Populate. The returned signature has no output type.
+func populateOutput(sig *types.Signature) (outputSignature, error) {
+	results := sig.Results()
+	isCleanup := func(t types.Type) bool {
+		return types.Identical(t, cleanupType) || types.Identical(t, errCleanupType)
+	}
+	switch results.Len() {
+	case 0:
+		return outputSignature{}, nil
+	case 1:
+		switch t := results.At(0).Type(); {
+		case types.Identical(t, errorType):
+			return outputSignature{err: true}, nil
+		case isCleanup(t):
+			return outputSignature{cleanup: true, cleanupErr: types.Identical(t, errCleanupType)}, nil
+		default:
+			return outputSignature{}, fmt.Errorf("return type is %s; must be error, func(), or func() error", types.TypeString(t, nil))
+		}
+	case 2:
+		t := results.At(0).Type()
+		if !isCleanup(t) {
+			return outputSignature{}, fmt.Errorf("first return type is %s; must be func() or func() error", types.TypeString(t, nil))
+		}
+		if t := results.At(1).Type(); !types.Identical(t, errorType) {
+			return outputSignature{}, fmt.Errorf("second return type is %s; must be error", types.TypeString(t, nil))
+		}
+		return outputSignature{cleanup: true, cleanupErr: types.Identical(t, errCleanupType), err: true}, nil
+	default:
+		return outputSignature{}, errors.New("too many return values; a function that populates a struct may only return a cleanup function and an error")
+	}
+}
+
+// populate generates a function that fills in the fields of the struct its
+// parameter target points to.
+func (g *gen) populate(pos token.Pos, name string, sig *types.Signature, target int, set *ProviderSet, out types.Type, doc *ast.CommentGroup) []error {
+	popSig, err := populateOutput(sig)
+	if err != nil {
+		return []error{notePosition(g.pkg.Fset.Position(pos),
+			withCode(CodeSignature, fmt.Errorf("inject %s: %v", name, err)))}
+	}
+	calls, errs := solve(g.pkg.Fset, out, sig.Params(), set)
+	if len(errs) > 0 {
+		return mapErrors(errs, func(e error) error {
+			if w, ok := e.(*wireErr); ok {
+				return notePosition(w.position, fmt.Errorf("inject %s: %w", name, w.error))
+			}
+			return notePosition(g.pkg.Fset.Position(pos), fmt.Errorf("inject %s: %w", name, e))
+		})
+	}
+	pendingVars, errs := g.checkCalls(pos, name, calls, popSig)
+	if len(errs) > 0 {
+		return errs
+	}
+	// Perform one pass to collect all imports, followed by the real pass.
+	populatePass(name, sig, target, calls, popSig, doc, &injectorGen{
+		g:       g,
+		errVar:  disambiguate("err", g.nameInFileScope),
+		discard: true,
+	})
+	populatePass(name, sig, target, calls, popSig, doc, &injectorGen{
+		g:       g,
+		errVar:  disambiguate("err", g.nameInFileScope),
+		discard: false,
+	})
+	g.writePendingVars(pendingVars)
+	return nil
+}
+
+// populatePass generates a function that populates a struct given the
+// output from analysis. The last call is the stand-in struct provider, whose
+// arguments are assigned to the target's fields.
+func populatePass(name string, sig *types.Signature, target int, calls []call, popSig outputSignature, doc *ast.CommentGroup, ig *injectorGen) {
+	ig.funcHeader(name, sig, doc)
+	cleanupTypeString := "func()"
+	if popSig.cleanupErr {
+		cleanupTypeString = "func() error"
+	}
+	switch {
+	case popSig.cleanup && popSig.err:
+		ig.p(") (%s, error) {\n", cleanupTypeString)
+	case popSig.cleanup:
+		ig.p(") %s {\n", cleanupTypeString)
+	case popSig.err:
+		ig.p(") error {\n")
+	default:
+		ig.p(") {\n")
+	}
+	fill := calls[len(calls)-1]
+	ig.calls(calls[:len(calls)-1], popSig)
+	ig.discardUnused(calls, len(ig.paramNames)+len(calls)-1, nil)
+	for i, a := range fill.args {
+		ig.p("\t%s.%s = ", ig.paramNames[target], fill.fieldNames[i])
+		if a < len(ig.paramNames) {
+			ig.p("%s\n", ig.paramNames[a])
+		} else {
+			ig.p("%s\n", ig.localNames[a-len(ig.paramNames)])
+		}
+	}
+	switch {
+	case popSig.cleanup:
+		ig.p("\treturn ")
+		ig.cleanupFunc(popSig)
+		if popSig.err {
+			ig.p(", nil")
+		}
+		ig.p("\n")
+	case popSig.err:
+		ig.p("\treturn nil\n")
+	}
+	ig.p("}\n\n")
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// providerDocs reads the doc comments of providers from their source files,
+// parsing each file once. Loading does not keep the comments of packages
+// other than the ones being generated, so they are read from disk.
+type providerDocs struct {
+	fset  *token.FileSet
+	files map[string]*ast.File
+}
+
+func newProviderDocs(fset *token.FileSet) *providerDocs {
+	return &providerDocs{
+		fset:  fset,
+		files: make(map[string]*ast.File),
+	}
+}
+
+// doc returns the doc comment of p, or nil if it has none.
+func (d *providerDocs) doc(p *Provider) (*ast.CommentGroup, error) {
+	pos := d.fset.Position(p.Pos)
+	if !pos.IsValid() {
+		return nil, nil
+	}
+	f, ok := d.files[pos.Filename]
+	if !ok {
+		var err error
+		f, err = parser.ParseFile(d.fset, pos.Filename, nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		d.files[pos.Filename] = f
+	}
+	// The file is parsed again, so match the declaration by line and
+	// column rather than by token.Pos.
+	return declDoc(d.fset, f, pos), nil
+}
+
+// declDoc returns the doc comment of the function, type, or variable in f
+// whose name is at pos.
+func declDoc(fset *token.FileSet, f *ast.File, pos token.Position) *ast.CommentGroup {
+	at := func(id *ast.Ident) bool {
+		p := fset.Position(id.Pos())
+		return p.Line == pos.Line && p.Column == pos.Column
+	}
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if at(decl.Name) {
+				return decl.Doc
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				var doc *ast.CommentGroup
+				found := false
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					doc, found = spec.Doc, at(spec.Name)
+				case *ast.ValueSpec:
+					doc = spec.Doc
+					for _, name := range spec.Names {
+						found = found || at(name)
+					}
+				}
+				if !found {
+					continue
+				}
+				if doc == nil && len(decl.Specs) == 1 {
+					doc = decl.Doc
+				}
+				return doc
+			}
+		}
+	}
+	return nil
+}
+
+// deprecationNotice returns the text of the "Deprecated:" paragraph of doc,
+// following the Go convention for marking deprecated identifiers, and
+// whether there is one.
+func deprecationNotice(doc *ast.CommentGroup) (string, bool) {
+	for _, para := range strings.Split(doc.Text(), "\n\n") {
+		if rest := strings.TrimPrefix(para, "Deprecated:"); rest != para {
+			return strings.Join(strings.Fields(rest), " "), true
+		}
+	}
+	return "", false
+}
+
+// deprecationWarnings warns about the deprecated providers that in calls.
+// Providers whose source cannot be read are assumed not to be deprecated.
+func deprecationWarnings(docs *providerDocs, in *Injector) ErrorList {
+	var warnings ErrorList
+	seen := make(map[*Provider]bool)
+	for _, step := range in.Steps {
+		p := step.Provider
+		if p == nil || seen[p] {
+			continue
+		}
+		seen[p] = true
+		doc, err := docs.doc(p)
+		if err != nil || doc == nil {
+			continue
+		}
+		notice, ok := deprecationNotice(doc)
+		if !ok {
+			continue
+		}
+		msg := fmt.Sprintf("inject %s: provider %s.%s is deprecated", in.FuncName, p.Pkg.Path(), p.Name)
+		if notice != "" {
+			msg += ": " + notice
+		}
+		err = notePosition(docs.fset.Position(in.Pos), errors.New(msg))
+		warnings = append(warnings, newWarning(in.ImportPath, CodeDeprecated, err))
+	}
+	return warnings
+}
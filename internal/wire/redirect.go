@@ -0,0 +1,153 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// A redirect records a call to wire.Redirect, which makes uses of one
+// provider set stand for another.
+type redirect struct {
+	from *types.Var
+	to   *types.Var
+	// pos is the position of the call to wire.Redirect.
+	pos token.Pos
+}
+
+// packageRedirects returns the redirects declared at the top level of the
+// package pkgPath, keyed by the name of the provider set they redirect, along
+// with the errors in the calls to wire.Redirect that could not be used. The
+// keys are names rather than objects since the package may be type-checked
+// again by a later load.
+func (oc *objectCache) packageRedirects(pkgPath string) (map[string]*redirect, []error) {
+	if r, ok := oc.redirects[pkgPath]; ok {
+		return r.byFrom, r.errs
+	}
+	pkg, _ := oc.ensurePackage(pkgPath)
+	if pkg == nil || pkg.TypesInfo == nil {
+		// The errors loading the package are reported where it is used.
+		return nil, nil
+	}
+	byFrom := make(map[string]*redirect)
+	var declared []*redirect
+	ec := new(errorCollector)
+	for _, f := range pkg.Syntax {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				for _, v := range spec.(*ast.ValueSpec).Values {
+					call, ok := astutil.Unparen(v).(*ast.CallExpr)
+					if !ok {
+						continue
+					}
+					fn := qualifiedIdentObject(pkg.TypesInfo, call.Fun)
+					if fn == nil || fn.Pkg() == nil || !isWireImport(fn.Pkg().Path()) || fn.Name() != "Redirect" {
+						continue
+					}
+					r, err := processRedirect(oc.fset, pkg.TypesInfo, pkg.Types, call)
+					if err != nil {
+						ec.add(err)
+						continue
+					}
+					if prev := byFrom[r.from.Name()]; prev != nil {
+						ec.add(notePosition(oc.fset.Position(r.pos),
+							fmt.Errorf("provider set %s is already redirected at %v", r.from.Name(), oc.fset.Position(prev.pos))))
+						continue
+					}
+					byFrom[r.from.Name()] = r
+					declared = append(declared, r)
+				}
+			}
+		}
+	}
+	for _, r := range declared {
+		if r.to.Pkg() == r.from.Pkg() && byFrom[r.to.Name()] != nil {
+			ec.add(notePosition(oc.fset.Position(r.pos),
+				fmt.Errorf("cannot redirect %s to %s, which is redirected itself", r.from.Name(), r.to.Name())))
+			delete(byFrom, r.from.Name())
+		}
+	}
+	if oc.redirects == nil {
+		oc.redirects = make(map[string]packageRedirects)
+	}
+	oc.redirects[pkgPath] = packageRedirects{byFrom: byFrom, errs: ec.errors}
+	return byFrom, ec.errors
+}
+
+// packageRedirects is the memoized result of objectCache.packageRedirects.
+type packageRedirects struct {
+	byFrom map[string]*redirect
+	errs   []error
+}
+
+// redirectOf returns the redirect of obj, or nil if obj is not a provider
+// set redirected with wire.Redirect.
+func (oc *objectCache) redirectOf(obj types.Object) *redirect {
+	v, ok := obj.(*types.Var)
+	if !ok || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() || !isProviderSetType(v.Type()) {
+		return nil
+	}
+	byFrom, _ := oc.packageRedirects(v.Pkg().Path())
+	return byFrom[v.Name()]
+}
+
+// processRedirect checks a call to wire.Redirect in the package pkg.
+func processRedirect(fset *token.FileSet, info *types.Info, pkg *types.Package, call *ast.CallExpr) (*redirect, error) {
+	// Assumes that call.Fun is wire.Redirect.
+
+	pos := fset.Position(call.Pos())
+	if len(call.Args) != 2 {
+		return nil, notePosition(pos, errors.New("call to Redirect takes exactly two arguments"))
+	}
+	from, ok := providerSetVar(info, call.Args[0])
+	if !ok || from.Pkg() != pkg {
+		return nil, notePosition(pos, errors.New("first argument to Redirect must be a provider set variable declared in the same package"))
+	}
+	to, ok := providerSetVar(info, call.Args[1])
+	if !ok {
+		return nil, notePosition(pos, errors.New("second argument to Redirect must be a provider set variable"))
+	}
+	if from == to {
+		return nil, notePosition(pos, fmt.Errorf("cannot redirect %s to itself", from.Name()))
+	}
+	return &redirect{from: from, to: to, pos: call.Pos()}, nil
+}
+
+// providerSetVar returns the package-level provider set variable that expr
+// names.
+func providerSetVar(info *types.Info, expr ast.Expr) (*types.Var, bool) {
+	v, ok := qualifiedIdentObject(info, astutil.Unparen(expr)).(*types.Var)
+	if !ok || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() || !isProviderSetType(v.Type()) {
+		return nil, false
+	}
+	return v, true
+}
+
+// redirectWarning warns about a use of the provider set r redirects, at pos
+// in the package pkgPath.
+func redirectWarning(pkgPath string, pos token.Position, r *redirect) *Error {
+	err := fmt.Errorf("provider set %s.%s is redirected to %s.%s; use it instead", r.from.Pkg().Path(), r.from.Name(), r.to.Pkg().Path(), r.to.Name())
+	return newWarning(pkgPath, CodeDeprecated, notePosition(pos, err))
+}
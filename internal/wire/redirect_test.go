@@ -0,0 +1,127 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadRedirect(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "db", "db.go"), strings.Join([]string{
+		"package db",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"type Conn struct{}",
+		"",
+		"func Open() *Conn { return &Conn{} }",
+		"",
+		"func OpenLegacy() *Conn { return &Conn{} }",
+		"",
+		"var StorageSet = wire.NewSet(Open)",
+		"",
+		"var DBSet = wire.NewSet(OpenLegacy)",
+		"",
+		"var _ = wire.Redirect(DBSet, StorageSet)",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"import (",
+		"	\"example.com/app/db\"",
+		"	\"github.com/goforj/wire\"",
+		")",
+		"",
+		"type App struct{ conn *db.Conn }",
+		"",
+		"func NewApp(conn *db.Conn) *App { return &App{conn: conn} }",
+		"",
+		"var Set = wire.NewSet(NewApp, db.DBSet)",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package app",
+		"",
+		"import (",
+		"	\"example.com/app/db\"",
+		"	\"github.com/goforj/wire\"",
+		")",
+		"",
+		"func InitApp() *App {",
+		"	panic(wire.Build(NewApp, db.DBSet))",
+		"}",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "bad", "bad.go"), strings.Join([]string{
+		"package bad",
+		"",
+		"import (",
+		"	\"example.com/app/db\"",
+		"	\"github.com/goforj/wire\"",
+		")",
+		"",
+		"var Set = wire.NewSet(db.Open)",
+		"",
+		"var _ = wire.Redirect(db.StorageSet, Set)",
+		"",
+		"var _ = wire.Redirect(Set, Set)",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	info, errs := Load(context.Background(), root, env, "", []string{"./app", "./db"})
+	if len(errs) > 0 {
+		t.Fatalf("Load failed: %v", errs)
+	}
+	var got []string
+	for _, w := range info.Warnings {
+		got = append(got, fmt.Sprintf("%s %s %v", w.Pkg, w.Code, w))
+	}
+	want := []string{
+		"example.com/app/app deprecated " + filepath.Join(root, "app", "app.go") + ":12:31: provider set example.com/app/db.DBSet is redirected to example.com/app/db.StorageSet; use it instead",
+		"example.com/app/app deprecated " + filepath.Join(root, "app", "wire.go") + ":11:27: provider set example.com/app/db.DBSet is redirected to example.com/app/db.StorageSet; use it instead",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Warnings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	for _, in := range info.Injectors {
+		if p := in.Steps[0].Provider; p == nil || p.Name != "Open" {
+			t.Errorf("%v builds *db.Conn with %v; want db.Open", in, in.Steps[0])
+		}
+	}
+
+	_, errs = Load(context.Background(), root, env, "", []string{"./bad"})
+	got = nil
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	want = []string{
+		filepath.Join(root, "bad", "bad.go") + ":10:9: first argument to Redirect must be a provider set variable declared in the same package",
+		filepath.Join(root, "bad", "bad.go") + ":12:9: cannot redirect Set to itself",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Load errors:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"time"
+)
+
+// ServeOptions holds the options for Serve.
+type ServeOptions struct {
+	// Generate configures each generation. If nil, the defaults of wire
+	// gen are used. Its OnResult is called as for Generate.
+	Generate *GenerateOptions
+	// PollInterval is how often Serve checks the files for changes when
+	// filesystem notifications are unavailable, as for Watch. If zero,
+	// the default of wire watch is used.
+	PollInterval time.Duration
+
+	// The hooks below are called on the goroutine running Serve, one at a
+	// time.
+
+	// OnGenerateStart, if not nil, is called with the patterns before
+	// each generation.
+	OnGenerateStart func(patterns []string)
+	// OnGenerateEnd, if not nil, is called with the result of each
+	// generation once its files are written.
+	OnGenerateEnd func(ServeResult)
+	// OnError, if not nil, is called with each error as it occurs: the
+	// errors that keep the packages from loading, those of each package
+	// that failed to generate, and those writing the generated files.
+	// The errors of a generation are passed to OnError before
+	// OnGenerateEnd is called.
+	OnError func(error)
+}
+
+// ServeResult describes a generation run by Serve.
+type ServeResult struct {
+	// Results holds the result of each package, as Generate returns it.
+	// The files of the packages that generated without errors have been
+	// written.
+	Results []GenerateResult
+	// Errs lists the errors that kept the packages from loading.
+	Errs ErrorList
+	// Initial reports whether this is the generation Serve runs when it
+	// starts, before any file changed.
+	Initial bool
+	// Duration is how long the generation took, including writing files.
+	Duration time.Duration
+}
+
+// OK reports whether every package generated, and was written, without
+// errors.
+func (r ServeResult) OK() bool {
+	return len(r.Errs) == 0 && allGeneratedOK(r.Results)
+}
+
+// Serve generates the packages matching patterns, relative to the
+// directory wd and in the environment env, writes their generated files,
+// and generates them again whenever a Go file or a go.mod, go.sum, or
+// go.work file changes in the module holding wd, as wire watch does: the
+// files are watched with Watch, and in a go.work workspace each module
+// holding matched packages is watched on its own, so that a change
+// regenerates only that module's packages. It reports each generation to
+// the hooks of opts, so that programs embedding Wire, such as development
+// servers and editor daemons, can show its status in their own UI. A nil
+// opts uses the defaults.
+//
+// Serve returns when ctx is done, with ctx.Err().
+func Serve(ctx context.Context, wd string, env []string, patterns []string, opts *ServeOptions) error {
+	if opts == nil {
+		opts = &ServeOptions{}
+	}
+	var tags string
+	if opts.Generate != nil {
+		tags = opts.Generate.Tags
+	}
+	roots, byModule, err := WatchRoots(ctx, wd, env, tags, patterns)
+	if err != nil {
+		debugf(ctx, "serve: failed to find the modules to watch, watching %s: %v", roots[0], err)
+	}
+	opts.generate(ctx, wd, env, patterns, true)
+
+	// The watchers pass the root of each change to this goroutine, which
+	// runs the generations, so that the hooks are called one at a time.
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	changes := make(chan string)
+	watchOpts := &WatchOptions{PollInterval: opts.PollInterval}
+	for _, root := range roots {
+		go func(root string) {
+			Watch(watchCtx, root, watchOpts, func([]string) {
+				select {
+				case changes <- root:
+				case <-watchCtx.Done():
+				}
+			})
+		}(root)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case root := <-changes:
+			debugf(ctx, "serve: files changed under %s, generating again", root)
+			pkgs := patterns
+			if byModule != nil {
+				grouped, err := PackagesByModule(ctx, wd, env, tags, patterns)
+				if err != nil {
+					opts.reportError(err)
+					continue
+				}
+				if pkgs = grouped[root]; len(pkgs) == 0 {
+					continue
+				}
+			}
+			opts.generate(ctx, wd, env, pkgs, false)
+		}
+	}
+}
+
+// generate runs one generation for Serve, writes its files, and reports it
+// to the hooks. Patterns naming a directory that was removed, or left with
+// only generated files, are skipped, and nothing is run if none is left.
+func (opts *ServeOptions) generate(ctx context.Context, wd string, env []string, patterns []string, initial bool) {
+	start := time.Now()
+	var kept []string
+	for _, p := range patterns {
+		if PackageRemoved(wd, p) {
+			debugf(ctx, "serve: %s was removed, skipping it", p)
+			continue
+		}
+		kept = append(kept, p)
+	}
+	if len(kept) == 0 {
+		return
+	}
+	if opts.OnGenerateStart != nil {
+		opts.OnGenerateStart(kept)
+	}
+	res := ServeResult{Initial: initial}
+	res.Results, res.Errs = Generate(ctx, wd, env, kept, opts.Generate)
+	for _, err := range res.Errs {
+		opts.reportError(err)
+	}
+	for i := range res.Results {
+		out := &res.Results[i]
+		for _, err := range out.Errs {
+			opts.reportError(err)
+		}
+		if len(out.Errs) > 0 {
+			continue
+		}
+		if err := out.Commit(); err != nil {
+			out.Errs.add(out.PkgPath, err)
+			opts.reportError(out.Errs[len(out.Errs)-1])
+		}
+	}
+	res.Duration = time.Since(start)
+	if opts.OnGenerateEnd != nil {
+		opts.OnGenerateEnd(res)
+	}
+}
+
+// reportError passes err to the OnError hook, if any.
+func (opts *ServeOptions) reportError(err error) {
+	if opts.OnError != nil {
+		opts.OnError(err)
+	}
+}
@@ -0,0 +1,392 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// A SetManifest lists provider sets for GenSet to declare, for programs
+// whose providers are registered in service metadata files instead of
+// hand-edited Go. It is read from a JSON or YAML file:
+//
+//	package: providers
+//	sets:
+//	  - name: ServiceSet
+//	    doc: ServiceSet provides the services of the API server.
+//	    providers:
+//	      - example.com/app/users.NewService
+//	      - example.com/app/orders.NewService
+//	      - example.com/app/db.ProviderSet
+//	  - name: AppSet
+//	    providers: [ServiceSet, example.com/app/server.New]
+type SetManifest struct {
+	// Package is the name of the package the sets are declared in. It
+	// is only needed if that package has no Go files yet.
+	Package string `json:"package"`
+	// Sets are declared in order.
+	Sets []ManifestSet `json:"sets"`
+}
+
+// A ManifestSet is a provider set of a SetManifest.
+type ManifestSet struct {
+	Name string `json:"name"`
+	// Doc is the doc comment of the set, without comment markers.
+	Doc string `json:"doc"`
+	// Providers are the arguments of the set's wire.NewSet call, each the
+	// name of a provider or provider set qualified by its import path, as
+	// in example.com/app/users.NewService, or the bare name of another
+	// set in the manifest.
+	Providers []string `json:"providers"`
+}
+
+// ReadSetManifest reads and validates the SetManifest in the file at path,
+// which is YAML if its name ends in .yaml or .yml and JSON otherwise. Only
+// the block style of YAML is understood, with plain or quoted scalars, flow
+// sequences of scalars, and comments.
+func ReadSetManifest(path string) (*SetManifest, error) {
+	data, err := osReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading set manifest: %v", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		v, err := parseYAML(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("parsing set manifest %s: %v", path, err)
+		}
+		if data, err = json.Marshal(v); err != nil {
+			return nil, fmt.Errorf("parsing set manifest %s: %v", path, err)
+		}
+	}
+	m := new(SetManifest)
+	if err := jsonUnmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("parsing set manifest %s: %v", path, err)
+	}
+	if err := m.validate(); err != nil {
+		return nil, fmt.Errorf("set manifest %s: %v", path, err)
+	}
+	return m, nil
+}
+
+// validate checks that the sets have unique identifiers for names and that
+// their providers are well-formed references.
+func (m *SetManifest) validate() error {
+	if m.Package != "" && !token.IsIdentifier(m.Package) {
+		return fmt.Errorf("package name %q is not a valid identifier", m.Package)
+	}
+	if len(m.Sets) == 0 {
+		return fmt.Errorf("no sets declared")
+	}
+	names := make(map[string]bool)
+	for _, set := range m.Sets {
+		if !token.IsIdentifier(set.Name) || names[set.Name] {
+			return fmt.Errorf("set names must be unique identifiers, got %q", set.Name)
+		}
+		names[set.Name] = true
+	}
+	for _, set := range m.Sets {
+		if len(set.Providers) == 0 {
+			return fmt.Errorf("set %s lists no providers", set.Name)
+		}
+		for _, p := range set.Providers {
+			path, name := splitProviderRef(p)
+			switch {
+			case !token.IsIdentifier(name):
+				return fmt.Errorf("set %s: provider %q must be of the form import/path.Name", set.Name, p)
+			case path == "" && !names[name]:
+				return fmt.Errorf("set %s: %s is not a set in the manifest; qualify it with its import path", set.Name, p)
+			case path == "" && name == set.Name:
+				return fmt.Errorf("set %s includes itself", set.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// splitProviderRef splits a reference such as example.com/app/users.New
+// into its import path and name. The path of a bare name is empty.
+func splitProviderRef(ref string) (path, name string) {
+	i := strings.LastIndex(ref, ".")
+	if i < 0 || strings.Contains(ref[i:], "/") {
+		return "", ref
+	}
+	return ref[:i], ref[i+1:]
+}
+
+// yamlLine is a line of a YAML document without its indentation and
+// comment.
+type yamlLine struct {
+	num    int
+	indent int
+	text   string
+}
+
+// parseYAML decodes the block-style subset of YAML that manifests use into
+// maps, slices, and strings.
+func parseYAML(src string) (interface{}, error) {
+	var lines []yamlLine
+	for i, line := range strings.Split(src, "\n") {
+		line = strings.TrimRight(stripYAMLComment(line), " \t\r")
+		text := strings.TrimLeft(line, " ")
+		if text == "" || text == "---" {
+			continue
+		}
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed in indentation", i+1)
+		}
+		lines = append(lines, yamlLine{num: i + 1, indent: len(line) - len(text), text: text})
+	}
+	if len(lines) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	v, next, err := parseYAMLNested(lines, 0)
+	if err != nil {
+		return nil, err
+	}
+	if next < len(lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", lines[next].num)
+	}
+	return v, nil
+}
+
+// parseYAMLBlock decodes the mapping or sequence whose entries start at
+// lines[i] with the given indentation, returning the index of the line
+// after it.
+func parseYAMLBlock(lines []yamlLine, i, indent int) (interface{}, int, error) {
+	if isYAMLSeqItem(lines[i].text) {
+		var seq []interface{}
+		for i < len(lines) && lines[i].indent == indent && isYAMLSeqItem(lines[i].text) {
+			rest := strings.TrimLeft(lines[i].text[1:], " ")
+			var v interface{}
+			var err error
+			switch {
+			case rest == "":
+				v, i, err = parseYAMLValue(lines, i+1, indent)
+			case yamlKey(rest) != "":
+				// A mapping that starts on the line of its item.
+				lines[i] = yamlLine{num: lines[i].num, indent: indent + len(lines[i].text) - len(rest), text: rest}
+				v, i, err = parseYAMLBlock(lines, i, lines[i].indent)
+			default:
+				v, err = parseYAMLScalar(lines[i].num, rest)
+				i++
+			}
+			if err != nil {
+				return nil, 0, err
+			}
+			seq = append(seq, v)
+		}
+		return seq, i, nil
+	}
+	m := make(map[string]interface{})
+	for i < len(lines) && lines[i].indent == indent {
+		line := lines[i]
+		key := yamlKey(line.text)
+		if key == "" {
+			return nil, 0, fmt.Errorf("line %d: expected a key followed by a colon", line.num)
+		}
+		rest := strings.TrimSpace(line.text[len(key)+1:])
+		key, err := unquoteYAML(key)
+		if err != nil {
+			return nil, 0, fmt.Errorf("line %d: %v", line.num, err)
+		}
+		if _, dup := m[key]; dup {
+			return nil, 0, fmt.Errorf("line %d: duplicate key %q", line.num, key)
+		}
+		var v interface{}
+		if rest == "" {
+			// Sequences may be indented as much as their key.
+			if i+1 < len(lines) && lines[i+1].indent == indent && isYAMLSeqItem(lines[i+1].text) {
+				v, i, err = parseYAMLBlock(lines, i+1, indent)
+			} else {
+				v, i, err = parseYAMLValue(lines, i+1, indent)
+			}
+		} else {
+			v, err = parseYAMLScalar(line.num, rest)
+			i++
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		m[key] = v
+	}
+	return m, i, nil
+}
+
+// parseYAMLValue decodes the block nested under a line with the given
+// indentation, or an empty value if the next line is not nested.
+func parseYAMLValue(lines []yamlLine, i, parent int) (interface{}, int, error) {
+	if i >= len(lines) || lines[i].indent <= parent {
+		return nil, i, nil
+	}
+	return parseYAMLNested(lines, i)
+}
+
+// parseYAMLNested decodes the block starting at lines[i] that makes up a
+// whole value, so that it must end at a line with less indentation.
+func parseYAMLNested(lines []yamlLine, i int) (interface{}, int, error) {
+	indent := lines[i].indent
+	v, i, err := parseYAMLBlock(lines, i, indent)
+	if err != nil {
+		return nil, 0, err
+	}
+	// Only sequences stop at lines with their own indentation.
+	if i < len(lines) && lines[i].indent == indent {
+		return nil, 0, fmt.Errorf("line %d: expected a sequence item starting with \"- \"", lines[i].num)
+	}
+	return v, i, nil
+}
+
+// parseYAMLScalar decodes a quoted or plain scalar, or a flow sequence of
+// them, on the line numbered num.
+func parseYAMLScalar(num int, s string) (interface{}, error) {
+	if !strings.HasPrefix(s, "[") {
+		v, err := unquoteYAML(s)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", num, err)
+		}
+		return v, nil
+	}
+	if !strings.HasSuffix(s, "]") {
+		return nil, fmt.Errorf("line %d: unterminated flow sequence %s", num, s)
+	}
+	seq := []interface{}{}
+	for _, item := range splitYAMLFlow(s[1 : len(s)-1]) {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		v, err := unquoteYAML(item)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", num, err)
+		}
+		seq = append(seq, v)
+	}
+	return seq, nil
+}
+
+// splitYAMLFlow splits the items of a flow sequence at the commas outside
+// of quoted scalars.
+func splitYAMLFlow(s string) []string {
+	var items []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote == '\'' && c == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && strings.TrimSpace(s[start:i]) == "":
+			quote = c
+		case c == ',':
+			items = append(items, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, s[start:])
+}
+
+// unquoteYAML returns the value of a plain, single-quoted, or double-quoted
+// scalar.
+func unquoteYAML(s string) (string, error) {
+	switch {
+	case s[0] == '"':
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return "", fmt.Errorf("malformed double-quoted scalar %s", s)
+		}
+		return v, nil
+	case s[0] == '\'':
+		v := s[1:]
+		for i := 0; i < len(v); i++ {
+			if v[i] != '\'' {
+				continue
+			}
+			if i+1 < len(v) && v[i+1] == '\'' {
+				i++
+				continue
+			}
+			if i+1 < len(v) {
+				return "", fmt.Errorf("unexpected text after single-quoted scalar %s", s[:i+2])
+			}
+			return strings.ReplaceAll(v[:i], "''", "'"), nil
+		}
+		return "", fmt.Errorf("unterminated single-quoted scalar %s", s)
+	case strings.ContainsAny(s[:1], "{&*|>!%@`"):
+		return "", fmt.Errorf("unsupported YAML syntax %s", s)
+	case strings.HasSuffix(s, ":") || strings.Contains(s, ": "):
+		return "", fmt.Errorf("unexpected colon in plain scalar %s; quote it", s)
+	}
+	return s, nil
+}
+
+// isYAMLSeqItem reports whether text is an item of a block sequence.
+func isYAMLSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// yamlKey returns the key of a mapping entry, or "" if text is not one.
+func yamlKey(text string) string {
+	if text[0] == '"' || text[0] == '\'' {
+		if end := strings.IndexByte(text[1:], text[0]); end >= 0 {
+			key := text[:end+2]
+			if rest := text[len(key):]; rest == ":" || strings.HasPrefix(rest, ": ") {
+				return key
+			}
+		}
+		return ""
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ') {
+			return text[:i]
+		}
+	}
+	return ""
+}
+
+// stripYAMLComment removes a comment from line: a '#' at its start or after
+// a space, outside of quoted scalars. Quotes only open a scalar at its
+// start, so apostrophes in plain scalars are not mistaken for them.
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote == '\'' && c == '\'' && i+1 < len(line) && line[i+1] == '\'':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" \t[,", line[i-1]) >= 0):
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
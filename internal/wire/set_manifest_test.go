@@ -0,0 +1,101 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	type m = map[string]interface{}
+	type s = []interface{}
+	tests := []struct {
+		name string
+		src  string
+		want interface{}
+	}{
+		{name: "Empty", src: "# nothing here\n---\n", want: m{}},
+		{name: "Mapping", src: "a: b\nc: d\n", want: m{"a": "b", "c": "d"}},
+		{name: "Nested", src: "a:\n  b:\n    c: d\n  e: f\n", want: m{"a": m{"b": m{"c": "d"}, "e": "f"}}},
+		{name: "EmptyValue", src: "a:\nb: c\n", want: m{"a": nil, "b": "c"}},
+		{name: "Sequence", src: "- a\n- b\n", want: s{"a", "b"}},
+		{name: "SequenceAtKeyIndent", src: "a:\n- b\n- c\nd: e\n", want: m{"a": s{"b", "c"}, "d": "e"}},
+		{name: "MappingInItem", src: "- a: b\n  c: d\n-\n  e: f\n", want: s{m{"a": "b", "c": "d"}, m{"e": "f"}}},
+		{name: "FlowSequence", src: "a: [b, 'c, d', \"e]\", 'f'', g', \"h\\\", i\"]\nj: []\n", want: m{"a": s{"b", "c, d", "e]", "f', g", "h\", i"}, "j": s{}}},
+		{name: "DoubleQuoted", src: `a: "b: \"c\"\td # e"`, want: m{"a": "b: \"c\"\td # e"}},
+		{name: "SingleQuoted", src: "a: 'it''s # not a comment'\n", want: m{"a": "it's # not a comment"}},
+		{name: "QuotedKey", src: "\"a: b\": c\n'd': e\n", want: m{"a: b": "c", "d": "e"}},
+		{name: "Comments", src: "# head\na: b # tail\n  # indented\nc: d#e\ne: it's # tail\n", want: m{"a": "b", "c": "d#e", "e": "it's"}},
+		{name: "PlainColon", src: "a: http://example.com\n", want: m{"a": "http://example.com"}},
+		{name: "DocumentIndented", src: "  a: b\n  c: d\n", want: m{"a": "b", "c": "d"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseYAML(test.src)
+			if err != nil {
+				t.Fatalf("parseYAML(%q): %v", test.src, err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseYAML(%q) = %#v; want %#v", test.src, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{name: "DeeperKey", src: "a: b\n  c: d\n", want: "line 2: unexpected indentation"},
+		{name: "DeeperItemKey", src: "- a: b\n   c: d\n", want: "line 2: unexpected indentation"},
+		{name: "PartialDedent", src: "a:\n    b: c\n  d: e\n", want: "line 3: unexpected indentation"},
+		{name: "ShallowerDocument", src: "  a: b\nc: d\n", want: "line 2: unexpected indentation"},
+		{name: "TabIndent", src: "a:\n\tb: c\n", want: "line 2: tabs are not allowed in indentation"},
+		{name: "TabAfterSpaces", src: "a:\n  \tb: c\n", want: "line 2: tabs are not allowed in indentation"},
+		{name: "NotAKey", src: "a: b\nc\n", want: "line 2: expected a key followed by a colon"},
+		{name: "ItemInMapping", src: "a: b\n- c\n", want: "line 2: expected a key followed by a colon"},
+		{name: "KeyInSequence", src: "- a\nb: c\n", want: `line 2: expected a sequence item starting with "- "`},
+		{name: "DuplicateKey", src: "a: b\nc: d\na: e\n", want: `line 3: duplicate key "a"`},
+		{name: "DuplicateQuotedKey", src: "a: b\n\"a\": c\n", want: `line 2: duplicate key "a"`},
+		{name: "DuplicateNestedKey", src: "a:\n  b: c\n  b: d\n", want: `line 3: duplicate key "b"`},
+		{name: "UnterminatedDouble", src: "a: \"b\n", want: "line 1: malformed double-quoted scalar \"b"},
+		{name: "TextAfterDouble", src: "a: \"b\" c\n", want: "line 1: malformed double-quoted scalar \"b\" c"},
+		{name: "BadEscape", src: `a: "\q"`, want: `line 1: malformed double-quoted scalar "\q"`},
+		{name: "UnterminatedSingle", src: "a: 'b''\n", want: "line 1: unterminated single-quoted scalar 'b''"},
+		{name: "TextAfterSingle", src: "a: 'b' c\n", want: "line 1: unexpected text after single-quoted scalar 'b'"},
+		{name: "UnterminatedQuoteInFlow", src: "a: [b, 'c]\n", want: "line 1: unterminated single-quoted scalar 'c"},
+		{name: "UnterminatedFlow", src: "a: [b, c\n", want: "line 1: unterminated flow sequence [b, c"},
+		{name: "ColonInPlain", src: "a: b: c\n", want: "line 1: unexpected colon in plain scalar b: c; quote it"},
+		{name: "FlowMapping", src: "a: {b: c}\n", want: "line 1: unsupported YAML syntax {b: c}"},
+		{name: "Anchor", src: "a: &x b\n", want: "line 1: unsupported YAML syntax &x b"},
+		{name: "Alias", src: "- *x\n", want: "line 1: unsupported YAML syntax *x"},
+		{name: "Literal", src: "a: |\n  b\n", want: "line 1: unsupported YAML syntax |"},
+		{name: "Tag", src: "a: !!str b\n", want: "line 1: unsupported YAML syntax !!str b"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseYAML(test.src)
+			if err == nil {
+				t.Fatalf("parseYAML(%q) = %#v; want error %q", test.src, got, test.want)
+			}
+			if err.Error() != test.want {
+				t.Errorf("parseYAML(%q) error = %q; want %q", test.src, err, test.want)
+			}
+		})
+	}
+}
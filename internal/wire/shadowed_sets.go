@@ -0,0 +1,136 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+)
+
+// shadowedSetWarnings returns a warning for each provider set of pkg passed
+// by its bare name to wire.Build, wire.MustBuild, or wire.NewSet in a file
+// that imports another package declaring a provider set of the same name,
+// such as ProviderSet in a file importing a repo package with its own
+// repo.ProviderSet. The bare name always means the set of pkg, which is
+// easy to miss when the other set was meant. Uses are not reported if the
+// file also refers to the other set, or if the set of pkg already includes
+// it.
+func shadowedSetWarnings(oc *objectCache, pkg *packages.Package) ErrorList {
+	var warnings ErrorList
+	scope := pkg.Types.Scope()
+	for _, f := range pkg.Syntax {
+		imported := fileImportedPackages(pkg.TypesInfo, f)
+		if len(imported) == 0 {
+			continue
+		}
+		referenced := make(map[types.Object]bool)
+		for id, obj := range pkg.TypesInfo.Uses {
+			if id.Pos() >= f.Pos() && id.Pos() < f.End() {
+				referenced[obj] = true
+			}
+		}
+		ast.Inspect(f, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok || !isSetCall(pkg.TypesInfo, call) {
+				return true
+			}
+			for _, arg := range call.Args {
+				id, ok := astutil.Unparen(arg).(*ast.Ident)
+				if !ok {
+					continue
+				}
+				local, ok := pkg.TypesInfo.Uses[id].(*types.Var)
+				if !ok || local.Parent() != scope || !isProviderSetType(local.Type()) {
+					continue
+				}
+				for _, other := range imported {
+					obj, ok := other.Scope().Lookup(id.Name).(*types.Var)
+					if !ok || !obj.Exported() || !isProviderSetType(obj.Type()) || referenced[obj] || setIncludes(oc, local, obj) {
+						continue
+					}
+					err := notePosition(oc.fset.Position(id.Pos()), fmt.Errorf(
+						"%s refers to the provider set of this package, declared at %v, not to %s.%s, declared at %v, which this file imports; qualify the name if that set is meant",
+						id.Name, oc.fset.Position(local.Pos()), other.Name(), obj.Name(), oc.fset.Position(obj.Pos())))
+					warnings = append(warnings, newWarning(pkg.PkgPath, CodeShadowedSet, err))
+				}
+			}
+			return true
+		})
+	}
+	return warnings
+}
+
+// fileImportedPackages returns the packages f imports, other than Wire.
+func fileImportedPackages(info *types.Info, f *ast.File) []*types.Package {
+	var pkgs []*types.Package
+	for _, spec := range f.Imports {
+		var obj types.Object
+		if spec.Name != nil {
+			obj = info.Defs[spec.Name]
+		} else {
+			obj = info.Implicits[spec]
+		}
+		pkgName, ok := obj.(*types.PkgName)
+		if !ok || isWireImport(pkgName.Imported().Path()) {
+			continue
+		}
+		pkgs = append(pkgs, pkgName.Imported())
+	}
+	return pkgs
+}
+
+// isSetCall reports whether call is a call to wire.Build, wire.MustBuild,
+// or wire.NewSet.
+func isSetCall(info *types.Info, call *ast.CallExpr) bool {
+	fn, ok := qualifiedIdentObject(info, call.Fun).(*types.Func)
+	if !ok || fn.Pkg() == nil || !isWireImport(fn.Pkg().Path()) {
+		return false
+	}
+	switch fn.Name() {
+	case "Build", "MustBuild", "NewSet":
+		return true
+	}
+	return false
+}
+
+// setIncludes reports whether the provider set declared by set includes the
+// one declared by other, directly or through the sets it includes.
+func setIncludes(oc *objectCache, set, other *types.Var) bool {
+	item, errs := oc.get(set)
+	pset, ok := item.(*ProviderSet)
+	if len(errs) > 0 || !ok {
+		// Errors are reported for the set itself.
+		return true
+	}
+	seen := make(map[*ProviderSet]bool)
+	var includes func(*ProviderSet) bool
+	includes = func(s *ProviderSet) bool {
+		if seen[s] {
+			return false
+		}
+		seen[s] = true
+		for _, imp := range s.Imports {
+			if imp.PkgPath == other.Pkg().Path() && imp.VarName == other.Name() || includes(imp) {
+				return true
+			}
+		}
+		return false
+	}
+	return includes(pset)
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShadowedSetWarnings(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "repo", "repo.go"), strings.Join([]string{
+		"package repo",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"type DB struct{}",
+		"",
+		"func NewDB() *DB { return &DB{} }",
+		"",
+		"var ProviderSet = wire.NewSet(NewDB)",
+		"",
+	}, "\n"))
+	// shadow's ProviderSet does not include repo.ProviderSet, which its
+	// wireinject file imports, so its injector takes the DB; included's
+	// does, so its injector builds the DB from the set.
+	for _, test := range []struct{ name, set, injector string }{
+		{"shadow", "wire.NewSet(NewApp)", "func InitApp(db *repo.DB) *App {"},
+		{"included", "wire.NewSet(NewApp, repo.ProviderSet)", "func InitDB() *repo.DB {"},
+	} {
+		writeFile(t, filepath.Join(root, test.name, "app.go"), strings.Join([]string{
+			"package " + test.name,
+			"",
+			"import (",
+			"\t\"example.com/app/repo\"",
+			"\t\"github.com/goforj/wire\"",
+			")",
+			"",
+			"type App struct{ DB *repo.DB }",
+			"",
+			"func NewApp(db *repo.DB) *App { return &App{DB: db} }",
+			"",
+			"var ProviderSet = " + test.set,
+			"",
+		}, "\n"))
+		writeFile(t, filepath.Join(root, test.name, "wire.go"), strings.Join([]string{
+			"//go:build wireinject",
+			"",
+			"package " + test.name,
+			"",
+			"import (",
+			"\t\"example.com/app/repo\"",
+			"\t\"github.com/goforj/wire\"",
+			")",
+			"",
+			test.injector,
+			"\tpanic(wire.Build(ProviderSet))",
+			"}",
+			"",
+		}, "\n"))
+	}
+
+	env := append(os.Environ(), "GOWORK=off")
+	info, errs := Load(context.Background(), root, env, "", []string{"./shadow", "./included"})
+	if len(errs) > 0 {
+		t.Fatalf("Load failed: %v", errs)
+	}
+	if len(info.Warnings) != 1 {
+		t.Fatalf("Load warnings = %v; want one", info.Warnings)
+	}
+	w := info.Warnings[0]
+	if w.Code != CodeShadowedSet || w.Pkg != "example.com/app/shadow" {
+		t.Errorf("Load warning = %s in %s; want %s in example.com/app/shadow", w.Code, w.Pkg, CodeShadowedSet)
+	}
+	if got := filepath.Base(w.Pos.Filename); got != "wire.go" || w.Pos.Line != 11 {
+		t.Errorf("Load warning is at %s:%d; want wire.go:11", got, w.Pos.Line)
+	}
+	for _, want := range []string{"not to repo.ProviderSet", "shadow" + string(filepath.Separator) + "app.go:12", "repo" + string(filepath.Separator) + "repo.go:9"} {
+		if !strings.Contains(w.Error(), want) {
+			t.Errorf("Load warning %q does not mention %q", w.Error(), want)
+		}
+	}
+}
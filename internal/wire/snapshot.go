@@ -0,0 +1,220 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// snapshotFile is the name of the file describing a debug snapshot.
+const snapshotFile = "snapshot.json"
+
+// snapshotVersion identifies the format of snapshotFile.
+const snapshotVersion = 1
+
+// A snapshot describes a generation run saved by GenerateOptions.DebugSnapshot.
+// The source files of every package outside GOROOT are copied under src/,
+// laid out by import path, so that the run can be replayed in GOPATH mode
+// without the original module, its dependencies, or network access.
+type snapshot struct {
+	Version int `json:"version"`
+	// Patterns are the import paths of the packages that the original
+	// patterns matched.
+	Patterns []string `json:"patterns"`
+	// GOOS, GOARCH, and CGOEnabled select the same files as the original
+	// run.
+	GOOS       string `json:"goos"`
+	GOARCH     string `json:"goarch"`
+	CGOEnabled string `json:"cgo_enabled"`
+
+	Tags             string   `json:"tags,omitempty"`
+	PrefixOutputFile string   `json:"prefix_output_file,omitempty"`
+	OutputFileName   string   `json:"output_file_name,omitempty"`
+	Header           string   `json:"header,omitempty"`
+	AutoContext      bool     `json:"auto_context,omitempty"`
+	StableOrder      bool     `json:"stable_order,omitempty"`
+	AutoNames        string   `json:"auto_names,omitempty"`
+	Injectors        []string `json:"injectors,omitempty"`
+	Partial          bool     `json:"partial,omitempty"`
+	Examples         bool     `json:"examples,omitempty"`
+
+	Packages []snapshotPackage `json:"packages"`
+	// Errors are the errors the original run reported.
+	Errors []string `json:"errors"`
+}
+
+// A snapshotPackage summarizes a package saved in a snapshot.
+type snapshotPackage struct {
+	Path    string   `json:"path"`
+	Name    string   `json:"name"`
+	Imports []string `json:"imports,omitempty"`
+	// Files are the package's files, relative to its directory.
+	Files []string `json:"files"`
+}
+
+// writeSnapshot saves the packages loaded for a generation run, along with
+// its options and results, to dir.
+func writeSnapshot(ctx context.Context, dir string, wd string, env []string, pkgs []*packages.Package, opts *GenerateOptions, results []GenerateResult) error {
+	goenv, err := goEnvValues(ctx, wd, env, "GOROOT", "GOOS", "GOARCH", "CGO_ENABLED")
+	if err != nil {
+		return err
+	}
+	snap := &snapshot{
+		Version:          snapshotVersion,
+		GOOS:             goenv["GOOS"],
+		GOARCH:           goenv["GOARCH"],
+		CGOEnabled:       goenv["CGO_ENABLED"],
+		Tags:             opts.Tags,
+		PrefixOutputFile: opts.PrefixOutputFile,
+		OutputFileName:   opts.OutputFileName,
+		Header:           string(opts.Header),
+		AutoContext:      opts.AutoContext,
+		StableOrder:      opts.StableOrder,
+		AutoNames:        opts.AutoNames,
+		Injectors:        opts.Injectors,
+		Partial:          opts.Partial,
+		Examples:         opts.Examples,
+	}
+	for _, pkg := range pkgs {
+		snap.Patterns = append(snap.Patterns, pkg.PkgPath)
+	}
+	for _, res := range results {
+		for _, err := range res.Errs {
+			snap.Errors = append(snap.Errors, err.Error())
+		}
+	}
+	goroot := []string{realPath(goenv["GOROOT"])}
+	all := collectAllPackages(pkgs)
+	paths := make([]string, 0, len(all))
+	for path := range all {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		pkg := all[path]
+		var files []string
+		for _, list := range [][]string{pkg.GoFiles, pkg.OtherFiles, pkg.IgnoredFiles, pkg.EmbedFiles} {
+			files = append(files, list...)
+		}
+		if len(files) == 0 {
+			continue
+		}
+		pkgDir := filepath.Dir(files[0])
+		if withinAny(realPath(pkgDir), goroot) {
+			continue
+		}
+		sp := snapshotPackage{Path: pkg.PkgPath, Name: pkg.Name}
+		for imp := range pkg.Imports {
+			sp.Imports = append(sp.Imports, imp)
+		}
+		sort.Strings(sp.Imports)
+		for _, f := range files {
+			rel, err := filepath.Rel(pkgDir, f)
+			if err != nil {
+				return err
+			}
+			if err := copySnapshotFile(f, filepath.Join(dir, "src", filepath.FromSlash(pkg.PkgPath), rel)); err != nil {
+				return err
+			}
+			sp.Files = append(sp.Files, filepath.ToSlash(rel))
+		}
+		snap.Packages = append(snap.Packages, sp)
+	}
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, snapshotFile), data, 0666)
+}
+
+// copySnapshotFile copies the file src to dst, creating dst's directory.
+func copySnapshotFile(src, dst string) error {
+	data, err := ioutil.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0777); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(dst, data, 0666)
+}
+
+// Replay repeats the generation run saved in the debug snapshot at dir by
+// GenerateOptions.DebugSnapshot, with the same options, so that failures
+// reported by users can be reproduced without their repository. The
+// snapshot is loaded in GOPATH mode; env supplies the rest of the
+// environment, such as PATH and GOCACHE. Output paths refer to the
+// snapshot's copy of the packages, and the errors of the original run are
+// listed in the snapshot's snapshot.json for comparison.
+func Replay(ctx context.Context, dir string, env []string) ([]GenerateResult, ErrorList) {
+	snap, err := readSnapshot(dir)
+	if err != nil {
+		return nil, newErrorList("", []error{err})
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return nil, newErrorList("", []error{err})
+	}
+	env = append(append([]string(nil), env...),
+		"GO111MODULE=off",
+		"GOPATH="+dir,
+		"GOENV=off",
+		"GOFLAGS=",
+		"GOWORK=off",
+		"GOOS="+snap.GOOS,
+		"GOARCH="+snap.GOARCH,
+		"CGO_ENABLED="+snap.CGOEnabled,
+	)
+	opts := &GenerateOptions{
+		PrefixOutputFile: snap.PrefixOutputFile,
+		OutputFileName:   snap.OutputFileName,
+		Tags:             snap.Tags,
+		AutoContext:      snap.AutoContext,
+		StableOrder:      snap.StableOrder,
+		AutoNames:        snap.AutoNames,
+		Injectors:        snap.Injectors,
+		Partial:          snap.Partial,
+		Examples:         snap.Examples,
+	}
+	if snap.Header != "" {
+		opts.Header = []byte(snap.Header)
+	}
+	return Generate(ctx, filepath.Join(dir, "src"), env, snap.Patterns, opts)
+}
+
+// readSnapshot reads the description of the debug snapshot at dir.
+func readSnapshot(dir string) (*snapshot, error) {
+	path := filepath.Join(dir, snapshotFile)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	snap := new(snapshot)
+	if err := json.Unmarshal(data, snap); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if snap.Version != snapshotVersion {
+		return nil, fmt.Errorf("%s: unsupported snapshot version %d", path, snap.Version)
+	}
+	return snap, nil
+}
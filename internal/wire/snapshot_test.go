@@ -0,0 +1,93 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDebugSnapshot(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"import \"example.com/app/config\"",
+		"",
+		"type App struct{ cfg config.Config }",
+		"",
+		"func NewApp(cfg config.Config) *App { return &App{cfg} }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "config", "config.go"), strings.Join([]string{
+		"package config",
+		"",
+		"type Config struct{ Name string }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func InitApp() *App {",
+		"	panic(wire.Build(NewApp))",
+		"}",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	snapDir := filepath.Join(t.TempDir(), "snapshot")
+	gens, errs := Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{DebugSnapshot: snapDir})
+	if len(errs) > 0 {
+		t.Fatalf("Generate returned errors: %v", errs)
+	}
+	if len(gens) != 1 || len(gens[0].Errs) != 1 {
+		t.Fatalf("Generate = %+v; want one error", gens)
+	}
+	for _, path := range []string{
+		"snapshot.json",
+		"src/example.com/app/app/wire.go",
+		"src/example.com/app/config/config.go",
+		"src/github.com/goforj/wire/wire.go",
+	} {
+		if _, err := os.Stat(filepath.Join(snapDir, filepath.FromSlash(path))); err != nil {
+			t.Errorf("snapshot is missing %s: %v", path, err)
+		}
+	}
+
+	// The snapshot does not need the original module.
+	if err := os.RemoveAll(root); err != nil {
+		t.Fatal(err)
+	}
+	gens, errs = Replay(ctx, snapDir, os.Environ())
+	if len(errs) > 0 {
+		t.Fatalf("Replay returned errors: %v", errs)
+	}
+	if len(gens) != 1 || len(gens[0].Errs) != 1 {
+		t.Fatalf("Replay = %+v; want one error", gens)
+	}
+	want := filepath.Join(snapDir, "src", "example.com", "app", "app", "wire.go") + ":7:1: inject InitApp: no provider found for example.com/app/config.Config"
+	if got := gens[0].Errs[0].Error(); !strings.HasPrefix(got, want) {
+		t.Errorf("Replay error = %q; want prefix %q", got, want)
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strconv"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// CheckSyntax checks the injectors of the packages matching patterns against
+// the rules that do not need type information: each injector is declared in
+// a file excluded from normal builds by the wireinject build tag, its body is
+// a single call to wire.Build followed by a return, and it returns a value,
+// optionally followed by a cleanup function and an error. Only the files of
+// each package are listed and parsed, so CheckSyntax is much faster than
+// Load, but it cannot tell whether a provider is missing or whether error in
+// a result list is the predeclared type. Test files are not checked.
+func CheckSyntax(ctx context.Context, wd string, env []string, tags string, patterns []string) []error {
+	checkStart := time.Now()
+	defer logTiming(ctx, "check_syntax", checkStart)
+	pkgs, err := listPackages(ctx, wd, env, tags, patterns, packages.NeedName|packages.NeedFiles)
+	if err != nil {
+		return []error{err}
+	}
+	fset := token.NewFileSet()
+	ec := new(errorCollector)
+	for _, pkg := range pkgs {
+		if isWireImport(pkg.PkgPath) {
+			continue
+		}
+		for _, err := range pkg.Errors {
+			ec.add(err)
+		}
+		for _, path := range pkg.GoFiles {
+			f, err := parser.ParseFile(fset, path, nil, parser.ParseComments|parser.SkipObjectResolution)
+			if err != nil {
+				ec.add(err)
+				continue
+			}
+			ec.add(checkFileSyntax(fset, f)...)
+		}
+	}
+	return ec.errors
+}
+
+// checkFileSyntax returns the structural errors of the injectors in f.
+func checkFileSyntax(fset *token.FileSet, f *ast.File) []error {
+	names, dot := wireImportNames(f)
+	if len(names) == 0 && !dot {
+		return nil
+	}
+	var errs []error
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		buildCall, panicked, err := findInjectorBuildSyntax(fn, names, dot)
+		if err != nil {
+			errs = append(errs, notePosition(fset.Position(fn.Pos()), err))
+			continue
+		}
+		if buildCall == nil {
+			continue
+		}
+		if err := missingWireinjectError(fset, f, fn); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if buildCallName(buildCall) == "Populate" {
+			// Injectors that fill in a struct have their own signatures.
+			continue
+		}
+		if fn.Type.Results.NumFields() == 0 {
+			// Whether the injector's sets give it anything to build for
+			// its side effects takes type information.
+			continue
+		}
+		if err := resultsSyntax(fn.Type.Results); err != nil {
+			errs = append(errs, notePosition(fset.Position(fn.Pos()),
+				withCode(CodeSignature, fmt.Errorf("inject %s: %v", fn.Name.Name, err))))
+			continue
+		}
+		if !panicked && !endsInReturn(fn.Body) {
+			errs = append(errs, notePosition(fset.Position(fn.Body.Rbrace),
+				fmt.Errorf("inject %s: wire.Build must be followed by a return statement", fn.Name.Name)))
+		}
+	}
+	return errs
+}
+
+// wireImportNames returns the names f imports the wire package under, and
+// whether it dot-imports the package.
+func wireImportNames(f *ast.File) (names map[string]bool, dot bool) {
+	names = make(map[string]bool)
+	for _, imp := range f.Imports {
+		if p, err := strconv.Unquote(imp.Path.Value); err != nil || !isWireImport(p) {
+			continue
+		}
+		switch {
+		case imp.Name == nil:
+			names["wire"] = true
+		case imp.Name.Name == ".":
+			dot = true
+		case imp.Name.Name != "_":
+			names[imp.Name.Name] = true
+		}
+	}
+	return names, dot
+}
+
+// isBuildCallSyntax reports whether call calls wire.Build, wire.TestBuild,
+// wire.MustBuild, or wire.Populate, with the wire package imported under one
+// of names or, if dot is set, with a dot import.
+func isBuildCallSyntax(call *ast.CallExpr, names map[string]bool, dot bool) bool {
+	switch fun := call.Fun.(type) {
+	case *ast.SelectorExpr:
+		id, ok := fun.X.(*ast.Ident)
+		return ok && names[id.Name] && isBuildName(fun.Sel.Name)
+	case *ast.Ident:
+		return dot && isBuildName(fun.Name)
+	}
+	return false
+}
+
+// isBuildName reports whether name is the name of a marker that makes a
+// function an injector.
+func isBuildName(name string) bool {
+	return name == "Build" || name == "TestBuild" || name == "MustBuild" || name == "Populate"
+}
+
+// buildCallName returns the name of the marker call calls.
+func buildCallName(call *ast.CallExpr) string {
+	switch fun := call.Fun.(type) {
+	case *ast.SelectorExpr:
+		return fun.Sel.Name
+	case *ast.Ident:
+		return fun.Name
+	}
+	return ""
+}
+
+// findInjectorBuildSyntax is findInjectorBuild without type information. It
+// also reports whether the call is wrapped in panic, in which case the
+// injector needs no return statement.
+func findInjectorBuildSyntax(fn *ast.FuncDecl, names map[string]bool, dot bool) (call *ast.CallExpr, panicked bool, err error) {
+	numStatements := 0
+	invalid := false
+	for _, stmt := range fn.Body.List {
+		switch stmt := stmt.(type) {
+		case *ast.ExprStmt:
+			numStatements++
+			if numStatements > 1 {
+				invalid = true
+			}
+			c, ok := stmt.X.(*ast.CallExpr)
+			if !ok {
+				continue
+			}
+			wrapped := false
+			if id, ok := c.Fun.(*ast.Ident); ok && id.Name == "panic" && len(c.Args) == 1 {
+				if c, ok = c.Args[0].(*ast.CallExpr); !ok {
+					continue
+				}
+				wrapped = true
+			}
+			if isBuildCallSyntax(c, names, dot) {
+				call, panicked = c, wrapped
+			}
+		case *ast.EmptyStmt:
+			// Do nothing.
+		case *ast.ReturnStmt:
+			if numStatements == 0 {
+				return nil, false, nil
+			}
+		default:
+			invalid = true
+		}
+	}
+	if call == nil {
+		return nil, false, nil
+	}
+	if invalid {
+		return nil, false, errors.New("a call to wire.Build indicates that this function is an injector, but injectors must consist of only the wire.Build call and an optional return")
+	}
+	return call, panicked, nil
+}
+
+// endsInReturn reports whether the last statement of body is a return.
+func endsInReturn(body *ast.BlockStmt) bool {
+	for i := len(body.List) - 1; i >= 0; i-- {
+		switch body.List[i].(type) {
+		case *ast.EmptyStmt:
+			continue
+		case *ast.ReturnStmt:
+			return true
+		}
+		return false
+	}
+	return false
+}
+
+// resultsSyntax checks the shape of an injector's results as funcOutput
+// does, by the names of the types: error, func(), and func() error.
+func resultsSyntax(results *ast.FieldList) error {
+	var names []string
+	for _, field := range results.List {
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			names = append(names, types.ExprString(field.Type))
+		}
+	}
+	const cleanup, errCleanup = "func()", "func() error"
+	switch len(names) {
+	case 1:
+		return nil
+	case 2:
+		if t := names[1]; t != "error" && t != cleanup && t != errCleanup {
+			return fmt.Errorf("second return type is %s; must be error, func(), or func() error", t)
+		}
+		return nil
+	case 3:
+		if t := names[1]; t != cleanup && t != errCleanup {
+			return fmt.Errorf("second return type is %s; must be func() or func() error", t)
+		}
+		if t := names[2]; t != "error" {
+			return fmt.Errorf("third return type is %s; must be error", t)
+		}
+		return nil
+	default:
+		return errors.New("too many return values")
+	}
+}
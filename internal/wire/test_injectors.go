@@ -0,0 +1,157 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Injectors may be declared in wireinject test files, so that test-only
+// object graphs, such as ones built with fakes, stay out of production code.
+// They are generated from the package's test variants into test outputs
+// named after the package's output file: wire_gen_test.go for injectors in
+// test files of the package itself, and wire_gen_external_test.go for those
+// in the external _test package.
+
+// isTestVariant reports whether pkg is a test variant of a package, as
+// loaded with packages.Config.Tests.
+func isTestVariant(pkg *packages.Package) bool {
+	return strings.HasSuffix(pkg.ID, ".test]")
+}
+
+// outputFileName returns the name of the file Wire generates for pkg.
+func outputFileName(pkg *packages.Package, opts *GenerateOptions) (string, error) {
+	external := strings.HasSuffix(pkg.Name, "_test")
+	name, err := outputFileNameFor(opts.PrefixOutputFile, opts.OutputFileName, strings.TrimSuffix(pkg.Name, "_test"))
+	if err != nil || !isTestVariant(pkg) {
+		return name, err
+	}
+	return testOutputFileName(name, external), nil
+}
+
+// hasTestInjectorFiles reports whether dir has test files built only with
+// the wireinject tag. It only reads their headers, so it is cheap enough to
+// call for every package.
+func hasTestInjectorFiles(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	fset := token.NewFileSet()
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, e.Name()), nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err == nil && isWireinjectFile(f) {
+			return true
+		}
+	}
+	return false
+}
+
+// packageDir returns the directory of pkg, or "" if it has no files.
+func packageDir(pkg *packages.Package) string {
+	files := append(append([]string(nil), pkg.GoFiles...), pkg.IgnoredFiles...)
+	if len(files) == 0 {
+		return ""
+	}
+	return filepath.Dir(files[0])
+}
+
+// loadTests loads the test variants of the package at pkgPath: the package
+// compiled with its test files and, if it has one, its external _test
+// package. Test files are parsed in full, like the package's own files.
+func (ll *lazyLoader) loadTests(pkgPath string) ([]*packages.Package, []error) {
+	if ll.preloaded != nil {
+		// Preloaded packages come without their test files.
+		return nil, nil
+	}
+	cfg := &packages.Config{
+		Context:    ll.ctx,
+		Mode:       ll.fullMode(),
+		Dir:        ll.wd,
+		Env:        ll.env,
+		BuildFlags: []string{"-tags=wireinject"},
+		Fset:       ll.fset,
+		Tests:      true,
+		ParseFile:  ll.parseTestFileFor(pkgPath),
+		Overlay:    loadOverlay(ll.ctx),
+	}
+	if len(ll.tags) > 0 {
+		cfg.BuildFlags[0] += " " + ll.tags
+	}
+	debugf(ll.ctx, "loading test variants of %s for the injectors in its test files", pkgPath)
+	loadStart := time.Now()
+	pkgs, err := packages.Load(cfg, "pattern="+pkgPath)
+	logTiming(ll.ctx, "load.packages.tests.load", loadStart)
+	if err != nil {
+		return nil, []error{err}
+	}
+	var variants []*packages.Package
+	for _, p := range pkgs {
+		if isTestVariant(p) {
+			variants = append(variants, p)
+		}
+	}
+	if errs := collectLoadErrors(variants); len(errs) > 0 {
+		return nil, injectorTypeErrors(ll.fset, variants, errs)
+	}
+	return variants, nil
+}
+
+// parseTestFileFor is like parseFileFor, but keeps the test files of the
+// package at pkgPath, which the initial load does not list.
+func (ll *lazyLoader) parseTestFileFor(pkgPath string) func(*token.FileSet, string, []byte) (*ast.File, error) {
+	var dir string
+	for name := range ll.baseFiles[pkgPath] {
+		dir = filepath.Dir(name)
+		break
+	}
+	parse := ll.parseFileFor(pkgPath)
+	return func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+		if strings.HasSuffix(filename, "_test.go") && filepath.Dir(filepath.Clean(filename)) == dir {
+			return parser.ParseFile(fset, filename, src, parser.ParseComments|parser.SkipObjectResolution)
+		}
+		return parse(fset, filename, src)
+	}
+}
+
+// testInjectorDirs returns the directories among dirs that have a test file
+// declaring an injector.
+func testInjectorDirs(fset *token.FileSet, dirs []string) map[string]bool {
+	found := make(map[string]bool)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasSuffix(e.Name(), "_test.go") && declaresInjector(fset, filepath.Join(dir, e.Name())) {
+				found[dir] = true
+				break
+			}
+		}
+	}
+	return found
+}
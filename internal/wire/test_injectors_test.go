@@ -0,0 +1,148 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateTestInjectors(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"type Store interface{ Get() string }",
+		"",
+		"type DB struct{}",
+		"",
+		"func (*DB) Get() string { return \"db\" }",
+		"",
+		"func NewDB() *DB { return &DB{} }",
+		"",
+		"type Service struct{ Store Store }",
+		"",
+		"func NewService(s Store) *Service { return &Service{Store: s} }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func InitService() *Service {",
+		"\tpanic(wire.Build(NewService, NewDB, wire.Bind(new(Store), new(*DB))))",
+		"}",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "fake_test.go"), strings.Join([]string{
+		"package app",
+		"",
+		"type fakeStore struct{}",
+		"",
+		"func (fakeStore) Get() string { return \"fake\" }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire_test.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func initTestService() *Service {",
+		"\tpanic(wire.Build(NewService, wire.Struct(new(fakeStore)), wire.Bind(new(Store), new(fakeStore))))",
+		"}",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire_external_test.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package app_test",
+		"",
+		"import (",
+		"\t\"example.com/app/app\"",
+		"\t\"github.com/goforj/wire\"",
+		")",
+		"",
+		"func initDBService() *app.Service {",
+		"\tpanic(wire.Build(app.NewService, app.NewDB, wire.Bind(new(app.Store), new(*app.DB))))",
+		"}",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	ctx := context.Background()
+	gens, errs := Generate(ctx, root, env, []string{"./app"}, &GenerateOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("Generate failed: %v", errs)
+	}
+	want := []struct {
+		file, pkg, injector, without string
+	}{
+		{"wire_gen.go", "package app\n", "func InitService() *Service {", "initTestService"},
+		{"wire_gen_test.go", "package app\n", "func initTestService() *Service {", "InitService"},
+		{"wire_gen_external_test.go", "package app_test\n", "func initDBService() *app.Service {", "initTestService"},
+	}
+	if len(gens) != len(want) {
+		t.Fatalf("Generate returned %d results; want %d", len(gens), len(want))
+	}
+	for i, w := range want {
+		gen := gens[i]
+		if len(gen.Errs) > 0 {
+			t.Fatalf("%s: %v", w.file, gen.Errs)
+		}
+		content := string(gen.Content)
+		if filepath.Base(gen.OutputPath) != w.file {
+			t.Errorf("result %d OutputPath = %s; want %s", i, gen.OutputPath, w.file)
+		}
+		if !strings.Contains(content, w.pkg) || !strings.Contains(content, w.injector) || strings.Contains(content, w.without) {
+			t.Errorf("%s content:\n%s\nwant %q and %q without %q", w.file, content, w.pkg, w.injector, w.without)
+		}
+		if err := os.WriteFile(gen.OutputPath, gen.Content, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if strings.Contains(string(gens[1].Content), "//go:generate") {
+		t.Errorf("wire_gen_test.go has a go:generate directive:\n%s", gens[1].Content)
+	}
+
+	files, err := GeneratedFiles(ctx, root, env, "", []string{"./app"}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Errorf("GeneratedFiles = %v; want the three outputs", files)
+	}
+	if err := os.Remove(filepath.Join(root, "app", "wire_test.go")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(root, "app", "wire_external_test.go")); err != nil {
+		t.Fatal(err)
+	}
+	orphaned, err := OrphanedFiles(ctx, root, env, "", []string{"./app"}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orphaned) != 2 || filepath.Base(orphaned[0]) != "wire_gen_external_test.go" || filepath.Base(orphaned[1]) != "wire_gen_test.go" {
+		t.Errorf("OrphanedFiles = %v; want the test outputs", orphaned)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func main() {
+	app, cleanup, err := injectApp()
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	fmt.Println("registered:", app.Registry.Names)
+	cleanup()
+	_, _, err = injectUnnamed(&Config{})
+	fmt.Println("error:", err)
+}
+
+type Config struct {
+	Name string
+}
+
+func NewConfig() *Config {
+	return &Config{Name: "app"}
+}
+
+type Registry struct {
+	Names []string
+}
+
+func NewRegistry() *Registry {
+	return new(Registry)
+}
+
+func (r *Registry) Register(name string) error {
+	if name == "" {
+		return errors.New("empty name")
+	}
+	r.Names = append(r.Names, name)
+	return nil
+}
+
+type App struct {
+	Name     string
+	Registry *Registry
+}
+
+func NewApp(cfg *Config, reg *Registry) (*App, func(), error) {
+	return &App{Name: cfg.Name, Registry: reg}, func() { fmt.Println("cleanup") }, nil
+}
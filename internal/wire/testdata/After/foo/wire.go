@@ -0,0 +1,39 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//go:build wireinject
+// +build wireinject
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/goforj/wire"
+)
+
+func injectApp() (*App, func(), error) {
+	panic(wire.Build(NewConfig, NewRegistry, NewApp,
+		wire.After(func(app *App, reg *Registry) error {
+			return reg.Register(app.Name)
+		}),
+		wire.After(func(app *App) {
+			fmt.Println("built", app.Name)
+		})))
+}
+
+func injectUnnamed(cfg *Config) (*App, func(), error) {
+	panic(wire.Build(NewRegistry, NewApp, wire.After(func(app *App) error {
+		return app.Registry.Register("")
+	})))
+}
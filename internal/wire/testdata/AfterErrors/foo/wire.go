@@ -0,0 +1,47 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//go:build wireinject
+// +build wireinject
+
+package main
+
+import (
+	"github.com/goforj/wire"
+)
+
+var Set = wire.NewSet(NewApp, wire.After(func(*App) {}))
+
+func injectFromSet() *App {
+	panic(wire.Build(Set))
+}
+
+func injectMustNotFail() *App {
+	panic(wire.Build(NewApp, wire.After(func(app *App) error {
+		return nil
+	})))
+}
+
+func injectMissing() *App {
+	panic(wire.Build(NewApp, wire.After(func(app *App, cfg *Config) {})))
+}
+
+func injectUsesArg(name string) *App {
+	panic(wire.Build(NewApp, wire.After(func(app *App) {
+		_ = name
+	})))
+}
+
+func injectNotLiteral() (*App, error) {
+	panic(wire.Build(NewApp, wire.After(validate)))
+}
@@ -0,0 +1,40 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"example.com/bar"
+)
+
+func main() {
+	fmt.Println(injectGreeter().Msg)
+}
+
+// Msg and Greeter are spelled differently by the providers and the
+// injector, but denote the same types.
+type (
+	Msg     = bar.Message
+	Greeter = bar.Greeter
+)
+
+func provideMsg() Msg {
+	return "Hello, World!"
+}
+
+func provideGreeter(m bar.Message) *Greeter {
+	return &Greeter{Msg: m}
+}
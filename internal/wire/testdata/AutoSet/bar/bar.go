@@ -0,0 +1,53 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bar
+
+import "example.com/bar/store"
+
+type Config struct {
+	Name string
+}
+
+func NewConfig() *Config {
+	return &Config{Name: "app"}
+}
+
+// DefaultConfig would conflict with NewConfig, but does not match the
+// naming convention.
+func DefaultConfig() *Config {
+	return &Config{Name: "default"}
+}
+
+type Greeter struct {
+	cfg   *Config
+	store *store.Store
+}
+
+func NewGreeter(cfg *Config, st *store.Store) *Greeter {
+	return &Greeter{cfg: cfg, store: st}
+}
+
+func (g *Greeter) Greet() string {
+	return g.store.Prefix + ": " + g.cfg.Name
+}
+
+// NewPair is not a valid provider and is skipped.
+func NewPair() (int, int) {
+	return 1, 2
+}
+
+func newHidden() *Greeter {
+	return nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build wireinject
+// +build wireinject
+
+package main
+
+import (
+	"example.com/bar"
+	"github.com/goforj/wire"
+)
+
+var pattern = "example.com/bar"
+
+func injectNotImported() *bar.Config {
+	panic(wire.Build(wire.AutoSet("example.com/baz")))
+}
+
+func injectNoMatches() *bar.Config {
+	panic(wire.Build(wire.AutoSet("example.com/bar")))
+}
+
+func injectNotConstant() *bar.Config {
+	panic(wire.Build(wire.AutoSet(pattern)))
+}
+
+func injectNoPatterns() *bar.Config {
+	panic(wire.Build(wire.AutoSet()))
+}
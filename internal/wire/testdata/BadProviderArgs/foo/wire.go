@@ -0,0 +1,43 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//go:build wireinject
+// +build wireinject
+
+package main
+
+import (
+	"github.com/goforj/wire"
+)
+
+var server Server
+
+func injectPort() Port {
+	panic(wire.Build(Port(8080)))
+}
+
+func injectFooFromCall() *Foo {
+	panic(wire.Build(makeFooProvider()))
+}
+
+func injectFooFromLiteral() *Foo {
+	panic(wire.Build(func() *Foo { return &Foo{} }))
+}
+
+func injectBar() *Bar {
+	panic(wire.Build(server.NewBar))
+}
+
+func injectFooFromField() *Foo {
+	panic(wire.Build(server.Foo))
+}
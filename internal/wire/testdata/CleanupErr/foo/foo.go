@@ -0,0 +1,65 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func main() {
+	bar, cleanup, err := injectBar()
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		return
+	}
+	fmt.Println(*bar)
+	fmt.Println(cleanup())
+	fmt.Println(*bar)
+}
+
+type Foo int
+type Baz int
+type Bar int
+
+func provideFoo() (*Foo, func() error, error) {
+	foo := new(Foo)
+	*foo = 42
+	return foo, func() error {
+		*foo = 0
+		return errors.New("close foo")
+	}, nil
+}
+
+func provideBaz(foo *Foo) (*Baz, func()) {
+	baz := new(Baz)
+	*baz = 1
+	return baz, func() {
+		if *foo == 0 {
+			panic("foo cleaned up before baz")
+		}
+	}
+}
+
+func provideBar(foo *Foo, baz *Baz) (*Bar, func() error) {
+	bar := new(Bar)
+	*bar = 77
+	return bar, func() error {
+		if *foo == 0 {
+			panic("foo cleaned up before bar")
+		}
+		*bar = 0
+		return errors.New("close bar")
+	}
+}
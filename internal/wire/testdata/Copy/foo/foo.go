@@ -0,0 +1,71 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+)
+
+func main() {
+	s := injectServer()
+	fmt.Println(s.HTTP.cfg.Name, s.HTTP.cfg.Build, s.GRPC.cfg.Name, s.GRPC.cfg.Build)
+}
+
+// Counter counts the configs built, and is shared by them.
+type Counter struct {
+	n int
+}
+
+func NewCounter() *Counter {
+	return &Counter{}
+}
+
+// Config is adjusted by each of its consumers.
+type Config struct {
+	Name  string
+	Build int
+}
+
+func NewConfig(c *Counter) *Config {
+	c.n++
+	return &Config{Name: "default", Build: c.n}
+}
+
+type HTTP struct {
+	cfg *Config
+}
+
+func NewHTTP(cfg *Config) *HTTP {
+	cfg.Name = "http"
+	return &HTTP{cfg: cfg}
+}
+
+type GRPC struct {
+	cfg *Config
+}
+
+func NewGRPC(cfg *Config) *GRPC {
+	cfg.Name = "grpc"
+	return &GRPC{cfg: cfg}
+}
+
+type Server struct {
+	HTTP *HTTP
+	GRPC *GRPC
+}
+
+func NewServer(h *HTTP, g *GRPC) *Server {
+	return &Server{HTTP: h, GRPC: g}
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package main
+
+import (
+	"fmt"
+
+	"github.com/goforj/wire"
+)
+
+func main() {
+	fmt.Println(injectServer().Greet())
+}
+
+type Config struct {
+	Name string
+}
+
+func NewConfig() *Config {
+	return &Config{Name: "app"}
+}
+
+type Logger struct {
+	Prefix string
+}
+
+func NewLogger() *Logger {
+	return &Logger{Prefix: "platform"}
+}
+
+func NewQuietLogger() *Logger {
+	return &Logger{Prefix: "quiet"}
+}
+
+type Server struct {
+	cfg *Config
+	log *Logger
+}
+
+func NewServer(cfg *Config, log *Logger) *Server {
+	return &Server{cfg: cfg, log: log}
+}
+
+func (s *Server) Greet() string {
+	return s.log.Prefix + ": " + s.cfg.Name
+}
+
+var (
+	LogSet      = wire.NewSet(NewLogger)
+	PlatformSet = wire.NewSet(NewConfig, LogSet)
+)
@@ -0,0 +1,30 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package bar
+
+type Config struct {
+	Name string
+}
+
+// Constructor is a named function type.
+type Constructor func() (*Config, error)
+
+// NewConfig is chosen when the package is initialized.
+var NewConfig Constructor
+
+func init() {
+	NewConfig = func() (*Config, error) {
+		return &Config{Name: "from bar"}, nil
+	}
+}
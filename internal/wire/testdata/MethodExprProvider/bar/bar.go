@@ -0,0 +1,31 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package bar
+
+type Registry struct {
+	host string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{host: "registry.local"}
+}
+
+type Client struct {
+	Addr string
+}
+
+// NewClient is a constructor exposed as a method on a factory object.
+func (r *Registry) NewClient() (*Client, error) {
+	return &Client{Addr: r.host + ":443"}, nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package main
+
+import (
+	"fmt"
+
+	"example.com/bar"
+)
+
+func main() {
+	app, err := injectApp()
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		return
+	}
+	fmt.Println(app.Greeting, app.Client.Addr)
+}
+
+type App struct {
+	Greeting Greeting
+	Client   *bar.Client
+}
+
+type Greeting string
+
+type Settings struct {
+	Name string
+}
+
+func NewSettings() Settings {
+	return Settings{Name: "gopher"}
+}
+
+// Greeting has a value receiver.
+func (s Settings) Greeting() Greeting {
+	return Greeting("hello " + s.Name)
+}
+
+func NewApp(g Greeting, c *bar.Client) *App {
+	return &App{Greeting: g, Client: c}
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package main
+
+import (
+	"fmt"
+
+	"github.com/goforj/wire"
+)
+
+func main() {
+	fmt.Println(injectApp())
+}
+
+type (
+	A int
+	B int
+	C int
+)
+
+type App struct {
+	A A
+	B B
+	C C
+}
+
+func provideA1() A { return 1 }
+func provideA2() A { return 2 }
+func provideB1() B { return 1 }
+func provideB2() B { return 2 }
+func provideC1() C { return 1 }
+func provideC2() C { return 2 }
+
+func provideApp(a A, b B, c C) *App {
+	return &App{A: a, B: b, C: c}
+}
+
+var (
+	Set1  = wire.NewSet(provideA1, provideB1)
+	Set2  = wire.NewSet(provideA2, provideB2)
+	Inner = wire.NewSet(provideC1)
+	Outer = wire.NewSet(Inner)
+)
@@ -0,0 +1,44 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func main() {
+	foo, cleanup := injectFoo(false)
+	fmt.Println(foo)
+	cleanup()
+	defer func() {
+		fmt.Println("recovered:", recover())
+	}()
+	injectFoo(true)
+}
+
+type Foo int
+type Bar int
+
+func provideBar() (Bar, func()) {
+	return 1, func() { fmt.Println("cleaned up bar") }
+}
+
+func provideFoo(bar Bar, fail bool) (Foo, error) {
+	if fail {
+		return 0, errors.New("foo failed")
+	}
+	return Foo(bar + 41), nil
+}
@@ -0,0 +1,38 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+func main() {
+	fmt.Println(injectFoo())
+}
+
+type Foo struct{ X int }
+
+func NewFoo() *Foo { return &Foo{} }
+
+type Bar struct{ W io.Writer }
+
+func NewBar(w io.Writer) *Bar { return &Bar{W: w} }
+
+const defaultX = 8080
+
+var out io.Writer
+
+var fooProviders = []func() *Foo{NewFoo}
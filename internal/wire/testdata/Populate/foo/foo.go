@@ -0,0 +1,63 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "fmt"
+
+func main() {
+	h := &Handler{Name: "main"}
+	cleanup, err := populateHandler(h, Config{DSN: "mem"})
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		return
+	}
+	fmt.Println(h.Name, h.DB.dsn, h.Log.prefix)
+	cleanup()
+
+	var w Worker
+	populateWorker(&w)
+	fmt.Println(w.Log.prefix)
+}
+
+type Config struct {
+	DSN string
+}
+
+type DB struct {
+	dsn string
+}
+
+type Logger struct {
+	prefix string
+}
+
+// Handler is allocated by a framework and populated by Wire.
+type Handler struct {
+	DB   *DB
+	Log  *Logger
+	Name string `wire:"-"`
+}
+
+type Worker struct {
+	Log *Logger
+}
+
+func provideDB(cfg Config) (*DB, func(), error) {
+	return &DB{dsn: cfg.DSN}, func() { fmt.Println("close db") }, nil
+}
+
+func provideLogger() *Logger {
+	return &Logger{prefix: "log"}
+}
@@ -0,0 +1,43 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package main
+
+import (
+	"fmt"
+
+	"github.com/goforj/wire"
+)
+
+func main() {
+	fmt.Println(injectStore().Name)
+}
+
+type Store struct {
+	Name string
+}
+
+func NewStore() *Store {
+	return &Store{Name: "store"}
+}
+
+func NewLegacyStore() *Store {
+	return &Store{Name: "legacy store"}
+}
+
+var StoreSet = wire.NewSet(NewStore)
+
+// Deprecated: Use StoreSet.
+var LegacySet = wire.NewSet(NewLegacyStore)
+
+var _ = wire.Redirect(LegacySet, StoreSet)
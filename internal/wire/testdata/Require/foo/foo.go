@@ -0,0 +1,50 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package main
+
+import (
+	"fmt"
+
+	"github.com/goforj/wire"
+)
+
+func main() {
+	fmt.Println(injectServer().Addr)
+}
+
+type Config struct {
+	Addr string
+}
+
+func NewConfig() *Config {
+	return &Config{Addr: ":8080"}
+}
+
+type Server struct {
+	Addr string
+}
+
+func NewServer(cfg *Config) *Server {
+	return &Server{Addr: cfg.Addr}
+}
+
+// Telemetry is only needed for the side effect of constructing it.
+type Telemetry struct{}
+
+func NewTelemetry(cfg *Config) *Telemetry {
+	fmt.Println("telemetry for", cfg.Addr)
+	return &Telemetry{}
+}
+
+var TelemetrySet = wire.NewSet(NewTelemetry, wire.Require(new(*Telemetry)))
@@ -0,0 +1,69 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package main
+
+import (
+	"fmt"
+
+	"github.com/goforj/wire"
+)
+
+func main() {
+	users, err := injectUsers()
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		return
+	}
+	orders, err := injectOrders()
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		return
+	}
+	fmt.Println(users.Pool == orders.Pool)
+}
+
+type Config struct {
+	DSN string
+}
+
+func NewConfig() *Config {
+	return &Config{DSN: "postgres://"}
+}
+
+type Pool struct {
+	DSN string
+}
+
+func NewPool(cfg *Config) (*Pool, error) {
+	fmt.Println("opening", cfg.DSN)
+	return &Pool{DSN: cfg.DSN}, nil
+}
+
+type Users struct {
+	Pool *Pool
+}
+
+func NewUsers(p *Pool) *Users {
+	return &Users{Pool: p}
+}
+
+type Orders struct {
+	Pool *Pool
+}
+
+func NewOrders(p *Pool) *Orders {
+	return &Orders{Pool: p}
+}
+
+var StorageSet = wire.NewSet(wire.Shared(NewConfig), wire.Shared(NewPool))
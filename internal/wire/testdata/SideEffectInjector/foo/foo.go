@@ -0,0 +1,51 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+)
+
+func main() {
+	registerAll()
+	if err := registerChecked(); err != nil {
+		fmt.Println("error:", err)
+	}
+	fmt.Println(registry)
+}
+
+// registry records the components as they register themselves.
+var registry []string
+
+type DB struct{}
+
+func provideDB() *DB {
+	registry = append(registry, "db")
+	return new(DB)
+}
+
+type Handlers struct{}
+
+func provideHandlers(db *DB) Handlers {
+	registry = append(registry, "handlers")
+	return Handlers{}
+}
+
+type Routes struct{}
+
+func provideRoutes(h Handlers) (Routes, error) {
+	registry = append(registry, "routes")
+	return Routes{}, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"example.com/bar"
+	"github.com/goforj/wire"
+)
+
+func main() {
+	app := initTestApp()
+	fmt.Println(app.clock.Now())
+	fmt.Println(app.mailer.Send())
+}
+
+// testFakes is shared between test injectors.
+var testFakes = wire.Fakes(bar.NewMemStore)
+
+type Clock interface {
+	Now() string
+}
+
+type Mailer interface {
+	Send() string
+}
+
+type App struct {
+	clock  Clock
+	mailer Mailer
+}
+
+func NewApp(clock Clock, mailer Mailer) *App {
+	return &App{clock: clock, mailer: mailer}
+}
+
+type realClock struct{}
+
+func (realClock) Now() string { return "real clock" }
+
+func NewClock() Clock {
+	return realClock{}
+}
+
+type fakeClock struct{}
+
+func (fakeClock) Now() string { return "fake clock" }
+
+// NewFakeClock is not used, since NewClock provides Clock.
+func NewFakeClock() Clock {
+	return fakeClock{}
+}
+
+type fakeMailer struct {
+	store bar.Store
+}
+
+func (m fakeMailer) Send() string { return "sent " + strings.Join(m.store.Messages(), ",") }
+
+// NewFakeMailer is used for Mailer by convention. Its Store is a fake too.
+func NewFakeMailer(store bar.Store) Mailer {
+	return fakeMailer{store: store}
+}
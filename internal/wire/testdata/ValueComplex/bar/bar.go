@@ -0,0 +1,46 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bar
+
+import (
+	"strings"
+	"time"
+
+	"github.com/goforj/wire"
+)
+
+type Host struct {
+	Name string
+	Port int
+}
+
+type Config struct {
+	Timeout time.Duration
+	Hosts   []Host
+	Tags    map[string][]string
+	Name    string
+	Retry   func(attempt int) bool
+}
+
+var Set = wire.NewSet(wire.Value(Config{
+	Timeout: 5 * time.Second,
+	Hosts:   []Host{{Name: "a", Port: 80}, {Name: "b"}},
+	Tags:    map[string][]string{"env": {"dev"}},
+	Name:    strings.ToUpper("primary"),
+	Retry: func(attempt int) bool {
+		max := 3
+		return attempt < max
+	},
+}))
@@ -49,3 +49,35 @@ func logTiming(ctx context.Context, label string, start time.Time) {
 		t(label, time.Since(start))
 	}
 }
+
+type metricLogger func(string, int64)
+
+type metricsKey struct{}
+
+// WithMetrics enables reporting of measurements other than durations, such
+// as the bytes of memory a lightweight Load released, using the provided
+// callback.
+func WithMetrics(ctx context.Context, logf func(string, int64)) context.Context {
+	if logf == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, metricsKey{}, metricLogger(logf))
+}
+
+func metrics(ctx context.Context) metricLogger {
+	if ctx == nil {
+		return nil
+	}
+	if v := ctx.Value(metricsKey{}); v != nil {
+		if m, ok := v.(metricLogger); ok {
+			return m
+		}
+	}
+	return nil
+}
+
+func logMetric(ctx context.Context, name string, value int64) {
+	if m := metrics(ctx); m != nil {
+		m(name, value)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"fmt"
+	"go/token"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// variantsDirective is the comment that generates a package once per build
+// configuration. Placed in a wireinject file before the package clause, as
+// in
+//
+//	//wire:variants linux darwin windows
+//
+// it makes wire load the package once with each tag added, and write the
+// output of each to its own file, such as wire_gen_linux.go, built only
+// with that tag. An injector declared once can then use providers that
+// exist only under some of the tags, such as a provider set declared in
+// both set_linux.go and set_darwin.go.
+const variantsDirective = "//wire:variants"
+
+// knownOS lists the values of GOOS. A variant named after one is loaded
+// with GOOS set to it, so that files selected by their name, such as
+// watch_linux.go, are loaded for it and those of the host are not.
+var knownOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true,
+	"freebsd": true, "hurd": true, "illumos": true, "ios": true, "js": true,
+	"linux": true, "netbsd": true, "openbsd": true, "plan9": true,
+	"solaris": true, "wasip1": true, "windows": true, "zos": true,
+}
+
+// knownArch lists the values of GOARCH. A variant named after one is
+// loaded with GOARCH set to it, as knownOS does for GOOS.
+var knownArch = map[string]bool{
+	"386": true, "amd64": true, "arm": true, "arm64": true, "loong64": true,
+	"mips": true, "mipsle": true, "mips64": true, "mips64le": true,
+	"ppc64": true, "ppc64le": true, "riscv64": true, "s390x": true,
+	"wasm": true,
+}
+
+type variantErrorsKey struct{}
+
+// withVariantErrors makes the loads run with ctx keep the packages with
+// //wire:variants directives even if they fail to load, since their
+// errors only concern the configuration of the load, and each variant is
+// loaded again.
+func withVariantErrors(ctx context.Context) context.Context {
+	return context.WithValue(ctx, variantErrorsKey{}, true)
+}
+
+// dropVariantErrors returns errs without the errors of the packages of pkgs
+// that have //wire:variants directives, if ctx asks for it.
+func dropVariantErrors(ctx context.Context, pkgs []*packages.Package, errs []error) []error {
+	if drop, _ := ctx.Value(variantErrorsKey{}).(bool); !drop || len(errs) == 0 {
+		return errs
+	}
+	hasVariants := make(map[string]bool)
+	for _, pkg := range pkgs {
+		if variants, err := packageVariants(pkg); err == nil && len(variants) > 0 {
+			hasVariants[pkg.PkgPath] = true
+		}
+	}
+	var kept []error
+	for _, err := range errs {
+		if pe, ok := err.(*pkgError); ok && hasVariants[pe.pkg] {
+			continue
+		}
+		kept = append(kept, err)
+	}
+	return kept
+}
+
+// packageVariants returns the variants listed by the //wire:variants
+// directives in the wireinject files of pkg, in the order they appear.
+func packageVariants(pkg *packages.Package) ([]string, error) {
+	variants, err := packageDirective(pkg, variantsDirective)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range variants {
+		if !isBuildTag(v) {
+			return nil, fmt.Errorf("%s: invalid variant %q: want a build tag", pkg.PkgPath, v)
+		}
+		if v == "test" {
+			// The output file would end in _test.go, and so be compiled
+			// only by go test.
+			return nil, fmt.Errorf("%s: invalid variant %q: its output file would be a test file", pkg.PkgPath, v)
+		}
+	}
+	return variants, nil
+}
+
+// isBuildTag reports whether s is a valid build tag.
+func isBuildTag(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// generateVariants generates pkg once for each of variants, loading it with
+// the variant added to opts.Tags and to its //wire:tags, and returns a
+// result for each. The output of each variant goes to the output file name
+// with the variant appended, such as wire_gen_linux.go.
+func generateVariants(ctx context.Context, wd string, env []string, opts *GenerateOptions, pkg *packages.Package, fset *token.FileSet, variants []string) []GenerateResult {
+	results := make([]GenerateResult, 0, len(variants))
+	for _, variant := range variants {
+		variantStart := time.Now()
+		res := generateVariant(ctx, wd, env, opts, pkg, fset, variant)
+		logTiming(ctx, "generate.package."+pkg.PkgPath+".variant."+variant, variantStart)
+		opts.report(res)
+		results = append(results, res)
+	}
+	return results
+}
+
+// generateVariant generates pkg for one of its variants.
+func generateVariant(ctx context.Context, wd string, env []string, opts *GenerateOptions, pkg *packages.Package, fset *token.FileSet, variant string) GenerateResult {
+	res := GenerateResult{PkgPath: pkg.PkgPath}
+	extra, err := packageTags(pkg)
+	if err != nil {
+		res.Errs.add(pkg.PkgPath, err)
+		return res
+	}
+	tags := appendTags(opts.Tags, append(extra, variant))
+	if knownOS[variant] {
+		env = append(env[:len(env):len(env)], "GOOS="+variant)
+	}
+	if knownArch[variant] {
+		env = append(env[:len(env):len(env)], "GOARCH="+variant)
+	}
+	debugf(ctx, "%s: loading variant %s with tags %q", pkg.PkgPath, variant, tags)
+	pkgs, loader, errs := loadInto(ctx, fset, wd, env, tags, []string{pkg.PkgPath})
+	if len(errs) > 0 {
+		res.Errs.add(pkg.PkgPath, errs...)
+		return res
+	}
+	var loaded *packages.Package
+	for _, p := range pkgs {
+		if p.PkgPath == pkg.PkgPath {
+			loaded = p
+		}
+	}
+	if loaded == nil {
+		res.Errs.add(pkg.PkgPath, fmt.Errorf("%s: package not found when loading variant %s", pkg.PkgPath, variant))
+		return res
+	}
+	name, err := outputFileName(loaded, opts)
+	if err != nil {
+		res.Errs.add(pkg.PkgPath, err)
+		return res
+	}
+	variantOpts := *opts
+	variantOpts.Tags = tags
+	variantOpts.PrefixOutputFile = ""
+	variantOpts.OutputFileName = strings.TrimSuffix(name, ".go") + "_" + variant + ".go"
+	pkgOpts, err := withPackageHeader(loaded, &variantOpts)
+	if err != nil {
+		res.Errs.add(pkg.PkgPath, err)
+		return res
+	}
+	res = generateForPackage(ctx, loaded, loader, pkgOpts, nil)
+	if res.Skipped && len(opts.Injectors) == 0 {
+		res.Warnings = append(res.Warnings, newWarning(pkg.PkgPath, CodeExcludedFile, fmt.Errorf(
+			"no injectors found for variant %s: no wireinject file is built with tags %q", variant, tags)))
+	}
+	return res
+}
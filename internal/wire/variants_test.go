@@ -0,0 +1,171 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateVariants(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"//wire:variants fast slow",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func Init() Engine {",
+		"\tpanic(wire.Build(EngineSet))",
+		"}",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "engine.go"), "package app\n\ntype Engine interface{ Name() string }\n")
+	// EngineSet exists only under the variant tags, so the package does
+	// not load without one.
+	for _, variant := range []string{"fast", "slow"} {
+		ctor := "new" + strings.ToUpper(variant[:1]) + variant[1:]
+		writeFile(t, filepath.Join(root, "app", variant+".go"), strings.Join([]string{
+			"//go:build " + variant,
+			"",
+			"package app",
+			"",
+			"import \"github.com/goforj/wire\"",
+			"",
+			"type " + variant + "Engine struct{}",
+			"",
+			"func (" + variant + "Engine) Name() string { return \"" + variant + "\" }",
+			"",
+			"func " + ctor + "() " + variant + "Engine { return " + variant + "Engine{} }",
+			"",
+			"var EngineSet = wire.NewSet(" + ctor + ", wire.Bind(new(Engine), new(" + variant + "Engine)))",
+			"",
+		}, "\n"))
+	}
+
+	env := append(os.Environ(), "GOWORK=off")
+	gens, errs := Generate(context.Background(), root, env, []string{"./..."}, &GenerateOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("Generate failed: %v", errs)
+	}
+	if len(gens) != 2 {
+		t.Fatalf("Generate returned %d results; want one per variant", len(gens))
+	}
+	for i, variant := range []string{"fast", "slow"} {
+		gen := gens[i]
+		ctor := "new" + strings.ToUpper(variant[:1]) + variant[1:]
+		if len(gen.Errs) > 0 {
+			t.Fatalf("variant %s failed: %v", variant, gen.Errs)
+		}
+		if want := filepath.Join(root, "app", "wire_gen_"+variant+".go"); gen.OutputPath != want {
+			t.Errorf("variant %s is written to %s; want %s", variant, gen.OutputPath, want)
+		}
+		content := string(gen.Content)
+		for _, want := range []string{"//go:build !wireinject && " + variant + "\n", ctor + "()"} {
+			if !strings.Contains(content, want) {
+				t.Errorf("variant %s output lacks %q:\n%s", variant, want, content)
+			}
+		}
+	}
+}
+
+func TestGenerateArchVariants(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"//wire:variants amd64 arm64",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func Init() string {",
+		"\tpanic(wire.Build(ArchSet))",
+		"}",
+		"",
+	}, "\n"))
+	// The files are selected by their names, which only GOARCH matches.
+	for _, arch := range []string{"amd64", "arm64"} {
+		writeFile(t, filepath.Join(root, "app", "arch_"+arch+".go"), strings.Join([]string{
+			"package app",
+			"",
+			"import \"github.com/goforj/wire\"",
+			"",
+			"func name" + arch + "() string { return \"" + arch + "\" }",
+			"",
+			"var ArchSet = wire.NewSet(name" + arch + ")",
+			"",
+		}, "\n"))
+	}
+
+	env := append(os.Environ(), "GOWORK=off")
+	gens, errs := Generate(context.Background(), root, env, []string{"./..."}, &GenerateOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("Generate failed: %v", errs)
+	}
+	if len(gens) != 2 {
+		t.Fatalf("Generate returned %d results; want one per variant", len(gens))
+	}
+	for i, arch := range []string{"amd64", "arm64"} {
+		gen := gens[i]
+		if len(gen.Errs) > 0 {
+			t.Fatalf("variant %s failed: %v", arch, gen.Errs)
+		}
+		if want := "name" + arch + "()"; !strings.Contains(string(gen.Content), want) {
+			t.Errorf("variant %s output lacks %q:\n%s", arch, want, gen.Content)
+		}
+	}
+}
+
+func TestGenerateTestVariant(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"//wire:variants prod test",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func Init() *Config {",
+		"\tpanic(wire.Build(NewConfig))",
+		"}",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "app.go"), "package app\n\ntype Config struct{}\n\nfunc NewConfig() *Config { return &Config{} }\n")
+
+	env := append(os.Environ(), "GOWORK=off")
+	gens, errs := Generate(context.Background(), root, env, []string{"./..."}, &GenerateOptions{})
+	var all []string
+	for _, err := range errs {
+		all = append(all, err.Error())
+	}
+	for _, gen := range gens {
+		for _, err := range gen.Errs {
+			all = append(all, err.Error())
+		}
+	}
+	if want := `invalid variant "test"`; !strings.Contains(strings.Join(all, "\n"), want) {
+		t.Errorf("Generate errors = %q; want one containing %q", all, want)
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// maxWarmLoads is the number of loads WarmLoads keeps. The least recently
+// used is dropped first.
+const maxWarmLoads = 8
+
+type warmLoadsKey struct{}
+
+// WarmLoads keeps the packages that Generate and Load load, along with
+// their type-checked syntax, between the runs of a long-lived process such
+// as wire daemon. A run of the same patterns with the same working
+// directory, environment, and tags reuses them rather than running the go
+// command and type-checking the packages again, as long as none of their
+// files, directories, or go.mod, go.sum, and go.work files has changed
+// since they were loaded. Runs with an overlay always load their packages.
+//
+// A WarmLoads is safe for concurrent use. A nil *WarmLoads keeps nothing.
+type WarmLoads struct {
+	mu    sync.Mutex
+	loads map[string]*warmLoad
+}
+
+// warmLoad is a load kept by WarmLoads.
+type warmLoad struct {
+	pkgs   []*packages.Package
+	loader *lazyLoader
+	// pkgPaths lists the import paths of the loaded packages and their
+	// dependencies.
+	pkgPaths []string
+	// files records the files and directories the packages were loaded
+	// from, as they were when they were loaded.
+	files    []cacheFile
+	lastUsed time.Time
+
+	mu sync.Mutex
+	// typed memoizes the type-checked loads of the loader, by load mode
+	// and import paths.
+	typed map[string][]*packages.Package
+}
+
+// NewWarmLoads returns an empty WarmLoads.
+func NewWarmLoads() *WarmLoads {
+	return &WarmLoads{loads: make(map[string]*warmLoad)}
+}
+
+// WithWarmLoads makes the Generate and Load runs with the returned context
+// reuse the packages kept by w, and keep the packages they load in w.
+func WithWarmLoads(ctx context.Context, w *WarmLoads) context.Context {
+	if w == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, warmLoadsKey{}, w)
+}
+
+func warmLoads(ctx context.Context) *WarmLoads {
+	w, _ := ctx.Value(warmLoadsKey{}).(*WarmLoads)
+	return w
+}
+
+// Invalidate drops the loads that include a package whose import path
+// matches one of patterns, as for InvalidatePattern, so that the next run
+// loads them again.
+func (w *WarmLoads) Invalidate(patterns []string) {
+	if w == nil {
+		return
+	}
+	res := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		res[i] = importPathPattern(p)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for key, wl := range w.loads {
+	paths:
+		for _, path := range wl.pkgPaths {
+			for _, re := range res {
+				if re.MatchString(path) {
+					delete(w.loads, key)
+					break paths
+				}
+			}
+		}
+	}
+}
+
+// load is like the package-level load, but reuses the packages kept for
+// the same arguments if they are current, and keeps the packages it loads
+// otherwise.
+func (w *WarmLoads) load(ctx context.Context, wd string, env []string, tags string, patterns []string) ([]*packages.Package, *lazyLoader, []error) {
+	if loadOverlay(ctx) != nil {
+		return loadInto(ctx, token.NewFileSet(), wd, env, tags, patterns)
+	}
+	key := strings.Join([]string{filepath.Clean(wd), envHash(env), tags, strings.Join(patterns, "\x00")}, "\x00")
+	w.mu.Lock()
+	wl := w.loads[key]
+	w.mu.Unlock()
+	if wl != nil {
+		if wl.current() {
+			debugf(ctx, "reusing the packages loaded for %s", strings.Join(patterns, " "))
+			w.mu.Lock()
+			wl.lastUsed = time.Now()
+			w.mu.Unlock()
+			loader := *wl.loader
+			loader.ctx = ctx
+			return append([]*packages.Package(nil), wl.pkgs...), &loader, nil
+		}
+		debugf(ctx, "files changed since %s was loaded, loading it again", strings.Join(patterns, " "))
+		w.mu.Lock()
+		delete(w.loads, key)
+		w.mu.Unlock()
+	}
+	pkgs, loader, errs := loadInto(ctx, token.NewFileSet(), wd, env, tags, patterns)
+	if len(errs) > 0 || loader == nil {
+		// Loads with errors are not kept; the next run reports them
+		// again.
+		return pkgs, loader, errs
+	}
+	all := collectAllPackages(pkgs)
+	files, err := buildCacheFiles(warmLoadFiles(wd, all))
+	if err != nil {
+		return pkgs, loader, errs
+	}
+	wl = &warmLoad{
+		pkgs:     append([]*packages.Package(nil), pkgs...),
+		files:    files,
+		lastUsed: time.Now(),
+		typed:    make(map[string][]*packages.Package),
+	}
+	for path := range all {
+		wl.pkgPaths = append(wl.pkgPaths, path)
+	}
+	loader.warm = wl
+	wl.loader = loader
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.loads[key] = wl
+	for len(w.loads) > maxWarmLoads {
+		var oldest string
+		for k, l := range w.loads {
+			if oldest == "" || l.lastUsed.Before(w.loads[oldest].lastUsed) {
+				oldest = k
+			}
+		}
+		delete(w.loads, oldest)
+	}
+	return pkgs, loader, errs
+}
+
+// current reports whether the files the packages were loaded from are
+// unchanged.
+func (wl *warmLoad) current() bool {
+	paths := make([]string, len(wl.files))
+	for i, f := range wl.files {
+		paths[i] = f.Path
+	}
+	files, err := buildCacheFiles(paths)
+	if err != nil {
+		return false
+	}
+	for i := range files {
+		if files[i] != wl.files[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// typedLoad returns the packages kept for a type-checked load of pkgPaths
+// with mode, if there are any.
+func (wl *warmLoad) typedLoad(pkgPaths []string, mode packages.LoadMode) ([]*packages.Package, bool) {
+	wl.mu.Lock()
+	defer wl.mu.Unlock()
+	pkgs, ok := wl.typed[typedLoadKey(pkgPaths, mode)]
+	return pkgs, ok
+}
+
+// keepTypedLoad keeps the packages of a type-checked load of pkgPaths with
+// mode.
+func (wl *warmLoad) keepTypedLoad(pkgPaths []string, mode packages.LoadMode, pkgs []*packages.Package) {
+	wl.mu.Lock()
+	defer wl.mu.Unlock()
+	wl.typed[typedLoadKey(pkgPaths, mode)] = pkgs
+}
+
+func typedLoadKey(pkgPaths []string, mode packages.LoadMode) string {
+	return mode.String() + "\x00" + strings.Join(pkgPaths, "\x00")
+}
+
+// warmLoadFiles returns the files whose changes make the packages in all
+// stale: their files and directories, which change when files are added or
+// removed, and the go.mod, go.sum, and go.work files that apply to wd.
+func warmLoadFiles(wd string, all map[string]*packages.Package) []string {
+	seen := make(map[string]struct{})
+	var paths []string
+	add := func(path string) {
+		path = filepath.Clean(path)
+		if _, ok := seen[path]; ok {
+			return
+		}
+		seen[path] = struct{}{}
+		paths = append(paths, path)
+	}
+	for _, pkg := range all {
+		if pkg == nil {
+			continue
+		}
+		for _, files := range [][]string{pkg.GoFiles, pkg.CompiledGoFiles, pkg.OtherFiles, pkg.EmbedFiles, pkg.IgnoredFiles} {
+			for _, name := range files {
+				add(name)
+				add(filepath.Dir(name))
+			}
+		}
+	}
+	for _, path := range extraCachePaths(wd) {
+		add(path)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWarmLoads(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"type Foo struct{}",
+		"",
+		"func NewFoo() *Foo { return &Foo{} }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"// +build wireinject",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func Init() *Foo {",
+		"\twire.Build(NewFoo)",
+		"\treturn nil",
+		"}",
+		"",
+	}, "\n"))
+
+	warm := NewWarmLoads()
+	env := append(os.Environ(), "GOWORK=off")
+	// loadApp loads the app package and reports whether the packages and
+	// their types were reused.
+	loadApp := func() (loads, types bool) {
+		t.Helper()
+		var msgs []string
+		ctx := WithDebug(WithWarmLoads(context.Background(), warm), func(msg string) {
+			msgs = append(msgs, msg)
+		})
+		info, errs := Load(ctx, root, env, "", []string{"./app"})
+		if len(errs) > 0 {
+			t.Fatalf("Load failed: %v", errs)
+		}
+		if len(info.Injectors) != 1 {
+			t.Fatalf("Load found %d injectors; want 1", len(info.Injectors))
+		}
+		for _, msg := range msgs {
+			loads = loads || strings.HasPrefix(msg, "reusing the packages loaded for ./app")
+			types = types || strings.HasPrefix(msg, "reusing the syntax and types of example.com/app")
+		}
+		return loads, types
+	}
+
+	if loads, types := loadApp(); loads || types {
+		t.Errorf("first load reused packages (loads %t, types %t)", loads, types)
+	}
+	if loads, types := loadApp(); !loads || !types {
+		t.Errorf("second load did not reuse packages (loads %t, types %t)", loads, types)
+	}
+
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"type Foo struct{ Name string }",
+		"",
+		"func NewFoo() *Foo { return &Foo{} }",
+		"",
+	}, "\n"))
+	if loads, _ := loadApp(); loads {
+		t.Error("load after a file changed reused packages")
+	}
+	if loads, _ := loadApp(); !loads {
+		t.Error("load after reloading did not reuse packages")
+	}
+
+	warm.Invalidate([]string{"example.com/other/..."})
+	if loads, _ := loadApp(); !loads {
+		t.Error("invalidating another module dropped the packages")
+	}
+	warm.Invalidate([]string{"example.com/app/..."})
+	if loads, _ := loadApp(); loads {
+		t.Error("load after Invalidate reused packages")
+	}
+
+	var nilWarm *WarmLoads
+	nilWarm.Invalidate([]string{"..."})
+	if ctx := context.Background(); WithWarmLoads(ctx, nil) != ctx {
+		t.Error("WithWarmLoads(nil) changed the context")
+	}
+}
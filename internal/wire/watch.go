@@ -0,0 +1,636 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// The defaults of WatchOptions, which are those of wire watch.
+const (
+	defaultPollInterval    = 250 * time.Millisecond
+	defaultMaxPollInterval = 4 * time.Second
+	defaultRescanInterval  = 2 * time.Second
+)
+
+// watchSettle is how long the watchers wait for a burst of file events,
+// such as an editor's atomic save, to finish before acting on it.
+const watchSettle = 200 * time.Millisecond
+
+// WatchOptions holds the options for Watch.
+type WatchOptions struct {
+	// PollInterval is how often Watch stats the watched files when
+	// filesystem notifications are unavailable. While nothing changes, the
+	// interval doubles up to MaxPollInterval.
+	PollInterval    time.Duration
+	MaxPollInterval time.Duration
+	// RescanInterval is how often polling looks for added or removed
+	// files, walking the tree only when a directory's modification time
+	// has changed.
+	RescanInterval time.Duration
+	// OnPoll, if not nil, is called with the reason filesystem
+	// notifications are unavailable when Watch falls back to polling.
+	OnPoll func(reason error)
+
+	// poll makes Watch poll even if notifications are available.
+	poll bool
+}
+
+// Watch calls onChange with the changed files whenever a Go file, or a
+// go.mod, go.sum, or go.work file, of the module rooted at root changes,
+// until ctx is done. It skips the directories the go command ignores and
+// nested modules, wire's own output, and the temporary and backup files
+// editors leave behind, and does not call onChange for changes that leave
+// every file's content as it was. It uses filesystem notifications if they
+// are available and polls otherwise. A nil opts uses the defaults.
+//
+// Watch returns when ctx is done, with ctx.Err().
+func Watch(ctx context.Context, root string, opts *WatchOptions, onChange func(changed []string)) error {
+	var o WatchOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = defaultPollInterval
+	}
+	if o.MaxPollInterval <= 0 {
+		o.MaxPollInterval = defaultMaxPollInterval
+	}
+	if o.RescanInterval <= 0 {
+		o.RescanInterval = defaultRescanInterval
+	}
+	if !o.poll {
+		err := watchWithFSNotify(ctx, root, onChange)
+		if err == nil || ctx.Err() != nil {
+			return ctx.Err()
+		}
+		debugf(ctx, "watch: fsnotify unavailable for %s, falling back to polling: %v", root, err)
+		if o.OnPoll != nil {
+			o.OnPoll(err)
+		}
+	}
+	return watchWithPolling(ctx, root, &o, onChange)
+}
+
+// watchWithFSNotify runs the watcher using native filesystem notifications.
+// It returns nil when ctx is done.
+func watchWithFSNotify(ctx context.Context, root string, onChange func([]string)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	if err := addWatchDirs(watcher, root); err != nil {
+		return err
+	}
+
+	state, _, err := scanWatchTree(root)
+	if err != nil {
+		return err
+	}
+	contents := newContentTracker(ctx, fileStatePaths(state))
+	changed := make(map[string]struct{})
+	timer := time.NewTimer(watchSettle)
+	if !timer.Stop() {
+		<-timer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return fmt.Errorf("watcher closed")
+			}
+			debugf(ctx, "watch: event %s", event)
+			if event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Remove|fsnotify.Rename) == 0 {
+				continue
+			}
+			if event.Op&fsnotify.Create != 0 {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if !shouldSkipDir(filepath.Base(event.Name)) && !isNestedModule(event.Name, root) {
+						_ = addWatchDirs(watcher, event.Name)
+					}
+					continue
+				}
+			}
+			if !isWatchedFile(event.Name) {
+				debugf(ctx, "watch: ignoring %s", event.Name)
+				continue
+			}
+			changed[event.Name] = struct{}{}
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(watchSettle)
+		case <-timer.C:
+			if len(changed) == 0 {
+				continue
+			}
+			paths := make([]string, 0, len(changed))
+			for path := range changed {
+				paths = append(paths, path)
+			}
+			for key := range changed {
+				delete(changed, key)
+			}
+			if paths = contents.changed(paths); len(paths) == 0 {
+				debugf(ctx, "watch: events left every file's content unchanged")
+				continue
+			}
+			onChange(paths)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return fmt.Errorf("watcher closed")
+			}
+			return err
+		}
+	}
+}
+
+// addWatchDirs registers watchers for all directories under root.
+func addWatchDirs(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if shouldSkipDir(d.Name()) || isNestedModule(path, root) {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// watchWithPolling runs the watcher by polling the files under root. It
+// returns ctx.Err() when ctx is done.
+func watchWithPolling(ctx context.Context, root string, opts *WatchOptions, onChange func([]string)) error {
+	state, dirs, err := scanWatchTree(root)
+	if err != nil {
+		debugf(ctx, "watch: initial scan of %s failed: %v", root, err)
+	}
+	contents := newContentTracker(ctx, fileStatePaths(state))
+	// scan walks the tree again, reporting how long that took.
+	scan := func(label string) (map[string]fileState, map[string]time.Time, error) {
+		start := time.Now()
+		files, dirs, err := scanWatchTree(root)
+		logTiming(ctx, label, start)
+		return files, dirs, err
+	}
+	// settled waits for an editor's save to finish, rescans, and reports
+	// the changed files whose content actually differs.
+	settled := func(changed []string) []string {
+		debugf(ctx, "watch: poll found %d changed files under %s", len(changed), root)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(watchSettle):
+		}
+		if next, nextDirs, err := scan("watch.scan"); err == nil {
+			changed = append(changed, diffFileState(state, next)...)
+			state, dirs = next, nextDirs
+		}
+		return contents.changed(dedupePaths(changed))
+	}
+
+	// The poll backs off while nothing changes, since statting every file
+	// of a large repository is expensive, and returns to the base interval
+	// as soon as something does.
+	interval := opts.PollInterval
+	pollTimer := time.NewTimer(interval)
+	rescanTicker := time.NewTicker(opts.RescanInterval)
+	defer pollTimer.Stop()
+	defer rescanTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-pollTimer.C:
+			pollStart := time.Now()
+			changed := updateFileState(state)
+			logTiming(ctx, "watch.poll", pollStart)
+			if len(changed) == 0 {
+				if next := nextPollInterval(interval, opts.MaxPollInterval); next != interval {
+					debugf(ctx, "watch: no changes under %s, polling every %s", root, next)
+					interval = next
+				}
+				pollTimer.Reset(interval)
+				continue
+			}
+			interval = opts.PollInterval
+			if changed = settled(changed); len(changed) > 0 {
+				onChange(changed)
+				state, dirs, _ = scan("watch.scan")
+			}
+			pollTimer.Reset(interval)
+		case <-rescanTicker.C:
+			rescanStart := time.Now()
+			if !dirsChanged(dirs) {
+				// No entry was added, removed, or renamed, so the
+				// file set is unchanged.
+				logTiming(ctx, "watch.rescan", rescanStart)
+				continue
+			}
+			newState, newDirs, err := scanWatchTree(root)
+			logTiming(ctx, "watch.rescan", rescanStart)
+			if err != nil {
+				debugf(ctx, "watch: rescan of %s failed: %v", root, err)
+				continue
+			}
+			changed := diffFileState(state, newState)
+			state, dirs = newState, newDirs
+			if len(changed) == 0 {
+				continue
+			}
+			// The next poll happens at the base interval again.
+			interval = opts.PollInterval
+			if changed = settled(changed); len(changed) == 0 {
+				continue
+			}
+			onChange(changed)
+			state, dirs, _ = scan("watch.scan")
+		}
+	}
+}
+
+// nextPollInterval returns the interval of the poll after one that found no
+// changes: twice cur, but no more than limit, and never less than cur.
+func nextPollInterval(cur, limit time.Duration) time.Duration {
+	next := cur * 2
+	if next > limit {
+		next = limit
+	}
+	if next < cur {
+		return cur
+	}
+	return next
+}
+
+// fileState stores file metadata for polling-based change detection.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// scanWatchTree recursively collects the metadata of the watched files
+// under root, along with the modification time of each watched directory.
+func scanWatchTree(root string) (map[string]fileState, map[string]time.Time, error) {
+	state := make(map[string]fileState)
+	dirs := make(map[string]time.Time)
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if shouldSkipDir(d.Name()) || isNestedModule(path, root) {
+				return filepath.SkipDir
+			}
+			if info, err := d.Info(); err == nil {
+				dirs[path] = info.ModTime()
+			}
+			return nil
+		}
+		if !isWatchedFile(path) {
+			return nil
+		}
+		info, infoErr := d.Info()
+		if infoErr != nil {
+			return nil
+		}
+		state[path] = fileState{
+			modTime: info.ModTime(),
+			size:    info.Size(),
+		}
+		return nil
+	})
+	return state, dirs, err
+}
+
+// dirsChanged reports whether any of dirs has been removed or modified
+// since it was scanned. Adding, removing, or renaming an entry updates the
+// modification time of its directory, so if none changed, neither did the
+// set of files under them.
+func dirsChanged(dirs map[string]time.Time) bool {
+	if len(dirs) == 0 {
+		return true
+	}
+	for dir, modTime := range dirs {
+		info, err := os.Stat(dir)
+		if err != nil || !info.ModTime().Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// updateFileState returns the paths that changed since the last poll.
+func updateFileState(state map[string]fileState) []string {
+	var changed []string
+	for path, old := range state {
+		info, err := os.Stat(path)
+		if err != nil {
+			delete(state, path)
+			changed = append(changed, path)
+			continue
+		}
+		next := fileState{modTime: info.ModTime(), size: info.Size()}
+		if next.modTime != old.modTime || next.size != old.size {
+			state[path] = next
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
+
+// diffFileState returns the paths that changed between two snapshots.
+func diffFileState(prev, next map[string]fileState) []string {
+	var changed []string
+	for path, old := range prev {
+		cur, ok := next[path]
+		if !ok {
+			changed = append(changed, path)
+			continue
+		}
+		if old.modTime != cur.modTime || old.size != cur.size {
+			changed = append(changed, path)
+		}
+	}
+	for path := range next {
+		if _, ok := prev[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
+
+// fileStatePaths returns the paths in a snapshot.
+func fileStatePaths(state map[string]fileState) []string {
+	paths := make([]string, 0, len(state))
+	for path := range state {
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// dedupePaths returns paths without duplicates, keeping the first of each.
+func dedupePaths(paths []string) []string {
+	seen := make(map[string]bool, len(paths))
+	out := paths[:0]
+	for _, path := range paths {
+		if !seen[path] {
+			seen[path] = true
+			out = append(out, path)
+		}
+	}
+	return out
+}
+
+// shouldSkipDir reports whether a directory should be ignored for watching.
+func shouldSkipDir(name string) bool {
+	if name == "vendor" {
+		return true
+	}
+	return strings.HasPrefix(name, ".")
+}
+
+// isNestedModule reports whether dir is the root of a module other than the
+// one at root. Its files belong to that module, so they are watched by its
+// own watcher, if at all.
+func isNestedModule(dir, root string) bool {
+	if dir == root {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(dir, "go.mod"))
+	return err == nil
+}
+
+// generatedFileSuffixes are the names of the files wire writes, which may
+// carry an output file prefix. Changes to them never trigger a regeneration.
+var generatedFileSuffixes = []string{
+	"wire_gen.go",
+	"wire_gen_test.go",
+	"wire_gen_external_test.go",
+	"wire_example_test.go",
+}
+
+// isWatchedFile reports whether a path should trigger a regeneration: a Go
+// file, or a go.mod, go.sum, or go.work file. Besides wire's own output, it
+// ignores the files the go command ignores and the temporary and backup
+// files editors leave behind while saving, some of which end in .go.
+func isWatchedFile(path string) bool {
+	name := filepath.Base(path)
+	switch name {
+	case "go.mod", "go.sum", "go.work", "go.work.sum":
+		return true
+	}
+	if !strings.HasSuffix(name, ".go") {
+		return false
+	}
+	for _, suffix := range generatedFileSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return false
+		}
+	}
+	switch {
+	case strings.HasPrefix(name, "."), strings.HasPrefix(name, "_"):
+		// Hidden files, such as Emacs lock files (.#main.go) and macOS
+		// resource forks (._main.go), which go build ignores too.
+		return false
+	case strings.HasPrefix(name, "#"), strings.Contains(name, "~"):
+		// Emacs auto-save files and backups.
+		return false
+	case strings.Contains(name, ".go."), strings.Contains(name, "___jb_"):
+		// Atomic-save temporaries named after the file being saved, such
+		// as main.go.tmp1234.go, and JetBrains' main.go___jb_tmp___.go.
+		return false
+	}
+	return true
+}
+
+// contentTracker remembers the content of the watched files, so that event
+// bursts that leave every file as it was, such as an editor renaming a file
+// away and writing it back unchanged, do not trigger a regeneration.
+type contentTracker struct {
+	ctx  context.Context
+	sums map[string][sha256.Size]byte
+}
+
+// newContentTracker records the current content of paths.
+func newContentTracker(ctx context.Context, paths []string) *contentTracker {
+	t := &contentTracker{ctx: ctx, sums: make(map[string][sha256.Size]byte, len(paths))}
+	t.changed(paths)
+	return t
+}
+
+// changed records the current content of paths and returns those whose
+// content differs from what was recorded before. A file that is missing
+// now and was not recorded before is unchanged.
+func (t *contentTracker) changed(paths []string) []string {
+	var changed []string
+	for _, path := range paths {
+		prev, known := t.sums[path]
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if known {
+				delete(t.sums, path)
+				changed = append(changed, path)
+			}
+			continue
+		}
+		if isWireOutput(data) {
+			// Output named with -output_file_name or a prefix.
+			debugf(t.ctx, "watch: ignoring wire output %s", path)
+			continue
+		}
+		sum := sha256.Sum256(data)
+		t.sums[path] = sum
+		if !known || sum != prev {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
+
+// isWireOutput reports whether data is the source of a file generated by
+// wire, whose marker comes after any header and before the package clause.
+func isWireOutput(data []byte) bool {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if string(line) == "// Code generated by Wire. DO NOT EDIT." {
+			return true
+		}
+		if bytes.HasPrefix(line, []byte("package ")) {
+			return false
+		}
+	}
+	return false
+}
+
+// PackageRemoved reports whether pattern, relative to wd, names a directory,
+// without a wildcard, that does not exist or holds no Go files but wire's
+// output. Import paths and wildcards are left to the go command.
+func PackageRemoved(wd, pattern string) bool {
+	if strings.Contains(pattern, "...") || !isLocalPattern(pattern) {
+		return false
+	}
+	dir := pattern
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(wd, dir)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return os.IsNotExist(err)
+	}
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".go") && isWatchedFile(filepath.Join(dir, e.Name())) {
+			return false
+		}
+	}
+	return true
+}
+
+// isLocalPattern reports whether pattern is a directory path rather than an
+// import path, as the go command decides.
+func isLocalPattern(pattern string) bool {
+	return filepath.IsAbs(pattern) || pattern == "." || pattern == ".." ||
+		strings.HasPrefix(pattern, "./") || strings.HasPrefix(pattern, "../")
+}
+
+// WatchRoots returns the directories to Watch for changes to pkgs, the
+// packages matched relative to wd with the build tags tags: in a go.work
+// workspace, the root of each module holding some of them, with the
+// packages each holds in byModule, or else the root of the module holding
+// wd, with a nil byModule. If listing the modules fails, it still returns
+// that root, or wd, along with the error.
+func WatchRoots(ctx context.Context, wd string, env []string, tags string, pkgs []string) (roots []string, byModule map[string][]string, err error) {
+	values, err := goEnvValues(ctx, wd, env, "GOMOD", "GOWORK")
+	if err != nil {
+		return []string{wd}, nil, err
+	}
+	root := wd
+	if gomod := values["GOMOD"]; gomod != "" && gomod != os.DevNull {
+		root = filepath.Dir(gomod)
+	}
+	if gowork := values["GOWORK"]; gowork == "" || gowork == "off" {
+		return []string{root}, nil, nil
+	}
+	modules, err := mainModuleDirs(ctx, wd, env)
+	if err != nil || len(modules) < 2 {
+		return []string{root}, nil, err
+	}
+	byModule, err = PackagesByModule(ctx, wd, env, tags, pkgs)
+	if err != nil {
+		return []string{root}, nil, err
+	}
+	for _, dir := range modules {
+		if len(byModule[dir]) > 0 {
+			roots = append(roots, dir)
+		}
+	}
+	if len(roots) < 2 {
+		return []string{root}, nil, nil
+	}
+	return roots, byModule, nil
+}
+
+// PackagesByModule groups the packages matching patterns, relative to wd
+// and with the build tags tags, by the directory of the module that
+// contains them.
+func PackagesByModule(ctx context.Context, wd string, env []string, tags string, patterns []string) (map[string][]string, error) {
+	args := []string{"list", "-e", "-tags=" + strings.TrimSpace("wireinject "+tags), "-f", "{{.ImportPath}}\t{{with .Module}}{{.Dir}}{{end}}", "--"}
+	cmd := exec.CommandContext(ctx, "go", append(args, patterns...)...)
+	cmd.Dir = wd
+	cmd.Env = env
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	byModule := make(map[string][]string)
+	for _, line := range strings.Split(string(out), "\n") {
+		if path, dir, ok := strings.Cut(line, "\t"); ok && dir != "" {
+			byModule[dir] = append(byModule[dir], path)
+		}
+	}
+	return byModule, nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIsWatchedFile(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "main.go", want: true},
+		{name: "go.mod", want: true},
+		{name: "go.sum", want: true},
+		{name: "go.work", want: true},
+		{name: "README.md", want: false},
+		{name: "wire_gen.go", want: false},
+		{name: "dev_wire_gen_test.go", want: false},
+		{name: "wire_example_test.go", want: false},
+		{name: ".#main.go", want: false},
+		{name: "_main.go", want: false},
+		{name: "#main.go#", want: false},
+		{name: "main.go~", want: false},
+		{name: "main.go.tmp1234.go", want: false},
+		{name: "main.go___jb_tmp___.go", want: false},
+	}
+	for _, test := range tests {
+		if got := isWatchedFile(filepath.Join("app", test.name)); got != test.want {
+			t.Errorf("isWatchedFile(%q) = %t; want %t", test.name, got, test.want)
+		}
+	}
+}
+
+func TestPackageRemoved(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "live", "live.go"), "package live\n")
+	writeFile(t, filepath.Join(root, "gen", "wire_gen.go"), "package gen\n")
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{pattern: "./live", want: false},
+		{pattern: "./gen", want: true},
+		{pattern: "./missing", want: true},
+		{pattern: "./missing/...", want: false},
+		{pattern: "example.com/missing", want: false},
+	}
+	for _, test := range tests {
+		if got := PackageRemoved(root, test.pattern); got != test.want {
+			t.Errorf("PackageRemoved(%q) = %t; want %t", test.pattern, got, test.want)
+		}
+	}
+}
+
+func TestWatchPolling(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/app\n")
+	writeFile(t, filepath.Join(root, "app.go"), "package app\n")
+	writeFile(t, filepath.Join(root, "old.go"), "package app\n")
+	writeFile(t, filepath.Join(root, "nested", "go.mod"), "module example.com/nested\n")
+	writeFile(t, filepath.Join(root, "nested", "nested.go"), "package nested\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan []string, 10)
+	done := make(chan error, 1)
+	opts := &WatchOptions{
+		PollInterval:    10 * time.Millisecond,
+		MaxPollInterval: 20 * time.Millisecond,
+		RescanInterval:  10 * time.Millisecond,
+		poll:            true,
+	}
+	go func() {
+		done <- Watch(ctx, root, opts, func(changed []string) { changes <- changed })
+	}()
+	// next returns the files of the next change, or nil if there is none
+	// within wait.
+	next := func(wait time.Duration) []string {
+		t.Helper()
+		select {
+		case changed := <-changes:
+			return changed
+		case err := <-done:
+			t.Fatalf("Watch returned early: %v", err)
+		case <-time.After(wait):
+		}
+		return nil
+	}
+	// Give the first scan time to record the files.
+	time.Sleep(50 * time.Millisecond)
+
+	// Editor temporaries, wire's output, and nested modules are ignored.
+	writeFile(t, filepath.Join(root, "app.go~"), "package app\n")
+	writeFile(t, filepath.Join(root, "app.go.tmp1234.go"), "package app\n")
+	writeFile(t, filepath.Join(root, "wire_gen.go"), "package app\n")
+	writeFile(t, filepath.Join(root, "nested", "nested.go"), "package nested // changed\n")
+	if changed := next(time.Second); changed != nil {
+		t.Fatalf("Watch reported %v; want no change", changed)
+	}
+
+	writeFile(t, filepath.Join(root, "app.go"), "package app // changed\n")
+	if changed, want := next(time.Minute), []string{filepath.Join(root, "app.go")}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("Watch reported %v after an edit; want %v", changed, want)
+	}
+	if err := os.Remove(filepath.Join(root, "old.go")); err != nil {
+		t.Fatal(err)
+	}
+	if changed, want := next(time.Minute), []string{filepath.Join(root, "old.go")}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("Watch reported %v after a deletion; want %v", changed, want)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Watch returned %v; want %v", err, context.Canceled)
+		}
+	case <-time.After(time.Minute):
+		t.Fatal("Watch did not return after its context was canceled")
+	}
+}
@@ -21,11 +21,13 @@ import (
 	"context"
 	"fmt"
 	"go/ast"
+	"go/build/constraint"
 	"go/printer"
 	"go/token"
 	"go/types"
 	"io/ioutil"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -47,24 +49,140 @@ type GenerateResult struct {
 	// Content is the gofmt'd source code that was generated. May be nil if
 	// there were errors during generation.
 	Content []byte
+	// ExamplePath is the path where the generated examples should be
+	// written. Empty unless GenerateOptions.Examples is set.
+	ExamplePath string
+	// ExampleContent is the gofmt'd source of a test file holding a Go doc
+	// example for each injector. May be nil if there are no injectors.
+	ExampleContent []byte
 	// Errs is a slice of errors identified during generation.
-	Errs []error
+	Errs ErrorList
+	// Warnings lists problems that did not stop generation, such as a
+	// package whose wireinject files are all excluded by build tags.
+	Warnings ErrorList
+	// Cached reports whether Content was read from the cache rather than
+	// generated.
+	Cached bool
+	// Skipped reports whether the package was left alone because it has
+	// nothing to generate: it declares no injectors, or none of those
+	// GenerateOptions.Injectors names. Content is then empty and Errs is
+	// nil, which tells a skipped package apart from one that generated
+	// nothing because of errors.
+	Skipped bool
 }
 
-// Commit writes the generated file to disk.
+// Commit writes the generated files to disk.
 func (gen GenerateResult) Commit() error {
 	if len(gen.Content) == 0 {
 		return nil
 	}
-	return ioutil.WriteFile(gen.OutputPath, gen.Content, 0666)
+	if err := ioutil.WriteFile(gen.OutputPath, gen.Content, 0666); err != nil {
+		return err
+	}
+	if len(gen.ExampleContent) == 0 {
+		return nil
+	}
+	return ioutil.WriteFile(gen.ExamplePath, gen.ExampleContent, 0666)
 }
 
 // GenerateOptions holds options for Generate.
 type GenerateOptions struct {
 	// Header will be inserted at the start of each generated file.
-	Header           []byte
+	Header []byte
+	// PackageHeader, if not empty, names the file whose content is inserted
+	// at the start of each package's generated files instead of Header,
+	// so that packages of modules with different license headers can be
+	// generated together. An absolute path names the same file for every
+	// package. A relative path, such as "header.txt", is looked up in the
+	// package's directory and then in each parent directory up to the
+	// package's module root, and the nearest file wins. Either may be a
+	// text/template executed with .Dir, the package's directory,
+	// .ModuleDir, its module root, .Package, its name, and .ImportPath.
+	// Packages for which no file is found get Header. Runs with a
+	// PackageHeader do not use or write the manifest of the package
+	// results, since it does not track the header files.
+	PackageHeader    string
 	PrefixOutputFile string
-	Tags             string
+	// OutputFileName, if not empty, is a text/template for the name of
+	// the generated file, such as "{{.Package}}_wire.gen.go", executed
+	// with the package name as .Package. PrefixOutputFile is prepended
+	// to the result. Test outputs are named after it, with _test.go or
+	// _external_test.go in place of .go.
+	OutputFileName string
+	Tags           string
+	// AutoContext lets an injector's context.Context argument take
+	// precedence over any context.Context provided by its provider sets, so
+	// that every provider that accepts a context receives the injector's
+	// ctx instead of reporting a conflicting binding.
+	AutoContext bool
+	// StableOrder breaks ties in the order of an injector's calls by the
+	// name of the type each call builds, instead of by the order providers
+	// are reached from the injector's output, so that the generated code
+	// only moves where the dependencies themselves change. The output and
+	// the values built for wire.After hooks keep their places.
+	StableOrder bool
+	// SourceMap precedes each statement of an injector that builds a value
+	// with a comment mapping it back to the provider, value, or field it
+	// comes from, such as
+	//
+	//	//wire:source ../db/db.go:25:6 example.com/app/db.NewDB
+	//
+	// so that tools can translate positions in stack traces and coverage
+	// reports of the generated file to the providers. The path is relative
+	// to the directory of the generated file. A value maps to the argument
+	// of its wire.Value call rather than to the call itself.
+	SourceMap bool
+	// AutoNames is the regular expression that selects the functions
+	// wire.AutoSet treats as providers by name. If empty, it is "^New".
+	AutoNames string
+	// DebugDir is the directory where generated source that fails to
+	// format is saved for inspection. If empty, a directory under the
+	// system's temporary directory is used.
+	DebugDir string
+	// DebugSnapshot, if not empty, is a directory where the loaded
+	// packages are saved when generation fails, along with the options
+	// and errors of the run, so that the failure can be reproduced with
+	// Replay without access to the original repository.
+	DebugSnapshot string
+	// Injectors, if not empty, restricts regeneration to the injectors with
+	// these names. The code of the package's other injectors is kept from
+	// the existing output file, and packages without any of the named
+	// injectors produce no output. Results are not cached.
+	Injectors []string
+	// Partial generates a package's other injectors when some of them
+	// fail. The failing injectors keep their previously generated code, if
+	// any, and their errors are reported in the package's result along
+	// with the content. Partial results are not cached.
+	Partial bool
+	// Hermetic fails generation if its result could depend on state outside
+	// the repository: go command settings such as GOFLAGS that are not part
+	// of the cache key, or packages loaded from outside the main modules,
+	// the module cache, and GOROOT. The cached result of a previous run is
+	// not reused without checking again.
+	Hermetic bool
+	// JSONManifest, if not empty, is the path of a JSON file mapping the
+	// import paths of packages to their files, relative to the file's
+	// directory, as written by a build system such as Bazel. Packages are
+	// then loaded from the listed files instead of with the go command, and
+	// the patterns passed to Generate are the import paths of the packages
+	// to generate, or all listed packages if there are none. The manifest
+	// must list every package outside the standard library, including
+	// github.com/goforj/wire. Test injectors, //wire:tags, and Hermetic are
+	// not supported, and the per-pattern result manifest is not used.
+	JSONManifest string
+	// Examples additionally generates a wire_example_test.go file per
+	// package with a Go doc example for each injector, so that godoc shows
+	// how the package's values are constructed.
+	Examples bool
+	// Overlay maps absolute file paths to contents that are loaded in
+	// place of the files on disk, or as additional files if they do not
+	// exist, so that unsaved editor buffers can be generated from. Results
+	// are not cached. It is not used with JSONManifest.
+	Overlay map[string][]byte
+	// OnResult, if not nil, is called with each package's result as soon
+	// as it is ready, before Generate returns. Results are reported in the
+	// same order as they are returned.
+	OnResult func(GenerateResult)
 }
 
 // Generate performs dependency injection for the packages that match the given
@@ -78,35 +196,196 @@ type GenerateOptions struct {
 // In case of duplicate environment variables, the last one in the list
 // takes precedence.
 //
+// A package whose wireinject files have //wire:variants directives is
+// generated once per variant, with a result for each; the results of all
+// but the first variant follow those of the other packages.
+//
 // Generate may return one or more errors if it failed to load the packages.
-func Generate(ctx context.Context, wd string, env []string, patterns []string, opts *GenerateOptions) ([]GenerateResult, []error) {
+// Each error describes its package, position, and kind; use Errors for a
+// plain []error.
+func Generate(ctx context.Context, wd string, env []string, patterns []string, opts *GenerateOptions) ([]GenerateResult, ErrorList) {
 	if opts == nil {
 		opts = &GenerateOptions{}
 	}
-	if cached, ok := readManifestResults(wd, env, patterns, opts); ok {
-		return cached, nil
+	if opts.OutputFileName != "" {
+		// Report a bad template once rather than for every package.
+		if _, err := outputFileNameFor(opts.PrefixOutputFile, opts.OutputFileName, "main"); err != nil {
+			return nil, newErrorList("", []error{err})
+		}
+	}
+	if opts.AutoNames != "" {
+		if _, err := regexp.Compile(opts.AutoNames); err != nil {
+			return nil, newErrorList("", []error{fmt.Errorf("invalid AutoNames expression: %v", err)})
+		}
+	}
+	if opts.PackageHeader != "" {
+		if _, err := parsePackageHeader(opts.PackageHeader); err != nil {
+			return nil, newErrorList("", []error{err})
+		}
+	}
+	ctx = withOverlay(ctx, opts.Overlay)
+	manifestStart := time.Now()
+	// Hermetic runs must load the packages to check them.
+	if useManifest(opts) && !opts.Hermetic {
+		cached, ok := readManifestResults(wd, env, patterns, opts)
+		logTiming(ctx, "generate.manifest_read", manifestStart)
+		if ok {
+			debugf(ctx, "manifest for %s is current, using %d cached results", strings.Join(patterns, " "), len(cached))
+			logTiming(ctx, "generate.manifest_hit", manifestStart)
+			for _, res := range cached {
+				opts.report(res)
+			}
+			return cached, nil
+		}
 	}
+	debugf(ctx, "no current manifest for %s", strings.Join(patterns, " "))
 	loadStart := time.Now()
-	pkgs, loader, errs := load(ctx, wd, env, opts.Tags, patterns)
+	var pkgs []*packages.Package
+	var loader *lazyLoader
+	var errs []error
+	if opts.JSONManifest != "" {
+		pkgs, loader, errs = loadJSONManifest(ctx, opts.JSONManifest, opts.Tags, patterns)
+	} else {
+		pkgs, loader, errs = load(withVariantErrors(ctx), wd, env, opts.Tags, patterns)
+	}
 	logTiming(ctx, "generate.load", loadStart)
 	if len(errs) > 0 {
-		return nil, errs
+		return nil, newErrorList("", errs)
+	}
+	if opts.Hermetic && opts.JSONManifest == "" {
+		if errs := checkHermetic(ctx, wd, env, pkgs); len(errs) > 0 {
+			return nil, newErrorList("", errs)
+		}
 	}
 	generated := make([]GenerateResult, len(pkgs))
+	shared := newSharedLoad(pkgs, loader, opts)
+	var variantGenerated, testGenerated []GenerateResult
+	retagged, warned := false, false
 	for i, pkg := range pkgs {
-		generated[i] = generateForPackage(ctx, pkg, loader, opts)
+		if opts.JSONManifest == "" {
+			variants, err := packageVariants(pkg)
+			if err != nil {
+				generated[i] = GenerateResult{PkgPath: pkg.PkgPath}
+				generated[i].Errs.add(pkg.PkgPath, err)
+				opts.report(generated[i])
+				continue
+			}
+			if len(variants) > 0 {
+				retagged = true
+				results := generateVariants(ctx, wd, env, opts, pkg, loader.fset, variants)
+				generated[i] = results[0]
+				variantGenerated = append(variantGenerated, results[1:]...)
+				continue
+			}
+		}
+		var tagged *packages.Package
+		var tagLoader *lazyLoader
+		var tags string
+		var errs []error
+		if opts.JSONManifest == "" {
+			// The build system that wrote a JSON manifest chose the files.
+			tagged, tagLoader, tags, errs = reloadWithTags(ctx, wd, env, opts.Tags, pkg, loader.fset)
+		}
+		pkgLoader, pkgOpts, pkgShared := loader, opts, shared
+		if len(errs) > 0 {
+			generated[i] = GenerateResult{PkgPath: pkg.PkgPath}
+			generated[i].Errs.add(pkg.PkgPath, errs...)
+			opts.report(generated[i])
+			continue
+		} else if tagged == nil {
+			tagged, tags = pkg, opts.Tags
+		} else {
+			retagged = true
+			pkgLoader, pkgShared = tagLoader, nil
+			tagOpts := *opts
+			tagOpts.Tags = tags
+			pkgOpts = &tagOpts
+		}
+		pkgOpts, err := withPackageHeader(tagged, pkgOpts)
+		if err != nil {
+			generated[i] = GenerateResult{PkgPath: pkg.PkgPath}
+			generated[i].Errs.add(pkg.PkgPath, err)
+			opts.report(generated[i])
+			continue
+		}
+		generated[i] = generateForPackage(ctx, tagged, pkgLoader, pkgOpts, pkgShared)
+		if res := &generated[i]; res.Skipped && len(opts.Injectors) == 0 {
+			res.Warnings = append(res.Warnings, excludedInjectorWarnings(tagged, env, tags)...)
+		}
+		warned = warned || len(generated[i].Warnings) > 0
+		if res := &generated[i]; len(res.Errs) > 0 {
+			// A leak across the wireinject tag explains the "undefined"
+			// errors it causes.
+			res.Warnings = append(res.Warnings, generatedLeakWarnings(tagged)...)
+		}
+		opts.report(generated[i])
+		if !hasTestInjectorFiles(packageDir(tagged)) {
+			continue
+		}
+		variants, errs := pkgLoader.loadTests(tagged.PkgPath)
+		if len(errs) > 0 {
+			res := GenerateResult{PkgPath: tagged.PkgPath}
+			res.Errs.add(tagged.PkgPath, errs...)
+			testGenerated = append(testGenerated, res)
+			opts.report(res)
+			continue
+		}
+		for _, variant := range variants {
+			res := generateForPackage(ctx, variant, pkgLoader, pkgOpts, nil)
+			if res.Skipped {
+				continue
+			}
+			testGenerated = append(testGenerated, res)
+			opts.report(res)
+		}
+	}
+	generated = append(generated, variantGenerated...)
+	generated = append(generated, testGenerated...)
+	if opts.DebugSnapshot != "" && !allGeneratedOK(generated) {
+		if err := writeSnapshot(ctx, opts.DebugSnapshot, wd, env, pkgs, opts, generated); err != nil {
+			for i := range generated {
+				if len(generated[i].Errs) > 0 {
+					generated[i].Errs.add(generated[i].PkgPath, fmt.Errorf("failed to write debug snapshot: %v", err))
+					break
+				}
+			}
+		}
 	}
-	if allGeneratedOK(generated) {
+	// The manifest is keyed by the global options, so it cannot describe
+	// packages generated with extra tags, nor test variants, which it does
+	// not load. It does not record warnings.
+	if useManifest(opts) && !retagged && !warned && len(testGenerated) == 0 && allGeneratedOK(generated) {
+		manifestWriteStart := time.Now()
 		writeManifest(wd, env, patterns, opts, pkgs)
+		logTiming(ctx, "generate.manifest_write", manifestWriteStart)
 	}
 	return generated, nil
 }
 
+// useManifest reports whether the manifest of the package results can
+// describe a run with opts. It cannot when the output depends on the
+// existing files, on unsaved contents, or on header files, which the
+// manifest does not track, or when a build system chose the files.
+func useManifest(opts *GenerateOptions) bool {
+	return len(opts.Injectors) == 0 && len(opts.Overlay) == 0 && opts.JSONManifest == "" && opts.PackageHeader == ""
+}
+
+// report passes res to the OnResult callback, if any.
+func (opts *GenerateOptions) report(res GenerateResult) {
+	if opts.OnResult != nil {
+		opts.OnResult(res)
+	}
+}
+
 // generateInjectors generates the injectors for a given package.
 func generateInjectors(oc *objectCache, g *gen, pkg *packages.Package) (injectorFiles []*ast.File, _ []error) {
 	injectorFiles = make([]*ast.File, 0, len(pkg.Syntax))
 	ec := new(errorCollector)
+	cgoFiles := cgoGeneratedFiles(pkg)
 	for _, f := range pkg.Syntax {
+		if g.tests && !strings.HasSuffix(g.pkg.Fset.File(f.Pos()).Name(), "_test.go") {
+			continue
+		}
 		for _, decl := range f.Decls {
 			fn, ok := decl.(*ast.FuncDecl)
 			if !ok {
@@ -127,42 +406,80 @@ func generateInjectors(oc *objectCache, g *gen, pkg *packages.Package) (injector
 				g.p("// Injectors from %s:\n\n", name)
 				injectorFiles = append(injectorFiles, f)
 			}
-			sig := pkg.TypesInfo.ObjectOf(fn.Name).Type().(*types.Signature)
-			ins, _, err := injectorFuncSignature(sig)
-			if err != nil {
-				if w, ok := err.(*wireErr); ok {
-					ec.add(notePosition(w.position, fmt.Errorf("inject %s: %v", fn.Name.Name, w.error)))
-				} else {
-					ec.add(notePosition(g.pkg.Fset.Position(fn.Pos()), fmt.Errorf("inject %s: %v", fn.Name.Name, err)))
+			if errs := g.generateInjector(oc, pkg, f, fn, buildCall); len(errs) > 0 {
+				ec.add(errs...)
+				if g.partial && g.keepPrevious(fn.Name.Name) {
+					g.emitted++
 				}
-				continue
-			}
-			injectorArgs := &InjectorArgs{
-				Name:  fn.Name.Name,
-				Tuple: ins,
-				Pos:   fn.Pos(),
 			}
-			set, errs := oc.processNewSet(pkg.TypesInfo, pkg.PkgPath, buildCall, injectorArgs, "")
-			if len(errs) > 0 {
-				ec.add(notePositionAll(g.pkg.Fset.Position(fn.Pos()), errs)...)
+		}
+
+		for _, impt := range f.Imports {
+			if impt.Name == nil || impt.Name.Name != "_" {
 				continue
 			}
-			if errs := g.inject(fn.Pos(), fn.Name.Name, sig, set, fn.Doc); len(errs) > 0 {
-				ec.add(errs...)
+			if impt.Path.Value == `"unsafe"` && cgoFiles[filepath.Clean(g.pkg.Fset.File(f.Pos()).Name())] {
+				// Added by cgo, not by the user.
 				continue
 			}
+			g.anonImports[impt.Path.Value] = true
 		}
+	}
+	if len(ec.errors) > 0 && !g.partial {
+		return nil, ec.errors
+	}
+	return injectorFiles, ec.errors
+}
 
-		for _, impt := range f.Imports {
-			if impt.Name != nil && impt.Name.Name == "_" {
-				g.anonImports[impt.Path.Value] = true
-			}
+// generateInjector generates fn, an injector declared in f whose body
+// contains buildCall.
+func (g *gen) generateInjector(oc *objectCache, pkg *packages.Package, f *ast.File, fn *ast.FuncDecl, buildCall *ast.CallExpr) []error {
+	if err := missingWireinjectError(g.pkg.Fset, f, fn); err != nil {
+		return []error{err}
+	}
+	if g.only != nil {
+		if g.only[fn.Name.Name] {
+			g.regenerated++
+		} else if g.keepPrevious(fn.Name.Name) {
+			g.emitted++
+			return nil
 		}
 	}
-	if len(ec.errors) > 0 {
-		return nil, ec.errors
+	sig := pkg.TypesInfo.ObjectOf(fn.Name).Type().(*types.Signature)
+	if isPopulateCall(pkg.TypesInfo, buildCall) {
+		if errs := g.generatePopulate(oc, pkg, fn, sig, buildCall); len(errs) > 0 {
+			return errs
+		}
+		g.emitted++
+		return nil
 	}
-	return injectorFiles, nil
+	ins, out, err := injectorFuncSignature(sig)
+	if err != nil {
+		if w, ok := err.(*wireErr); ok {
+			return []error{notePosition(w.position, fmt.Errorf("inject %s: %w", fn.Name.Name, w.error))}
+		}
+		return []error{notePosition(g.pkg.Fset.Position(fn.Pos()), fmt.Errorf("inject %s: %w", fn.Name.Name, err))}
+	}
+	injectorArgs := &InjectorArgs{
+		Name:  fn.Name.Name,
+		Tuple: ins,
+		Pos:   fn.Pos(),
+	}
+	set, errs := oc.processNewSet(pkg.TypesInfo, pkg.PkgPath, buildCall, injectorArgs, "")
+	if len(errs) == 0 && out.out == nil && !set.buildsForEffect() {
+		return []error{noResultsError(g.pkg.Fset, pkg, fn, set)}
+	}
+	if len(errs) == 0 {
+		errs = oc.addFakes(set, out.out)
+	}
+	if len(errs) > 0 {
+		return notePositionAll(g.pkg.Fset.Position(fn.Pos()), errs)
+	}
+	if errs := g.inject(fn.Pos(), fn.Name.Name, sig, set, fn.Doc); len(errs) > 0 {
+		return errs
+	}
+	g.emitted++
+	return nil
 }
 
 // copyNonInjectorDecls copies any non-injector declarations from the
@@ -213,6 +530,35 @@ type gen struct {
 	imports     map[string]importInfo
 	anonImports map[string]bool
 	values      map[ast.Expr]string
+	// shared maps the providers passed to wire.Shared, by sharedKey, to the
+	// names of their accessor functions.
+	shared map[string]string
+
+	// only, if non-nil, restricts regeneration to the named injectors.
+	// Other injectors are copied from prev when possible. regenerated
+	// counts the named injectors found in the package.
+	only        map[string]bool
+	prev        *previousOutput
+	regenerated int
+
+	// partial mirrors GenerateOptions.Partial: injectors that fail are
+	// copied from prev when possible instead of failing the package.
+	// emitted counts the injectors written to the output.
+	partial bool
+	emitted int
+
+	// tests is set when generating a test variant of the package. Only
+	// injectors declared in test files are generated.
+	tests bool
+
+	// constraint, if non-nil, is added to the build constraint of the
+	// output because injectors are redeclared under other constraints.
+	constraint constraint.Expr
+
+	// sourceDir, if not empty, is the directory of the output. Each
+	// statement that builds a value is then preceded by a //wire:source
+	// comment giving the position of its provider relative to sourceDir.
+	sourceDir string
 }
 
 func newGen(pkg *packages.Package) *gen {
@@ -221,24 +567,80 @@ func newGen(pkg *packages.Package) *gen {
 		anonImports: make(map[string]bool),
 		imports:     make(map[string]importInfo),
 		values:      make(map[ast.Expr]string),
+		shared:      make(map[string]string),
 	}
 }
 
+// generatedMarker is the first line of generated files after any header.
+const generatedMarker = "// Code generated by Wire. DO NOT EDIT."
+
 // frame bakes the built up source body into an unformatted Go source file.
 func (g *gen) frame(tags string) []byte {
 	if g.buf.Len() == 0 {
 		return nil
 	}
 	var buf bytes.Buffer
+	expr := generatedConstraint(tags, g.constraint)
 	if len(tags) > 0 {
 		tags = fmt.Sprintf(" gen -tags \"%s\"", tags)
 	}
-	buf.WriteString("// Code generated by Wire. DO NOT EDIT.\n\n")
-	buf.WriteString("//go:generate go run -mod=mod " + wireGoGeneratePath(g.pkg) + "/cmd/wire" + tags + "\n")
-	buf.WriteString("//+build !wireinject\n\n")
+	buf.WriteString(generatedMarker + "\n\n")
+	if !g.tests && !hasWireGoGenerate(g.pkg.Syntax) {
+		// The package's wire_gen.go already regenerates the test outputs,
+		// and a directive in the package's own files replaces this one.
+		buf.WriteString("//go:generate go run -mod=mod " + wireGoGeneratePath(g.pkg) + "/cmd/wire" + tags + "\n")
+	}
+	for _, line := range plusBuildLines(expr) {
+		buf.WriteString(line + "\n")
+	}
+	buf.WriteString("\n")
 	buf.WriteString("package ")
 	buf.WriteString(g.pkg.Name)
 	buf.WriteString("\n\n")
+	g.writeImports(&buf)
+	buf.Write(g.buf.Bytes())
+	return buf.Bytes()
+}
+
+// generatedConstraint returns the build constraint of a file generated with
+// the build tags tags, separated by spaces or commas as for go build -tags,
+// and the additional constraint extra, if non-nil. The file is excluded by
+// the wireinject tag, and requires each of tags, since its output depends
+// on the files those tags select. Terms that tags and extra repeat are
+// only written once.
+func generatedConstraint(tags string, extra constraint.Expr) constraint.Expr {
+	var expr constraint.Expr = &constraint.NotExpr{X: &constraint.TagExpr{Tag: "wireinject"}}
+	seen := map[string]bool{expr.String(): true, "wireinject": true}
+	add := func(x constraint.Expr) {
+		if s := x.String(); !seen[s] {
+			seen[s] = true
+			expr = &constraint.AndExpr{X: expr, Y: x}
+		}
+	}
+	for _, tag := range strings.FieldsFunc(tags, func(r rune) bool { return r == ' ' || r == ',' }) {
+		add(&constraint.TagExpr{Tag: tag})
+	}
+	for _, x := range conjuncts(extra) {
+		add(x)
+	}
+	return expr
+}
+
+// conjuncts returns the operands of the chain of && at the top of expr, or
+// nil if expr is nil.
+func conjuncts(expr constraint.Expr) []constraint.Expr {
+	switch x := expr.(type) {
+	case nil:
+		return nil
+	case *constraint.AndExpr:
+		return append(conjuncts(x.X), conjuncts(x.Y)...)
+	}
+	return []constraint.Expr{expr}
+}
+
+// writeImports writes the import declarations collected while generating
+// the body to buf.
+func (g *gen) writeImports(buf *bytes.Buffer) {
 	if len(g.imports) > 0 {
 		buf.WriteString("import (\n")
 		imps := make([]string, 0, len(g.imports))
@@ -250,9 +652,9 @@ func (g *gen) frame(tags string) []byte {
 			// Omit the local package identifier if it matches the package name.
 			info := g.imports[path]
 			if info.differs {
-				fmt.Fprintf(&buf, "\t%s %q\n", info.name, path)
+				fmt.Fprintf(buf, "\t%s %q\n", info.name, path)
 			} else {
-				fmt.Fprintf(&buf, "\t%q\n", path)
+				fmt.Fprintf(buf, "\t%q\n", path)
 			}
 		}
 		buf.WriteString(")\n\n")
@@ -266,12 +668,10 @@ func (g *gen) frame(tags string) []byte {
 		sort.Strings(anonImps)
 
 		for _, path := range anonImps {
-			fmt.Fprintf(&buf, "\t_ %s\n", path)
+			fmt.Fprintf(buf, "\t_ %s\n", path)
 		}
 		buf.WriteString(")\n\n")
 	}
-	buf.Write(g.buf.Bytes())
-	return buf.Bytes()
 }
 
 func wireGoGeneratePath(pkg *packages.Package) string {
@@ -280,46 +680,95 @@ func wireGoGeneratePath(pkg *packages.Package) string {
 
 // inject emits the code for an injector.
 func (g *gen) inject(pos token.Pos, name string, sig *types.Signature, set *ProviderSet, doc *ast.CommentGroup) []error {
-	injectSig, err := funcOutput(sig)
+	injectSig, err := injectorOutput(sig)
 	if err != nil {
 		return []error{notePosition(g.pkg.Fset.Position(pos),
-			fmt.Errorf("inject %s: %v", name, err))}
+			withCode(CodeSignature, fmt.Errorf("inject %s: %v", name, err)))}
+	}
+	if set.mustBuild {
+		if injectSig.err {
+			return []error{notePosition(g.pkg.Fset.Position(pos),
+				withCode(CodeSignature, fmt.Errorf("inject %s: wire.MustBuild injectors panic when a provider fails, so they cannot return an error; remove the error result or use wire.Build", name)))}
+		}
+		injectSig.must = true
 	}
 	params := sig.Params()
 	calls, errs := solve(g.pkg.Fset, injectSig.out, params, set)
 	if len(errs) > 0 {
 		return mapErrors(errs, func(e error) error {
 			if w, ok := e.(*wireErr); ok {
-				return notePosition(w.position, fmt.Errorf("inject %s: %v", name, w.error))
+				return notePosition(w.position, fmt.Errorf("inject %s: %w", name, w.error))
 			}
-			return notePosition(g.pkg.Fset.Position(pos), fmt.Errorf("inject %s: %v", name, e))
+			return notePosition(g.pkg.Fset.Position(pos), fmt.Errorf("inject %s: %w", name, e))
 		})
 	}
-	type pendingVar struct {
-		name     string
-		expr     ast.Expr
-		typeInfo *types.Info
+	pendingVars, errs := g.checkCalls(pos, name, calls, injectSig)
+	for _, hook := range set.Afters {
+		if hook.Err && !injectSig.canFail() {
+			errs = append(errs, notePosition(
+				g.pkg.Fset.Position(hook.Pos),
+				withCode(CodeSignature, fmt.Errorf("inject %s: wire.After function returns error but injection not allowed to fail", name))))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
 	}
+
+	// Perform one pass to collect all imports, followed by the real pass.
+	injectPass(name, sig, calls, set, doc, &injectorGen{
+		g:       g,
+		errVar:  disambiguate("err", g.nameInFileScope),
+		discard: true,
+	})
+	injectPass(name, sig, calls, set, doc, &injectorGen{
+		g:       g,
+		errVar:  disambiguate("err", g.nameInFileScope),
+		discard: false,
+	})
+	g.writePendingVars(pendingVars)
+	return nil
+}
+
+// A pendingVar is a package variable holding a wire.Value expression, or
+// the accessor for a provider passed to wire.Shared, that is written after
+// the injector that uses it.
+type pendingVar struct {
+	name     string
+	expr     ast.Expr
+	typeInfo *types.Info
+
+	// shared is the call to the shared provider if this is an accessor.
+	shared *call
+}
+
+// checkCalls verifies that the injector name can make calls given its output
+// signature, and names the variables for the values the calls use.
+func (g *gen) checkCalls(pos token.Pos, name string, calls []call, injectSig outputSignature) ([]pendingVar, []error) {
 	var pendingVars []pendingVar
 	ec := new(errorCollector)
 	for i := range calls {
 		c := &calls[i]
 		if c.hasCleanup && !injectSig.cleanup {
-			ts := types.TypeString(c.out, nil)
+			ts := TypeString(c.out)
 			ec.add(notePosition(
 				g.pkg.Fset.Position(pos),
-				fmt.Errorf("inject %s: provider for %s returns cleanup but injection does not return cleanup function", name, ts)))
+				withCode(CodeSignature, fmt.Errorf("inject %s: provider for %s returns cleanup but injection does not return cleanup function", name, ts))))
+		} else if c.cleanupErr && !injectSig.cleanupErr {
+			ts := TypeString(c.out)
+			ec.add(notePosition(
+				g.pkg.Fset.Position(pos),
+				withCode(CodeSignature, fmt.Errorf("inject %s: provider for %s returns a func() error cleanup but injection's cleanup function is func(); change it to func() error", name, ts))))
 		}
-		if c.hasErr && !injectSig.err {
-			ts := types.TypeString(c.out, nil)
+		if c.hasErr && !injectSig.canFail() {
+			ts := TypeString(c.out)
 			ec.add(notePosition(
 				g.pkg.Fset.Position(pos),
-				fmt.Errorf("inject %s: provider for %s returns error but injection not allowed to fail", name, ts)))
+				withCode(CodeSignature, fmt.Errorf("inject %s: provider for %s returns error but injection not allowed to fail; return an error, or use wire.MustBuild to panic instead", name, ts))))
 		}
 		if c.kind == valueExpr {
 			if err := accessibleFrom(c.valueTypeInfo, c.valueExpr, g.pkg.PkgPath); err != nil {
 				// TODO(light): Display line number of value expression.
-				ts := types.TypeString(c.out, nil)
+				ts := TypeString(c.out)
 				ec.add(notePosition(
 					g.pkg.Fset.Position(pos),
 					fmt.Errorf("inject %s: value %s can't be used: %v", name, ts, err)))
@@ -336,32 +785,113 @@ func (g *gen) inject(pos token.Pos, name string, sig *types.Signature, set *Prov
 				})
 			}
 		}
+		if c.shared && g.shared[sharedKey(c)] == "" {
+			name := typeVariableName(c.out, "", func(name string) string { return "_wireShared" + export(name) }, func(name string) bool {
+				return g.nameInFileScope(name) || g.nameInFileScope(name+"Once")
+			})
+			g.shared[sharedKey(c)] = name
+			pendingVars = append(pendingVars, pendingVar{name: name, shared: c})
+		}
 	}
 	if len(ec.errors) > 0 {
-		return ec.errors
+		return nil, ec.errors
 	}
+	return pendingVars, nil
+}
 
-	// Perform one pass to collect all imports, followed by the real pass.
-	injectPass(name, sig, calls, set, doc, &injectorGen{
-		g:       g,
-		errVar:  disambiguate("err", g.nameInFileScope),
-		discard: true,
-	})
-	injectPass(name, sig, calls, set, doc, &injectorGen{
-		g:       g,
-		errVar:  disambiguate("err", g.nameInFileScope),
-		discard: false,
-	})
-	if len(pendingVars) > 0 {
+// writePendingVars writes the declarations of the value variables and the
+// shared provider accessors.
+func (g *gen) writePendingVars(pendingVars []pendingVar) {
+	var values, shared []pendingVar
+	for _, pv := range pendingVars {
+		if pv.shared != nil {
+			shared = append(shared, pv)
+		} else {
+			values = append(values, pv)
+		}
+	}
+	if len(values) > 0 {
 		g.p("var (\n")
-		for _, pv := range pendingVars {
+		for _, pv := range values {
 			g.p("\t%s = ", pv.name)
 			g.writeAST(pv.typeInfo, pv.expr)
 			g.p("\n")
 		}
 		g.p(")\n\n")
 	}
-	return nil
+	for _, pv := range shared {
+		g.writeSharedAccessor(pv.name, pv.shared)
+	}
+}
+
+// sharedKey identifies the provider called by c, a call to a shared provider.
+func sharedKey(c *call) string {
+	return c.pkg.Path() + "." + c.name
+}
+
+// writeSharedAccessor writes the accessor function name for the shared
+// provider called by c, along with the variable, named name+"Once", that
+// holds its result.
+func (g *gen) writeSharedAccessor(name string, c *call) {
+	state := name + "Once"
+	out := types.TypeString(c.out, g.qualifyPkg)
+	g.p("var %s struct {\n", state)
+	g.p("\t%s\n", g.qualifiedID("sync", "sync", "Once"))
+	g.p("\tvalue %s\n", out)
+	if c.hasErr {
+		g.p("\terr error\n")
+	}
+	g.p("}\n\n")
+
+	params := make([]string, len(c.ins))
+	collides := func(n string) bool {
+		for _, p := range params {
+			if p == n {
+				return true
+			}
+		}
+		return g.nameInFileScope(n)
+	}
+	g.p("func %s(", name)
+	for i, t := range c.ins {
+		params[i] = typeVariableName(t, "arg", unexport, collides)
+		if i > 0 {
+			g.p(", ")
+		}
+		if c.varargs && i == len(c.ins)-1 {
+			g.p("%s ...%s", params[i], types.TypeString(t.(*types.Slice).Elem(), g.qualifyPkg))
+		} else {
+			g.p("%s %s", params[i], types.TypeString(t, g.qualifyPkg))
+		}
+	}
+	if c.hasErr {
+		g.p(") (%s, error) {\n", out)
+	} else {
+		g.p(") %s {\n", out)
+	}
+	g.p("\t%s.Do(func() {\n\t\t", state)
+	if c.hasErr {
+		g.p("%s.value, %s.err = ", state, state)
+	} else {
+		g.p("%s.value = ", state)
+	}
+	args := params
+	if c.method != "" {
+		g.p("%s.%s(", params[0], c.method)
+		args = params[1:]
+	} else {
+		g.p("%s(", g.qualifiedID(c.pkg.Name(), c.pkg.Path(), c.name))
+	}
+	g.p("%s", strings.Join(args, ", "))
+	if c.varargs {
+		g.p("...")
+	}
+	g.p(")\n\t})\n")
+	if c.hasErr {
+		g.p("\treturn %s.value, %s.err\n}\n\n", state, state)
+	} else {
+		g.p("\treturn %s.value\n}\n\n", state)
+	}
 }
 
 // rewritePkgRefs rewrites any package references in an AST into references for the
@@ -533,6 +1063,11 @@ func (g *gen) nameInFileScope(name string) bool {
 			return true
 		}
 	}
+	for _, other := range g.shared {
+		if other == name || other+"Once" == name {
+			return true
+		}
+	}
 	_, obj := g.pkg.Types.Scope().LookupParent(name, token.NoPos)
 	return obj != nil
 }
@@ -552,7 +1087,10 @@ type injectorGen struct {
 	paramNames   []string
 	localNames   []string
 	cleanupNames []string
-	errVar       string
+	// cleanupErrs reports for each of cleanupNames whether the cleanup
+	// function returns an error.
+	cleanupErrs []bool
+	errVar      string
 
 	// discard causes ig.p and ig.writeAST to no-op. Useful to run
 	// generation for side-effects like filling in g.imports.
@@ -562,12 +1100,67 @@ type injectorGen struct {
 // injectPass generates an injector given the output from analysis.
 // The sig passed in should be verified.
 func injectPass(name string, sig *types.Signature, calls []call, set *ProviderSet, doc *ast.CommentGroup, ig *injectorGen) {
-	params := sig.Params()
-	injectSig, err := funcOutput(sig)
+	injectSig, err := injectorOutput(sig)
 	if err != nil {
 		// This should be checked by the caller already.
 		panic(err)
 	}
+	injectSig.must = set.mustBuild
+	ig.funcHeader(name, sig, doc)
+	outTypeString := types.TypeString(injectSig.out, ig.g.qualifyPkg)
+	cleanupTypeString := "func()"
+	if injectSig.cleanupErr {
+		cleanupTypeString = "func() error"
+	}
+	switch {
+	case injectSig.out == nil && injectSig.err:
+		ig.p(") error {\n")
+	case injectSig.out == nil:
+		ig.p(") {\n")
+	case injectSig.cleanup && injectSig.err:
+		ig.p(") (%s, %s, error) {\n", outTypeString, cleanupTypeString)
+	case injectSig.cleanup:
+		ig.p(") (%s, %s) {\n", outTypeString, cleanupTypeString)
+	case injectSig.err:
+		ig.p(") (%s, error) {\n", outTypeString)
+	default:
+		ig.p(") %s {\n", outTypeString)
+	}
+	ig.calls(calls, injectSig)
+	out := argIndex(len(ig.paramNames), calls, set, injectSig.out)
+	hookArgs := make([][]int, len(set.Afters))
+	for i, hook := range set.Afters {
+		for _, t := range hook.Params {
+			hookArgs[i] = append(hookArgs[i], argIndex(len(ig.paramNames), calls, set, t))
+		}
+	}
+	ig.discardUnused(calls, out, hookArgs)
+	for i, hook := range set.Afters {
+		ig.afterHook(hook, hookArgs[i], injectSig)
+	}
+	switch {
+	case injectSig.out == nil && injectSig.err:
+		ig.p("\treturn nil\n}\n\n")
+		return
+	case injectSig.out == nil:
+		ig.p("}\n\n")
+		return
+	}
+	ig.p("\treturn %s", ig.argName(out))
+	if injectSig.cleanup {
+		ig.p(", ")
+		ig.cleanupFunc(injectSig)
+	}
+	if injectSig.err {
+		ig.p(", nil")
+	}
+	ig.p("\n}\n\n")
+}
+
+// funcHeader writes the doc comment, name, and parameters of an injector,
+// up to but not including the closing parenthesis of its parameter list.
+func (ig *injectorGen) funcHeader(name string, sig *types.Signature, doc *ast.CommentGroup) {
+	params := sig.Params()
 	if doc != nil {
 		for _, c := range doc.List {
 			ig.p("%s\n", c.Text)
@@ -594,21 +1187,18 @@ func injectPass(name string, sig *types.Signature, calls []call, set *ProviderSe
 			ig.p("%s %s", ig.paramNames[i], types.TypeString(pi.Type(), ig.g.qualifyPkg))
 		}
 	}
-	outTypeString := types.TypeString(injectSig.out, ig.g.qualifyPkg)
-	switch {
-	case injectSig.cleanup && injectSig.err:
-		ig.p(") (%s, func(), error) {\n", outTypeString)
-	case injectSig.cleanup:
-		ig.p(") (%s, func()) {\n", outTypeString)
-	case injectSig.err:
-		ig.p(") (%s, error) {\n", outTypeString)
-	default:
-		ig.p(") %s {\n", outTypeString)
-	}
+}
+
+// calls writes the statements that make calls.
+func (ig *injectorGen) calls(calls []call, injectSig outputSignature) {
+	candidates := localVarCandidates(calls)
 	for i := range calls {
 		c := &calls[i]
-		lname := typeVariableName(c.out, "v", unexport, ig.nameInInjector)
+		lname := pickName(candidates[i], ig.nameInInjector)
 		ig.localNames = append(ig.localNames, lname)
+		if ig.g.sourceDir != "" {
+			ig.sourceComment(c)
+		}
 		switch c.kind {
 		case structProvider:
 			ig.structProviderCall(lname, c)
@@ -622,22 +1212,92 @@ func injectPass(name string, sig *types.Signature, calls []call, set *ProviderSe
 			panic("unknown kind")
 		}
 	}
-	if len(calls) == 0 {
-		ig.p("\treturn %s", ig.paramNames[set.For(injectSig.out).Arg().Index])
-	} else {
-		ig.p("\treturn %s", ig.localNames[len(calls)-1])
+}
+
+// sourceComment writes the //wire:source comment of c, the position and
+// name of the provider, value, or field it comes from.
+func (ig *injectorGen) sourceComment(c *call) {
+	var pos token.Pos
+	var what string
+	switch {
+	case c.src.IsProvider():
+		p := c.src.Provider()
+		pos, what = p.Pos, p.Pkg.Path()+"."+p.Name
+	case c.src.IsValue():
+		pos, what = c.src.Value().Pos, "wire.Value"
+	case c.src.IsField():
+		f := c.src.Field()
+		pos, what = f.Pos, "wire.FieldsOf "+types.TypeString(f.Parent, nil)+"."+f.Name
+	default:
+		return
 	}
-	if injectSig.cleanup {
-		ig.p(", func() {\n")
-		for i := len(ig.cleanupNames) - 1; i >= 0; i-- {
-			ig.p("\t\t%s()\n", ig.cleanupNames[i])
+	position := ig.g.pkg.Fset.Position(pos)
+	if !position.IsValid() {
+		return
+	}
+	file := position.Filename
+	if rel, err := filepath.Rel(ig.g.sourceDir, file); err == nil {
+		file = rel
+	}
+	ig.p("\t//wire:source %s:%d:%d %s\n", filepath.ToSlash(file), position.Line, position.Column, what)
+}
+
+// argIndex returns the index of the injector argument or call that provides
+// t, in the same space as call.args.
+func argIndex(numParams int, calls []call, set *ProviderSet, t types.Type) int {
+	if t == nil {
+		// The injector has no output.
+		return -1
+	}
+	pv := set.For(t)
+	if pv.IsArg() {
+		return pv.Arg().Index
+	}
+	for i := range calls {
+		if types.Identical(calls[i].out, pv.Type()) {
+			return numParams + i
 		}
-		ig.p("\t}")
 	}
-	if injectSig.err {
-		ig.p(", nil")
+	panic("no call provides " + types.TypeString(t, nil))
+}
+
+// discardUnused assigns to the blank identifier the values built only
+// because wire.Require requires them, which would otherwise be unused
+// variables. out is the index of the injector's output.
+func (ig *injectorGen) discardUnused(calls []call, out int, hookArgs [][]int) {
+	used := make([]bool, len(calls))
+	markUsed := func(args []int) {
+		for _, a := range args {
+			if a >= len(ig.paramNames) {
+				used[a-len(ig.paramNames)] = true
+			}
+		}
+	}
+	for _, c := range calls {
+		markUsed(c.args)
+	}
+	for _, args := range hookArgs {
+		markUsed(args)
+	}
+	for i := range calls {
+		if !used[i] && len(ig.paramNames)+i != out {
+			ig.p("\t_ = %s\n", ig.localNames[i])
+		}
 	}
-	ig.p("\n}\n\n")
+}
+
+// cleanupFunc writes the injector's cleanup function, which runs the
+// provider cleanups in reverse order.
+func (ig *injectorGen) cleanupFunc(injectSig outputSignature) {
+	if injectSig.cleanupErr {
+		ig.errCleanup()
+		return
+	}
+	ig.p("func() {\n")
+	for i := len(ig.cleanupNames) - 1; i >= 0; i-- {
+		ig.p("\t\t%s()\n", ig.cleanupNames[i])
+	}
+	ig.p("\t}")
 }
 
 func (ig *injectorGen) funcProviderCall(lname string, c *call, injectSig outputSignature) {
@@ -646,22 +1306,34 @@ func (ig *injectorGen) funcProviderCall(lname string, c *call, injectSig outputS
 	if c.hasCleanup {
 		cname := disambiguate("cleanup", ig.nameInInjector)
 		ig.cleanupNames = append(ig.cleanupNames, cname)
+		ig.cleanupErrs = append(ig.cleanupErrs, c.cleanupErr)
 		ig.p(", %s", cname)
 	}
 	if c.hasErr {
 		ig.p(", %s", ig.errVar)
 	}
 	ig.p(" := ")
-	ig.p("%s(", ig.g.qualifiedID(c.pkg.Name(), c.pkg.Path(), c.name))
-	for i, a := range c.args {
+	argName := func(a int) string {
+		if a < len(ig.paramNames) {
+			return ig.paramNames[a]
+		}
+		return ig.localNames[a-len(ig.paramNames)]
+	}
+	args := c.args
+	switch {
+	case c.shared:
+		ig.p("%s(", ig.g.shared[sharedKey(c)])
+	case c.method != "":
+		ig.p("%s.%s(", argName(args[0]), c.method)
+		args = args[1:]
+	default:
+		ig.p("%s(", ig.g.qualifiedID(c.pkg.Name(), c.pkg.Path(), c.name))
+	}
+	for i, a := range args {
 		if i > 0 {
 			ig.p(", ")
 		}
-		if a < len(ig.paramNames) {
-			ig.p("%s", ig.paramNames[a])
-		} else {
-			ig.p("%s", ig.localNames[a-len(ig.paramNames)])
-		}
+		ig.p("%s", argName(a))
 	}
 	if c.varargs {
 		ig.p("...")
@@ -669,17 +1341,149 @@ func (ig *injectorGen) funcProviderCall(lname string, c *call, injectSig outputS
 	ig.p(")\n")
 	if c.hasErr {
 		ig.p("\tif %s != nil {\n", ig.errVar)
+		// Errors from cleanups run here are dropped in favor of the
+		// provider's error.
 		for i := prevCleanup - 1; i >= 0; i-- {
 			ig.p("\t\t%s()\n", ig.cleanupNames[i])
 		}
-		ig.p("\t\treturn %s", zeroValue(injectSig.out, ig.g.qualifyPkg))
+		if injectSig.must {
+			ig.p("\t\tpanic(%s)\n", ig.errVar)
+			ig.p("\t}\n")
+			return
+		}
+		ig.p("\t\treturn ")
+		if injectSig.out != nil {
+			ig.p("%s, ", zeroValue(injectSig.out, ig.g.qualifyPkg))
+		}
 		if injectSig.cleanup {
-			ig.p(", nil")
+			ig.p("nil, ")
 		}
 		// TODO(light): Give information about failing provider.
-		ig.p(", err\n")
+		ig.p("err\n")
+		ig.p("\t}\n")
+	}
+}
+
+// afterHook writes the call to a wire.After hook. If the hook fails, the
+// injector runs its cleanups and returns the hook's error, or panics with it
+// if declared with wire.MustBuild.
+func (ig *injectorGen) afterHook(hook *AfterHook, args []int, injectSig outputSignature) {
+	if hook.Err {
+		ig.p("\tif %s := ", ig.errVar)
+	} else {
+		ig.p("\t")
+	}
+	ig.writeAST(hook.Info, hook.Lit)
+	ig.p("(")
+	for i, a := range args {
+		if i > 0 {
+			ig.p(", ")
+		}
+		ig.p("%s", ig.argName(a))
+	}
+	ig.p(")")
+	if !hook.Err {
+		ig.p("\n")
+		return
+	}
+	ig.p("; %s != nil {\n", ig.errVar)
+	for i := len(ig.cleanupNames) - 1; i >= 0; i-- {
+		ig.p("\t\t%s()\n", ig.cleanupNames[i])
+	}
+	if injectSig.must {
+		ig.p("\t\tpanic(%s)\n", ig.errVar)
 		ig.p("\t}\n")
+		return
+	}
+	ig.p("\t\treturn ")
+	if injectSig.out != nil {
+		ig.p("%s, ", zeroValue(injectSig.out, ig.g.qualifyPkg))
 	}
+	if injectSig.cleanup {
+		ig.p("nil, ")
+	}
+	ig.p("%s\n", ig.errVar)
+	ig.p("\t}\n")
+}
+
+// errCleanup emits an injector cleanup function of type func() error. It
+// runs the provider cleanups in reverse order and joins the errors they
+// return.
+func (ig *injectorGen) errCleanup() {
+	ig.p("func() error {\n")
+	hasErrs := false
+	for _, isErr := range ig.cleanupErrs {
+		hasErrs = hasErrs || isErr
+	}
+	if !hasErrs {
+		for i := len(ig.cleanupNames) - 1; i >= 0; i-- {
+			ig.p("\t\t%s()\n", ig.cleanupNames[i])
+		}
+		ig.p("\t\treturn nil\n\t}")
+		return
+	}
+	errsVar := disambiguate("errs", ig.nameInInjector)
+	ig.p("\t\tvar %s []error\n", errsVar)
+	for i := len(ig.cleanupNames) - 1; i >= 0; i-- {
+		if ig.cleanupErrs[i] {
+			ig.p("\t\t%s = append(%s, %s())\n", errsVar, errsVar, ig.cleanupNames[i])
+		} else {
+			ig.p("\t\t%s()\n", ig.cleanupNames[i])
+		}
+	}
+	if ig.g.canJoinErrors() {
+		ig.p("\t\treturn %s(%s...)\n\t}", ig.g.qualifiedID("errors", "errors", "Join"), errsVar)
+		return
+	}
+	// errors.Join was added in Go 1.20; return the first error instead.
+	errVar := disambiguate("err", ig.nameInInjector)
+	ig.p("\t\tfor _, %s := range %s {\n", errVar, errsVar)
+	ig.p("\t\t\tif %s != nil {\n\t\t\t\treturn %s\n\t\t\t}\n\t\t}\n", errVar, errVar)
+	ig.p("\t\treturn nil\n\t}")
+}
+
+// canJoinErrors reports whether the generated code may call errors.Join,
+// which needs Go 1.20: the go directive of the package's module must not
+// name an earlier version. Packages outside a module are assumed to be
+// built with a current toolchain.
+func (g *gen) canJoinErrors() bool {
+	gomod := packageGoMod(g.pkg)
+	if gomod == "" {
+		return true
+	}
+	data, err := ioutil.ReadFile(gomod)
+	if err != nil {
+		return true
+	}
+	return !goVersionBefore(moduleGoVersion(data), 20)
+}
+
+// moduleGoVersion returns the version in the go directive of the contents
+// of a go.mod file, such as "1.19", or the empty string if there is none.
+func moduleGoVersion(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "go" {
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// goVersionBefore reports whether the Go version v, such as "1.19" or
+// "1.21.3", is older than 1.minor. Versions it cannot parse are not.
+func goVersionBefore(v string, minor int) bool {
+	rest := strings.TrimPrefix(v, "1.")
+	if rest == v {
+		return false
+	}
+	if i := strings.IndexAny(rest, ".rcbeta"); i >= 0 {
+		rest = rest[:i]
+	}
+	n, err := strconv.Atoi(rest)
+	return err == nil && n < minor
 }
 
 func (ig *injectorGen) structProviderCall(lname string, c *call) {
@@ -718,6 +1522,15 @@ func (ig *injectorGen) fieldExpr(lname string, c *call) {
 	}
 }
 
+// argName returns the name of the injector argument or local variable at
+// index a, in the same space as call.args.
+func (ig *injectorGen) argName(a int) string {
+	if a < len(ig.paramNames) {
+		return ig.paramNames[a]
+	}
+	return ig.localNames[a-len(ig.paramNames)]
+}
+
 // nameInInjector reports whether name collides with any other identifier
 // in the current injector.
 func (ig *injectorGen) nameInInjector(name string) bool {
@@ -749,6 +1562,16 @@ func (ig *injectorGen) p(format string, args ...interface{}) {
 	ig.g.p(format, args...)
 }
 
+func (ig *injectorGen) writeAST(info *types.Info, node ast.Node) {
+	node = ig.g.rewritePkgRefs(info, node)
+	if ig.discard {
+		return
+	}
+	if err := printer.Fprint(&ig.g.buf, ig.g.pkg.Fset, node); err != nil {
+		panic(err)
+	}
+}
+
 // zeroValue returns the shortest expression that evaluates to the zero
 // value for the given type.
 func zeroValue(t types.Type, qf types.Qualifier) string {
@@ -782,6 +1605,23 @@ func zeroValue(t types.Type, qf types.Qualifier) string {
 // names is unambiguous, it used; otherwise, the first derived name is
 // disambiguated using disambiguate().
 func typeVariableName(t types.Type, defaultName string, transform func(string) string, collides func(string) bool) string {
+	names := typeNames(t)
+
+	// If we were unable to derive a name, use defaultName.
+	if len(names) == 0 {
+		names = append(names, defaultName)
+	}
+
+	// Transform the name(s).
+	for i, name := range names {
+		names[i] = transform(name)
+	}
+	return pickName(names, collides)
+}
+
+// typeNames returns the names that can be derived from t: its own name and,
+// for named types, the name prefixed with its package name.
+func typeNames(t types.Type) []string {
 	if p, ok := t.(*types.Pointer); ok {
 		t = p.Elem()
 	}
@@ -802,17 +1642,68 @@ func typeVariableName(t types.Type, defaultName string, transform func(string) s
 			names = append(names, fmt.Sprintf("%s%s", pkg.Name(), strings.Title(obj.Name())))
 		}
 	}
+	return names
+}
 
-	// If we were unable to derive a name, use defaultName.
-	if len(names) == 0 {
-		names = append(names, defaultName)
+// localVarCandidates returns the names to try for each call's local variable
+// in an injector. Names are derived from the provided type, or for unnamed
+// types from the provider or field, rather than from the call's position, so
+// that adding an unrelated provider does not rename existing locals. When
+// several calls derive the same name, the first keeps it and the others fall
+// back to their package-qualified names, so that adding a second type of the
+// same name does not rename the local of the first.
+func localVarCandidates(calls []call) [][]string {
+	candidates := make([][]string, len(calls))
+	taken := make(map[string]bool)
+	for i := range calls {
+		c := &calls[i]
+		names := typeNames(c.out)
+		if len(names) == 0 {
+			switch c.kind {
+			case funcProviderCall:
+				name := c.name
+				if c.method != "" {
+					name = c.method
+				}
+				if name := trimProviderPrefix(name); name != "" {
+					names = append(names, name)
+				}
+			case selectorExpr:
+				names = append(names, c.name)
+			}
+		}
+		if len(names) == 0 {
+			names = append(names, "v")
+		}
+		for j, name := range names {
+			names[j] = unexport(name)
+		}
+		if taken[names[0]] && len(names) > 1 {
+			names = names[1:]
+		} else {
+			taken[names[0]] = true
+		}
+		candidates[i] = names
 	}
+	return candidates
+}
 
-	// Transform the name(s).
-	for i, name := range names {
-		names[i] = transform(name)
+// trimProviderPrefix strips a conventional New or Provide prefix from a
+// provider function name, e.g. "NewTags" -> "Tags".
+func trimProviderPrefix(name string) string {
+	for _, prefix := range []string{"New", "new", "Provide", "provide"} {
+		if rest := strings.TrimPrefix(name, prefix); rest != name {
+			if r, _ := utf8.DecodeRuneInString(rest); unicode.IsUpper(r) {
+				return rest
+			}
+		}
 	}
+	return name
+}
 
+// pickName returns the first of names that doesn't collide, or a
+// disambiguated form of the first name.
+func pickName(names []string, collides func(string) bool) string {
 	// See if there's an unambiguous name; if so, use it.
 	for _, name := range names {
 		if !token.Lookup(name).IsKeyword() && !collides(name) {
@@ -896,6 +1787,7 @@ func disambiguate(name string, collides func(string) bool) string {
 // accessibleFrom reports whether node can be copied to wantPkg without
 // violating Go visibility rules.
 func accessibleFrom(info *types.Info, node ast.Node, wantPkg string) error {
+	start, end := node.Pos(), node.End()
 	var unexportError error
 	ast.Inspect(node, func(node ast.Node) bool {
 		if unexportError != nil {
@@ -906,10 +1798,18 @@ func accessibleFrom(info *types.Info, node ast.Node, wantPkg string) error {
 			return true
 		}
 		obj := info.ObjectOf(ident)
+		if obj == nil {
+			return true
+		}
 		if _, ok := obj.(*types.PkgName); ok {
 			// Local package names are fine, since we can just reimport them.
 			return true
 		}
+		if start <= obj.Pos() && obj.Pos() < end {
+			// Declared by the node itself, such as a function literal's
+			// parameters and locals.
+			return true
+		}
 		if pkg := obj.Pkg(); pkg != nil {
 			if !ast.IsExported(ident.Name) && pkg.Path() != wantPkg {
 				unexportError = fmt.Errorf("uses unexported identifier %s", obj.Name())
@@ -926,6 +1826,7 @@ func accessibleFrom(info *types.Info, node ast.Node, wantPkg string) error {
 }
 
 var (
-	errorType   = types.Universe.Lookup("error").Type()
-	cleanupType = types.NewSignature(nil, nil, nil, false)
+	errorType      = types.Universe.Lookup("error").Type()
+	cleanupType    = types.NewSignature(nil, nil, nil, false)
+	errCleanupType = types.NewSignature(nil, nil, types.NewTuple(types.NewVar(token.NoPos, nil, "", errorType)), false)
 )
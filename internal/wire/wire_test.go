@@ -21,12 +21,14 @@ import (
 	"fmt"
 	"go/ast"
 	"go/build"
+	"go/build/constraint"
 	"go/token"
 	"go/types"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 	"unicode"
@@ -398,6 +400,87 @@ func TestTypeVariableName(t *testing.T) {
 	}
 }
 
+func TestLocalVarCandidates(t *testing.T) {
+	var (
+		stringT     = types.Typ[types.String]
+		fooPkg      = types.NewPackage("my.example/foo", "foo")
+		barPkg      = types.NewPackage("my.example/bar", "bar")
+		fooConfigT  = types.NewNamed(types.NewTypeName(0, fooPkg, "Config", nil), stringT, nil)
+		barConfigT  = types.NewNamed(types.NewTypeName(0, barPkg, "Config", nil), stringT, nil)
+		fooServiceT = types.NewNamed(types.NewTypeName(0, fooPkg, "Service", nil), stringT, nil)
+		tagsT       = types.NewSlice(stringT)
+	)
+	calls := []call{
+		{kind: funcProviderCall, out: fooConfigT},
+		{kind: funcProviderCall, out: types.NewPointer(fooServiceT)},
+		{kind: funcProviderCall, out: tagsT, name: "NewTags"},
+		{kind: selectorExpr, out: tagsT, name: "Labels"},
+		{kind: valueExpr, out: tagsT},
+		{kind: funcProviderCall, out: barConfigT},
+	}
+	got := localVarCandidates(calls)
+	want := [][]string{
+		{"config", "fooConfig"},
+		{"service", "fooService"},
+		{"tags"},
+		{"labels"},
+		{"v"},
+		{"barConfig"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("localVarCandidates (-want +got):\n%s", diff)
+	}
+}
+
+func TestTrimProviderPrefix(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"NewTags", "Tags"},
+		{"newTags", "Tags"},
+		{"ProvideTags", "Tags"},
+		{"Newsletter", "Newsletter"},
+		{"provide", "provide"},
+		{"Tags", "Tags"},
+	}
+	for _, test := range tests {
+		if got := trimProviderPrefix(test.name); got != test.want {
+			t.Errorf("trimProviderPrefix(%q) = %q; want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestGeneratedConstraint(t *testing.T) {
+	tests := []struct {
+		tags  string
+		extra string
+		want  string
+	}{
+		{"", "", "!wireinject"},
+		{"integration", "", "!wireinject && integration"},
+		{"integration prod", "", "!wireinject && integration && prod"},
+		{"integration,prod", "", "!wireinject && integration && prod"},
+		{" integration , prod  linux", "", "!wireinject && integration && prod && linux"},
+		{"prod prod,wireinject", "", "!wireinject && prod"},
+		{"dev", "dev", "!wireinject && dev"},
+		{"dev", "!prod && dev", "!wireinject && dev && !prod"},
+		{"", "linux || darwin", "!wireinject && (linux || darwin)"},
+	}
+	for _, test := range tests {
+		var extra constraint.Expr
+		if test.extra != "" {
+			var err error
+			if extra, err = constraint.Parse("//go:build " + test.extra); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := generatedConstraint(test.tags, extra).String(); got != test.want {
+			t.Errorf("generatedConstraint(%q, %q) = %q; want %q", test.tags, test.extra, got, test.want)
+		}
+	}
+}
+
 func TestDisambiguate(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -657,3 +740,75 @@ func (test *testCase) materialize(gopath string) error {
 	}
 	return nil
 }
+
+func TestGoVersionBefore(t *testing.T) {
+	tests := []struct {
+		gomod string
+		want  bool
+	}{
+		{"module example.com/app\n\ngo 1.19\n", true},
+		{"module example.com/app\n\ngo 1.20 // errors.Join\n", false},
+		{"module example.com/app\n\ngo 1.21.3\n\ntoolchain go1.22.1\n", false},
+		{"module example.com/app\n\ngo 1.21rc1\n", false},
+		{"module example.com/app\n", false},
+	}
+	for _, test := range tests {
+		if got := goVersionBefore(moduleGoVersion([]byte(test.gomod)), 20); got != test.want {
+			t.Errorf("goVersionBefore(moduleGoVersion(%q), 20) = %t; want %t", test.gomod, got, test.want)
+		}
+	}
+}
+
+func TestCleanupErrorsWithoutErrorsJoin(t *testing.T) {
+	root := t.TempDir()
+	writeAppModule(t, root)
+	writeFile(t, filepath.Join(root, "app", "app.go"), strings.Join([]string{
+		"package app",
+		"",
+		"type Foo struct{}",
+		"type Bar struct{}",
+		"",
+		"func NewFoo() (*Foo, func() error, error) { return &Foo{}, func() error { return nil }, nil }",
+		"func NewBar(*Foo) (*Bar, func() error) { return &Bar{}, func() error { return nil } }",
+		"",
+	}, "\n"))
+	writeFile(t, filepath.Join(root, "app", "wire.go"), strings.Join([]string{
+		"//go:build wireinject",
+		"",
+		"package app",
+		"",
+		"import \"github.com/goforj/wire\"",
+		"",
+		"func InitBar() (*Bar, func() error, error) {",
+		"\tpanic(wire.Build(NewFoo, NewBar))",
+		"}",
+		"",
+	}, "\n"))
+
+	env := append(os.Environ(), "GOWORK=off")
+	for _, goVersion := range []string{"1.19", "1.20"} {
+		gomod := filepath.Join(root, "go.mod")
+		data, err := ioutil.ReadFile(gomod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = regexp.MustCompile(`(?m)^go .*$`).ReplaceAll(data, []byte("go "+goVersion))
+		writeFile(t, gomod, string(data))
+		gens, errs := Generate(context.Background(), root, env, []string{"./app"}, &GenerateOptions{})
+		if len(errs) > 0 || len(gens) != 1 || len(gens[0].Errs) > 0 {
+			t.Fatalf("go %s: Generate failed: %v %v", goVersion, errs, gens)
+		}
+		content := string(gens[0].Content)
+		if joins := strings.Contains(content, "errors.Join"); joins != (goVersion == "1.20") {
+			t.Errorf("go %s: generated code calls errors.Join = %t:\n%s", goVersion, joins, content)
+		}
+		if err := gens[0].Commit(); err != nil {
+			t.Fatal(err)
+		}
+		cmd := exec.Command("go", "vet", "./app")
+		cmd.Dir, cmd.Env = root, env
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("go %s: generated code does not build: %v\n%s\n%s", goVersion, err, out, content)
+		}
+	}
+}
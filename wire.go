@@ -45,7 +45,9 @@ type ProviderSet struct{}
 // the injector function.
 //
 // Passing a ProviderSet to NewSet is the same as if the set's contents
-// were passed as arguments to NewSet directly.
+// were passed as arguments to NewSet directly. Converting a function value to
+// a function type, as in NewSet(LoaderFunc(loadConfig)), is the same as
+// passing the function itself.
 //
 // The behavior of passing the result of a call to other functions in this
 // package are described in their respective doc comments.
@@ -90,6 +92,80 @@ func Build(...interface{}) string {
 	return "implementation not generated, run wire"
 }
 
+// TestBuild is used in place of Build in injectors for tests. It behaves like
+// Build, except that types the injector needs but its arguments do not
+// provide are filled in by fakes:
+//
+//   - providers passed to TestBuild in a call to Fakes, and
+//   - for an interface type X, a function named NewFakeX in the injector's
+//     package returning X.
+//
+// Fakes are only called for types that would otherwise have no provider, so
+// real providers always take precedence and unused fakes are not an error.
+//
+// Example:
+//
+//	func initTestServer() *Server {
+//		panic(wire.TestBuild(ServerSet, wire.Fakes(clocktest.NewFakeClock)))
+//	}
+//
+//	// NewFakeMailer is used for the Mailer interface.
+//	func NewFakeMailer() Mailer { return &fakeMailer{} }
+func TestBuild(...interface{}) string {
+	return "implementation not generated, run wire"
+}
+
+// MustBuild is used in place of Build in injectors that panic when a
+// provider fails instead of returning the error, such as those called once
+// from main. The injector function has no error result even if providers
+// return errors; it runs the cleanup functions of the providers called so
+// far and then panics with the provider's error. An injector declared with
+// MustBuild may still return a cleanup function, but not an error.
+//
+// Example:
+//
+//	func initServer(cfg *Config) *Server {
+//		panic(wire.MustBuild(ServerSet))
+//	}
+func MustBuild(...interface{}) string {
+	return "implementation not generated, run wire"
+}
+
+// Populate is placed in the body of a function template to fill in the
+// fields of an existing struct instead of constructing a new value. target
+// must be a parameter of the function of type pointer to a named struct; the
+// remaining arguments are interpreted the same as Build. The Wire code
+// generation tool will fill in an implementation that assigns every field of
+// the struct, except those tagged `wire:"-"`, from the providers. The other
+// parameters of the function are used as inputs in the dependency graph.
+//
+// The function has no results, or, if its providers require them, a cleanup
+// function and an error, in that order.
+//
+// Example:
+//
+//	func populateHandler(h *Handler, cfg *Config) error {
+//		panic(wire.Populate(h, storage.Set, NewLogger))
+//	}
+func Populate(target interface{}, providers ...interface{}) string {
+	return "implementation not generated, run wire"
+}
+
+// A FakeSet is a group of fake providers.
+type FakeSet struct{}
+
+// Fakes declares fake providers for TestBuild. Each argument must be a
+// provider function, or a FakeSet. A FakeSet may be stored in a package
+// variable and shared between test injectors, but it may only be passed to
+// TestBuild or Fakes.
+//
+// Example:
+//
+//	var TestFakes = wire.Fakes(NewFakeClock, NewFakeStore)
+func Fakes(...interface{}) FakeSet {
+	return FakeSet{}
+}
+
 // A Binding maps an interface to a concrete type.
 type Binding struct{}
 
@@ -123,10 +199,13 @@ type ProvidedValue struct{}
 
 // Value binds an expression to provide the type of the expression.
 // The expression may not be an interface value; use InterfaceValue for that.
+// It is copied to the injector's package and evaluated once, when that
+// package is initialized, so it may not receive from a channel.
 //
 // Example:
 //
 //	var MySet = wire.NewSet(wire.Value([]string(nil)))
+//	var Defaults = wire.NewSet(wire.Value(Config{Timeout: 5 * time.Second, Hosts: []string{"a"}}))
 func Value(interface{}) ProvidedValue {
 	return ProvidedValue{}
 }
@@ -194,3 +273,147 @@ type StructFields struct{}
 func FieldsOf(structType interface{}, fieldNames ...string) StructFields {
 	return StructFields{}
 }
+
+// A Requirement asserts that an injector can build a type.
+type Requirement struct{}
+
+// Require declares that an injector using the set must build a value of the
+// type ptr points to, even if the injector's output does not depend on it.
+// Generation fails if the type cannot be provided. The value is built before
+// the injector's output and otherwise discarded, which pins down providers
+// that are only needed for their side effects, such as registering metrics.
+// An injector that returns nothing, or only an error, builds just the
+// required types.
+//
+// Example:
+//
+//	func initServer() *Server {
+//		panic(wire.Build(ServerSet, telemetry.Set, wire.Require(new(*telemetry.Exporter))))
+//	}
+func Require(ptr interface{}) Requirement {
+	return Requirement{}
+}
+
+// A CopiedType is the result of Copy.
+type CopiedType struct{}
+
+// Copy declares that every provider in an injector that needs a value of the
+// type ptr points to receives a value of its own, built by calling the type's
+// provider again, instead of sharing one value across the injector. This
+// suits mutable configuration structs that consumers adjust for themselves.
+// The provider's own dependencies are still shared, unless their types are
+// copied too. The injector's output, wire.Require, and wire.After hooks use
+// the same value as each other, apart from the consumers' copies.
+//
+// The type must be built by a provider function or struct provider: values,
+// fields, injector arguments and providers passed to Shared cannot be copied.
+//
+// Example:
+//
+//	func initServer() *Server {
+//		panic(wire.Build(NewConfig, NewHTTP, NewGRPC, NewServer, wire.Copy(new(*Config))))
+//	}
+func Copy(ptr interface{}) CopiedType {
+	return CopiedType{}
+}
+
+// A Redirection is the result of Redirect.
+type Redirection struct{}
+
+// Redirect soft-deprecates the provider set from in favor of to. Wire uses to
+// wherever from is passed to NewSet or Build, so consumers of from keep
+// working while the provider sets share a single definition, and wire check
+// warns about each use of from so that they can be migrated gradually.
+//
+// Redirect must be called in a top-level variable declaration in the package
+// that declares from. from must still be declared for code that uses it to
+// compile; it is usually declared as to.
+//
+// Example:
+//
+//	// Deprecated: Use StorageSet.
+//	var DBSet = StorageSet
+//
+//	var _ = wire.Redirect(DBSet, StorageSet)
+func Redirect(from, to ProviderSet) Redirection {
+	return Redirection{}
+}
+
+// An AfterHook is a function an injector calls once it has built its output.
+type AfterHook struct{}
+
+// After declares a hook, a function literal that the injector calls once it
+// has built its output, for work such as validation or registration that
+// would otherwise require wrapping the injector by hand. The hook's
+// parameters are built by the injector like its output, even if the output
+// does not depend on them. The hook may return an error, in which case the
+// injector must return one too: if the hook fails, the injector runs its
+// cleanup functions and returns the error. Hooks run in the order they are
+// passed, and may only be passed to Build.
+//
+// The literal is copied into the generated injector, so it may not refer to
+// the injector's parameters or local variables; take them as parameters
+// instead.
+//
+// Example:
+//
+//	func initApp() (*App, error) {
+//		panic(wire.Build(AppSet, wire.After(func(app *App, reg *Registry) error {
+//			return reg.Register(app)
+//		})))
+//	}
+func After(hook interface{}) AfterHook {
+	return AfterHook{}
+}
+
+// A SharedProvider is a provider whose result is reused by the injectors in
+// a package.
+type SharedProvider struct{}
+
+// Shared declares that provider, a provider function, is called at most once
+// per generated package: the first injector to need its result calls it, and
+// later calls to any injector in the package reuse the same result. An error
+// it returns is returned by every later call. Providers that return a cleanup
+// function cannot be shared, and the arguments of a shared provider must be
+// shared too, or be values or fields of them, so that they are the same in
+// every injector.
+//
+// Example:
+//
+//	var StorageSet = wire.NewSet(wire.Shared(NewConfig), wire.Shared(NewDBPool))
+func Shared(provider interface{}) SharedProvider {
+	return SharedProvider{}
+}
+
+// AutoSet returns a provider set with the exported functions of the packages
+// matching patterns whose names follow a naming convention, by default those
+// that start with New, so that teams with naming discipline need not list
+// every constructor. A pattern is an import path, which may end in /... to
+// include the packages below it, and only matches the calling package and
+// the packages it imports, directly or indirectly. The convention is set
+// with the -auto_names flag of wire gen. Functions whose signatures are not
+// valid for providers, generic functions, and functions in wireinject files
+// are skipped.
+//
+// Example:
+//
+//	var ServiceSet = wire.AutoSet("example.com/app/services/...")
+func AutoSet(patterns ...string) ProviderSet {
+	return ProviderSet{}
+}
+
+// Exclude returns a provider set with the providers of set except those
+// that provide the types ptrs point to, so that an injector or another set
+// can use a large shared set while supplying its own provider for one of
+// its types. The bindings are removed from the sets set imports as well.
+// Each excluded type must be provided by set, and an interface that set binds
+// to an excluded type with Bind must be excluded too.
+//
+// Example:
+//
+//	func initServer() *Server {
+//		panic(wire.Build(wire.Exclude(platform.Set, new(*platform.Logger)), NewQuietLogger, NewServer))
+//	}
+func Exclude(set ProviderSet, ptrs ...interface{}) ProviderSet {
+	return ProviderSet{}
+}
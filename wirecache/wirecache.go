@@ -0,0 +1,50 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package wirecache controls the cache of generated output that the Wire
+// code generation tool keeps between runs, so that build tools that rewrite
+// package sources behind its back, such as by restoring files from another
+// checkout, can drop the affected entries without running wire cache.
+package wirecache
+
+import (
+	"context"
+
+	"github.com/goforj/wire/internal/wire"
+)
+
+// Dir returns the directory that holds Wire's cache.
+func Dir() string {
+	return wire.CacheDir()
+}
+
+// Clear removes the whole cache, as wire cache -clear does.
+func Clear() error {
+	return wire.ClearCache()
+}
+
+// ClearPackage removes the cached output of the package at pkgPath, for
+// every set of options it was generated with, so that the next run generates
+// it again. The other packages stay cached.
+func ClearPackage(ctx context.Context, pkgPath string) error {
+	return wire.ClearPackage(ctx, pkgPath)
+}
+
+// InvalidatePattern is like ClearPackage for each package whose import path
+// matches one of patterns, as wire cache -invalidate does. A pattern is an
+// import path, or an import path containing "..." wildcards as for the go
+// command, such as "example.com/app/...".
+func InvalidatePattern(ctx context.Context, patterns []string) error {
+	return wire.InvalidatePattern(ctx, patterns)
+}
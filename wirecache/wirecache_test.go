@@ -0,0 +1,126 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wirecache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goforj/wire/internal/wire"
+)
+
+func TestCacheControls(t *testing.T) {
+	repoRoot, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The cache lives in the temporary directory.
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	if got, want := Dir(), filepath.Join(tmp, "wire-cache"); got != want {
+		t.Fatalf("Dir() = %q; want %q", got, want)
+	}
+
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod": strings.Join([]string{
+			"module example.com/app",
+			"",
+			"go 1.19",
+			"",
+			"require github.com/goforj/wire v0.0.0",
+			"replace github.com/goforj/wire => " + repoRoot,
+			"",
+		}, "\n"),
+	}
+	for _, name := range []string{"a", "b"} {
+		files[name+"/app.go"] = "package " + name + "\n\nfunc NewMessage() string { return \"ok\" }\n"
+		files[name+"/wire.go"] = strings.Join([]string{
+			"//go:build wireinject",
+			"",
+			"package " + name,
+			"",
+			"import \"github.com/goforj/wire\"",
+			"",
+			"func Init() string {",
+			"\twire.Build(NewMessage)",
+			"\treturn \"\"",
+			"}",
+			"",
+		}, "\n")
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+	env := append(os.Environ(), "GOWORK=off")
+	// cached generates the packages and returns those served from the
+	// cache.
+	cached := func() []string {
+		t.Helper()
+		outs, errs := wire.Generate(ctx, root, env, []string{"./..."}, nil)
+		if len(errs) > 0 {
+			t.Fatalf("Generate failed: %v", errs)
+		}
+		got := []string{}
+		for _, out := range outs {
+			if len(out.Errs) > 0 {
+				t.Fatalf("Generate failed for %s: %v", out.PkgPath, out.Errs)
+			}
+			if out.Cached {
+				got = append(got, out.PkgPath)
+			}
+		}
+		return got
+	}
+	all := []string{"example.com/app/a", "example.com/app/b"}
+
+	cached()
+	if got := cached(); !reflect.DeepEqual(got, all) {
+		t.Fatalf("second run served %v from the cache; want %v", got, all)
+	}
+	if err := ClearPackage(ctx, "example.com/app/a"); err != nil {
+		t.Fatalf("ClearPackage: %v", err)
+	}
+	if got, want := cached(), []string{"example.com/app/b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after ClearPackage, served %v from the cache; want %v", got, want)
+	}
+	if err := InvalidatePattern(ctx, []string{"example.com/app/..."}); err != nil {
+		t.Fatalf("InvalidatePattern: %v", err)
+	}
+	if got := cached(); len(got) > 0 {
+		t.Errorf("after InvalidatePattern, served %v from the cache; want nothing", got)
+	}
+	if err := Clear(); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	if _, err := os.Stat(Dir()); !os.IsNotExist(err) {
+		t.Errorf("after Clear, Stat(Dir()) returned %v; want a not-exist error", err)
+	}
+	if got := cached(); len(got) > 0 {
+		t.Errorf("after Clear, served %v from the cache; want nothing", got)
+	}
+}
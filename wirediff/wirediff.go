@@ -0,0 +1,46 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package wirediff compares the wire_gen.go files on disk with what the Wire
+// code generation tool would generate for them, so that bots and editor
+// plugins can present the differences without running the wire command or
+// comparing the files themselves.
+package wirediff
+
+import (
+	"context"
+
+	"github.com/goforj/wire/internal/wire"
+)
+
+// Options configures generation as the flags of wire gen and wire diff do.
+type Options = wire.GenerateOptions
+
+// A FileDiff compares a generated file on disk with what Wire produces for
+// it.
+type FileDiff = wire.FileDiff
+
+// An ErrorList is a list of errors, each with the package it belongs to and,
+// where known, its position.
+type ErrorList = wire.ErrorList
+
+// Diff generates the packages matching patterns, relative to the directory
+// wd and in the environment env, and compares each generated file with the
+// one on disk. It returns a FileDiff for every package that has injectors,
+// whether or not its file is up to date, along with the errors of the
+// packages that failed to generate. A nil opts uses the defaults of wire
+// diff.
+func Diff(ctx context.Context, wd string, env []string, patterns []string, opts *Options) ([]FileDiff, ErrorList) {
+	return wire.Diff(ctx, wd, env, patterns, opts)
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wirediff
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	repoRoot, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod": strings.Join([]string{
+			"module example.com/app",
+			"",
+			"go 1.19",
+			"",
+			"require github.com/goforj/wire v0.0.0",
+			"replace github.com/goforj/wire => " + repoRoot,
+			"",
+		}, "\n"),
+		"app.go": "package app\n\nfunc NewMessage() string { return \"ok\" }\n",
+		"wire.go": strings.Join([]string{
+			"//go:build wireinject",
+			"",
+			"package app",
+			"",
+			"import \"github.com/goforj/wire\"",
+			"",
+			"func Init() string {",
+			"\twire.Build(NewMessage)",
+			"\treturn \"\"",
+			"}",
+			"",
+		}, "\n"),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	env := append(os.Environ(), "GOWORK=off")
+	diffs, errs := Diff(context.Background(), root, env, []string{"."}, nil)
+	if len(errs) > 0 {
+		t.Fatalf("Diff returned errors: %v", errs)
+	}
+	if len(diffs) != 1 {
+		t.Fatalf("Diff returned %d diffs; want 1", len(diffs))
+	}
+	if d := diffs[0]; d.PkgPath != "example.com/app" || !d.Changed() || !strings.Contains(d.Unified, "+func Init() string {") {
+		t.Errorf("Diff = %+v; want a diff that adds Init to example.com/app", d)
+	}
+}
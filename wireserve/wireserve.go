@@ -0,0 +1,55 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package wireserve runs the Wire code generation tool continuously,
+// regenerating packages as their files change, so that development servers
+// and editor daemons can embed it and report each regeneration in their own
+// UI instead of running wire watch and parsing its output.
+package wireserve
+
+import (
+	"context"
+
+	"github.com/goforj/wire/internal/wire"
+)
+
+// Options configures Serve, including the hooks it reports each
+// regeneration to.
+type Options = wire.ServeOptions
+
+// GenerateOptions configures generation as the flags of wire gen do.
+type GenerateOptions = wire.GenerateOptions
+
+// A Result describes one regeneration run by Serve.
+type Result = wire.ServeResult
+
+// A GenerateResult is the result of generating one package.
+type GenerateResult = wire.GenerateResult
+
+// An ErrorList is a list of errors, each with the package it belongs to and,
+// where known, its position.
+type ErrorList = wire.ErrorList
+
+// Serve generates the packages matching patterns, relative to the directory
+// wd and in the environment env, writes their generated files, and generates
+// them again whenever a Go file or a go.mod, go.sum, or go.work file of the
+// module holding wd changes. Before each generation it calls
+// opts.OnGenerateStart, it passes each error to opts.OnError, and once the
+// files are written it calls opts.OnGenerateEnd with the result. A nil opts
+// uses the defaults.
+//
+// Serve returns when ctx is done, with ctx.Err().
+func Serve(ctx context.Context, wd string, env []string, patterns []string, opts *Options) error {
+	return wire.Serve(ctx, wd, env, patterns, opts)
+}
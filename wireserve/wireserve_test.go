@@ -0,0 +1,166 @@
+// Copyright 2026 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wireserve
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServe(t *testing.T) {
+	repoRoot, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	wireGo := func(provider string) string {
+		return strings.Join([]string{
+			"//go:build wireinject",
+			"",
+			"package app",
+			"",
+			"import \"github.com/goforj/wire\"",
+			"",
+			"func Init() string {",
+			"\twire.Build(" + provider + ")",
+			"\treturn \"\"",
+			"}",
+			"",
+		}, "\n")
+	}
+	files := map[string]string{
+		"go.mod": strings.Join([]string{
+			"module example.com/app",
+			"",
+			"go 1.19",
+			"",
+			"require github.com/goforj/wire v0.0.0",
+			"replace github.com/goforj/wire => " + repoRoot,
+			"",
+		}, "\n"),
+		"app.go":  "package app\n\nfunc NewMessage() string { return \"ok\" }\n",
+		"wire.go": wireGo("NewMessage"),
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range files {
+		write(name, content)
+	}
+
+	// The hooks run on Serve's goroutine, one at a time, and each sends an
+	// event describing the call.
+	type event struct {
+		start []string
+		err   error
+		end   *Result
+	}
+	events := make(chan event, 100)
+	opts := &Options{
+		PollInterval:    10 * time.Millisecond,
+		OnGenerateStart: func(patterns []string) { events <- event{start: patterns} },
+		OnError:         func(err error) { events <- event{err: err} },
+		OnGenerateEnd:   func(res Result) { events <- event{end: &res} },
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	env := append(os.Environ(), "GOWORK=off")
+	done := make(chan error, 1)
+	go func() { done <- Serve(ctx, root, env, []string{"."}, opts) }()
+
+	// nextRun returns the errors reported by the next generation and its
+	// result, checking that it started with the patterns given to Serve.
+	nextRun := func() ([]error, Result) {
+		t.Helper()
+		var errs []error
+		started := false
+		for {
+			select {
+			case e := <-events:
+				switch {
+				case e.start != nil:
+					if started {
+						t.Fatal("OnGenerateStart called twice before OnGenerateEnd")
+					}
+					started = true
+					if want := []string{"."}; !reflect.DeepEqual(e.start, want) {
+						t.Errorf("OnGenerateStart called with %q; want %q", e.start, want)
+					}
+				case e.err != nil:
+					if !started {
+						t.Fatalf("OnError called outside a generation: %v", e.err)
+					}
+					errs = append(errs, e.err)
+				case e.end != nil:
+					if !started {
+						t.Fatal("OnGenerateEnd called without OnGenerateStart")
+					}
+					return errs, *e.end
+				}
+			case err := <-done:
+				t.Fatalf("Serve returned early: %v", err)
+			case <-time.After(time.Minute):
+				t.Fatal("timed out waiting for a generation")
+			}
+		}
+	}
+
+	errs, res := nextRun()
+	if len(errs) > 0 || !res.OK() || !res.Initial || len(res.Results) != 1 {
+		t.Fatalf("initial generation reported errors %v and result %+v; want one package generated without errors", errs, res)
+	}
+	genPath := filepath.Join(root, "wire_gen.go")
+	if gen, err := os.ReadFile(genPath); err != nil || !strings.Contains(string(gen), "func Init() string") {
+		t.Fatalf("initial generation wrote %q, %v; want the injector", gen, err)
+	}
+
+	write("wire.go", wireGo("NewMissingMessage"))
+	errs, res = nextRun()
+	if len(errs) == 0 || res.OK() || res.Initial {
+		t.Fatalf("generation of a broken package reported errors %v and result %+v; want errors", errs, res)
+	}
+	if !strings.Contains(errs[0].Error(), "NewMissingMessage") {
+		t.Errorf("OnError called with %v; want an error about NewMissingMessage", errs[0])
+	}
+
+	write("wire.go", wireGo("NewMessage"))
+	if errs, res = nextRun(); len(errs) > 0 || !res.OK() {
+		t.Fatalf("generation after the fix reported errors %v and result %+v; want none", errs, res)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Serve returned %v; want %v", err, context.Canceled)
+		}
+	case <-time.After(time.Minute):
+		t.Fatal("Serve did not return after its context was canceled")
+	}
+	select {
+	case e := <-events:
+		t.Errorf("hook called after the last generation: %+v", e)
+	default:
+	}
+}